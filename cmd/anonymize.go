@@ -0,0 +1,20 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// anonymizeCmd is the root for all "anonymize" related subcommands.
+var anonymizeCmd = &cobra.Command{
+	Use:   "anonymize",
+	Short: "Remove personal data and secrets from files before sharing them",
+	Long:  "Commands for redacting personal data and secrets from logs and diagnostics, eg, before attaching them to external bug reports.",
+}
+
+func init() {
+	rootCmd.AddCommand(anonymizeCmd)
+}
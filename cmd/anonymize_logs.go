@@ -0,0 +1,482 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+// Redact personal data and secrets from log files.
+type anonymizeLogsOpts struct {
+	UsePositionalArgs
+
+	argInputPath     string
+	flagOutputPath   string
+	flagPatterns     []string
+	flagPatternsFile string
+	flagDisable      []string
+}
+
+// logAnonymizerRule is a pattern of sensitive values to redact. If the pattern has a group named
+// 'value', only the group is redacted (eg, the value in 'password=...'), otherwise the whole match.
+type logAnonymizerRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// logAnonymizerPatternsFile is the file given with --patterns-file.
+type logAnonymizerPatternsFile struct {
+	Patterns []struct {
+		Name  string `yaml:"name"`
+		Regex string `yaml:"regex"`
+	} `yaml:"patterns"`
+	Disable []string `yaml:"disable"` // Names of the built-in rules to disable
+}
+
+// Built-in rules, applied in order after any custom patterns. Tokens are redacted before the
+// generic patterns, so that eg, a JWT is not partially matched as something else.
+var defaultLogAnonymizerRules = []logAnonymizerRule{
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{"bearer-token", regexp.MustCompile(`(?i)\bbearer\s+(?P<value>[A-Za-z0-9._~+/=-]+)`)},
+	{"secret", regexp.MustCompile(`(?i)\b(?:access_?token|refresh_?token|id_?token|token|secret|client_?secret|password|passwd|api_?key|access_?key)["']?\s*[:=]\s*["']?(?P<value>[^\s"',;&]+)`)},
+	{"player-id", regexp.MustCompile(`\bPlayer:[0-9A-Za-z]{10}\b`)},
+	{"device-id", regexp.MustCompile(`(?i)\bdevice_?id["']?\s*[:=]\s*["']?(?P<value>[^\s"',;&]+)`)},
+	{"uuid", regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	{"ipv4", regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])\.){3}(?:25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])\b`)},
+	{"ipv6", regexp.MustCompile(`(?i)\b(?:[0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b|\b(?:[0-9a-f]{1,4}:){1,6}:(?:[0-9a-f]{1,4}:){0,5}[0-9a-f]{1,4}\b`)},
+}
+
+// Names of the rules, and the placeholders that the redacted values are replaced with.
+var (
+	logAnonymizerRuleNameRegex    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	logAnonymizerPlaceholderRegex = regexp.MustCompile(`^<[a-z0-9-]+-[0-9]+>$`)
+)
+
+// logAnonymizer replaces the sensitive values with placeholders like '<player-id-1>'. The same
+// value is always replaced with the same placeholder, so that the anonymized logs can still be
+// followed, eg, to see which lines are about the same player.
+type logAnonymizer struct {
+	rules       []logAnonymizerRule
+	pseudonyms  map[string]map[string]string // Placeholders of the redacted values, by rule name
+	occurrences map[string]int               // Number of redacted occurrences, by rule name
+}
+
+func init() {
+	o := anonymizeLogsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argInputPath, "FILE", "Log file to anonymize, a diagnostics bundle (.tar.gz), or '-' for stdin.")
+
+	cmd := &cobra.Command{
+		Use:   "logs FILE [flags]",
+		Short: "Redact player IDs, IP addresses, device IDs, and tokens from log files",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Redact personal data and secrets from downloaded game server or CLI logs, eg, before
+			attaching them to an external bug report.
+
+			The following are redacted by default (the names can be used with --disable):
+			- jwt: JSON Web Tokens.
+			- bearer-token: Bearer tokens, eg, in Authorization headers.
+			- secret: Values of tokens, secrets, passwords, and API keys, eg, 'password=...'.
+			- player-id: Player entity IDs, eg, 'Player:ab12CD34ef'.
+			- device-id: Values of device IDs, eg, 'deviceId: ...'.
+			- uuid: UUIDs, which are commonly used as device and user identifiers.
+			- email: Email addresses.
+			- ipv4, ipv6: IP addresses.
+
+			Each distinct value is replaced with a numbered placeholder, eg, '<player-id-1>', so that
+			the log lines about the same player or device can still be told apart.
+
+			Additional patterns can be given with --pattern NAME=REGEX, or in a YAML file with
+			--patterns-file. If the regular expression has a group named 'value', only the group
+			is redacted. The patterns file can also disable built-in rules:
+
+			  patterns:
+			    - name: order-id
+			      regex: 'orderId=(?P<value>[0-9]+)'
+			  disable: [uuid]
+
+			A diagnostics bundle from 'metaplay debug collect' is anonymized file by file into a
+			new bundle. Binary files in the bundle, such as heap dumps, cannot be anonymized and
+			are left out.
+
+			The patterns cannot catch all personal data, so review the output before sharing it.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay debug logs ...' to view and download the game server logs.
+			- 'metaplay debug collect ...' to collect a diagnostics bundle.
+		`),
+		Example: renderExample(`
+			# Anonymize a log file into server.anonymized.log.
+			metaplay anonymize logs server.log
+
+			# Anonymize a diagnostics bundle into diagnostics-nimbly.anonymized.tar.gz.
+			metaplay anonymize logs diagnostics-nimbly-20250101-120000.tar.gz
+
+			# Anonymize the logs from stdin to stdout.
+			metaplay debug logs nimbly | metaplay anonymize logs - -o -
+
+			# Also redact order IDs, but keep the UUIDs.
+			metaplay anonymize logs server.log --pattern 'order-id=orderId=(?P<value>[0-9]+)' --disable uuid
+		`),
+	}
+	anonymizeCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagOutputPath, "output", "o", "", "Output path, or '-' for stdout (default: FILE with '.anonymized' before the extension, or stdout for stdin)")
+	flags.StringArrayVar(&o.flagPatterns, "pattern", nil, "Additional pattern to redact as NAME=REGEX, can be repeated")
+	flags.StringVar(&o.flagPatternsFile, "patterns-file", "", "YAML file with additional patterns to redact and built-in rules to disable")
+	flags.StringSliceVar(&o.flagDisable, "disable", nil, "Built-in rules to disable (comma-separated), eg, 'uuid,email'")
+}
+
+func (o *anonymizeLogsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagOutputPath == "" {
+		o.flagOutputPath = defaultAnonymizedOutputPath(o.argInputPath)
+	}
+	if o.flagOutputPath != "-" && o.argInputPath != "-" && filepath.Clean(o.flagOutputPath) == filepath.Clean(o.argInputPath) {
+		return clierrors.NewUsageError("The output file must be different from the input file").
+			WithSuggestion("Specify another output path with --output")
+	}
+	if isDiagnosticsBundlePath(o.argInputPath) && o.flagOutputPath == "-" {
+		return clierrors.NewUsageError("An anonymized diagnostics bundle cannot be written to stdout").
+			WithSuggestion("Specify the output file with --output, eg, 'bundle.anonymized.tar.gz'")
+	}
+	return nil
+}
+
+func (o *anonymizeLogsOpts) Run(cmd *cobra.Command) error {
+	rules, err := resolveLogAnonymizerRules(o.flagPatterns, o.flagPatternsFile, o.flagDisable)
+	if err != nil {
+		return err
+	}
+	anonymizer := newLogAnonymizer(rules)
+
+	// Anonymize the input.
+	skippedFiles := []string{}
+	if isDiagnosticsBundlePath(o.argInputPath) {
+		skippedFiles, err = anonymizeDiagnosticsBundle(anonymizer, o.argInputPath, o.flagOutputPath)
+	} else {
+		err = anonymizeLogFile(anonymizer, o.argInputPath, o.flagOutputPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Print the summary into stderr when writing the output into stdout.
+	summaryOut := io.Writer(os.Stdout)
+	if o.flagOutputPath == "-" {
+		summaryOut = os.Stderr
+	}
+	fmt.Fprintln(summaryOut, "")
+	if o.flagOutputPath != "-" {
+		fmt.Fprintf(summaryOut, "✅ %s %s\n", styles.RenderSuccess("Wrote anonymized logs to"), styles.RenderTechnical(o.flagOutputPath))
+	}
+	for _, rule := range rules {
+		if count := anonymizer.occurrences[rule.Name]; count > 0 {
+			fmt.Fprintf(summaryOut, "  %-14s %d occurrence(s) of %d distinct value(s)\n", rule.Name, count, len(anonymizer.pseudonyms[rule.Name]))
+		}
+	}
+	for _, name := range skippedFiles {
+		fmt.Fprintf(summaryOut, "  %s %s\n", styles.RenderWarning("Left out binary file"), name)
+	}
+	fmt.Fprintln(summaryOut, styles.RenderMuted("Review the output before sharing it: the patterns cannot catch all personal data."))
+	return nil
+}
+
+// defaultAnonymizedOutputPath returns the output path for the input, eg, 'server.anonymized.log'
+// for 'server.log'. Stdin is written to stdout.
+func defaultAnonymizedOutputPath(inputPath string) string {
+	if inputPath == "-" {
+		return "-"
+	}
+	for _, ext := range []string{".tar.gz", ".tgz"} {
+		if base, found := strings.CutSuffix(inputPath, ext); found {
+			return base + ".anonymized" + ext
+		}
+	}
+	ext := filepath.Ext(inputPath)
+	return strings.TrimSuffix(inputPath, ext) + ".anonymized" + ext
+}
+
+// isDiagnosticsBundlePath reports whether the path is a diagnostics bundle (a .tar.gz archive).
+func isDiagnosticsBundlePath(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// resolveLogAnonymizerRules returns the custom patterns followed by the enabled built-in rules.
+func resolveLogAnonymizerRules(patterns []string, patternsFilePath string, disable []string) ([]logAnonymizerRule, error) {
+	rules := []logAnonymizerRule{}
+	disabled := slices.Clone(disable)
+
+	addRule := func(name, regex, source string) error {
+		if !logAnonymizerRuleNameRegex.MatchString(name) {
+			return clierrors.NewUsageErrorf("Invalid pattern name '%s' in %s", name, source).
+				WithSuggestion("Use only lowercase letters, digits, and dashes, eg, 'order-id'")
+		}
+		if slices.ContainsFunc(rules, func(rule logAnonymizerRule) bool { return rule.Name == name }) {
+			return clierrors.NewUsageErrorf("Duplicate pattern name '%s' in %s", name, source)
+		}
+		pattern, err := regexp.Compile(regex)
+		if err != nil {
+			return clierrors.NewUsageErrorf("Invalid regular expression for pattern '%s' in %s", name, source).
+				WithDetails(err.Error())
+		}
+		rules = append(rules, logAnonymizerRule{Name: name, Pattern: pattern})
+		return nil
+	}
+
+	// Patterns from the file.
+	if patternsFilePath != "" {
+		content, err := os.ReadFile(patternsFilePath)
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read patterns file %s", patternsFilePath)
+		}
+		var patternsFile logAnonymizerPatternsFile
+		if err := yaml.Unmarshal(content, &patternsFile); err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to parse patterns file %s", patternsFilePath)
+		}
+		for _, pattern := range patternsFile.Patterns {
+			if err := addRule(pattern.Name, pattern.Regex, patternsFilePath); err != nil {
+				return nil, err
+			}
+		}
+		disabled = append(disabled, patternsFile.Disable...)
+	}
+
+	// Patterns from the command line.
+	for _, pattern := range patterns {
+		name, regex, found := strings.Cut(pattern, "=")
+		if !found || regex == "" {
+			return nil, clierrors.NewUsageErrorf("Invalid --pattern '%s'", pattern).
+				WithSuggestion("Use the format NAME=REGEX, eg, 'order-id=ORD-[0-9]+'")
+		}
+		if err := addRule(name, regex, "--pattern"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Built-in rules, unless disabled.
+	for _, name := range disabled {
+		if !slices.ContainsFunc(defaultLogAnonymizerRules, func(rule logAnonymizerRule) bool { return rule.Name == name }) {
+			return nil, clierrors.NewUsageErrorf("Unknown built-in rule '%s' to disable", name).
+				WithSuggestion("See 'metaplay anonymize logs --help' for the names of the built-in rules")
+		}
+	}
+	for _, rule := range defaultLogAnonymizerRules {
+		if slices.Contains(disabled, rule.Name) {
+			continue
+		}
+		if slices.ContainsFunc(rules, func(r logAnonymizerRule) bool { return r.Name == rule.Name }) {
+			return nil, clierrors.NewUsageErrorf("Pattern name '%s' is reserved for a built-in rule", rule.Name).
+				WithSuggestion(fmt.Sprintf("Use another name, or disable the built-in rule with --disable %s", rule.Name))
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func newLogAnonymizer(rules []logAnonymizerRule) *logAnonymizer {
+	return &logAnonymizer{
+		rules:       rules,
+		pseudonyms:  map[string]map[string]string{},
+		occurrences: map[string]int{},
+	}
+}
+
+// AnonymizeLine replaces the sensitive values on the line with their placeholders.
+func (a *logAnonymizer) AnonymizeLine(line string) string {
+	for _, rule := range a.rules {
+		line = a.applyRule(rule, line)
+	}
+	return line
+}
+
+// applyRule replaces the values matched by the rule. Values already replaced with a placeholder
+// by an earlier rule are left as-is.
+func (a *logAnonymizer) applyRule(rule logAnonymizerRule, line string) string {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+
+	valueGroupNdx := rule.Pattern.SubexpIndex("value")
+	var sb strings.Builder
+	prevEnd := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if valueGroupNdx >= 0 {
+			start, end = match[2*valueGroupNdx], match[2*valueGroupNdx+1]
+		}
+		if start < 0 || start == end {
+			continue
+		}
+		value := line[start:end]
+		if logAnonymizerPlaceholderRegex.MatchString(value) {
+			continue
+		}
+
+		sb.WriteString(line[prevEnd:start])
+		sb.WriteString(a.pseudonym(rule.Name, value))
+		prevEnd = end
+	}
+	sb.WriteString(line[prevEnd:])
+	return sb.String()
+}
+
+// pseudonym returns the placeholder for the value, eg, '<player-id-3>'.
+func (a *logAnonymizer) pseudonym(ruleName, value string) string {
+	a.occurrences[ruleName]++
+	values, ok := a.pseudonyms[ruleName]
+	if !ok {
+		values = map[string]string{}
+		a.pseudonyms[ruleName] = values
+	}
+	if placeholder, ok := values[value]; ok {
+		return placeholder
+	}
+	placeholder := fmt.Sprintf("<%s-%d>", ruleName, len(values)+1)
+	values[value] = placeholder
+	return placeholder
+}
+
+// Anonymize copies the input into the output line by line, anonymizing each line.
+func (a *logAnonymizer) Anonymize(input io.Reader, output io.Writer) error {
+	reader := bufio.NewReader(input)
+	writer := bufio.NewWriter(output)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, writeErr := writer.WriteString(a.AnonymizeLine(line)); writeErr != nil {
+				return writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// anonymizeLogFile anonymizes the log file (or stdin) into the output file (or stdout).
+func anonymizeLogFile(anonymizer *logAnonymizer, inputPath, outputPath string) error {
+	input := io.Reader(os.Stdin)
+	if inputPath != "-" {
+		file, err := os.Open(inputPath)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to open log file %s", inputPath)
+		}
+		defer func() { _ = file.Close() }()
+		input = file
+	}
+
+	output := io.Writer(os.Stdout)
+	if outputPath != "-" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to create output file %s", outputPath)
+		}
+		defer func() { _ = file.Close() }()
+		output = file
+	}
+
+	if err := anonymizer.Anonymize(input, output); err != nil {
+		return clierrors.Wrap(err, "Failed to anonymize the logs")
+	}
+	return nil
+}
+
+// anonymizeDiagnosticsBundle anonymizes each text file in the diagnostics bundle into a new bundle.
+// Binary files are left out, and their names are returned.
+func anonymizeDiagnosticsBundle(anonymizer *logAnonymizer, inputPath, outputPath string) ([]string, error) {
+	inputFile, err := os.Open(inputPath)
+	if err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to open diagnostics bundle %s", inputPath)
+	}
+	defer func() { _ = inputFile.Close() }()
+	gzipReader, err := gzip.NewReader(inputFile)
+	if err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to read diagnostics bundle %s", inputPath)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to create output file %s", outputPath)
+	}
+	defer func() { _ = outputFile.Close() }()
+	gzipWriter := gzip.NewWriter(outputFile)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	skippedFiles := []string{}
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read diagnostics bundle %s", inputPath)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			if err := tarWriter.WriteHeader(header); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read %s from diagnostics bundle", header.Name)
+		}
+		if isBinaryContent(content) {
+			skippedFiles = append(skippedFiles, header.Name)
+			continue
+		}
+
+		var anonymized bytes.Buffer
+		if err := anonymizer.Anonymize(bytes.NewReader(content), &anonymized); err != nil {
+			return nil, err
+		}
+		header.Size = int64(anonymized.Len())
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(anonymized.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return skippedFiles, nil
+}
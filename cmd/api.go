@@ -0,0 +1,268 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// HTTP methods supported by 'metaplay api'.
+var apiRequestMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Perform an authenticated HTTP request against the game server admin API.
+type apiOpts struct {
+	UsePositionalArgs
+
+	argEnvironment  string
+	argMethod       string
+	argPath         string
+	flagBody        string
+	flagHeaders     []string
+	flagContentType string
+	flagOutput      string
+	flagRaw         bool
+	flagSilent      bool
+}
+
+func init() {
+	o := apiOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argMethod, "METHOD", "HTTP method to use: GET, HEAD, POST, PUT, PATCH, or DELETE.")
+	args.AddStringArgument(&o.argPath, "PATH", "Path of the admin API endpoint, eg, 'api/hello'.")
+
+	cmd := &cobra.Command{
+		Use:   "api ENVIRONMENT METHOD PATH [flags]",
+		Short: "Make an authenticated request to the game server admin API",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Make an authenticated HTTP request to any endpoint of the game server admin API in the
+			target environment and print the response.
+
+			The admin API hostname of the environment is resolved automatically and the request is
+			authenticated with the current credentials: the token from 'metaplay auth login' or the
+			machine token from 'metaplay auth machine-login' (or METAPLAY_CREDENTIALS).
+
+			The response status line is printed to stderr and the response body to stdout, so the
+			output can be piped to other tools. JSON responses are pretty-printed unless --raw is
+			used. The command exits with an error if the response status is not successful (2xx).
+
+			The request body can be given inline with --body, read from a file with --body=@file,
+			or read from stdin with --body=@-. The Content-Type is detected from the body (JSON or
+			binary) unless specified with --content-type. Extra request headers can be added with
+			--header.
+
+			Unlike 'metaplay debug admin-request', this command supports all the common HTTP
+			methods, custom headers, and reports the response status, which makes it suitable for
+			scripting.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay debug admin-request ...' makes admin API requests with JSON output.
+			- 'metaplay auth machine-login ...' to authenticate in CI.
+		`),
+		Example: renderExample(`
+			# Get the server hello message.
+			metaplay api nimbly GET api/hello
+
+			# Extract a field from the response with jq.
+			metaplay api nimbly GET api/hello --silent | jq -r .commitId
+
+			# Send a POST request with the body read from a file.
+			metaplay api nimbly POST api/some-endpoint --body=@request.json
+
+			# Send a PUT request with the body read from stdin.
+			cat update.json | metaplay api nimbly PUT api/some-endpoint --body=@-
+
+			# Add a custom header to the request.
+			metaplay api nimbly GET api/some-endpoint --header "X-Custom: value"
+
+			# Save a binary response into a file.
+			metaplay api nimbly GET api/GameConfig/.../download --output gameconfig.mca
+		`),
+	}
+	cmd.GroupID = "manage"
+	rootCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagBody, "body", "", "Request body: inline content, '@<file>' to read from a file, or '@-' to read from stdin")
+	flags.StringArrayVarP(&o.flagHeaders, "header", "H", nil, "Extra request header in the format 'Name: value' (can be repeated)")
+	flags.StringVar(&o.flagContentType, "content-type", "", "Content-Type of the request body (default: detected from the body)")
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Write the response body to a file instead of stdout")
+	flags.BoolVar(&o.flagRaw, "raw", false, "Print the response body as-is without pretty-printing JSON")
+	flags.BoolVarP(&o.flagSilent, "silent", "s", false, "Don't print the response status line")
+}
+
+func (o *apiOpts) Prepare(cmd *cobra.Command, args []string) error {
+	o.argMethod = strings.ToUpper(o.argMethod)
+	if !slices.Contains(apiRequestMethods, o.argMethod) {
+		return clierrors.NewUsageErrorf("Invalid HTTP method '%s'", o.argMethod).
+			WithSuggestion(fmt.Sprintf("Use one of: %s", strings.Join(apiRequestMethods, ", ")))
+	}
+
+	argPath, err := normalizeAdminAPIPath(o.argPath, fmt.Sprintf("metaplay api %s %s", o.argEnvironment, o.argMethod))
+	if err != nil {
+		return err
+	}
+	o.argPath = argPath
+
+	if _, err := parseAPIRequestHeaders(o.flagHeaders); err != nil {
+		return err
+	}
+
+	if o.flagBody != "" && (o.argMethod == http.MethodGet || o.argMethod == http.MethodHead) {
+		return clierrors.NewUsageErrorf("A request body cannot be used with %s requests", o.argMethod)
+	}
+
+	return nil
+}
+
+func (o *apiOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve project and environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the request body.
+	requestBody, err := readAPIRequestBody(o.flagBody, os.Stdin)
+	if err != nil {
+		return err
+	}
+	contentType := o.flagContentType
+	if contentType == "" && requestBody != nil {
+		contentType = "application/octet-stream"
+		if json.Valid(requestBody) {
+			contentType = "application/json"
+		}
+	}
+
+	headers, err := parseAPIRequestHeaders(o.flagHeaders)
+	if err != nil {
+		return err
+	}
+
+	adminAPIBaseURL := getAdminAPIBaseURL(envConfig)
+	adminClient := metahttp.NewJSONClient(tokenSet, adminAPIBaseURL)
+	log.Debug().Msgf("Admin API request: %s %s%s", o.argMethod, adminAPIBaseURL, o.argPath)
+
+	request := adminClient.Resty.R().SetContext(cmd.Context())
+	if contentType != "" {
+		request.SetHeader("Content-Type", contentType)
+	}
+	if requestBody != nil {
+		request.SetBody(requestBody)
+	}
+	for name, value := range headers {
+		request.SetHeader(name, value)
+	}
+
+	response, err := request.Execute(o.argMethod, o.argPath)
+	if err != nil {
+		return clierrors.Wrapf(err, "Request to %s failed", adminAPIBaseURL+o.argPath)
+	}
+
+	// Print status line to stderr to keep stdout clean for the body.
+	statusCode := response.StatusCode()
+	isSuccess := statusCode >= 200 && statusCode < 300
+	if !o.flagSilent {
+		statusLine := fmt.Sprintf("HTTP %s", response.Status())
+		if isSuccess {
+			fmt.Fprintln(os.Stderr, styles.RenderSuccess(statusLine))
+		} else {
+			fmt.Fprintln(os.Stderr, styles.RenderError(statusLine))
+		}
+	}
+
+	// Output the response body.
+	body := response.Body()
+	if o.flagOutput != "" {
+		if err := os.WriteFile(o.flagOutput, body, 0644); err != nil {
+			return clierrors.Wrapf(err, "Failed to write response to %s", o.flagOutput)
+		}
+		if !o.flagSilent {
+			fmt.Fprintf(os.Stderr, "Saved response to %s (%d bytes)\n", o.flagOutput, len(body))
+		}
+	} else if len(body) > 0 {
+		isJSON := strings.Contains(response.Header().Get("Content-Type"), "json")
+		log.Info().Msg(formatAPIResponseBody(body, isJSON && !o.flagRaw))
+	}
+
+	if !isSuccess {
+		return clierrors.Newf("Request failed with status %d", statusCode)
+	}
+	return nil
+}
+
+// readAPIRequestBody resolves the --body value: '@-' reads the body from stdin, '@<file>' from
+// the file, and anything else is used as-is. Returns nil if no body was given.
+func readAPIRequestBody(value string, stdin io.Reader) ([]byte, error) {
+	switch {
+	case value == "":
+		return nil, nil
+	case value == "@-":
+		body, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, clierrors.Wrap(err, "Failed to read request body from stdin")
+		}
+		return body, nil
+	case strings.HasPrefix(value, "@"):
+		body, err := os.ReadFile(value[1:])
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read request body from file '%s'", value[1:])
+		}
+		return body, nil
+	default:
+		return []byte(value), nil
+	}
+}
+
+// parseAPIRequestHeaders parses the --header values in the format 'Name: value'.
+func parseAPIRequestHeaders(values []string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, value := range values {
+		name, headerValue, found := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, clierrors.NewUsageErrorf("Invalid header '%s'", value).
+				WithSuggestion("Use the format 'Name: value', eg, --header 'X-Custom: value'")
+		}
+		headers[name] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
+// formatAPIResponseBody returns the response body for printing, pretty-printing JSON bodies
+// if requested. Bodies that fail to parse as JSON are returned as-is.
+func formatAPIResponseBody(body []byte, prettyPrintJSON bool) string {
+	if prettyPrintJSON {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err == nil {
+			return indented.String()
+		}
+	}
+	return strings.TrimSuffix(string(body), "\n")
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAPIRequestBody(t *testing.T) {
+	body, err := readAPIRequestBody("", strings.NewReader("unused"))
+	require.NoError(t, err)
+	assert.Nil(t, body)
+
+	body, err = readAPIRequestBody(`{"name":"test"}`, strings.NewReader("unused"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"test"}`, string(body))
+
+	body, err = readAPIRequestBody("@-", strings.NewReader("from stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin", string(body))
+
+	filePath := filepath.Join(t.TempDir(), "body.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"from":"file"}`), 0644))
+	body, err = readAPIRequestBody("@"+filePath, strings.NewReader("unused"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"from":"file"}`, string(body))
+
+	_, err = readAPIRequestBody("@"+filepath.Join(t.TempDir(), "missing.json"), strings.NewReader("unused"))
+	assert.Error(t, err)
+}
+
+func TestParseAPIRequestHeaders(t *testing.T) {
+	headers, err := parseAPIRequestHeaders([]string{"X-Custom: value", "Accept:text/plain", "X-Empty:"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"X-Custom": "value", "Accept": "text/plain", "X-Empty": ""}, headers)
+
+	_, err = parseAPIRequestHeaders([]string{"NoColon"})
+	assert.Error(t, err)
+	_, err = parseAPIRequestHeaders([]string{": value"})
+	assert.Error(t, err)
+	_, err = parseAPIRequestHeaders([]string{"Bad Name: value"})
+	assert.Error(t, err)
+}
+
+func TestFormatAPIResponseBody(t *testing.T) {
+	assert.Equal(t, "{\n  \"a\": 1\n}", formatAPIResponseBody([]byte(`{"a":1}`), true))
+	assert.Equal(t, `{"a":1}`, formatAPIResponseBody([]byte("{\"a\":1}\n"), false))
+	assert.Equal(t, "not json", formatAPIResponseBody([]byte("not json"), true))
+}
+
+func TestNormalizeAdminAPIPath(t *testing.T) {
+	path, err := normalizeAdminAPIPath("api/hello", "metaplay api nimbly GET")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/hello", path)
+
+	path, err = normalizeAdminAPIPath("/api/hello", "metaplay api nimbly GET")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/hello", path)
+
+	_, err = normalizeAdminAPIPath("C:/Program Files/Git/api/hello", "metaplay api nimbly GET")
+	assert.Error(t, err)
+}
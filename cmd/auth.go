@@ -13,9 +13,21 @@ var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Authenticate to Metaplay Cloud",
 	Long: `Commands related to authenticating with Metaplay Cloud.
-Supports sign in via a browser for human users and using a secret for machine users.`,
+Supports sign in via a browser for human users and using a secret for machine users.
+
+The credentials are stored in profiles, so that you can stay logged in to multiple accounts,
+eg, of different organizations. Select the profile with 'metaplay auth switch', with the
+METAPLAY_PROFILE environment variable, or with --profile for the auth commands.
+
+The refresh tokens are stored in the OS keyring when available. Run
+'metaplay auth tokens --show-location' to see where the credentials are stored.`,
 }
 
+// Credential profile to use for the auth commands (--profile).
+var flagAuthProfile string
+
 func init() {
 	rootCmd.AddCommand(authCmd)
+
+	authCmd.PersistentFlags().StringVar(&flagAuthProfile, "profile", "", "Credential profile to use, eg, 'work' [env: METAPLAY_PROFILE]")
 }
@@ -34,7 +34,22 @@ func init() {
 			'metaplay-project.yaml', you can specify the name of the provider you want to use with the
 			argument AUTH_PROVIDER.
 
+			The credentials are stored in the active profile (see 'metaplay auth switch'). Use
+			--profile to log in to another profile, eg, to stay logged in to the accounts of
+			multiple organizations at the same time.
+
 			{Arguments}
+
+			Related commands:
+			- 'metaplay auth switch PROFILE' to change the active profile.
+			- 'metaplay auth whoami' to show the logged in user.
+		`),
+		Example: renderExample(`
+			# Log in using the browser.
+			metaplay auth login
+
+			# Log in to the profile 'clientA'.
+			metaplay auth login --profile=clientA
 		`),
 		Run: runCommand(&o),
 	}
@@ -70,6 +85,7 @@ func (o *authLoginOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg("")
 	log.Info().Msgf("Project:       %s", styles.RenderTechnical(projectID))
 	log.Info().Msgf("Auth provider: %s", styles.RenderTechnical(authProvider.Name))
+	log.Info().Msgf("Profile:       %s", styles.RenderTechnical(auth.ResolveActiveProfile()))
 	log.Info().Msg("")
 
 	// Login using the active auth provider.
@@ -10,6 +10,7 @@ import (
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,7 @@ type authMachineLoginOpts struct {
 
 	argAuthProvider string
 	flagCredentials string
+	flagOIDC        bool
 }
 
 func init() {
@@ -37,17 +39,42 @@ func init() {
 			'metaplay-project.yaml', you can specify the name of the provider you want to use with the
 			argument AUTH_PROVIDER.
 
+			By default, the machine user's credentials are read from the METAPLAY_CREDENTIALS
+			environment variable. Alternatively, use --oidc to exchange the CI provider's OIDC ID
+			token for the machine user's credentials, so that no long-lived secrets need to be
+			stored in the CI system. This requires the trusted issuer and audience to be configured
+			in 'oidcFederation' in metaplay-project.yaml:
+
+			  oidcFederation:
+			    issuer: https://token.actions.githubusercontent.com
+			    audience: metaplay
+
+			On GitHub Actions, the ID token is requested automatically (the workflow needs the
+			'id-token: write' permission). On other CI providers, pass the ID token in the
+			METAPLAY_OIDC_TOKEN environment variable, eg, with the 'id_tokens' keyword on GitLab.
+
 			{Arguments}
 		`),
+		Example: renderExample(`
+			# Log in with the credentials in METAPLAY_CREDENTIALS.
+			metaplay auth machine-login
+
+			# Log in with the OIDC ID token of the GitHub Actions or GitLab CI job.
+			metaplay auth machine-login --oidc
+		`),
 		Run: runCommand(&o),
 	}
 	authCmd.AddCommand(cmd)
 
 	flags := cmd.Flags()
 	flags.StringVar(&o.flagCredentials, "dev-credentials", "", "Machine login credentials (prefer passing credentials via the environment variable METAPLAY_CREDENTIALS for better security)")
+	flags.BoolVar(&o.flagOIDC, "oidc", false, "Exchange the CI provider's OIDC ID token for the credentials instead of using METAPLAY_CREDENTIALS")
 }
 
 func (o *authMachineLoginOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagOIDC && o.flagCredentials != "" {
+		return clierrors.NewUsageError("--oidc and --dev-credentials cannot be used together")
+	}
 	return nil
 }
 
@@ -64,6 +91,11 @@ func (o *authMachineLoginOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Exchange the CI provider's ID token, if requested.
+	if o.flagOIDC {
+		return o.loginWithOIDC(cmd, project, authProvider)
+	}
+
 	// Resolve credentials to use.
 	var credentials string
 	if o.flagCredentials != "" {
@@ -72,8 +104,12 @@ func (o *authMachineLoginOpts) Run(cmd *cobra.Command) error {
 	} else {
 		log.Debug().Msg("Using environment variable METAPLAY_CREDENTIALS for machine login")
 		if envCredentials, ok := os.LookupEnv("METAPLAY_CREDENTIALS"); !ok {
+			suggestion := "Set the METAPLAY_CREDENTIALS environment variable to the credentials value from the developer portal"
+			if project != nil && project.Config.OIDCFederation != nil {
+				suggestion += ", or use --oidc to log in with the CI provider's OIDC ID token"
+			}
 			return clierrors.NewUsageError("Missing credentials for machine login").
-				WithSuggestion("Set the METAPLAY_CREDENTIALS environment variable to the credentials value from the developer portal")
+				WithSuggestion(suggestion)
 		} else {
 			credentials = envCredentials
 		}
@@ -98,3 +134,25 @@ func (o *authMachineLoginOpts) Run(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// loginWithOIDC logs in by exchanging the CI provider's OIDC ID token for the credentials of a
+// machine user, using the issuer and audience from the project config.
+func (o *authMachineLoginOpts) loginWithOIDC(cmd *cobra.Command, project *metaproj.MetaplayProject, authProvider *auth.AuthProviderConfig) error {
+	if project == nil {
+		return clierrors.NewUsageError("--oidc requires a project").
+			WithSuggestion("Run in the project directory or use --project to specify it")
+	}
+	federation := project.Config.OIDCFederation
+	if federation == nil {
+		return clierrors.New("OIDC federation is not configured for the project").
+			WithSuggestion("Add 'oidcFederation' with the trusted 'issuer' and 'audience' to metaplay-project.yaml")
+	}
+
+	idToken, source, err := auth.ResolveCIIDToken(cmd.Context(), federation.Audience)
+	if err != nil {
+		return err
+	}
+	log.Debug().Msgf("Using OIDC ID token from %s for machine login", source)
+
+	return auth.MachineLoginWithIDToken(authProvider, federation, idToken)
+}
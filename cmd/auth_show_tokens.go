@@ -11,6 +11,7 @@ import (
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -18,7 +19,8 @@ import (
 type authShowTokensOpts struct {
 	UsePositionalArgs
 
-	argAuthProvider string
+	argAuthProvider  string
+	flagShowLocation bool
 }
 
 func init() {
@@ -28,8 +30,9 @@ func init() {
 	args.AddStringArgumentOpt(&o.argAuthProvider, "AUTH_PROVIDER", "Name of the auth provider to use. Defaults to 'metaplay'.")
 
 	cmd := &cobra.Command{
-		Use:   "show-tokens [AUTH_PROVIDER]",
-		Short: "Print the active tokens as JSON to stdout",
+		Use:     "show-tokens [AUTH_PROVIDER] [flags]",
+		Aliases: []string{"tokens"},
+		Short:   "Print the active tokens as JSON to stdout",
 		Long: renderLong(&o, `
 			Print the currently active authentication tokens to stdout.
 
@@ -37,13 +40,27 @@ func init() {
 			'metaplay-project.yaml', you can specify the name of the provider you want to use with the
 			argument AUTH_PROVIDER.
 
+			Use --show-location to show where the credentials are stored instead of the tokens. The
+			long-lived refresh token is stored in the OS keyring (macOS Keychain, Windows Credential
+			Manager or Secret Service on Linux) when available. The short-lived tokens are stored in
+			a credentials file, encrypted with a key stored in the OS keyring. Without a keyring, eg,
+			on headless Linux machines, everything is stored in the credentials file, which is then
+			protected only by the filesystem permissions.
+
 			{Arguments}
 		`),
+		Example: renderExample(`
+			# Show where the credentials are stored.
+			metaplay auth tokens --show-location
+		`),
 		Run: runCommand(&o),
 	}
 
 	cmd.Hidden = true
 	authCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagShowLocation, "show-location", false, "Show where the credentials are stored instead of the tokens")
 }
 
 func (o *authShowTokensOpts) Prepare(cmd *cobra.Command, args []string) error {
@@ -87,6 +104,10 @@ func (o *authShowTokensOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	if o.flagShowLocation {
+		return o.showLocation(authProvider)
+	}
+
 	// Load tokenSet from keyring & refresh if needed.
 	tokenSet, err := auth.LoadAndRefreshTokenSet(authProvider)
 	if err != nil {
@@ -133,3 +154,39 @@ func (o *authShowTokensOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg(string(bytes))
 	return nil
 }
+
+// showLocation prints where the credentials of the session are stored.
+func (o *authShowTokensOpts) showLocation(authProvider *auth.AuthProviderConfig) error {
+	info, err := auth.DescribeSessionStorage(authProvider.GetSessionID())
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to resolve the credentials storage")
+	}
+	if info == nil {
+		return clierrors.New("Not logged in").
+			WithSuggestion("Sign in first with 'metaplay auth login' or 'metaplay auth machine-login'")
+	}
+
+	describeLocation := func(location auth.CredentialLocation) string {
+		switch location {
+		case auth.CredentialLocationKeyring:
+			return styles.RenderSuccess(info.KeyringName)
+		case auth.CredentialLocationFile:
+			return styles.RenderWarning("credentials file")
+		case auth.CredentialLocationFallbackKey:
+			return styles.RenderWarning("built-in key (protected by the file permissions only)")
+		default:
+			return styles.RenderError("missing")
+		}
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Credentials Storage"))
+	log.Info().Msg("")
+	log.Info().Msgf("Session:          %s", styles.RenderTechnical(info.SessionID))
+	log.Info().Msgf("User type:        %s", styles.RenderTechnical(string(info.UserType)))
+	log.Info().Msgf("Credentials file: %s", styles.RenderTechnical(info.CredentialsFile))
+	log.Info().Msgf("Refresh token:    %s", describeLocation(info.RefreshToken))
+	log.Info().Msgf("Encryption key:   %s", describeLocation(info.EncryptionKey))
+	log.Info().Msg("")
+	return nil
+}
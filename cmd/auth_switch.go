@@ -0,0 +1,136 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Switch the active credential profile, or list the profiles.
+type authSwitchOpts struct {
+	UsePositionalArgs
+
+	argProfile string
+}
+
+func init() {
+	o := authSwitchOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argProfile, "PROFILE", "Name of the profile to switch to, eg, 'work'. Lists the profiles if omitted.")
+
+	cmd := &cobra.Command{
+		Use:   "switch [PROFILE] [flags]",
+		Short: "Switch the active credential profile",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Switch the credential profile used by all the commands, or list the profiles.
+
+			Each profile has its own sessions, so you can stay logged in to the accounts of multiple
+			organizations at the same time and switch between them without logging out and in. The
+			profile named 'default' is used when no other profile is selected.
+
+			The METAPLAY_PROFILE environment variable takes precedence over the profile selected
+			with this command, eg, to use a different profile in a single shell.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay auth login --profile=PROFILE' to log in to a profile.
+			- 'metaplay auth logout --profile=PROFILE' to log out from a profile.
+			- 'metaplay auth whoami' to show the logged in user of the active profile.
+		`),
+		Example: renderExample(`
+			# List the profiles.
+			metaplay auth switch
+
+			# Log in to a profile and switch to it.
+			metaplay auth login --profile=clientA
+			metaplay auth switch clientA
+
+			# Switch back to the default profile.
+			metaplay auth switch default
+
+			# Use another profile for a single command.
+			METAPLAY_PROFILE=clientA metaplay get environment-info nimbly
+		`),
+	}
+
+	authCmd.AddCommand(cmd)
+}
+
+func (o *authSwitchOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if flagAuthProfile != "" {
+		return clierrors.NewUsageError("--profile cannot be used with 'metaplay auth switch'").
+			WithSuggestion("Give the profile as an argument, eg, 'metaplay auth switch " + flagAuthProfile + "'")
+	}
+	if o.argProfile != "" {
+		if err := auth.ValidateProfileName(o.argProfile); err != nil {
+			return clierrors.WrapUsageError(err, "Invalid profile name")
+		}
+	}
+	return nil
+}
+
+func (o *authSwitchOpts) Run(cmd *cobra.Command) error {
+	if o.argProfile == "" {
+		return o.listProfiles()
+	}
+
+	if err := auth.SwitchProfile(o.argProfile); err != nil {
+		return clierrors.Wrap(err, "Failed to switch the profile")
+	}
+	log.Info().Msgf("%s Switched to profile %s", styles.RenderSuccess("✓"), styles.RenderTechnical(o.argProfile))
+
+	// Let the user know if the switch has no effect in this shell.
+	if envProfile := os.Getenv(auth.ProfileEnvVar); envProfile != "" && envProfile != o.argProfile {
+		log.Warn().Msgf("%s=%s overrides the active profile in this shell", auth.ProfileEnvVar, envProfile)
+	}
+
+	// Hint about logging in to a new profile.
+	profiles, err := auth.ListProfiles()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if profile.Name == o.argProfile && len(profile.Providers) == 0 {
+			log.Info().Msgf("The profile has no sessions yet. Run %s to log in.", styles.RenderPrompt("metaplay auth login"))
+		}
+	}
+	return nil
+}
+
+// listProfiles prints the profiles and the auth providers logged in to in each of them.
+func (o *authSwitchOpts) listProfiles() error {
+	profiles, err := auth.ListProfiles()
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to list the profiles")
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Credential Profiles"))
+	log.Info().Msg("")
+	for _, profile := range profiles {
+		marker := " "
+		if profile.IsActive {
+			marker = styles.RenderSuccess("*")
+		}
+		sessions := styles.RenderMuted("(not logged in)")
+		if len(profile.Providers) > 0 {
+			sessions = styles.RenderMuted(strings.Join(profile.Providers, ", "))
+		}
+		log.Info().Msgf("%s %-16s %s", marker, profile.Name, sessions)
+	}
+	log.Info().Msg("")
+	log.Info().Msgf("Run %s to switch the profile.", styles.RenderPrompt("metaplay auth switch PROFILE"))
+	return nil
+}
@@ -7,8 +7,14 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -20,6 +26,39 @@ type authWhoamiOpts struct {
 
 	argAuthProvider string
 	flagFormat      string
+	flagPermissions bool
+}
+
+// whoamiPermissionsReport describes the identity of the signed in user and what they can access,
+// for 'metaplay auth whoami --permissions'.
+type whoamiPermissionsReport struct {
+	Name                 string               `json:"name"`
+	Email                string               `json:"email"`
+	UserType             auth.UserType        `json:"userType"`
+	ProviderID           string               `json:"providerId"`
+	Profile              string               `json:"profile"`
+	AccessTokenExpiresAt *time.Time           `json:"accessTokenExpiresAt"` // Nil if the expiry cannot be determined
+	HasRefreshToken      bool                 `json:"hasRefreshToken"`
+	Organizations        []whoamiOrganization `json:"organizations"`
+}
+
+type whoamiOrganization struct {
+	Name     string          `json:"name"`
+	Role     string          `json:"role"`
+	Projects []whoamiProject `json:"projects"`
+}
+
+type whoamiProject struct {
+	HumanID      string              `json:"humanId"`
+	Name         string              `json:"name"`
+	Environments []whoamiEnvironment `json:"environments,omitempty"` // Only for the projects that were inspected
+}
+
+type whoamiEnvironment struct {
+	HumanID string   `json:"humanId"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Roles   []string `json:"roles"` // Nil if the portal does not report the roles
 }
 
 func init() {
@@ -37,6 +76,11 @@ func init() {
 			By default, displays the information in a human-readable text format.
 			Use --format=json to get the complete user information in JSON format.
 
+			Use --permissions to also show the expiry of the tokens, and the organizations, projects
+			and environments you can access along with the roles granted in each environment, as
+			reported by the portal. Useful for finding out why a command fails with '403 Forbidden'.
+			When run in a project directory, only the environments of the project are shown.
+
 			The default auth provider is 'metaplay'. If you have multiple auth providers configured in your
 			'metaplay-project.yaml', you can specify the name of the provider you want to use with the
 			argument AUTH_PROVIDER.
@@ -50,6 +94,9 @@ func init() {
 			# Show complete user information in JSON format
 			metaplay auth whoami --format=json
 
+			# Show the accessible environments and the roles granted in them
+			metaplay auth whoami --permissions
+
 			# Show user information for a specific auth provider
 			metaplay auth whoami myAuthProvider
 		`),
@@ -58,6 +105,7 @@ func init() {
 
 	flags := cmd.Flags()
 	flags.StringVar(&o.flagFormat, "format", "text", "Output format. Valid values are 'text' or 'json'")
+	flags.BoolVar(&o.flagPermissions, "permissions", false, "Also show the token expiry and the accessible organizations, projects, environments and roles")
 
 	authCmd.AddCommand(cmd)
 }
@@ -68,6 +116,11 @@ func (o *authWhoamiOpts) Prepare(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid format %q, must be either 'text' or 'json'", o.flagFormat)
 	}
 
+	// Permissions are only known by the portal, ie, for Metaplay Auth sessions.
+	if o.flagPermissions && o.argAuthProvider != "" && o.argAuthProvider != "metaplay" {
+		return clierrors.NewUsageError("--permissions is only supported for the 'metaplay' auth provider")
+	}
+
 	return nil
 }
 
@@ -110,6 +163,23 @@ func (o *authWhoamiOpts) Run(cmd *cobra.Command) error {
 		log.Panic().Msgf("Failed to fetch user info: %v", err)
 	}
 
+	if o.flagPermissions {
+		report, err := buildWhoamiPermissionsReport(project, userInfo, sessionState.UserType, tokenSet)
+		if err != nil {
+			return err
+		}
+		if o.flagFormat == "json" {
+			reportJSON, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return clierrors.Wrap(err, "Failed to marshal the permissions report as JSON")
+			}
+			log.Info().Msg(string(reportJSON))
+		} else {
+			printWhoamiPermissionsReport(report)
+		}
+		return nil
+	}
+
 	// Output based on format
 	if o.flagFormat == "json" {
 		// Pretty-print as JSON
@@ -129,6 +199,7 @@ func (o *authWhoamiOpts) Run(cmd *cobra.Command) error {
 		log.Info().Msg("")
 		log.Info().Msgf("Project:       %s", styles.RenderTechnical(projectID))
 		log.Info().Msgf("Auth provider: %s", styles.RenderTechnical(authProvider.Name))
+		log.Info().Msgf("Profile:       %s", styles.RenderTechnical(auth.ResolveActiveProfile()))
 		log.Info().Msg("")
 		log.Info().Msgf("Name:        %s", styles.RenderTechnical(userInfo.Name))
 		log.Info().Msgf("Email:       %s", styles.RenderTechnical(userInfo.Email))
@@ -140,3 +211,132 @@ func (o *authWhoamiOpts) Run(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// buildWhoamiPermissionsReport fetches the accessible organizations, projects and environments,
+// and the roles granted in the environments from the portal. When in a project, only the
+// environments of the project are fetched.
+func buildWhoamiPermissionsReport(project *metaproj.MetaplayProject, userInfo *auth.UserInfoResponse, userType auth.UserType, tokenSet *auth.TokenSet) (*whoamiPermissionsReport, error) {
+	report := &whoamiPermissionsReport{
+		Name:            userInfo.Name,
+		Email:           userInfo.Email,
+		UserType:        userType,
+		ProviderID:      userInfo.Subject,
+		Profile:         auth.ResolveActiveProfile(),
+		HasRefreshToken: tokenSet.RefreshToken != "",
+		Organizations:   []whoamiOrganization{},
+	}
+	if expiresAt, err := auth.GetAccessTokenExpiresAt(tokenSet); err == nil {
+		report.AccessTokenExpiresAt = &expiresAt
+	}
+
+	portalClient := portalapi.NewClient(tokenSet)
+	orgs, err := portalClient.FetchUserOrgsAndProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, org := range orgs {
+		reportOrg := whoamiOrganization{Name: org.Name, Role: org.Role, Projects: []whoamiProject{}}
+		for _, projectInfo := range org.Projects {
+			reportProject := whoamiProject{HumanID: projectInfo.HumanID, Name: projectInfo.Name}
+			if project == nil || project.Config.ProjectHumanID == projectInfo.HumanID {
+				environments, err := fetchWhoamiEnvironments(portalClient, projectInfo.UUID)
+				if err != nil {
+					return nil, err
+				}
+				reportProject.Environments = environments
+			}
+			reportOrg.Projects = append(reportOrg.Projects, reportProject)
+		}
+		report.Organizations = append(report.Organizations, reportOrg)
+	}
+	return report, nil
+}
+
+// fetchWhoamiEnvironments fetches the environments of the project along with the roles granted
+// to the caller in each of them.
+func fetchWhoamiEnvironments(portalClient *portalapi.Client, projectUUID string) ([]whoamiEnvironment, error) {
+	envInfos, err := portalClient.FetchProjectEnvironments(projectUUID)
+	if err != nil {
+		return nil, err
+	}
+	envRoles, err := portalClient.FetchEnvironmentRoles(projectUUID)
+	if err != nil {
+		return nil, err
+	}
+	return mergeWhoamiEnvironmentRoles(envInfos, envRoles), nil
+}
+
+// mergeWhoamiEnvironmentRoles combines the environments with the roles granted in them. If the
+// roles are not known (nil), the roles of all the environments are left nil.
+func mergeWhoamiEnvironmentRoles(envInfos []portalapi.EnvironmentInfo, envRoles []portalapi.EnvironmentRoles) []whoamiEnvironment {
+	rolesByEnvUID := map[string][]string{}
+	for _, roles := range envRoles {
+		rolesByEnvUID[roles.EnvironmentUID] = roles.Roles
+	}
+
+	environments := make([]whoamiEnvironment, 0, len(envInfos))
+	for _, envInfo := range envInfos {
+		env := whoamiEnvironment{HumanID: envInfo.HumanID, Name: envInfo.Name, Type: string(envInfo.Type)}
+		if envRoles != nil {
+			env.Roles = rolesByEnvUID[envInfo.UID]
+			if env.Roles == nil {
+				env.Roles = []string{}
+			}
+		}
+		environments = append(environments, env)
+	}
+	return environments
+}
+
+// printWhoamiPermissionsReport prints the permissions report in human-readable format.
+func printWhoamiPermissionsReport(report *whoamiPermissionsReport) {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Identity"))
+	log.Info().Msg("")
+	log.Info().Msgf("Name:          %s", styles.RenderTechnical(report.Name))
+	log.Info().Msgf("Email:         %s", styles.RenderTechnical(coalesceString(report.Email, "n/a")))
+	log.Info().Msgf("User type:     %s", styles.RenderTechnical(string(report.UserType)))
+	log.Info().Msgf("Provider ID:   %s", styles.RenderTechnical(report.ProviderID))
+	log.Info().Msgf("Profile:       %s", styles.RenderTechnical(report.Profile))
+
+	accessTokenExpiry := styles.RenderMuted("unknown")
+	if report.AccessTokenExpiresAt != nil {
+		if report.AccessTokenExpiresAt.After(time.Now()) {
+			accessTokenExpiry = styles.RenderTechnical(fmt.Sprintf("expires %s (%s)", humanize.Time(*report.AccessTokenExpiresAt), report.AccessTokenExpiresAt.Local().Format(time.DateTime)))
+		} else {
+			accessTokenExpiry = styles.RenderWarning(fmt.Sprintf("expired %s", humanize.Time(*report.AccessTokenExpiresAt)))
+		}
+	}
+	refreshToken := styles.RenderTechnical("available")
+	if !report.HasRefreshToken {
+		refreshToken = styles.RenderWarning("none (log in again when the access token expires)")
+	}
+	log.Info().Msgf("Access token:  %s", accessTokenExpiry)
+	log.Info().Msgf("Refresh token: %s", refreshToken)
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Access"))
+	for _, org := range report.Organizations {
+		log.Info().Msg("")
+		log.Info().Msgf("%s %s", styles.RenderTechnical(org.Name), styles.RenderMuted(fmt.Sprintf("(organization role: %s)", coalesceString(org.Role, "n/a"))))
+		if len(org.Projects) == 0 {
+			log.Info().Msgf("  %s", styles.RenderMuted("no accessible projects"))
+		}
+		for _, project := range org.Projects {
+			log.Info().Msgf("  %s %s", styles.RenderTechnical(project.HumanID), styles.RenderMuted(project.Name))
+			for _, env := range project.Environments {
+				roles := styles.RenderMuted("roles not reported by the portal")
+				if env.Roles != nil {
+					if len(env.Roles) == 0 {
+						roles = styles.RenderWarning("no roles")
+					} else {
+						roles = strings.Join(env.Roles, ", ")
+					}
+				}
+				log.Info().Msgf("    %-40s %-12s %s", env.HumanID, env.Type, roles)
+			}
+		}
+	}
+	log.Info().Msg("")
+}
@@ -0,0 +1,35 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeWhoamiEnvironmentRoles(t *testing.T) {
+	envInfos := []portalapi.EnvironmentInfo{
+		{UID: "uid-1", HumanID: "tiny-squids", Name: "Develop", Type: portalapi.EnvironmentType("development")},
+		{UID: "uid-2", HumanID: "lovely-wombats", Name: "Production", Type: portalapi.EnvironmentType("production")},
+	}
+
+	// Roles reported for some of the environments.
+	envRoles := []portalapi.EnvironmentRoles{
+		{EnvironmentUID: "uid-1", Roles: []string{"game-admin", "developer"}},
+	}
+	environments := mergeWhoamiEnvironmentRoles(envInfos, envRoles)
+	assert.Equal(t, []whoamiEnvironment{
+		{HumanID: "tiny-squids", Name: "Develop", Type: "development", Roles: []string{"game-admin", "developer"}},
+		{HumanID: "lovely-wombats", Name: "Production", Type: "production", Roles: []string{}},
+	}, environments)
+
+	// Roles not supported by the portal.
+	environments = mergeWhoamiEnvironmentRoles(envInfos, nil)
+	assert.Len(t, environments, 2)
+	assert.Nil(t, environments[0].Roles)
+	assert.Nil(t, environments[1].Roles)
+}
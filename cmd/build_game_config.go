@@ -0,0 +1,181 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Kind of archive built by the project's game config builder.
+type configArchiveKind struct {
+	Name               string   // Human-readable name, eg, 'game config'
+	DefaultArchivePath string   // Default path of the built archive, relative to the Backend/Server directory
+	BuilderArgs        []string // Extra arguments to the builder to select the archive kind
+	UploadPath         string   // Admin API endpoint for uploading the archive
+	PublishPath        string   // Admin API endpoint for activating an uploaded archive, formatted with the id
+}
+
+// The game config archive.
+var gameConfigArchiveKind = configArchiveKind{
+	Name:               "game config",
+	DefaultArchivePath: "GameConfig/StaticGameConfig.mpa",
+	UploadPath:         "/api/gameConfig",
+	PublishPath:        "/api/gameConfig/%s/publish",
+}
+
+// Build the game config archive using the project's game config builder.
+type buildGameConfigOpts struct {
+	UsePositionalArgs
+
+	extraArgs  []string
+	flagOutput string
+}
+
+func init() {
+	o := buildGameConfigOpts{}
+
+	args := o.Arguments()
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to the game config builder.")
+
+	cmd := &cobra.Command{
+		Use:   "game-config [flags] [-- EXTRA_ARGS]",
+		Short: "Build the game config archive",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Build the game config archive by running the project's game config builder.
+
+			This command:
+			- Verifies the required .NET SDK version is installed
+			- Runs the game config builder with 'dotnet run' against the shared code directory
+			- Verifies that the game config archive was produced
+
+			The game config builder is the .NET project in 'gameConfigBuilderDir' of the
+			metaplay-project.yaml, or the Backend/Server project if not specified. The builder is
+			invoked with '--shared-code-dir <dir> --output <file>' followed by any extra arguments.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy game-config ...' to build and publish the game config to an environment.
+			- 'metaplay build server' to build the game server .NET project.
+		`),
+		Example: renderExample(`
+			# Build the game config archive into the default location.
+			metaplay build game-config
+
+			# Build the game config archive into a custom location.
+			metaplay build game-config --output build/StaticGameConfig.mpa
+
+			# Pass extra arguments to the game config builder.
+			metaplay build game-config -- --verbose
+		`),
+	}
+	buildCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Path to write the game config archive to (default: Backend/Server/GameConfig/StaticGameConfig.mpa)")
+}
+
+func (o *buildGameConfigOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *buildGameConfigOpts) Run(cmd *cobra.Command) error {
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Build Game Config"))
+	log.Info().Msg("")
+
+	archivePath, err := buildConfigArchive(cmd.Context(), project, gameConfigArchiveKind, o.flagOutput, o.extraArgs)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("%s Game config archive built successfully: %s", styles.RenderSuccess("✓"), styles.RenderTechnical(archivePath))
+	return nil
+}
+
+// resolveConfigArchivePath returns the path to write the archive to: the given path if
+// non-empty, otherwise the default location of the archive kind in the Backend/Server directory.
+func resolveConfigArchivePath(project *metaproj.MetaplayProject, kind configArchiveKind, outputPath string) string {
+	if outputPath != "" {
+		return outputPath
+	}
+	return filepath.Join(project.GetServerDir(), filepath.FromSlash(kind.DefaultArchivePath))
+}
+
+// getConfigBuilderArgs returns the 'dotnet run' arguments for invoking the game config builder.
+// The paths must be absolute as the builder is run in its own directory.
+func getConfigBuilderArgs(kind configArchiveKind, sharedCodeDir string, archivePath string, extraArgs []string) []string {
+	builderArgs := []string{"run", "--"}
+	builderArgs = append(builderArgs, kind.BuilderArgs...)
+	builderArgs = append(builderArgs, "--shared-code-dir", sharedCodeDir, "--output", archivePath)
+	return append(builderArgs, extraArgs...)
+}
+
+// buildConfigArchive runs the project's game config builder to produce the archive of the
+// given kind. Returns the path to the built archive.
+func buildConfigArchive(ctx context.Context, project *metaproj.MetaplayProject, kind configArchiveKind, outputPath string, extraArgs []string) (string, error) {
+	// Check for .NET SDK installation and required version (based on SDK version).
+	if err := checkDotnetSdkVersion(ctx, project.VersionMetadata.MinDotnetSdkVersion); err != nil {
+		return "", clierrors.Wrap(err, "Failed to verify .NET SDK version").
+			WithSuggestion("Install the required .NET SDK version")
+	}
+
+	// Check that the builder project exists.
+	builderDir := project.GetGameConfigBuilderDir()
+	if info, err := os.Stat(builderDir); err != nil || !info.IsDir() {
+		return "", clierrors.Newf("Game config builder directory '%s' not found", builderDir).
+			WithSuggestion("Specify the builder project with 'gameConfigBuilderDir' in metaplay-project.yaml")
+	}
+
+	// Resolve absolute paths for the builder.
+	sharedCodeDir, err := filepath.Abs(project.GetSharedCodeDir())
+	if err != nil {
+		return "", clierrors.Wrap(err, "Failed to resolve shared code directory")
+	}
+	archivePath, err := filepath.Abs(resolveConfigArchivePath(project, kind, outputPath))
+	if err != nil {
+		return "", clierrors.Wrapf(err, "Failed to resolve %s archive path", kind.Name)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return "", clierrors.Wrapf(err, "Failed to create directory for %s archive %s", kind.Name, archivePath)
+	}
+
+	// Remove any stale archive so that a failed build can't be mistaken for a successful one.
+	if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+		return "", clierrors.Wrapf(err, "Failed to remove previous %s archive %s", kind.Name, archivePath)
+	}
+
+	// Run the game config builder.
+	log.Debug().Msgf("Building %s in %s", kind.Name, builderDir)
+	if err := execChildTask(ctx, builderDir, "dotnet", getConfigBuilderArgs(kind, sharedCodeDir, archivePath, extraArgs)); err != nil {
+		return "", clierrors.Wrapf(err, "Failed to build the %s", kind.Name).
+			WithSuggestion("Check the build output above for details")
+	}
+
+	// Check that the builder produced the archive.
+	info, err := os.Stat(archivePath)
+	if err != nil || info.IsDir() {
+		return "", clierrors.Newf("Game config builder did not produce the %s archive %s", kind.Name, archivePath).
+			WithSuggestion("Check that the game config builder supports the '--output' argument")
+	}
+
+	return archivePath, nil
+}
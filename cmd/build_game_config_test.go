@@ -0,0 +1,35 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveConfigArchivePath(t *testing.T) {
+	project := &metaproj.MetaplayProject{
+		RelativeDir: "project",
+		Config:      metaproj.ProjectConfig{BackendDir: "Backend"},
+	}
+
+	assert.Equal(t, filepath.Join("project", "Backend", "Server", "GameConfig", "StaticGameConfig.mpa"), resolveConfigArchivePath(project, gameConfigArchiveKind, ""))
+	assert.Equal(t, "out/Config.mpa", resolveConfigArchivePath(project, gameConfigArchiveKind, "out/Config.mpa"))
+}
+
+func TestGetConfigBuilderArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"run", "--", "--shared-code-dir", "/project/Assets/SharedCode", "--output", "/project/Config.mpa"},
+		getConfigBuilderArgs(gameConfigArchiveKind, "/project/Assets/SharedCode", "/project/Config.mpa", nil))
+	assert.Equal(t,
+		[]string{"run", "--", "--shared-code-dir", "/shared", "--output", "/out.mpa", "--verbose"},
+		getConfigBuilderArgs(gameConfigArchiveKind, "/shared", "/out.mpa", []string{"--verbose"}))
+	assert.Equal(t,
+		[]string{"run", "--", "--localizations", "--shared-code-dir", "/shared", "--output", "/out.mpa"},
+		getConfigBuilderArgs(localizationsArchiveKind, "/shared", "/out.mpa", nil))
+}
@@ -7,17 +7,23 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/metaplay/cli/internal/envutil"
 	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
 	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
@@ -28,6 +34,74 @@ import (
 // cross-architecture builds. 28.0.0 was published in Feb 2025.
 var recommendedDockerEngineVersion = semver.MustParse("28.0.0")
 
+// Stages in the SDK's Dockerfile.server that can be built as images of their own.
+const (
+	dockerStageBotClient          = "botclient"
+	dockerStagePlaywrightTsTests  = "playwright-ts-tests"
+	dockerStagePlaywrightNetTests = "playwright-net-tests"
+)
+
+// buildImageTarget is a kind of image that 'metaplay build image' can build.
+type buildImageTarget struct {
+	Name         string   // Name used with --target, eg, 'botclient'.
+	Aliases      []string // Alternative names accepted by --target.
+	DockerStage  string   // Dockerfile stage to build (empty for the final stage).
+	ImageSuffix  string   // Suffix appended to the project ID for the default image name.
+	Description  string   // Human-readable description of the image contents.
+	IsDeployable bool     // Can the image be deployed with 'metaplay deploy server'?
+	IsDashboard  bool     // Is the image built from the locally built custom dashboard instead of the SDK's Dockerfile?
+}
+
+// Targets supported by 'metaplay build image --target'. The first one is the default.
+var buildImageTargets = []buildImageTarget{
+	{
+		Name:         "server",
+		Description:  "Game server, LiveOps Dashboard, and BotClient",
+		IsDeployable: true,
+	},
+	{
+		Name:        "botclient",
+		DockerStage: dockerStageBotClient,
+		ImageSuffix: "-botclient",
+		Description: "BotClient only, eg, for running soak tests in the cloud",
+	},
+	{
+		Name:        "dashboard",
+		ImageSuffix: "-dashboard",
+		Description: "Custom LiveOps Dashboard only, for hosting it separately from the game server",
+		IsDashboard: true,
+	},
+	{
+		Name:        "dashboard-tests",
+		Aliases:     []string{dockerStagePlaywrightTsTests},
+		DockerStage: dockerStagePlaywrightTsTests,
+		ImageSuffix: "-dashboard-tests",
+		Description: "Playwright (TypeScript) tests for the LiveOps Dashboard",
+	},
+	{
+		Name:        "playwright-net",
+		Aliases:     []string{dockerStagePlaywrightNetTests},
+		DockerStage: dockerStagePlaywrightNetTests,
+		ImageSuffix: "-playwright-net-tests",
+		Description: "Playwright.NET system tests",
+	},
+}
+
+// resolveBuildImageTarget finds the build target by its name or alias (case-insensitive).
+func resolveBuildImageTarget(name string) (*buildImageTarget, error) {
+	names := []string{}
+	for ndx := range buildImageTargets {
+		target := &buildImageTargets[ndx]
+		if strings.EqualFold(target.Name, name) || slices.ContainsFunc(target.Aliases, func(alias string) bool { return strings.EqualFold(alias, name) }) {
+			return target, nil
+		}
+		names = append(names, target.Name)
+	}
+
+	return nil, clierrors.NewUsageErrorf("Invalid build target '%s'", name).
+		WithSuggestion(fmt.Sprintf("Use one of: %s", strings.Join(names, ", ")))
+}
+
 // Build docker image for the project.
 type buildImageOpts struct {
 	UsePositionalArgs
@@ -36,8 +110,15 @@ type buildImageOpts struct {
 	extraArgs         []string
 	flagBuildEngine   string
 	flagArchitectures []string
+	flagPlatforms     []string
 	flagCommitID      string
 	flagBuildNumber   string
+	flagTarget        string
+	flagRegistry      string
+	flagSBOM          bool
+	flagProvenance    bool
+
+	target *buildImageTarget
 }
 
 func init() {
@@ -57,11 +138,47 @@ func init() {
 			The built image contains both the game server (C# project), the LiveOps
 			Dashboard, and the BotClient.
 
+			Use --target to build one of the other images:
+			- 'server' (default): game server, LiveOps Dashboard, and BotClient, named '<projectID>'.
+			- 'botclient': BotClient only, named '<projectID>-botclient'.
+			- 'dashboard': the project's custom LiveOps Dashboard only, named '<projectID>-dashboard'.
+			- 'dashboard-tests': Playwright tests for the LiveOps Dashboard, named '<projectID>-dashboard-tests'.
+			- 'playwright-net': Playwright.NET system tests, named '<projectID>-playwright-net-tests'.
+
+			The 'dashboard' target is for projects that host their custom LiveOps Dashboard separately
+			from the game server. The dashboard is first built locally like with 'metaplay build dashboard',
+			which checks that Node.js and pnpm satisfy the SDK's version requirements, and the build output
+			is then packaged into an image that serves it with nginx on port 8080. The game server image
+			always includes the dashboard, so the dashboard in the cloud environments is updated by
+			deploying the server image.
+
+			The default image names can be customized with the 'imageNaming' template in
+			metaplay-project.yaml, eg, '{registry}/{project}/{component}:{date}-{commit}'. The
+			supported placeholders are {registry} (from --registry), {project}, {component} (the
+			build target), {tag}, {date}, {commit}, and {buildNumber}.
+
+			By default, the image is built for linux/amd64. Use --architecture=arm64 (or
+			--platforms=linux/arm64) for ARM-based clusters, eg, AWS Graviton. Specifying both
+			architectures builds a multi-arch image with buildx, which requires the containerd
+			image store to be enabled in Docker. 'metaplay deploy server' checks that the image
+			includes the architecture of the target environment's nodes.
+
+			Use --sbom to attach a software bill of materials (SPDX, generated by BuildKit) and
+			--provenance to attach a SLSA provenance attestation to the image. The attestations
+			are generated by buildx and require the containerd image store to be enabled in Docker.
+			They are pushed along with the image, and 'metaplay deploy server' verifies and shows
+			them. A different SBOM generator, eg, one producing CycloneDX, can be used by passing
+			'--attest type=sbom,generator=<image>' to docker as extra arguments.
+
+			The image is built locally, so it is signed with cosign only when pushing it with
+			'metaplay image push ... --sign'. If the project enables 'requireSignedImages', the
+			image must be pushed and signed before deploying it.
+
 			{Arguments}
 
 			Related commands:
 			- 'metaplay deploy server ...' to push and deploy the game server image into a cloud environment.
-			- 'metaplay image push ...' to push the built image into a target environment's registry.
+			- 'metaplay image push ...' to push (and optionally sign) the built image into a target environment's registry.
 		`),
 		Example: renderExample(`
 			# Build Docker image, produces image named '<projectID>:YYYYMMDD-HHMMSS-COMMIT_ID'.
@@ -83,8 +200,20 @@ func init() {
 			# Build a multi-arch image for both amd64 and arm64 (only supported with 'buildx').
 			metaplay build image mygame:364cff09 --architecture=amd64,arm64
 
+			# Same as above, using Docker's platform syntax.
+			metaplay build image mygame:364cff09 --platforms=linux/amd64,linux/arm64
+
+			# Attach SBOM and SLSA provenance attestations to the image (only supported with 'buildx').
+			metaplay build image mygame:364cff09 --sbom --provenance
+
 			# Pass extra arguments to the docker build.
 			metaplay build image mygame:364cff09 -- --build-arg FOO=BAR
+
+			# Build only the BotClient, produces image named '<projectID>-botclient:364cff09'.
+			metaplay build image 364cff09 --target=botclient
+
+			# Build only the custom LiveOps Dashboard, produces image named '<projectID>-dashboard:364cff09'.
+			metaplay build image 364cff09 --target=dashboard
 		`),
 	}
 
@@ -93,20 +222,22 @@ func init() {
 	flags := cmd.Flags()
 	flags.StringVar(&o.flagBuildEngine, "engine", "buildx", "Docker build engine to use ('buildx' or 'buildkit' [deprecated])")
 	flags.StringSliceVar(&o.flagArchitectures, "architecture", []string{"amd64"}, "Architectures of build targets (comma-separated), eg, 'amd64' or 'amd64,arm64'")
+	flags.StringSliceVar(&o.flagPlatforms, "platforms", nil, "Platforms of build targets (comma-separated), eg, 'linux/amd64,linux/arm64' (alternative to --architecture)")
 	flags.StringVar(&o.flagCommitID, "commit-id", "", "Git commit SHA hash or similar, eg, '7d1ebc858b'")
 	flags.StringVar(&o.flagBuildNumber, "build-number", "", "Number identifying this build, eg, '715'")
+	flags.StringVar(&o.flagTarget, "target", "server", "Image to build: 'server', 'botclient', 'dashboard', 'dashboard-tests', or 'playwright-net'")
+	flags.BoolVar(&o.flagSBOM, "sbom", false, "Attach an SBOM attestation (SPDX) to the image (only supported with 'buildx')")
+	flags.BoolVar(&o.flagProvenance, "provenance", false, "Attach a SLSA provenance attestation to the image (only supported with 'buildx')")
+	flags.StringVar(&o.flagRegistry, "registry", "", "Registry to prefix the image name with, eg, 'registry.example.com/team' (fills in {registry} in 'imageNaming')")
 }
 
 func (o *buildImageOpts) Prepare(cmd *cobra.Command, args []string) error {
-	// Handle image name.
-	if o.argImageName == "" {
-		o.argImageName = "<projectID>:<autotag>"
-	} else if strings.Contains(o.argImageName, ":") {
-		// Full name specified, use as-is
-	} else {
-		// Only tag specified, prefix with projectID
-		o.argImageName = fmt.Sprintf("<projectID>:%s", o.argImageName)
+	// Resolve the build target.
+	target, err := resolveBuildImageTarget(o.flagTarget)
+	if err != nil {
+		return err
 	}
+	o.target = target
 
 	return nil
 }
@@ -123,6 +254,12 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// The dashboard image can only be built for custom dashboards.
+	if o.target.IsDashboard && !project.UsesCustomDashboard() {
+		return clierrors.New("Project does not have a custom dashboard to build an image of").
+			WithSuggestion("Initialize a custom dashboard with 'metaplay init dashboard'")
+	}
+
 	// Log extra arguments.
 	if len(o.extraArgs) > 0 {
 		log.Debug().Msgf("Extra args to docker: %s", strings.Join(o.extraArgs, " "))
@@ -162,19 +299,11 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 		}
 	}
 
-	// Resolve image name to use: fill in <autotag> with YYYYMMDD-HHMMSS[-COMMIT_ID]
-	// and <projectID> with the project's human ID.
-	log.Debug().Msgf("Image name template: %s", o.argImageName)
-	imageName := o.argImageName
-	if strings.Contains(imageName, "<autotag>") {
-		// Generate auto-tag in format YYYYMMDD-HHMMSS[-COMMIT_ID]
-		autoTag := time.Now().UTC().Format("20060102-150405")
-		if commitID != "" && commitID != "none" {
-			autoTag = fmt.Sprintf("%s-%s", autoTag, commitID)
-		}
-		imageName = strings.ReplaceAll(imageName, "<autotag>", autoTag)
+	// Resolve image name to use.
+	imageName, err := resolveBuildImageName(project, o.target, o.argImageName, o.flagRegistry, commitID, buildNumber, time.Now())
+	if err != nil {
+		return err
 	}
-	imageName = strings.ReplaceAll(imageName, "<projectID>", project.Config.ProjectHumanID)
 
 	if strings.HasSuffix(imageName, ":latest") {
 		return clierrors.New("Cannot build image with tag 'latest'").
@@ -212,30 +341,50 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	// Validate target architectures.
-	validArchitectures := []string{"amd64", "arm64"}
-	if len(o.flagArchitectures) == 0 {
-		return clierrors.NewUsageError("No target architecture specified").
-			WithSuggestion("Use --architecture=amd64 or --architecture=arm64")
+	// Resolve and validate target platforms.
+	if cmd.Flags().Changed("architecture") && cmd.Flags().Changed("platforms") {
+		return clierrors.NewUsageError("Only one of --architecture and --platforms can be specified")
 	}
-	for _, arch := range o.flagArchitectures {
-		if !slices.Contains(validArchitectures, arch) {
-			return clierrors.NewUsageErrorf("Invalid architecture '%s'", arch).
-				WithDetails(fmt.Sprintf("Valid architectures: %v", validArchitectures)).
-				WithSuggestion("Use --architecture=amd64 or --architecture=arm64")
-		}
+	platforms, err := resolveBuildPlatforms(o.flagArchitectures, o.flagPlatforms)
+	if err != nil {
+		return err
 	}
 
-	// Only buildx supports building multiple architectures at once.
-	if buildEngine == "buildkit" && len(o.flagArchitectures) > 1 {
+	// Only buildx supports building multiple platforms at once.
+	if buildEngine == "buildkit" && len(platforms) > 1 {
 		return clierrors.NewUsageError("BuildKit does not support multi-architecture builds").
 			WithSuggestion("Use --engine=buildx for multi-arch builds, or build for only one architecture")
 	}
 
-	// Resolve target platforms.
-	platforms := []string{}
-	for _, arch := range o.flagArchitectures {
-		platforms = append(platforms, fmt.Sprintf("linux/%s", arch))
+	// Only buildx supports attestations.
+	if buildEngine == "buildkit" && (o.flagSBOM || o.flagProvenance) {
+		return clierrors.NewUsageError("BuildKit does not support SBOM and provenance attestations").
+			WithSuggestion("Use --engine=buildx to attach attestations to the image")
+	}
+	attestations := []string{}
+	if o.flagSBOM {
+		attestations = append(attestations, "sbom")
+	}
+	if o.flagProvenance {
+		attestations = append(attestations, "provenance")
+	}
+
+	// Detect uncommitted changes in the git working tree, so that deploying unreproducible
+	// builds into production can be prevented.
+	labels := map[string]string{}
+	gitStateStr := "unknown"
+	gitStateBadge := styles.RenderMuted("(not a git repository)")
+	if isDirty, err := isGitWorkingTreeDirty(ctx, project.RelativeDir); err != nil {
+		log.Debug().Msgf("Unable to check git working tree state: %v", err)
+	} else {
+		labels[envapi.DirtyImageLabel] = strconv.FormatBool(isDirty)
+		gitStateBadge = ""
+		if isDirty {
+			gitStateStr = "dirty"
+			gitStateBadge = styles.RenderWarning("[uncommitted changes; deploying into production requires --allow-dirty]")
+		} else {
+			gitStateStr = "clean"
+		}
 	}
 
 	// Resolve Docker version string and badge for the build summary.
@@ -253,10 +402,13 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 	// Print build info.
 	log.Info().Msgf("Project ID:          %s", styles.RenderTechnical(project.Config.ProjectHumanID))
 	log.Info().Msgf("Metaplay SDK:        %s", styles.RenderTechnical(project.VersionMetadata.SdkVersion.String()))
+	log.Info().Msgf("Build target:        %s %s", styles.RenderTechnical(o.target.Name), styles.RenderMuted(fmt.Sprintf("(%s)", o.target.Description)))
 	log.Info().Msgf("Docker image:        %s", styles.RenderTechnical(imageName))
 	log.Info().Msgf("Commit ID            %s %s", styles.RenderTechnical(commitID), commitIDBadge)
 	log.Info().Msgf("Build number:        %s %s", styles.RenderTechnical(buildNumber), buildNumberBadge)
+	log.Info().Msgf("Git working tree:    %s %s", styles.RenderTechnical(gitStateStr), gitStateBadge)
 	log.Info().Msgf("Target platform(s):  %s", styles.RenderTechnical(strings.Join(platforms, ", ")))
+	log.Info().Msgf("Attestations:        %s", styles.RenderTechnical(coalesceString(strings.Join(attestations, ", "), "none")))
 	log.Info().Msgf("Docker version:      %s %s", styles.RenderTechnical(dockerVersionStr), dockerVersionBadge)
 	log.Info().Msgf("Docker build engine: %s", styles.RenderTechnical(buildEngine))
 
@@ -269,17 +421,39 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 		commitID:    commitID,
 		buildNumber: buildNumber,
 		extraArgs:   o.extraArgs,
+		target:      o.target.DockerStage,
+		labels:      labels,
+		sbom:        o.flagSBOM,
+		provenance:  o.flagProvenance,
 	}
 
-	if err := buildDockerImage(ctx, buildParams); err != nil {
+	if o.target.IsDashboard {
+		// Build the dashboard locally (with Node.js and pnpm version checks) and package the output.
+		buildDashboard := buildDashboardOpts{}
+		if err := buildDashboard.Run(cmd); err != nil {
+			return err
+		}
+		if err := buildDashboardDockerImage(ctx, buildParams, filepath.Join(project.GetDashboardDir(), "dist")); err != nil {
+			return err
+		}
+	} else if err := buildDockerImage(ctx, buildParams); err != nil {
 		return err
 	}
 
 	log.Info().Msg("")
 	log.Info().Msgf("✅ %s %s", styles.RenderSuccess("Successfully built docker image"), styles.RenderTechnical(imageName))
 	log.Info().Msg("")
-	log.Info().Msg("You can deploy the image to a cloud environment using:")
-	log.Info().Msgf(styles.RenderTechnical("  metaplay deploy server ENVIRONMENT %s"), imageName)
+	if o.target.IsDeployable && project.Config.RequireSignedImages {
+		log.Info().Msg("The project requires signed images: push and sign the image, then deploy it using:")
+		log.Info().Msgf(styles.RenderTechnical("  metaplay image push ENVIRONMENT %s --sign"), imageName)
+		log.Info().Msgf(styles.RenderTechnical("  metaplay deploy server ENVIRONMENT %s"), imageName)
+	} else if o.target.IsDeployable {
+		log.Info().Msg("You can deploy the image to a cloud environment using:")
+		log.Info().Msgf(styles.RenderTechnical("  metaplay deploy server ENVIRONMENT %s"), imageName)
+	} else {
+		log.Info().Msg("You can push the image to a cloud environment's registry using:")
+		log.Info().Msgf(styles.RenderTechnical("  metaplay image push ENVIRONMENT %s"), imageName)
+	}
 
 	envsIDs := []string{}
 	for _, env := range project.Config.Environments {
@@ -290,6 +464,117 @@ func (o *buildImageOpts) Run(cmd *cobra.Command) error {
 	return nil
 }
 
+// Architectures that the game server images can be built for.
+var validBuildArchitectures = []string{"amd64", "arm64"}
+
+// resolveBuildPlatforms resolves the target platforms, eg, 'linux/amd64', from either the
+// --platforms or the --architecture flag values. The platforms take precedence when given.
+func resolveBuildPlatforms(architectures []string, platforms []string) ([]string, error) {
+	// Convert the platforms to architectures so both flags are validated the same way.
+	if len(platforms) > 0 {
+		architectures = []string{}
+		for _, platform := range platforms {
+			arch, found := strings.CutPrefix(platform, "linux/")
+			if !found {
+				return nil, clierrors.NewUsageErrorf("Invalid platform '%s'", platform).
+					WithSuggestion("Use --platforms=linux/amd64, --platforms=linux/arm64, or --platforms=linux/amd64,linux/arm64")
+			}
+			architectures = append(architectures, arch)
+		}
+	}
+
+	if len(architectures) == 0 {
+		return nil, clierrors.NewUsageError("No target architecture specified").
+			WithSuggestion("Use --architecture=amd64 or --architecture=arm64")
+	}
+
+	result := []string{}
+	for _, arch := range architectures {
+		if !slices.Contains(validBuildArchitectures, arch) {
+			return nil, clierrors.NewUsageErrorf("Invalid architecture '%s'", arch).
+				WithDetails(fmt.Sprintf("Valid architectures: %v", validBuildArchitectures)).
+				WithSuggestion("Use --architecture=amd64 or --architecture=arm64")
+		}
+		platform := fmt.Sprintf("linux/%s", arch)
+		if !slices.Contains(result, platform) {
+			result = append(result, platform)
+		}
+	}
+	return result, nil
+}
+
+// resolveBuildImageName resolves the name of the image to build from the IMAGE argument:
+//   - 'NAME:TAG' is used as-is.
+//   - 'TAG' uses the given tag with the default image name of the target.
+//   - An empty argument uses the default image name and an auto-generated tag
+//     'YYYYMMDD-HHMMSS[-COMMIT_ID]'.
+//
+// The default image name comes from the 'imageNaming' template in metaplay-project.yaml,
+// or is '<projectID>' (with a target-specific suffix) if no template is specified.
+func resolveBuildImageName(project *metaproj.MetaplayProject, target *buildImageTarget, imageArg, registry, commitID, buildNumber string, now time.Time) (string, error) {
+	// Full name specified, use as-is.
+	if strings.Contains(imageArg, ":") {
+		return imageArg, nil
+	}
+
+	// Generate auto-tag in format YYYYMMDD-HHMMSS[-COMMIT_ID]
+	date := now.UTC().Format("20060102-150405")
+	if commitID == "none" {
+		commitID = ""
+	}
+	if buildNumber == "none" {
+		buildNumber = ""
+	}
+	autoTag := date
+	if commitID != "" {
+		autoTag = fmt.Sprintf("%s-%s", autoTag, commitID)
+	}
+
+	// Without a naming template, use '[REGISTRY/]<projectID>[-SUFFIX]:TAG'.
+	if project.Config.ImageNaming == "" {
+		repository := project.Config.ProjectHumanID + target.ImageSuffix
+		if registry != "" {
+			repository = strings.TrimSuffix(registry, "/") + "/" + repository
+		}
+		return fmt.Sprintf("%s:%s", repository, coalesceString(imageArg, autoTag)), nil
+	}
+
+	imageName, err := project.RenderImageName(metaproj.ImageNameParams{
+		Registry:    registry,
+		Component:   target.Name,
+		Tag:         coalesceString(imageArg, autoTag),
+		Date:        date,
+		Commit:      commitID,
+		BuildNumber: buildNumber,
+	})
+	if err != nil {
+		return "", clierrors.Wrap(err, "Failed to resolve image name from 'imageNaming' in metaplay-project.yaml")
+	}
+
+	// An explicitly specified tag takes precedence over the tag from the template.
+	if imageArg != "" {
+		imageName = replaceImageTag(imageName, imageArg)
+	}
+	return imageName, nil
+}
+
+// replaceImageTag replaces the tag of the 'NAME:TAG' image name.
+func replaceImageTag(imageName, tag string) string {
+	return imageName[:strings.LastIndex(imageName, ":")+1] + tag
+}
+
+// resolveProjectImageName resolves the name of the project's image of the component with the
+// given tag, eg, of an image built earlier with 'metaplay build image', using the 'imageNaming'
+// template in metaplay-project.yaml. The template must be set. The build date and commit are
+// parsed from the tag when it is in the default '<date>-<commit>' format.
+func resolveProjectImageName(project *metaproj.MetaplayProject, component, tag string) (string, error) {
+	imageName, err := project.RenderImageNameWithTag(metaproj.ImageNameParams{Component: component}, tag)
+	if err != nil {
+		return "", clierrors.Wrap(err, "Failed to resolve image name from 'imageNaming' in metaplay-project.yaml")
+	}
+	return imageName, nil
+}
+
 // Find the first non-empty environment variable from a list of keys.
 // If none of the keys have a value, return an empty string.
 func detectEnvVar(keys []string) string {
@@ -543,6 +828,10 @@ type buildDockerImageParams struct {
 	buildNumber string                    // Build number to use for the build
 	extraArgs   []string                  // Extra arguments to pass to docker build
 	target      string                    // Optional: Dockerfile stage to build
+	dockerFile  string                    // Optional: Dockerfile to build (defaults to the SDK's Dockerfile.server)
+	labels      map[string]string         // Optional: Extra labels to add to the image
+	sbom        bool                      // Optional: Attach an SBOM attestation (buildx only)
+	provenance  bool                      // Optional: Attach a SLSA provenance attestation (buildx only)
 }
 
 // buildDockerImage builds a Docker image with the given parameters.
@@ -558,7 +847,13 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 	}
 
 	dockerFilePath := filepath.Join(sdkRootPath, "Dockerfile.server")
-	if _, err := os.Stat(dockerFilePath); os.IsNotExist(err) {
+	if params.dockerFile != "" {
+		dockerFilePath = params.dockerFile
+		if _, err := os.Stat(dockerFilePath); os.IsNotExist(err) {
+			return clierrors.Newf("Cannot find Dockerfile at %s", dockerFilePath).
+				WithSuggestion("Check that the Dockerfile path in metaplay-project.yaml points to the correct location")
+		}
+	} else if _, err := os.Stat(dockerFilePath); os.IsNotExist(err) {
 		return clierrors.Newf("Cannot find Dockerfile.server at %s", dockerFilePath).
 			WithSuggestion("Make sure the Metaplay SDK is properly installed")
 	}
@@ -584,7 +879,7 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 	}
 	rebasedDockerFilePath, err := rebasePath(dockerFilePath, buildRootDir)
 	if err != nil {
-		return clierrors.Wrap(err, "Failed to resolve path to Dockerfile.server from build root")
+		return clierrors.Wrapf(err, "Failed to resolve path to %s from build root", filepath.Base(dockerFilePath))
 	}
 	rebasedProjectRoot, err := rebasePath(params.project.RelativeDir, buildRootDir)
 	if err != nil {
@@ -606,16 +901,7 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 	dockerEnv = append(dockerEnv, "DOCKER_CLI_HINTS=false")
 
 	// Handle build engine differences.
-	var buildEngineArgs []string
-	switch params.buildEngine {
-	case "buildkit":
-		dockerEnv = append(dockerEnv, "DOCKER_BUILDKIT=1")
-		buildEngineArgs = []string{"build"}
-	case "buildx":
-		buildEngineArgs = []string{"buildx", "build", "--load"}
-	default:
-		log.Panic().Msgf("Unsupported docker build engine: %s", params.buildEngine)
-	}
+	buildEngineArgs, dockerEnv := dockerBuildEngineArgs(params.buildEngine, dockerEnv)
 
 	// Resolve .NET runtime version to build project for, expects '<major>.<minor>'.
 	projectDotnetVersionSegments := params.project.Config.DotnetRuntimeVersion.Segments()
@@ -639,12 +925,8 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 		}...,
 	)
 
-	// If target platform is specified, set it explicitly.
-	if len(params.platforms) > 0 {
-		dockerArgs = append(
-			dockerArgs,
-			"--platform", strings.Join(params.platforms, ","))
-	}
+	// Add platforms, labels, and attestations.
+	dockerArgs = append(dockerArgs, dockerBuildOptionArgs(params)...)
 
 	// Add target if specified (for multi-stage builds)
 	if params.target != "" {
@@ -657,6 +939,8 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 	log.Info().Msg("")
 
 	// Execute the docker build
+	stopSlowHint := tui.WarnIfSlow(getDockerBuildSlowHint(buildRootDir))
+	defer stopSlowHint()
 	if err := executeCommand(ctx, buildRootDir, dockerEnv, "docker", dockerArgs...); err != nil {
 		printBitbucketRequirementsBanner()
 		return clierrors.Wrap(err, "Docker build failed").
@@ -666,6 +950,99 @@ func buildDockerImage(ctx context.Context, params buildDockerImageParams) error
 	return nil
 }
 
+// dockerBuildEngineArgs returns the docker arguments for building with the build engine, and
+// the environment with any variables required by the engine added.
+func dockerBuildEngineArgs(buildEngine string, dockerEnv []string) ([]string, []string) {
+	switch buildEngine {
+	case "buildkit":
+		return []string{"build"}, append(dockerEnv, "DOCKER_BUILDKIT=1")
+	case "buildx":
+		return []string{"buildx", "build", "--load"}, dockerEnv
+	default:
+		log.Panic().Msgf("Unsupported docker build engine: %s", buildEngine)
+		return nil, nil
+	}
+}
+
+// dockerBuildOptionArgs returns the docker build arguments for the target platforms, the extra
+// labels, and the attestations of the build.
+func dockerBuildOptionArgs(params buildDockerImageParams) []string {
+	dockerArgs := []string{}
+
+	// If target platform is specified, set it explicitly.
+	if len(params.platforms) > 0 {
+		dockerArgs = append(
+			dockerArgs,
+			"--platform", strings.Join(params.platforms, ","))
+	}
+
+	// Add extra labels, sorted for a stable command line.
+	for _, label := range slices.Sorted(maps.Keys(params.labels)) {
+		dockerArgs = append(dockerArgs, "--label", fmt.Sprintf("%s=%s", label, params.labels[label]))
+	}
+
+	// Add attestations, if requested.
+	if params.sbom {
+		dockerArgs = append(dockerArgs, "--sbom=true")
+	}
+	if params.provenance {
+		dockerArgs = append(dockerArgs, "--provenance=mode=max")
+	}
+
+	return dockerArgs
+}
+
+// dockerBuildContextSizeHintLimit is the build context size above which a slow docker build
+// is likely caused by uploading the build context.
+const dockerBuildContextSizeHintLimit = 500 * 1024 * 1024
+
+// getDockerBuildSlowHint returns the hint to show when the docker build is slow, or nil if there
+// is nothing actionable. Without a .dockerignore in the build root, docker uploads the whole
+// directory as the build context, which is slow if it contains eg, the Unity Library/ directory.
+// The hint is only returned if the build context is large, so that builds that are slow for
+// other reasons (eg, compiling) don't get blamed on the build context.
+func getDockerBuildSlowHint(buildRootDir string) *tui.SlowHint {
+	if _, err := os.Stat(filepath.Join(buildRootDir, ".dockerignore")); err == nil {
+		return nil
+	}
+	if !isDirectorySizeOver(buildRootDir, dockerBuildContextSizeHintLimit) {
+		return nil
+	}
+	return &tui.SlowHint{
+		Threshold: 60 * time.Second,
+		Message:   fmt.Sprintf("No .dockerignore found in the build root %s, so the whole directory (over %dMB) is uploaded to docker as the build context. Add a .dockerignore that excludes large directories, eg, the Unity project's Library/.", buildRootDir, dockerBuildContextSizeHintLimit/(1024*1024)),
+	}
+}
+
+// isDirectorySizeOver returns true if the total size of the regular files in the directory tree
+// exceeds the limit. The walk stops as soon as the limit is exceeded, so that measuring a huge
+// directory stays cheap. Unreadable entries are skipped.
+func isDirectorySizeOver(dir string, limit int64) bool {
+	errLimitExceeded := errors.New("limit exceeded")
+	var totalSize int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		totalSize += info.Size()
+		if totalSize > limit {
+			return errLimitExceeded
+		}
+		return nil
+	})
+	return errors.Is(err, errLimitExceeded)
+}
+
 // printBitbucketRequirementsBanner prints a prominent banner reminding the user
 // of Metaplay's Bitbucket Pipelines requirements (runtime v3, default-image:5).
 // These cannot be detected from inside the build, so we surface them on failure
@@ -0,0 +1,75 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// Dockerfile for packaging the built custom dashboard into an image. The build context is the
+// dashboard's build output directory. Unknown paths fall back to index.html, so that the routes of
+// the single-page app work when the page is reloaded.
+const dashboardImageDockerfile = `# syntax=docker/dockerfile:1
+FROM nginxinc/nginx-unprivileged:stable-alpine
+COPY <<"EOF" /etc/nginx/conf.d/default.conf
+server {
+    listen 8080;
+    root /usr/share/nginx/html;
+    location / {
+        try_files $uri $uri/ /index.html;
+    }
+}
+EOF
+COPY . /usr/share/nginx/html/
+EXPOSE 8080
+`
+
+// buildDashboardDockerImage packages the dashboard build output in distDir into a Docker image
+// that serves it with nginx. The dashboard must be built before calling this.
+func buildDashboardDockerImage(ctx context.Context, params buildDockerImageParams, distDir string) error {
+	if _, err := os.Stat(filepath.Join(distDir, "index.html")); err != nil {
+		return clierrors.Newf("Built dashboard not found in %s", distDir).
+			WithSuggestion("Build the dashboard with 'metaplay build dashboard' and check its output")
+	}
+
+	// Write the Dockerfile outside the build context, so it doesn't end up in the image.
+	tmpDir, err := os.MkdirTemp("", "metaplay-dashboard-image-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	dockerFilePath := filepath.Join(tmpDir, "Dockerfile")
+	if err := os.WriteFile(dockerFilePath, []byte(dashboardImageDockerfile), 0644); err != nil {
+		return fmt.Errorf("failed to write the dashboard Dockerfile: %w", err)
+	}
+
+	// Silence docker's recommendation messages at end-of-build.
+	dockerEnv := os.Environ()
+	dockerEnv = append(dockerEnv, "DOCKER_CLI_HINTS=false")
+
+	buildEngineArgs, dockerEnv := dockerBuildEngineArgs(params.buildEngine, dockerEnv)
+	dockerArgs := append(buildEngineArgs, "--pull", "-t", params.imageName, "-f", dockerFilePath)
+	dockerArgs = append(dockerArgs, dockerBuildOptionArgs(params)...)
+	dockerArgs = append(dockerArgs, params.extraArgs...)
+	dockerArgs = append(dockerArgs, ".")
+	log.Info().Msg("")
+	log.Info().Msgf(styles.RenderMuted("docker %s"), strings.Join(dockerArgs, " "))
+	log.Info().Msg("")
+
+	if err := executeCommand(ctx, distDir, dockerEnv, "docker", dockerArgs...); err != nil {
+		return clierrors.Wrap(err, "Docker build failed").
+			WithSuggestion("Check the build output above for details")
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBuildImageTarget(t *testing.T) {
+	target, err := resolveBuildImageTarget("botclient")
+	require.NoError(t, err)
+	assert.Equal(t, dockerStageBotClient, target.DockerStage)
+
+	// Dockerfile stage names are accepted as aliases.
+	target, err = resolveBuildImageTarget("Playwright-TS-Tests")
+	require.NoError(t, err)
+	assert.Equal(t, "dashboard-tests", target.Name)
+
+	// The dashboard is built from the custom dashboard, not the SDK's Dockerfile.
+	target, err = resolveBuildImageTarget("dashboard")
+	require.NoError(t, err)
+	assert.True(t, target.IsDashboard)
+	assert.Empty(t, target.DockerStage)
+
+	// The server target builds the final stage.
+	target, err = resolveBuildImageTarget("server")
+	require.NoError(t, err)
+	assert.Empty(t, target.DockerStage)
+	assert.True(t, target.IsDeployable)
+
+	_, err = resolveBuildImageTarget("unknown")
+	assert.Error(t, err)
+}
+
+func TestResolveBuildPlatforms(t *testing.T) {
+	platforms, err := resolveBuildPlatforms([]string{"amd64"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/amd64"}, platforms)
+
+	// Platforms take precedence over the architectures.
+	platforms, err = resolveBuildPlatforms([]string{"amd64"}, []string{"linux/amd64", "linux/arm64"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, platforms)
+
+	// Duplicates are dropped.
+	platforms, err = resolveBuildPlatforms([]string{"arm64", "arm64"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/arm64"}, platforms)
+
+	_, err = resolveBuildPlatforms(nil, nil)
+	assert.Error(t, err)
+	_, err = resolveBuildPlatforms([]string{"x86"}, nil)
+	assert.Error(t, err)
+	_, err = resolveBuildPlatforms(nil, []string{"windows/amd64"})
+	assert.Error(t, err)
+	_, err = resolveBuildPlatforms(nil, []string{"linux/riscv64"})
+	assert.Error(t, err)
+}
+
+func TestResolveBuildImageName(t *testing.T) {
+	now := time.Date(2025, 1, 31, 13, 30, 12, 0, time.UTC)
+	project := &metaproj.MetaplayProject{Config: metaproj.ProjectConfig{ProjectHumanID: "mygame"}}
+	namedProject := &metaproj.MetaplayProject{Config: metaproj.ProjectConfig{
+		ProjectHumanID: "mygame",
+		ImageNaming:    "{registry}/{project}/{component}:{date}-{commit}",
+	}}
+
+	testCases := []struct {
+		project  *metaproj.MetaplayProject
+		target   string
+		imageArg string
+		registry string
+		commitID string
+		expected string
+	}{
+		{project, "server", "", "", "none", "mygame:20250131-133012"},
+		{project, "server", "", "", "1a27c257", "mygame:20250131-133012-1a27c257"},
+		{project, "server", "364cff09", "", "none", "mygame:364cff09"},
+		{project, "botclient", "364cff09", "", "none", "mygame-botclient:364cff09"},
+		{project, "botclient", "364cff09", "registry.example.com/", "none", "registry.example.com/mygame-botclient:364cff09"},
+		{project, "dashboard-tests", "mygame-tests:364cff09", "", "none", "mygame-tests:364cff09"},
+		{project, "dashboard", "364cff09", "", "none", "mygame-dashboard:364cff09"},
+		{namedProject, "dashboard", "364cff09", "registry.example.com", "none", "registry.example.com/mygame/dashboard:364cff09"},
+		{namedProject, "server", "", "", "1a27c257", "mygame/server:20250131-133012-1a27c257"},
+		{namedProject, "botclient", "364cff09", "registry.example.com", "none", "registry.example.com/mygame/botclient:364cff09"},
+	}
+
+	for _, tc := range testCases {
+		target, err := resolveBuildImageTarget(tc.target)
+		require.NoError(t, err)
+		imageName, err := resolveBuildImageName(tc.project, target, tc.imageArg, tc.registry, tc.commitID, "none", now)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, imageName, "target=%s image=%s", tc.target, tc.imageArg)
+	}
+}
+
+func TestResolveProjectImageName(t *testing.T) {
+	project := &metaproj.MetaplayProject{Config: metaproj.ProjectConfig{
+		ProjectHumanID: "mygame",
+		ImageNaming:    "{registry}/{project}/{component}:{date}-{commit}",
+	}}
+
+	// The names match those of 'metaplay build image' with the same tags.
+	for _, tag := range []string{"20250131-133012-1a27c257", "364cff09", "test"} {
+		imageName, err := resolveProjectImageName(project, "server", tag)
+		require.NoError(t, err)
+		assert.Equal(t, "mygame/server:"+tag, imageName)
+	}
+
+	imageName, err := resolveLocalServerImageName(project, "364cff09")
+	require.NoError(t, err)
+	assert.Equal(t, "mygame/server:364cff09", imageName)
+
+	imageName, err = resolveTestImageName(project, "dashboard-tests", "playwright-ts")
+	require.NoError(t, err)
+	assert.Equal(t, "mygame/dashboard-tests:test", imageName)
+}
+
+func TestBuildDashboardDockerImageRequiresBuild(t *testing.T) {
+	// Without the built dashboard, the image build fails before invoking docker.
+	params := buildDockerImageParams{imageName: "mygame-dashboard:364cff09", buildEngine: "buildx"}
+	err := buildDashboardDockerImage(t.Context(), params, filepath.Join(t.TempDir(), "dist"))
+	assert.ErrorContains(t, err, "Built dashboard not found")
+}
+
+func TestDockerBuildOptionArgs(t *testing.T) {
+	assert.Empty(t, dockerBuildOptionArgs(buildDockerImageParams{}))
+	assert.Equal(t,
+		[]string{"--platform", "linux/amd64,linux/arm64", "--label", "a=1", "--label", "b=2", "--sbom=true", "--provenance=mode=max"},
+		dockerBuildOptionArgs(buildDockerImageParams{
+			platforms:  []string{"linux/amd64", "linux/arm64"},
+			labels:     map[string]string{"b": "2", "a": "1"},
+			sbom:       true,
+			provenance: true,
+		}))
+}
+
+func TestGetDockerBuildSlowHint(t *testing.T) {
+	// A small build context without .dockerignore doesn't explain a slow build.
+	buildRootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(buildRootDir, "Dockerfile"), []byte("FROM scratch\n"), 0644))
+	assert.Nil(t, getDockerBuildSlowHint(buildRootDir))
+
+	// A large build context does (use a sparse file to avoid writing the data).
+	libraryDir := filepath.Join(buildRootDir, "Library")
+	require.NoError(t, os.Mkdir(libraryDir, 0755))
+	largeFile, err := os.Create(filepath.Join(libraryDir, "large.bin"))
+	require.NoError(t, err)
+	require.NoError(t, largeFile.Truncate(dockerBuildContextSizeHintLimit+1))
+	require.NoError(t, largeFile.Close())
+
+	hint := getDockerBuildSlowHint(buildRootDir)
+	require.NotNil(t, hint)
+	assert.Contains(t, hint.Message, ".dockerignore")
+	assert.True(t, hint.IsExceeded(2*time.Minute))
+	assert.False(t, hint.IsExceeded(10*time.Second))
+
+	require.NoError(t, os.WriteFile(filepath.Join(buildRootDir, ".dockerignore"), []byte("Library/\n"), 0644))
+	assert.Nil(t, getDockerBuildSlowHint(buildRootDir))
+}
+
+func TestIsDirectorySizeOver(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), make([]byte, 100), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.txt"), make([]byte, 100), 0644))
+
+	assert.True(t, isDirectorySizeOver(dir, 150))
+	assert.False(t, isDirectorySizeOver(dir, 200))
+	assert.False(t, isDirectorySizeOver(filepath.Join(dir, "missing"), 0))
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// The localizations archive.
+var localizationsArchiveKind = configArchiveKind{
+	Name:               "localizations",
+	DefaultArchivePath: "GameConfig/Localizations.mpa",
+	BuilderArgs:        []string{"--localizations"},
+	UploadPath:         "/api/localization",
+	PublishPath:        "/api/localization/%s/publish",
+}
+
+// Build the localizations archive using the project's game config builder.
+type buildLocalizationsOpts struct {
+	UsePositionalArgs
+
+	extraArgs  []string
+	flagOutput string
+}
+
+func init() {
+	o := buildLocalizationsOpts{}
+
+	args := o.Arguments()
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to the game config builder.")
+
+	cmd := &cobra.Command{
+		Use:   "localizations [flags] [-- EXTRA_ARGS]",
+		Short: "Build the localizations archive",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Build the localizations archive by running the project's game config builder.
+
+			This command:
+			- Verifies the required .NET SDK version is installed
+			- Runs the game config builder with 'dotnet run' against the shared code directory
+			- Verifies that the localizations archive was produced
+
+			The game config builder is the .NET project in 'gameConfigBuilderDir' of the
+			metaplay-project.yaml, or the Backend/Server project if not specified. The builder is
+			invoked with '--localizations --shared-code-dir <dir> --output <file>' followed by any
+			extra arguments.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy localizations ...' to build and publish the localizations to an environment.
+			- 'metaplay build game-config' to build the game config archive.
+		`),
+		Example: renderExample(`
+			# Build the localizations archive into the default location.
+			metaplay build localizations
+
+			# Build the localizations archive into a custom location.
+			metaplay build localizations --output build/Localizations.mpa
+		`),
+	}
+	buildCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Path to write the localizations archive to (default: Backend/Server/GameConfig/Localizations.mpa)")
+}
+
+func (o *buildLocalizationsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *buildLocalizationsOpts) Run(cmd *cobra.Command) error {
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Build Localizations"))
+	log.Info().Msg("")
+
+	archivePath, err := buildConfigArchive(cmd.Context(), project, localizationsArchiveKind, o.flagOutput, o.extraArgs)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("%s Localizations archive built successfully: %s", styles.RenderSuccess("✓"), styles.RenderTechnical(archivePath))
+	return nil
+}
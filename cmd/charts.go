@@ -0,0 +1,19 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// chartsCmd includes commands for managing the Helm charts used by the project.
+var chartsCmd = &cobra.Command{
+	Use:   "charts",
+	Short: "Commands for managing the Helm charts used by the project",
+}
+
+func init() {
+	rootCmd.AddCommand(chartsCmd)
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Download the Helm charts into the project for offline and locked deploys.
+type chartsVendorOpts struct {
+	flagVersion             string
+	flagBotClientVersion    string
+	flagOutput              string
+	flagHelmChartRepository string
+}
+
+// vendoredChart is a Helm chart to vendor, with the version (or range) to download.
+type vendoredChart struct {
+	name              string
+	versionSpecifier  string
+	minVersion        string // Oldest supported version of the chart.
+	versionConfigName string // Field in metaplay-project.yaml with the default version.
+}
+
+func init() {
+	o := chartsVendorOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "vendor [flags]",
+		Short: "Download the Helm charts into the project for offline deploys",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Download the metaplay-gameserver and bot client (metaplay-loadtest) Helm charts into a
+			directory in the project, so that the deploys are locked to the vendored charts and work
+			without access to the chart repository, eg, in air-gapped environments.
+
+			The chart versions default to 'serverChartVersion' and 'botClientChartVersion' in
+			metaplay-project.yaml. Use --version to download another version (or the latest version
+			matching a range) of both charts, and --botclient-version to use a different version of
+			the bot client chart.
+
+			The charts are written to the directory given with --output, or to the directory
+			configured with 'localChartsDir' in metaplay-project.yaml. Any dependencies that are not
+			bundled into the chart archives are downloaded into the same directory.
+
+			When 'localChartsDir' points to the directory, 'metaplay deploy server' and 'metaplay
+			deploy botclient' prefer the vendored charts over the chart repository.
+
+			Related commands:
+			- 'metaplay deploy server ...' to deploy the game server using the vendored chart.
+		`),
+		Example: renderExample(`
+			# Vendor the chart versions configured in metaplay-project.yaml into 'localChartsDir'.
+			metaplay charts vendor
+
+			# Vendor version 0.8.3 of the charts into the 'charts/' directory.
+			metaplay charts vendor --version=0.8.3 --output=charts/
+
+			# Vendor the charts from a mirror in an OCI registry.
+			metaplay charts vendor --output=charts/ --helm-chart-repo=oci://registry.example.com/charts
+		`),
+	}
+	chartsCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagVersion, "version", "", "Helm chart version or version range to download, eg, '0.8.3' or '>=0.8.0 <0.9.0'")
+	flags.StringVar(&o.flagBotClientVersion, "botclient-version", "", "Override for the bot client chart version or version range to download")
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Directory to download the charts into (defaults to 'localChartsDir' in metaplay-project.yaml)")
+	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository or OCI registry (oci://...) to download the charts from")
+}
+
+func (o *chartsVendorOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if err := metaproj.ValidateHelmChartRepositoryURL(o.flagHelmChartRepository); err != nil {
+		return clierrors.WrapUsageError(err, "Invalid --helm-chart-repo").
+			WithSuggestion("Use an http(s) chart repository or an OCI registry, eg, 'oci://registry.example.com/charts'")
+	}
+	return nil
+}
+
+func (o *chartsVendorOpts) Run(cmd *cobra.Command) error {
+	// The project is optional when all the flags are given.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve the output directory.
+	outputDir := o.flagOutput
+	if outputDir == "" && project != nil {
+		outputDir = project.GetLocalChartsDir()
+	}
+	if outputDir == "" {
+		return clierrors.NewUsageError("No output directory specified").
+			WithSuggestion("Specify the directory with --output, eg, '--output=charts/', or set 'localChartsDir' in metaplay-project.yaml")
+	}
+
+	// Resolve the charts and their versions.
+	helmChartRepo := defaultHelmChartRepository
+	charts := []vendoredChart{
+		{name: metaplayGameServerChartName, versionSpecifier: o.flagVersion, minVersion: "0.7.0", versionConfigName: "serverChartVersion"},
+		{name: metaplayLoadTestChartName, versionSpecifier: coalesceString(o.flagBotClientVersion, o.flagVersion), minVersion: "0.4.0", versionConfigName: "botClientChartVersion"},
+	}
+	if project != nil {
+		helmChartRepo = coalesceString(project.Config.HelmChartRepository, defaultHelmChartRepository)
+		charts[0].versionSpecifier = coalesceString(charts[0].versionSpecifier, project.Config.ServerChartVersion)
+		charts[1].versionSpecifier = coalesceString(charts[1].versionSpecifier, project.Config.BotClientChartVersion)
+	}
+	helmChartRepo = coalesceString(o.flagHelmChartRepository, helmChartRepo)
+	for _, chart := range charts {
+		if chart.versionSpecifier == "" {
+			return clierrors.NewUsageErrorf("No version specified for the %s chart", chart.name).
+				WithSuggestion(fmt.Sprintf("Specify the version with --version, or set '%s' in metaplay-project.yaml", chart.versionConfigName))
+		}
+	}
+
+	// Create the registry client for OCI chart repositories. The credentials from 'helm registry
+	// login' or 'docker login' are used, as there's no target environment.
+	registryClient, err := newHelmChartRegistryClient(helmChartRepo, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", outputDir, err)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Vendor Helm Charts"))
+	log.Info().Msg("")
+	log.Info().Msgf("Chart repository: %s", styles.RenderTechnical(helmChartRepo))
+	log.Info().Msgf("Output directory: %s", styles.RenderTechnical(outputDir))
+	log.Info().Msg("")
+
+	for _, chart := range charts {
+		chartVersionConstraints, err := metaproj.ParseHelmChartVersionConstraints(chart.versionSpecifier)
+		if err != nil {
+			return clierrors.WrapUsageError(err, fmt.Sprintf("Invalid Helm chart version '%s'", chart.versionSpecifier))
+		}
+		minChartVersion, _ := version.NewVersion(chart.minVersion)
+		chartVersion, err := helmutil.ResolveBestMatchingHelmVersion(registryClient, helmChartRepo, chart.name, minChartVersion, chartVersionConstraints)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to resolve the %s Helm chart version '%s' from %s", chart.name, chart.versionSpecifier, helmChartRepo)
+		}
+
+		chartPath, err := helmutil.PullHelmChart(registryClient, helmChartRepo, chart.name, chartVersion, outputDir)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to download the %s Helm chart", chart.name)
+		}
+		log.Info().Msgf("%s %s v%s %s", styles.RenderSuccess("✓"), chart.name, chartVersion, styles.RenderMuted(chartPath))
+
+		if err := vendorChartDependencies(chartPath, outputDir); err != nil {
+			return err
+		}
+	}
+	log.Info().Msg("")
+
+	// Let the user know if the deploys won't use the vendored charts.
+	if project == nil || filepath.Clean(project.GetLocalChartsDir()) != filepath.Clean(outputDir) {
+		relativeDir := outputDir
+		if project != nil {
+			if rel, err := filepath.Rel(project.RelativeDir, outputDir); err == nil {
+				relativeDir = filepath.ToSlash(rel)
+			}
+		}
+		log.Info().Msgf("To deploy with the vendored charts, set %s in metaplay-project.yaml.", styles.RenderPrompt(fmt.Sprintf("localChartsDir: %s", relativeDir)))
+	}
+	return nil
+}
+
+// vendorChartDependencies downloads the dependencies of the chart archive that are not bundled
+// into it into the output directory.
+func vendorChartDependencies(chartPath, outputDir string) error {
+	dependencies, err := helmutil.FindUnbundledChartDependencies(chartPath)
+	if err != nil {
+		return err
+	}
+
+	for _, dependency := range dependencies {
+		if metaproj.ValidateHelmChartRepositoryURL(dependency.Repository) != nil || dependency.Repository == "" {
+			log.Warn().Msgf("  Skipping dependency %s: unsupported repository '%s'", dependency.Name, dependency.Repository)
+			continue
+		}
+
+		registryClient, err := newHelmChartRegistryClient(dependency.Repository, nil)
+		if err != nil {
+			return err
+		}
+		dependencyPath, err := helmutil.PullHelmChart(registryClient, dependency.Repository, dependency.Name, dependency.Version, outputDir)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to download the chart dependency %s", dependency.Name)
+		}
+		log.Info().Msgf("  %s dependency %s %s", styles.RenderSuccess("✓"), dependency.Name, styles.RenderMuted(dependencyPath))
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ciCmd includes commands for managing the project's CI/CD configuration.
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Commands for managing the project's CI/CD configuration",
+}
+
+func init() {
+	rootCmd.AddCommand(ciCmd)
+}
@@ -0,0 +1,423 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Lint the project's CI configuration files for outdated patterns and optionally fix them.
+type ciValidateOpts struct {
+	UsePositionalArgs
+
+	flagFix         bool
+	flagAutoConfirm bool
+	flagPlanJSON    bool
+	flagPlanOnly    bool
+}
+
+// ciLintRule detects an outdated pattern on a line of a CI configuration file.
+type ciLintRule struct {
+	ID          string         // Identifier of the rule, shown with the issues
+	Pattern     *regexp.Regexp // Pattern matching the outdated usage on a line
+	Message     string         // Description of the problem
+	Fixable     bool           // Whether the pattern can be fixed automatically
+	Replacement string         // Replacement for the matches (with $1-style references), if fixable
+}
+
+// ciLintIssue is an outdated pattern found in a CI configuration file.
+type ciLintIssue struct {
+	FilePath string // Path to the file with the issue
+	Line     int    // Line number in the file (1-based), or 0 if unknown
+	RuleID   string // Identifier of the rule that found the issue
+	Message  string // Description of the problem
+	Fixable  bool   // Whether the issue is fixed automatically with --fix
+}
+
+// ciLintResult is the result of linting a single CI configuration file.
+type ciLintResult struct {
+	FilePath     string        // Path to the linted file
+	Issues       []ciLintIssue // Issues found, in line order
+	FixedContent []byte        // Content of the file with the fixable issues fixed
+}
+
+// ciLintRules are the line-based checks for outdated patterns in the CI configuration files.
+var ciLintRules = []ciLintRule{
+	{
+		ID:          "outdated-checkout-action",
+		Pattern:     regexp.MustCompile(`actions/checkout@v[1-5]\b`),
+		Message:     "Outdated version of actions/checkout, use actions/checkout@v6",
+		Fixable:     true,
+		Replacement: "actions/checkout@v6",
+	},
+	{
+		ID:          "deprecated-buildkit-engine",
+		Pattern:     regexp.MustCompile(`\s+--engine[= ]buildkit\b`),
+		Message:     "The --engine=buildkit flag is deprecated, the default buildx engine should be used",
+		Fixable:     true,
+		Replacement: "",
+	},
+	{
+		ID:          "deprecated-skip-pnpm",
+		Pattern:     regexp.MustCompile(`--skip-pnpm\b`),
+		Message:     "The --skip-pnpm flag is deprecated, use --skip-install",
+		Fixable:     true,
+		Replacement: "--skip-install",
+	},
+	{
+		ID:          "deprecated-prerelease-flag",
+		Pattern:     regexp.MustCompile(`(metaplay\s+update\s+cli\b.*?)--prerelease\b`),
+		Message:     "The --prerelease flag is deprecated, use --channel=prerelease",
+		Fixable:     true,
+		Replacement: "${1}--channel=prerelease",
+	},
+	{
+		ID:          "unsupported-only-flag",
+		Pattern:     regexp.MustCompile(`(metaplay\s+test\s+integration\b.*?)--only\b`),
+		Message:     "The --only flag is not supported by 'metaplay test integration', use --test",
+		Fixable:     true,
+		Replacement: "${1}--test",
+	},
+	{
+		ID:      "deprecated-snapshot-command",
+		Pattern: regexp.MustCompile(`metaplay\s+database\s+(export|import)-snapshot\b`),
+		Message: "The 'database export-snapshot' and 'database import-snapshot' commands are deprecated, use 'database export-archive' and 'database import-archive'",
+	},
+}
+
+// Matches the CLI commands that access the cloud environments and thus require signing in.
+var ciCloudCommandRegex = regexp.MustCompile(`\bmetaplay\s+(deploy|image\s+push|secrets|database|debug)\b`)
+
+// Matches the ways of signing in with the machine user credentials in the CI jobs.
+var ciMachineLoginRegex = regexp.MustCompile(`metaplay\s+auth\s+machine-login|setup-cli@`)
+
+func init() {
+	o := ciValidateOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "validate [flags]",
+		Short: "Check the CI configuration files for outdated patterns",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Check the project's CI configuration files, eg, the ones generated with 'metaplay init ci',
+			for syntax errors and outdated patterns. The following files are checked:
+			- GitHub Actions workflows: .github/workflows/*.yaml and *.yml
+			- Bitbucket Pipelines: bitbucket-pipelines.yml
+			- GitLab CI: .gitlab-ci.yml
+			- Azure DevOps: azure-pipelines*.yml
+			- Jenkins: Jenkinsfile*
+			- Generic CI scripts: deploy-server-*.sh and validate-server.sh
+
+			The checks include:
+			- Syntax errors in the YAML files.
+			- Outdated versions of the GitHub actions used by the generated workflows.
+			- Deprecated or unsupported Metaplay CLI flags and commands.
+			- Jobs that access the cloud environments without signing in with a machine user.
+
+			Use --fix to fix the issues that can be fixed automatically. The file changes are previewed
+			before writing. Use --plan-only or --plan-json to only show the changes.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay init ci' to generate the CI configuration files.
+			- 'metaplay validate project' to validate the project config and the files referenced by it.
+		`),
+		Example: renderExample(`
+			# Check the CI configuration files.
+			metaplay ci validate
+
+			# Fix the issues that can be fixed automatically.
+			metaplay ci validate --fix
+
+			# Show the fixes without writing them.
+			metaplay ci validate --fix --plan-only
+		`),
+	}
+	ciCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagFix, "fix", false, "Fix the issues that can be fixed automatically")
+	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Automatically confirm file writes")
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned fixes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned fixes without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "dry-run", false, "Alias for --plan-only")
+}
+
+func (o *ciValidateOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if (o.flagPlanOnly || o.flagPlanJSON) && !o.flagFix {
+		return clierrors.NewUsageError("--plan-only and --plan-json can only be used with --fix")
+	}
+	return nil
+}
+
+func (o *ciValidateOpts) Run(cmd *cobra.Command) error {
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+
+	filePaths, err := findCIConfigFiles(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Lint all the files.
+	var results []ciLintResult
+	filePerms := map[string]os.FileMode{}
+	for _, filePath := range filePaths {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to access %s", filePath)
+		}
+		filePerms[filePath] = info.Mode().Perm()
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to read %s", filePath)
+		}
+		results = append(results, lintCIConfigFile(filePath, content))
+	}
+
+	// Plan the fixes.
+	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
+	numFixable := 0
+	numUnfixable := 0
+	for _, result := range results {
+		fixable := 0
+		for _, issue := range result.Issues {
+			if issue.Fixable {
+				fixable++
+			} else {
+				numUnfixable++
+			}
+		}
+		if fixable > 0 {
+			plan.AddUpdate(result.FilePath, result.FixedContent, filePerms[result.FilePath], fmt.Sprintf("fix %d outdated pattern(s)", fixable))
+			numFixable += fixable
+		}
+	}
+	if err := plan.Scan(); err != nil {
+		return err
+	}
+
+	if o.flagPlanJSON {
+		return showPlanOnly(plan, true, false)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Validate CI Configuration"))
+	log.Info().Msg("")
+
+	if len(filePaths) == 0 {
+		log.Info().Msgf("No CI configuration files found, use %s to generate them.", styles.RenderPrompt("metaplay init ci"))
+		return nil
+	}
+
+	printCILintResults(projectDir, results)
+
+	if numFixable+numUnfixable == 0 {
+		log.Info().Msgf("✅ All %d CI configuration file(s) are up to date", len(filePaths))
+		return nil
+	}
+
+	log.Info().Msg("")
+	if !o.flagFix || numFixable == 0 {
+		if numFixable > 0 {
+			log.Info().Msgf("%d of the issues can be fixed automatically with %s.", numFixable, styles.RenderPrompt("metaplay ci validate --fix"))
+			log.Info().Msg("")
+		}
+		return clierrors.Newf("Found %d issue(s) in the CI configuration files", numFixable+numUnfixable).
+			WithSuggestion("Fix the issues listed above")
+	}
+
+	if o.flagPlanOnly {
+		return showPlanOnly(plan, false, false)
+	}
+
+	log.Info().Msg("Files to be modified:")
+	plan.Preview(false)
+
+	if err := plan.WaitForWritable(cmd.Context(), false); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	if !o.flagAutoConfirm {
+		if !tui.IsInteractiveMode() {
+			return clierrors.New("Confirmation required to fix the CI configuration files").
+				WithSuggestion("Use --yes to apply the fixes in non-interactive mode")
+		}
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Apply the fixes?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Aborted.")
+			return nil
+		}
+	}
+
+	if err := plan.Execute(); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Fixed %d issue(s) in the CI configuration files", numFixable)))
+	if numUnfixable > 0 {
+		return clierrors.Newf("%d issue(s) need to be fixed manually", numUnfixable).
+			WithSuggestion("Fix the issues listed above")
+	}
+	return nil
+}
+
+// findCIConfigFiles returns the CI configuration files in the project directory, eg, the ones
+// generated with 'metaplay init ci'.
+func findCIConfigFiles(projectDir string) ([]string, error) {
+	patterns := []string{
+		".github/workflows/*.yaml",
+		".github/workflows/*.yml",
+		"bitbucket-pipelines.yml",
+		".gitlab-ci.yml",
+		"azure-pipelines*.yml",
+		"Jenkinsfile*",
+		"deploy-server-*.sh",
+		"validate-server.sh",
+	}
+
+	var filePaths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(projectDir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			// Skip the .new-suffixed files written by 'metaplay init ci --on-conflict=rename'.
+			if strings.HasSuffix(match, ".new") || slices.Contains(filePaths, match) {
+				continue
+			}
+			filePaths = append(filePaths, match)
+		}
+	}
+	return filePaths, nil
+}
+
+// lintCIConfigFile checks the content of a CI configuration file for syntax errors and outdated
+// patterns, and returns the issues found along with the content with the fixable issues fixed.
+func lintCIConfigFile(filePath string, content []byte) ciLintResult {
+	result := ciLintResult{FilePath: filePath}
+
+	// Check the syntax of YAML files.
+	ext := filepath.Ext(filePath)
+	if ext == ".yaml" || ext == ".yml" {
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			result.Issues = append(result.Issues, ciLintIssue{
+				FilePath: filePath,
+				Line:     parseYAMLErrorLine(err),
+				RuleID:   "invalid-yaml",
+				Message:  err.Error(),
+			})
+		}
+	}
+
+	// Check the lines for the outdated patterns, fixing them as we go.
+	lines := strings.Split(string(content), "\n")
+	firstCloudCommandLine := 0
+	hasMachineLogin := false
+	for ndx, line := range lines {
+		for _, rule := range ciLintRules {
+			if !rule.Pattern.MatchString(line) {
+				continue
+			}
+			result.Issues = append(result.Issues, ciLintIssue{
+				FilePath: filePath,
+				Line:     ndx + 1,
+				RuleID:   rule.ID,
+				Message:  rule.Message,
+				Fixable:  rule.Fixable,
+			})
+			if rule.Fixable {
+				line = rule.Pattern.ReplaceAllString(line, rule.Replacement)
+			}
+		}
+		lines[ndx] = line
+
+		// Comments don't run anything.
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if firstCloudCommandLine == 0 && ciCloudCommandRegex.MatchString(line) {
+			firstCloudCommandLine = ndx + 1
+		}
+		if ciMachineLoginRegex.MatchString(line) {
+			hasMachineLogin = true
+		}
+	}
+	result.FixedContent = []byte(strings.Join(lines, "\n"))
+
+	// Accessing the cloud environments requires signing in with the machine user.
+	if firstCloudCommandLine > 0 && !hasMachineLogin {
+		result.Issues = append(result.Issues, ciLintIssue{
+			FilePath: filePath,
+			Line:     firstCloudCommandLine,
+			RuleID:   "missing-machine-login",
+			Message:  "The job accesses the cloud environments without signing in, run 'metaplay auth machine-login' with METAPLAY_CREDENTIALS first",
+		})
+	}
+
+	slices.SortStableFunc(result.Issues, func(a, b ciLintIssue) int {
+		return a.Line - b.Line
+	})
+	return result
+}
+
+// printCILintResults prints the issues found in the CI configuration files, with the offending
+// lines for context.
+func printCILintResults(projectDir string, results []ciLintResult) {
+	for _, result := range results {
+		displayPath := result.FilePath
+		if rel, err := filepath.Rel(projectDir, result.FilePath); err == nil {
+			displayPath = rel
+		}
+		displayPath = filepath.ToSlash(displayPath)
+
+		if len(result.Issues) == 0 {
+			log.Info().Msgf("%s %s", styles.RenderSuccess("✓"), displayPath)
+			continue
+		}
+
+		content, _ := os.ReadFile(result.FilePath)
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		for _, issue := range result.Issues {
+			location := displayPath
+			if issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d", displayPath, issue.Line)
+			}
+			marker := styles.RenderError("✗")
+			if issue.Fixable {
+				marker = styles.RenderWarning("~")
+			}
+			log.Info().Msgf("%s %s %s %s", marker, styles.RenderMuted(location+":"), issue.Message, styles.RenderMuted(fmt.Sprintf("[%s]", issue.RuleID)))
+			for _, line := range renderFileLineContext(lines, issue.Line, 0) {
+				log.Info().Msg(line)
+			}
+		}
+	}
+}
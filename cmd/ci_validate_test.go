@@ -0,0 +1,129 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCIConfigFileFixesOutdatedPatterns(t *testing.T) {
+	content := `jobs:
+  deploy:
+    steps:
+      - uses: actions/checkout@v4
+      - uses: metaplay-shared/github-workflows/setup-cli@v0
+      - run: metaplay build image gameserver:tag --engine=buildkit
+      - run: metaplay test integration --only=bots
+      - run: metaplay deploy server nimbly gameserver:tag
+`
+	result := lintCIConfigFile("deploy.yaml", []byte(content))
+
+	var ruleIDs []string
+	for _, issue := range result.Issues {
+		assert.True(t, issue.Fixable, "issue %s should be fixable", issue.RuleID)
+		ruleIDs = append(ruleIDs, issue.RuleID)
+	}
+	assert.Equal(t, []string{"outdated-checkout-action", "deprecated-buildkit-engine", "unsupported-only-flag"}, ruleIDs)
+
+	expected := `jobs:
+  deploy:
+    steps:
+      - uses: actions/checkout@v6
+      - uses: metaplay-shared/github-workflows/setup-cli@v0
+      - run: metaplay build image gameserver:tag
+      - run: metaplay test integration --test=bots
+      - run: metaplay deploy server nimbly gameserver:tag
+`
+	assert.Equal(t, expected, string(result.FixedContent))
+
+	// The fixed content has no issues.
+	assert.Empty(t, lintCIConfigFile("deploy.yaml", result.FixedContent).Issues)
+}
+
+func TestLintCIConfigFileReportsUnfixableIssues(t *testing.T) {
+	content := `#!/bin/bash
+# metaplay deploy server in a comment is ignored
+metaplay database export-snapshot nimbly
+metaplay deploy server nimbly gameserver:tag
+`
+	result := lintCIConfigFile("deploy-server-nimbly.sh", []byte(content))
+	require.Len(t, result.Issues, 2)
+	assert.Equal(t, "deprecated-snapshot-command", result.Issues[0].RuleID)
+	assert.Equal(t, 3, result.Issues[0].Line)
+	assert.Equal(t, "missing-machine-login", result.Issues[1].RuleID)
+	assert.Equal(t, 3, result.Issues[1].Line)
+	assert.False(t, result.Issues[0].Fixable)
+	assert.False(t, result.Issues[1].Fixable)
+	assert.Equal(t, content, string(result.FixedContent))
+}
+
+func TestLintCIConfigFileReportsInvalidYAML(t *testing.T) {
+	result := lintCIConfigFile("bitbucket-pipelines.yml", []byte("pipelines:\n  custom: [\n"))
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "invalid-yaml", result.Issues[0].RuleID)
+	assert.Positive(t, result.Issues[0].Line)
+}
+
+func TestLintCIConfigFileAcceptsGeneratedFiles(t *testing.T) {
+	pipelineData := testCIPipelineTemplateData()
+	pipelineData.Validate = true
+	pipelineData.Nightly = true
+	deployData := ciTemplateData{EnvironmentDisplayName: "Nimbly", EnvironmentHumanID: "lovely-wombats-build-nimbly"}
+
+	testCases := []struct {
+		fileName string
+		tmpl     *template.Template
+		data     any
+	}{
+		{"deploy-server-nimbly.yaml", githubActionsTmpl, deployData},
+		{"deploy-server-nimbly.sh", genericCITmpl, deployData},
+		{"bitbucket-pipelines.yml", bitbucketPipelinesTmpl, pipelineData},
+		{".gitlab-ci.yml", gitlabCITmpl, pipelineData},
+		{"azure-pipelines.yml", azurePipelinesTmpl, pipelineData},
+		{"Jenkinsfile", jenkinsfileTmpl, pipelineData},
+		{"validate-server.yaml", githubActionsValidateTmpl, ciValidateTemplateData{}},
+		{"azure-pipelines-nightly.yml", azurePipelinesValidateTmpl, ciValidateTemplateData{Nightly: true}},
+		{"Jenkinsfile.validate", jenkinsfileValidateTmpl, ciValidateTemplateData{}},
+		{"validate-server.sh", genericCIValidateTmpl, ciValidateTemplateData{}},
+	}
+	for _, tc := range testCases {
+		content, err := renderTemplate(tc.tmpl, tc.data)
+		require.NoError(t, err)
+		assert.Empty(t, lintCIConfigFile(tc.fileName, []byte(content)).Issues, tc.fileName)
+	}
+}
+
+func TestFindCIConfigFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	for _, fileName := range []string{
+		".github/workflows/deploy-server-nimbly.yaml",
+		".github/workflows/deploy-server-nimbly.yaml.new",
+		".gitlab-ci.yml",
+		"Jenkinsfile",
+		"deploy-server-nimbly.sh",
+		"README.md",
+	} {
+		filePath := filepath.Join(projectDir, filepath.FromSlash(fileName))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte{}, 0644))
+	}
+
+	filePaths, err := findCIConfigFiles(projectDir)
+	require.NoError(t, err)
+
+	var relPaths []string
+	for _, filePath := range filePaths {
+		rel, err := filepath.Rel(projectDir, filePath)
+		require.NoError(t, err)
+		relPaths = append(relPaths, filepath.ToSlash(rel))
+	}
+	assert.Equal(t, []string{".github/workflows/deploy-server-nimbly.yaml", ".gitlab-ci.yml", "Jenkinsfile", "deploy-server-nimbly.sh"}, relPaths)
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Environment variable for naming the active context explicitly, eg, to share it between the
+// panes of a terminal multiplexer. By default, each shell has its own context.
+const cliContextEnvVar = "METAPLAYCLI_CONTEXT"
+
+// Directory under the state directory where the contexts are persisted, one file per context.
+const cliContextsDirName = "contexts"
+
+// Annotation for commands that must not be affected by the active context, eg, 'metaplay use'.
+const annotationIgnoreCliContext = "metaplay.io/ignore-context"
+
+// How long the contexts of shells (named after the shell's process ID) are valid. Process IDs
+// get reused, so a context left behind by a closed shell must not leak into an unrelated shell
+// started much later.
+const cliShellContextTTL = 24 * time.Hour
+
+// Valid names of contexts, used as file names.
+var cliContextNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// cliContext pins the project, environment and auth profile for the commands run in a shell,
+// so that the ENVIRONMENT argument can be omitted. Set with 'metaplay use'.
+type cliContext struct {
+	Name        string    `json:"name"`               // Name of the context, eg, 'shell-1234'.
+	ProjectDir  string    `json:"projectDir"`         // Absolute path to the project directory, or empty if not in a project.
+	Environment string    `json:"environment"`        // Human ID of the environment, eg, 'tiny-squids'.
+	AuthProfile string    `json:"authProfile"`        // Credential profile used for the environment, eg, 'default'.
+	CreatedAt   time.Time `json:"createdAt"`          // When the context was set.
+	ExpiresAt   time.Time `json:"expiresAt,omitzero"` // When the context expires, or zero if never.
+}
+
+// isExpired returns true if the context has expired at the given time.
+func (cliCtx *cliContext) isExpired(now time.Time) bool {
+	return !cliCtx.ExpiresAt.IsZero() && !now.Before(cliCtx.ExpiresAt)
+}
+
+// newCliContext creates a context with the given name. Contexts of shells expire after
+// cliShellContextTTL, contexts named with METAPLAYCLI_CONTEXT never expire.
+func newCliContext(name string, now time.Time) *cliContext {
+	cliCtx := &cliContext{
+		Name:        name,
+		AuthProfile: auth.ResolveActiveProfile(),
+		CreatedAt:   now,
+	}
+	if os.Getenv(cliContextEnvVar) == "" {
+		cliCtx.ExpiresAt = now.Add(cliShellContextTTL)
+	}
+	return cliCtx
+}
+
+// Context of the current shell, loaded before running a command. Nil if there is none.
+var activeCliContext *cliContext
+
+// getCliContextName returns the name of the context of the current shell: the value of
+// METAPLAYCLI_CONTEXT, or else derived from the parent process, which is the shell when the
+// CLI is run interactively.
+func getCliContextName() (string, error) {
+	if name := os.Getenv(cliContextEnvVar); name != "" {
+		if !cliContextNameRegex.MatchString(name) {
+			return "", fmt.Errorf("invalid %s '%s': only letters, digits, '_', '.' and '-' are allowed", cliContextEnvVar, name)
+		}
+		return name, nil
+	}
+	return fmt.Sprintf("shell-%d", os.Getppid()), nil
+}
+
+// resolveCliContextFilePath returns the path to the persisted context with the given name.
+func resolveCliContextFilePath(name string) (string, error) {
+	stateDir, err := common.ResolveStateDirPath(common.StateDirState)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir.Path, cliContextsDirName, name+".json"), nil
+}
+
+// readCliContextFile reads a persisted context.
+func readCliContextFile(filePath string) (*cliContext, error) {
+	contextJSON, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var cliCtx cliContext
+	if err := json.Unmarshal(contextJSON, &cliCtx); err != nil {
+		return nil, err
+	}
+	return &cliCtx, nil
+}
+
+// loadCliContext loads the context of the current shell. Returns nil if there is none, or if
+// it has expired (in which case it is also removed).
+func loadCliContext() (*cliContext, error) {
+	name, err := getCliContextName()
+	if err != nil {
+		return nil, err
+	}
+	filePath, err := resolveCliContextFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cliCtx, err := readCliContextFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read context '%s': %w", name, err)
+	}
+
+	if cliCtx.isExpired(time.Now()) {
+		log.Debug().Msgf("Removing context '%s' that expired at %s", name, cliCtx.ExpiresAt.Local().Format(time.DateTime))
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			log.Debug().Msgf("Failed to remove expired context '%s': %v", name, err)
+		}
+		return nil, nil
+	}
+	return cliCtx, nil
+}
+
+// removeExpiredCliContexts removes the expired contexts in the contexts directory, so that the
+// contexts of closed shells don't pile up. Failures are ignored: the expired contexts are also
+// ignored when loading them.
+func removeExpiredCliContexts(contextsDir string, now time.Time) {
+	entries, err := os.ReadDir(contextsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		filePath := filepath.Join(contextsDir, entry.Name())
+		cliCtx, err := readCliContextFile(filePath)
+		if err != nil || !cliCtx.isExpired(now) {
+			continue
+		}
+		log.Debug().Msgf("Removing expired context '%s'", cliCtx.Name)
+		_ = os.Remove(filePath)
+	}
+}
+
+// saveCliContext persists the context of the current shell, and removes any expired contexts.
+func saveCliContext(cliCtx *cliContext) error {
+	filePath, err := resolveCliContextFilePath(cliCtx.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return fmt.Errorf("failed to create contexts directory: %w", err)
+	}
+	removeExpiredCliContexts(filepath.Dir(filePath), time.Now())
+
+	contextJSON, err := json.MarshalIndent(cliCtx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal context: %w", err)
+	}
+	if err := os.WriteFile(filePath, contextJSON, 0600); err != nil {
+		return fmt.Errorf("failed to write context: %w", err)
+	}
+	return nil
+}
+
+// clearCliContext removes the context of the current shell. Returns false if there was none.
+func clearCliContext() (bool, error) {
+	name, err := getCliContextName()
+	if err != nil {
+		return false, err
+	}
+	filePath, err := resolveCliContextFilePath(name)
+	if err != nil {
+		return false, err
+	}
+	if err := os.Remove(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to remove context '%s': %w", name, err)
+	}
+	return true, nil
+}
+
+// initActiveCliContext loads the context of the current shell for running the command.
+// Failing to load the context is not fatal: the commands work without it.
+func initActiveCliContext(cmd *cobra.Command) {
+	activeCliContext = nil
+	if cmd.Annotations[annotationIgnoreCliContext] != "" {
+		return
+	}
+	cliCtx, err := loadCliContext()
+	if err != nil {
+		log.Warn().Msgf("Ignoring the active context: %v", err)
+		return
+	}
+	if cliCtx == nil {
+		return
+	}
+
+	// The context only applies to its own project: ignore it when working on another project.
+	// The context is activated first, so that it takes precedence over the default project of
+	// the user's settings when not in any project directory.
+	activeCliContext = cliCtx
+	if cliCtx.ProjectDir != "" {
+		if projectDir, err := findProjectDirectory(); err == nil {
+			absProjectDir, err := filepath.Abs(projectDir)
+			if err != nil || absProjectDir != cliCtx.ProjectDir {
+				log.Debug().Msgf("Ignoring context '%s' for project '%s' in project '%s'", cliCtx.Name, cliCtx.ProjectDir, projectDir)
+				activeCliContext = nil
+				return
+			}
+		}
+	}
+
+	log.Debug().Msgf("Using context '%s': project=%s, environment=%s, profile=%s", cliCtx.Name, cliCtx.ProjectDir, cliCtx.Environment, cliCtx.AuthProfile)
+
+	// Use the auth profile of the context, unless one is given explicitly with --profile or
+	// METAPLAY_PROFILE.
+	if cliCtx.AuthProfile != "" && flagAuthProfile == "" && os.Getenv(auth.ProfileEnvVar) == "" {
+		if err := auth.SetProfileOverride(cliCtx.AuthProfile); err != nil {
+			log.Warn().Msgf("Ignoring the auth profile of context '%s': %v", cliCtx.Name, err)
+		}
+	}
+}
+
+// getContextEnvironment returns the environment of the active context, or an empty string if
+// there is no active context.
+func getContextEnvironment() string {
+	if activeCliContext == nil {
+		return ""
+	}
+	return activeCliContext.Environment
+}
+
+// logContextEnvironment tells the user that the environment is taken from the active context.
+// Logged to stderr to keep the output of the commands intact.
+func logContextEnvironment() {
+	stderrLogger.Info().Msgf("Using environment %s from the active context (see 'metaplay use')", styles.RenderTechnical(activeCliContext.Environment))
+}
+
+// insertContextEnvironment inserts the environment of the active context into the command line,
+// when the command requires an ENVIRONMENT argument and the command line is missing a required
+// argument. Optional ENVIRONMENT arguments are resolved from the context in resolveEnvironment().
+func insertContextEnvironment(args *PositionalArgs, argv []string, environment string) []string {
+	if environment == "" {
+		return argv
+	}
+
+	numRequired := 0
+	envNdx := -1
+	for ndx, spec := range args.Specs {
+		if spec.IsRequired {
+			numRequired++
+			if spec.Name == "ENVIRONMENT" {
+				envNdx = ndx
+			}
+		}
+	}
+	if envNdx == -1 || len(argv) >= numRequired || envNdx > len(argv) {
+		return argv
+	}
+
+	result := append([]string{}, argv[:envNdx]...)
+	result = append(result, environment)
+	return append(result, argv[envNdx:]...)
+}
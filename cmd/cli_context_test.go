@@ -0,0 +1,128 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertContextEnvironment(t *testing.T) {
+	// Command with a required ENVIRONMENT and TAG, like 'deploy server'.
+	var env, tag string
+	deployArgs := &PositionalArgs{}
+	deployArgs.AddStringArgument(&env, "ENVIRONMENT", "")
+	deployArgs.AddStringArgument(&tag, "TAG", "")
+
+	assert.Equal(t, []string{"nimbly", "364cff09"}, insertContextEnvironment(deployArgs, []string{"364cff09"}, "nimbly"))
+	assert.Equal(t, []string{"nimbly"}, insertContextEnvironment(deployArgs, []string{}, "nimbly"))
+	// Environment given explicitly.
+	assert.Equal(t, []string{"tough-falcons", "364cff09"}, insertContextEnvironment(deployArgs, []string{"tough-falcons", "364cff09"}, "nimbly"))
+	// No active context.
+	assert.Equal(t, []string{"364cff09"}, insertContextEnvironment(deployArgs, []string{"364cff09"}, ""))
+
+	// Optional ENVIRONMENT is resolved later, in resolveEnvironment().
+	optionalArgs := &PositionalArgs{}
+	optionalArgs.AddStringArgumentOpt(&env, "ENVIRONMENT", "")
+	assert.Equal(t, []string{}, insertContextEnvironment(optionalArgs, []string{}, "nimbly"))
+
+	// Commands without an ENVIRONMENT argument.
+	var name string
+	otherArgs := &PositionalArgs{}
+	otherArgs.AddStringArgument(&name, "NAME", "")
+	assert.Equal(t, []string{}, insertContextEnvironment(otherArgs, []string{}, "nimbly"))
+}
+
+func TestCliContextPersistence(t *testing.T) {
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	t.Setenv(cliContextEnvVar, "debugging")
+
+	// No context initially.
+	cliCtx, err := loadCliContext()
+	require.NoError(t, err)
+	assert.Nil(t, cliCtx)
+
+	// Save and load the context.
+	saved := &cliContext{Name: "debugging", Environment: "tough-falcons", AuthProfile: "default", CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	require.NoError(t, saveCliContext(saved))
+	cliCtx, err = loadCliContext()
+	require.NoError(t, err)
+	assert.Equal(t, saved, cliCtx)
+
+	// Clear the context.
+	cleared, err := clearCliContext()
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	cleared, err = clearCliContext()
+	require.NoError(t, err)
+	assert.False(t, cleared)
+
+	// Invalid context name.
+	t.Setenv(cliContextEnvVar, "../escape")
+	_, err = loadCliContext()
+	assert.ErrorContains(t, err, "invalid METAPLAYCLI_CONTEXT")
+}
+
+func TestNewCliContext(t *testing.T) {
+	now := time.Now()
+	t.Setenv(auth.ProfileEnvVar, "work")
+
+	// Contexts of shells expire, as the process IDs get reused.
+	t.Setenv(cliContextEnvVar, "")
+	cliCtx := newCliContext("shell-1234", now)
+	assert.Equal(t, "work", cliCtx.AuthProfile)
+	assert.Equal(t, now.Add(cliShellContextTTL), cliCtx.ExpiresAt)
+	assert.False(t, cliCtx.isExpired(now))
+	assert.True(t, cliCtx.isExpired(now.Add(cliShellContextTTL)))
+
+	// Named contexts never expire.
+	t.Setenv(cliContextEnvVar, "debugging")
+	cliCtx = newCliContext("debugging", now)
+	assert.True(t, cliCtx.ExpiresAt.IsZero())
+	assert.False(t, cliCtx.isExpired(now.Add(365*24*time.Hour)))
+}
+
+func TestCliContextExpiry(t *testing.T) {
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	now := time.Now()
+
+	// An expired context of the current shell is ignored and removed.
+	t.Setenv(cliContextEnvVar, "expired")
+	expired := &cliContext{Name: "expired", Environment: "nimbly", CreatedAt: now.Add(-2 * cliShellContextTTL), ExpiresAt: now.Add(-time.Second)}
+	require.NoError(t, saveCliContext(expired))
+	cliCtx, err := loadCliContext()
+	require.NoError(t, err)
+	assert.Nil(t, cliCtx)
+	expiredFilePath, err := resolveCliContextFilePath(expired.Name)
+	require.NoError(t, err)
+	assert.NoFileExists(t, expiredFilePath)
+
+	// An expired context left behind by a closed shell.
+	stale := &cliContext{Name: "shell-1", Environment: "nimbly", CreatedAt: now.Add(-2 * cliShellContextTTL), ExpiresAt: now.Add(-cliShellContextTTL)}
+	require.NoError(t, saveCliContext(stale))
+	staleFilePath, err := resolveCliContextFilePath(stale.Name)
+	require.NoError(t, err)
+	assert.FileExists(t, staleFilePath)
+
+	// Saving a context removes the expired contexts of other shells, but keeps the valid ones.
+	valid := &cliContext{Name: "valid", Environment: "nimbly", CreatedAt: now, ExpiresAt: now.Add(cliShellContextTTL)}
+	require.NoError(t, saveCliContext(valid))
+	assert.NoFileExists(t, staleFilePath)
+	require.NoError(t, saveCliContext(&cliContext{Name: "current", Environment: "tough-falcons", CreatedAt: now}))
+	entries, err := os.ReadDir(filepath.Dir(staleFilePath))
+	require.NoError(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"valid.json", "current.json"}, names)
+}
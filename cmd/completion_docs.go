@@ -0,0 +1,154 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/version"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// Documentation formats supported by 'metaplay completion docs'.
+var completionDocsFormats = []string{"all", "markdown", "man"}
+
+// Generate manpages and markdown command reference from the command tree.
+type completionDocsOpts struct {
+	UsePositionalArgs
+
+	argOutputDir string
+	flagFormat   string
+}
+
+// initCompletionDocsCmd adds the 'docs' sub-command to the 'completion' command. Must be called
+// after the default completion command has been created.
+func initCompletionDocsCmd(root *cobra.Command) {
+	completionCmd, _, err := root.Find([]string{"completion"})
+	if err != nil || completionCmd == root {
+		log.Panic().Msg("The completion command must be initialized before adding 'completion docs'")
+	}
+
+	o := completionDocsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argOutputDir, "OUTPUT_DIR", "Directory to write the documentation into, eg, 'docs/cli'.")
+
+	cmd := &cobra.Command{
+		Use:   "docs OUTPUT_DIR [flags]",
+		Short: "Generate manpages and markdown command reference",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Generate manpages and a markdown command reference for all the commands of the CLI.
+
+			The documentation is generated from the command tree of the running binary, so it
+			always matches the commands and flags of the installed CLI version exactly. Hidden
+			commands are not included.
+
+			The markdown reference is written into OUTPUT_DIR/markdown/ with one file per command,
+			and the manpages into OUTPUT_DIR/man/ (section 1). Existing files are overwritten.
+			The markdown files don't contain timestamps, so re-generating the reference only
+			produces changes when the commands change.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay dev export-cli-reference' exports the command reference as JSON.
+		`),
+		Example: renderExample(`
+			# Generate both manpages and markdown reference into docs/cli/.
+			metaplay completion docs docs/cli
+
+			# Generate only the markdown reference.
+			metaplay completion docs docs/cli --format markdown
+
+			# Install the manpages for the current user.
+			metaplay completion docs /tmp/metaplay-docs --format man && cp /tmp/metaplay-docs/man/* ~/.local/share/man/man1/
+		`),
+	}
+	completionCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "all", "Documentation format to generate: all, markdown, or man")
+}
+
+func (o *completionDocsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(completionDocsFormats, o.flagFormat) {
+		return clierrors.NewUsageErrorf("Invalid format '%s'", o.flagFormat).
+			WithSuggestion(fmt.Sprintf("Use one of: %s", strings.Join(completionDocsFormats, ", ")))
+	}
+	return nil
+}
+
+func (o *completionDocsOpts) Run(cmd *cobra.Command) error {
+	root := cmd.Root()
+	prepareCommandTreeForDocs(root)
+
+	if o.flagFormat == "all" || o.flagFormat == "markdown" {
+		markdownDir := filepath.Join(o.argOutputDir, "markdown")
+		if err := generateMarkdownDocs(root, markdownDir); err != nil {
+			return err
+		}
+		log.Info().Msgf("✅ Wrote markdown command reference to %s", styles.RenderTechnical(markdownDir))
+	}
+
+	if o.flagFormat == "all" || o.flagFormat == "man" {
+		manDir := filepath.Join(o.argOutputDir, "man")
+		if err := generateManDocs(root, manDir); err != nil {
+			return err
+		}
+		log.Info().Msgf("✅ Wrote manpages to %s", styles.RenderTechnical(manDir))
+	}
+
+	return nil
+}
+
+// prepareCommandTreeForDocs strips the terminal styling from the command descriptions and
+// disables the generation timestamps in the markdown files. Modifies the commands in place,
+// so should only be called right before generating the docs.
+func prepareCommandTreeForDocs(cmd *cobra.Command) {
+	cmd.DisableAutoGenTag = true
+	cmd.Short = sanitizeText(cmd.Short)
+	cmd.Long = sanitizeText(cmd.Long)
+	cmd.Example = ansiSequencePattern.ReplaceAllString(cmd.Example, "")
+	for _, subCmd := range cmd.Commands() {
+		prepareCommandTreeForDocs(subCmd)
+	}
+}
+
+// generateMarkdownDocs writes the markdown reference of the command tree into the directory.
+func generateMarkdownDocs(root *cobra.Command, dirPath string) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return clierrors.Wrapf(err, "Failed to create directory %s", dirPath)
+	}
+	if err := doc.GenMarkdownTree(root, dirPath); err != nil {
+		return clierrors.Wrap(err, "Failed to generate markdown command reference")
+	}
+	return nil
+}
+
+// generateManDocs writes the manpages of the command tree into the directory.
+func generateManDocs(root *cobra.Command, dirPath string) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return clierrors.Wrapf(err, "Failed to create directory %s", dirPath)
+	}
+	header := &doc.GenManHeader{
+		Title:   strings.ToUpper(root.Name()),
+		Section: "1",
+		Source:  fmt.Sprintf("Metaplay CLI %s", version.AppVersion),
+		Manual:  "Metaplay CLI Manual",
+	}
+	if err := doc.GenManTree(root, header, dirPath); err != nil {
+		return clierrors.Wrap(err, "Failed to generate manpages")
+	}
+	return nil
+}
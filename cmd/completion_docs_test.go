@@ -0,0 +1,62 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDocsCommandTree() *cobra.Command {
+	root := &cobra.Command{Use: "metaplay", Short: "Metaplay CLI"}
+	deploy := &cobra.Command{Use: "deploy", Short: "Deploy things"}
+	server := &cobra.Command{
+		Use:   "server ENVIRONMENT",
+		Short: "Deploy the game server",
+		Long:  "\x1b[1mNote:\x1b[0m Deploys the game server.",
+		Run:   func(cmd *cobra.Command, args []string) {},
+	}
+	server.Flags().Bool("dry-run", false, "Only show what would be deployed")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(cmd *cobra.Command, args []string) {}}
+
+	root.AddCommand(deploy)
+	deploy.AddCommand(server, hidden)
+	return root
+}
+
+func TestPrepareCommandTreeForDocs(t *testing.T) {
+	root := newTestDocsCommandTree()
+	prepareCommandTreeForDocs(root)
+
+	server, _, err := root.Find([]string{"deploy", "server"})
+	require.NoError(t, err)
+	assert.Equal(t, "Note: Deploys the game server.", server.Long)
+	assert.True(t, server.DisableAutoGenTag)
+}
+
+func TestGenerateDocs(t *testing.T) {
+	root := newTestDocsCommandTree()
+	prepareCommandTreeForDocs(root)
+	dirPath := t.TempDir()
+
+	require.NoError(t, generateMarkdownDocs(root, filepath.Join(dirPath, "markdown")))
+	markdown, err := os.ReadFile(filepath.Join(dirPath, "markdown", "metaplay_deploy_server.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "## metaplay deploy server")
+	assert.Contains(t, string(markdown), "--dry-run")
+	assert.NotContains(t, string(markdown), "Auto generated")
+	assert.NoFileExists(t, filepath.Join(dirPath, "markdown", "metaplay_deploy_secret.md"))
+
+	require.NoError(t, generateManDocs(root, filepath.Join(dirPath, "man")))
+	manPage, err := os.ReadFile(filepath.Join(dirPath, "man", "metaplay-deploy-server.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(manPage), `.TH "METAPLAY"`)
+	assert.Contains(t, string(manPage), "dry-run")
+}
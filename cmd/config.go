@@ -0,0 +1,19 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd includes commands for managing the per-user settings of the CLI.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Commands for managing the CLI's per-user settings",
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
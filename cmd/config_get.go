@@ -0,0 +1,65 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Get the value of a per-user setting of the CLI.
+type configGetOpts struct {
+	UsePositionalArgs
+
+	argKey string
+}
+
+func init() {
+	o := configGetOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argKey, "KEY", "Key of the setting, eg, 'outputFormat'.")
+
+	cmd := &cobra.Command{
+		Use:   "get KEY [flags]",
+		Short: "Get the value of a CLI setting",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Print the value of a per-user setting of the CLI. Prints an empty line if the setting
+			is not set.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay config list' to list all the settings and their values.
+			- 'metaplay config set KEY VALUE' to change a setting.
+		`),
+		Example: renderExample(`
+			# Get the default output format.
+			metaplay config get outputFormat
+		`),
+	}
+	configCmd.AddCommand(cmd)
+}
+
+func (o *configGetOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if _, err := common.FindUserConfigSetting(o.argKey); err != nil {
+		return clierrors.WrapUsageError(err, "Invalid setting").
+			WithSuggestion("Run 'metaplay config list' to see the available settings")
+	}
+	return nil
+}
+
+func (o *configGetOpts) Run(cmd *cobra.Command) error {
+	_, config, err := loadUserConfigForEdit()
+	if err != nil {
+		return err
+	}
+	setting, _ := common.FindUserConfigSetting(o.argKey)
+	log.Info().Msg(setting.Get(config))
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// List the per-user settings of the CLI.
+type configListOpts struct {
+	flagFormat string
+}
+
+// configListResult is the JSON output of 'metaplay config list'.
+type configListResult struct {
+	FilePath string              `json:"filePath"`
+	Settings []configSettingInfo `json:"settings"`
+}
+
+// configSettingInfo is a setting along with its current value (empty if not set).
+type configSettingInfo struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+}
+
+func init() {
+	o := configListOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the CLI's per-user settings",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			List all the per-user settings of the CLI along with their current values.
+
+			The settings are stored in config.yaml in the CLI's config directory, eg,
+			~/.config/metaplay/config.yaml on Linux. See 'metaplay state show' for the location.
+
+			Related commands:
+			- 'metaplay config get KEY' to get the value of a setting.
+			- 'metaplay config set KEY VALUE' to change a setting.
+			- 'metaplay config unset KEY' to reset a setting to its default.
+		`),
+		Example: renderExample(`
+			# List the settings.
+			metaplay config list
+
+			# Output the settings as JSON.
+			metaplay config list --format=json
+		`),
+	}
+	configCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *configListOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *configListOpts) Run(cmd *cobra.Command) error {
+	filePath, config, err := loadUserConfigForEdit()
+	if err != nil {
+		return err
+	}
+
+	result := configListResult{FilePath: filePath}
+	for _, setting := range common.UserConfigSettings {
+		result.Settings = append(result.Settings, configSettingInfo{
+			Key:         setting.Key,
+			Value:       setting.Get(config),
+			Description: setting.Description,
+		})
+	}
+
+	if o.flagFormat == "json" {
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal settings as JSON")
+		}
+		log.Info().Msg(string(resultJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("CLI Settings"))
+	log.Info().Msg("")
+	log.Info().Msgf("File: %s", styles.RenderTechnical(filePath))
+	log.Info().Msg("")
+	for _, setting := range result.Settings {
+		value := styles.RenderMuted("(not set)")
+		if setting.Value != "" {
+			value = styles.RenderTechnical(setting.Value)
+		}
+		log.Info().Msgf("  %-16s %s", setting.Key+":", value)
+		log.Info().Msgf("  %-16s %s", "", styles.RenderMuted(setting.Description))
+	}
+	log.Info().Msg("")
+	return nil
+}
+
+// loadUserConfigForEdit loads the per-user settings for the 'metaplay config' commands. Unlike
+// when running other commands, a broken settings file is an error.
+func loadUserConfigForEdit() (string, *common.UserConfig, error) {
+	filePath, err := common.ResolveUserConfigFilePath()
+	if err != nil {
+		return "", nil, clierrors.Wrap(err, "Failed to resolve the CLI settings file")
+	}
+	config, err := common.LoadUserConfig()
+	if err != nil {
+		return "", nil, clierrors.Wrap(err, "Failed to load the CLI settings").
+			WithSuggestion("Fix or remove the settings file " + filePath)
+	}
+	return filePath, config, nil
+}
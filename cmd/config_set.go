@@ -0,0 +1,113 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Set the value of a per-user setting of the CLI.
+type configSetOpts struct {
+	UsePositionalArgs
+
+	argKey   string
+	argValue string
+}
+
+func init() {
+	o := configSetOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argKey, "KEY", "Key of the setting, eg, 'outputFormat'.")
+	args.AddStringArgument(&o.argValue, "VALUE", "New value of the setting, eg, 'json'.")
+
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE [flags]",
+		Short: "Set the value of a CLI setting",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Set a per-user setting of the CLI, so that the same flags don't need to be repeated on
+			every invocation. Flags and environment variables given explicitly always take
+			precedence over the settings.
+
+			The available settings are:
+			- outputFormat: Default output format of the commands with a --format flag ('text' or 'json').
+			- color: Whether to color the output ('yes', 'no' or 'auto'), like --color.
+			- projectPath: Project directory to use when not running in any project directory, like --project.
+			- telemetryOptOut: Opt out of the telemetry of the tools run by the CLI, eg, the .NET SDK ('true' or 'false').
+			- portalBaseURL: Base URL of the Metaplay portal, like METAPLAYCLI_PORTAL_BASEURL.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay config list' to list all the settings and their values.
+			- 'metaplay config unset KEY' to reset a setting to its default.
+		`),
+		Example: renderExample(`
+			# Output JSON from all the commands with a --format flag.
+			metaplay config set outputFormat json
+
+			# Never color the output.
+			metaplay config set color no
+
+			# Use the given project when running outside of any project directory.
+			metaplay config set projectPath ~/projects/MyGame
+
+			# Opt out of the telemetry of the .NET SDK and other tools.
+			metaplay config set telemetryOptOut true
+		`),
+	}
+	configCmd.AddCommand(cmd)
+}
+
+func (o *configSetOpts) Prepare(cmd *cobra.Command, args []string) error {
+	setting, err := common.FindUserConfigSetting(o.argKey)
+	if err != nil {
+		return clierrors.WrapUsageError(err, "Invalid setting").
+			WithSuggestion("Run 'metaplay config list' to see the available settings")
+	}
+
+	// Store the project path as absolute, so that it works from any directory.
+	if setting.Key == "projectPath" && o.argValue != "" {
+		absPath, err := filepath.Abs(o.argValue)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to resolve the absolute path of '%s'", o.argValue)
+		}
+		o.argValue = absPath
+	}
+
+	if o.argValue == "" {
+		return clierrors.NewUsageError("VALUE must not be empty").
+			WithSuggestion("Use 'metaplay config unset " + setting.Key + "' to reset the setting")
+	}
+	if err := setting.Set(&common.UserConfig{}, o.argValue); err != nil {
+		return clierrors.WrapUsageError(err, fmt.Sprintf("Invalid value for %s", setting.Key))
+	}
+	return nil
+}
+
+func (o *configSetOpts) Run(cmd *cobra.Command) error {
+	_, config, err := loadUserConfigForEdit()
+	if err != nil {
+		return err
+	}
+	setting, _ := common.FindUserConfigSetting(o.argKey)
+	if err := setting.Set(config, o.argValue); err != nil {
+		return clierrors.WrapUsageError(err, fmt.Sprintf("Invalid value for %s", setting.Key))
+	}
+	if err := common.SaveUserConfig(config); err != nil {
+		return clierrors.Wrap(err, "Failed to save the CLI settings")
+	}
+
+	log.Info().Msgf("%s Set %s to %s", styles.RenderSuccess("✓"), styles.RenderTechnical(setting.Key), styles.RenderTechnical(setting.Get(config)))
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Reset a per-user setting of the CLI to its default.
+type configUnsetOpts struct {
+	UsePositionalArgs
+
+	argKey string
+}
+
+func init() {
+	o := configUnsetOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argKey, "KEY", "Key of the setting, eg, 'outputFormat'.")
+
+	cmd := &cobra.Command{
+		Use:   "unset KEY [flags]",
+		Short: "Reset a CLI setting to its default",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Remove a per-user setting of the CLI, so that its default is used again.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay config list' to list all the settings and their values.
+			- 'metaplay config set KEY VALUE' to change a setting.
+		`),
+		Example: renderExample(`
+			# Use the default output format again.
+			metaplay config unset outputFormat
+		`),
+	}
+	configCmd.AddCommand(cmd)
+}
+
+func (o *configUnsetOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if _, err := common.FindUserConfigSetting(o.argKey); err != nil {
+		return clierrors.WrapUsageError(err, "Invalid setting").
+			WithSuggestion("Run 'metaplay config list' to see the available settings")
+	}
+	return nil
+}
+
+func (o *configUnsetOpts) Run(cmd *cobra.Command) error {
+	_, config, err := loadUserConfigForEdit()
+	if err != nil {
+		return err
+	}
+	setting, _ := common.FindUserConfigSetting(o.argKey)
+	if setting.Get(config) == "" {
+		log.Info().Msgf("%s is not set", styles.RenderTechnical(setting.Key))
+		return nil
+	}
+	if err := setting.Set(config, ""); err != nil {
+		return err
+	}
+	if err := common.SaveUserConfig(config); err != nil {
+		return clierrors.Wrap(err, "Failed to save the CLI settings")
+	}
+
+	log.Info().Msgf("%s Reset %s to its default", styles.RenderSuccess("✓"), styles.RenderTechnical(setting.Key))
+	return nil
+}
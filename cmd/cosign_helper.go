@@ -0,0 +1,166 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/rs/zerolog/log"
+)
+
+// checkCosignInstalled checks that the cosign CLI is available for signing or verifying images.
+func checkCosignInstalled(ctx context.Context) error {
+	if err := checkCommand(ctx, "cosign", "version"); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return clierrors.New("cosign is not installed or not in PATH").
+			WithSuggestion("Install cosign from https://docs.sigstore.dev/cosign/system_config/installation/")
+	}
+	return nil
+}
+
+// resolveRemoteImageDigestRef resolves the image in the remote repository to a reference by its
+// manifest digest, eg, 'repo@sha256:1a2b3c', so that the signature is bound to the exact image
+// instead of a (mutable) tag.
+func resolveRemoteImageDigestRef(dockerCredentials *envapi.DockerCredentials, remoteImageName string) (string, error) {
+	digests, exists, err := envapi.FetchRemoteDockerImageDigests(dockerCredentials, remoteImageName)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("remote docker image '%s' not found", remoteImageName)
+	}
+	ref, err := name.ParseReference(remoteImageName, name.WithDefaultRegistry(dockerCredentials.RegistryURL))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse remote docker image reference '%s': %w", remoteImageName, err)
+	}
+	return fmt.Sprintf("%s@%s", ref.Context().Name(), digests.ManifestDigest), nil
+}
+
+// cosignSignArgs returns the arguments for signing the image with cosign. Without a key, the
+// image is signed keyless using the ambient OIDC identity (eg, in GitHub Actions) or a browser login.
+func cosignSignArgs(imageDigestRef string, key string) []string {
+	args := []string{"sign", "--yes"}
+	if key != "" {
+		args = append(args, "--key", key)
+	}
+	return append(args, imageDigestRef)
+}
+
+// cosignVerifyArgs returns the arguments for verifying the image's signature with cosign, either
+// with the public key or the keyless signer's identity from the project's signing config.
+func cosignVerifyArgs(imageDigestRef string, signing *metaproj.ImageSigningConfig) ([]string, error) {
+	args := []string{"verify"}
+	switch {
+	case signing != nil && signing.PublicKey != "":
+		args = append(args, "--key", signing.PublicKey)
+	case signing != nil && signing.CertificateIdentityRegexp != "":
+		args = append(args,
+			"--certificate-identity-regexp", signing.CertificateIdentityRegexp,
+			"--certificate-oidc-issuer", signing.CertificateOidcIssuer)
+	default:
+		return nil, clierrors.New("No public key or signer identity configured for verifying the image signatures").
+			WithSuggestion("Specify 'imageSigning.publicKey' or 'imageSigning.certificateIdentityRegexp' in metaplay-project.yaml")
+	}
+	return append(args, "--output", "json", imageDigestRef), nil
+}
+
+// signRemoteImage signs the image in the environment's repository with cosign. The signature is
+// stored in the same repository. Returns the digest reference of the signed image.
+func signRemoteImage(ctx context.Context, output *tui.TaskOutput, dockerCredentials *envapi.DockerCredentials, remoteImageName string, key string) (string, error) {
+	imageDigestRef, err := resolveRemoteImageDigestRef(dockerCredentials, remoteImageName)
+	if err != nil {
+		return "", err
+	}
+
+	output.AppendLinef("Signing image %s", imageDigestRef)
+	if err := runCosign(ctx, dockerCredentials, cosignSignArgs(imageDigestRef, key)...); err != nil {
+		return "", fmt.Errorf("failed to sign image: %w", err)
+	}
+	return imageDigestRef, nil
+}
+
+// verifyRemoteImageSignature verifies the cosign signature of the image in the environment's
+// repository against the project's signing config.
+func verifyRemoteImageSignature(ctx context.Context, dockerCredentials *envapi.DockerCredentials, remoteImageName string, signing *metaproj.ImageSigningConfig) error {
+	imageDigestRef, err := resolveRemoteImageDigestRef(dockerCredentials, remoteImageName)
+	if err != nil {
+		return err
+	}
+
+	args, err := cosignVerifyArgs(imageDigestRef, signing)
+	if err != nil {
+		return err
+	}
+	return runCosign(ctx, dockerCredentials, args...)
+}
+
+// runCosign runs cosign with the given arguments, the last of which is the image. The registry credentials
+// are passed to cosign in a temporary Docker config file, so that they don't show up in the
+// process list or need a 'docker login'.
+func runCosign(ctx context.Context, dockerCredentials *envapi.DockerCredentials, args ...string) error {
+	dockerConfigDir, err := os.MkdirTemp("", "metaplay-cosign-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary docker config directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dockerConfigDir) }()
+
+	dockerConfig, err := renderCosignDockerConfig(dockerCredentials, args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dockerConfigDir, "config.json"), dockerConfig, 0600); err != nil {
+		return fmt.Errorf("failed to write temporary docker config: %w", err)
+	}
+
+	log.Debug().Msgf("Run cosign %s", strings.Join(args, " "))
+	cmd := exec.CommandContext(ctx, "cosign", args...)
+	cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+dockerConfigDir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		trimmed := strings.TrimSpace(stderr.String())
+		if trimmed != "" {
+			return fmt.Errorf("cosign %s failed: %s", args[0], truncateForLog(trimmed, 500))
+		}
+		return fmt.Errorf("cosign %s failed: %v", args[0], err)
+	}
+	return nil
+}
+
+// renderCosignDockerConfig renders a Docker config.json with the credentials for the registry of
+// the image digest reference.
+func renderCosignDockerConfig(dockerCredentials *envapi.DockerCredentials, imageDigestRef string) ([]byte, error) {
+	repositoryName, _, _ := strings.Cut(imageDigestRef, "@")
+	repository, err := name.NewRepository(repositoryName, name.WithDefaultRegistry(dockerCredentials.RegistryURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference '%s': %w", imageDigestRef, err)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(dockerCredentials.Username + ":" + dockerCredentials.Password))
+	config := map[string]any{
+		"auths": map[string]any{
+			repository.RegistryStr(): map[string]string{"auth": auth},
+		},
+	}
+	return json.Marshal(config)
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCosignSignArgs(t *testing.T) {
+	imageRef := "123.dkr.ecr.eu-west-1.amazonaws.com/nimbly@sha256:1a2b3c"
+
+	assert.Equal(t, []string{"sign", "--yes", imageRef}, cosignSignArgs(imageRef, ""))
+	assert.Equal(t, []string{"sign", "--yes", "--key", "awskms:///alias/signing", imageRef}, cosignSignArgs(imageRef, "awskms:///alias/signing"))
+}
+
+func TestCosignVerifyArgs(t *testing.T) {
+	imageRef := "123.dkr.ecr.eu-west-1.amazonaws.com/nimbly@sha256:1a2b3c"
+
+	args, err := cosignVerifyArgs(imageRef, &metaproj.ImageSigningConfig{PublicKey: "cosign.pub"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"verify", "--key", "cosign.pub", "--output", "json", imageRef}, args)
+
+	args, err = cosignVerifyArgs(imageRef, &metaproj.ImageSigningConfig{
+		CertificateIdentityRegexp: "^https://github.com/myorg/",
+		CertificateOidcIssuer:     "https://token.actions.githubusercontent.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"verify",
+		"--certificate-identity-regexp", "^https://github.com/myorg/",
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+		"--output", "json", imageRef,
+	}, args)
+
+	// Signing key alone cannot be used for verifying.
+	_, err = cosignVerifyArgs(imageRef, &metaproj.ImageSigningConfig{Key: "cosign.key"})
+	assert.Error(t, err)
+	_, err = cosignVerifyArgs(imageRef, nil)
+	assert.Error(t, err)
+}
+
+func TestRenderCosignDockerConfig(t *testing.T) {
+	creds := &envapi.DockerCredentials{Username: "AWS", Password: "secret", RegistryURL: "123.dkr.ecr.eu-west-1.amazonaws.com"}
+	content, err := renderCosignDockerConfig(creds, "123.dkr.ecr.eu-west-1.amazonaws.com/nimbly@sha256:1a2b3c")
+	require.NoError(t, err)
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	require.NoError(t, json.Unmarshal(content, &config))
+	require.Contains(t, config.Auths, "123.dkr.ecr.eu-west-1.amazonaws.com")
+	decoded, err := base64.StdEncoding.DecodeString(config.Auths["123.dkr.ecr.eu-west-1.amazonaws.com"].Auth)
+	require.NoError(t, err)
+	assert.Equal(t, "AWS:secret", string(decoded))
+}
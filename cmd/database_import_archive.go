@@ -131,6 +131,16 @@ func (o *databaseImportArchiveOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDestroy); err != nil {
+		return err
+	}
+
 	// Check if this is a production environment and require additional confirmation. A dry run imports
 	// nothing, so it doesn't require --confirm-production.
 	if envConfig.Type == portalapi.EnvironmentTypeProduction && !o.flagConfirmProduction && !o.flagDryRun {
@@ -0,0 +1,401 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Query for the sizes of all tables in the current database, largest first.
+const databaseTableSizesQuery = "SELECT TABLE_NAME, COALESCE(TABLE_ROWS, 0), COALESCE(DATA_LENGTH, 0), COALESCE(INDEX_LENGTH, 0) " +
+	"FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() " +
+	"ORDER BY COALESCE(DATA_LENGTH, 0) + COALESCE(INDEX_LENGTH, 0) DESC, TABLE_NAME;"
+
+// Query for the replication lag of Aurora replicas (only available on Aurora MySQL).
+const databaseAuroraReplicaLagQuery = "SELECT MAX(REPLICA_LAG_IN_MILLISECONDS) FROM information_schema.REPLICA_HOST_STATUS " +
+	"WHERE SESSION_ID <> 'MASTER_SESSION_ID';"
+
+// Query for the replication status of a MySQL/MariaDB replica (vertical output).
+const databaseReplicaStatusQuery = "SHOW SLAVE STATUS\\G"
+
+// databaseTableInfo is the size information of a single table in a database shard.
+type databaseTableInfo struct {
+	Name           string `json:"name"`
+	EstimatedRows  int64  `json:"estimatedRows"`
+	DataSizeBytes  int64  `json:"dataSizeBytes"`
+	IndexSizeBytes int64  `json:"indexSizeBytes"`
+}
+
+// databaseShardInfo is the information collected about a single database shard.
+type databaseShardInfo struct {
+	ShardIndex            int                 `json:"shardIndex"`
+	DatabaseName          string              `json:"databaseName"`
+	NumTables             int                 `json:"numTables"`
+	EstimatedRows         int64               `json:"estimatedRows"`
+	DataSizeBytes         int64               `json:"dataSizeBytes"`
+	IndexSizeBytes        int64               `json:"indexSizeBytes"`
+	MasterVersion         *int                `json:"masterVersion,omitempty"`
+	ReplicationLagSeconds *float64            `json:"replicationLagSeconds,omitempty"`
+	Tables                []databaseTableInfo `json:"tables"`
+	Error                 string              `json:"error,omitempty"`
+}
+
+type databaseInfoOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagFormat     string
+	flagTables     int
+}
+
+func init() {
+	o := databaseInfoOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "info ENVIRONMENT [flags]",
+		Short: "Show the table sizes and status of each database shard in an environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show information about each database shard in the target environment: the number of
+			tables, the estimated number of rows, the data and index sizes, the database master
+			version (from the MetaInfo table), and the replication lag of the read replica, if
+			available.
+
+			This command starts a temporary debug pod and runs the queries with a mariadb client
+			inside it, connecting to the read-only replica of each shard. The row counts and sizes
+			are estimates maintained by the database and can lag behind the actual values.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay debug database' connects to a database shard interactively.
+			- 'metaplay database export-archive' exports the database contents into a file.
+		`),
+		Example: renderExample(`
+			# Show the database shards of environment 'nimbly'.
+			metaplay database info nimbly
+
+			# Also show the 10 largest tables of each shard.
+			metaplay database info nimbly --tables=10
+
+			# Output all the information, including all tables, as JSON.
+			metaplay database info nimbly --format=json
+		`),
+	}
+	databaseCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+	flags.IntVar(&o.flagTables, "tables", 0, "Number of largest tables to show for each shard in text output (-1 for all)")
+}
+
+func (o *databaseInfoOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	if o.flagTables < -1 {
+		return clierrors.NewUsageErrorf("Invalid number of tables %d", o.flagTables).
+			WithSuggestion("Use a non-negative number, or -1 for all tables")
+	}
+	return nil
+}
+
+func (o *databaseInfoOpts) Run(cmd *cobra.Command) error {
+	// Resolve the project & auth provider
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment config
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the database shard configuration from Kubernetes secret
+	log.Debug().Str("namespace", kubeCli.Namespace).Msg("Fetching database shard configuration")
+	shards, err := kubeutil.FetchDatabaseShardsFromSecret(cmd.Context(), kubeCli, kubeCli.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if o.flagFormat == "text" {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Database Info"))
+		log.Info().Msg("")
+		log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.HumanID))
+		log.Info().Msgf("Shards:      %s", styles.RenderTechnical(strconv.Itoa(len(shards))))
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderMuted("Inspecting the database shards..."))
+	}
+
+	// Create a debug pod to run the mariadb client in.
+	log.Debug().Msg("Creating debug pod for database inspection")
+	podName, cleanup, err := kubeutil.CreateDebugPod(
+		cmd.Context(),
+		kubeCli,
+		debugDatabaseImage,
+		false,
+		false,
+		[]string{"sleep", "3600"},
+	)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Inspect each shard. Failures are recorded per shard so that the other shards are still shown.
+	shardInfos := []databaseShardInfo{}
+	numFailed := 0
+	for _, shard := range shards {
+		info := inspectDatabaseShard(cmd.Context(), kubeCli, podName, "debug", shard)
+		if info.Error != "" {
+			numFailed++
+		}
+		shardInfos = append(shardInfos, info)
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		shardsJSON, err := json.MarshalIndent(shardInfos, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal database info as JSON")
+		}
+		log.Info().Msg(string(shardsJSON))
+	} else {
+		log.Info().Msg("")
+		for _, line := range renderDatabaseShardsTable(shardInfos) {
+			log.Info().Msg(line)
+		}
+		if o.flagTables != 0 {
+			for _, info := range shardInfos {
+				log.Info().Msg("")
+				for _, line := range renderDatabaseTablesTable(info, o.flagTables) {
+					log.Info().Msg(line)
+				}
+			}
+		}
+		log.Info().Msg("")
+	}
+
+	if numFailed > 0 {
+		return clierrors.Newf("Failed to inspect %d of %d database shards", numFailed, len(shards)).
+			WithSuggestion("Run with --verbose for more details")
+	}
+	return nil
+}
+
+// inspectDatabaseShard collects the information about a single database shard, connecting to
+// its read-only replica. The table sizes are required, the other information is best-effort.
+func inspectDatabaseShard(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, shard kubeutil.DatabaseShardConfig) databaseShardInfo {
+	info := databaseShardInfo{
+		ShardIndex:   shard.ShardIndex,
+		DatabaseName: shard.DatabaseName,
+		Tables:       []databaseTableInfo{},
+	}
+
+	runQuery := func(query string) (string, error) {
+		return execDatabaseQuery(ctx, kubeCli, podName, debugContainerName, shard.ReadOnlyHost, shard.UserId, shard.Password, shard.DatabaseName, query)
+	}
+
+	// Table sizes.
+	output, err := runQuery(databaseTableSizesQuery)
+	if err != nil {
+		log.Debug().Err(err).Int("shard_index", shard.ShardIndex).Msg("Failed to query table sizes")
+		info.Error = fmt.Sprintf("failed to query table sizes: %v", err)
+		return info
+	}
+	tables, err := parseDatabaseTableSizes(output)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Tables = tables
+	info.NumTables = len(tables)
+	for _, table := range tables {
+		info.EstimatedRows += table.EstimatedRows
+		info.DataSizeBytes += table.DataSizeBytes
+		info.IndexSizeBytes += table.IndexSizeBytes
+	}
+
+	// Database master version (best-effort).
+	info.MasterVersion = queryDatabaseMasterVersion(ctx, kubeCli, podName, debugContainerName, shard.ReadOnlyHost, shard.UserId, shard.Password, shard.DatabaseName)
+
+	// Replication lag (best-effort): Aurora exposes it in information_schema, plain MySQL and
+	// MariaDB replicas in the replica status.
+	if output, err := runQuery(databaseAuroraReplicaLagQuery); err == nil {
+		info.ReplicationLagSeconds = parseAuroraReplicaLag(output)
+	} else {
+		log.Debug().Err(err).Int("shard_index", shard.ShardIndex).Msg("Aurora replica lag not available")
+	}
+	if info.ReplicationLagSeconds == nil {
+		if output, err := runQuery(databaseReplicaStatusQuery); err == nil {
+			info.ReplicationLagSeconds = parseReplicaStatusLag(output)
+		} else {
+			log.Debug().Err(err).Int("shard_index", shard.ShardIndex).Msg("Replica status not available")
+		}
+	}
+
+	return info
+}
+
+// parseDatabaseTableSizes parses the output of databaseTableSizesQuery.
+func parseDatabaseTableSizes(output string) ([]databaseTableInfo, error) {
+	tables := []databaseTableInfo{}
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+
+		columns := strings.Split(line, "\t")
+		if len(columns) != 4 {
+			return nil, fmt.Errorf("unexpected table sizes row '%s'", line)
+		}
+
+		values := make([]int64, 3)
+		for ndx := range values {
+			value, err := strconv.ParseInt(columns[ndx+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value '%s' in table sizes row '%s'", columns[ndx+1], line)
+			}
+			values[ndx] = value
+		}
+
+		tables = append(tables, databaseTableInfo{
+			Name:           columns[0],
+			EstimatedRows:  values[0],
+			DataSizeBytes:  values[1],
+			IndexSizeBytes: values[2],
+		})
+	}
+	return tables, nil
+}
+
+// parseAuroraReplicaLag parses the output of databaseAuroraReplicaLagQuery into seconds.
+// Returns nil if there are no replicas.
+func parseAuroraReplicaLag(output string) *float64 {
+	lagMillis, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil {
+		return nil
+	}
+	lagSeconds := lagMillis / 1000
+	return &lagSeconds
+}
+
+// parseReplicaStatusLag parses the 'Seconds_Behind_Master' from the output of
+// databaseReplicaStatusQuery. Returns nil if the server is not a replica or the lag is
+// unknown (replication not running).
+func parseReplicaStatusLag(output string) *float64 {
+	for line := range strings.SplitSeq(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found || key != "Seconds_Behind_Master" {
+			continue
+		}
+		lagSeconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil
+		}
+		return &lagSeconds
+	}
+	return nil
+}
+
+// renderDatabaseShardsTable renders the summary of each shard as a table.
+func renderDatabaseShardsTable(shardInfos []databaseShardInfo) []string {
+	lines := []string{
+		fmt.Sprintf("  %-6s  %-7s  %-14s  %-10s  %-10s  %-14s  %s", "SHARD", "TABLES", "ROWS (EST.)", "DATA", "INDEX", "MASTER VER.", "REPLICA LAG"),
+		"",
+	}
+
+	for _, info := range shardInfos {
+		shard := fmt.Sprintf("#%d", info.ShardIndex)
+		if info.Error != "" {
+			lines = append(lines, fmt.Sprintf("  %s  %s", styles.RenderTechnical(fmt.Sprintf("%-6s", shard)), styles.RenderError(info.Error)))
+			continue
+		}
+
+		masterVersion := "-"
+		if info.MasterVersion != nil {
+			masterVersion = strconv.Itoa(*info.MasterVersion)
+		}
+		replicaLag := "-"
+		if info.ReplicationLagSeconds != nil {
+			replicaLag = fmt.Sprintf("%.1fs", *info.ReplicationLagSeconds)
+		}
+
+		// Pad plain text before applying ANSI styles.
+		lines = append(lines, fmt.Sprintf("  %s  %-7d  %-14d  %-10s  %-10s  %-14s  %s",
+			styles.RenderTechnical(fmt.Sprintf("%-6s", shard)),
+			info.NumTables,
+			info.EstimatedRows,
+			formatImageSize(info.DataSizeBytes),
+			formatImageSize(info.IndexSizeBytes),
+			masterVersion,
+			replicaLag,
+		))
+	}
+
+	return lines
+}
+
+// renderDatabaseTablesTable renders the largest tables of the shard as a table. With a
+// negative maxTables, all the tables are shown.
+func renderDatabaseTablesTable(info databaseShardInfo, maxTables int) []string {
+	lines := []string{styles.RenderTitle(fmt.Sprintf("Shard #%d tables", info.ShardIndex))}
+	if info.Error != "" || len(info.Tables) == 0 {
+		return append(lines, styles.RenderMuted("  No tables found"))
+	}
+
+	tables := info.Tables
+	if maxTables >= 0 && len(tables) > maxTables {
+		tables = tables[:maxTables]
+	}
+
+	nameW := len("TABLE")
+	for _, table := range tables {
+		nameW = max(nameW, len(table.Name))
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("  %-*s  %-14s  %-10s  %s", nameW, "TABLE", "ROWS (EST.)", "DATA", "INDEX"),
+		"",
+	)
+	for _, table := range tables {
+		lines = append(lines, fmt.Sprintf("  %s  %-14d  %-10s  %s",
+			styles.RenderTechnical(fmt.Sprintf("%-*s", nameW, table.Name)),
+			table.EstimatedRows,
+			formatImageSize(table.DataSizeBytes),
+			formatImageSize(table.IndexSizeBytes),
+		))
+	}
+
+	if numHidden := len(info.Tables) - len(tables); numHidden > 0 {
+		lines = append(lines, "", styles.RenderMuted(fmt.Sprintf("  %d smaller tables not shown. Use --tables=-1 to see all.", numHidden)))
+	}
+	return lines
+}
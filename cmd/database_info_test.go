@@ -0,0 +1,71 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDatabaseTableSizes(t *testing.T) {
+	output := "Players\t1200\t16384000\t2097152\nMetaInfo\t1\t16384\t0\n"
+	tables, err := parseDatabaseTableSizes(output)
+	require.NoError(t, err)
+	assert.Equal(t, []databaseTableInfo{
+		{Name: "Players", EstimatedRows: 1200, DataSizeBytes: 16384000, IndexSizeBytes: 2097152},
+		{Name: "MetaInfo", EstimatedRows: 1, DataSizeBytes: 16384, IndexSizeBytes: 0},
+	}, tables)
+
+	// Empty database.
+	tables, err = parseDatabaseTableSizes("")
+	require.NoError(t, err)
+	assert.Empty(t, tables)
+
+	// Malformed output.
+	_, err = parseDatabaseTableSizes("Players\t1200")
+	assert.Error(t, err)
+	_, err = parseDatabaseTableSizes("Players\tNULL\t0\t0")
+	assert.Error(t, err)
+}
+
+func TestParseReplicationLag(t *testing.T) {
+	lag := parseAuroraReplicaLag("1520\n")
+	require.NotNil(t, lag)
+	assert.InDelta(t, 1.52, *lag, 0.0001)
+	assert.Nil(t, parseAuroraReplicaLag("NULL\n"))
+
+	status := "*************************** 1. row ***************************\n" +
+		"               Slave_IO_State: Waiting for master to send event\n" +
+		"        Seconds_Behind_Master: 3\n"
+	lag = parseReplicaStatusLag(status)
+	require.NotNil(t, lag)
+	assert.Equal(t, 3.0, *lag)
+	assert.Nil(t, parseReplicaStatusLag("        Seconds_Behind_Master: NULL\n"))
+	assert.Nil(t, parseReplicaStatusLag(""))
+}
+
+func TestRenderDatabaseTablesTable(t *testing.T) {
+	info := databaseShardInfo{
+		ShardIndex: 1,
+		Tables: []databaseTableInfo{
+			{Name: "Players", EstimatedRows: 1200},
+			{Name: "Guilds", EstimatedRows: 30},
+			{Name: "MetaInfo", EstimatedRows: 1},
+		},
+	}
+
+	rendered := strings.Join(renderDatabaseTablesTable(info, 2), "\n")
+	assert.Contains(t, rendered, "Players")
+	assert.Contains(t, rendered, "Guilds")
+	assert.NotContains(t, rendered, "MetaInfo")
+	assert.Contains(t, rendered, "1 smaller tables not shown")
+
+	rendered = strings.Join(renderDatabaseTablesTable(info, -1), "\n")
+	assert.Contains(t, rendered, "MetaInfo")
+	assert.NotContains(t, rendered, "not shown")
+}
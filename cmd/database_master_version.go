@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
 
@@ -23,12 +22,44 @@ import (
 // the provided debug pod. Returns nil when it can't be determined (e.g. the MetaInfo table doesn't
 // exist on a fresh database). Best-effort: it must never fail the calling command.
 func queryDatabaseMasterVersion(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName, host, user, password, dbName string) *int {
-	// Pipe the query via stdin (avoids shell quoting). -N skips column names, -B uses batch
-	// (tab-separated) output, so a successful query prints just the integer value.
-	mariadbCmd := fmt.Sprintf("mariadb -h %s -u %s -p%s -N -B %s", host, user, password, dbName)
-
 	const query = "SELECT MasterVersion FROM MetaInfo ORDER BY Version DESC LIMIT 1;"
 
+	// Errors are expected, eg, when the MetaInfo table doesn't exist, so only log them for debugging.
+	output, err := execDatabaseQuery(ctx, kubeCli, podName, debugContainerName, host, user, password, dbName, query)
+	if err != nil {
+		log.Debug().Err(err).Msg("Could not query database master version (continuing without it)")
+		return nil
+	}
+
+	output = strings.TrimSpace(output)
+	if output == "" || strings.EqualFold(output, "NULL") {
+		log.Debug().Msg("Database master version not available (empty MetaInfo result)")
+		return nil
+	}
+
+	masterVersion, err := strconv.Atoi(output)
+	if err != nil {
+		log.Debug().Str("output", output).Msg("Could not parse database master version (continuing without it)")
+		return nil
+	}
+
+	return &masterVersion
+}
+
+// execDatabaseQuery runs the SQL query against a database using a mariadb client running in the
+// provided debug pod and returns its output. The output is in batch format without column names:
+// one row per line with the columns separated by tabs.
+func execDatabaseQuery(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName, host, user, password, dbName, query string) (string, error) {
+	// -N skips column names, -B uses batch (tab-separated) output.
+	return execDatabaseClient(ctx, kubeCli, podName, debugContainerName, host, user, password, dbName, "-N -B", query)
+}
+
+// execDatabaseClient runs a mariadb client with the given output options in the provided debug
+// pod, feeding it the SQL statements via stdin, and returns its output.
+func execDatabaseClient(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName, host, user, password, dbName, clientArgs, statements string) (string, error) {
+	// Pipe the statements via stdin (avoids shell quoting).
+	mariadbCmd := fmt.Sprintf("mariadb -h %s -u %s -p%s %s %s", host, user, password, clientArgs, dbName)
+
 	req := kubeCli.Clientset.CoreV1().
 		RESTClient().
 		Post().
@@ -45,30 +76,20 @@ func queryDatabaseMasterVersion(ctx context.Context, kubeCli *envapi.KubeClient,
 			TTY:       false,
 		}, scheme.ParameterCodec)
 
-	// Capture stdout; discard stderr so a missing MetaInfo table doesn't print a scary error.
 	var outputBuffer bytes.Buffer
+	var errorBuffer bytes.Buffer
 	ioStreams := IOStreams{
-		In:     strings.NewReader(query),
+		In:     strings.NewReader(statements),
 		Out:    &outputBuffer,
-		ErrOut: io.Discard,
+		ErrOut: &errorBuffer,
 	}
 
 	if err := execRemoteKubernetesCommand(ctx, kubeCli.RestConfig, req.URL(), ioStreams, false, false); err != nil {
-		log.Debug().Err(err).Msg("Could not query database master version (continuing without it)")
-		return nil
-	}
-
-	output := strings.TrimSpace(outputBuffer.String())
-	if output == "" || strings.EqualFold(output, "NULL") {
-		log.Debug().Msg("Database master version not available (empty MetaInfo result)")
-		return nil
+		if stderr := strings.TrimSpace(errorBuffer.String()); stderr != "" {
+			return "", fmt.Errorf("%w: %s", err, stderr)
+		}
+		return "", err
 	}
 
-	masterVersion, err := strconv.Atoi(output)
-	if err != nil {
-		log.Debug().Str("output", output).Msg("Could not parse database master version (continuing without it)")
-		return nil
-	}
-
-	return &masterVersion
+	return outputBuffer.String(), nil
 }
@@ -0,0 +1,449 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Statements allowed in read-only mode (the first keyword of the query).
+var databaseReadOnlyStatements = []string{"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN", "WITH"}
+
+// databaseQueryResult is the result set of a query: the column names and the rows of values.
+// NULL values are represented as nil.
+type databaseQueryResult struct {
+	Columns []string
+	Rows    [][]*string
+}
+
+type databaseQueryOpts struct {
+	UsePositionalArgs
+
+	argEnvironment        string
+	argQuery              string
+	flagShard             int
+	flagAllowWrites       bool
+	flagConfirmProduction bool
+	flagFormat            string
+}
+
+func init() {
+	o := databaseQueryOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argQuery, "QUERY", "SQL query to run, eg, 'SELECT COUNT(*) FROM Players'.")
+
+	cmd := &cobra.Command{
+		Use:   "query ENVIRONMENT QUERY [flags]",
+		Short: "Run an SQL query against a database shard in an environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Run a single SQL query against a database shard of the target environment and output
+			the result as a table, CSV, or JSON.
+
+			This command starts a temporary debug pod and runs the query with a mariadb client
+			inside it. No database client or VPN is needed on the local machine.
+
+			By default, the query is run against the read-only replica of the shard in a read-only
+			transaction, and only read statements (SELECT, SHOW, DESCRIBE, EXPLAIN, WITH) are
+			allowed. Use --allow-writes to run the query against the read-write replica without
+			these restrictions. Writes are refused in environments protected by your organization's
+			policy, and writing into a production environment requires confirmation, or
+			--confirm-production in non-interactive sessions.
+
+			In JSON output, the rows are output as objects keyed by the column names and NULL
+			values are output as null. In the table and CSV outputs, NULL values are output as
+			'NULL'.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay debug database' connects to a database shard interactively.
+			- 'metaplay database info' shows the table sizes of each database shard.
+		`),
+		Example: renderExample(`
+			# Count the players in the first shard of environment 'nimbly'.
+			metaplay database query nimbly "SELECT COUNT(*) FROM Players"
+
+			# Query the second shard (0-based indexing is used).
+			metaplay database query nimbly --shard=1 "SELECT EntityId, PersistedAt FROM Players LIMIT 10"
+
+			# Output the result as CSV into a file.
+			metaplay database query nimbly "SELECT EntityId FROM Players" --format=csv > players.csv
+
+			# Run a modifying statement against the read-write replica.
+			metaplay database query nimbly --allow-writes "DELETE FROM AuditLogEvents WHERE ..."
+		`),
+	}
+	databaseCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.IntVar(&o.flagShard, "shard", 0, "Index of the database shard to query")
+	flags.BoolVar(&o.flagAllowWrites, "allow-writes", false, "Allow modifying statements and run them against the read-write replica")
+	flags.BoolVar(&o.flagConfirmProduction, "confirm-production", false, "Confirm writes into production environments in non-interactive mode")
+	flags.StringVar(&o.flagFormat, "format", "table", "Output format: 'table', 'csv', or 'json'")
+}
+
+func (o *databaseQueryOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if !slices.Contains([]string{"table", "csv", "json"}, o.flagFormat) {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'table', 'csv', or 'json'")
+	}
+	if o.flagShard < 0 {
+		return clierrors.NewUsageErrorf("Invalid shard index %d", o.flagShard).
+			WithSuggestion("Shard index must be a non-negative integer, eg, 0 or 1")
+	}
+
+	query, err := normalizeDatabaseQuery(o.argQuery)
+	if err != nil {
+		return err
+	}
+	o.argQuery = query
+
+	if !o.flagAllowWrites && !isReadOnlyDatabaseQuery(query) {
+		return clierrors.NewUsageError("Only read statements are allowed by default").
+			WithDetails(fmt.Sprintf("Allowed statements: %s", strings.Join(databaseReadOnlyStatements, ", "))).
+			WithSuggestion("Use --allow-writes to run modifying statements against the read-write replica")
+	}
+
+	return nil
+}
+
+func (o *databaseQueryOpts) Run(cmd *cobra.Command) error {
+	// Resolve the project & auth provider
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment config
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Writes can modify or destroy any data, so they're guarded like resetting the database.
+	if o.flagAllowWrites {
+		// Check that the environment belongs to this project before modifying it.
+		if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+			return err
+		}
+
+		// Check that the operation is allowed by the organization's policy.
+		if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDestroy); err != nil {
+			return err
+		}
+
+		// Require confirmation for production.
+		if envConfig.Type == portalapi.EnvironmentTypeProduction && !o.flagConfirmProduction {
+			if !tui.CanAskQuestions() {
+				return clierrors.Newf("Production environment detected: %s", envConfig.Name).
+					WithSuggestion("Use --confirm-production flag to confirm writes into production environments")
+			}
+			confirmed, err := tui.DoConfirmQuestion(cmd.Context(), fmt.Sprintf("Run the query with writes allowed in production environment '%s'?", envConfig.Name))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				log.Info().Msg(styles.RenderMuted("Database query canceled."))
+				return nil
+			}
+		}
+	}
+
+	// Create Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the database shard configuration from Kubernetes secret
+	log.Debug().Str("namespace", kubeCli.Namespace).Msg("Fetching database shard configuration")
+	shards, err := kubeutil.FetchDatabaseShardsFromSecret(cmd.Context(), kubeCli, kubeCli.Namespace)
+	if err != nil {
+		return err
+	}
+	if o.flagShard >= len(shards) {
+		return clierrors.NewUsageErrorf("Shard index %d is out of range", o.flagShard).
+			WithSuggestion(fmt.Sprintf("The environment has %d database shard(s), use an index between 0 and %d", len(shards), len(shards)-1))
+	}
+	shard := shards[o.flagShard]
+
+	// Read-only queries run against the read-only replica in a read-only transaction, as a
+	// safety net for the statement check.
+	host := shard.ReadOnlyHost
+	statements := o.argQuery + ";"
+	if o.flagAllowWrites {
+		host = shard.ReadWriteHost
+	} else {
+		statements = "SET SESSION TRANSACTION READ ONLY;\n" + statements
+	}
+
+	if o.flagFormat == "table" {
+		replica := "read-only"
+		if o.flagAllowWrites {
+			replica = "read-write"
+		}
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Database Query"))
+		log.Info().Msg("")
+		log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.HumanID))
+		log.Info().Msgf("Shard:       %s", styles.RenderTechnical(fmt.Sprintf("#%d (%s)", shard.ShardIndex, replica)))
+		log.Info().Msg("")
+	}
+
+	// Create a debug pod to run the mariadb client in.
+	log.Debug().Msg("Creating debug pod for database query")
+	podName, cleanup, err := kubeutil.CreateDebugPod(
+		cmd.Context(),
+		kubeCli,
+		debugDatabaseImage,
+		false,
+		false,
+		[]string{"sleep", "3600"},
+	)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Run the query in batch mode (tab-separated output with column names).
+	output, err := execDatabaseClient(cmd.Context(), kubeCli, podName, "debug", host, shard.UserId, shard.Password, shard.DatabaseName, "-B", statements)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to run query on database shard #%d", shard.ShardIndex)
+	}
+
+	result, err := parseDatabaseQueryOutput(output)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to parse query output")
+	}
+
+	// Output in desired format.
+	switch o.flagFormat {
+	case "json":
+		resultJSON, err := json.MarshalIndent(result.toObjects(), "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal query result as JSON")
+		}
+		log.Info().Msg(string(resultJSON))
+	case "csv":
+		resultCSV, err := result.toCSV()
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to format query result as CSV")
+		}
+		log.Info().Msg(strings.TrimSuffix(resultCSV, "\n"))
+	default:
+		if len(result.Columns) == 0 {
+			log.Info().Msg(styles.RenderSuccess("✓ Query executed successfully"))
+		} else {
+			for _, line := range renderDatabaseQueryTable(result) {
+				log.Info().Msg(line)
+			}
+			log.Info().Msg("")
+			log.Info().Msg(styles.RenderMuted(fmt.Sprintf("%d row(s)", len(result.Rows))))
+		}
+		log.Info().Msg("")
+	}
+
+	return nil
+}
+
+// normalizeDatabaseQuery trims the whitespace and trailing semicolons from the query and checks
+// that it's a single non-empty statement.
+func normalizeDatabaseQuery(query string) (string, error) {
+	query = strings.TrimRightFunc(strings.TrimSpace(query), func(r rune) bool { return r == ';' || unicode.IsSpace(r) })
+	if query == "" {
+		return "", clierrors.NewUsageError("Query must not be empty")
+	}
+
+	// Check for statement separators outside of quoted strings and identifiers.
+	var quote rune
+	escaped := false
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ';':
+			return "", clierrors.NewUsageError("Only a single SQL statement can be run at a time").
+				WithSuggestion("Run each statement with a separate command, or use 'metaplay debug database' for interactive use")
+		}
+	}
+
+	return query, nil
+}
+
+// isReadOnlyDatabaseQuery returns true if the query starts with one of the read statements.
+func isReadOnlyDatabaseQuery(query string) bool {
+	query = strings.TrimLeft(query, "( \t\r\n")
+	keyword, _, _ := strings.Cut(query, " ")
+	keyword = strings.TrimRightFunc(keyword, func(r rune) bool { return !unicode.IsLetter(r) })
+	return slices.Contains(databaseReadOnlyStatements, strings.ToUpper(keyword))
+}
+
+// parseDatabaseQueryOutput parses the output of the mariadb client in batch mode with column
+// names: the first line contains the column names, the following lines the rows. Statements
+// without a result set produce no output.
+func parseDatabaseQueryOutput(output string) (*databaseQueryResult, error) {
+	result := &databaseQueryResult{Columns: []string{}, Rows: [][]*string{}}
+	output = strings.TrimSuffix(output, "\n")
+	if output == "" {
+		return result, nil
+	}
+
+	lines := strings.Split(output, "\n")
+	for _, column := range strings.Split(lines[0], "\t") {
+		result.Columns = append(result.Columns, unescapeDatabaseBatchValue(column))
+	}
+
+	for _, line := range lines[1:] {
+		values := strings.Split(line, "\t")
+		if len(values) != len(result.Columns) {
+			return nil, fmt.Errorf("expected %d columns in row, got %d: '%s'", len(result.Columns), len(values), line)
+		}
+
+		row := make([]*string, len(values))
+		for ndx, value := range values {
+			if value != "NULL" {
+				unescaped := unescapeDatabaseBatchValue(value)
+				row[ndx] = &unescaped
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// unescapeDatabaseBatchValue reverts the escaping of special characters in the mariadb batch
+// output: '\t', '\n', '\0', and '\\'.
+func unescapeDatabaseBatchValue(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+
+	var sb strings.Builder
+	escaped := false
+	for _, r := range value {
+		if !escaped {
+			if r == '\\' {
+				escaped = true
+			} else {
+				sb.WriteRune(r)
+			}
+			continue
+		}
+
+		escaped = false
+		switch r {
+		case 't':
+			sb.WriteRune('\t')
+		case 'n':
+			sb.WriteRune('\n')
+		case '0':
+			sb.WriteRune(0)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// toObjects converts the rows into objects keyed by the column names.
+func (result *databaseQueryResult) toObjects() []map[string]*string {
+	objects := make([]map[string]*string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		object := make(map[string]*string, len(result.Columns))
+		for ndx, column := range result.Columns {
+			object[column] = row[ndx]
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+// toCSV formats the result as CSV with a header row.
+func (result *databaseQueryResult) toCSV() (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+	if err := writer.Write(result.Columns); err != nil {
+		return "", err
+	}
+	for _, row := range result.Rows {
+		if err := writer.Write(formatDatabaseQueryRow(row)); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	return sb.String(), writer.Error()
+}
+
+// formatDatabaseQueryRow converts the row values to strings, with NULL values as 'NULL'.
+func formatDatabaseQueryRow(row []*string) []string {
+	values := make([]string, len(row))
+	for ndx, value := range row {
+		if value == nil {
+			values[ndx] = "NULL"
+		} else {
+			values[ndx] = *value
+		}
+	}
+	return values
+}
+
+// renderDatabaseQueryTable renders the result as a table. Line breaks and tabs within values
+// are shown escaped to keep the table aligned.
+func renderDatabaseQueryTable(result *databaseQueryResult) []string {
+	escaper := strings.NewReplacer("\n", "\\n", "\t", "\\t", "\r", "\\r")
+	rows := make([][]string, len(result.Rows))
+	widths := make([]int, len(result.Columns))
+	for ndx, column := range result.Columns {
+		widths[ndx] = len(column)
+	}
+	for rowNdx, row := range result.Rows {
+		rows[rowNdx] = formatDatabaseQueryRow(row)
+		for ndx, value := range rows[rowNdx] {
+			rows[rowNdx][ndx] = escaper.Replace(value)
+			widths[ndx] = max(widths[ndx], len(rows[rowNdx][ndx]))
+		}
+	}
+
+	// Pad plain text before applying ANSI styles.
+	formatRow := func(values []string, style func(string) string) string {
+		cells := make([]string, len(values))
+		for ndx, value := range values {
+			cells[ndx] = style(fmt.Sprintf("%-*s", widths[ndx], value))
+		}
+		return strings.TrimRight("  "+strings.Join(cells, "  "), " ")
+	}
+
+	lines := []string{formatRow(result.Columns, styles.RenderTechnical), ""}
+	for _, row := range rows {
+		lines = append(lines, formatRow(row, func(s string) string { return s }))
+	}
+	return lines
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDatabaseQuery(t *testing.T) {
+	query, err := normalizeDatabaseQuery("  SELECT COUNT(*) FROM Players; \n")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM Players", query)
+
+	// Semicolons within quoted strings are allowed.
+	query, err = normalizeDatabaseQuery(`SELECT * FROM Players WHERE EntityId = 'a;b' OR EntityId = "c\";d"`)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM Players WHERE EntityId = 'a;b' OR EntityId = "c\";d"`, query)
+
+	_, err = normalizeDatabaseQuery(" ; ")
+	assert.Error(t, err)
+	_, err = normalizeDatabaseQuery("SELECT 1; DROP TABLE Players")
+	assert.Error(t, err)
+}
+
+func TestIsReadOnlyDatabaseQuery(t *testing.T) {
+	assert.True(t, isReadOnlyDatabaseQuery("SELECT 1"))
+	assert.True(t, isReadOnlyDatabaseQuery("select\n1"))
+	assert.True(t, isReadOnlyDatabaseQuery("(SELECT 1) UNION (SELECT 2)"))
+	assert.True(t, isReadOnlyDatabaseQuery("SHOW TABLES"))
+	assert.True(t, isReadOnlyDatabaseQuery("DESC Players"))
+	assert.True(t, isReadOnlyDatabaseQuery("WITH x AS (SELECT 1) SELECT * FROM x"))
+	assert.False(t, isReadOnlyDatabaseQuery("DELETE FROM Players"))
+	assert.False(t, isReadOnlyDatabaseQuery("UPDATE Players SET Payload = NULL"))
+	assert.False(t, isReadOnlyDatabaseQuery("SELECTX"))
+}
+
+func TestParseDatabaseQueryOutput(t *testing.T) {
+	result, err := parseDatabaseQueryOutput("EntityId\tPayload\nPlayer:0000000001\tNULL\nPlayer:0000000002\ta\\tb\\nc\\\\d\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EntityId", "Payload"}, result.Columns)
+	require.Len(t, result.Rows, 2)
+	assert.Equal(t, []string{"Player:0000000001", "NULL"}, formatDatabaseQueryRow(result.Rows[0]))
+	assert.Nil(t, result.Rows[0][1])
+	assert.Equal(t, "a\tb\nc\\d", *result.Rows[1][1])
+
+	// Statements without a result set.
+	result, err = parseDatabaseQueryOutput("")
+	require.NoError(t, err)
+	assert.Empty(t, result.Columns)
+	assert.Empty(t, result.Rows)
+
+	// Mismatching number of columns.
+	_, err = parseDatabaseQueryOutput("A\tB\n1\n")
+	assert.Error(t, err)
+}
+
+func TestDatabaseQueryResultFormats(t *testing.T) {
+	value := "x,y"
+	result := &databaseQueryResult{
+		Columns: []string{"Name", "Value"},
+		Rows:    [][]*string{{&value, nil}},
+	}
+
+	csv, err := result.toCSV()
+	require.NoError(t, err)
+	assert.Equal(t, "Name,Value\n\"x,y\",NULL\n", csv)
+
+	objects := result.toObjects()
+	require.Len(t, objects, 1)
+	assert.Equal(t, "x,y", *objects[0]["Name"])
+	assert.Nil(t, objects[0]["Value"])
+}
@@ -0,0 +1,423 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// MasterVersion written into the MetaInfo table by 'metaplay database reset' while the reset is
+// in progress.
+const databaseResetInProgressMasterVersion = -4004
+
+// Query for the names of all tables in the current database.
+const databaseTableNamesQuery = "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME;"
+
+// Query for the latest MetaInfo record, which holds the number of shards the database is
+// partitioned into.
+const databaseMetaInfoQuery = "SELECT MasterVersion, NumShards FROM MetaInfo ORDER BY Version DESC LIMIT 1;"
+
+// Severities of the shard topology findings.
+const (
+	topologySeverityError   = "error"
+	topologySeverityWarning = "warning"
+	topologySeverityOK      = "ok"
+)
+
+// shardTopologyProbe is the state of a single database shard, as seen from the debug pod.
+type shardTopologyProbe struct {
+	ShardIndex   int    `json:"shardIndex"`
+	DatabaseName string `json:"databaseName"`
+	Reachable    bool   `json:"reachable"`
+	NumTables    int    `json:"numTables"`
+	HasMetaInfo  bool   `json:"hasMetaInfo"`
+	Error        string `json:"error,omitempty"`
+}
+
+// shardTopologyFinding is a single result of checking the shard topology.
+type shardTopologyFinding struct {
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// databaseTopologyReport is the result of 'metaplay database rebalance-check'.
+type databaseTopologyReport struct {
+	Environment          string                 `json:"environment"`
+	ConfiguredShards     int                    `json:"configuredShards"`     // Shards in the infra runtime options
+	NumActiveShards      int                    `json:"numActiveShards"`      // NumActiveShards in the infra runtime options (0 if not set)
+	ExpectedActiveShards int                    `json:"expectedActiveShards"` // Active shards the server will use
+	RecordedShards       *int                   `json:"recordedShards"`       // NumShards in the latest MetaInfo record, if any
+	MasterVersion        *int                   `json:"masterVersion"`        // MasterVersion in the latest MetaInfo record, if any
+	MetaInfoError        string                 `json:"metaInfoError,omitempty"`
+	Shards               []shardTopologyProbe   `json:"shards"`
+	Findings             []shardTopologyFinding `json:"findings"`
+}
+
+// Check the database shard topology of an environment.
+type databaseRebalanceCheckOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagFormat     string
+}
+
+func init() {
+	o := databaseRebalanceCheckOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "rebalance-check ENVIRONMENT [flags]",
+		Short: "Check that the database shards match the shard count the server expects",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Check that the database shards configured for the target environment match the shard
+			topology that the database was partitioned with. Run this after the number of database
+			shards of an environment has been changed in the infrastructure, before deploying the
+			game server: a mismatch otherwise only shows up as errors when the game server boots.
+
+			The following are checked:
+			- The number of active shards (NumActiveShards) does not exceed the configured shards.
+			- Each configured shard is reachable with the credentials from the runtime options.
+			- The number of shards recorded in the MetaInfo table of the first shard matches the
+			  number of active shards that the game server will use.
+			- No database reset has been left in progress.
+			- Inactive shards do not contain tables, which the game server would not use.
+
+			When a mismatch is found, the command explains the supported ways forward: restoring
+			the previous shard count, letting a game server that supports resharding migrate the
+			data at startup, or resetting the database.
+
+			This command starts a temporary debug pod and runs read-only queries with a mariadb
+			client inside it, connecting to the read-only replica of each shard. The command exits
+			with an error if any problems are found.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay database info ENVIRONMENT' shows the table sizes of each shard.
+			- 'metaplay database export-archive ENVIRONMENT' takes a backup of the database.
+			- 'metaplay database reset ENVIRONMENT' deletes all data from the database.
+			- 'metaplay test database-resharding' tests resharding with your game server locally.
+		`),
+		Example: renderExample(`
+			# Check the shard topology of environment 'nimbly'.
+			metaplay database rebalance-check nimbly
+
+			# Output the results as JSON, eg, for checking in CI before deploying.
+			metaplay database rebalance-check nimbly --format=json
+		`),
+	}
+	databaseCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *databaseRebalanceCheckOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *databaseRebalanceCheckOpts) Run(cmd *cobra.Command) error {
+	// Resolve the project & auth provider
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment config
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Fetch the database configuration from Kubernetes secret
+	log.Debug().Str("namespace", kubeCli.Namespace).Msg("Fetching database configuration")
+	dbConfig, err := kubeutil.FetchDatabaseConfigFromSecret(cmd.Context(), kubeCli, kubeCli.Namespace)
+	if err != nil {
+		return err
+	}
+
+	report := databaseTopologyReport{
+		Environment:          envConfig.HumanID,
+		ConfiguredShards:     len(dbConfig.Shards),
+		NumActiveShards:      dbConfig.NumActiveShards,
+		ExpectedActiveShards: getExpectedActiveShards(dbConfig.NumActiveShards, len(dbConfig.Shards)),
+		Shards:               []shardTopologyProbe{},
+	}
+
+	if o.flagFormat == "text" {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Database Rebalance Check"))
+		log.Info().Msg("")
+		log.Info().Msgf("Environment:       %s", styles.RenderTechnical(envConfig.HumanID))
+		log.Info().Msgf("Configured shards: %s", styles.RenderTechnical(strconv.Itoa(report.ConfiguredShards)))
+		log.Info().Msgf("Active shards:     %s", styles.RenderTechnical(strconv.Itoa(report.ExpectedActiveShards)))
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderMuted("Inspecting the database shards..."))
+	}
+
+	// Create a debug pod to run the mariadb client in.
+	log.Debug().Msg("Creating debug pod for database inspection")
+	podName, cleanup, err := kubeutil.CreateDebugPod(
+		cmd.Context(),
+		kubeCli,
+		debugDatabaseImage,
+		false,
+		false,
+		[]string{"sleep", "3600"},
+	)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Probe each shard. Failures are recorded per shard so that all shards are checked.
+	for _, shard := range dbConfig.Shards {
+		report.Shards = append(report.Shards, probeDatabaseShardTopology(cmd.Context(), kubeCli, podName, "debug", shard))
+	}
+
+	// The first shard holds the authoritative MetaInfo record.
+	if firstShard := report.Shards[0]; firstShard.Reachable && firstShard.HasMetaInfo {
+		shard := dbConfig.Shards[0]
+		output, err := execDatabaseQuery(cmd.Context(), kubeCli, podName, "debug", shard.ReadOnlyHost, shard.UserId, shard.Password, shard.DatabaseName, databaseMetaInfoQuery)
+		if err == nil {
+			report.MasterVersion, report.RecordedShards, err = parseDatabaseMetaInfo(output)
+		}
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to read the MetaInfo record")
+			report.MetaInfoError = err.Error()
+		}
+	}
+
+	report.Findings = analyzeShardTopology(report)
+	numErrors := 0
+	for _, finding := range report.Findings {
+		if finding.Severity == topologySeverityError {
+			numErrors++
+		}
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal rebalance check results as JSON")
+		}
+		log.Info().Msg(string(reportJSON))
+	} else {
+		log.Info().Msg("")
+		for _, line := range renderShardTopologyTable(report) {
+			log.Info().Msg(line)
+		}
+		log.Info().Msg("")
+		for _, finding := range report.Findings {
+			log.Info().Msg(renderShardTopologyFinding(finding))
+			if finding.Suggestion != "" {
+				for line := range strings.SplitSeq(finding.Suggestion, "\n") {
+					log.Info().Msgf("    %s", styles.RenderMuted(line))
+				}
+			}
+		}
+		log.Info().Msg("")
+	}
+
+	if numErrors > 0 {
+		return clierrors.Newf("Found %d database shard topology problem(s) in environment %s", numErrors, envConfig.HumanID).
+			WithSuggestion("Resolve the problems before deploying the game server")
+	}
+	return nil
+}
+
+// getExpectedActiveShards returns the number of active shards the game server uses: all the
+// configured shards, unless NumActiveShards is set.
+func getExpectedActiveShards(numActiveShards, numConfiguredShards int) int {
+	if numActiveShards > 0 {
+		return numActiveShards
+	}
+	return numConfiguredShards
+}
+
+// probeDatabaseShardTopology checks that the shard is reachable and lists its tables.
+func probeDatabaseShardTopology(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, shard kubeutil.DatabaseShardConfig) shardTopologyProbe {
+	probe := shardTopologyProbe{
+		ShardIndex:   shard.ShardIndex,
+		DatabaseName: shard.DatabaseName,
+	}
+
+	output, err := execDatabaseQuery(ctx, kubeCli, podName, debugContainerName, shard.ReadOnlyHost, shard.UserId, shard.Password, shard.DatabaseName, databaseTableNamesQuery)
+	if err != nil {
+		log.Debug().Err(err).Int("shard_index", shard.ShardIndex).Msg("Failed to query tables")
+		probe.Error = err.Error()
+		return probe
+	}
+
+	probe.Reachable = true
+	for table := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		if table == "" {
+			continue
+		}
+		probe.NumTables++
+		if strings.EqualFold(table, "MetaInfo") {
+			probe.HasMetaInfo = true
+		}
+	}
+	return probe
+}
+
+// parseDatabaseMetaInfo parses the output of databaseMetaInfoQuery into the master version and
+// the number of shards. Both are nil if the MetaInfo table is empty.
+func parseDatabaseMetaInfo(output string) (*int, *int, error) {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, nil, nil
+	}
+
+	columns := strings.Split(output, "\t")
+	if len(columns) != 2 {
+		return nil, nil, fmt.Errorf("unexpected MetaInfo query output: %q", output)
+	}
+	masterVersion, err := strconv.Atoi(columns[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid MasterVersion %q in MetaInfo", columns[0])
+	}
+	numShards, err := strconv.Atoi(columns[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid NumShards %q in MetaInfo", columns[1])
+	}
+	return &masterVersion, &numShards, nil
+}
+
+// analyzeShardTopology compares the configured shards against the state of the database and
+// returns the findings, errors first.
+func analyzeShardTopology(report databaseTopologyReport) []shardTopologyFinding {
+	env := report.Environment
+	expected := report.ExpectedActiveShards
+	findings := []shardTopologyFinding{}
+	addFinding := func(severity, message, suggestion string) {
+		findings = append(findings, shardTopologyFinding{Severity: severity, Message: message, Suggestion: suggestion})
+	}
+
+	// The server cannot use more shards than are configured.
+	if report.NumActiveShards > report.ConfiguredShards {
+		addFinding(topologySeverityError,
+			fmt.Sprintf("The game server expects %d active shards, but only %d shards are configured", report.NumActiveShards, report.ConfiguredShards),
+			"Fix the database configuration of the environment in the infrastructure: NumActiveShards must not exceed the number of shards")
+	}
+
+	// All configured shards must be reachable.
+	for _, shard := range report.Shards {
+		if !shard.Reachable {
+			addFinding(topologySeverityError,
+				fmt.Sprintf("Shard %d (%s) is not reachable: %s", shard.ShardIndex, shard.DatabaseName, shard.Error),
+				fmt.Sprintf("Check that the database exists and that its credentials are up to date, eg, with 'metaplay debug database %s %d'", env, shard.ShardIndex))
+		}
+	}
+
+	// Inactive shards should not contain data, as the server ignores them.
+	for _, shard := range report.Shards {
+		if shard.Reachable && shard.ShardIndex >= expected && shard.NumTables > 0 {
+			addFinding(topologySeverityWarning,
+				fmt.Sprintf("Shard %d is not active but contains %d tables, which the game server does not use", shard.ShardIndex, shard.NumTables),
+				"The tables are left over from a larger shard count. Check that their data has been migrated to the active shards before removing them")
+		}
+	}
+
+	// Compare the topology recorded in the database against the expected one.
+	firstShard := report.Shards[0]
+	switch {
+	case !firstShard.Reachable:
+		// Already reported above, the recorded topology is unknown.
+	case report.MetaInfoError != "":
+		addFinding(topologySeverityError,
+			fmt.Sprintf("Failed to read the MetaInfo table of shard 0: %s", report.MetaInfoError),
+			fmt.Sprintf("Inspect the table with 'metaplay debug database %s 0 --query \"SELECT * FROM MetaInfo\"'", env))
+	case report.MasterVersion == nil:
+		addFinding(topologySeverityOK,
+			fmt.Sprintf("The database has not been initialized, the game server will partition it into %d shards when it first starts", expected),
+			"")
+	case *report.MasterVersion == databaseResetInProgressMasterVersion:
+		addFinding(topologySeverityError,
+			"A database reset has been started but not completed, the database may be partially deleted",
+			fmt.Sprintf("Complete the reset with 'metaplay database reset %s'", env))
+	case *report.RecordedShards != expected:
+		addFinding(topologySeverityError,
+			fmt.Sprintf("The database is partitioned into %d shards, but the game server is configured to use %d active shards", *report.RecordedShards, expected),
+			strings.Join([]string{
+				fmt.Sprintf("1. Take a backup first: 'metaplay database export-archive %s'.", env),
+				fmt.Sprintf("2. Then either restore the previous shard count of %d in the infrastructure,", *report.RecordedShards),
+				"   or deploy a game server version that supports resharding to migrate the data at",
+				"   startup (validate it locally with 'metaplay test database-resharding' first),",
+				fmt.Sprintf("   or discard all data with 'metaplay database reset %s' (use --dry-run first).", env),
+			}, "\n"))
+	default:
+		addFinding(topologySeverityOK,
+			fmt.Sprintf("The database is partitioned into %d shards, matching the game server configuration", expected),
+			"")
+	}
+
+	// Errors first, then warnings, then the rest in their original order.
+	severityOrder := []string{topologySeverityError, topologySeverityWarning, topologySeverityOK}
+	slices.SortStableFunc(findings, func(a, b shardTopologyFinding) int {
+		return slices.Index(severityOrder, a.Severity) - slices.Index(severityOrder, b.Severity)
+	})
+	return findings
+}
+
+// renderShardTopologyTable renders the state of each shard as a table.
+func renderShardTopologyTable(report databaseTopologyReport) []string {
+	lines := []string{styles.RenderMuted(fmt.Sprintf("%-6s %-24s %-8s %-11s %s", "SHARD", "DATABASE", "ACTIVE", "REACHABLE", "TABLES"))}
+	for _, shard := range report.Shards {
+		active := "yes"
+		if shard.ShardIndex >= report.ExpectedActiveShards {
+			active = "no"
+		}
+		reachable := styles.RenderSuccess(fmt.Sprintf("%-11s", "yes"))
+		tables := strconv.Itoa(shard.NumTables)
+		if !shard.Reachable {
+			reachable = styles.RenderError(fmt.Sprintf("%-11s", "no"))
+			tables = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%-6d %-24s %-8s %s %s", shard.ShardIndex, shard.DatabaseName, active, reachable, tables))
+	}
+	return lines
+}
+
+// renderShardTopologyFinding renders the finding with a marker matching its severity.
+func renderShardTopologyFinding(finding shardTopologyFinding) string {
+	switch finding.Severity {
+	case topologySeverityError:
+		return styles.RenderError("✗ " + finding.Message)
+	case topologySeverityWarning:
+		return styles.RenderWarning("! " + finding.Message)
+	default:
+		return styles.RenderSuccess("✓ " + finding.Message)
+	}
+}
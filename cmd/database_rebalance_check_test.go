@@ -0,0 +1,111 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDatabaseMetaInfo(t *testing.T) {
+	masterVersion, numShards, err := parseDatabaseMetaInfo("12\t4\n")
+	require.NoError(t, err)
+	require.NotNil(t, masterVersion)
+	require.NotNil(t, numShards)
+	assert.Equal(t, 12, *masterVersion)
+	assert.Equal(t, 4, *numShards)
+
+	// Empty MetaInfo table.
+	masterVersion, numShards, err = parseDatabaseMetaInfo("")
+	require.NoError(t, err)
+	assert.Nil(t, masterVersion)
+	assert.Nil(t, numShards)
+
+	// Malformed output.
+	_, _, err = parseDatabaseMetaInfo("12")
+	assert.Error(t, err)
+	_, _, err = parseDatabaseMetaInfo("12\tNULL")
+	assert.Error(t, err)
+}
+
+func TestGetExpectedActiveShards(t *testing.T) {
+	assert.Equal(t, 4, getExpectedActiveShards(0, 4))
+	assert.Equal(t, 2, getExpectedActiveShards(2, 4))
+}
+
+func TestAnalyzeShardTopology(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	newReport := func(numShards int, recordedShards *int) databaseTopologyReport {
+		report := databaseTopologyReport{
+			Environment:          "nimbly",
+			ConfiguredShards:     numShards,
+			ExpectedActiveShards: numShards,
+			RecordedShards:       recordedShards,
+		}
+		if recordedShards != nil {
+			report.MasterVersion = intPtr(5)
+		}
+		for ndx := range numShards {
+			report.Shards = append(report.Shards, shardTopologyProbe{ShardIndex: ndx, Reachable: true, NumTables: 10, HasMetaInfo: true})
+		}
+		return report
+	}
+	severities := func(findings []shardTopologyFinding) []string {
+		result := []string{}
+		for _, finding := range findings {
+			result = append(result, finding.Severity)
+		}
+		return result
+	}
+
+	// Matching topology.
+	findings := analyzeShardTopology(newReport(4, intPtr(4)))
+	assert.Equal(t, []string{topologySeverityOK}, severities(findings))
+
+	// Uninitialized database.
+	findings = analyzeShardTopology(newReport(4, nil))
+	assert.Equal(t, []string{topologySeverityOK}, severities(findings))
+	assert.Contains(t, findings[0].Message, "not been initialized")
+
+	// Shards added in the infra.
+	findings = analyzeShardTopology(newReport(4, intPtr(2)))
+	assert.Equal(t, []string{topologySeverityError}, severities(findings))
+	assert.Contains(t, findings[0].Message, "partitioned into 2 shards")
+	assert.Contains(t, findings[0].Suggestion, "metaplay database export-archive nimbly")
+
+	// More active shards than configured.
+	report := newReport(2, intPtr(4))
+	report.NumActiveShards = 4
+	report.ExpectedActiveShards = 4
+	findings = analyzeShardTopology(report)
+	assert.Equal(t, []string{topologySeverityError, topologySeverityOK}, severities(findings))
+	assert.Contains(t, findings[0].Message, "only 2 shards are configured")
+
+	// Fewer active shards, with data left on the inactive shards.
+	report = newReport(4, intPtr(2))
+	report.NumActiveShards = 2
+	report.ExpectedActiveShards = 2
+	findings = analyzeShardTopology(report)
+	assert.Equal(t, []string{topologySeverityWarning, topologySeverityWarning, topologySeverityOK}, severities(findings))
+	assert.Contains(t, findings[0].Message, "Shard 2 is not active")
+
+	// Unreachable shard, reported before the other findings.
+	report = newReport(4, intPtr(4))
+	report.Shards[3].Reachable = false
+	report.Shards[3].Error = "access denied"
+	findings = analyzeShardTopology(report)
+	assert.Equal(t, []string{topologySeverityError, topologySeverityOK}, severities(findings))
+	assert.Contains(t, findings[0].Message, "access denied")
+	assert.Contains(t, findings[0].Suggestion, "metaplay debug database nimbly 3")
+
+	// Reset left in progress.
+	report = newReport(4, intPtr(0))
+	report.MasterVersion = intPtr(databaseResetInProgressMasterVersion)
+	findings = analyzeShardTopology(report)
+	assert.Equal(t, []string{topologySeverityError}, severities(findings))
+	assert.Contains(t, findings[0].Suggestion, "metaplay database reset nimbly")
+}
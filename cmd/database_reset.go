@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
@@ -34,6 +35,7 @@ type databaseResetOpts struct {
 	flagYes               bool
 	flagForce             bool
 	flagConfirmProduction bool
+	flagDryRun            bool
 }
 
 func init() {
@@ -60,6 +62,12 @@ func init() {
 			WARNING: This operation is DESTRUCTIVE and will delete ALL data in the database.
 			Use with extreme caution and only on development/staging environments.
 
+			With --dry-run, the database is not modified and no confirmation is required. Instead,
+			the impact of the reset is reported, eg, for including in a change review:
+			- The tables in each shard with their estimated row counts and sizes.
+			- The estimated total amount of data that would be destroyed.
+			- The exact SQL statements that the reset would execute, in order.
+
 			{Arguments}
 		`),
 		Example: renderExample(`
@@ -68,6 +76,9 @@ func init() {
 
 			# Auto-accept reset without confirmation prompt
 			metaplay database reset nimbly --yes
+
+			# Show what the reset would drop without dropping anything
+			metaplay database reset nimbly --dry-run
 		`),
 		Run: runCommand(&o),
 	}
@@ -75,6 +86,7 @@ func init() {
 	cmd.Flags().BoolVar(&o.flagYes, "yes", false, "Skip confirmation prompt and proceed with reset")
 	cmd.Flags().BoolVar(&o.flagForce, "force", false, "Proceed with reset even if a game server is deployed (DANGEROUS!!)")
 	cmd.Flags().BoolVar(&o.flagConfirmProduction, "confirm-production", false, "Required flag when resetting production environments")
+	addDryRunFlag(cmd.Flags(), &o.flagDryRun)
 
 	databaseCmd.AddCommand(cmd)
 }
@@ -87,7 +99,7 @@ func (o *databaseResetOpts) Prepare(cmd *cobra.Command, args []string) error {
 	}
 
 	// In non-interactive mode, --yes flag is required for safety
-	if !tui.IsInteractiveMode() && !o.flagYes {
+	if !tui.IsInteractiveMode() && !o.flagYes && !o.flagDryRun {
 		return clierrors.NewUsageError("Confirmation required for destructive operation").
 			WithSuggestion("Use --yes flag in non-interactive mode to confirm database reset")
 	}
@@ -108,8 +120,18 @@ func (o *databaseResetOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDestroy); err != nil {
+		return err
+	}
+
 	// Check if this is a production environment and require additional confirmation
-	if envConfig.Type == portalapi.EnvironmentTypeProduction && !o.flagConfirmProduction {
+	if envConfig.Type == portalapi.EnvironmentTypeProduction && !o.flagConfirmProduction && !o.flagDryRun {
 		return clierrors.Newf("Production environment detected: %s", envConfig.Name).
 			WithSuggestion("Use --confirm-production flag to confirm reset of production environments")
 	}
@@ -137,19 +159,25 @@ func (o *databaseResetOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Check if there's a game server deployed.
+	proceeding := "Proceeding with database reset"
+	if o.flagDryRun {
+		proceeding = "Would proceed with database reset"
+	}
 	log.Info().Msg("")
-	if len(helmReleases) > 0 {
+	if len(helmReleases) > 0 && o.flagDryRun && !o.flagForce {
+		log.Warn().Msgf("%s The game server is deployed in environment '%s': the reset would be refused without --force", styles.RenderWarning("⚠️"), o.argEnvironment)
+	} else if len(helmReleases) > 0 {
 		if !o.flagForce {
 			return clierrors.New("Cannot reset database while game server is deployed").
 				WithSuggestion(fmt.Sprintf("Remove the game server first with 'metaplay remove server %s'", o.argEnvironment))
 		}
 
 		log.Warn().Msgf("%s %s", styles.RenderWarning("⚠️"), fmt.Sprintf("WARNING: active game server deployment detected in environment '%s'", o.argEnvironment))
-		log.Warn().Msgf("   Proceeding with database reset due to --force flag.")
+		log.Warn().Msgf("   %s due to --force flag.", proceeding)
 		log.Warn().Msgf("   Your game server will stop functioning and you'll need to re-deploy it after the reset.")
 		log.Info().Msg("")
 	} else {
-		log.Info().Msgf("%s %s", styles.RenderSuccess("✓"), "No active game server deployments found, proceeding with database reset")
+		log.Info().Msgf("%s %s", styles.RenderSuccess("✓"), "No active game server deployments found, "+strings.ToLower(proceeding))
 	}
 	log.Info().Msg("")
 
@@ -166,7 +194,7 @@ func (o *databaseResetOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Show warning and get confirmation
-	if !o.flagYes {
+	if !o.flagYes && !o.flagDryRun {
 		// Check if we're in non-interactive mode - fail if we can't prompt
 		if !tui.IsInteractiveMode() {
 			return fmt.Errorf("--yes flag is required in non-interactive mode to confirm the destructive database reset operation")
@@ -225,6 +253,16 @@ func (o *databaseResetOpts) Run(cmd *cobra.Command) error {
 		return nil
 	}
 
+	// If dry-run mode, show the impact and the reset steps and stop here.
+	if o.flagDryRun {
+		printDatabaseResetImpact(cmd.Context(), kubeCli, podName, "debug", shards)
+
+		dryRun := dryRunPlan{}
+		addDatabaseResetSteps(&dryRun, shards, allShardTables)
+		dryRun.Print()
+		return nil
+	}
+
 	err = o.resetDatabaseContents(cmd.Context(), kubeCli, podName, "debug", shards, allShardTables)
 	if err != nil {
 		if cmd.Context().Err() != nil {
@@ -301,16 +339,83 @@ func (o *databaseResetOpts) resetDatabaseContents(ctx context.Context, kubeCli *
 	return nil
 }
 
-// Helper function to mark reset in progress by setting MasterVersion to -4004
-func (o *databaseResetOpts) markResetInProgress(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, mainShard kubeutil.DatabaseShardConfig) error {
-	const resetInProgressVersion = -4004
+// addDatabaseResetSteps describes the exact SQL statements executed by resetDatabaseContents(),
+// in order, in a dry-run plan.
+func addDatabaseResetSteps(dryRun *dryRunPlan, shards []kubeutil.DatabaseShardConfig, allShardTables map[int][]string) {
+	dryRun.Addf("Shard %d (%s): %s", shards[0].ShardIndex, shards[0].DatabaseName, strings.Join(strings.Fields(databaseResetMarkInProgressSQL), " "))
+	for _, shard := range shards {
+		for _, table := range getDatabaseResetPhase1Tables(allShardTables[shard.ShardIndex]) {
+			dryRun.Addf("Shard %d (%s): %s", shard.ShardIndex, shard.DatabaseName, dropDatabaseTableSQL(table))
+		}
+	}
+	for i := len(shards) - 1; i >= 0; i-- {
+		dryRun.Addf("Shard %d (%s): %s", shards[i].ShardIndex, shards[i].DatabaseName, dropDatabaseTableSQL("MetaInfo"))
+	}
+}
 
-	// Build SQL command to insert new MetaInfo record with reset in progress marker
-	sqlCmd := fmt.Sprintf(`INSERT INTO MetaInfo (Version, Timestamp, MasterVersion, NumShards)
-		SELECT Version + 1, NOW(), %d, 0 FROM MetaInfo WHERE Version = (SELECT MAX(Version) FROM MetaInfo);`,
-		resetInProgressVersion)
+// printDatabaseResetImpact shows the tables in each shard with their estimated row counts and
+// sizes, and the estimated total amount of data that a reset would destroy. The sizes are
+// best-effort: the shards that cannot be inspected are reported as such.
+func printDatabaseResetImpact(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, shards []kubeutil.DatabaseShardConfig) {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Data to Be Destroyed"))
+	log.Info().Msg("")
+
+	shardInfos := []databaseShardInfo{}
+	var totalTables int
+	var totalRows, totalBytes int64
+	for _, shard := range shards {
+		info := inspectDatabaseShard(ctx, kubeCli, podName, debugContainerName, shard)
+		shardInfos = append(shardInfos, info)
+		totalTables += info.NumTables
+		totalRows += info.EstimatedRows
+		totalBytes += info.DataSizeBytes + info.IndexSizeBytes
+	}
 
-	err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, mainShard, sqlCmd)
+	for _, line := range renderDatabaseShardsTable(shardInfos) {
+		log.Info().Msg(line)
+	}
+	for _, info := range shardInfos {
+		log.Info().Msg("")
+		for _, line := range renderDatabaseTablesTable(info, -1) {
+			log.Info().Msg(line)
+		}
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("Estimated total: %s tables, %s rows and %s of data and indexes in %s shards",
+		styles.RenderTechnical(strconv.Itoa(totalTables)),
+		styles.RenderTechnical(strconv.FormatInt(totalRows, 10)),
+		styles.RenderTechnical(formatImageSize(totalBytes)),
+		styles.RenderTechnical(strconv.Itoa(len(shards))))
+	log.Info().Msg(styles.RenderMuted("Row counts and sizes are estimates from the database statistics."))
+}
+
+// SQL statement for marking the reset in progress, by inserting a new MetaInfo record with
+// MasterVersion -4004. Only executed on the first shard.
+const databaseResetMarkInProgressSQL = `INSERT INTO MetaInfo (Version, Timestamp, MasterVersion, NumShards)
+		SELECT Version + 1, NOW(), -4004, 0 FROM MetaInfo WHERE Version = (SELECT MAX(Version) FROM MetaInfo);`
+
+// dropDatabaseTableSQL returns the SQL statement for dropping the table.
+func dropDatabaseTableSQL(table string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", table)
+}
+
+// getDatabaseResetPhase1Tables returns the tables dropped in the first phase of a reset, ie,
+// all but MetaInfo (case-insensitive), in their original order.
+func getDatabaseResetPhase1Tables(tables []string) []string {
+	var tablesToDrop []string
+	for _, table := range tables {
+		if strings.ToLower(table) != "metainfo" {
+			tablesToDrop = append(tablesToDrop, table)
+		}
+	}
+	return tablesToDrop
+}
+
+// Helper function to mark reset in progress by setting MasterVersion to -4004
+func (o *databaseResetOpts) markResetInProgress(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, mainShard kubeutil.DatabaseShardConfig) error {
+	err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, mainShard, databaseResetMarkInProgressSQL)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to mark reset in progress (table may not exist yet)")
 		return fmt.Errorf("failed to mark reset in progress: %v", err)
@@ -322,20 +427,13 @@ func (o *databaseResetOpts) markResetInProgress(ctx context.Context, kubeCli *en
 
 // Helper function to reset a single shard - Phase 1: Drop all tables except MetaInfo
 func (o *databaseResetOpts) resetShardPhase1(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, shard kubeutil.DatabaseShardConfig, tables []string) error {
-	// Filter out MetaInfo table (case-insensitive)
-	var tablesToDrop []string
-	for _, table := range tables {
-		if strings.ToLower(table) != "metainfo" {
-			tablesToDrop = append(tablesToDrop, table)
-		}
-	}
+	tablesToDrop := getDatabaseResetPhase1Tables(tables)
 
 	log.Debug().Int("shard_index", shard.ShardIndex).Int("total_tables", len(tables)).Int("tables_to_drop", len(tablesToDrop)).Msg("Phase 1: Dropping tables except MetaInfo")
 
 	// Drop each table
 	for _, table := range tablesToDrop {
-		sqlCmd := fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", table)
-		err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, shard, sqlCmd)
+		err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, shard, dropDatabaseTableSQL(table))
 		if err != nil {
 			return fmt.Errorf("failed to drop table %s: %v", table, err)
 		}
@@ -349,8 +447,7 @@ func (o *databaseResetOpts) resetShardPhase1(ctx context.Context, kubeCli *envap
 func (o *databaseResetOpts) resetShardPhase2(ctx context.Context, kubeCli *envapi.KubeClient, podName, debugContainerName string, shard kubeutil.DatabaseShardConfig) error {
 	log.Debug().Int("shard_index", shard.ShardIndex).Msg("Phase 2: Dropping MetaInfo table")
 
-	sqlCmd := "DROP TABLE IF EXISTS `MetaInfo`;"
-	err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, shard, sqlCmd)
+	err := o.executeSQLCommand(ctx, kubeCli, podName, debugContainerName, shard, dropDatabaseTableSQL("MetaInfo"))
 	if err != nil {
 		return fmt.Errorf("failed to drop MetaInfo table: %v", err)
 	}
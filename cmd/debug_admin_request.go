@@ -13,6 +13,7 @@ import (
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -106,21 +107,12 @@ func (o *debugAdminRequestOpts) Prepare(cmd *cobra.Command, args []string) error
 		return fmt.Errorf("invalid HTTP method: %s. Must be one of: GET, POST, DELETE, PUT", o.argMethod)
 	}
 
-	// Detect MSYS/Git-Bash path mangling: when a bash arg starts with '/', MSYS
-	// rewrites it to a Windows path like "C:/Program Files/Git/api/hello". No
-	// legitimate admin API path starts with a drive letter, so this is unambiguous.
-	if len(o.argPath) >= 3 && o.argPath[1] == ':' &&
-		(o.argPath[2] == '/' || o.argPath[2] == '\\') &&
-		((o.argPath[0] >= 'A' && o.argPath[0] <= 'Z') || (o.argPath[0] >= 'a' && o.argPath[0] <= 'z')) {
-		return clierrors.NewUsageErrorf("PATH argument looks like it was rewritten by MSYS/Git-Bash: %q", o.argPath).
-			WithSuggestion(fmt.Sprintf("Drop the leading slash — the CLI adds it automatically. For example:\n  metaplay debug admin-request %s %s api/<your-path>", o.argEnvironment, o.argMethod)).
-			WithDetails("MSYS/Git-Bash rewrites bash args starting with '/' into Windows paths. You can also prefix the invocation with MSYS_NO_PATHCONV=1 to disable this conversion.")
-	}
-
-	// Ensure path starts with a slash
-	if !strings.HasPrefix(o.argPath, "/") {
-		o.argPath = "/" + o.argPath
+	// Validate and normalize the path.
+	argPath, err := normalizeAdminAPIPath(o.argPath, fmt.Sprintf("metaplay debug admin-request %s %s", o.argEnvironment, o.argMethod))
+	if err != nil {
+		return err
 	}
+	o.argPath = argPath
 
 	// Check that only one body source is provided
 	if o.flagBody != "" && o.flagFile != "" {
@@ -141,6 +133,34 @@ func IsJSON(str string) bool {
 	return json.Unmarshal([]byte(str), &js) == nil
 }
 
+// getAdminAPIBaseURL returns the base URL of the game server admin API of the environment.
+// The admin hostname follows the infra-modules convention: <humanID>-admin.<stackDomain>.
+// Avoids a privileged StackAPI /v0/deployments call just to learn the public hostname.
+func getAdminAPIBaseURL(envConfig *metaproj.ProjectEnvironmentConfig) string {
+	return fmt.Sprintf("https://%s-admin.%s", envConfig.HumanID, envConfig.StackDomain)
+}
+
+// normalizeAdminAPIPath validates the admin API request path and ensures it starts with a slash.
+// The exampleCommand is the command and arguments preceding the path, used in the suggestion.
+func normalizeAdminAPIPath(path string, exampleCommand string) (string, error) {
+	// Detect MSYS/Git-Bash path mangling: when a bash arg starts with '/', MSYS
+	// rewrites it to a Windows path like "C:/Program Files/Git/api/hello". No
+	// legitimate admin API path starts with a drive letter, so this is unambiguous.
+	if len(path) >= 3 && path[1] == ':' &&
+		(path[2] == '/' || path[2] == '\\') &&
+		((path[0] >= 'A' && path[0] <= 'Z') || (path[0] >= 'a' && path[0] <= 'z')) {
+		return "", clierrors.NewUsageErrorf("PATH argument looks like it was rewritten by MSYS/Git-Bash: %q", path).
+			WithSuggestion(fmt.Sprintf("Drop the leading slash — the CLI adds it automatically. For example:\n  %s api/<your-path>", exampleCommand)).
+			WithDetails("MSYS/Git-Bash rewrites bash args starting with '/' into Windows paths. You can also prefix the invocation with MSYS_NO_PATHCONV=1 to disable this conversion.")
+	}
+
+	// Ensure path starts with a slash
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path, nil
+}
+
 func (o *debugAdminRequestOpts) Run(cmd *cobra.Command) error {
 	// Try to resolve the project & auth provider.
 	project, err := tryResolveProject()
@@ -154,9 +174,7 @@ func (o *debugAdminRequestOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	// Admin hostname follows the infra-modules convention: <humanID>-admin.<stackDomain>.
-	// Avoids a privileged StackAPI /v0/deployments call just to learn the public hostname.
-	adminAPIBaseURL := fmt.Sprintf("https://%s-admin.%s", envConfig.HumanID, envConfig.StackDomain)
+	adminAPIBaseURL := getAdminAPIBaseURL(envConfig)
 	adminClient := metahttp.NewJSONClient(tokenSet, adminAPIBaseURL)
 
 	// Prepare request body if needed
@@ -0,0 +1,498 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Collect a diagnostics bundle from a game server deployment.
+type debugCollectDiagnosticsOpts struct {
+	UsePositionalArgs
+
+	argEnvironment  string
+	argPodName      string
+	flagOutputPath  string
+	flagLogLines    int64
+	flagNoHeapDump  bool
+	flagYes         bool
+	bundleName      string // Name of the bundle (without extension), used as the root directory in the archive.
+	collectionNotes []string
+}
+
+func init() {
+	o := debugCollectDiagnosticsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argPodName, "POD", "Pod to collect the process diagnostics and heap dump from, eg, 'service-0'.")
+
+	cmd := &cobra.Command{
+		Use:     "collect [ENVIRONMENT] [POD] [flags]",
+		Aliases: []string{"collect-diagnostics"},
+		Short:   "Collect a diagnostics bundle from a game server deployment",
+		Run:     runCommand(&o),
+		Long: renderLong(&o, `
+			Collect a diagnostics bundle from a game server deployment into a single timestamped
+			.tar.gz file, eg, for attaching to a support ticket.
+
+			The bundle contains:
+			- Descriptions of all the game server pods and recent Kubernetes events.
+			- The most recent log lines from each game server pod (see --log-lines).
+			- The Helm values and chart version of the game server release. The values of keys that
+			  look like credentials, eg, 'password' or 'apiKey', are redacted.
+			- Process and system statistics (from /proc) of the game server process in the target pod.
+			- A managed heap dump (dotnet-gcdump) of the game server process in the target pod.
+
+			WARNING: Collecting the heap dump freezes the server process for the duration of the
+			operation, which can be from seconds to minutes depending on the heap size. Use
+			--no-heap-dump to leave the heap dump out of the bundle.
+
+			The process statistics and heap dump are collected from the specified pod. If multiple
+			game server pods are running, you are asked to choose the pod.
+
+			Any parts of the bundle that fail to be collected are listed in the file
+			collection-errors.txt in the bundle.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay debug collect-heap-dump ...' to only collect a heap dump.
+			- 'metaplay debug logs ...' to view the game server logs.
+		`),
+		Example: renderExample(`
+			# Collect a diagnostics bundle from the only running pod.
+			metaplay debug collect nimbly
+
+			# Collect a diagnostics bundle targeting pod 'service-0'.
+			metaplay debug collect nimbly service-0
+
+			# Collect without the heap dump (does not freeze the server process).
+			metaplay debug collect nimbly --no-heap-dump
+
+			# Specify the output file and the number of log lines to include.
+			metaplay debug collect nimbly -o nimbly-incident.tar.gz --log-lines=5000
+		`),
+	}
+	debugCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagOutputPath, "output", "o", "", "Output path for the bundle (default: diagnostics-<environment>-YYYYMMDD-hhmmss.tar.gz)")
+	flags.Int64Var(&o.flagLogLines, "log-lines", 1000, "Number of most recent log lines to include from each pod")
+	flags.BoolVar(&o.flagNoHeapDump, "no-heap-dump", false, "Do not include a heap dump in the bundle")
+	flags.BoolVar(&o.flagYes, "yes", false, "Skip the confirmation for collecting the heap dump")
+}
+
+func (o *debugCollectDiagnosticsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagLogLines < 0 {
+		return clierrors.NewUsageErrorf("Invalid --log-lines value %d", o.flagLogLines).
+			WithSuggestion("Use a non-negative number of lines")
+	}
+
+	// Must confirm the heap dump in interactive mode, or with --yes.
+	if !o.flagNoHeapDump && !o.flagYes && !tui.IsInteractiveMode() {
+		return clierrors.NewUsageError("Collecting a heap dump requires confirmation").
+			WithSuggestion("Use --yes to confirm collecting the heap dump, or --no-heap-dump to skip it")
+	}
+
+	if o.flagOutputPath != "" && !strings.HasSuffix(o.flagOutputPath, ".tar.gz") {
+		return clierrors.NewUsageErrorf("Invalid output file name '%s'", o.flagOutputPath).
+			WithSuggestion("Use the .tar.gz extension, e.g., 'diagnostics.tar.gz'")
+	}
+
+	return nil
+}
+
+func (o *debugCollectDiagnosticsOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment config.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the output path.
+	if o.flagOutputPath == "" {
+		timestamp := time.Now().Format("20060102-150405")
+		o.flagOutputPath = fmt.Sprintf("diagnostics-%s-%s.tar.gz", envConfig.HumanID, timestamp)
+	}
+	o.bundleName = strings.TrimSuffix(filepath.Base(o.flagOutputPath), ".tar.gz")
+
+	// Resolve target environment & game server.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	gameServer, err := targetEnv.GetGameServer(ctx)
+	if err != nil {
+		return err
+	}
+	shardSetsWithPods, err := gameServer.GetAllShardSetsWithPods()
+	if err != nil {
+		return err
+	}
+
+	// Resolve target pod (or ask for it if not defined).
+	kubeCli, pod, err := resolveTargetPod(gameServer, o.argPodName)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Collect Diagnostics Bundle"))
+	log.Info().Msg("")
+	log.Info().Msgf("Target environment: %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("Target pod:         %s", styles.RenderTechnical(pod.Name))
+	log.Info().Msgf("Include heap dump:  %s", styles.RenderTechnical(fmt.Sprintf("%v", !o.flagNoHeapDump)))
+	log.Info().Msgf("Output file:        %s", styles.RenderTechnical(o.flagOutputPath))
+	log.Info().Msg("")
+
+	// Confirm the heap dump as it freezes the server process.
+	if !o.flagNoHeapDump && !o.flagYes {
+		log.Warn().Msg(styles.RenderAttention("⚠️ WARNING: Collecting the heap dump will completely freeze the server process!"))
+		log.Warn().Msg("")
+		confirmed, err := tui.DoConfirmQuestion(ctx, "Are you sure you want to continue?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msgf("Use %s to collect the bundle without the heap dump.", styles.RenderPrompt("--no-heap-dump"))
+			return clierrors.New("Diagnostics collection canceled by user")
+		}
+		log.Info().Msg("")
+	}
+
+	// Collect the files into a temporary directory.
+	bundleDir, err := os.MkdirTemp("", "metaplay-diagnostics-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(bundleDir) }()
+
+	runner := tui.NewTaskRunner()
+
+	runner.AddTask("Collect pod descriptions and events", func(output *tui.TaskOutput) error {
+		o.collectPodDescriptions(ctx, bundleDir, shardSetsWithPods)
+		return nil
+	})
+
+	runner.AddTask("Collect recent logs", func(output *tui.TaskOutput) error {
+		o.collectPodLogs(ctx, bundleDir, shardSetsWithPods)
+		return nil
+	})
+
+	runner.AddTask("Collect Helm release", func(output *tui.TaskOutput) error {
+		o.collectHelmRelease(bundleDir, gameServer.KubeCli)
+		return nil
+	})
+
+	// Create a debug container in the target pod for the process diagnostics.
+	// Keep the container alive for an hour to avoid leaks.
+	debugContainerName, cleanup, err := kubeutil.CreateDebugContainer(ctx, kubeCli, pod.Name, metaplayServerContainerName, false, false, []string{"sleep", "3600"})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	processInfo, err := kubeutil.GetServerProcessInformation(ctx, kubeCli, pod.Name, debugContainerName)
+	if err != nil {
+		return err
+	}
+
+	runner.AddTask("Collect process statistics", func(output *tui.TaskOutput) error {
+		o.collectProcessStats(ctx, bundleDir, kubeCli, pod.Name, debugContainerName, processInfo)
+		return nil
+	})
+
+	// Collect the heap dump using the same tasks as 'debug collect-heap-dump'.
+	if !o.flagNoHeapDump {
+		heapDumpOpts := &debugCollectHeapDumpOpts{
+			flagCollectMode: "gcdump",
+			flagOutputPath:  filepath.Join(bundleDir, fmt.Sprintf("heap-%s.gcdump", pod.Name)),
+		}
+		if err := heapDumpOpts.collectAndRetrieveHeapDump(ctx, kubeCli, pod.Name, debugContainerName, processInfo, runner); err != nil {
+			return err
+		}
+	}
+
+	runner.AddTask("Create bundle", func(output *tui.TaskOutput) error {
+		if len(o.collectionNotes) > 0 {
+			notes := strings.Join(o.collectionNotes, "\n") + "\n"
+			if err := os.WriteFile(filepath.Join(bundleDir, "collection-errors.txt"), []byte(notes), 0644); err != nil {
+				return fmt.Errorf("failed to write collection errors: %w", err)
+			}
+			output.AppendLinef("Failed to collect %d item(s), see collection-errors.txt", len(o.collectionNotes))
+		}
+		return writeTarGzFromDir(bundleDir, o.bundleName, o.flagOutputPath)
+	})
+
+	if err := runner.Run(); err != nil {
+		return err
+	}
+
+	log.Info().Msg(styles.RenderSuccess("✅ Diagnostics bundle collected successfully!"))
+	log.Info().Msgf("  Output file: %s", styles.RenderTechnical(o.flagOutputPath))
+	return nil
+}
+
+// addCollectionNote records a part of the bundle that failed to be collected. Failures don't
+// abort the collection so that the rest of the bundle remains useful.
+func (o *debugCollectDiagnosticsOpts) addCollectionNote(format string, args ...any) {
+	note := fmt.Sprintf(format, args...)
+	log.Debug().Msgf("Diagnostics collection: %s", note)
+	o.collectionNotes = append(o.collectionNotes, note)
+}
+
+// writeBundleFile writes a file into the bundle directory, creating any parent directories.
+func (o *debugCollectDiagnosticsOpts) writeBundleFile(bundleDir, relativePath string, content []byte) {
+	filePath := filepath.Join(bundleDir, relativePath)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		o.addCollectionNote("%s: failed to create directory: %v", relativePath, err)
+		return
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		o.addCollectionNote("%s: failed to write file: %v", relativePath, err)
+	}
+}
+
+// writeBundleJSON writes a value as indented JSON into the bundle directory.
+func (o *debugCollectDiagnosticsOpts) writeBundleJSON(bundleDir, relativePath string, value any) {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		o.addCollectionNote("%s: failed to marshal JSON: %v", relativePath, err)
+		return
+	}
+	o.writeBundleFile(bundleDir, relativePath, content)
+}
+
+// collectPodDescriptions writes the game server pods and the recent Kubernetes events in
+// the namespace of each cluster.
+func (o *debugCollectDiagnosticsOpts) collectPodDescriptions(ctx context.Context, bundleDir string, shardSetsWithPods []envapi.ShardSetWithPods) {
+	visitedClients := map[*envapi.KubeClient]bool{}
+	for _, shardSet := range shardSetsWithPods {
+		for _, pod := range shardSet.Pods {
+			o.writeBundleJSON(bundleDir, filepath.Join("pods", pod.Name+".json"), pod)
+		}
+
+		kubeCli := shardSet.ShardSet.Cluster.KubeClient
+		if visitedClients[kubeCli] {
+			continue
+		}
+		visitedClients[kubeCli] = true
+
+		events, err := kubeCli.Clientset.CoreV1().Events(kubeCli.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			o.addCollectionNote("events: failed to list events: %v", err)
+			continue
+		}
+		o.writeBundleJSON(bundleDir, fmt.Sprintf("events-%d.json", len(visitedClients)-1), events.Items)
+	}
+}
+
+// collectPodLogs writes the most recent log lines of each game server pod.
+func (o *debugCollectDiagnosticsOpts) collectPodLogs(ctx context.Context, bundleDir string, shardSetsWithPods []envapi.ShardSetWithPods) {
+	for _, shardSet := range shardSetsWithPods {
+		kubeCli := shardSet.ShardSet.Cluster.KubeClient
+		for _, pod := range shardSet.Pods {
+			tailLines := o.flagLogLines
+			logOpts := &corev1.PodLogOptions{
+				Container:  metaplayServerContainerName,
+				Timestamps: true,
+				TailLines:  &tailLines,
+			}
+			logs, err := kubeCli.Clientset.CoreV1().Pods(kubeCli.Namespace).GetLogs(pod.Name, logOpts).DoRaw(ctx)
+			if err != nil {
+				o.addCollectionNote("logs/%s.log: failed to read logs: %v", pod.Name, err)
+				continue
+			}
+			o.writeBundleFile(bundleDir, filepath.Join("logs", pod.Name+".log"), logs)
+		}
+	}
+}
+
+// collectHelmRelease writes the Helm values and release information of the game server.
+func (o *debugCollectDiagnosticsOpts) collectHelmRelease(bundleDir string, kubeCli *envapi.KubeClient) {
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, kubeCli.Namespace)
+	if err != nil {
+		o.addCollectionNote("helm: failed to initialize Helm: %v", err)
+		return
+	}
+
+	release, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		o.addCollectionNote("helm: %v", err)
+		return
+	}
+	if release == nil {
+		o.addCollectionNote("helm: no game server Helm release found")
+		return
+	}
+
+	releaseInfo := fmt.Sprintf("Release:  %s\nChart:    %s\nVersion:  %s\nRevision: %d\nStatus:   %s\nDeployed: %s\n",
+		release.Name,
+		release.Chart.Metadata.Name,
+		release.Chart.Metadata.Version,
+		release.Version,
+		release.Info.Status,
+		release.Info.LastDeployed.Format(time.RFC3339))
+	o.writeBundleFile(bundleDir, filepath.Join("helm", "release.txt"), []byte(releaseInfo))
+
+	values, err := yaml.Marshal(redactHelmValues(release.Config))
+	if err != nil {
+		o.addCollectionNote("helm/values.yaml: failed to marshal values: %v", err)
+		return
+	}
+	o.writeBundleFile(bundleDir, filepath.Join("helm", "values.yaml"), values)
+}
+
+// Substrings of the (lower-cased) Helm value keys whose values are redacted from the bundle.
+var sensitiveHelmValueKeyParts = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "privatekey", "private_key", "accesskey", "access_key", "credential", "connectionstring"}
+
+// redactHelmValues returns a copy of the Helm values with the values of credential-looking keys
+// redacted, so that they don't leak via the diagnostics bundle.
+func redactHelmValues(values map[string]any) map[string]any {
+	redacted := make(map[string]any, len(values))
+	for key, value := range values {
+		if isSensitiveHelmValueKey(key) && value != nil {
+			redacted[key] = "<redacted>"
+		} else {
+			redacted[key] = redactHelmValue(value)
+		}
+	}
+	return redacted
+}
+
+// redactHelmValue redacts the nested maps and lists of a Helm value.
+func redactHelmValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		return redactHelmValues(typed)
+	case []any:
+		result := make([]any, len(typed))
+		for ndx, elem := range typed {
+			result[ndx] = redactHelmValue(elem)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// isSensitiveHelmValueKey returns whether the Helm value key looks like it holds a credential.
+func isSensitiveHelmValueKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, part := range sensitiveHelmValueKeyParts {
+		if strings.Contains(lowerKey, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectProcessStats writes the /proc statistics of the game server process and its host.
+func (o *debugCollectDiagnosticsOpts) collectProcessStats(ctx context.Context, bundleDir string, kubeCli *envapi.KubeClient, podName, debugContainerName string, processInfo *kubeutil.ServerProcessInfo) {
+	procFiles := []struct {
+		fileName   string
+		remotePath string
+	}{
+		{"status.txt", fmt.Sprintf("/proc/%d/status", processInfo.Pid)},
+		{"limits.txt", fmt.Sprintf("/proc/%d/limits", processInfo.Pid)},
+		{"io.txt", fmt.Sprintf("/proc/%d/io", processInfo.Pid)},
+		{"sched.txt", fmt.Sprintf("/proc/%d/sched", processInfo.Pid)},
+		{"meminfo.txt", "/proc/meminfo"},
+		{"loadavg.txt", "/proc/loadavg"},
+		{"uptime.txt", "/proc/uptime"},
+	}
+
+	for _, procFile := range procFiles {
+		relativePath := filepath.Join("process", podName, procFile.fileName)
+		stdout, stderr, err := kubeutil.ExecInDebugContainer(ctx, kubeCli, podName, debugContainerName, "cat "+procFile.remotePath)
+		if err != nil {
+			o.addCollectionNote("%s: failed to read %s: %v %s", relativePath, procFile.remotePath, err, strings.TrimSpace(stderr))
+			continue
+		}
+		o.writeBundleFile(bundleDir, relativePath, []byte(stdout))
+	}
+}
+
+// writeTarGzFromDir writes all the files in srcDir into a .tar.gz archive at destPath, with
+// the files placed under the rootName directory in the archive.
+func writeTarGzFromDir(srcDir, rootName, destPath string) error {
+	file, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", destPath, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(filepath.Join(rootName, relativePath))
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		srcFile, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = srcFile.Close() }()
+		_, err = io.Copy(tarWriter, srcFile)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write diagnostics bundle: %w", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finalize diagnostics bundle: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finalize diagnostics bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTarGzFromDir(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "logs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "logs", "service-0.log"), []byte("hello\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "collection-errors.txt"), []byte("oops\n"), 0644))
+
+	destPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, writeTarGzFromDir(srcDir, "diagnostics-nimbly", destPath))
+
+	file, err := os.Open(destPath)
+	require.NoError(t, err)
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	contents := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		contents[header.Name] = string(data)
+	}
+
+	assert.Equal(t, map[string]string{
+		"diagnostics-nimbly/logs/service-0.log":    "hello\n",
+		"diagnostics-nimbly/collection-errors.txt": "oops\n",
+	}, contents)
+}
+
+func TestDebugCollectDiagnosticsPrepare(t *testing.T) {
+	wasInteractive := tui.IsInteractiveMode()
+	tui.SetInteractiveMode(false)
+	defer tui.SetInteractiveMode(wasInteractive)
+
+	o := debugCollectDiagnosticsOpts{flagLogLines: 100, flagNoHeapDump: true}
+	assert.NoError(t, o.Prepare(nil, nil))
+
+	// Heap dump requires confirmation in non-interactive mode.
+	o = debugCollectDiagnosticsOpts{flagLogLines: 100}
+	assert.Error(t, o.Prepare(nil, nil))
+	o = debugCollectDiagnosticsOpts{flagLogLines: 100, flagYes: true}
+	assert.NoError(t, o.Prepare(nil, nil))
+
+	o = debugCollectDiagnosticsOpts{flagLogLines: -1, flagNoHeapDump: true}
+	assert.Error(t, o.Prepare(nil, nil))
+
+	o = debugCollectDiagnosticsOpts{flagLogLines: 100, flagNoHeapDump: true, flagOutputPath: "bundle.zip"}
+	assert.Error(t, o.Prepare(nil, nil))
+}
+
+func TestRedactHelmValues(t *testing.T) {
+	values := map[string]any{
+		"image": map[string]any{"tag": "364cff09"},
+		"config": map[string]any{
+			"database": map[string]any{"Password": "hunter2", "host": "db"},
+			"apiKey":   "abc",
+			"tokens":   []any{"a", "b"},
+		},
+		"sidecars": []any{
+			map[string]any{"name": "proxy", "clientSecret": "xyz"},
+		},
+		"secretName": nil,
+	}
+
+	redacted := redactHelmValues(values)
+	assert.Equal(t, map[string]any{
+		"image": map[string]any{"tag": "364cff09"},
+		"config": map[string]any{
+			"database": map[string]any{"Password": "<redacted>", "host": "db"},
+			"apiKey":   "<redacted>",
+			"tokens":   "<redacted>",
+		},
+		"sidecars": []any{
+			map[string]any{"name": "proxy", "clientSecret": "<redacted>"},
+		},
+		"secretName": nil,
+	}, redacted)
+
+	// The original values are not modified.
+	assert.Equal(t, "hunter2", values["config"].(map[string]any)["database"].(map[string]any)["Password"])
+}
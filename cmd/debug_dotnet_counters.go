@@ -0,0 +1,454 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Header of the CSV files written by 'dotnet-counters collect --format csv'.
+var dotnetCountersCSVHeader = []string{"Timestamp", "Provider", "Counter Name", "Counter Type", "Mean/Increment"}
+
+type debugDotnetCountersOpts struct {
+	UsePositionalArgs
+
+	argEnvironment      string
+	argPodName          string
+	extraArgs           []string
+	flagCounters        string
+	flagDuration        time.Duration
+	flagRefreshInterval int
+	flagOutput          string
+	flagOutputFile      string
+}
+
+func init() {
+	o := debugDotnetCountersOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argPodName, "POD", "Name of the target pod, eg, 'service-0'.")
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to 'dotnet-counters collect'")
+
+	cmd := &cobra.Command{
+		Use:     "dotnet-counters [ENVIRONMENT] [POD] [flags]",
+		Aliases: []string{"counters"},
+		Short:   "Stream live .NET runtime metrics from a running server pod",
+		Run:     runCommand(&o),
+		Long: renderLong(&o, `
+			Stream live .NET runtime metrics (GC, thread pool, exceptions, CPU usage, etc.) from
+			a running game server pod using dotnet-counters.
+
+			This command will create a debug container in the target pod, attach dotnet-counters
+			to the game server process and stream the counter values back to your machine.
+
+			By default, the latest values are shown in a table that is refreshed as new samples
+			arrive. With --output=csv, all the samples are instead written into a local CSV file
+			for offline analysis.
+
+			The collection runs until the --duration has elapsed or until interrupted with Ctrl+C.
+
+			{Arguments}
+		`),
+		Example: renderExample(`
+			# Show live runtime counters from the only running pod.
+			metaplay debug dotnet-counters nimbly
+
+			# Show live runtime counters from pod 'service-0'.
+			metaplay debug dotnet-counters nimbly service-0
+
+			# Collect counters for 5 minutes into a CSV file.
+			metaplay debug dotnet-counters nimbly --duration 5m --output csv --output-file counters.csv
+
+			# Only show selected counters, refreshing every 5 seconds.
+			metaplay debug dotnet-counters nimbly --counters System.Runtime[cpu-usage,working-set] --refresh-interval 5
+
+			# Pass extra arguments to dotnet-counters (after --)
+			metaplay debug dotnet-counters nimbly -- --max-histograms 20
+		`),
+	}
+	debugCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagCounters, "counters", "", "Counters to collect, eg, 'System.Runtime,Microsoft.AspNetCore.Hosting' (default: System.Runtime)")
+	flags.DurationVar(&o.flagDuration, "duration", 0, "How long to collect the counters for, eg, '30s' or '5m' (default: until interrupted)")
+	flags.IntVar(&o.flagRefreshInterval, "refresh-interval", 1, "Interval between counter samples in seconds")
+	flags.StringVar(&o.flagOutput, "output", "table", "Output mode: 'table' for a live table or 'csv' to write all samples to a file")
+	flags.StringVar(&o.flagOutputFile, "output-file", "", "Path of the CSV file to write with --output=csv (default: counters-YYYYMMDD-hhmmss.csv)")
+}
+
+func (o *debugDotnetCountersOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagOutput != "table" && o.flagOutput != "csv" {
+		return clierrors.NewUsageErrorf("Invalid --output '%s'", o.flagOutput).
+			WithSuggestion("Use either '--output=table' or '--output=csv'")
+	}
+
+	if o.flagOutputFile != "" && o.flagOutput != "csv" {
+		return clierrors.NewUsageError("The --output-file flag can only be used with --output=csv")
+	}
+
+	if o.flagRefreshInterval <= 0 {
+		return clierrors.NewUsageError("The --refresh-interval must be at least 1 second")
+	}
+
+	if o.flagDuration < 0 {
+		return clierrors.NewUsageError("The --duration must not be negative")
+	}
+	if o.flagDuration > 0 && o.flagDuration < time.Second {
+		return clierrors.NewUsageError("The --duration must be at least 1 second")
+	}
+
+	if o.flagOutput == "csv" && o.flagOutputFile == "" {
+		o.flagOutputFile = fmt.Sprintf("counters-%s.csv", time.Now().Format("20060102-150405"))
+	}
+
+	return nil
+}
+
+func (o *debugDotnetCountersOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment config.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Resolve target environment & game server.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	gameServer, err := targetEnv.GetGameServer(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// Resolve target pod (or ask for it if not defined).
+	kubeCli, pod, err := resolveTargetPod(gameServer, o.argPodName)
+	if err != nil {
+		return err
+	}
+
+	// Create and manage debug container in the server pod.
+	// Keep the container alive for an hour to avoid leaks.
+	debugContainerName, cleanup, err := kubeutil.CreateDebugContainer(cmd.Context(), kubeCli, pod.Name, metaplayServerContainerName, false, false, []string{"sleep", "3600"})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Get information about the running server process.
+	processInfo, err := kubeutil.GetServerProcessInformation(cmd.Context(), kubeCli, pod.Name, debugContainerName)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Msgf("Game server process found with PID %d, running as user %s.", processInfo.Pid, processInfo.Username)
+
+	durationStr := "until interrupted"
+	if o.flagDuration > 0 {
+		durationStr = o.flagDuration.String()
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Stream .NET Counters"))
+	log.Info().Msg("")
+	log.Info().Msgf("Target pod:       %s", styles.RenderTechnical(pod.Name))
+	log.Info().Msgf("Duration:         %s", styles.RenderTechnical(durationStr))
+	log.Info().Msgf("Refresh interval: %s", styles.RenderTechnical(fmt.Sprintf("%d seconds", o.flagRefreshInterval)))
+	if o.flagOutput == "csv" {
+		log.Info().Msgf("Output file:      %s", styles.RenderTechnical(o.flagOutputFile))
+	}
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderMuted("Press Ctrl+C to stop."))
+	log.Info().Msg("")
+
+	// Open the output file (if any) before starting the collection.
+	var csvWriter *csv.Writer
+	if o.flagOutput == "csv" {
+		file, err := os.Create(o.flagOutputFile)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to create output file %s", o.flagOutputFile)
+		}
+		defer file.Close()
+
+		csvWriter = csv.NewWriter(file)
+		if err := csvWriter.Write(dotnetCountersCSVHeader); err != nil {
+			return clierrors.Wrapf(err, "Failed to write to output file %s", o.flagOutputFile)
+		}
+		csvWriter.Flush()
+	}
+
+	// Start dotnet-counters in the debug container and stream its CSV output back.
+	remoteScript := buildDotnetCountersScript(processInfo, time.Now().Format("20060102-150405"), o.flagRefreshInterval, o.flagDuration, o.flagCounters, o.extraArgs)
+	log.Debug().Msgf("Execute on remote: %s", remoteScript)
+
+	ctx := cmd.Context()
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	remoteStderr := new(strings.Builder)
+	streamDone := make(chan error, 1)
+	go func() {
+		err := kubeutil.StreamFromDebugContainer(ctx, kubeCli, pod.Name, debugContainerName, remoteScript, writer, remoteStderr)
+		writer.CloseWithError(err)
+		streamDone <- err
+	}()
+
+	// When interrupted, stop dotnet-counters in the debug container as it is not
+	// terminated along with the stream.
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		_, _, err := kubeutil.ExecInDebugContainer(stopCtx, kubeCli, pod.Name, debugContainerName, "pkill -INT -f 'dotnet-counters collect'")
+		if err != nil {
+			log.Debug().Msgf("Failed to stop dotnet-counters in the debug container: %v", err)
+		}
+	}()
+
+	// Consume the samples as they arrive.
+	table := newCountersTable()
+	display := countersTableDisplay{redrawInPlace: tui.IsInteractiveMode()}
+	numSamples := 0
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	for {
+		record, err := csvReader.Read()
+		if err != nil {
+			// Stream errors are handled below, only malformed rows are of interest here.
+			if _, ok := errors.AsType[*csv.ParseError](err); ok {
+				log.Debug().Msgf("Skipping malformed dotnet-counters output: %v", err)
+				continue
+			}
+			break
+		}
+
+		sample, ok := parseCountersCSVRecord(record)
+		if !ok {
+			continue
+		}
+		numSamples++
+
+		if csvWriter != nil {
+			if err := csvWriter.Write(record); err != nil {
+				return clierrors.Wrapf(err, "Failed to write to output file %s", o.flagOutputFile)
+			}
+			csvWriter.Flush()
+			continue
+		}
+
+		// All counters of a single refresh share the timestamp: show the table once
+		// a sample from the next refresh arrives.
+		if table.update(sample) {
+			display.show(table)
+		}
+	}
+
+	// Wait for the remote command to finish and check the result. Interrupting
+	// the collection is the normal way of stopping so it's not an error.
+	streamErr := <-streamDone
+	if streamErr != nil && ctx.Err() == nil {
+		return clierrors.Wrap(streamErr, "Failed to collect counters with dotnet-counters").
+			WithDetails(strings.TrimSpace(remoteStderr.String()))
+	}
+
+	if csvWriter == nil {
+		display.show(table)
+		log.Info().Msg("")
+	}
+
+	if numSamples == 0 {
+		log.Warn().Msg("No counter samples were received from the server")
+		return nil
+	}
+
+	if csvWriter != nil {
+		log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Collected %d counter samples", numSamples)))
+		log.Info().Msgf("  Output file: %s", styles.RenderTechnical(o.flagOutputFile))
+	}
+	return nil
+}
+
+// buildDotnetCountersScript builds the shell script that runs dotnet-counters against the
+// server process in the debug container. The samples are written into a CSV file which is
+// followed for as long as dotnet-counters is running. On failure, the output of
+// dotnet-counters is written to stderr.
+func buildDotnetCountersScript(processInfo *kubeutil.ServerProcessInfo, suffix string, refreshInterval int, duration time.Duration, counters string, extraArgs []string) string {
+	csvPath := fmt.Sprintf("/tmp/counters-%s.csv", suffix)
+	logPath := fmt.Sprintf("/tmp/counters-%s.log", suffix)
+
+	collectCmd := fmt.Sprintf("dotnet-counters collect -p %d --format csv --refresh-interval %d -o %s", processInfo.Pid, refreshInterval, csvPath)
+	if counters != "" {
+		collectCmd += fmt.Sprintf(" --counters %s", counters)
+	}
+	if duration > 0 {
+		collectCmd += fmt.Sprintf(" --duration %s", formatDotnetCountersDuration(duration))
+	}
+	if len(extraArgs) > 0 {
+		collectCmd += " " + strings.Join(extraArgs, " ")
+	}
+
+	// If server is running as non-root, collect counters as that user
+	if processInfo.Username != "root" {
+		collectCmd = fmt.Sprintf("su %s -c 'sh -c \"%s\"'", processInfo.Username, collectCmd)
+	}
+
+	lines := []string{
+		fmt.Sprintf("rm -f %s %s", csvPath, logPath),
+		fmt.Sprintf("%s > %s 2>&1 &", collectCmd, logPath),
+		"pid=$!",
+		fmt.Sprintf("while [ ! -s %s ] && kill -0 $pid 2>/dev/null; do sleep 0.2; done", csvPath),
+		fmt.Sprintf("if [ -f %s ]; then tail -n +1 -f --pid=$pid %s; fi", csvPath, csvPath),
+		"wait $pid; rc=$?",
+		fmt.Sprintf("if [ $rc -ne 0 ]; then cat %s >&2; fi", logPath),
+		fmt.Sprintf("rm -f %s %s", csvPath, logPath),
+		"exit $rc",
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDotnetCountersDuration formats the duration in the 'dd:hh:mm:ss' format expected by
+// dotnet-counters. Fractional seconds are rounded up.
+func formatDotnetCountersDuration(duration time.Duration) string {
+	totalSeconds := int64((duration + time.Second - 1) / time.Second)
+	days := totalSeconds / 86400
+	hours := (totalSeconds % 86400) / 3600
+	minutes := (totalSeconds % 3600) / 60
+	seconds := totalSeconds % 60
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", days, hours, minutes, seconds)
+}
+
+// countersSample is a single sample of a counter from the dotnet-counters CSV output.
+type countersSample struct {
+	timestamp   string
+	provider    string
+	name        string
+	counterType string
+	value       float64
+}
+
+// parseCountersCSVRecord parses a row of the dotnet-counters CSV output. Returns false for
+// the header and any rows that are not valid samples.
+func parseCountersCSVRecord(record []string) (countersSample, bool) {
+	if len(record) < len(dotnetCountersCSVHeader) || record[0] == dotnetCountersCSVHeader[0] {
+		return countersSample{}, false
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+	if err != nil {
+		return countersSample{}, false
+	}
+
+	return countersSample{
+		timestamp:   record[0],
+		provider:    record[1],
+		name:        record[2],
+		counterType: record[3],
+		value:       value,
+	}, true
+}
+
+// countersTable holds the latest value of each counter, in the order they were first seen.
+type countersTable struct {
+	keys          []string
+	latest        map[string]countersSample
+	lastTimestamp string
+}
+
+func newCountersTable() *countersTable {
+	return &countersTable{
+		latest: map[string]countersSample{},
+	}
+}
+
+// update stores the sample in the table. Returns true if the sample starts a new refresh,
+// ie, all the samples of the previous refresh have been received and can be shown.
+func (table *countersTable) update(sample countersSample) bool {
+	isNewRefresh := table.lastTimestamp != "" && sample.timestamp != table.lastTimestamp
+	table.lastTimestamp = sample.timestamp
+
+	key := sample.provider + "/" + sample.name
+	if _, ok := table.latest[key]; !ok {
+		table.keys = append(table.keys, key)
+	}
+	table.latest[key] = sample
+	return isNewRefresh
+}
+
+// render formats the table with counters grouped by their provider.
+func (table *countersTable) render() []string {
+	if len(table.keys) == 0 {
+		return []string{styles.RenderMuted("Waiting for counters...")}
+	}
+
+	nameWidth := 0
+	for _, key := range table.keys {
+		nameWidth = max(nameWidth, len(table.latest[key].name))
+	}
+
+	lines := []string{styles.RenderMuted(fmt.Sprintf("Updated at %s", table.lastTimestamp))}
+	provider := ""
+	for _, key := range table.keys {
+		sample := table.latest[key]
+		if sample.provider != provider {
+			provider = sample.provider
+			lines = append(lines, "", styles.RenderTitle(fmt.Sprintf("[%s]", provider)))
+		}
+		lines = append(lines, fmt.Sprintf("  %-*s  %s", nameWidth, sample.name, styles.RenderTechnical(formatCounterValue(sample.value))))
+	}
+	return lines
+}
+
+// formatCounterValue formats the counter value: whole numbers without decimals and others
+// with two decimals.
+func formatCounterValue(value float64) string {
+	if value == float64(int64(value)) {
+		return strconv.FormatInt(int64(value), 10)
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// countersTableDisplay shows the counters table on the terminal. In interactive mode, the
+// previously shown table is overwritten to keep the display in place.
+type countersTableDisplay struct {
+	redrawInPlace bool
+	numLinesShown int
+}
+
+func (display *countersTableDisplay) show(table *countersTable) {
+	lines := table.render()
+
+	var sb strings.Builder
+	if display.redrawInPlace && display.numLinesShown > 0 {
+		// Move the cursor to the start of the previous table and clear until the end of screen.
+		fmt.Fprintf(&sb, "\033[%dA\033[J", display.numLinesShown)
+	} else if display.numLinesShown > 0 {
+		sb.WriteString("\n")
+	}
+	sb.WriteString(strings.Join(lines, "\n"))
+	log.Info().Msg(sb.String())
+
+	display.numLinesShown = len(lines)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCountersCSVRecord(t *testing.T) {
+	sample, ok := parseCountersCSVRecord([]string{"10/16/2026 12:00:01", "System.Runtime", "CPU Usage (%)", "Metric", "12.5"})
+	require.True(t, ok)
+	assert.Equal(t, countersSample{
+		timestamp:   "10/16/2026 12:00:01",
+		provider:    "System.Runtime",
+		name:        "CPU Usage (%)",
+		counterType: "Metric",
+		value:       12.5,
+	}, sample)
+
+	// Header, short and non-numeric rows are skipped.
+	_, ok = parseCountersCSVRecord(dotnetCountersCSVHeader)
+	assert.False(t, ok)
+	_, ok = parseCountersCSVRecord([]string{"10/16/2026 12:00:01", "System.Runtime"})
+	assert.False(t, ok)
+	_, ok = parseCountersCSVRecord([]string{"10/16/2026 12:00:01", "System.Runtime", "CPU Usage (%)", "Metric", "n/a"})
+	assert.False(t, ok)
+}
+
+func TestCountersTableUpdate(t *testing.T) {
+	table := newCountersTable()
+
+	// Samples of the first refresh don't complete it.
+	assert.False(t, table.update(countersSample{timestamp: "t1", provider: "System.Runtime", name: "CPU Usage (%)", value: 10}))
+	assert.False(t, table.update(countersSample{timestamp: "t1", provider: "System.Runtime", name: "Working Set (MB)", value: 512}))
+
+	// First sample of the next refresh completes the previous one and replaces the old value.
+	assert.True(t, table.update(countersSample{timestamp: "t2", provider: "System.Runtime", name: "CPU Usage (%)", value: 20.25}))
+	assert.Equal(t, []string{"System.Runtime/CPU Usage (%)", "System.Runtime/Working Set (MB)"}, table.keys)
+
+	rendered := strings.Join(table.render(), "\n")
+	assert.Contains(t, rendered, "System.Runtime")
+	assert.Contains(t, rendered, "20.25")
+	assert.Contains(t, rendered, "512")
+	assert.NotContains(t, rendered, "512.00")
+}
+
+func TestFormatDotnetCountersDuration(t *testing.T) {
+	assert.Equal(t, "00:00:00:30", formatDotnetCountersDuration(30*time.Second))
+	assert.Equal(t, "00:01:05:00", formatDotnetCountersDuration(65*time.Minute))
+	assert.Equal(t, "01:00:00:01", formatDotnetCountersDuration(24*time.Hour+500*time.Millisecond))
+}
+
+func TestBuildDotnetCountersScript(t *testing.T) {
+	script := buildDotnetCountersScript(&kubeutil.ServerProcessInfo{Pid: 42, Username: "root"}, "x", 2, time.Minute, "System.Runtime", nil)
+	assert.Contains(t, script, "dotnet-counters collect -p 42 --format csv --refresh-interval 2 -o /tmp/counters-x.csv --counters System.Runtime --duration 00:00:01:00 >")
+	assert.Contains(t, script, "tail -n +1 -f --pid=$pid /tmp/counters-x.csv")
+
+	// Non-root servers are attached to as the same user.
+	script = buildDotnetCountersScript(&kubeutil.ServerProcessInfo{Pid: 42, Username: "app"}, "x", 1, 0, "", []string{"--max-histograms", "5"})
+	assert.Contains(t, script, "su app -c 'sh -c \"dotnet-counters collect -p 42 --format csv --refresh-interval 1 -o /tmp/counters-x.csv --max-histograms 5\"'")
+	assert.NotContains(t, script, "--duration")
+}
@@ -9,11 +9,14 @@ import (
 	"container/heap"
 	"context"
 	"fmt"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -35,7 +38,14 @@ type debugLogsOpts struct {
 	flagSince      time.Duration // Show logs since X duration ago
 	flagSinceTime  string        // Show logs since the specified timestamp (RFC3339)
 	flagFollow     bool          // Keep streaming logs in until terminated
+	flagTail       int64         // Number of most recent lines to show from each pod (-1 for all)
+	flagContainer  string        // Name of the container to show logs from
+	flagGrep       string        // Only show lines matching this regular expression
+	flagLevel      string        // Only show structured lines with at least this level
+	flagSource     string        // Only show structured lines from this source
+	flagOutputFile string        // Also write the logs to this file
 	sinceTime      *time.Time    // Parsed flagSinceTime (or nil of flagSinceTime is empty)
+	filter         logEntryFilter
 }
 
 func init() {
@@ -51,6 +61,23 @@ func init() {
 		Long: renderLong(&o, `
 			Show logs from one or more game server pods in the target environment.
 
+			The logs from all the game server pods are streamed concurrently and merged in
+			timestamp order. Each line is prefixed with the name of the pod it came from, with
+			a different color for each pod.
+
+			Use --tail to limit the number of historical lines shown from each pod, --container
+			to show logs from a sidecar container instead of the game server, and --grep to only
+			show the lines matching a regular expression.
+
+			Log lines emitted by the game server in JSON format are parsed and shown in a
+			human-readable form, colored by their level. Use --level to only show the lines with
+			at least the given level (verbose, debug, info, warn, error, fatal) and --source to
+			only show the lines from sources matching the given name (eg, 'PlayerActor'). When
+			either of these is used, lines that are not in JSON format are not shown.
+
+			Use --output-file to also write the logs to a file (without colors and with the
+			timestamps included) for sharing or later analysis.
+
 			{Arguments}
 
 			Related commands:
@@ -71,6 +98,18 @@ func init() {
 
 			# Show logs since Dec 27th, 2024 15:04:05 UTC.
 			metaplay debug logs nimbly --since-time=2024-12-27T15:04:05Z
+
+			# Show the last 100 lines from each pod and keep streaming.
+			metaplay debug logs nimbly --tail=100 -f
+
+			# Show only the lines mentioning errors or exceptions.
+			metaplay debug logs nimbly --grep='Error|Exception'
+
+			# Show only warnings and errors from the PlayerActor.
+			metaplay debug logs nimbly --level=warn --source=PlayerActor
+
+			# Write the logs from the last hour into a file.
+			metaplay debug logs nimbly --since=1h --output-file=nimbly.log
 		`),
 	}
 
@@ -82,6 +121,12 @@ func init() {
 	flags.DurationVar(&o.flagSince, "since", 0, "Show logs more recent than specified duration like 30s, 15m, or 3h. Defaults to all logs.")
 	flags.StringVar(&o.flagSinceTime, "since-time", "", "Show logs more recent than specified timestamp. Defaults to all logs.")
 	flags.BoolVarP(&o.flagFollow, "follow", "f", false, "Keep streaming logs from pods until terminated.")
+	flags.Int64Var(&o.flagTail, "tail", -1, "Number of most recent lines to show from each pod. Defaults to all lines.")
+	flags.StringVar(&o.flagContainer, "container", metaplayServerContainerName, "Name of the container in the pods to show logs from.")
+	flags.StringVar(&o.flagGrep, "grep", "", "Only show lines matching this regular expression.")
+	flags.StringVar(&o.flagLevel, "level", "", "Only show JSON log lines with at least this level (verbose, debug, info, warn, error, fatal).")
+	flags.StringVar(&o.flagSource, "source", "", "Only show JSON log lines from sources matching this name, eg, 'PlayerActor'.")
+	flags.StringVar(&o.flagOutputFile, "output-file", "", "Also write the logs into this file.")
 }
 
 func (o *debugLogsOpts) Prepare(cmd *cobra.Command, args []string) error {
@@ -101,6 +146,32 @@ func (o *debugLogsOpts) Prepare(cmd *cobra.Command, args []string) error {
 		o.sinceTime = &t
 	}
 
+	if o.flagTail < -1 {
+		return clierrors.NewUsageErrorf("Invalid --tail value %d", o.flagTail).
+			WithSuggestion("Use a non-negative number of lines, or -1 to show all lines")
+	}
+
+	// Compile the --grep filter (if specified).
+	if o.flagGrep != "" {
+		re, err := regexp.Compile(o.flagGrep)
+		if err != nil {
+			return clierrors.WrapUsageError(err, "Invalid --grep regular expression").
+				WithSuggestion("Use Go regular expression syntax, e.g., 'Error|Exception'")
+		}
+		o.filter.grep = re
+	}
+
+	// Parse the --level filter (if specified).
+	if o.flagLevel != "" {
+		level, err := parseLogLevel(o.flagLevel)
+		if err != nil {
+			return clierrors.WrapUsageError(err, "Invalid --level value").
+				WithSuggestion("Use one of: verbose, debug, info, warn, error, fatal")
+		}
+		o.filter.minLevel = level
+	}
+	o.filter.source = o.flagSource
+
 	return nil
 }
 
@@ -162,11 +233,29 @@ func (o *debugLogsOpts) Run(cmd *cobra.Command) error {
 		log.Debug().Msgf("Filtered game server pods to: %s", strings.Join(getPodNames(pods), ", "))
 	}
 
+	// Open the output file (if specified).
+	writer := &logEntryWriter{}
+	if o.flagOutputFile != "" {
+		file, err := os.Create(o.flagOutputFile)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to create output file %s", o.flagOutputFile)
+		}
+		defer func() { _ = file.Close() }()
+		writer.file = file
+	}
+
 	// Stream logs from the pods.
-	return o.readOrderedLogs(cmd.Context(), kubeCli, pods)
+	if err := o.readOrderedLogs(cmd.Context(), kubeCli, pods, writer); err != nil {
+		return err
+	}
+
+	if writer.file != nil {
+		stderrLogger.Info().Msgf("Wrote %d log lines to %s", writer.numWritten, o.flagOutputFile)
+	}
+	return nil
 }
 
-func (o *debugLogsOpts) readOrderedLogs(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod) error {
+func (o *debugLogsOpts) readOrderedLogs(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod, writer *logEntryWriter) error {
 	// Use current time as the cut-off time between historical and real-time streaming logs.
 	cutoffTime := time.Now().UTC()
 	log.Debug().Msgf("Use cutoff time: %s", cutoffTime)
@@ -177,22 +266,22 @@ func (o *debugLogsOpts) readOrderedLogs(ctx context.Context, kubeCli *envapi.Kub
 	// Start reading/following the realtime logs from each pod, starting from cutoffTime.
 	var realtimeSources []*podLogSource
 	if o.flagFollow {
-		realtimeSources = readRealtimeLogsFromPods(ctx, kubeCli, pods, cutoffTime)
+		realtimeSources = o.readRealtimeLogsFromPods(ctx, kubeCli, pods, cutoffTime)
 	}
 
 	// Aggregate historical source while merging the sources in timestamp order (until completion).
-	aggregateHistoricalLogsInTimeOrder(historicalSources)
+	aggregateHistoricalLogsInTimeOrder(historicalSources, writer)
 	log.Debug().Msgf("Switch from historical to realtime logs")
 
 	// Next, aggregate real-time sources with a small time-window for merging sources in timestamp order.
 	if o.flagFollow {
-		aggregateRealtimeSourcesInTimeOrder(realtimeSources)
+		aggregateRealtimeSourcesInTimeOrder(realtimeSources, writer)
 	}
 
 	return nil
 }
 
-func readPodLogsWithOpts(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod, logOpts *corev1.PodLogOptions, cutoffTime *time.Time) []*podLogSource {
+func readPodLogsWithOpts(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod, logOpts *corev1.PodLogOptions, filter *logEntryFilter, cutoffTime *time.Time) []*podLogSource {
 	// Determine longest prefix name (to keep the prefixes aligned).
 	longestPrefixName := getLongestPodPrefix(pods)
 
@@ -201,11 +290,13 @@ func readPodLogsWithOpts(ctx context.Context, kubeCli *envapi.KubeClient, pods [
 	for ndx, pod := range pods {
 		req := kubeCli.Clientset.CoreV1().Pods(kubeCli.Namespace).GetLogs(pod.Name, logOpts)
 		channel := make(chan LogEntry, logEntryBufferSize)
-		prefix := rightPad(fmt.Sprintf("%s:", pod.Name), longestPrefixName+1)
+		prefix := styles.RenderSourceColor(ndx, rightPad(fmt.Sprintf("%s:", pod.Name), longestPrefixName+1))
 		sources[ndx] = &podLogSource{
+			podName: pod.Name,
 			prefix:  prefix,
 			request: req,
 			channel: channel,
+			filter:  filter,
 		}
 	}
 
@@ -230,39 +321,68 @@ func (o *debugLogsOpts) readHistoricalLogsFromPods(ctx context.Context, kubeCli
 		sinceTimePtr = &metav1.Time{Time: *o.sinceTime}
 	}
 
+	var tailLinesPtr *int64
+	if o.flagTail >= 0 {
+		tailLines := o.flagTail
+		tailLinesPtr = &tailLines
+	}
+
 	opts := &corev1.PodLogOptions{
 		Follow:       false,
-		Container:    metaplayServerContainerName,
+		Container:    o.flagContainer,
 		Timestamps:   true,
 		SinceSeconds: sinceSecondsPtr,
 		SinceTime:    sinceTimePtr,
+		TailLines:    tailLinesPtr,
 	}
 
-	return readPodLogsWithOpts(ctx, kubeCli, pods, opts, &cutoffTime)
+	return readPodLogsWithOpts(ctx, kubeCli, pods, opts, &o.filter, &cutoffTime)
 }
 
-func readRealtimeLogsFromPods(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod, cutoffTime time.Time) []*podLogSource {
+func (o *debugLogsOpts) readRealtimeLogsFromPods(ctx context.Context, kubeCli *envapi.KubeClient, pods []corev1.Pod, cutoffTime time.Time) []*podLogSource {
 	// Log options for realtime entries.
 	opts := &corev1.PodLogOptions{
 		Follow:     true,
-		Container:  metaplayServerContainerName,
+		Container:  o.flagContainer,
 		SinceTime:  &metav1.Time{Time: cutoffTime},
 		Timestamps: true,
 	}
 
 	// Read the logs from the pods.
-	return readPodLogsWithOpts(ctx, kubeCli, pods, opts, nil)
+	return readPodLogsWithOpts(ctx, kubeCli, pods, opts, &o.filter, nil)
 }
 
 type podLogSource struct {
-	prefix  string        // Name of the source (eg, pod name).
-	request *rest.Request // REST request to read data from.
-	channel chan LogEntry // Channel of log entries.
+	podName string          // Name of the pod.
+	prefix  string          // Name of the source (eg, pod name).
+	request *rest.Request   // REST request to read data from.
+	channel chan LogEntry   // Channel of log entries.
+	filter  *logEntryFilter // Only entries matching the filter are output (nil for all).
 }
 
 type LogEntry struct {
 	timestamp time.Time
 	message   string
+	level     logLevel // Level of structured entries (logLevelNone for plain text entries).
+}
+
+// logEntryWriter outputs the aggregated log entries, and optionally writes them into a file.
+type logEntryWriter struct {
+	file       *os.File // File to also write the entries to (or nil).
+	numWritten int      // Number of entries written to the file.
+}
+
+func (w *logEntryWriter) write(source *podLogSource, entry LogEntry) {
+	log.Info().Msgf("%s%s", source.prefix, renderLogEntryMessage(entry))
+
+	if w.file != nil {
+		if _, err := fmt.Fprintf(w.file, "%s %s: %s\n", entry.timestamp.Format(time.RFC3339Nano), source.podName, entry.message); err != nil {
+			log.Error().Msgf("Failed to write to output file: %v", err)
+			w.file = nil
+			return
+		}
+		w.numWritten++
+	}
 }
 
 // readPodLogs reads log entries from the source, parses them (i.e., extracts the
@@ -310,10 +430,22 @@ func readPodLogs(ctx context.Context, source *podLogSource, cutoffTime *time.Tim
 			}
 		}
 
+		// Parse structured (JSON) entries.
+		structured := parseStructuredLogLine(msg)
+
+		// Skip entries not matching the filter.
+		if source.filter != nil && !source.filter.matches(msg, structured) {
+			continue
+		}
+
 		entry := LogEntry{
 			timestamp: timestamp,
 			message:   msg,
 		}
+		if structured != nil {
+			entry.message = structured.render()
+			entry.level = structured.level
+		}
 
 		// Send entry to aggregator (or bail out if operation canceled).
 		select {
@@ -351,7 +483,7 @@ func (h *logEntryHeap) Pop() any {
 }
 
 // aggregateHistoricalLogsInTimeOrder merges multiple channels of LogEntry in ascending timestamp order.
-func aggregateHistoricalLogsInTimeOrder(sources []*podLogSource) {
+func aggregateHistoricalLogsInTimeOrder(sources []*podLogSource, writer *logEntryWriter) {
 	// Initialize a min-heap for log entries
 	var pq logEntryHeap
 	heap.Init(&pq)
@@ -377,7 +509,7 @@ func aggregateHistoricalLogsInTimeOrder(sources []*podLogSource) {
 		entrySource := sources[earliest.sourceNdx]
 
 		// Output (or process) the earliest entry
-		log.Info().Msgf("%s%s", entrySource.prefix, earliest.entry.message)
+		writer.write(entrySource, earliest.entry)
 
 		// Read the next entry from the same channel (block until value is available or channel is closed)
 		nextEntry, ok := <-entrySource.channel
@@ -402,7 +534,7 @@ func aggregateHistoricalLogsInTimeOrder(sources []*podLogSource) {
 // \todo Optimize the memory usage by not fetching only one entry per source at a time.
 // This also fixes a potential misordering of entries if they have the same timestamp
 // (heap provides no guarantees of stable ordering of items with identical priority).
-func aggregateRealtimeSourcesInTimeOrder(sources []*podLogSource) {
+func aggregateRealtimeSourcesInTimeOrder(sources []*podLogSource, writer *logEntryWriter) {
 	// Initialize a min-heap for log entries
 	var pq logEntryHeap
 	heap.Init(&pq)
@@ -460,7 +592,7 @@ func aggregateRealtimeSourcesInTimeOrder(sources []*podLogSource) {
 			oldest := pq[0] // peek at the earliest event
 			if oldest.entry.timestamp.Before(cutoff) {
 				popped := heap.Pop(&pq).(entryWithSource)
-				log.Info().Msgf("%s%s", sources[popped.sourceNdx].prefix, popped.entry.message)
+				writer.write(sources[popped.sourceNdx], popped.entry)
 			} else {
 				// The earliest event is still within the 1-second window,
 				// so we wait for the next iteration in case something older arrives.
@@ -0,0 +1,192 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/metaplay/cli/pkg/styles"
+)
+
+// Severity level of a structured log entry.
+type logLevel int
+
+const (
+	logLevelNone logLevel = iota // Unknown level (or no level filter).
+	logLevelVerbose
+	logLevelDebug
+	logLevelInformation
+	logLevelWarning
+	logLevelError
+	logLevelFatal
+)
+
+// Short names of the log levels, used when rendering structured log entries.
+var logLevelShortNames = map[logLevel]string{
+	logLevelVerbose:     "VRB",
+	logLevelDebug:       "DBG",
+	logLevelInformation: "INF",
+	logLevelWarning:     "WRN",
+	logLevelError:       "ERR",
+	logLevelFatal:       "FTL",
+}
+
+// parseLogLevel parses a log level name. Both the full names (eg, 'Warning') and the
+// common abbreviations (eg, 'warn' or 'WRN') are accepted, case-insensitively.
+func parseLogLevel(name string) (logLevel, error) {
+	switch strings.ToLower(name) {
+	case "verbose", "trace", "vrb":
+		return logLevelVerbose, nil
+	case "debug", "dbg":
+		return logLevelDebug, nil
+	case "information", "info", "inf":
+		return logLevelInformation, nil
+	case "warning", "warn", "wrn":
+		return logLevelWarning, nil
+	case "error", "err":
+		return logLevelError, nil
+	case "fatal", "critical", "ftl":
+		return logLevelFatal, nil
+	default:
+		return logLevelNone, fmt.Errorf("unknown log level '%s'", name)
+	}
+}
+
+// structuredLogLine is a log line emitted by the game server in JSON format.
+type structuredLogLine struct {
+	level     logLevel
+	source    string // Source of the log line, eg, 'PlayerActor' (can be empty).
+	message   string
+	exception string // Exception details (can be empty).
+}
+
+// Keys in the JSON log lines for each of the fields, in order of priority. Both the
+// Serilog compact format ('@l', '@m', ...) and common plain key names are supported.
+var (
+	structuredLogLevelKeys     = []string{"@l", "level", "Level", "severity"}
+	structuredLogSourceKeys    = []string{"SourceContext", "source", "Source", "logger"}
+	structuredLogMessageKeys   = []string{"@m", "message", "Message", "msg", "@mt"}
+	structuredLogExceptionKeys = []string{"@x", "exception", "Exception"}
+)
+
+// parseStructuredLogLine parses a JSON log line. Returns nil if the line is not a JSON
+// object with a message in it.
+func parseStructuredLogLine(line string) *structuredLogLine {
+	if !strings.HasPrefix(line, "{") {
+		return nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil
+	}
+
+	message, ok := lookupLogField(fields, structuredLogMessageKeys)
+	if !ok {
+		return nil
+	}
+
+	// Serilog's compact format omits the level for informational entries.
+	level := logLevelInformation
+	if levelName, ok := lookupLogField(fields, structuredLogLevelKeys); ok {
+		if parsed, err := parseLogLevel(levelName); err == nil {
+			level = parsed
+		}
+	}
+
+	source, _ := lookupLogField(fields, structuredLogSourceKeys)
+	exception, _ := lookupLogField(fields, structuredLogExceptionKeys)
+
+	return &structuredLogLine{
+		level:     level,
+		source:    source,
+		message:   message,
+		exception: exception,
+	}
+}
+
+// lookupLogField returns the value of the first of the keys found in the fields.
+// Non-string values are converted to strings.
+func lookupLogField(fields map[string]any, keys []string) (string, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok && value != nil {
+			if str, ok := value.(string); ok {
+				return str, true
+			}
+			return fmt.Sprint(value), true
+		}
+	}
+	return "", false
+}
+
+// render formats the structured log line for human consumption, eg,
+// "WRN [PlayerActor] Player session timed out".
+func (line *structuredLogLine) render() string {
+	var sb strings.Builder
+	sb.WriteString(logLevelShortNames[line.level])
+	if line.source != "" {
+		sb.WriteString(" [")
+		sb.WriteString(line.source)
+		sb.WriteString("]")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(line.message)
+	if line.exception != "" {
+		sb.WriteString("\n")
+		sb.WriteString(line.exception)
+	}
+	return sb.String()
+}
+
+// logEntryFilter decides which log entries are shown.
+type logEntryFilter struct {
+	grep     *regexp.Regexp // Only show lines matching the regular expression (nil for all).
+	minLevel logLevel       // Only show structured entries with at least this level (logLevelNone for all).
+	source   string         // Only show structured entries whose source contains this (empty for all).
+}
+
+// requiresStructured returns true if only structured log entries can match the filter.
+func (filter *logEntryFilter) requiresStructured() bool {
+	return filter.minLevel != logLevelNone || filter.source != ""
+}
+
+// matches returns true if the log entry passes the filter. The grep is matched against the
+// raw line as emitted by the server.
+func (filter *logEntryFilter) matches(rawLine string, structured *structuredLogLine) bool {
+	if filter.grep != nil && !filter.grep.MatchString(rawLine) {
+		return false
+	}
+
+	if filter.requiresStructured() {
+		if structured == nil {
+			return false
+		}
+		if structured.level < filter.minLevel {
+			return false
+		}
+		if filter.source != "" && !strings.Contains(strings.ToLower(structured.source), strings.ToLower(filter.source)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// renderLogEntryMessage colors the log entry message based on its level.
+func renderLogEntryMessage(entry LogEntry) string {
+	switch entry.level {
+	case logLevelVerbose, logLevelDebug:
+		return styles.RenderMuted(entry.message)
+	case logLevelWarning:
+		return styles.RenderWarning(entry.message)
+	case logLevelError, logLevelFatal:
+		return styles.RenderError(entry.message)
+	default:
+		return entry.message
+	}
+}
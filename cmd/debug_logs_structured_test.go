@@ -0,0 +1,85 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := parseLogLevel("warn")
+	require.NoError(t, err)
+	assert.Equal(t, logLevelWarning, level)
+
+	level, err = parseLogLevel("Information")
+	require.NoError(t, err)
+	assert.Equal(t, logLevelInformation, level)
+
+	level, err = parseLogLevel("ERR")
+	require.NoError(t, err)
+	assert.Equal(t, logLevelError, level)
+
+	_, err = parseLogLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestParseStructuredLogLine(t *testing.T) {
+	// Serilog compact format.
+	line := parseStructuredLogLine(`{"@t":"2025-01-02T03:04:05.678Z","@m":"Session timed out","@l":"Warning","SourceContext":"PlayerActor"}`)
+	require.NotNil(t, line)
+	assert.Equal(t, logLevelWarning, line.level)
+	assert.Equal(t, "PlayerActor", line.source)
+	assert.Equal(t, "WRN [PlayerActor] Session timed out", line.render())
+
+	// Level is omitted for informational entries.
+	line = parseStructuredLogLine(`{"@t":"2025-01-02T03:04:05.678Z","@m":"Server started"}`)
+	require.NotNil(t, line)
+	assert.Equal(t, logLevelInformation, line.level)
+	assert.Equal(t, "INF Server started", line.render())
+
+	// Plain key names with an exception.
+	line = parseStructuredLogLine(`{"level":"error","source":"GuildActor","message":"Failed to persist","exception":"System.Exception: boom"}`)
+	require.NotNil(t, line)
+	assert.Equal(t, logLevelError, line.level)
+	assert.Equal(t, "ERR [GuildActor] Failed to persist\nSystem.Exception: boom", line.render())
+
+	// Not structured.
+	assert.Nil(t, parseStructuredLogLine("[03:04:05.678 INF] Server started"))
+	assert.Nil(t, parseStructuredLogLine(`{"unrelated":true}`))
+	assert.Nil(t, parseStructuredLogLine(`{malformed`))
+}
+
+func TestLogEntryFilter(t *testing.T) {
+	warning := parseStructuredLogLine(`{"@m":"Session timed out","@l":"Warning","SourceContext":"Metaplay.Server.PlayerActor"}`)
+	info := parseStructuredLogLine(`{"@m":"Player logged in","SourceContext":"Metaplay.Server.PlayerActor"}`)
+	guildError := parseStructuredLogLine(`{"@m":"Failed to persist","@l":"Error","SourceContext":"GuildActor"}`)
+
+	// Empty filter matches everything.
+	filter := logEntryFilter{}
+	assert.True(t, filter.matches("plain text", nil))
+	assert.True(t, filter.matches("", info))
+
+	// Level filter.
+	filter = logEntryFilter{minLevel: logLevelWarning}
+	assert.True(t, filter.matches("", warning))
+	assert.True(t, filter.matches("", guildError))
+	assert.False(t, filter.matches("", info))
+	assert.False(t, filter.matches("plain text", nil))
+
+	// Source filter (case-insensitive substring).
+	filter = logEntryFilter{source: "playeractor"}
+	assert.True(t, filter.matches("", warning))
+	assert.True(t, filter.matches("", info))
+	assert.False(t, filter.matches("", guildError))
+
+	// Grep is matched against the raw line.
+	filter = logEntryFilter{grep: regexp.MustCompile("timed out"), minLevel: logLevelWarning}
+	assert.True(t, filter.matches(`{"@m":"Session timed out"}`, warning))
+	assert.False(t, filter.matches(`{"@m":"Failed to persist"}`, guildError))
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugLogsPrepare(t *testing.T) {
+	o := debugLogsOpts{flagTail: -1, flagGrep: "Error|Exception"}
+	require.NoError(t, o.Prepare(nil, nil))
+	require.NotNil(t, o.filter.grep)
+	assert.True(t, o.filter.grep.MatchString("Unhandled Exception in actor"))
+	assert.False(t, o.filter.grep.MatchString("Player logged in"))
+
+	o = debugLogsOpts{flagTail: -1, flagGrep: "[unclosed"}
+	assert.Error(t, o.Prepare(nil, nil))
+
+	o = debugLogsOpts{flagTail: -2}
+	assert.Error(t, o.Prepare(nil, nil))
+}
+
+func TestDebugLogsPrepareStructuredFilters(t *testing.T) {
+	o := debugLogsOpts{flagTail: -1, flagLevel: "warn", flagSource: "PlayerActor"}
+	require.NoError(t, o.Prepare(nil, nil))
+	assert.Equal(t, logLevelWarning, o.filter.minLevel)
+	assert.Equal(t, "PlayerActor", o.filter.source)
+
+	o = debugLogsOpts{flagTail: -1, flagLevel: "loud"}
+	assert.Error(t, o.Prepare(nil, nil))
+}
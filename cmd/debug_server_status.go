@@ -38,6 +38,8 @@ func init() {
 
 			Runs the same checks as 'deploy server' but without deploying anything:
 			- All expected pods are present, healthy, and ready.
+			- NetworkPolicies allow client and admin traffic, and PodDisruptionBudgets allow node
+			  maintenance (only reported as warnings).
 			- Client-facing domain name resolves correctly.
 			- Game server responds to client traffic.
 			- Admin domain name resolves correctly.
@@ -80,19 +82,6 @@ func (o *debugCheckServerStatus) Run(cmd *cobra.Command) error {
 	// Create TargetEnvironment.
 	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
 
-	// Get environment details.
-	envDetails, err := targetEnv.GetDetails()
-	if err != nil {
-		return err
-	}
-
-	// Get docker credentials.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
-	if err != nil {
-		return fmt.Errorf("failed to get docker credentials: %v", err)
-	}
-	log.Debug().Msgf("Got docker credentials: username=%s", dockerCredentials.Username)
-
 	// Create a Kubernetes client.
 	kubeCli, err := targetEnv.GetPrimaryKubeClient()
 	if err != nil {
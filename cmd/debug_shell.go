@@ -6,6 +6,10 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
@@ -28,9 +32,10 @@ type debugShellOpts struct {
 	// Environment and pod selection
 	Environment string
 	PodName     string
+	flagPodName string // Pod name given with --pod (alternative to the POD argument).
 
 	// Container options
-	ContainerName string
+	ContainerName string // Container to target (empty to choose interactively).
 	Image         string
 	Command       []string
 	Interactive   bool
@@ -38,10 +43,9 @@ type debugShellOpts struct {
 
 func init() {
 	o := debugShellOpts{
-		ContainerName: metaplayServerContainerName,
-		Image:         "metaplay/diagnostics:latest",
-		Command:       []string{"/bin/bash", "--rcfile", "/entrypoint.sh"},
-		Interactive:   true,
+		Image:       "metaplay/diagnostics:latest",
+		Command:     []string{"/bin/bash", "--rcfile", "/entrypoint.sh"},
+		Interactive: true,
 	}
 
 	args := o.Arguments()
@@ -58,13 +62,17 @@ func init() {
 			This command creates a Kubernetes ephemeral debug container that attaches to an existing
 			game server pod, allowing you to inspect and troubleshoot the running server.
 
-			If multiple game server pods are running in the environment, you must specify which pod
-			to debug by providing its name as the second argument. If only one pod is running,
-			the pod name is optional.
+			If multiple game server pods are running in the environment, you are asked to choose the
+			pod from a list showing the phase and age of each pod. If the pod has multiple containers,
+			or debug containers from earlier sessions are still running in it, you are also asked
+			to choose the container. Use the POD argument (or --pod) and --container to skip the
+			questions, e.g., in non-interactive use.
 
 			The debug container uses the metaplay/diagnostics:latest image which contains various
-			debugging and diagnostic tools. The container is attached to the shard-server container
-			within the pod, giving you direct access to the game server process.
+			debugging and diagnostic tools. By default, the container targets the shard-server
+			container within the pod, giving you direct access to the game server process. When
+			choosing an already-running debug container, the shell attaches to it instead of
+			starting a new one.
 
 			{Arguments}
 		`),
@@ -74,14 +82,29 @@ func init() {
 
 			# Start a debug container in the 'nimbly' environment, targeting pod 'service-0'.
 			metaplay debug shell nimbly service-0
+
+			# Same as above, but without any interactive questions.
+			metaplay debug shell nimbly --pod=service-0 --container=shard-server
 		`),
 	}
 
 	debugCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagPodName, "pod", "", "Name of the pod to debug (alternative to the POD argument)")
+	flags.StringVar(&o.ContainerName, "container", "", "Name of the container to target, or of a running debug container to attach to (default: choose interactively, or shard-server)")
 }
 
 // Complete finishes parsing arguments for the command
 func (o *debugShellOpts) Prepare(cmd *cobra.Command, args []string) error {
+	// The pod can be given either as an argument or with --pod.
+	if o.flagPodName != "" {
+		if o.PodName != "" && o.PodName != o.flagPodName {
+			return clierrors.NewUsageErrorf("Conflicting pod names '%s' (argument) and '%s' (--pod)", o.PodName, o.flagPodName).
+				WithSuggestion("Specify the pod either as an argument or with --pod, not both")
+		}
+		o.PodName = o.flagPodName
+	}
 	return nil
 }
 
@@ -112,12 +135,24 @@ func (o *debugShellOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	// Create and attach to debug container
-	debugContainerName, cleanup, err := kubeutil.CreateDebugContainer(cmd.Context(), kubeCli, pod.Name, o.ContainerName, true, true, o.Command)
+	// Resolve target container (or ask for it if not defined).
+	target, err := resolveDebugShellTarget(pod, o.ContainerName)
 	if err != nil {
 		return err
 	}
-	defer cleanup()
+
+	// Create the debug container, unless attaching to an already-running one.
+	debugContainerName := target.name
+	if !target.isDebugContainer {
+		var cleanup func()
+		debugContainerName, cleanup, err = kubeutil.CreateDebugContainer(cmd.Context(), kubeCli, pod.Name, target.name, true, true, o.Command)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else {
+		log.Info().Msgf("Attaching to running debug container %s", styles.RenderTechnical(debugContainerName))
+	}
 
 	// Setup IO streams using mobyterm.StdStreams() for proper terminal handling.
 	// On Windows, this handles Virtual Terminal Input mode detection and falls back
@@ -232,3 +267,92 @@ func chooseTargetShardAndPodDialog(shardSetsWithPods []envapi.ShardSetWithPods)
 
 	return selectedPodWithContext.shardSet.ShardSet.Cluster.KubeClient, selectedPodWithContext.pod, nil
 }
+
+// debugShellTarget is a container in a pod that a debug shell can target.
+type debugShellTarget struct {
+	name             string // Name of the container.
+	isDebugContainer bool   // Is this a running ephemeral debug container (attach to it directly)?
+	description      string // Description shown in the container picker.
+}
+
+// getDebugShellTargets returns the containers of the pod that a debug shell can target: the
+// pod's regular containers and its running ephemeral debug containers.
+func getDebugShellTargets(pod *corev1.Pod) []debugShellTarget {
+	targets := []debugShellTarget{}
+	for _, container := range pod.Spec.Containers {
+		targets = append(targets, debugShellTarget{
+			name:        container.Name,
+			description: "[start a new debug container]",
+		})
+	}
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.State.Running == nil {
+			continue
+		}
+		targets = append(targets, debugShellTarget{
+			name:             status.Name,
+			isDebugContainer: true,
+			description:      fmt.Sprintf("[running debug container, started %s]", humanize.Time(status.State.Running.StartedAt.Time)),
+		})
+	}
+	return targets
+}
+
+// resolveDebugShellTarget resolves the container to target in the pod. If containerName is
+// empty and there are multiple candidates, the user is asked to choose (or shard-server is
+// used in non-interactive mode).
+func resolveDebugShellTarget(pod *corev1.Pod, containerName string) (*debugShellTarget, error) {
+	targets := getDebugShellTargets(pod)
+	targetNames := make([]string, len(targets))
+	for ndx, target := range targets {
+		targetNames[ndx] = target.name
+	}
+
+	// Find the explicitly specified container.
+	if containerName != "" {
+		for ndx := range targets {
+			if targets[ndx].name == containerName {
+				return &targets[ndx], nil
+			}
+		}
+		return nil, clierrors.Newf("Container '%s' not found in pod %s", containerName, pod.Name).
+			WithSuggestion(fmt.Sprintf("Available containers: %s", strings.Join(targetNames, ", ")))
+	}
+
+	if len(targets) == 0 {
+		return nil, clierrors.Newf("No containers found in pod %s", pod.Name)
+	}
+
+	// With only one candidate, use it.
+	if len(targets) == 1 {
+		return &targets[0], nil
+	}
+
+	// In non-interactive mode, default to the game server container.
+	if !tui.IsInteractiveMode() {
+		for ndx := range targets {
+			if targets[ndx].name == metaplayServerContainerName && !targets[ndx].isDebugContainer {
+				return &targets[ndx], nil
+			}
+		}
+		return nil, clierrors.NewUsageErrorf("Multiple containers found in pod %s", pod.Name).
+			WithSuggestion(fmt.Sprintf("Specify the container with --container, one of: %s", strings.Join(targetNames, ", ")))
+	}
+
+	// Let the user choose the container.
+	selected, err := tui.ChooseFromListDialog(
+		"Select Target Container",
+		targets,
+		func(target *debugShellTarget) (string, string) {
+			return target.name, target.description
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Msgf(" %s %s", styles.RenderSuccess("✓"), selected.name)
+	log.Info().Msg("")
+
+	return selected, nil
+}
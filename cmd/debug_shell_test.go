@@ -0,0 +1,70 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createDebugShellTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "service-0"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "shard-server"}, {Name: "metrics-sidecar"}},
+		},
+		Status: corev1.PodStatus{
+			EphemeralContainerStatuses: []corev1.ContainerStatus{
+				{Name: "debugger-abcd", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.Now()}}},
+				{Name: "debugger-dead", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+			},
+		},
+	}
+}
+
+func TestGetDebugShellTargets(t *testing.T) {
+	targets := getDebugShellTargets(createDebugShellTestPod())
+	require.Len(t, targets, 3)
+	assert.Equal(t, "shard-server", targets[0].name)
+	assert.False(t, targets[0].isDebugContainer)
+	assert.Equal(t, "metrics-sidecar", targets[1].name)
+	assert.Equal(t, "debugger-abcd", targets[2].name)
+	assert.True(t, targets[2].isDebugContainer)
+}
+
+func TestResolveDebugShellTarget(t *testing.T) {
+	pod := createDebugShellTestPod()
+
+	// Explicitly specified containers.
+	target, err := resolveDebugShellTarget(pod, "debugger-abcd")
+	require.NoError(t, err)
+	assert.True(t, target.isDebugContainer)
+
+	_, err = resolveDebugShellTarget(pod, "debugger-dead")
+	assert.Error(t, err)
+
+	// Non-interactive mode defaults to the game server container.
+	wasInteractive := tui.IsInteractiveMode()
+	tui.SetInteractiveMode(false)
+	defer tui.SetInteractiveMode(wasInteractive)
+	target, err = resolveDebugShellTarget(pod, "")
+	require.NoError(t, err)
+	assert.Equal(t, "shard-server", target.name)
+	assert.False(t, target.isDebugContainer)
+}
+
+func TestDebugShellPreparePodFlag(t *testing.T) {
+	o := debugShellOpts{flagPodName: "service-0"}
+	require.NoError(t, o.Prepare(nil, nil))
+	assert.Equal(t, "service-0", o.PodName)
+
+	o = debugShellOpts{PodName: "service-1", flagPodName: "service-0"}
+	assert.Error(t, o.Prepare(nil, nil))
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Attach to an in-flight game server deployment and wait for it to become ready.
+type deployAttachOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	argDeployID    string
+}
+
+func init() {
+	o := deployAttachOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argDeployID, "DEPLOY_ID", "Optional: Deploy ID printed by 'metaplay deploy server --detach', eg, 'lovely-wombats-build-nimbly-gameserver.v12'.")
+
+	cmd := &cobra.Command{
+		Use:   "attach ENVIRONMENT [DEPLOY_ID] [flags]",
+		Short: "Wait for an in-flight game server deployment to become ready",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Attach to a game server deployment started with 'metaplay deploy server --detach' and
+			wait for it to become ready, running the same checks as 'metaplay deploy server' does
+			after a deployment.
+
+			If DEPLOY_ID is given, the command checks that it's still the latest deployment in the
+			environment and fails if a newer deployment has superseded it. Without DEPLOY_ID, the
+			latest deployment is waited for.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy server ... --detach' to start a deployment without waiting for it.
+			- 'metaplay deploy status ...' to show the current state of the deployment.
+		`),
+		Example: renderExample(`
+			# Wait for the deployment printed by 'metaplay deploy server nimbly 364cff09 --detach'.
+			metaplay deploy attach nimbly lovely-wombats-build-nimbly-gameserver.v12
+
+			# Wait for the latest deployment in environment nimbly.
+			metaplay deploy attach nimbly
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+}
+
+func (o *deployAttachOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.argDeployID != "" {
+		if _, _, err := parseDeployID(o.argDeployID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *deployAttachOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Create a Kubernetes client.
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+
+	// Find the game server release.
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+	if existingRelease == nil {
+		return clierrors.New("No game server deployment found in the environment").
+			WithSuggestion(fmt.Sprintf("Deploy a game server with 'metaplay deploy server %s'", o.argEnvironment))
+	}
+
+	// Check that the deployment is still the latest one and hasn't failed.
+	if err := checkAttachableRelease(existingRelease, o.argDeployID); err != nil {
+		return err
+	}
+
+	deployID := formatDeployID(existingRelease)
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Attach to Game Server Deployment"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment:  %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("Deploy ID:    %s", styles.RenderTechnical(deployID))
+	log.Info().Msgf("Image tag:    %s", styles.RenderTechnical(coalesceString(getReleaseImageTag(existingRelease), "<not available>")))
+	log.Info().Msg("")
+
+	// Wait for the game server to be ready.
+	taskRunner := tui.NewTaskRunner()
+	if err := targetEnv.WaitForServerToBeReady(cmd.Context(), taskRunner); err != nil {
+		return err
+	}
+	if err := taskRunner.Run(); err != nil {
+		return err
+	}
+
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Game server deployment %s is ready!", deployID)))
+	return nil
+}
+
+// formatDeployID returns the ID of the deployment of a Helm release revision, in the same
+// format as the revision's Helm secret suffix, eg, 'mygame-gameserver.v12'.
+func formatDeployID(rel *release.Release) string {
+	return fmt.Sprintf("%s.v%d", rel.Name, rel.Version)
+}
+
+// parseDeployID parses a deploy ID returned by formatDeployID into the Helm release name
+// and revision.
+func parseDeployID(deployID string) (string, int, error) {
+	ndx := strings.LastIndex(deployID, ".v")
+	if ndx > 0 {
+		revision, err := strconv.Atoi(deployID[ndx+2:])
+		if err == nil && revision > 0 {
+			return deployID[:ndx], revision, nil
+		}
+	}
+	return "", 0, clierrors.NewUsageErrorf("Invalid deploy ID '%s'", deployID).
+		WithSuggestion("Use the deploy ID printed by 'metaplay deploy server --detach', eg, 'mygame-gameserver.v12'")
+}
+
+// checkAttachableRelease checks that the release can be attached to: it matches the deploy ID
+// (if given) and has not failed.
+func checkAttachableRelease(rel *release.Release, deployID string) error {
+	if deployID != "" {
+		releaseName, revision, err := parseDeployID(deployID)
+		if err != nil {
+			return err
+		}
+		if releaseName != rel.Name {
+			return clierrors.Newf("Deployment %s not found: the game server release in the environment is '%s'", deployID, rel.Name)
+		}
+		if revision < rel.Version {
+			return clierrors.Newf("Deployment %s has been superseded by a newer deployment %s", deployID, formatDeployID(rel)).
+				WithSuggestion("Run 'metaplay deploy attach' without the deploy ID to wait for the latest deployment")
+		}
+		if revision > rel.Version {
+			return clierrors.Newf("Deployment %s not found: the latest deployment is %s", deployID, formatDeployID(rel))
+		}
+	}
+
+	if rel.Info != nil && rel.Info.Status == release.StatusFailed {
+		return clierrors.Newf("Deployment %s has failed", formatDeployID(rel)).
+			WithDetails(rel.Info.Description).
+			WithSuggestion("Check the deployment with 'metaplay deploy status' and redeploy with 'metaplay deploy server'")
+	}
+
+	return nil
+}
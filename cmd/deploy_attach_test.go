@@ -0,0 +1,46 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestDeployID(t *testing.T) {
+	rel := &release.Release{Name: "lovely-wombats-build-nimbly-gameserver", Version: 12}
+	deployID := formatDeployID(rel)
+	assert.Equal(t, "lovely-wombats-build-nimbly-gameserver.v12", deployID)
+
+	releaseName, revision, err := parseDeployID(deployID)
+	require.NoError(t, err)
+	assert.Equal(t, rel.Name, releaseName)
+	assert.Equal(t, 12, revision)
+
+	for _, invalid := range []string{"", "gameserver", "gameserver.v", "gameserver.vX", ".v12", "gameserver.v0"} {
+		_, _, err := parseDeployID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCheckAttachableRelease(t *testing.T) {
+	rel := &release.Release{
+		Name:    "nimbly-gameserver",
+		Version: 12,
+		Info:    &release.Info{Status: release.StatusDeployed},
+	}
+
+	assert.NoError(t, checkAttachableRelease(rel, ""))
+	assert.NoError(t, checkAttachableRelease(rel, "nimbly-gameserver.v12"))
+	assert.ErrorContains(t, checkAttachableRelease(rel, "nimbly-gameserver.v11"), "superseded")
+	assert.ErrorContains(t, checkAttachableRelease(rel, "nimbly-gameserver.v13"), "not found")
+	assert.ErrorContains(t, checkAttachableRelease(rel, "other-gameserver.v12"), "not found")
+
+	rel.Info.Status = release.StatusFailed
+	assert.ErrorContains(t, checkAttachableRelease(rel, "nimbly-gameserver.v12"), "failed")
+}
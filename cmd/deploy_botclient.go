@@ -5,22 +5,25 @@
 package cmd
 
 // \todo More configurability: number of replicas, number of bots, etc.
-// \todo Add checks that the deployment/pods are running as expected
 
 import (
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/hashicorp/go-version"
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
 	"github.com/metaplay/cli/pkg/envapi"
 	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/portalapi"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -30,20 +33,20 @@ const metaplayLoadTestChartName = "metaplay-loadtest"
 // Earlier versions include the trailing dot in the SNI request which fails with Traefik.
 var minSdkVersionSniHostname = version.Must(version.NewVersion("37.0.0"))
 
-// const metaplayBotClientPodLabelSelector = "app=botclient"
-
 // Deploy bots to the target environment with specified docker image version.
 type deployBotClientOpts struct {
 	UsePositionalArgs
 
 	argEnvironment          string
-	argImageTag             string
+	argImageNameTag         string
 	extraArgs               []string
 	flagHelmReleaseName     string
 	flagHelmChartLocalPath  string
 	flagHelmChartRepository string
 	flagHelmChartVersion    string
 	flagHelmValuesPath      string
+	flagPostRenderer        string
+	flagDryRun              bool
 }
 
 func init() {
@@ -51,11 +54,11 @@ func init() {
 
 	args := o.Arguments()
 	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
-	args.AddStringArgument(&o.argImageTag, "IMAGE_TAG", "Docker image name and tag, eg, '364cff09'.")
+	args.AddStringArgumentOpt(&o.argImageNameTag, "[IMAGE:]TAG", "Docker image name and tag, eg, 'mygame:364cff09' or '364cff09'.")
 	args.SetExtraArgs(&o.extraArgs, "Passed as-is to Helm.")
 
 	cmd := &cobra.Command{
-		Use:     "botclient [ENVIRONMENT] [IMAGE_TAG] [flags] [-- EXTRA_ARGS]",
+		Use:     "botclient [ENVIRONMENT] [IMAGE:]TAG [flags] [-- EXTRA_ARGS]",
 		Aliases: []string{"bots", "botclients"},
 		Short:   "[preview] Deploy load testing bots into the target environment",
 		Run:     runCommand(&o),
@@ -64,7 +67,20 @@ func init() {
 			key functionality.
 
 			Deploy bots into the target cloud environment using the specified docker image version.
-			The image must exist in the target environment image repository.
+
+			After deploying the bots, the bot client pods are checked to be present, healthy, and ready.
+
+			When a full docker image tag is specified (eg, 'mygame:364cff09'), the image is first
+			pushed to the environment's registry. If only a tag is specified (eg, '364cff09'), the
+			image is assumed to be present in the remote registry already.
+
+			A Helm post-renderer can be used to customize the rendered Kubernetes manifests before
+			they are applied, eg, to inject sidecars or labels not exposed by the Helm chart. The
+			post-renderer is either an executable (receives the manifests on stdin and writes the
+			modified manifests to stdout) or a kustomize overlay directory. For overlays, the rendered
+			manifests are provided in the file 'all.yaml', which the overlay's kustomization.yaml must
+			list in its resources. The post-renderer can be configured per environment using the
+			'botclientPostRenderer' field in metaplay-project.yaml or overridden with --post-renderer.
 
 			{Arguments}
 
@@ -77,6 +93,18 @@ func init() {
 		Example: renderExample(`
 			# Deploy bots into environment nimbly with the docker image tag 364cff09.
 			metaplay deploy botclient nimbly 364cff09
+
+			# Push the local image and deploy the bots using it.
+			metaplay deploy botclient nimbly mygame:364cff09
+
+			# Deploy bots using the latest locally built image for this project.
+			metaplay deploy botclient nimbly latest-local
+
+			# Pass extra arguments to Helm.
+			metaplay deploy botclient nimbly 364cff09 -- --set botclients.maxBotId=5000
+
+			# Apply a post-renderer on the rendered Kubernetes manifests.
+			metaplay deploy botclient nimbly 364cff09 --post-renderer=./add-sidecar.sh
 		`),
 	}
 	deployCmd.AddCommand(cmd)
@@ -84,20 +112,18 @@ func init() {
 	flags := cmd.Flags()
 	flags.StringVar(&o.flagHelmReleaseName, "helm-release-name", "", "Helm release name to use for the bot deployment (defaults to '<environmentID>-loadtest'")
 	flags.StringVar(&o.flagHelmChartLocalPath, "local-chart-path", "", "Path to a local version of the metaplay-loadtest chart (repository and version are ignored if this is set)")
-	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository to use for the metaplay-loadtest chart")
-	flags.StringVar(&o.flagHelmChartVersion, "helm-chart-version", "", "Override for Helm chart version to use, eg, '0.4.2'")
+	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository or OCI registry (oci://...) to use for the metaplay-loadtest chart")
+	flags.StringVar(&o.flagHelmChartVersion, "helm-chart-version", "", "Override for Helm chart version or version range to use, eg, '0.4.2' or '>=0.4.0 <0.5.0'")
 	flags.StringVarP(&o.flagHelmValuesPath, "values", "f", "", "Override for path to the Helm values file, e.g., 'Backend/Deployments/develop-botclients.yaml'")
+	flags.StringVar(&o.flagPostRenderer, "post-renderer", "", "Override for Helm post-renderer: path to an executable or a kustomize overlay directory")
+	flags.BoolVar(&o.flagDryRun, "dry-run", false, "Show what would be deployed without actually performing the deployment")
 }
 
 func (o *deployBotClientOpts) Prepare(cmd *cobra.Command, args []string) error {
-	// Validate image tag.
-	if o.argImageTag == "" {
-		log.Panic().Msgf("Positional argument IMAGE_TAG is empty")
+	if err := metaproj.ValidateHelmChartRepositoryURL(o.flagHelmChartRepository); err != nil {
+		return clierrors.WrapUsageError(err, "Invalid --helm-chart-repo").
+			WithSuggestion("Use an http(s) chart repository or an OCI registry, eg, 'oci://registry.example.com/charts'")
 	}
-	if strings.Contains(o.argImageTag, ":") {
-		return fmt.Errorf("IMAGE_TAG must contain only the tag (not the repository prefix), eg, '364cff092af8646bd'")
-	}
-
 	return nil
 }
 
@@ -114,15 +140,31 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	log.Info().Msg("")
-	log.Info().Msg(styles.RenderTitle("Deploy Bots to Cloud"))
-	log.Info().Msg("")
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDeploy); err != nil {
+		return err
+	}
 
 	// Create TargetEnvironment.
 	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
 
+	// Check that docker is installed and running (only needed for local images).
+	if o.argImageNameTag == "" || o.argImageNameTag == "latest-local" || strings.Contains(o.argImageNameTag, ":") {
+		log.Debug().Msgf("Check if docker is available")
+		err = checkDockerAvailable(cmd.Context())
+		if err != nil {
+			return err
+		}
+	}
+
 	// Validate Helm chart reference.
 	var chartVersionConstraints version.Constraints = nil
+	var chartVersionSpecifier string // Version or range of the chart, as specified by the user.
 	if o.flagHelmChartLocalPath != "" {
 		err = helmutil.ValidateLocalHelmChart(o.flagHelmChartLocalPath)
 		if err != nil {
@@ -135,16 +177,13 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 			helmChartVersion = o.flagHelmChartVersion
 		}
 
-		if helmChartVersion == "latest-prerelease" {
-			// Accept any version
-		} else {
-			// Parse Helm chart semver range.
-			chartVersionConstraints, err = version.NewConstraint(helmChartVersion)
-			if err != nil {
-				return fmt.Errorf("invalid Helm chart version: %v", err)
-			}
-			log.Debug().Msgf("Accepted Helm chart semver constraints: %v", chartVersionConstraints)
+		// Parse Helm chart version or semver range ('latest-prerelease' accepts any version).
+		chartVersionConstraints, err = metaproj.ParseHelmChartVersionConstraints(helmChartVersion)
+		if err != nil {
+			return fmt.Errorf("invalid Helm chart version '%s': %v", helmChartVersion, err)
 		}
+		log.Debug().Msgf("Accepted Helm chart semver constraints: %v", chartVersionConstraints)
+		chartVersionSpecifier = helmChartVersion
 	}
 
 	// Get environment details.
@@ -154,19 +193,18 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Get docker credentials to fetch image metadata.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return clierrors.Wrap(err, "Failed to get Docker credentials")
 	}
 
-	// Fetch SDK version from the remote docker image to determine actual SDK version being deployed.
-	remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, o.argImageTag)
-	imageInfo, err := envapi.FetchRemoteDockerImageMetadata(dockerCredentials, remoteImageName)
+	// Resolve the docker image to deploy (local or remote). The image metadata is needed
+	// to determine the actual SDK version being deployed.
+	image, err := resolveDeployImage(project, envDetails, dockerCredentials, o.argImageNameTag, true)
 	if err != nil {
-		return clierrors.Newf("Image '%s' not found in the environment's container registry", o.argImageTag).
-			WithSuggestion("Push the image first with 'metaplay image push'").
-			WithDetails(err.Error())
+		return err
 	}
+	imageInfo := image.info
 	log.Debug().Msgf("Image SDK version: %s", imageInfo.SdkVersion)
 
 	// Workaround: Strip trailing dot from server hostname for SDK versions before 37.0.0
@@ -187,36 +225,56 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 	// Resolve path to Helm chart (local or remote).
 	var helmChartPath string
 	var useHelmChartVersion string
+	var helmRegistryClient *registry.Client // Only for OCI chart repositories.
 	if o.flagHelmChartLocalPath != "" {
 		// Use local Helm chart directly.
 		helmChartPath = o.flagHelmChartLocalPath
 		useHelmChartVersion = "local"
 	} else {
 		// Determine the Helm chart repo and version to use.
-		helmChartRepo := coalesceString(project.Config.HelmChartRepository, o.flagHelmChartRepository, "https://charts.metaplay.dev")
+		helmChartRepo := coalesceString(project.Config.HelmChartRepository, o.flagHelmChartRepository, defaultHelmChartRepository)
+		helmRegistryClient, err = newHelmChartRegistryClient(helmChartRepo, dockerCredentials)
+		if err != nil {
+			return err
+		}
 		minChartVersion, _ := version.NewVersion("0.4.0")
-		useHelmChartVersion, err = helmutil.ResolveBestMatchingHelmVersion(helmChartRepo, metaplayLoadTestChartName, minChartVersion, chartVersionConstraints)
-		helmChartPath = helmutil.GetHelmChartPath(helmChartRepo, metaplayLoadTestChartName, useHelmChartVersion)
+		useHelmChartVersion, helmChartPath, err = resolveHelmChart(project, helmRegistryClient, helmChartRepo, metaplayLoadTestChartName, minChartVersion, chartVersionConstraints)
 		if err != nil {
 			return err
 		}
+		if !metaproj.IsExactHelmChartVersion(chartVersionSpecifier) {
+			log.Info().Msgf("Resolved Helm chart version %s to %s", styles.RenderTechnical(chartVersionSpecifier), styles.RenderTechnical(useHelmChartVersion))
+		}
 	}
 
-	// Resolve Helm values file path relative to current directory.
+	// Resolve Helm values file path relative to current directory (or use the --values override).
 	valuesFiles := project.GetBotClientValuesFiles(envConfig)
+	if o.flagHelmValuesPath != "" {
+		valuesFiles = []string{o.flagHelmValuesPath}
+	}
+
+	// Resolve the Helm post-renderer, if any (--post-renderer overrides the project config).
+	postRendererPath := coalesceString(o.flagPostRenderer, project.GetBotClientPostRenderer(envConfig))
+	var postRenderer postrender.PostRenderer
+	if postRendererPath != "" {
+		postRenderer, err = helmutil.NewPostRenderer(postRendererPath)
+		if err != nil {
+			return err
+		}
+	}
 
-	// Get kubeconfig to access the environment.
-	kubeconfigPayload, err := targetEnv.GetKubeConfigWithEmbeddedCredentials()
+	// Create a Kubernetes client.
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
 	if err != nil {
 		return err
 	}
-	log.Debug().Msgf("Resolved kubeconfig to access environment")
 
 	// Configure Helm.
-	actionConfig, err := helmutil.NewActionConfig(kubeconfigPayload, envConfig.GetKubernetesNamespace())
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
 	if err != nil {
 		return fmt.Errorf("failed to initialize Helm config: %v", err)
 	}
+	actionConfig.RegistryClient = helmRegistryClient
 
 	// Determine if there's an existing release deployed.
 	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayLoadTestChartName)
@@ -237,7 +295,7 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 			"botSessionDuration": "00:00:20",
 			"image": map[string]any{
 				"repository": envDetails.Deployment.EcrRepo,
-				"tag":        o.argImageTag,
+				"tag":        image.tag,
 			},
 			"targetHost":       serverHostname,
 			"targetTlsEnabled": true,
@@ -262,7 +320,7 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 		"botclients": map[string]any{
 			"image": map[string]any{
 				"repository": envDetails.Deployment.EcrRepo,
-				"tag":        o.argImageTag,
+				"tag":        image.tag,
 			},
 		},
 	}
@@ -282,16 +340,54 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 		}
 	}
 
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Deploy Bots to Cloud"))
+	log.Info().Msg("")
+
 	// Show info.
 	log.Info().Msg("Target environment:")
 	log.Info().Msgf("  Name:               %s", styles.RenderTechnical(envConfig.Name))
 	log.Info().Msgf("  ID:                 %s", styles.RenderTechnical(envConfig.HumanID))
 	log.Info().Msgf("  Type:               %s", styles.RenderTechnical(string(envConfig.Type)))
+	log.Info().Msgf("  Stack domain:       %s", styles.RenderTechnical(envConfig.StackDomain))
+	log.Info().Msg("")
 	log.Info().Msg("Build information:")
-	log.Info().Msgf("  Image tag:          %s", styles.RenderTechnical(o.argImageTag))
+	if image.isLocal {
+		log.Info().Msgf("  Image name:         %s", styles.RenderTechnical(image.nameTag))
+	} else {
+		log.Info().Msgf("  Image name:         %s", styles.RenderTechnical(fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, image.tag)))
+	}
+	log.Info().Msgf("  Build number:       %s", styles.RenderTechnical(imageInfo.BuildNumber))
+	log.Info().Msgf("  Commit ID:          %s", styles.RenderTechnical(imageInfo.CommitID))
+	log.Info().Msgf("  Created:            %s", styles.RenderTechnical(humanize.Time(imageInfo.CreatedTime)))
+	log.Info().Msgf("  Metaplay SDK:       %s", styles.RenderTechnical(imageInfo.SdkVersion))
+	log.Info().Msg("")
 	log.Info().Msgf("Deployment info:")
+	if o.flagHelmChartLocalPath != "" {
+		log.Info().Msgf("  Helm chart path:    %s", styles.RenderTechnical(helmChartPath))
+	} else {
+		log.Info().Msgf("  Helm chart version: %s", styles.RenderTechnical(useHelmChartVersion))
+	}
 	log.Info().Msgf("  Helm release name:  %s %s", styles.RenderTechnical(helmReleaseName), helmReleaseNameBadge)
 	log.Info().Msgf("  Helm values files:  %s", styles.RenderTechnical(coalesceString(strings.Join(valuesFiles, ", "), "none")))
+	if postRendererPath != "" {
+		log.Info().Msgf("  Helm post-renderer: %s", styles.RenderTechnical(postRendererPath))
+	}
+	// Show current Helm release info if it exists.
+	if existingRelease != nil {
+		log.Info().Msg("")
+		log.Info().Msg("Existing deployment:")
+		if existingRelease.Chart != nil && existingRelease.Chart.Metadata != nil {
+			log.Info().Msgf("  %-19s %s", "Chart version:", styles.RenderTechnical(existingRelease.Chart.Metadata.Version))
+		}
+		log.Info().Msgf("  %-19s %s", "Status:", styles.RenderTechnical(existingRelease.Info.Status.String()))
+		log.Info().Msgf("  %-19s %s", "Revision:", styles.RenderTechnical(fmt.Sprintf("%d", existingRelease.Version)))
+		lastDeployedAt := "Unknown"
+		if !existingRelease.Info.LastDeployed.IsZero() {
+			lastDeployedAt = humanize.Time(existingRelease.Info.LastDeployed.Time)
+		}
+		log.Info().Msgf("  %-19s %s", "Last Deployed:", styles.RenderTechnical(lastDeployedAt))
+	}
 	log.Info().Msg("")
 
 	// Check if the existing release is in some kind of pending state
@@ -314,8 +410,22 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// If dry-run mode, stop here.
+	if o.flagDryRun {
+		log.Info().Msg(styles.RenderMuted("Dry-run mode: skipping deployment"))
+		return nil
+	}
+
 	taskRunner := tui.NewTaskRunner()
 
+	// If using local image, add task to push it.
+	if image.isLocal {
+		taskRunner.AddTask("Push docker image to environment repository", func(output *tui.TaskOutput) error {
+			_, err := pushDockerImage(cmd.Context(), output, image.nameTag, envDetails.Deployment.EcrRepo, dockerCredentials)
+			return err
+		})
+	}
+
 	// Install or upgrade the Helm chart.
 	taskRunner.AddTask("Deploy loadtest Helm chart", func(output *tui.TaskOutput) error {
 		_, err = helmutil.HelmUpgradeOrInstall(
@@ -330,17 +440,15 @@ func (o *deployBotClientOpts) Run(cmd *cobra.Command) error {
 			helmDefaultValues,
 			cliSetValues,
 			helmRequiredValues,
+			postRenderer,
 			5*time.Minute,
+			true,
 			true)
 		return err
 	})
 
 	// Validate the bots status.
-	// log.Info().Msgf("Check bot status...")
-	// err = targetEnv.WaitForServerToBeReady(cmd.Context())
-	// if err != nil {
-	// 	return fmt.Errorf("deployed server failed to start: %v", err)
-	// }
+	targetEnv.WaitForBotClientsToBeReady(cmd.Context(), taskRunner)
 
 	// Run all tasks.
 	if err = taskRunner.Run(); err != nil {
@@ -0,0 +1,157 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Show the changes a game server deployment would make, without deploying. Shares the
+// implementation with 'deploy server --diff'.
+type deployDiffOpts struct {
+	deployGameServerOpts
+}
+
+func init() {
+	o := deployDiffOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argImageNameTag, "[IMAGE:]TAG", "Docker image name and tag, eg, 'mygame:364cff09' or '364cff09'.")
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to Helm.")
+
+	cmd := &cobra.Command{
+		Use:   "diff ENVIRONMENT [IMAGE:]TAG [flags] [-- EXTRA_ARGS]",
+		Short: "Show the changes a game server deployment would make without deploying",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Render the game server Helm chart with the same values as 'metaplay deploy server' would,
+			and show the changes against the currently deployed Helm release. Nothing is deployed
+			and local images are not pushed.
+
+			The output contains the diff of the Helm values, followed by a diff of each added,
+			removed, or modified Kubernetes resource. The values of Secrets are redacted. Chart
+			hooks are not included in the comparison.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy server ...' to perform the deployment.
+			- 'metaplay deploy history ...' to view the earlier deployments.
+		`),
+		Example: renderExample(`
+			# Show what deploying image '364cff09' to environment nimbly would change.
+			metaplay deploy diff nimbly 364cff09
+
+			# Show the effect of upgrading the Helm chart version with the latest locally built image.
+			metaplay deploy diff nimbly latest-local --helm-chart-version=0.9.0
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagHelmReleaseName, "helm-release-name", "", "Helm release name to compare against (default to the existing release)")
+	flags.StringVar(&o.flagHelmChartLocalPath, "local-chart-path", "", "Path to a local version of the metaplay-gameserver chart (repository and version are ignored if this is set)")
+	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository or OCI registry (oci://...) to use for the metaplay-gameserver chart")
+	flags.StringVar(&o.flagHelmChartVersion, "helm-chart-version", "", "Override for Helm chart version or version range to use, eg, '0.7.0' or '>=0.8.0 <0.9.0'")
+	flags.StringVarP(&o.flagHelmValuesPath, "values", "f", "", "Override for path to the Helm values file, e.g., 'Backend/Deployments/develop-server.yaml'")
+	flags.StringVar(&o.flagPostRenderer, "post-renderer", "", "Override for Helm post-renderer: path to an executable or a kustomize overlay directory")
+}
+
+func (o *deployDiffOpts) Prepare(cmd *cobra.Command, args []string) error {
+	o.flagDiff = true
+	o.flagStrategy = "all-at-once"
+	return o.deployGameServerOpts.Prepare(cmd, args)
+}
+
+// printDeployDiff prints the changes to the Helm values and the rendered Kubernetes manifests
+// between the existing release (if any) and the new ones as colored unified diffs.
+func printDeployDiff(existingRelease *release.Release, newValues map[string]any, newManifest string) error {
+	oldValues := map[string]any{}
+	oldManifest := ""
+	if existingRelease != nil {
+		oldValues = existingRelease.Config
+		oldManifest = existingRelease.Manifest
+	}
+
+	oldValuesYAML, err := yaml.Marshal(oldValues)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to marshal deployed Helm values as YAML")
+	}
+	newValuesYAML, err := yaml.Marshal(newValues)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to marshal new Helm values as YAML")
+	}
+
+	manifestChanges, err := helmutil.DiffManifests(oldManifest, newManifest)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to compare the Kubernetes manifests")
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Helm Values Changes"))
+	log.Info().Msg("")
+	if string(oldValuesYAML) == string(newValuesYAML) {
+		log.Info().Msg(styles.RenderMuted("No changes to Helm values"))
+	} else {
+		printColoredDiff(generateUnifiedDiff("values.yaml", oldValuesYAML, newValuesYAML, existingRelease == nil, false))
+	}
+	log.Info().Msg("")
+
+	log.Info().Msg(styles.RenderTitle("Kubernetes Manifest Changes"))
+	log.Info().Msg("")
+	numAdded, numRemoved, numModified := 0, 0, 0
+	for _, change := range manifestChanges {
+		switch change.Change {
+		case helmutil.ManifestResourceAdded:
+			numAdded++
+		case helmutil.ManifestResourceRemoved:
+			numRemoved++
+		case helmutil.ManifestResourceModified:
+			numModified++
+		}
+		printColoredDiff(generateUnifiedDiff(change.Resource, []byte(change.OldContent), []byte(change.NewContent),
+			change.Change == helmutil.ManifestResourceAdded, change.Change == helmutil.ManifestResourceRemoved))
+	}
+	if len(manifestChanges) == 0 {
+		log.Info().Msg(styles.RenderMuted("No changes to Kubernetes resources"))
+	} else {
+		log.Info().Msgf("Resources: %s added, %s modified, %s removed",
+			styles.RenderSuccess(fmt.Sprintf("%d", numAdded)),
+			styles.RenderAttention(fmt.Sprintf("%d", numModified)),
+			styles.RenderError(fmt.Sprintf("%d", numRemoved)))
+	}
+	log.Info().Msg("")
+
+	log.Info().Msg(styles.RenderMuted("Diff mode: skipping deployment"))
+	return nil
+}
+
+// printColoredDiff prints a unified diff with added lines in green and removed lines in red.
+func printColoredDiff(diff string) {
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			log.Info().Msg(styles.RenderBright(line))
+		case strings.HasPrefix(line, "@@"):
+			log.Info().Msg(styles.RenderTechnical(line))
+		case strings.HasPrefix(line, "+"):
+			log.Info().Msg(styles.RenderSuccess(line))
+		case strings.HasPrefix(line, "-"):
+			log.Info().Msg(styles.RenderError(line))
+		default:
+			log.Info().Msg(line)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Build the game config archive and publish it to the target environment.
+type deployGameConfigOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	extraArgs      []string
+	flagArchive    string
+	flagSetActive  bool
+	flagYes        bool
+}
+
+func init() {
+	o := deployGameConfigOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to the game config builder.")
+
+	cmd := &cobra.Command{
+		Use:   "game-config ENVIRONMENT [flags] [-- EXTRA_ARGS]",
+		Short: "Build and publish the game config to a cloud environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Build the game config archive and publish it to the target environment.
+
+			This command:
+			- Builds the game config archive with the project's game config builder (see
+			  'metaplay build game-config'), unless an existing archive is given with --archive
+			- Uploads the archive to the game server via the admin API
+			- Activates the uploaded game config if --set-active is specified
+
+			Without --set-active, the uploaded game config can be reviewed and activated in the
+			LiveOps Dashboard.
+
+			Publishing into a production environment requires confirmation, or --yes in
+			non-interactive sessions. With --set-active, the deploy windows of your
+			organization's policy apply.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay build game-config' to only build the game config archive.
+			- 'metaplay api ENVIRONMENT GET api/gameConfig' to list the game configs in an environment.
+		`),
+		Example: renderExample(`
+			# Build the game config and upload it to the environment 'nimbly'.
+			metaplay deploy game-config nimbly
+
+			# Build, upload, and activate the game config.
+			metaplay deploy game-config nimbly --set-active
+
+			# Upload and activate a previously built game config archive.
+			metaplay deploy game-config nimbly --archive build/StaticGameConfig.mpa --set-active
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagArchive, "archive", "", "Upload an existing game config archive instead of building one")
+	flags.BoolVar(&o.flagSetActive, "set-active", false, "Activate the game config after uploading it")
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt for production environments")
+}
+
+func (o *deployGameConfigOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagArchive != "" && len(o.extraArgs) > 0 {
+		return clierrors.NewUsageError("Extra arguments for the game config builder cannot be used with --archive")
+	}
+	return nil
+}
+
+func (o *deployGameConfigOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Building the game config requires a project.
+	if project == nil && o.flagArchive == "" {
+		return clierrors.NewUsageError("Building the game config requires a Metaplay project").
+			WithSuggestion("Run the command in a Metaplay project directory, or upload an existing archive with --archive")
+	}
+
+	// Resolve project and environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy. Activating the game
+	// config is a deployment, subject to the deploy windows.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, configArchiveOrgPolicyOperation(o.flagSetActive)); err != nil {
+		return err
+	}
+
+	// Require confirmation for production.
+	confirmed, err := confirmConfigArchiveDeploy(ctx, envConfig, gameConfigArchiveKind, o.flagYes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Game config deployment canceled."))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Deploy Game Config"))
+	log.Info().Msg("")
+
+	// Build the archive, unless an existing one was given.
+	archivePath := o.flagArchive
+	if archivePath == "" {
+		archivePath, err = buildConfigArchive(ctx, project, gameConfigArchiveKind, "", o.extraArgs)
+		if err != nil {
+			return err
+		}
+		log.Info().Msg("")
+	}
+
+	archive, err := os.ReadFile(archivePath)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to read game config archive %s", archivePath)
+	}
+
+	// Upload the game config.
+	adminClient := metahttp.NewJSONClient(tokenSet, getAdminAPIBaseURL(envConfig))
+	log.Info().Msgf("Uploading game config archive %s (%d bytes)...", styles.RenderTechnical(archivePath), len(archive))
+	configID, err := uploadConfigArchive(ctx, adminClient, gameConfigArchiveKind, archive)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("%s Uploaded game config %s", styles.RenderSuccess("✓"), styles.RenderTechnical(configID))
+
+	// Activate the game config, if requested.
+	if o.flagSetActive {
+		if err := publishConfigArchive(ctx, adminClient, gameConfigArchiveKind, configID); err != nil {
+			return err
+		}
+		log.Info().Msgf("%s Activated game config %s", styles.RenderSuccess("✓"), styles.RenderTechnical(configID))
+	} else {
+		log.Info().Msg(styles.RenderMuted("Game config was not activated: use --set-active or the LiveOps Dashboard to activate it"))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess("✅ Game config deployed successfully!"))
+	return nil
+}
+
+// configArchiveOrgPolicyOperation returns the kind of operation that publishing a game config or
+// localizations is for the organization policy: activating it is a deployment, only uploading it
+// a modification.
+func configArchiveOrgPolicyOperation(setActive bool) orgPolicyOperation {
+	if setActive {
+		return orgPolicyOperationDeploy
+	}
+	return orgPolicyOperationModify
+}
+
+// confirmConfigArchiveDeploy asks for confirmation to publish a game config or localizations into
+// a production environment. Other environments don't need confirmation.
+func confirmConfigArchiveDeploy(ctx context.Context, envConfig *metaproj.ProjectEnvironmentConfig, kind configArchiveKind, autoConfirm bool) (bool, error) {
+	if envConfig.Type != portalapi.EnvironmentTypeProduction || autoConfirm {
+		return true, nil
+	}
+	if !tui.CanAskQuestions() {
+		return false, clierrors.Newf("Confirmation required for production environment '%s'", envConfig.Name).
+			WithSuggestion("Use --yes to confirm in non-interactive mode")
+	}
+	confirmed, err := tui.DoConfirmQuestion(ctx, fmt.Sprintf("Publish the %s to production environment '%s'?", kind.Name, envConfig.Name))
+	if err != nil {
+		return false, err
+	}
+	log.Info().Msg("")
+	return confirmed, nil
+}
+
+// uploadConfigArchive uploads the archive to the game server. Returns the id of the uploaded
+// game config or localization.
+func uploadConfigArchive(ctx context.Context, adminClient *metahttp.Client, kind configArchiveKind, archive []byte) (string, error) {
+	response, err := adminClient.Resty.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(archive).
+		Post(kind.UploadPath)
+	if err != nil {
+		return "", clierrors.Wrapf(err, "Failed to upload %s", kind.Name)
+	}
+	if response.IsError() {
+		return "", clierrors.Newf("Failed to upload %s: %s", kind.Name, response.Status()).
+			WithDetails(string(response.Body()))
+	}
+	return parseConfigArchiveUploadResponse(kind, response.Body())
+}
+
+// parseConfigArchiveUploadResponse returns the id of the uploaded archive from the upload response body.
+func parseConfigArchiveUploadResponse(kind configArchiveKind, body []byte) (string, error) {
+	var uploadResponse struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &uploadResponse); err != nil {
+		return "", clierrors.Wrapf(err, "Failed to parse %s upload response", kind.Name)
+	}
+	if uploadResponse.ID == "" {
+		return "", clierrors.Newf("The %s upload response is missing the id", kind.Name)
+	}
+	return uploadResponse.ID, nil
+}
+
+// publishConfigArchive activates the uploaded game config or localization on the game server.
+func publishConfigArchive(ctx context.Context, adminClient *metahttp.Client, kind configArchiveKind, id string) error {
+	response, err := adminClient.Resty.R().
+		SetContext(ctx).
+		Post(fmt.Sprintf(kind.PublishPath, url.PathEscape(id)))
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to activate %s %s", kind.Name, id)
+	}
+	if response.IsError() {
+		return clierrors.Newf("Failed to activate %s %s: %s", kind.Name, id, response.Status()).
+			WithDetails(string(response.Body()))
+	}
+	return nil
+}
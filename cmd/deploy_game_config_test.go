@@ -0,0 +1,48 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigArchiveUploadResponse(t *testing.T) {
+	configID, err := parseConfigArchiveUploadResponse(gameConfigArchiveKind, []byte(`{"id": "0000018d1c2b3a4f-abcdef"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "0000018d1c2b3a4f-abcdef", configID)
+
+	_, err = parseConfigArchiveUploadResponse(gameConfigArchiveKind, []byte(`{}`))
+	assert.Error(t, err)
+	_, err = parseConfigArchiveUploadResponse(gameConfigArchiveKind, []byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestConfigArchiveOrgPolicyOperation(t *testing.T) {
+	assert.Equal(t, orgPolicyOperationDeploy, configArchiveOrgPolicyOperation(true))
+	assert.Equal(t, orgPolicyOperationModify, configArchiveOrgPolicyOperation(false))
+}
+
+func TestConfirmConfigArchiveDeploy(t *testing.T) {
+	ctx := context.Background()
+
+	// Non-production environments don't need confirmation.
+	envConfig := &metaproj.ProjectEnvironmentConfig{Name: "Development", Type: portalapi.EnvironmentTypeDevelopment}
+	confirmed, err := confirmConfigArchiveDeploy(ctx, envConfig, gameConfigArchiveKind, false)
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+
+	// Production environments are confirmed with --yes.
+	envConfig.Type = portalapi.EnvironmentTypeProduction
+	confirmed, err = confirmConfigArchiveDeploy(ctx, envConfig, localizationsArchiveKind, true)
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+}
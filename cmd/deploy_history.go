@@ -0,0 +1,226 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+type deployHistoryOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagFormat     string
+	flagLimit      int
+}
+
+// helmRevisionInfo describes a single revision (deploy) of the game server Helm release.
+type helmRevisionInfo struct {
+	Revision     int       `json:"revision"`
+	Status       string    `json:"status"`
+	ChartVersion string    `json:"chart_version"`
+	ImageTag     string    `json:"image_tag"`
+	Deployed     time.Time `json:"deployed"`
+	Description  string    `json:"description"`
+}
+
+func init() {
+	o := deployHistoryOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "history ENVIRONMENT [flags]",
+		Short: "Show the recent game server deployments in the target environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show the recent game server deployments in the target environment, newest first.
+
+			Each deployment corresponds to a revision of the game server Helm release and shows
+			the Helm chart version, docker image tag, and the time of the deployment.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy status ...' to show the status of the current deployment.
+			- 'metaplay deploy server ...' to deploy a game server.
+		`),
+		Example: renderExample(`
+			# Show the recent game server deployments in environment nimbly.
+			metaplay deploy history nimbly
+
+			# Show all the retained deployments in JSON format.
+			metaplay deploy history nimbly --format=json --limit=0
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+	flags.IntVar(&o.flagLimit, "limit", 10, "Maximum number of deployments to show (0 for all)")
+}
+
+func (o *deployHistoryOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	if o.flagLimit < 0 {
+		return clierrors.NewUsageErrorf("Invalid limit %d", o.flagLimit).
+			WithSuggestion("Use a non-negative number (0 for all)")
+	}
+	return nil
+}
+
+func (o *deployHistoryOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Create a Kubernetes client.
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+
+	// Find the game server release.
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the release history.
+	revisions := []helmRevisionInfo{}
+	if existingRelease != nil {
+		releases, err := helmutil.GetReleaseHistory(actionConfig, existingRelease.Name, o.flagLimit)
+		if err != nil {
+			return err
+		}
+		revisions = getHelmRevisionInfos(releases)
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		revisionsJSON, err := json.MarshalIndent(revisions, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal deployment history as JSON")
+		}
+		log.Info().Msg(string(revisionsJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Game Server Deployment History"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msg("")
+
+	if existingRelease == nil {
+		log.Info().Msg("No game server deployment found in the environment.")
+	} else {
+		log.Info().Msgf("Helm release: %s", styles.RenderTechnical(existingRelease.Name))
+		log.Info().Msg("")
+		renderHelmRevisions(revisions)
+
+		// Show truncation footer if applicable.
+		if o.flagLimit > 0 && len(revisions) == o.flagLimit {
+			log.Info().Msg("")
+			log.Info().Msg(styles.RenderMuted(fmt.Sprintf("  Showing latest %d deployments. Use --limit to see more.", o.flagLimit)))
+		}
+	}
+
+	log.Info().Msg("")
+	return nil
+}
+
+// getHelmRevisionInfos converts the Helm release revisions into simpler info objects.
+func getHelmRevisionInfos(releases []*release.Release) []helmRevisionInfo {
+	revisions := make([]helmRevisionInfo, 0, len(releases))
+	for _, rel := range releases {
+		revision := helmRevisionInfo{
+			Revision: rel.Version,
+			ImageTag: getReleaseImageTag(rel),
+		}
+		if rel.Info != nil {
+			revision.Status = rel.Info.Status.String()
+			revision.Deployed = rel.Info.LastDeployed.Time
+			revision.Description = rel.Info.Description
+		}
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			revision.ChartVersion = rel.Chart.Metadata.Version
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// getReleaseImageTag returns the docker image tag from the Helm release values, or an
+// empty string if it is not available (legacy Helm charts).
+func getReleaseImageTag(rel *release.Release) string {
+	if imageConfig, ok := rel.Config["image"].(map[string]any); ok {
+		if tag, ok := imageConfig["tag"].(string); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// renderHelmRevisions prints the Helm release revisions as a table.
+func renderHelmRevisions(revisions []helmRevisionInfo) {
+	// Compute column widths from data.
+	statusW := len("STATUS")
+	chartW := len("CHART")
+	tagW := len("IMAGE TAG")
+	for _, rev := range revisions {
+		statusW = max(statusW, len(rev.Status))
+		chartW = max(chartW, len(rev.ChartVersion))
+		tagW = max(tagW, len(rev.ImageTag))
+	}
+
+	log.Info().Msgf("  %-8s  %-*s  %-*s  %-*s  %s", "REVISION", statusW, "STATUS", chartW, "CHART", tagW, "IMAGE TAG", "DEPLOYED")
+	for _, rev := range revisions {
+		deployed := ""
+		if !rev.Deployed.IsZero() {
+			deployed = rev.Deployed.Local().Format("2006-01-02 15:04")
+		}
+
+		// Pad plain text before applying ANSI styles.
+		log.Info().Msgf("  %-8d  %-*s  %-*s  %s  %s",
+			rev.Revision,
+			statusW, rev.Status,
+			chartW, rev.ChartVersion,
+			styles.RenderTechnical(fmt.Sprintf("%-*s", tagW, coalesceString(rev.ImageTag, "-"))),
+			styles.RenderMuted(deployed),
+		)
+	}
+}
@@ -0,0 +1,375 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Admin API endpoints for inspecting localizations.
+const (
+	localizationContentsPath       = "/api/localization/%s"
+	activeLocalizationContentsPath = "/api/localization/active"
+)
+
+// Maximum number of missing translation keys to list per language.
+const maxMissingTranslationsToShow = 10
+
+// Build the localizations archive and publish it to the target environment.
+type deployLocalizationsOpts struct {
+	UsePositionalArgs
+
+	argEnvironment   string
+	extraArgs        []string
+	flagArchive      string
+	flagSetActive    bool
+	flagAllowMissing bool
+	flagYes          bool
+}
+
+// localizationContents is the localization as returned by the admin API: the translations
+// of each language, keyed by the translation key.
+type localizationContents struct {
+	ID        string                       `json:"id"`
+	Languages map[string]map[string]string `json:"languages"`
+}
+
+// localizationLanguageDiff summarizes the changes to the translations of a single language.
+type localizationLanguageDiff struct {
+	Language string
+	Status   string // "added", "removed", or "changed"
+	Added    int
+	Removed  int
+	Changed  int
+}
+
+func init() {
+	o := deployLocalizationsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to the game config builder.")
+
+	cmd := &cobra.Command{
+		Use:   "localizations ENVIRONMENT [flags] [-- EXTRA_ARGS]",
+		Short: "Build and publish the localizations to a cloud environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Build the localizations archive and publish it to the target environment.
+
+			This command:
+			- Builds the localizations archive with the project's game config builder (see
+			  'metaplay build localizations'), unless an existing archive is given with --archive
+			- Uploads the archive to the game server via the admin API
+			- Checks the uploaded localizations for missing translations: keys that exist in some
+			  languages but not in others
+			- Shows a summary of the changes compared to the currently active localizations
+			- Activates the uploaded localizations if --set-active is specified
+
+			Localizations with missing translations are not activated unless
+			--allow-missing-translations is specified. Without --set-active, the uploaded
+			localizations can be reviewed and activated in the LiveOps Dashboard.
+
+			Publishing into a production environment requires confirmation, or --yes in
+			non-interactive sessions. With --set-active, the deploy windows of your
+			organization's policy apply.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay build localizations' to only build the localizations archive.
+			- 'metaplay deploy game-config ...' to publish the game config to an environment.
+		`),
+		Example: renderExample(`
+			# Build the localizations and upload them to the environment 'nimbly'.
+			metaplay deploy localizations nimbly
+
+			# Build, upload, and activate the localizations.
+			metaplay deploy localizations nimbly --set-active
+
+			# Activate the localizations even if some translations are missing.
+			metaplay deploy localizations nimbly --set-active --allow-missing-translations
+
+			# Upload a previously built localizations archive.
+			metaplay deploy localizations nimbly --archive build/Localizations.mpa
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagArchive, "archive", "", "Upload an existing localizations archive instead of building one")
+	flags.BoolVar(&o.flagSetActive, "set-active", false, "Activate the localizations after uploading them")
+	flags.BoolVar(&o.flagAllowMissing, "allow-missing-translations", false, "Activate the localizations even if some translations are missing")
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt for production environments")
+}
+
+func (o *deployLocalizationsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagArchive != "" && len(o.extraArgs) > 0 {
+		return clierrors.NewUsageError("Extra arguments for the game config builder cannot be used with --archive")
+	}
+	return nil
+}
+
+func (o *deployLocalizationsOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Building the localizations requires a project.
+	if project == nil && o.flagArchive == "" {
+		return clierrors.NewUsageError("Building the localizations requires a Metaplay project").
+			WithSuggestion("Run the command in a Metaplay project directory, or upload an existing archive with --archive")
+	}
+
+	// Resolve project and environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy. Activating the
+	// localizations is a deployment, subject to the deploy windows.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, configArchiveOrgPolicyOperation(o.flagSetActive)); err != nil {
+		return err
+	}
+
+	// Require confirmation for production.
+	confirmed, err := confirmConfigArchiveDeploy(ctx, envConfig, localizationsArchiveKind, o.flagYes)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Localizations deployment canceled."))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Deploy Localizations"))
+	log.Info().Msg("")
+
+	// Build the archive, unless an existing one was given.
+	archivePath := o.flagArchive
+	if archivePath == "" {
+		archivePath, err = buildConfigArchive(ctx, project, localizationsArchiveKind, "", o.extraArgs)
+		if err != nil {
+			return err
+		}
+		log.Info().Msg("")
+	}
+
+	archive, err := os.ReadFile(archivePath)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to read localizations archive %s", archivePath)
+	}
+
+	// Upload the localizations.
+	adminClient := metahttp.NewJSONClient(tokenSet, getAdminAPIBaseURL(envConfig))
+	log.Info().Msgf("Uploading localizations archive %s (%d bytes)...", styles.RenderTechnical(archivePath), len(archive))
+	localizationID, err := uploadConfigArchive(ctx, adminClient, localizationsArchiveKind, archive)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("%s Uploaded localizations %s", styles.RenderSuccess("✓"), styles.RenderTechnical(localizationID))
+
+	// Fetch the uploaded and currently active localizations.
+	uploaded, err := fetchLocalizationContents(ctx, adminClient, fmt.Sprintf(localizationContentsPath, url.PathEscape(localizationID)))
+	if err != nil {
+		return err
+	}
+	if uploaded == nil {
+		return clierrors.Newf("Uploaded localizations %s not found", localizationID)
+	}
+	active, err := fetchLocalizationContents(ctx, adminClient, activeLocalizationContentsPath)
+	if err != nil {
+		return err
+	}
+
+	// Check for missing translations.
+	missing := findMissingTranslations(uploaded.Languages)
+	log.Info().Msg("")
+	renderMissingTranslations(uploaded.Languages, missing)
+
+	// Show the changes compared to the active localizations.
+	log.Info().Msg("")
+	if active == nil {
+		log.Info().Msg(styles.RenderMuted("No active localizations in the environment to compare against"))
+	} else {
+		renderLocalizationDiff(active.ID, diffLocalizations(active.Languages, uploaded.Languages))
+	}
+	log.Info().Msg("")
+
+	// Activate the localizations, if requested.
+	if !o.flagSetActive {
+		log.Info().Msg(styles.RenderMuted("Localizations were not activated: use --set-active or the LiveOps Dashboard to activate them"))
+		return nil
+	}
+	if len(missing) > 0 && !o.flagAllowMissing {
+		return clierrors.Newf("Not activating localizations %s: %d language(s) have missing translations", localizationID, len(missing)).
+			WithSuggestion("Add the missing translations, or use --allow-missing-translations to activate anyway")
+	}
+	if err := publishConfigArchive(ctx, adminClient, localizationsArchiveKind, localizationID); err != nil {
+		return err
+	}
+	log.Info().Msgf("%s Activated localizations %s", styles.RenderSuccess("✓"), styles.RenderTechnical(localizationID))
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess("✅ Localizations deployed successfully!"))
+	return nil
+}
+
+// fetchLocalizationContents fetches the localization contents from the admin API endpoint.
+// Returns nil if the localization does not exist.
+func fetchLocalizationContents(ctx context.Context, adminClient *metahttp.Client, path string) (*localizationContents, error) {
+	response, err := adminClient.Resty.R().SetContext(ctx).Get(path)
+	if err != nil {
+		return nil, clierrors.Wrap(err, "Failed to fetch localizations")
+	}
+	if response.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.IsError() {
+		return nil, clierrors.Newf("Failed to fetch localizations: %s", response.Status()).
+			WithDetails(string(response.Body()))
+	}
+
+	var contents localizationContents
+	if err := json.Unmarshal(response.Body(), &contents); err != nil {
+		return nil, clierrors.Wrap(err, "Failed to parse localizations response")
+	}
+	return &contents, nil
+}
+
+// findMissingTranslations returns the translation keys missing from each language, compared to
+// the union of keys over all languages. Languages with no missing translations are omitted.
+func findMissingTranslations(languages map[string]map[string]string) map[string][]string {
+	allKeys := map[string]struct{}{}
+	for _, translations := range languages {
+		for key := range translations {
+			allKeys[key] = struct{}{}
+		}
+	}
+
+	missing := map[string][]string{}
+	for language, translations := range languages {
+		for key := range allKeys {
+			if _, ok := translations[key]; !ok {
+				missing[language] = append(missing[language], key)
+			}
+		}
+		slices.Sort(missing[language])
+	}
+	return missing
+}
+
+// diffLocalizations compares the translations of each language between the active and the
+// uploaded localizations. Unchanged languages are omitted.
+func diffLocalizations(active, uploaded map[string]map[string]string) []localizationLanguageDiff {
+	languages := slices.Sorted(maps.Keys(active))
+	for language := range uploaded {
+		if _, ok := active[language]; !ok {
+			languages = append(languages, language)
+		}
+	}
+	slices.Sort(languages)
+
+	diffs := []localizationLanguageDiff{}
+	for _, language := range languages {
+		oldTranslations, hasOld := active[language]
+		newTranslations, hasNew := uploaded[language]
+		diff := localizationLanguageDiff{Language: language, Status: "changed"}
+		switch {
+		case !hasOld:
+			diff.Status = "added"
+		case !hasNew:
+			diff.Status = "removed"
+		}
+
+		for key, newText := range newTranslations {
+			oldText, ok := oldTranslations[key]
+			if !ok {
+				diff.Added++
+			} else if oldText != newText {
+				diff.Changed++
+			}
+		}
+		for key := range oldTranslations {
+			if _, ok := newTranslations[key]; !ok {
+				diff.Removed++
+			}
+		}
+
+		if diff.Status != "changed" || diff.Added+diff.Removed+diff.Changed > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+// renderMissingTranslations prints the number of translations and missing translations of each language.
+func renderMissingTranslations(languages map[string]map[string]string, missing map[string][]string) {
+	log.Info().Msg(styles.RenderTitle("Translations"))
+	log.Info().Msg("")
+	if len(languages) == 0 {
+		log.Info().Msg(styles.RenderMuted("  No languages in the localizations"))
+		return
+	}
+
+	log.Info().Msgf("  %-10s  %8s  %8s", "LANGUAGE", "KEYS", "MISSING")
+	for _, language := range slices.Sorted(maps.Keys(languages)) {
+		missingStr := fmt.Sprintf("%8d", len(missing[language]))
+		if len(missing[language]) > 0 {
+			missingStr = styles.RenderWarning(missingStr)
+		}
+		log.Info().Msgf("  %-10s  %8d  %s", language, len(languages[language]), missingStr)
+	}
+
+	for _, language := range slices.Sorted(maps.Keys(missing)) {
+		keys := missing[language]
+		shown := keys[:min(len(keys), maxMissingTranslationsToShow)]
+		log.Info().Msg("")
+		log.Info().Msgf("  Missing from %s: %s", styles.RenderAttention(language), strings.Join(shown, ", "))
+		if len(keys) > len(shown) {
+			log.Info().Msg(styles.RenderMuted(fmt.Sprintf("  ... and %d more", len(keys)-len(shown))))
+		}
+	}
+}
+
+// renderLocalizationDiff prints the summary of changes compared to the active localizations.
+func renderLocalizationDiff(activeID string, diffs []localizationLanguageDiff) {
+	log.Info().Msg(styles.RenderTitle(fmt.Sprintf("Changes compared to active localizations %s", activeID)))
+	log.Info().Msg("")
+	if len(diffs) == 0 {
+		log.Info().Msg(styles.RenderMuted("  No changes"))
+		return
+	}
+
+	log.Info().Msgf("  %-10s  %-8s  %8s  %8s  %8s", "LANGUAGE", "STATUS", "ADDED", "REMOVED", "CHANGED")
+	for _, diff := range diffs {
+		log.Info().Msgf("  %-10s  %-8s  %8d  %8d  %8d", diff.Language, diff.Status, diff.Added, diff.Removed, diff.Changed)
+	}
+}
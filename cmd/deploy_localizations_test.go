@@ -0,0 +1,51 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMissingTranslations(t *testing.T) {
+	missing := findMissingTranslations(map[string]map[string]string{
+		"en": {"Hello": "Hello", "Bye": "Bye", "Shop": "Shop"},
+		"fi": {"Hello": "Hei", "Bye": "Heippa", "Shop": "Kauppa"},
+		"de": {"Hello": "Hallo", "Extra": "Extra"},
+	})
+
+	assert.Equal(t, map[string][]string{
+		"en": {"Extra"},
+		"fi": {"Extra"},
+		"de": {"Bye", "Shop"},
+	}, missing)
+
+	assert.Empty(t, findMissingTranslations(map[string]map[string]string{
+		"en": {"Hello": "Hello"},
+		"fi": {"Hello": "Hei"},
+	}))
+}
+
+func TestDiffLocalizations(t *testing.T) {
+	active := map[string]map[string]string{
+		"en": {"Hello": "Hello", "Bye": "Bye"},
+		"fi": {"Hello": "Hei", "Bye": "Heippa"},
+		"sv": {"Hello": "Hej"},
+	}
+	uploaded := map[string]map[string]string{
+		"en": {"Hello": "Hello!", "Shop": "Shop"},
+		"fi": {"Hello": "Hei", "Bye": "Heippa"},
+		"de": {"Hello": "Hallo"},
+	}
+
+	assert.Equal(t, []localizationLanguageDiff{
+		{Language: "de", Status: "added", Added: 1},
+		{Language: "en", Status: "changed", Added: 1, Removed: 1, Changed: 1},
+		{Language: "sv", Status: "removed", Removed: 1},
+	}, diffLocalizations(active, uploaded))
+
+	assert.Empty(t, diffLocalizations(active, active))
+}
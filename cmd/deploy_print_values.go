@@ -0,0 +1,169 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Print the fully merged Helm values that 'metaplay deploy server' would use.
+type deployPrintValuesOpts struct {
+	UsePositionalArgs
+
+	argEnvironment     string
+	argImageNameTag    string
+	extraArgs          []string
+	flagHelmValuesPath string
+	flagOutput         string
+}
+
+func init() {
+	o := deployPrintValuesOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argImageNameTag, "[IMAGE:]TAG", "Docker image name and tag, eg, 'mygame:364cff09' or '364cff09'. The image values are left out if omitted.")
+	args.SetExtraArgs(&o.extraArgs, "Helm --set and --set-string arguments applied on top of the values files.")
+
+	cmd := &cobra.Command{
+		Use:   "print-values ENVIRONMENT [[IMAGE:]TAG] [flags] [-- EXTRA_ARGS]",
+		Short: "Print the merged Helm values used for deploying the game server",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Print the fully merged Helm values that 'metaplay deploy server' would use for deploying
+			the game server into the target environment, so that you can review, lint, or commit the
+			effective configuration, or use it with Helm directly.
+
+			The values are merged in the same order as when deploying:
+			1. The defaults computed by the CLI for the environment.
+			2. The environment's Helm values file(s) from metaplay-project.yaml, or --values.
+			3. Any --set and --set-string arguments given as EXTRA_ARGS.
+			4. The image repository and tag, which cannot be overridden.
+
+			When no image is given, the image tag and the SDK version are left out of the values.
+
+			The values are written to the file given with --output, or printed to stdout.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy server ENVIRONMENT [IMAGE:]TAG' to deploy the game server.
+			- 'metaplay deploy diff ENVIRONMENT [IMAGE:]TAG' to show the changes against the deployed release.
+		`),
+		Example: renderExample(`
+			# Print the Helm values for environment 'nimbly'.
+			metaplay deploy print-values nimbly
+
+			# Write the Helm values for deploying image '364cff09' into a file.
+			metaplay deploy print-values nimbly 364cff09 --output=values-nimbly.yaml
+
+			# Print the values with an override applied.
+			metaplay deploy print-values nimbly -- --set config.logLevel=Debug
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagHelmValuesPath, "values", "f", "", "Override for path to the Helm values file, e.g., 'Backend/Deployments/develop-server.yaml'")
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Path of the output file where to write the values (written to stdout if not specified)")
+}
+
+func (o *deployPrintValuesOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *deployPrintValuesOpts) Run(cmd *cobra.Command) error {
+	// Resolve the project, as the values files are relative to it.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve project and environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Get environment details for the image repository.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	envDetails, err := targetEnv.GetDetails()
+	if err != nil {
+		return err
+	}
+
+	// Resolve the image (if specified) for the image tag and SDK version.
+	sdkVersion := ""
+	imageTag := ""
+	if o.argImageNameTag != "" {
+		dockerCredentials, err := targetEnv.GetDockerCredentials()
+		if err != nil {
+			return fmt.Errorf("failed to get docker credentials: %v", err)
+		}
+		image, err := resolveDeployImage(project, envDetails, dockerCredentials, o.argImageNameTag, true)
+		if err != nil {
+			return err
+		}
+		sdkVersion = image.info.SdkVersion
+		imageTag = image.tag
+	}
+
+	// Resolve the Helm values files.
+	valuesFiles := project.GetServerValuesFiles(envConfig)
+	if o.flagHelmValuesPath != "" {
+		valuesFiles = []string{o.flagHelmValuesPath}
+	}
+	log.Debug().Msgf("Helm values files: %v", valuesFiles)
+
+	// Parse extra Helm arguments (--set, --set-string).
+	cliSetValues, err := helmutil.ParseHelmExtraArgs(o.extraArgs)
+	if err != nil {
+		return err
+	}
+
+	// Merge the values like 'metaplay deploy server' does.
+	defaultValues, requiredValues := gameServerHelmValues(envConfig, sdkVersion, envDetails.Deployment.EcrRepo, imageTag)
+	omitUnresolvedImageValues(defaultValues, requiredValues)
+	values, err := helmutil.ResolveHelmValues(nil, valuesFiles, defaultValues, cliSetValues, requiredValues)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to resolve the Helm values")
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to marshal the Helm values as YAML")
+	}
+
+	// Write the values to a file or stdout.
+	if o.flagOutput != "" {
+		log.Debug().Msgf("Write Helm values to file %s", o.flagOutput)
+		if err := os.WriteFile(o.flagOutput, valuesYAML, 0644); err != nil {
+			return fmt.Errorf("failed to write Helm values to file: %v", err)
+		}
+		log.Info().Msgf("Wrote Helm values to %s", o.flagOutput)
+	} else {
+		log.Info().Msg(string(valuesYAML))
+	}
+	return nil
+}
+
+// omitUnresolvedImageValues removes the SDK version and image tag from the Helm values when no
+// image was given, rather than printing them as empty strings.
+func omitUnresolvedImageValues(defaultValues, requiredValues map[string]any) {
+	if sdk, ok := defaultValues["sdk"].(map[string]any); ok && sdk["version"] == "" {
+		delete(defaultValues, "sdk")
+	}
+	if image, ok := requiredValues["image"].(map[string]any); ok && image["tag"] == "" {
+		delete(image, "tag")
+	}
+}
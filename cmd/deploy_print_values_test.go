@@ -0,0 +1,49 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintValuesMerge(t *testing.T) {
+	envConfig := &metaproj.ProjectEnvironmentConfig{
+		Name:    "nimbly",
+		HumanID: "lovely-wombats-build-nimbly",
+		Type:    portalapi.EnvironmentTypeDevelopment,
+	}
+
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("environment: overridden\nconfig:\n  logLevel: Debug\n"), 0644))
+
+	// Without an image, the SDK version and image tag are omitted.
+	defaultValues, requiredValues := gameServerHelmValues(envConfig, "", "registry/repo", "")
+	omitUnresolvedImageValues(defaultValues, requiredValues)
+	values, err := helmutil.ResolveHelmValues(nil, []string{valuesFile}, defaultValues, nil, requiredValues)
+	require.NoError(t, err)
+
+	assert.Equal(t, "overridden", values["environment"])
+	assert.NotContains(t, values, "sdk")
+	assert.Equal(t, map[string]any{"repository": "registry/repo"}, values["image"])
+	config := values["config"].(map[string]any)
+	assert.Equal(t, "Debug", config["logLevel"])
+	assert.Contains(t, config, "files")
+
+	// With an image, all the values are included.
+	defaultValues, requiredValues = gameServerHelmValues(envConfig, "34.1", "registry/repo", "364cff09")
+	omitUnresolvedImageValues(defaultValues, requiredValues)
+	values, err = helmutil.ResolveHelmValues(nil, nil, defaultValues, nil, requiredValues)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"version": "34.1"}, values["sdk"])
+	assert.Equal(t, map[string]any{"repository": "registry/repo", "tag": "364cff09"}, values["image"])
+}
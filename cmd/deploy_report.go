@@ -0,0 +1,207 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// Names of the deploy report files written into the report directory.
+const (
+	deployReportJSONFileName     = "deploy-report.json"
+	deployReportMarkdownFileName = "deploy-report.md"
+)
+
+// Results of a deployment in the deploy report.
+const (
+	deployResultSuccess  = "success"
+	deployResultFailure  = "failure"
+	deployResultDetached = "detached" // Exited without waiting for the deployment to complete.
+)
+
+// deployReport is the record of a single 'deploy server' run, written as JSON and markdown so
+// that CI pipelines can archive it.
+type deployReport struct {
+	Environment     string                  `json:"environment"`
+	ImageTag        string                  `json:"image_tag"`
+	BuildNumber     string                  `json:"build_number"`
+	CommitID        string                  `json:"commit_id"`
+	HelmRelease     string                  `json:"helm_release"`
+	DeployID        string                  `json:"deploy_id,omitempty"`
+	Strategy        string                  `json:"strategy"`
+	StartedAt       time.Time               `json:"started_at"`
+	FinishedAt      time.Time               `json:"finished_at"`
+	DurationSeconds float64                 `json:"duration_seconds"`
+	Result          string                  `json:"result"`
+	Error           string                  `json:"error,omitempty"`
+	Checks          []deployReportCheck     `json:"checks"`
+	ShardSets       []envapi.ShardSetStatus `json:"shard_sets"`
+	Endpoints       []deployReportEndpoint  `json:"endpoints"`
+}
+
+// deployReportCheck is a single step or check run during the deployment.
+type deployReportCheck struct {
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// deployReportEndpoint is a game server endpoint verified by the readiness checks.
+type deployReportEndpoint struct {
+	Name     string `json:"name"`
+	Address  string `json:"address"`
+	Verified bool   `json:"verified"`
+}
+
+// newDeployReportChecks converts the task runner results into the report checks.
+func newDeployReportChecks(results []tui.TaskResult) []deployReportCheck {
+	checks := make([]deployReportCheck, 0, len(results))
+	for _, result := range results {
+		check := deployReportCheck{
+			Name:            result.Title,
+			Status:          result.Status.String(),
+			DurationSeconds: result.Elapsed.Round(time.Millisecond).Seconds(),
+		}
+		if result.Error != nil {
+			check.Error = result.Error.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// finalize fills in the result and timing of the deployment and the endpoints checked.
+func (report *deployReport) finalize(result string, deployErr error, envDetails *envapi.DeploymentSecret, finishedAt time.Time) {
+	report.Result = result
+	if deployErr != nil {
+		report.Error = deployErr.Error()
+	}
+	report.FinishedAt = finishedAt
+	report.DurationSeconds = finishedAt.Sub(report.StartedAt).Round(time.Millisecond).Seconds()
+
+	// The endpoints are only verified when the readiness checks pass.
+	verified := result == deployResultSuccess
+	report.Endpoints = []deployReportEndpoint{
+		{Name: "Game server", Address: fmt.Sprintf("%s:9339", envDetails.Deployment.ServerHostname), Verified: verified},
+		{Name: "LiveOps Dashboard", Address: "https://" + envDetails.Deployment.AdminHostname, Verified: verified},
+	}
+}
+
+// renderMarkdown renders the report as a markdown document.
+func (report *deployReport) renderMarkdown() string {
+	var sb strings.Builder
+	resultIcon := map[string]string{deployResultSuccess: "✅", deployResultFailure: "❌", deployResultDetached: "⏳"}[report.Result]
+
+	fmt.Fprintf(&sb, "# Deploy report: %s\n\n", report.Environment)
+	fmt.Fprintf(&sb, "**Result:** %s %s\n\n", resultIcon, report.Result)
+	if report.Error != "" {
+		fmt.Fprintf(&sb, "**Error:** %s\n\n", escapeMarkdownTableCell(report.Error))
+	}
+
+	sb.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&sb, "| Environment | `%s` |\n", report.Environment)
+	fmt.Fprintf(&sb, "| Image tag | `%s` |\n", report.ImageTag)
+	fmt.Fprintf(&sb, "| Build number | `%s` |\n", report.BuildNumber)
+	fmt.Fprintf(&sb, "| Commit ID | `%s` |\n", report.CommitID)
+	fmt.Fprintf(&sb, "| Helm release | `%s` |\n", report.HelmRelease)
+	if report.DeployID != "" {
+		fmt.Fprintf(&sb, "| Deploy ID | `%s` |\n", report.DeployID)
+	}
+	fmt.Fprintf(&sb, "| Strategy | %s |\n", report.Strategy)
+	fmt.Fprintf(&sb, "| Started | %s |\n", report.StartedAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "| Duration | %s |\n", time.Duration(report.DurationSeconds*float64(time.Second)).Round(time.Second))
+
+	sb.WriteString("\n## Checks\n\n| Check | Status | Duration |\n|---|---|---|\n")
+	for _, check := range report.Checks {
+		status := check.Status
+		if check.Error != "" {
+			status += ": " + escapeMarkdownTableCell(check.Error)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %.1fs |\n", check.Name, status, check.DurationSeconds)
+	}
+
+	sb.WriteString("\n## Game server pods\n\n")
+	if len(report.ShardSets) == 0 {
+		sb.WriteString("No shard sets found.\n")
+	} else {
+		sb.WriteString("| Shard set | Pod | Phase | Message |\n|---|---|---|---|\n")
+		for _, shardSet := range report.ShardSets {
+			for _, pod := range shardSet.Pods {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", shardSet.Name, pod.Name, pod.Phase, escapeMarkdownTableCell(pod.Message))
+			}
+		}
+	}
+
+	sb.WriteString("\n## Endpoints\n\n| Endpoint | Address | Verified |\n|---|---|---|\n")
+	for _, endpoint := range report.Endpoints {
+		verified := "no"
+		if endpoint.Verified {
+			verified = "yes"
+		}
+		fmt.Fprintf(&sb, "| %s | `%s` | %s |\n", endpoint.Name, endpoint.Address, verified)
+	}
+
+	return sb.String()
+}
+
+// escapeMarkdownTableCell makes the text safe to use within a markdown table cell.
+func escapeMarkdownTableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// writeDeployReport writes the report as JSON and markdown files into the directory, creating
+// the directory if needed.
+func writeDeployReport(report *deployReport, dirPath string) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("failed to create deploy report directory %s: %w", dirPath, err)
+	}
+
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal deploy report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dirPath, deployReportJSONFileName), append(reportJSON, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write deploy report: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dirPath, deployReportMarkdownFileName), []byte(report.renderMarkdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write deploy report: %w", err)
+	}
+
+	return nil
+}
+
+// completeDeployReport finalizes the report and writes it into the directory. The pod statuses
+// are fetched from the environment on a best-effort basis. Failures are only logged as warnings
+// to not mask the result of the deployment itself.
+func completeDeployReport(ctx context.Context, report *deployReport, dirPath string, result string, deployErr error, taskRunner *tui.TaskRunner, kubeCli *envapi.KubeClient, envDetails *envapi.DeploymentSecret) {
+	report.Checks = newDeployReportChecks(taskRunner.Results())
+	report.ShardSets = []envapi.ShardSetStatus{}
+	if shardSets, err := envapi.FetchGameServerShardSetStatuses(context.WithoutCancel(ctx), kubeCli); err != nil {
+		log.Warn().Msgf("Failed to fetch game server pods for the deploy report: %v", err)
+	} else {
+		report.ShardSets = shardSets
+	}
+	report.finalize(result, deployErr, envDetails, time.Now())
+
+	if err := writeDeployReport(report, dirPath); err != nil {
+		log.Warn().Msgf("Failed to write the deploy report: %v", err)
+		return
+	}
+	log.Info().Msgf("Deploy report written to %s", styles.RenderTechnical(filepath.Join(dirPath, deployReportMarkdownFileName)))
+}
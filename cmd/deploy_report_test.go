@@ -0,0 +1,91 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDeployReport(t *testing.T) *deployReport {
+	startedAt := time.Date(2025, 1, 31, 13, 30, 0, 0, time.UTC)
+	report := &deployReport{
+		Environment: "nimbly",
+		ImageTag:    "364cff09",
+		BuildNumber: "123",
+		CommitID:    "364cff09abc",
+		HelmRelease: "nimbly-gameserver",
+		Strategy:    "all-at-once",
+		StartedAt:   startedAt,
+		Checks: newDeployReportChecks([]tui.TaskResult{
+			{Title: "Deploy game server using Helm", Status: tui.StatusCompleted, Elapsed: 12345 * time.Millisecond},
+			{Title: "Wait for game server pods to be ready", Status: tui.StatusFailed, Error: errors.New("pod | crashed"), Elapsed: time.Minute},
+			{Title: "Wait for game server to serve clients", Status: tui.StatusPending},
+		}),
+		ShardSets: []envapi.ShardSetStatus{
+			{Name: "all", Pods: []envapi.ShardPodStatus{{Name: "all-0", Phase: envapi.PhaseFailed, Message: "CrashLoopBackOff"}}},
+		},
+	}
+
+	envDetails := &envapi.DeploymentSecret{}
+	envDetails.Deployment.ServerHostname = "nimbly.p1.metaplay.io"
+	envDetails.Deployment.AdminHostname = "nimbly-admin.p1.metaplay.io"
+	report.finalize(deployResultFailure, errors.New("pods not ready"), envDetails, startedAt.Add(90*time.Second))
+	return report
+}
+
+func TestDeployReportFinalize(t *testing.T) {
+	report := newTestDeployReport(t)
+
+	assert.Equal(t, deployResultFailure, report.Result)
+	assert.Equal(t, "pods not ready", report.Error)
+	assert.Equal(t, 90.0, report.DurationSeconds)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, deployReportCheck{Name: "Deploy game server using Helm", Status: "completed", DurationSeconds: 12.345}, report.Checks[0])
+	assert.Equal(t, "failed", report.Checks[1].Status)
+	assert.Equal(t, "pod | crashed", report.Checks[1].Error)
+	assert.Equal(t, "pending", report.Checks[2].Status)
+	assert.Equal(t, []deployReportEndpoint{
+		{Name: "Game server", Address: "nimbly.p1.metaplay.io:9339", Verified: false},
+		{Name: "LiveOps Dashboard", Address: "https://nimbly-admin.p1.metaplay.io", Verified: false},
+	}, report.Endpoints)
+}
+
+func TestDeployReportMarkdown(t *testing.T) {
+	markdown := newTestDeployReport(t).renderMarkdown()
+
+	assert.Contains(t, markdown, "# Deploy report: nimbly")
+	assert.Contains(t, markdown, "**Result:** ❌ failure")
+	assert.Contains(t, markdown, "| Duration | 1m30s |")
+	assert.Contains(t, markdown, "| Wait for game server pods to be ready | failed: pod \\| crashed | 60.0s |")
+	assert.Contains(t, markdown, "| all | all-0 | Failed | CrashLoopBackOff |")
+	assert.Contains(t, markdown, "| LiveOps Dashboard | `https://nimbly-admin.p1.metaplay.io` | no |")
+}
+
+func TestWriteDeployReport(t *testing.T) {
+	dirPath := filepath.Join(t.TempDir(), "reports")
+	report := newTestDeployReport(t)
+	require.NoError(t, writeDeployReport(report, dirPath))
+
+	reportJSON, err := os.ReadFile(filepath.Join(dirPath, deployReportJSONFileName))
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(reportJSON, &decoded))
+	assert.Equal(t, "failure", decoded["result"])
+	assert.Equal(t, "364cff09", decoded["image_tag"])
+
+	markdown, err := os.ReadFile(filepath.Join(dirPath, deployReportMarkdownFileName))
+	require.NoError(t, err)
+	assert.Equal(t, report.renderMarkdown(), string(markdown))
+}
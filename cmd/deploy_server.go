@@ -5,7 +5,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -14,17 +17,29 @@ import (
 	"github.com/hashicorp/go-version"
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/cistatus"
 	"github.com/metaplay/cli/pkg/envapi"
 	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/portalapi"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 )
 
 const metaplayGameServerChartName = "metaplay-gameserver"
 
+// Default Helm chart repository, used when the project does not specify one.
+const defaultHelmChartRepository = "https://charts.metaplay.dev"
+
+// How often to poll the environment's registry for the image with --wait-for-image.
+const remoteImagePollInterval = 5 * time.Second
+
 // Deploy a game server to the target environment with specified docker image version.
 type deployGameServerOpts struct {
 	UsePositionalArgs
@@ -37,7 +52,21 @@ type deployGameServerOpts struct {
 	flagHelmChartRepository string
 	flagHelmChartVersion    string
 	flagHelmValuesPath      string
+	flagPostRenderer        string
 	flagDryRun              bool
+	flagDiff                bool
+	flagStrategy            string
+	flagCanaryPercent       int
+	flagCanaryDuration      time.Duration
+	flagCanaryMaxErrors     int
+	flagCanarySkipErrors    bool
+	flagSkipCIStatus        bool
+	flagDetach              bool
+	flagReportDir           string
+	flagAllowDirty          bool
+	flagRequireAttestations bool
+	flagWaitForImage        time.Duration
+	flagImageDigest         string
 }
 
 func init() {
@@ -59,6 +88,8 @@ func init() {
 			After deploying the server image, various checks are run against the deployment to
 			help diagnose any potential issues:
 			- All expected pods are present, healthy, and ready.
+			- NetworkPolicies allow client and admin traffic, and PodDisruptionBudgets allow node
+			  maintenance (only reported as warnings).
 			- Client-facing domain name resolves correctly.
 			- Game server responds to client traffic.
 			- Admin domain name resolves correctly.
@@ -66,7 +97,102 @@ func init() {
 
 			When a full docker image tag is specified (eg, 'mygame:364cff09'), the image is first
 			pushed to the environment's registry. If only a tag is specified (eg, '364cff09'), the
-			image is assumed to be present in the remote registry already.
+			image is assumed to be present in the remote registry already. If the project specifies
+			an 'imageNaming' template in metaplay-project.yaml, a local image with the tag named
+			using the template is pushed and deployed instead, if one exists.
+
+			A Helm post-renderer can be used to customize the rendered Kubernetes manifests before
+			they are applied, eg, to inject sidecars or labels not exposed by the Helm chart. The
+			post-renderer is either an executable (receives the manifests on stdin and writes the
+			modified manifests to stdout) or a kustomize overlay directory. For overlays, the rendered
+			manifests are provided in the file 'all.yaml', which the overlay's kustomization.yaml must
+			list in its resources. The post-renderer can be configured per environment using the
+			'serverPostRenderer' field in metaplay-project.yaml or overridden with --post-renderer.
+
+			The Helm chart is resolved based on 'serverChartVersion' in metaplay-project.yaml. Charts
+			vendored into the project with 'metaplay charts vendor' are preferred: if the local charts
+			directory configured with 'localChartsDir' in metaplay-project.yaml contains a matching
+			chart archive, eg, 'metaplay-gameserver-0.8.3.tgz', it is used. Otherwise, the chart is
+			resolved from the chart repository (https://charts.metaplay.dev by default).
+
+			Deploying into a production environment is refused if the image was built from a git
+			working tree with uncommitted changes, or if its commit has not been pushed to a git
+			remote (checked against the local repository, when it knows the commit). Such builds
+			cannot be reproduced later. Use --allow-dirty to deploy them anyway.
+
+			Before deploying, the image's platforms are checked against the CPU architectures of the
+			Kubernetes nodes that the game server runs on (or can be scheduled on, based on its node
+			selector and affinity), so that an image built only for amd64 is not deployed onto arm64
+			nodes (or vice versa). Build multi-arch images with 'metaplay build image
+			--platforms=linux/amd64,linux/arm64'.
+
+			The SBOM and SLSA provenance attestations of the image (see 'metaplay build image --sbom
+			--provenance') are verified to refer to the image and shown before deploying. Local images
+			are verified after pushing them. Use --require-attestations to refuse deploying images
+			without both attestations, eg, when your security team requires supply-chain metadata
+			for production deploys.
+
+			Deploying must also be allowed by your organization's policy, if the organization admins
+			have defined one in the portal: the policy can limit deploys to given time windows, block
+			Helm chart versions, require the image to have a cosign signature in the environment's
+			image repository, and require a minimum CLI version. The policy is cached locally for a
+			few minutes.
+
+			If 'requireSignedImages' is enabled in metaplay-project.yaml, the image's cosign signature
+			is verified with the public key or the keyless signer identity configured in
+			'imageSigning', and unsigned or unverified images are refused. The image must have been
+			pushed and signed before with 'metaplay image push --sign', and it is deployed from the
+			environment's registry by its TAG: local images are refused. Verifying requires cosign
+			to be installed. When the signature is checked, the image is deployed pinned to the
+			verified manifest digest (as 'TAG@sha256:...'), so that moving the tag during the deploy
+			cannot change the deployed image.
+
+			In split CI pipelines, where the image is pushed by a separate job, the registry may not
+			have the pushed tag available yet when the deploy starts. Use --wait-for-image to poll the
+			environment's registry for the tag until it appears (or the timeout expires), instead of
+			failing. With --image-digest, the tag must also point to the given manifest digest, eg,
+			the digest reported by the job that pushed the image, so that a stale image with the same
+			tag is not deployed.
+
+			With --strategy=canary, the new version is first rolled out to a subset of the game
+			server pods (--canary-percent). The canary pods are then monitored for a while
+			(--canary-duration): if any of them fails or restarts, or the game server reports
+			more errors via the admin API than allowed (--canary-max-errors), the deployment is
+			rolled back to the previous Helm release revision. Otherwise, the new version is
+			promoted to all the pods. If the server errors cannot be fetched from the admin API,
+			the canary fails: use --canary-skip-error-check to only monitor the canary pods. The
+			canary strategy requires an existing deployment whose shard sets use the RollingUpdate
+			strategy, and the game server must tolerate running mixed versions during the canary
+			phase. The shard sets' rolling update partitions are restored after the canary phase.
+
+			When running in GitHub Actions or Bitbucket Pipelines with API credentials available, the
+			deployment status is reported back to the CI provider: as a GitHub Deployment (requires
+			GITHUB_TOKEN with the 'deployments: write' permission) or as a Bitbucket build status on
+			the deployed commit (requires BITBUCKET_ACCESS_TOKEN, or BITBUCKET_USERNAME and
+			BITBUCKET_APP_PASSWORD). The status links to the environment's LiveOps Dashboard.
+			Failing to report the status does not fail the deployment. Use --skip-ci-status to
+			disable the reporting.
+
+			With --detach, the command exits right after the Helm release has been applied, without
+			waiting for the game server to become ready, and prints a deploy ID. Use 'metaplay deploy
+			attach ENVIRONMENT DEPLOY_ID' to later wait for the rollout to complete and run the
+			checks. This is useful with CI runners that have short job timeouts. The deployment
+			status is not reported to the CI provider when detaching. --detach cannot be used with
+			--strategy=canary as the canary phase needs to be monitored.
+
+			With --report-dir (or the METAPLAYCLI_DEPLOY_REPORT_DIR environment variable), a deploy
+			report is written into the directory after the deployment, both as 'deploy-report.json'
+			and 'deploy-report.md'. The report includes the steps and checks run with their
+			durations, the game server pods, the verified endpoints, and the final result. It is
+			written also when the deployment fails, so CI pipelines can archive it.
+
+			With --diff, the Helm chart is rendered with the resolved values and the changes to the
+			Helm values and Kubernetes manifests of the currently deployed release are shown, without
+			deploying anything. The same is available as 'metaplay deploy diff ...'. --diff cannot be
+			used with --strategy=canary, as the diff does not depend on the strategy.
+
+			With --dry-run, all the checks are run as usual, and the steps that the deployment would
+			perform (image push, Helm install or upgrade, canary phases) are listed without executing them.
 
 			{Arguments}
 
@@ -97,6 +223,33 @@ func init() {
 
 			# Override the Helm release name.
 			metaplay deploy server nimbly mygame:364cff09 --helm-release-name=my-release-name
+
+			# Deploy to 20% of the game server pods first and promote after 10 minutes without errors.
+			metaplay deploy server nimbly mygame:364cff09 --strategy=canary --canary-percent=20 --canary-duration=10m
+
+			# Apply the deployment and exit without waiting for the game server to be ready.
+			metaplay deploy server nimbly 364cff09 --detach
+
+			# Write a deploy report into the directory 'reports' for archiving in CI.
+			metaplay deploy server nimbly 364cff09 --report-dir=reports
+
+			# Show the changes to the deployed Helm values and Kubernetes manifests without deploying.
+			metaplay deploy server nimbly 364cff09 --diff
+
+			# Deploy a build with uncommitted changes into a production environment.
+			metaplay deploy server production mygame:364cff09 --allow-dirty
+
+			# Wait up to 5 minutes for an image pushed by another CI job to appear in the registry.
+			metaplay deploy server nimbly 364cff09 --wait-for-image=5m --image-digest=sha256:4f1c...
+
+			# Deploy only if the image has SBOM and provenance attestations.
+			metaplay deploy server production 364cff09 --require-attestations
+
+			# List the deployment steps without executing them.
+			metaplay deploy server nimbly mygame:364cff09 --dry-run
+
+			# Apply a post-renderer on the rendered Kubernetes manifests.
+			metaplay deploy server nimbly mygame:364cff09 --post-renderer=Backend/Deployments/kustomize-overlay
 		`),
 	}
 	deployCmd.AddCommand(cmd)
@@ -104,17 +257,82 @@ func init() {
 	flags := cmd.Flags()
 	flags.StringVar(&o.flagHelmReleaseName, "helm-release-name", "", "Helm release name to use for the game server deployment (default to '<environmentID>-gameserver')")
 	flags.StringVar(&o.flagHelmChartLocalPath, "local-chart-path", "", "Path to a local version of the metaplay-gameserver chart (repository and version are ignored if this is set)")
-	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository to use for the metaplay-gameserver chart")
-	flags.StringVar(&o.flagHelmChartVersion, "helm-chart-version", "", "Override for Helm chart version to use, eg, '0.7.0'")
+	flags.StringVar(&o.flagHelmChartRepository, "helm-chart-repo", "", "Override for Helm chart repository or OCI registry (oci://...) to use for the metaplay-gameserver chart")
+	flags.StringVar(&o.flagHelmChartVersion, "helm-chart-version", "", "Override for Helm chart version or version range to use, eg, '0.7.0' or '>=0.8.0 <0.9.0'")
 	flags.StringVarP(&o.flagHelmValuesPath, "values", "f", "", "Override for path to the Helm values file, e.g., 'Backend/Deployments/develop-server.yaml'")
+	flags.StringVar(&o.flagPostRenderer, "post-renderer", "", "Override for Helm post-renderer: path to an executable or a kustomize overlay directory")
 	flags.BoolVar(&o.flagDryRun, "dry-run", false, "Show what would be deployed without actually performing the deployment")
+	flags.BoolVar(&o.flagDiff, "diff", false, "Show the changes to the deployed Helm values and Kubernetes manifests without performing the deployment")
+	flags.StringVar(&o.flagStrategy, "strategy", "all-at-once", "Deployment strategy: 'all-at-once' or 'canary'")
+	flags.IntVar(&o.flagCanaryPercent, "canary-percent", 10, "Percentage of game server pods to deploy first with --strategy=canary")
+	flags.DurationVar(&o.flagCanaryDuration, "canary-duration", 5*time.Minute, "How long to monitor the canary pods before promoting with --strategy=canary")
+	flags.IntVar(&o.flagCanaryMaxErrors, "canary-max-errors", 0, "Maximum number of server errors allowed during the canary phase with --strategy=canary")
+	flags.BoolVar(&o.flagCanarySkipErrors, "canary-skip-error-check", false, "Don't check the server errors from the admin API during the canary phase with --strategy=canary")
+	flags.BoolVar(&o.flagSkipCIStatus, "skip-ci-status", false, "Don't report the deployment status to the CI provider (GitHub or Bitbucket)")
+	flags.BoolVar(&o.flagDetach, "detach", false, "Exit after applying the Helm release without waiting for the game server to be ready")
+	flags.StringVar(&o.flagReportDir, "report-dir", "", "Directory to write the deploy report (deploy-report.json and deploy-report.md) into")
+	flags.BoolVar(&o.flagRequireAttestations, "require-attestations", false, "Refuse deploying an image without SBOM and provenance attestations")
+	flags.DurationVar(&o.flagWaitForImage, "wait-for-image", 0, "Wait up to the given duration for the image tag to appear in the environment's registry, eg, '5m'")
+	flags.StringVar(&o.flagImageDigest, "image-digest", "", "Require the image tag to point to the given manifest digest, eg, 'sha256:4f1c...'")
+	flags.BoolVar(&o.flagAllowDirty, "allow-dirty", false, "Allow deploying an image built from uncommitted or unpushed changes into a production environment")
 }
 
 func (o *deployGameServerOpts) Prepare(cmd *cobra.Command, args []string) error {
+	switch o.flagStrategy {
+	case "all-at-once":
+		if cmd.Flags().Changed("canary-percent") || cmd.Flags().Changed("canary-duration") || cmd.Flags().Changed("canary-max-errors") || o.flagCanarySkipErrors {
+			return clierrors.NewUsageError("The --canary-* flags can only be used with --strategy=canary")
+		}
+	case "canary":
+		if o.flagDetach {
+			return clierrors.NewUsageError("--detach cannot be used with --strategy=canary").
+				WithSuggestion("The canary phase must be monitored, use the default strategy with --detach")
+		}
+		if o.flagDiff {
+			return clierrors.NewUsageError("--diff cannot be used with --strategy=canary").
+				WithSuggestion("The diff does not depend on the strategy, run it without --strategy=canary")
+		}
+		if o.flagCanaryPercent < 1 || o.flagCanaryPercent > 99 {
+			return clierrors.NewUsageErrorf("Invalid --canary-percent %d", o.flagCanaryPercent).
+				WithSuggestion("Use a value between 1 and 99")
+		}
+		if o.flagCanaryDuration < 0 {
+			return clierrors.NewUsageErrorf("Invalid --canary-duration %v", o.flagCanaryDuration).
+				WithSuggestion("Use a non-negative duration, eg, '5m'")
+		}
+		if o.flagCanaryMaxErrors < 0 {
+			return clierrors.NewUsageErrorf("Invalid --canary-max-errors %d", o.flagCanaryMaxErrors).
+				WithSuggestion("Use a non-negative number")
+		}
+	default:
+		return clierrors.NewUsageErrorf("Invalid --strategy %q", o.flagStrategy).
+			WithSuggestion("Use 'all-at-once' or 'canary'")
+	}
+
+	if err := metaproj.ValidateHelmChartRepositoryURL(o.flagHelmChartRepository); err != nil {
+		return clierrors.WrapUsageError(err, "Invalid --helm-chart-repo").
+			WithSuggestion("Use an http(s) chart repository or an OCI registry, eg, 'oci://registry.example.com/charts'")
+	}
+
+	// Waiting is only possible for images already pushed into the registry (TAG only).
+	if o.flagWaitForImage < 0 {
+		return clierrors.NewUsageErrorf("Invalid --wait-for-image %v", o.flagWaitForImage).
+			WithSuggestion("Use a non-negative duration, eg, '5m'")
+	}
+	if o.flagImageDigest != "" && !strings.HasPrefix(o.flagImageDigest, "sha256:") {
+		return clierrors.NewUsageErrorf("Invalid --image-digest %q", o.flagImageDigest).
+			WithSuggestion("Use the image's manifest digest, eg, 'sha256:4f1c...'")
+	}
+	if (o.flagWaitForImage > 0 || o.flagImageDigest != "") && (o.argImageNameTag == "" || o.argImageNameTag == "latest-local" || strings.Contains(o.argImageNameTag, ":")) {
+		return clierrors.NewUsageError("--wait-for-image and --image-digest can only be used when deploying an image TAG from the environment's registry").
+			WithSuggestion("Specify only the image tag, eg, '364cff09', instead of a local IMAGE:TAG")
+	}
 	return nil
 }
 
 func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
+	startedAt := time.Now()
+
 	// Try to resolve the project & auth provider.
 	project, err := resolveProject()
 	if err != nil {
@@ -127,6 +345,26 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// For Metaplay-managed environments, check that the environment belongs to this project and
+	// that the local env config (from metaplay-project.yaml) matches the one from portal.
+	portalInfo, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet)
+	if err != nil {
+		return err
+	}
+
+	// Environment type (prod, staging, development) must match that in the portal.
+	// Otherwise, the game server will be using wrong environment type-specific defaults.
+	if portalInfo != nil && envConfig.Type != portalInfo.Type {
+		return clierrors.Newf("Environment type mismatch: local config has '%s', portal has '%s'", envConfig.Type, portalInfo.Type).
+			WithSuggestion("Run 'metaplay update project-environments' to sync with portal")
+	}
+
+	// Check that deploying is allowed by the organization's policy.
+	orgPolicy, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDeploy)
+	if err != nil {
+		return err
+	}
+
 	// Create TargetEnvironment.
 	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
 
@@ -139,6 +377,7 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 
 	// Validate Helm chart reference.
 	var chartVersionConstraints version.Constraints = nil
+	var chartVersionSpecifier string // Version or range of the chart, as specified by the user.
 	if o.flagHelmChartLocalPath != "" {
 		err = helmutil.ValidateLocalHelmChart(o.flagHelmChartLocalPath)
 		if err != nil {
@@ -151,16 +390,13 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 			helmChartVersion = o.flagHelmChartVersion
 		}
 
-		if helmChartVersion == "latest-prerelease" {
-			// Accept any version
-		} else {
-			// Parse Helm chart semver range.
-			chartVersionConstraints, err = version.NewConstraint(helmChartVersion)
-			if err != nil {
-				return fmt.Errorf("invalid Helm chart version: %v", err)
-			}
-			log.Debug().Msgf("Accepted Helm chart semver constraints: %v", chartVersionConstraints)
+		// Parse Helm chart version or semver range ('latest-prerelease' accepts any version).
+		chartVersionConstraints, err = metaproj.ParseHelmChartVersionConstraints(helmChartVersion)
+		if err != nil {
+			return fmt.Errorf("invalid Helm chart version '%s': %v", helmChartVersion, err)
 		}
+		log.Debug().Msgf("Accepted Helm chart semver constraints: %v", chartVersionConstraints)
+		chartVersionSpecifier = helmChartVersion
 	}
 
 	// Get environment details.
@@ -170,60 +406,86 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Get docker credentials.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return fmt.Errorf("failed to get docker credentials: %v", err)
 	}
 	log.Debug().Msgf("Got docker credentials: username=%s", dockerCredentials.Username)
 
-	// If no docker image specified, scan the images matching project from the local docker repo
-	// and then let the user choose from the images.
-	switch o.argImageNameTag {
-	case "":
-		selectedImage, err := selectDockerImageInteractively("Select Image to Deploy", project.Config.ProjectHumanID)
-		if err != nil {
-			return err
+	// Wait for an image pushed by another job to become available in the registry.
+	if o.flagWaitForImage > 0 || o.flagImageDigest != "" {
+		remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, o.argImageNameTag)
+		fetchDigests := func() (*envapi.RemoteDockerImageDigests, bool, error) {
+			return envapi.FetchRemoteDockerImageDigests(dockerCredentials, remoteImageName)
 		}
-		o.argImageNameTag = selectedImage.RepoTag
-	case "latest-local":
-		// Resolve the local docker images matching project human ID.
-		localImages, err := envapi.ReadLocalDockerImagesByProjectID(project.Config.ProjectHumanID)
-		if err != nil {
+		if err := waitForRemoteDeployImage(cmd.Context(), fetchDigests, o.argImageNameTag, o.flagImageDigest, o.flagWaitForImage, remoteImagePollInterval); err != nil {
 			return err
 		}
+	}
 
-		// If there are no images for this project, error out.
-		if len(localImages) == 0 {
-			return clierrors.Newf("No Docker images matching project '%s' found locally", project.Config.ProjectHumanID).
-				WithSuggestion("Build an image first with 'metaplay build image'")
+	// Resolve the docker image to deploy (local or remote).
+	// With --wait-for-image or --image-digest, the TAG always refers to the registry. When
+	// signed images are required, so does the TAG, as local images cannot be signed yet.
+	requireSignedImages := project != nil && project.Config.RequireSignedImages
+	localByTag := o.flagWaitForImage == 0 && o.flagImageDigest == "" && !requireSignedImages
+	image, err := resolveDeployImage(project, envDetails, dockerCredentials, o.argImageNameTag, localByTag)
+	if err != nil {
+		return err
+	}
+	useLocalImage := image.isLocal
+	imageTag := image.tag
+	imageInfo := image.info
+	o.argImageNameTag = image.nameTag
+
+	// Check that production deployments are reproducible from the image's commit.
+	if envConfig.Type == portalapi.EnvironmentTypeProduction {
+		if err := checkDeployImageGitState(cmd.Context(), project, imageInfo, o.flagAllowDirty); err != nil {
+			return err
 		}
+	}
 
-		// Use the first entry (they are reverse sorted by creation time).
-		o.argImageNameTag = localImages[0].RepoTag
+	// Local images are pushed during the deploy, so they cannot have been signed before it.
+	if requireSignedImages && useLocalImage {
+		return clierrors.Newf("Local image '%s' cannot be deployed, as 'requireSignedImages' is enabled in metaplay-project.yaml", o.argImageNameTag).
+			WithDetails("Local images are pushed during the deploy and cannot be signed before it.").
+			WithSuggestion(fmt.Sprintf("Push and sign the image with 'metaplay image push ENVIRONMENT %s --sign', and deploy it using its tag", o.argImageNameTag))
 	}
 
-	// Resolve image tag and metadata from the local or remote image.
-	useLocalImage := strings.Contains(o.argImageNameTag, ":")
-	var imageTag string
-	var imageInfo *envapi.MetaplayImageInfo
-	if useLocalImage {
-		// Resolve metadata from local image.
-		imageInfo, err = envapi.ReadLocalDockerImageMetadata(o.argImageNameTag)
+	// When the image's signature is checked, pin the image to its manifest digest, so that the
+	// deployed image is the one that was checked even if the tag is moved in between.
+	deployImageTag := imageTag
+	var imageDigestRef string
+	if !useLocalImage && (orgPolicy.RequireImageSigning || requireSignedImages) {
+		imageDigestRef, err = resolveRemoteImageDigestRef(dockerCredentials, fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageTag))
 		if err != nil {
+			return clierrors.Wrapf(err, "Failed to resolve the digest of image '%s'", imageTag)
+		}
+		deployImageTag = pinImageTagToDigest(imageTag, imageDigestRef)
+		log.Debug().Msgf("Pinned image %s to %s", imageTag, imageDigestRef)
+	}
+
+	// Check that the image is signed, if required by the organization's policy.
+	if orgPolicy.RequireImageSigning {
+		if err := checkDeployImageSigned(image, imageDigestRef, dockerCredentials); err != nil {
 			return err
 		}
+	}
 
-		// Extract the tag part.
-		imageTag, err = extractDockerImageTag(o.argImageNameTag)
-		if err != nil {
+	// Verify the image's cosign signature, if required by the project.
+	if requireSignedImages {
+		if err := checkCosignInstalled(cmd.Context()); err != nil {
 			return err
 		}
-	} else {
-		imageTag = o.argImageNameTag
+		if err := checkDeployImageSignatureVerified(cmd.Context(), dockerCredentials, imageDigestRef, imageTag, project.Config.ImageSigning); err != nil {
+			return err
+		}
+	}
 
-		// Fetch the image info from the remote docker image.
+	// Verify the image's attestations. Local images are verified after pushing them.
+	var attestations *envapi.ImageAttestations
+	if !useLocalImage {
 		remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageTag)
-		imageInfo, err = envapi.FetchRemoteDockerImageMetadata(dockerCredentials, remoteImageName)
+		attestations, err = checkDeployImageAttestations(dockerCredentials, remoteImageName, imageTag, o.flagRequireAttestations)
 		if err != nil {
 			return err
 		}
@@ -232,36 +494,64 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 	// Resolve Helm chart to use (local or remote).
 	var helmChartPath string
 	var useHelmChartVersion string
+	var helmRegistryClient *registry.Client // Only for OCI chart repositories.
 	if o.flagHelmChartLocalPath != "" {
 		// Use local Helm chart directly.
 		helmChartPath = o.flagHelmChartLocalPath
 		useHelmChartVersion = "local"
 	} else {
 		// Determine the Helm chart repo and version to use.
-		helmChartRepo := coalesceString(project.Config.HelmChartRepository, o.flagHelmChartRepository, "https://charts.metaplay.dev")
+		helmChartRepo := coalesceString(project.Config.HelmChartRepository, o.flagHelmChartRepository, defaultHelmChartRepository)
+		helmRegistryClient, err = newHelmChartRegistryClient(helmChartRepo, dockerCredentials)
+		if err != nil {
+			return err
+		}
 		minChartVersion, _ := version.NewVersion("0.7.0")
-		useHelmChartVersion, err = helmutil.ResolveBestMatchingHelmVersion(helmChartRepo, metaplayGameServerChartName, minChartVersion, chartVersionConstraints)
-		helmChartPath = helmutil.GetHelmChartPath(helmChartRepo, metaplayGameServerChartName, useHelmChartVersion)
+		useHelmChartVersion, helmChartPath, err = resolveHelmChart(project, helmRegistryClient, helmChartRepo, metaplayGameServerChartName, minChartVersion, chartVersionConstraints)
 		if err != nil {
 			return err
 		}
+		if !metaproj.IsExactHelmChartVersion(chartVersionSpecifier) {
+			log.Info().Msgf("Resolved Helm chart version %s to %s", styles.RenderTechnical(chartVersionSpecifier), styles.RenderTechnical(useHelmChartVersion))
+		}
 	}
 	log.Debug().Msgf("Helm chart path: %s", helmChartPath)
 
+	// Check that the chart version is not blocked by the organization's policy.
+	if err := checkPolicyChartVersion(orgPolicy, useHelmChartVersion); err != nil {
+		return err
+	}
+
 	// Resolve Helm values file path relative to current directory.
 	valuesFiles := project.GetServerValuesFiles(envConfig)
 
+	// Resolve the Helm post-renderer, if any (--post-renderer overrides the project config).
+	postRendererPath := coalesceString(o.flagPostRenderer, project.GetServerPostRenderer(envConfig))
+	var postRenderer postrender.PostRenderer
+	if postRendererPath != "" {
+		postRenderer, err = helmutil.NewPostRenderer(postRendererPath)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create a Kubernetes client.
 	kubeCli, err := targetEnv.GetPrimaryKubeClient()
 	if err != nil {
 		return err
 	}
 
+	// Check that the image can run on the environment's nodes.
+	if err := checkDeployImageArchitectures(cmd.Context(), image, envDetails, dockerCredentials, kubeCli); err != nil {
+		return err
+	}
+
 	// Configure Helm.
 	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
 	if err != nil {
 		return fmt.Errorf("failed to initialize Helm config: %v", err)
 	}
+	actionConfig.RegistryClient = helmRegistryClient
 
 	// Determine if there's an existing release deployed.
 	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
@@ -298,88 +588,8 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 		}
 	}
 
-	// For Metaplay-managed environments, check that the local env config (from metaplay-project.yaml)
-	// matches the one from portal.
-	if envConfig.HostingType == portalapi.HostingTypeMetaplayHosted {
-		portalClient := portalapi.NewClient(targetEnv.TokenSet)
-
-		// Fetch info from the portal.
-		portalInfo, err := portalClient.FetchEnvironmentInfoByHumanID(envConfig.HumanID, envConfig.StackDomain)
-		if err != nil {
-			return err
-		}
-
-		// Environment type (prod, staging, development) must match that in the portal.
-		// Otherwise, the game server will be using wrong environment type-specific defaults.
-		if envConfig.Type != portalInfo.Type {
-			return clierrors.Newf("Environment type mismatch: local config has '%s', portal has '%s'", envConfig.Type, portalInfo.Type).
-				WithSuggestion("Run 'metaplay update project-environments' to sync with portal")
-		}
-	}
-
-	// Default shard config based on environment type.
-	// \todo Auto-detect these from the infrastructure.
-	var shardsConfig []map[string]any
-	if envConfig.Type == portalapi.EnvironmentTypeProduction || envConfig.Type == portalapi.EnvironmentTypeStaging {
-		shardsConfig = []map[string]any{
-			{
-				"name":      "all",
-				"singleton": true,
-				"requests": map[string]any{
-					"cpu":    "1000m",
-					"memory": "2000M",
-				},
-			},
-		}
-	} else {
-		shardsConfig = []map[string]any{
-			{
-				"name":      "all",
-				"singleton": true,
-				"requests": map[string]any{
-					"cpu":    "250m",
-					"memory": "500Mi",
-				},
-			},
-		}
-	}
-
-	// Convert shardConfig to []any to avoid JSON schema validation type errors.
-	// This happens because Helm, or https://github.com/santhosh-tekuri/jsonschema where the inputs are validated,
-	// doesn't allow []map[string]any. Its typeOf() function only accepts `[]any` as array types, not other types
-	// of arrays, like []map[string]any.
-	// Bug report in Helm: https://github.com/helm/helm/issues/31148 -- if the issue gets fixed, this code can be removed.
-	untypedShardsConfig := make([]any, len(shardsConfig))
-	for i, v := range shardsConfig {
-		untypedShardsConfig[i] = v
-	}
-
-	// Default Helm values. The user Helm values files are applied on top so
-	// all these values can be overridden by the user.
-	// \todo check for the existence of the runtime options files
-	helmDefaultValues := map[string]any{
-		"environment":       envConfig.Name,
-		"environmentFamily": envConfig.GetEnvironmentFamily(),
-		"config": map[string]any{
-			"files": []any{
-				"./Config/Options.base.yaml",
-				envConfig.GetEnvironmentSpecificRuntimeOptionsFile(),
-			},
-		},
-		"tenant": map[string]any{
-			"discoveryEnabled": true,
-		},
-		"sdk": map[string]any{
-			"version": imageInfo.SdkVersion,
-		},
-		"shards": untypedShardsConfig,
-	}
-	helmRequiredValues := map[string]any{
-		"image": map[string]any{
-			"tag":        imageTag,
-			"repository": envDetails.Deployment.EcrRepo,
-		},
-	}
+	// Default and required Helm values for the game server.
+	helmDefaultValues, helmRequiredValues := gameServerHelmValues(envConfig, imageInfo.SdkVersion, envDetails.Deployment.EcrRepo, deployImageTag)
 
 	// Resolve Helm release name. If not specified, default to:
 	// - Earlier name if a deployment already exists.
@@ -415,12 +625,18 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 	if useLocalImage {
 		log.Info().Msgf("  Image name:         %s", styles.RenderTechnical(o.argImageNameTag))
 	} else {
-		log.Info().Msgf("  Image name:         %s", styles.RenderTechnical(fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageTag)))
+		log.Info().Msgf("  Image name:         %s", styles.RenderTechnical(fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, deployImageTag)))
 	}
 	log.Info().Msgf("  Build number:       %s", styles.RenderTechnical(imageInfo.BuildNumber))
 	log.Info().Msgf("  Commit ID:          %s", styles.RenderTechnical(imageInfo.CommitID))
 	log.Info().Msgf("  Created:            %s", styles.RenderTechnical(humanize.Time(imageInfo.CreatedTime)))
 	log.Info().Msgf("  Metaplay SDK:       %s", styles.RenderTechnical(imageInfo.SdkVersion))
+	if attestations != nil {
+		log.Info().Msgf("  SBOM:               %s", styles.RenderTechnical(describeImageSBOM(attestations.SBOM)))
+		log.Info().Msgf("  Provenance:         %s", styles.RenderTechnical(describeImageProvenance(attestations.Provenance)))
+	} else {
+		log.Info().Msgf("  Attestations:       %s", styles.RenderMuted("verified after pushing the image"))
+	}
 	log.Info().Msg("")
 	log.Info().Msgf("Deployment info:")
 	if o.flagHelmChartLocalPath != "" {
@@ -432,6 +648,9 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 	if len(valuesFiles) > 0 {
 		log.Info().Msgf("  Helm values files:  %s", styles.RenderTechnical(strings.Join(valuesFiles, ", ")))
 	}
+	if postRendererPath != "" {
+		log.Info().Msgf("  Helm post-renderer: %s", styles.RenderTechnical(postRendererPath))
+	}
 	// \todo list of runtime options files
 	// Show current Helm release info if it exists.
 	if existingRelease != nil {
@@ -464,26 +683,144 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 		log.Debug().Msgf("Existing Helm release info: %+v", existingRelease.Info)
 	}
 
-	// If dry-run mode, stop here.
+	// Figure out whether the values file JSON schema can be validated.
+	validateJsonSchema := shouldValidateChartValuesSchema(useHelmChartVersion)
+
+	// Parse extra Helm arguments (--set, --set-string).
+	cliSetValues, err := helmutil.ParseHelmExtraArgs(o.extraArgs)
+	if err != nil {
+		return err
+	}
+
+	// If diff mode, show the changes against the deployed release and stop here.
+	if o.flagDiff {
+		// Releases that would be uninstalled are rendered as fresh installs.
+		renderAgainstRelease := existingRelease
+		if uninstallExistingRelease || uninstallExisting {
+			renderAgainstRelease = nil
+		}
+
+		var newValues map[string]any
+		var newManifest string
+		taskRunner := tui.NewTaskRunner()
+		taskRunner.AddTask("Render Helm chart", func(output *tui.TaskOutput) error {
+			newValues, err = helmutil.ResolveHelmValues(output, valuesFiles, helmDefaultValues, cliSetValues, helmRequiredValues)
+			if err != nil {
+				return err
+			}
+			newManifest, err = helmutil.RenderHelmManifests(output, actionConfig, renderAgainstRelease, envConfig.GetKubernetesNamespace(), helmReleaseName, helmChartPath, useHelmChartVersion, newValues, postRenderer, validateJsonSchema)
+			return err
+		})
+		if err = taskRunner.Run(); err != nil {
+			return err
+		}
+
+		return printDeployDiff(existingRelease, newValues, newManifest)
+	}
+
+	// Plan the canary rollout, if requested.
+	var canary *envapi.CanaryRollout
+	if o.flagStrategy == "canary" {
+		if existingRelease == nil || uninstallExistingRelease || uninstallExisting {
+			return clierrors.New("Canary deployment requires an existing, healthy game server deployment").
+				WithSuggestion("Deploy the game server without --strategy=canary first")
+		}
+		canary, err = targetEnv.NewCanaryRollout(cmd.Context(), o.flagCanaryPercent)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("Canary deployment: %s first, then promote after %s", styles.RenderTechnical(fmt.Sprintf("%d pod(s)", canary.NumCanaryPods())), styles.RenderTechnical(o.flagCanaryDuration.String()))
+		log.Info().Msg("")
+	}
+
+	// If dry-run mode, show the deployment steps and stop here.
 	if o.flagDryRun {
-		log.Info().Msg(styles.RenderMuted("Dry-run mode: skipping deployment"))
+		dryRun := dryRunPlan{}
+		if useLocalImage {
+			dstImageName, needsPush, err := checkDockerImagePushNeeded(o.argImageNameTag, envDetails.Deployment.EcrRepo, dockerCredentials)
+			if err != nil {
+				return err
+			}
+			if needsPush {
+				dryRun.Addf("Push image %s as %s", styles.RenderTechnical(o.argImageNameTag), styles.RenderTechnical(dstImageName))
+			}
+		}
+		if uninstallExistingRelease {
+			dryRun.Addf("Uninstall Helm release %s in pending state '%s'", styles.RenderTechnical(existingRelease.Name), existingRelease.Info.Status)
+		}
+		if uninstallExisting {
+			dryRun.Addf("Uninstall Helm release %s (incompatible chart version)", styles.RenderTechnical(existingRelease.Name))
+		}
+		if canary != nil {
+			dryRun.Addf("Pause rollout of game server shard sets")
+		}
+		helmAction := "Install"
+		if existingRelease != nil && !uninstallExistingRelease && !uninstallExisting {
+			helmAction = "Upgrade"
+		}
+		helmChart := fmt.Sprintf("chart version %s", useHelmChartVersion)
+		if o.flagHelmChartLocalPath != "" {
+			helmChart = fmt.Sprintf("chart %s", helmChartPath)
+		}
+		dryRun.Addf("%s Helm release %s in namespace %s using %s", helmAction, styles.RenderTechnical(helmReleaseName), styles.RenderTechnical(envConfig.GetKubernetesNamespace()), styles.RenderTechnical(helmChart))
+		if canary != nil {
+			dryRun.Addf("Roll out %d canary pod(s) and monitor them for %s", canary.NumCanaryPods(), o.flagCanaryDuration)
+			dryRun.Addf("Promote canary to all game server pods")
+		}
+		if o.flagDetach {
+			dryRun.Addf("Exit without waiting for the game server to be ready")
+		} else {
+			dryRun.Addf("Wait for the game server to be ready")
+		}
+		dryRun.Print()
 		return nil
 	}
 
+	// Report the deployment status to the CI provider, if running in one.
+	var ciStatus cistatus.DeploymentStatusReporter
+	if !o.flagSkipCIStatus && !o.flagDetach {
+		ciStatus = startCIDeploymentStatus(cmd.Context(), cistatus.DeploymentInfo{
+			Environment:    envConfig.HumanID,
+			EnvironmentURL: "https://" + envDetails.Deployment.AdminHostname,
+			IsProduction:   envConfig.Type == portalapi.EnvironmentTypeProduction,
+			Description:    fmt.Sprintf("Deploy %s (build %s)", imageTag, imageInfo.BuildNumber),
+		})
+	}
+
+	// Prepare the deploy report, if requested.
+	var report *deployReport
+	reportDir := coalesceString(o.flagReportDir, os.Getenv("METAPLAYCLI_DEPLOY_REPORT_DIR"))
+	if reportDir != "" {
+		report = &deployReport{
+			Environment: envConfig.HumanID,
+			ImageTag:    imageTag,
+			BuildNumber: imageInfo.BuildNumber,
+			CommitID:    imageInfo.CommitID,
+			HelmRelease: helmReleaseName,
+			Strategy:    o.flagStrategy,
+			StartedAt:   startedAt,
+		}
+	}
+
 	// Use TaskRunner to visualize progress.
 	taskRunner := tui.NewTaskRunner()
 
-	// If using local image, add task to push it.
+	// If using local image, add task to push it. The push doesn't depend on the cluster state,
+	// so it runs in parallel with the uninstall of a pending release.
 	if useLocalImage {
-		taskRunner.AddTask("Push docker image to environment repository", func(output *tui.TaskOutput) error {
-			_, err := pushDockerImage(cmd.Context(), output, o.argImageNameTag, envDetails.Deployment.EcrRepo, dockerCredentials)
+		taskRunner.AddTaskAfter("Push docker image to environment repository", nil, func(output *tui.TaskOutput) error {
+			if _, err := pushDockerImage(cmd.Context(), output, o.argImageNameTag, envDetails.Deployment.EcrRepo, dockerCredentials); err != nil {
+				return err
+			}
+			remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageTag)
+			_, err := checkDeployImageAttestations(dockerCredentials, remoteImageName, imageTag, o.flagRequireAttestations)
 			return err
 		})
 	}
 
 	// If there's a pending release, uninstall it first.
 	if uninstallExistingRelease {
-		taskRunner.AddTask("Uninstall existing Helm release", func(output *tui.TaskOutput) error {
+		taskRunner.AddTaskAfter("Uninstall existing Helm release", nil, func(output *tui.TaskOutput) error {
 			output.SetHeaderLines([]string{
 				fmt.Sprintf("Release status: %s", existingRelease.Info.Status),
 			})
@@ -505,59 +842,20 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 		})
 	}
 
-	// Figure out whether the values file JSON schema can be validated:
-	// - v0.9+ (including v1.x+, v0.10.x+, and prereleases) can be validated.
-	// - v0.8.1+ (including prereleases) can be validated, but v0.8.0 cannot.
-	// - v0.7.x and earlier cannot be validated.
-	// - Local charts are validated (we assume recent versions are used).
-	validateJsonSchema := false
-	if useHelmChartVersion != "local" {
-		chartVersion, err := semver.NewVersion(useHelmChartVersion)
-		if err != nil {
-			log.Warn().Err(err).Msgf("Failed to parse Helm chart version '%s', skipping schema validation", useHelmChartVersion)
-			validateJsonSchema = false
-		} else {
-			major := chartVersion.Major()
-			minor := chartVersion.Minor()
-			patch := chartVersion.Patch()
-
-			if major >= 1 || (major == 0 && minor >= 9) {
-				// v0.9 and later can be validated (including v0.10.x, v1.x.x and later, and v0.9.x-pre versions)
-				validateJsonSchema = true
-			} else if major == 0 && minor == 8 {
-				// For v0.8 series: don't validate for v0.8.0, but do validate for >=v0.8.1 (including pre releases)
-				if patch == 0 {
-					// Exactly v0.8.0 cannot be validated
-					validateJsonSchema = false
-				} else {
-					// v0.8.1+ (including prereleases) can be validated
-					validateJsonSchema = true
-				}
-			} else {
-				// v0.7 and earlier cannot be validated
-				log.Warn().Msgf("Helm chart version '%s' is below minimum supported version, skipping schema validation", useHelmChartVersion)
-				validateJsonSchema = false
-			}
-
-			log.Debug().Msgf("Helm chart version '%s': schema validation %s", useHelmChartVersion,
-				map[bool]string{true: "enabled", false: "disabled"}[validateJsonSchema])
-		}
-	} else {
-		// For local charts, we assume recent versions, and enable validation.
-		// \todo Add flag for disabling this, if needed.
-		log.Debug().Msg("Using local Helm chart, enable schema validation")
-		validateJsonSchema = true
-	}
-
-	// Parse extra Helm arguments (--set, --set-string).
-	cliSetValues, err := helmutil.ParseHelmExtraArgs(o.extraArgs)
-	if err != nil {
-		return err
+	// With canary, prevent the pods from being updated until the canary phase.
+	if canary != nil {
+		taskRunner.AddTask("Pause rollout of game server shard sets", func(output *tui.TaskOutput) error {
+			return canary.Pause(cmd.Context(), output)
+		})
 	}
 
 	// Install or upgrade the Helm chart.
+	helmDeployStarted := false
+	var deployedRelease *release.Release
 	taskRunner.AddTask("Deploy game server using Helm", func(output *tui.TaskOutput) error {
-		_, err := helmutil.HelmUpgradeOrInstall(
+		helmDeployStarted = true
+		var err error
+		deployedRelease, err = helmutil.HelmUpgradeOrInstall(
 			output,
 			actionConfig,
 			existingRelease,
@@ -569,26 +867,432 @@ func (o *deployGameServerOpts) Run(cmd *cobra.Command) error {
 			helmDefaultValues,
 			cliSetValues,
 			helmRequiredValues,
+			postRenderer,
 			5*time.Minute,
+			!o.flagDetach,
 			validateJsonSchema)
 		return err
 	})
 
-	// Validate the game server status.
-	err = targetEnv.WaitForServerToBeReady(cmd.Context(), taskRunner)
-	if err != nil {
-		return err
-	}
+	// With --detach, exit after the Helm release has been applied.
+	if o.flagDetach {
+		err := taskRunner.Run()
+		if report != nil {
+			result := deployResultDetached
+			if err != nil {
+				result = deployResultFailure
+			} else {
+				report.DeployID = formatDeployID(deployedRelease)
+			}
+			completeDeployReport(cmd.Context(), report, reportDir, result, err, taskRunner, kubeCli, envDetails)
+		}
+		if err != nil {
+			return err
+		}
 
-	// Run the tasks.
-	if err = taskRunner.Run(); err != nil {
-		return err
+		deployID := formatDeployID(deployedRelease)
+		log.Info().Msg(styles.RenderSuccess("✅ Game server deployment started!"))
+		log.Info().Msg("")
+		log.Info().Msgf("Deploy ID: %s", styles.RenderTechnical(deployID))
+		log.Info().Msg("")
+		log.Info().Msgf("Wait for the deployment to complete with: %s", styles.RenderPrompt(fmt.Sprintf("metaplay deploy attach %s %s", envConfig.HumanID, deployID)))
+		return nil
+	}
+
+	// With canary, roll out the canary pods, monitor them, and promote to all the pods.
+	canaryPromoted := false
+	if canary != nil {
+		taskRunner.AddTask(fmt.Sprintf("Roll out %d canary pod(s)", canary.NumCanaryPods()), func(output *tui.TaskOutput) error {
+			if err := canary.Start(cmd.Context(), output); err != nil {
+				return err
+			}
+			return canary.WaitForCanaryPodsReady(cmd.Context(), output, 10*time.Minute)
+		})
+
+		var adminClient *metahttp.Client
+		if !o.flagCanarySkipErrors {
+			adminClient = metahttp.NewJSONClient(tokenSet, "https://"+envDetails.Deployment.AdminHostname)
+		}
+		taskRunner.AddTask("Monitor canary pods", func(output *tui.TaskOutput) error {
+			return canary.MonitorCanary(cmd.Context(), output, adminClient, o.flagCanaryDuration, o.flagCanaryMaxErrors)
+		})
+
+		taskRunner.AddTask("Promote canary to all game server pods", func(output *tui.TaskOutput) error {
+			if err := canary.Resume(cmd.Context(), output); err != nil {
+				return err
+			}
+			canaryPromoted = true
+			return nil
+		})
+	}
+
+	// Validate the game server status.
+	err = targetEnv.WaitForServerToBeReady(cmd.Context(), taskRunner)
+	if err != nil {
+		finishCIDeploymentStatus(cmd.Context(), ciStatus, false)
+		return err
+	}
+
+	// Run the tasks.
+	err = taskRunner.Run()
+	finishCIDeploymentStatus(cmd.Context(), ciStatus, err == nil)
+	if report != nil {
+		result := deployResultSuccess
+		if err != nil {
+			result = deployResultFailure
+		} else if deployedRelease != nil {
+			report.DeployID = formatDeployID(deployedRelease)
+		}
+		completeDeployReport(cmd.Context(), report, reportDir, result, err, taskRunner, kubeCli, envDetails)
+	}
+	if err != nil {
+		// If the canary was not promoted, roll back to the previous version.
+		if canary != nil && !canaryPromoted {
+			return rollbackCanaryDeployment(cmd.Context(), canary, actionConfig, helmReleaseName, existingRelease.Version, helmDeployStarted, err)
+		}
+		return err
 	}
 
 	log.Info().Msg(styles.RenderSuccess("✅ Game server successfully deployed!"))
 	return nil
 }
 
+// startCIDeploymentStatus marks the deployment as in progress in the CI provider the CLI is
+// running in, if any. Failures are only logged as the reporting is best-effort. Returns the
+// reporter to finish the deployment with, or nil if not reporting.
+func startCIDeploymentStatus(ctx context.Context, info cistatus.DeploymentInfo) cistatus.DeploymentStatusReporter {
+	reporter := cistatus.DetectDeploymentStatusReporter(info, os.Getenv)
+	if reporter == nil {
+		log.Debug().Msg("Not reporting deployment status: no supported CI provider with credentials detected")
+		return nil
+	}
+
+	if err := reporter.Start(ctx); err != nil {
+		log.Warn().Msgf("Failed to report deployment status to %s: %v", reporter.Provider(), err)
+		return nil
+	}
+	log.Info().Msgf("Reporting deployment status to %s", styles.RenderTechnical(reporter.Provider()))
+	log.Info().Msg("")
+	return reporter
+}
+
+// finishCIDeploymentStatus marks the deployment as succeeded or failed in the CI provider.
+// Does nothing if not reporting. The status is reported even if the context was canceled.
+func finishCIDeploymentStatus(ctx context.Context, reporter cistatus.DeploymentStatusReporter, success bool) {
+	if reporter == nil {
+		return
+	}
+
+	if err := reporter.Finish(context.WithoutCancel(ctx), success); err != nil {
+		log.Warn().Msgf("Failed to report deployment status to %s: %v", reporter.Provider(), err)
+	}
+}
+
+// rollbackCanaryDeployment rolls a failed canary deployment back to the previous Helm release
+// revision and resumes the normal rollout of the shard sets so the canary pods get reverted.
+func rollbackCanaryDeployment(ctx context.Context, canary *envapi.CanaryRollout, actionConfig *action.Configuration, releaseName string, previousRevision int, helmDeployStarted bool, canaryErr error) error {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderAttention(fmt.Sprintf("Canary deployment failed: %v", canaryErr)))
+	log.Info().Msg("")
+
+	taskRunner := tui.NewTaskRunner()
+	if helmDeployStarted {
+		taskRunner.AddTask(fmt.Sprintf("Roll back Helm release to revision %d", previousRevision), func(output *tui.TaskOutput) error {
+			return helmutil.RollbackRelease(actionConfig, releaseName, previousRevision)
+		})
+	}
+	taskRunner.AddTask("Resume rollout of game server shard sets", func(output *tui.TaskOutput) error {
+		return canary.Resume(ctx, output)
+	})
+
+	if err := taskRunner.Run(); err != nil {
+		return clierrors.Wrap(err, "Failed to roll back the canary deployment").
+			WithDetails(fmt.Sprintf("Canary failure: %v", canaryErr)).
+			WithSuggestion(fmt.Sprintf("Check the deployment with 'metaplay deploy status' and roll back manually to revision %d if needed", previousRevision))
+	}
+
+	return clierrors.Wrap(canaryErr, "Canary deployment failed and was rolled back").
+		WithSuggestion("Check the canary pod logs with 'metaplay debug logs'")
+}
+
+// deployImage is a docker image resolved for deploying into an environment.
+type deployImage struct {
+	nameTag string                    // Image name and tag, eg, 'mygame:364cff09', or only the tag for remote images
+	tag     string                    // Image tag only, eg, '364cff09'
+	isLocal bool                      // Is the image in the local docker repository (and needs to be pushed first)?
+	info    *envapi.MetaplayImageInfo // Metadata of the image
+}
+
+// resolveDeployImage resolves the image to deploy from the [IMAGE:]TAG argument:
+// - An empty argument lets the user choose from the local images interactively.
+// - 'latest-local' uses the most recently built local image of the project.
+// - 'IMAGE:TAG' refers to a local image that gets pushed to the environment registry.
+// - 'TAG' refers to an image that already exists in the environment registry. With an
+// 'imageNaming' template in metaplay-project.yaml, a local image with the name resolved from
+// the template is used instead, if it exists and localByTag is set.
+func resolveDeployImage(project *metaproj.MetaplayProject, envDetails *envapi.DeploymentSecret, dockerCredentials *envapi.DockerCredentials, imageNameTag string, localByTag bool) (*deployImage, error) {
+	// If no docker image specified, scan the images matching project from the local docker repo
+	// and then let the user choose from the images.
+	switch imageNameTag {
+	case "":
+		selectedImage, err := selectDockerImageInteractively("Select Image to Deploy", project.Config.ProjectHumanID)
+		if err != nil {
+			return nil, err
+		}
+		imageNameTag = selectedImage.RepoTag
+	case "latest-local":
+		// Resolve the local docker images matching project human ID.
+		localImages, err := envapi.ReadLocalDockerImagesByProjectID(project.Config.ProjectHumanID)
+		if err != nil {
+			return nil, err
+		}
+
+		// If there are no images for this project, error out.
+		if len(localImages) == 0 {
+			return nil, clierrors.Newf("No Docker images matching project '%s' found locally", project.Config.ProjectHumanID).
+				WithSuggestion("Build an image first with 'metaplay build image'")
+		}
+
+		// Use the first entry (they are reverse sorted by creation time).
+		imageNameTag = localImages[0].RepoTag
+	}
+
+	// Resolve image tag and metadata from the local or remote image.
+	if strings.Contains(imageNameTag, ":") {
+		// Resolve metadata from local image.
+		imageInfo, err := envapi.ReadLocalDockerImageMetadata(imageNameTag)
+		if err != nil {
+			return nil, err
+		}
+
+		// Extract the tag part.
+		imageTag, err := extractDockerImageTag(imageNameTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return &deployImage{nameTag: imageNameTag, tag: imageTag, isLocal: true, info: imageInfo}, nil
+	}
+
+	// Use the local image named after the project's template, if built earlier.
+	if localByTag && project != nil && project.Config.ImageNaming != "" {
+		localImageName, err := resolveProjectImageName(project, "server", imageNameTag)
+		if err != nil {
+			return nil, err
+		}
+		imageInfo, err := envapi.ReadLocalDockerImageMetadata(localImageName)
+		if err == nil {
+			log.Info().Msgf("Using local image %s", styles.RenderTechnical(localImageName))
+			return &deployImage{nameTag: localImageName, tag: imageNameTag, isLocal: true, info: imageInfo}, nil
+		}
+		log.Debug().Msgf("No local image %s, using the image from the environment's registry: %v", localImageName, err)
+	}
+
+	// Fetch the image info from the remote docker image.
+	remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageNameTag)
+	imageInfo, err := envapi.FetchRemoteDockerImageMetadata(dockerCredentials, remoteImageName)
+	if err != nil {
+		return nil, clierrors.Newf("Image '%s' not found in the environment's container registry", imageNameTag).
+			WithSuggestion("Push the image first with 'metaplay image push', or specify a local image as IMAGE:TAG").
+			WithDetails(err.Error())
+	}
+
+	return &deployImage{nameTag: imageNameTag, tag: imageNameTag, isLocal: false, info: imageInfo}, nil
+}
+
+// waitForRemoteDeployImage polls the environment's registry until the image tag exists, and points
+// to the expected manifest digest (if given), or the timeout expires. With a zero timeout, the
+// registry is checked only once. Errors from the registry are retried until the timeout, as the
+// registry may be temporarily unavailable while replicating.
+func waitForRemoteDeployImage(ctx context.Context, fetchDigests func() (*envapi.RemoteDockerImageDigests, bool, error), imageTag, expectedDigest string, timeout, pollInterval time.Duration) error {
+	if timeout > 0 {
+		log.Info().Msgf("Waiting for image %s to be available in the environment's registry (timeout %v)...", styles.RenderTechnical(imageTag), timeout)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		digests, exists, err := fetchDigests()
+		var problem string
+		switch {
+		case err != nil:
+			problem = err.Error()
+		case !exists:
+			problem = "the tag does not exist in the registry yet"
+		case expectedDigest != "" && digests.ManifestDigest != expectedDigest:
+			problem = fmt.Sprintf("the tag points to %s instead of the expected digest %s", digests.ManifestDigest, expectedDigest)
+		default:
+			log.Info().Msgf("%s Image %s is available %s", styles.RenderSuccess("✓"), styles.RenderTechnical(imageTag), styles.RenderMuted(fmt.Sprintf("(%s)", digests.ManifestDigest)))
+			return nil
+		}
+		log.Debug().Msgf("Image %s not available yet: %s", imageTag, problem)
+
+		// Give up when the timeout expires.
+		if !time.Now().Add(pollInterval).Before(deadline) {
+			return clierrors.Newf("Image '%s' is not available in the environment's container registry", imageTag).
+				WithDetails(problem).
+				WithSuggestion("Check that the image was pushed successfully, or increase --wait-for-image")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// pinImageTagToDigest returns the image tag pinned to the manifest digest of the digest reference,
+// eg, '364cff09@sha256:1a2b3c' for 'repo@sha256:1a2b3c'. The container runtime pulls the image by
+// the digest, while the tag is kept for humans.
+func pinImageTagToDigest(imageTag, imageDigestRef string) string {
+	_, digest, _ := strings.Cut(imageDigestRef, "@")
+	return fmt.Sprintf("%s@%s", imageTag, digest)
+}
+
+// checkDeployImageSigned checks that the image to deploy, pinned to its digest with imageDigestRef,
+// has a cosign signature in the environment's image repository. Local images are pushed only
+// during the deploy and thus cannot be signed yet.
+func checkDeployImageSigned(image *deployImage, imageDigestRef string, dockerCredentials *envapi.DockerCredentials) error {
+	if image.isLocal {
+		return clierrors.New("Your organization's policy requires deployed images to be signed").
+			WithDetails("Local images are pushed during the deploy and cannot be signed before it.").
+			WithSuggestion("Push and sign the image with 'metaplay image push ENVIRONMENT IMAGE:TAG --sign', and deploy it using its tag")
+	}
+
+	signed, err := envapi.HasRemoteDockerImageSignature(dockerCredentials, imageDigestRef)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to check the signature of image '%s'", image.tag)
+	}
+	if !signed {
+		return clierrors.Newf("Image '%s' is not signed, which is required by your organization's policy", image.tag).
+			WithSuggestion(fmt.Sprintf("Sign the image with 'cosign sign %s', and deploy again", imageDigestRef))
+	}
+	return nil
+}
+
+// checkDeployImageSignatureVerified verifies the cosign signature of the image in the environment's
+// repository against the project's 'imageSigning' config, as required by 'requireSignedImages'.
+func checkDeployImageSignatureVerified(ctx context.Context, dockerCredentials *envapi.DockerCredentials, remoteImageName, imageTag string, signing *metaproj.ImageSigningConfig) error {
+	log.Debug().Msgf("Verify cosign signature of image %s", remoteImageName)
+	if err := verifyRemoteImageSignature(ctx, dockerCredentials, remoteImageName, signing); err != nil {
+		return clierrors.Wrapf(err, "Image '%s' does not have a valid signature, which is required by 'requireSignedImages' in metaplay-project.yaml", imageTag).
+			WithSuggestion(fmt.Sprintf("Push and sign the image with 'metaplay image push ENVIRONMENT IMAGE:%s --sign', and deploy again", imageTag))
+	}
+	return nil
+}
+
+// checkDeployImageAttestations fetches and verifies the attestations of the image in the
+// environment's image repository. If required, both the SBOM and provenance must be present.
+func checkDeployImageAttestations(dockerCredentials *envapi.DockerCredentials, remoteImageName, imageTag string, requireAttestations bool) (*envapi.ImageAttestations, error) {
+	attestations, err := envapi.FetchRemoteDockerImageAttestations(dockerCredentials, remoteImageName)
+	if err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to verify the attestations of image '%s'", imageTag).
+			WithSuggestion("Rebuild the image with 'metaplay build image --sbom --provenance' and push it again")
+	}
+
+	if requireAttestations && (attestations.SBOM == nil || attestations.Provenance == nil) {
+		return nil, clierrors.Newf("Image '%s' is missing SBOM or provenance attestations", imageTag).
+			WithDetails(fmt.Sprintf("SBOM: %s, provenance: %s", describeImageSBOM(attestations.SBOM), describeImageProvenance(attestations.Provenance))).
+			WithSuggestion("Build the image with 'metaplay build image --sbom --provenance'")
+	}
+	return attestations, nil
+}
+
+// describeImageSBOM formats the SBOM attestation summary for humans.
+func describeImageSBOM(sbom *envapi.ImageSBOM) string {
+	if sbom == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%s (%d packages)", sbom.Format, sbom.NumPackages)
+}
+
+// describeImageProvenance formats the provenance attestation summary for humans.
+func describeImageProvenance(provenance *envapi.ImageProvenance) string {
+	if provenance == nil {
+		return "none"
+	}
+	builder := coalesceString(provenance.BuilderID, "unknown builder")
+	return fmt.Sprintf("SLSA %s (%s)", provenance.SlsaVersion, builder)
+}
+
+// checkDeployImageGitState checks that the image is reproducible from its commit: it was not
+// built from a dirty git working tree, and the commit has been pushed to a git remote. Returns
+// an error on issues, or only logs warnings if allowDirty is set.
+func checkDeployImageGitState(ctx context.Context, project *metaproj.MetaplayProject, imageInfo *envapi.MetaplayImageInfo, allowDirty bool) error {
+	// Check whether the commit has been pushed. This is only possible when the local repository
+	// knows the commit, eg, not when deploying an image built elsewhere.
+	var isCommitPushed *bool
+	if imageInfo.CommitID != "" && imageInfo.CommitID != "none" {
+		if pushed, err := isGitCommitPushed(ctx, project.RelativeDir, imageInfo.CommitID); err != nil {
+			log.Debug().Msgf("Unable to check whether commit %s has been pushed: %v", imageInfo.CommitID, err)
+		} else {
+			isCommitPushed = &pushed
+		}
+	}
+
+	issues := getDeployImageGitIssues(imageInfo, isCommitPushed)
+	if len(issues) == 0 {
+		return nil
+	}
+
+	if !allowDirty {
+		return clierrors.New("Refusing to deploy an unreproducible build into a production environment").
+			WithDetails(strings.Join(issues, "\n")).
+			WithSuggestion("Build the image from a clean, pushed commit, or use --allow-dirty to deploy anyway")
+	}
+
+	for _, issue := range issues {
+		log.Warn().Msgf("%s %s (deploying anyway due to --allow-dirty)", styles.RenderWarning("Warning:"), issue)
+	}
+	return nil
+}
+
+// getDeployImageGitIssues returns the reasons why the image cannot be reproduced from its
+// commit. isCommitPushed is nil if it's not known whether the commit has been pushed.
+func getDeployImageGitIssues(imageInfo *envapi.MetaplayImageInfo, isCommitPushed *bool) []string {
+	issues := []string{}
+	if imageInfo.IsDirty {
+		issues = append(issues, fmt.Sprintf("Image was built from a git working tree with uncommitted changes (commit %s)", imageInfo.CommitID))
+	}
+	if isCommitPushed != nil && !*isCommitPushed {
+		issues = append(issues, fmt.Sprintf("Commit %s has not been pushed to a git remote", imageInfo.CommitID))
+	}
+	return issues
+}
+
+// checkDeployImageArchitectures checks that the image includes the CPU architectures of the
+// Kubernetes nodes that the game server runs on, eg, that an image built only for amd64 is not deployed into
+// an arm64 (Graviton) cluster. The check is skipped with a warning if the image platforms or
+// the nodes cannot be resolved, eg, due to missing permissions.
+func checkDeployImageArchitectures(ctx context.Context, image *deployImage, envDetails *envapi.DeploymentSecret, dockerCredentials *envapi.DockerCredentials, kubeCli *envapi.KubeClient) error {
+	var imagePlatforms []string
+	var err error
+	if image.isLocal {
+		imagePlatforms, err = envapi.ReadLocalDockerImagePlatforms(ctx, image.nameTag)
+	} else {
+		imagePlatforms, err = envapi.FetchRemoteDockerImagePlatforms(dockerCredentials, fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, image.tag))
+	}
+	if err != nil {
+		log.Warn().Msgf("Unable to resolve the image platforms, skipping the architecture check: %v", err)
+		return nil
+	}
+
+	nodeArchitectures, err := envapi.GetGameServerNodeArchitectures(ctx, kubeCli)
+	if err != nil {
+		log.Warn().Msgf("Unable to resolve the game server's node architectures, skipping the architecture check: %v", err)
+		return nil
+	}
+	log.Debug().Msgf("Image platforms: %v, node architectures: %v", imagePlatforms, nodeArchitectures)
+
+	missing := envapi.FindMissingImageArchitectures(imagePlatforms, nodeArchitectures)
+	if len(missing) > 0 {
+		return clierrors.Newf("Image '%s' does not support the game server's node architecture: %s", image.nameTag, strings.Join(missing, ", ")).
+			WithDetails(fmt.Sprintf("Image platforms: %s", strings.Join(imagePlatforms, ", "))).
+			WithSuggestion(fmt.Sprintf("Build the image for the environment with 'metaplay build image --architecture=%s'", strings.Join(nodeArchitectures, ",")))
+	}
+	return nil
+}
+
 func selectDockerImageInteractively(title string, projectHumanID string) (*envapi.MetaplayImageInfo, error) {
 	// Resolve the local docker images matching project human ID.
 	localImages, err := envapi.ReadLocalDockerImagesByProjectID(projectHumanID)
@@ -602,63 +1306,21 @@ func selectDockerImageInteractively(title string, projectHumanID string) (*envap
 			WithSuggestion("Build an image first with 'metaplay build image'")
 	}
 
-	// Precompute per-image columns and track max widths.
-	type imageColumns struct {
-		age, sdk, commit string
-	}
-	columns := make([]imageColumns, len(localImages))
-	maxTagLen := len("TAG")
-	maxAgeLen := len("AGE")
-	maxSdkLen := len("SDK")
-	maxCommitLen := len("COMMIT")
-	for i := range localImages {
-		img := &localImages[i]
-		col := imageColumns{age: humanize.Time(img.CreatedTime)}
-		if img.SdkVersion != "" {
-			col.sdk = img.SdkVersion
-		}
-		if img.CommitID != "" {
-			col.commit = img.CommitID
-			if len(col.commit) > 12 {
-				col.commit = col.commit[:12]
-			}
-		}
-		columns[i] = col
-		if len(img.RepoTag) > maxTagLen {
-			maxTagLen = len(img.RepoTag)
-		}
-		if len(col.age) > maxAgeLen {
-			maxAgeLen = len(col.age)
-		}
-		if len(col.sdk) > maxSdkLen {
-			maxSdkLen = len(col.sdk)
-		}
-		if len(col.commit) > maxCommitLen {
-			maxCommitLen = len(col.commit)
-		}
-	}
-
-	// Build a header row aligned with the list items.
-	// Note: the first gap is 1 space to match the single space between name and description in list items.
-	header := fmt.Sprintf("%-*s %-*s  %-*s  %-*s", maxTagLen, "TAG", maxAgeLen, "AGE", maxSdkLen, "SDK", maxCommitLen, "COMMIT")
-
 	// Let the user choose from the list of images.
-	selectedImage, err := tui.ChooseFromListDialogWithHeader(
+	selectedImage, err := tui.ChooseFromTableDialog(
 		title,
-		header,
+		[]string{"TAG", "SIZE", "AGE", "SDK", "COMMIT"},
 		localImages,
-		func(img *envapi.MetaplayImageInfo) (string, string) {
-			i := -1
-			for j := range localImages {
-				if &localImages[j] == img {
-					i = j
-					break
-				}
+		func(img *envapi.MetaplayImageInfo) []string {
+			size := ""
+			if img.Size > 0 {
+				size = humanize.Bytes(uint64(img.Size))
+			}
+			commit := img.CommitID
+			if len(commit) > 12 {
+				commit = commit[:12]
 			}
-			col := columns[i]
-			name := fmt.Sprintf("%-*s", maxTagLen, img.RepoTag)
-			description := fmt.Sprintf("%-*s  %-*s  %-*s", maxAgeLen, col.age, maxSdkLen, col.sdk, maxCommitLen, col.commit)
-			return name, description
+			return []string{img.RepoTag, size, humanize.Time(img.CreatedTime), img.SdkVersion, commit}
 		})
 	if err != nil {
 		return nil, err
@@ -668,6 +1330,140 @@ func selectDockerImageInteractively(title string, projectHumanID string) (*envap
 	return selectedImage, nil
 }
 
+// newHelmChartRegistryClient creates the Helm registry client for an OCI chart repository, or
+// returns nil for other repositories. The environment's docker credentials are used when the
+// charts are in the environment's own registry, and the credentials from 'helm registry login'
+// or 'docker login' otherwise, so the environment's credentials are never sent elsewhere.
+func newHelmChartRegistryClient(helmChartRepo string, dockerCredentials *envapi.DockerCredentials) (*registry.Client, error) {
+	if !helmutil.IsOCIChartRepository(helmChartRepo) {
+		return nil, nil
+	}
+
+	chartRegistryHost, err := helmutil.GetOCIRegistryHost(helmChartRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	username, password := "", ""
+	if dockerCredentials != nil && getRegistryHost(dockerCredentials.RegistryURL) == chartRegistryHost {
+		log.Debug().Msgf("Using the environment's docker credentials for the OCI chart repository %s", chartRegistryHost)
+		username, password = dockerCredentials.Username, dockerCredentials.Password
+	}
+	return helmutil.NewOCIRegistryClient(username, password)
+}
+
+// getRegistryHost returns the host part of a docker registry URL, eg, 'registry.example.com'
+// for 'https://registry.example.com/'.
+func getRegistryHost(registryURL string) string {
+	if parsedURL, err := url.Parse(registryURL); err == nil && parsedURL.Host != "" {
+		return parsedURL.Host
+	}
+	return strings.TrimSuffix(registryURL, "/")
+}
+
+// resolveHelmChart resolves the version and path of the Helm chart to deploy. The charts vendored
+// into the project's local charts directory (localChartsDir) are preferred when one of them matches
+// the version constraints, so that deploys are locked to them and work offline. Otherwise, the chart
+// is resolved from the chart repository. Chart versions older than minChartVersion are never used.
+func resolveHelmChart(project *metaproj.MetaplayProject, registryClient *registry.Client, helmChartRepo, chartName string, minChartVersion *version.Version, chartVersionConstraints version.Constraints) (string, string, error) {
+	// Prefer the vendored charts, if any match.
+	localChartsDir := project.GetLocalChartsDir()
+	if localChartsDir != "" {
+		chartVersion, chartPath, err := helmutil.ResolveBestMatchingLocalHelmChart(localChartsDir, chartName, minChartVersion, chartVersionConstraints)
+		if err == nil {
+			log.Info().Msgf("Using vendored chart %s v%s from %s", chartName, chartVersion, localChartsDir)
+			return chartVersion, chartPath, nil
+		}
+		log.Debug().Msgf("No matching vendored %s chart, resolving from %s: %v", chartName, helmChartRepo, err)
+	}
+
+	stopSlowHint := tui.WarnIfSlow(&tui.SlowHint{
+		Threshold: 20 * time.Second,
+		Message:   fmt.Sprintf("Fetching the Helm chart index from %s is slow. If you use a chart repository mirror, check its health, or vendor the charts into the project with 'metaplay charts vendor'.", helmChartRepo),
+	})
+	chartVersion, err := helmutil.ResolveBestMatchingHelmVersion(registryClient, helmChartRepo, chartName, minChartVersion, chartVersionConstraints)
+	stopSlowHint()
+	if err != nil {
+		suggestion := "To deploy when the chart repository is unavailable, vendor the charts into the project with 'metaplay charts vendor'"
+		if localChartsDir != "" {
+			suggestion = fmt.Sprintf("Add a matching '%s-<version>.tgz' chart archive to %s with 'metaplay charts vendor'", chartName, localChartsDir)
+		}
+		return "", "", clierrors.Wrapf(err, "Failed to resolve the %s Helm chart from %s", chartName, helmChartRepo).
+			WithSuggestion(suggestion)
+	}
+	return chartVersion, helmutil.GetHelmChartPath(helmChartRepo, chartName, chartVersion), nil
+}
+
+// gameServerHelmValues returns the default Helm values for deploying the game server into the
+// environment, and the values that are required to be used as-is. The user Helm values files are
+// applied on top of the default values.
+func gameServerHelmValues(envConfig *metaproj.ProjectEnvironmentConfig, sdkVersion, imageRepository, imageTag string) (map[string]any, map[string]any) {
+	// Default shard config based on environment type.
+	// \todo Auto-detect these from the infrastructure.
+	var shardsConfig []map[string]any
+	if envConfig.Type == portalapi.EnvironmentTypeProduction || envConfig.Type == portalapi.EnvironmentTypeStaging {
+		shardsConfig = []map[string]any{
+			{
+				"name":      "all",
+				"singleton": true,
+				"requests": map[string]any{
+					"cpu":    "1000m",
+					"memory": "2000M",
+				},
+			},
+		}
+	} else {
+		shardsConfig = []map[string]any{
+			{
+				"name":      "all",
+				"singleton": true,
+				"requests": map[string]any{
+					"cpu":    "250m",
+					"memory": "500Mi",
+				},
+			},
+		}
+	}
+
+	// Convert shardConfig to []any to avoid JSON schema validation type errors.
+	// This happens because Helm, or https://github.com/santhosh-tekuri/jsonschema where the inputs are validated,
+	// doesn't allow []map[string]any. Its typeOf() function only accepts `[]any` as array types, not other types
+	// of arrays, like []map[string]any.
+	// Bug report in Helm: https://github.com/helm/helm/issues/31148 -- if the issue gets fixed, this code can be removed.
+	untypedShardsConfig := make([]any, len(shardsConfig))
+	for i, v := range shardsConfig {
+		untypedShardsConfig[i] = v
+	}
+
+	// Default Helm values. The user Helm values files are applied on top so
+	// all these values can be overridden by the user.
+	// \todo check for the existence of the runtime options files
+	helmDefaultValues := map[string]any{
+		"environment":       envConfig.Name,
+		"environmentFamily": envConfig.GetEnvironmentFamily(),
+		"config": map[string]any{
+			"files": []any{
+				"./Config/Options.base.yaml",
+				envConfig.GetEnvironmentSpecificRuntimeOptionsFile(),
+			},
+		},
+		"tenant": map[string]any{
+			"discoveryEnabled": true,
+		},
+		"sdk": map[string]any{
+			"version": sdkVersion,
+		},
+		"shards": untypedShardsConfig,
+	}
+	helmRequiredValues := map[string]any{
+		"image": map[string]any{
+			"tag":        imageTag,
+			"repository": imageRepository,
+		},
+	}
+	return helmDefaultValues, helmRequiredValues
+}
+
 // Return the first non-empty string in the provided arguments.
 func coalesceString(values ...string) string {
 	for _, value := range values {
@@ -677,3 +1473,52 @@ func coalesceString(values ...string) string {
 	}
 	return ""
 }
+
+// shouldValidateChartValuesSchema figures out whether the values file JSON schema can be validated
+// with the given chart version:
+// - v0.9+ (including v1.x+, v0.10.x+, and prereleases) can be validated.
+// - v0.8.1+ (including prereleases) can be validated, but v0.8.0 cannot.
+// - v0.7.x and earlier cannot be validated.
+// - Local charts are validated (we assume recent versions are used).
+func shouldValidateChartValuesSchema(chartVersionStr string) bool {
+	validateJsonSchema := false
+	if chartVersionStr != "local" {
+		chartVersion, err := semver.NewVersion(chartVersionStr)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to parse Helm chart version '%s', skipping schema validation", chartVersionStr)
+			validateJsonSchema = false
+		} else {
+			major := chartVersion.Major()
+			minor := chartVersion.Minor()
+			patch := chartVersion.Patch()
+
+			if major >= 1 || (major == 0 && minor >= 9) {
+				// v0.9 and later can be validated (including v0.10.x, v1.x.x and later, and v0.9.x-pre versions)
+				validateJsonSchema = true
+			} else if major == 0 && minor == 8 {
+				// For v0.8 series: don't validate for v0.8.0, but do validate for >=v0.8.1 (including pre releases)
+				if patch == 0 {
+					// Exactly v0.8.0 cannot be validated
+					validateJsonSchema = false
+				} else {
+					// v0.8.1+ (including prereleases) can be validated
+					validateJsonSchema = true
+				}
+			} else {
+				// v0.7 and earlier cannot be validated
+				log.Warn().Msgf("Helm chart version '%s' is below minimum supported version, skipping schema validation", chartVersionStr)
+				validateJsonSchema = false
+			}
+
+			log.Debug().Msgf("Helm chart version '%s': schema validation %s", chartVersionStr,
+				map[bool]string{true: "enabled", false: "disabled"}[validateJsonSchema])
+		}
+	} else {
+		// For local charts, we assume recent versions, and enable validation.
+		// \todo Add flag for disabling this, if needed.
+		log.Debug().Msg("Using local Helm chart, enable schema validation")
+		validateJsonSchema = true
+	}
+
+	return validateJsonSchema
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDeployImageGitIssues(t *testing.T) {
+	pushed := true
+	notPushed := false
+
+	// Clean image from a pushed commit.
+	cleanImage := &envapi.MetaplayImageInfo{CommitID: "1a27c25753"}
+	assert.Empty(t, getDeployImageGitIssues(cleanImage, &pushed))
+
+	// Unknown push state is not an issue, eg, for images built elsewhere.
+	assert.Empty(t, getDeployImageGitIssues(cleanImage, nil))
+
+	// Unpushed commit.
+	issues := getDeployImageGitIssues(cleanImage, &notPushed)
+	assert.Len(t, issues, 1)
+	assert.Contains(t, issues[0], "has not been pushed")
+
+	// Dirty working tree and unpushed commit.
+	dirtyImage := &envapi.MetaplayImageInfo{CommitID: "1a27c25753", IsDirty: true}
+	issues = getDeployImageGitIssues(dirtyImage, &notPushed)
+	assert.Len(t, issues, 2)
+	assert.Contains(t, issues[0], "uncommitted changes")
+}
+
+func TestDescribeImageAttestations(t *testing.T) {
+	assert.Equal(t, "none", describeImageSBOM(nil))
+	assert.Equal(t, "SPDX-2.3 (42 packages)", describeImageSBOM(&envapi.ImageSBOM{Format: "SPDX-2.3", NumPackages: 42}))
+
+	assert.Equal(t, "none", describeImageProvenance(nil))
+	assert.Equal(t, "SLSA v0.2 (unknown builder)", describeImageProvenance(&envapi.ImageProvenance{SlsaVersion: "v0.2"}))
+	assert.Equal(t, "SLSA v1 (https://github.com/metaplay/game/actions/runs/1)", describeImageProvenance(&envapi.ImageProvenance{SlsaVersion: "v1", BuilderID: "https://github.com/metaplay/game/actions/runs/1"}))
+}
+
+func TestPinImageTagToDigest(t *testing.T) {
+	assert.Equal(t, "364cff09@sha256:1a2b3c", pinImageTagToDigest("364cff09", "123456789.dkr.ecr.eu-west-1.amazonaws.com/mygame@sha256:1a2b3c"))
+}
+
+func TestWaitForRemoteDeployImage(t *testing.T) {
+	const digest = "sha256:4f1c0a"
+
+	// Image appears after a couple of polls, with a transient error in between.
+	numCalls := 0
+	fetchDigests := func() (*envapi.RemoteDockerImageDigests, bool, error) {
+		numCalls++
+		switch numCalls {
+		case 1:
+			return nil, false, nil
+		case 2:
+			return nil, false, errors.New("registry unavailable")
+		default:
+			return &envapi.RemoteDockerImageDigests{ManifestDigest: digest}, true, nil
+		}
+	}
+	err := waitForRemoteDeployImage(context.Background(), fetchDigests, "364cff09", digest, time.Minute, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, numCalls)
+
+	// Without a timeout, the registry is checked only once.
+	numCalls = 0
+	err = waitForRemoteDeployImage(context.Background(), fetchDigests, "364cff09", "", 0, time.Millisecond)
+	assert.ErrorContains(t, err, "is not available")
+	assert.Equal(t, 1, numCalls)
+
+	// Tag pointing to another digest times out.
+	otherDigest := func() (*envapi.RemoteDockerImageDigests, bool, error) {
+		return &envapi.RemoteDockerImageDigests{ManifestDigest: "sha256:0000"}, true, nil
+	}
+	err = waitForRemoteDeployImage(context.Background(), otherDigest, "364cff09", digest, 10*time.Millisecond, time.Millisecond)
+	assert.ErrorContains(t, err, "is not available")
+
+	// Canceling the context stops waiting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	notFound := func() (*envapi.RemoteDockerImageDigests, bool, error) { return nil, false, nil }
+	err = waitForRemoteDeployImage(ctx, notFound, "364cff09", "", time.Minute, time.Millisecond)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewHelmChartRegistryClient(t *testing.T) {
+	// No registry client for http(s) chart repositories.
+	client, err := newHelmChartRegistryClient("https://charts.metaplay.dev", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+
+	creds := &envapi.DockerCredentials{Username: "AWS", Password: "secret", RegistryURL: "https://123456789.dkr.ecr.eu-west-1.amazonaws.com"}
+	client, err = newHelmChartRegistryClient("oci://123456789.dkr.ecr.eu-west-1.amazonaws.com/charts", creds)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+
+	assert.Equal(t, "123456789.dkr.ecr.eu-west-1.amazonaws.com", getRegistryHost(creds.RegistryURL))
+	assert.Equal(t, "registry.example.com", getRegistryHost("registry.example.com/"))
+}
+
+func TestResolveHelmChartPrefersVendoredCharts(t *testing.T) {
+	projectDir := t.TempDir()
+	chartsDir := filepath.Join(projectDir, "charts")
+	require.NoError(t, os.MkdirAll(chartsDir, 0755))
+	for _, name := range []string{"metaplay-gameserver-0.8.2.tgz", "metaplay-gameserver-0.8.3.tgz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(chartsDir, name), []byte{}, 0644))
+	}
+	project := &metaproj.MetaplayProject{RelativeDir: projectDir, Config: metaproj.ProjectConfig{LocalChartsDir: "charts"}}
+
+	// The matching vendored chart is used without accessing the (unreachable) repository.
+	constraints, err := metaproj.ParseHelmChartVersionConstraints(">=0.8.0 <0.9.0")
+	require.NoError(t, err)
+	chartVersion, chartPath, err := resolveHelmChart(project, nil, "http://127.0.0.1:1", metaplayGameServerChartName, nil, constraints)
+	require.NoError(t, err)
+	assert.Equal(t, "0.8.3", chartVersion)
+	assert.Equal(t, filepath.Join(chartsDir, "metaplay-gameserver-0.8.3.tgz"), chartPath)
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Number of recent deployments to show in 'deploy status'.
+const deployStatusNumRecentDeploys = 5
+
+type deployStatusOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagFormat     string
+}
+
+// deployStatusInfo is the status report of the game server deployment.
+type deployStatusInfo struct {
+	HelmRelease   *helmReleaseInfo        `json:"helm_release"`
+	ImageInfo     *deploymentImageInfo    `json:"image_info"`
+	ShardSets     []envapi.ShardSetStatus `json:"shard_sets"`
+	RecentDeploys []helmRevisionInfo      `json:"recent_deploys"`
+}
+
+func init() {
+	o := deployStatusOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "status ENVIRONMENT [flags]",
+		Short: "Show the status of the game server deployment in the target environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show the status of the game server deployment in the target environment.
+
+			The report includes:
+			- The current Helm release and chart version.
+			- The deployed docker image tag and its build information (commit, build number, SDK version).
+			- The phase of each game server pod, grouped by shard set.
+			- The most recent deployments with their timestamps.
+
+			Unlike 'metaplay debug server-status', this command does not wait for the deployment
+			to become healthy and only reports its current state.
+
+			By default, the report is shown in a human-readable text format. Use --format=json to
+			get the report in JSON format.
+			WARNING: The JSON output is subject to change!
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy history ...' to show all the recent deployments.
+			- 'metaplay debug server-status ...' to check the health of the deployment.
+			- 'metaplay deploy server ...' to deploy a game server.
+		`),
+		Example: renderExample(`
+			# Show the status of the game server deployment in environment nimbly.
+			metaplay deploy status nimbly
+
+			# Output the status as JSON.
+			metaplay deploy status nimbly --format=json
+		`),
+	}
+	deployCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *deployStatusOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *deployStatusOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Create a Kubernetes client.
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+
+	// Find the game server release.
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+
+	// Gather the status report.
+	status := deployStatusInfo{
+		ShardSets:     []envapi.ShardSetStatus{},
+		RecentDeploys: []helmRevisionInfo{},
+	}
+	if existingRelease != nil {
+		status.HelmRelease, err = getHelmReleaseInfo(existingRelease)
+		if err != nil {
+			return err
+		}
+
+		// Image info is not available for legacy charts, so only warn about failures.
+		status.ImageInfo, err = getDeployedImageInfo(ctx, targetEnv, existingRelease)
+		if err != nil {
+			log.Warn().Msgf("Unable to resolve deployed image info: %v", err)
+		}
+
+		releases, err := helmutil.GetReleaseHistory(actionConfig, existingRelease.Name, deployStatusNumRecentDeploys)
+		if err != nil {
+			return err
+		}
+		status.RecentDeploys = getHelmRevisionInfos(releases)
+	}
+
+	status.ShardSets, err = envapi.FetchGameServerShardSetStatuses(ctx, kubeCli)
+	if err != nil {
+		return err
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		statusJSON, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal deployment status as JSON")
+		}
+		log.Info().Msg(string(statusJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Game Server Deployment Status"))
+	log.Info().Msg("")
+	log.Info().Msgf("Target environment:")
+	log.Info().Msgf("  %-19s %s", "Name:", styles.RenderTechnical(envConfig.Name))
+	log.Info().Msgf("  %-19s %s", "ID:", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("  %-19s %s", "Type:", styles.RenderTechnical(string(envConfig.Type)))
+	log.Info().Msgf("  %-19s %s", "Stack domain:", styles.RenderTechnical(envConfig.StackDomain))
+	log.Info().Msg("")
+
+	if status.HelmRelease == nil {
+		log.Info().Msg(styles.RenderAttention("No game server deployment found in the environment."))
+		log.Info().Msg("")
+		return nil
+	}
+
+	log.Info().Msg("Helm release:")
+	log.Info().Msgf("  %-19s %s", "Release name:", styles.RenderTechnical(status.HelmRelease.Name))
+	log.Info().Msgf("  %-19s %s", "Chart version:", styles.RenderTechnical(status.HelmRelease.ChartVersion))
+	log.Info().Msgf("  %-19s %s", "Status:", styles.RenderTechnical(status.HelmRelease.Status))
+	log.Info().Msgf("  %-19s %s", "Revision:", styles.RenderTechnical(fmt.Sprintf("%d", status.HelmRelease.Revision)))
+	if !status.HelmRelease.LastDeployed.IsZero() {
+		log.Info().Msgf("  %-19s %s", "Last deployed:", styles.RenderTechnical(humanize.Time(status.HelmRelease.LastDeployed)))
+	}
+	log.Info().Msg("")
+
+	log.Info().Msg("Image information:")
+	if status.ImageInfo != nil {
+		log.Info().Msgf("  %-19s %s", "Image tag:", styles.RenderTechnical(status.ImageInfo.ImageTag))
+		log.Info().Msgf("  %-19s %s", "Commit ID:", styles.RenderTechnical(status.ImageInfo.CommitID))
+		log.Info().Msgf("  %-19s %s", "Build number:", styles.RenderTechnical(status.ImageInfo.BuildNumber))
+		log.Info().Msgf("  %-19s %s", "SDK version:", styles.RenderTechnical(status.ImageInfo.SdkVersion))
+		log.Info().Msgf("  %-19s %s", "Created:", styles.RenderTechnical(humanize.Time(status.ImageInfo.CreationTime)))
+	} else {
+		log.Info().Msgf("  %-19s %s", "Image tag:", styles.RenderTechnical(coalesceString(getReleaseImageTag(existingRelease), "<not available>")))
+	}
+	log.Info().Msg("")
+
+	log.Info().Msg("Game server pods:")
+	if len(status.ShardSets) == 0 {
+		log.Info().Msg("  No shard sets found")
+	}
+	for _, shardSet := range status.ShardSets {
+		log.Info().Msgf("  ShardSet '%s' pods (%d):", shardSet.Name, len(shardSet.Pods))
+		for _, pod := range shardSet.Pods {
+			log.Info().Msgf("    %s: %s %s", pod.Name, renderPodPhase(pod.Phase), styles.RenderMuted(fmt.Sprintf("[%s]", pod.Message)))
+		}
+	}
+	log.Info().Msg("")
+
+	log.Info().Msg("Recent deployments:")
+	renderHelmRevisions(status.RecentDeploys)
+	log.Info().Msg("")
+
+	return nil
+}
+
+// renderPodPhase renders the pod phase with a color matching its health.
+func renderPodPhase(phase envapi.GameServerPodPhase) string {
+	switch phase {
+	case envapi.PhaseReady:
+		return styles.RenderSuccess(string(phase))
+	case envapi.PhaseFailed:
+		return styles.RenderError(string(phase))
+	default:
+		return styles.RenderWarning(string(phase))
+	}
+}
@@ -6,6 +6,8 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"time"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/envapi"
@@ -14,11 +16,35 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Bot load options passed to the BotClient on the command line. Zero values are not passed,
+// so the BotClient's own defaults apply.
+type botClientLoadOptions struct {
+	MaxBots         int           // Maximum number of concurrent bots
+	SpawnRate       float64       // Number of bots spawned per second
+	SessionDuration time.Duration // Expected duration of each bot session
+}
+
+// toArgs returns the BotClient command line arguments for the load options.
+func (opts botClientLoadOptions) toArgs() []string {
+	args := []string{}
+	if opts.MaxBots > 0 {
+		args = append(args, fmt.Sprintf("-MaxBots=%d", opts.MaxBots))
+	}
+	if opts.SpawnRate > 0 {
+		args = append(args, "-SpawnRate="+strconv.FormatFloat(opts.SpawnRate, 'f', -1, 64))
+	}
+	if opts.SessionDuration > 0 {
+		args = append(args, "-ExpectedSessionDuration="+formatDotnetTimeSpan(opts.SessionDuration))
+	}
+	return args
+}
+
 type devBotClientOpts struct {
 	UsePositionalArgs
 
 	extraArgs       []string
 	flagEnvironment string
+	flagLoad        botClientLoadOptions
 }
 
 func init() {
@@ -34,6 +60,13 @@ func init() {
 		Long: renderLong(&o, `
 			Run simulated bots against the locally running server, or a cloud environment.
 
+			The BotClient project is first built and then run with 'dotnet run'. The number of bots
+			and their behavior can be controlled with --max-bots, --spawn-rate, and
+			--session-duration. The BotClient's own defaults are used for the ones not specified.
+
+			Developer-specific environment variables, eg, secrets, can be defined under 'env' and
+			'botClient' in the project's .metaplay/env.local.yaml (which should be gitignored).
+
 			{Arguments}
 
 			Related commands:
@@ -48,6 +81,9 @@ func init() {
 			# Run bots against the 'nimbly' cloud environment.
 			metaplay dev botclient -e nimbly
 
+			# Run up to 50 bots, spawning 5 bots per second, with 2-minute sessions.
+			metaplay dev botclient --max-bots 50 --spawn-rate 5 --session-duration 2m
+
 			# Pass additional arguments to 'dotnet run' of the BotClient project.
 			metaplay dev botclient -- -MaxBots=5 -MaxBotId=20
 		`),
@@ -57,9 +93,21 @@ func init() {
 
 	flags := cmd.Flags()
 	flags.StringVarP(&o.flagEnvironment, "environment", "e", "", "Environment (from metaplay-project.yaml) to run the bots against.")
+	flags.IntVar(&o.flagLoad.MaxBots, "max-bots", 0, "Maximum number of concurrent bots (default: BotClient default)")
+	flags.Float64Var(&o.flagLoad.SpawnRate, "spawn-rate", 0, "Number of bots to spawn per second (default: BotClient default)")
+	flags.DurationVar(&o.flagLoad.SessionDuration, "session-duration", 0, "Expected duration of each bot session, eg, 30s or 5m (default: BotClient default)")
 }
 
 func (o *devBotClientOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagLoad.MaxBots < 0 {
+		return clierrors.NewUsageError("--max-bots must not be negative")
+	}
+	if o.flagLoad.SpawnRate < 0 {
+		return clierrors.NewUsageError("--spawn-rate must not be negative")
+	}
+	if o.flagLoad.SessionDuration < 0 {
+		return clierrors.NewUsageError("--session-duration must not be negative")
+	}
 	return nil
 }
 
@@ -108,6 +156,13 @@ func (o *devBotClientOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Inject the developer-specific environment variables from .metaplay/env.local.yaml.
+	localEnv, err := loadLocalEnvFile(ctx, project)
+	if err != nil {
+		return err
+	}
+	botClientEnv := append(slicesClone(commonDotnetEnvVars), formatEnvVars(localEnv.BotClientEnv())...)
+
 	// Resolve botclient path.
 	botClientPath := project.GetBotClientDir()
 
@@ -119,8 +174,9 @@ func (o *devBotClientOpts) Run(cmd *cobra.Command) error {
 
 	// Run the project without rebuilding
 	botRunFlags := append([]string{"run", "--no-build"}, targetEnvFlags...)
+	botRunFlags = append(botRunFlags, o.flagLoad.toArgs()...)
 	botRunFlags = append(botRunFlags, o.extraArgs...)
-	if err := execChildInteractive(ctx, botClientPath, "dotnet", botRunFlags, commonDotnetEnvVars); err != nil {
+	if err := execChildInteractive(ctx, botClientPath, "dotnet", botRunFlags, botClientEnv); err != nil {
 		return clierrors.Wrap(err, "BotClient exited with error")
 	}
 
@@ -0,0 +1,23 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotClientLoadOptionsToArgs(t *testing.T) {
+	// Zero values use the BotClient defaults.
+	assert.Empty(t, botClientLoadOptions{}.toArgs())
+
+	args := botClientLoadOptions{MaxBots: 10, SpawnRate: 0.5, SessionDuration: 90 * time.Second}.toArgs()
+	assert.Equal(t, []string{"-MaxBots=10", "-SpawnRate=0.5", "-ExpectedSessionDuration=00:01:30"}, args)
+
+	args = botClientLoadOptions{SpawnRate: 2}.toArgs()
+	assert.Equal(t, []string{"-SpawnRate=2"}, args)
+}
@@ -5,16 +5,27 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
+// Address of the locally running game server's admin API.
+const localAdminAPIBaseURL = "http://localhost:5550"
+
 type devDashboardOpts struct {
 	UsePositionalArgs
 
-	extraArgs []string
+	extraArgs       []string
+	flagEnvironment string
+	flagInstall     bool
 }
 
 func init() {
@@ -24,13 +35,54 @@ func init() {
 	args.SetExtraArgs(&o.extraArgs, "Passed as-is to 'pnpm dev'.")
 
 	cmd := &cobra.Command{
-		Use:     "dashboard",
+		Use:     "dashboard [flags] [-- EXTRA_ARGS]",
 		Aliases: []string{"dash"},
 		Short:   "Run the dashboard Vue.js project locally in development mode",
 		Run:     runCommand(&o),
+		Long: renderLong(&o, `
+			Run the LiveOps Dashboard locally in development mode, with hot reloading of changes.
+
+			The project's custom dashboard is used if it has one (see 'metaplay init dashboard'),
+			otherwise the SDK's default dashboard in MetaplaySDK/Frontend/DefaultDashboard/ is run.
+
+			This command:
+			- Verifies that the required Node.js and pnpm versions are installed
+			- Installs the dashboard dependencies with 'pnpm install' if they are missing
+			- Runs 'pnpm dev' which serves the dashboard on http://localhost:5551
+
+			The dashboard dev server proxies the API requests to the locally running game server
+			(see 'metaplay dev server'). With --environment, the requests are proxied to the game
+			server in the given cloud environment instead. The proxy target and your access token
+			are passed to the dev server in the METAPLAY_DASHBOARD_PROXY_TARGET and
+			METAPLAY_ACCESS_TOKEN environment variables.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay dev server' runs the game server locally.
+			- 'metaplay build dashboard' builds the custom dashboard.
+			- 'metaplay dev clean-dashboard-artifacts' removes dashboard build artifacts.
+		`),
+		Example: renderExample(`
+			# Run the dashboard against the locally running game server.
+			metaplay dev dashboard
+
+			# Run the dashboard against the game server in the 'nimbly' cloud environment.
+			metaplay dev dashboard --environment nimbly
+
+			# Re-install the dependencies before running the dashboard.
+			metaplay dev dashboard --install
+
+			# Pass extra arguments to 'pnpm dev'.
+			metaplay dev dashboard -- --port 5560
+		`),
 	}
 
 	devCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagEnvironment, "environment", "e", "", "Environment (from metaplay-project.yaml) to proxy the API requests to (default: local game server)")
+	flags.BoolVar(&o.flagInstall, "install", false, "Always run 'pnpm install', even if the dependencies are already installed")
 }
 
 func (o *devDashboardOpts) Prepare(cmd *cobra.Command, args []string) error {
@@ -44,33 +96,56 @@ func (o *devDashboardOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	// Check that project uses a custom dashboard, otherwise error out
-	if !project.UsesCustomDashboard() {
-		return clierrors.New("Project does not have a custom dashboard to run")
+	// Resolve the dashboard to run: the project's custom dashboard or the SDK default one.
+	dashboardPath, err := resolveDevDashboardDir(project)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+
+	// Resolve the game server to proxy the API requests to.
+	proxyTarget := localAdminAPIBaseURL
+	accessToken := ""
+	if o.flagEnvironment != "" {
+		envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.flagEnvironment)
+		if err != nil {
+			return err
+		}
+		proxyTarget = getAdminAPIBaseURL(envConfig)
+		accessToken = tokenSet.AccessToken
 	}
 
 	log.Info().Msg("")
 	log.Info().Msg(styles.RenderTitle("Run LiveOps Dashboard Locally"))
 	log.Info().Msg("")
+	log.Info().Msgf("Dashboard:    %s", styles.RenderTechnical(filepath.ToSlash(dashboardPath)))
+	log.Info().Msgf("Proxy target: %s", styles.RenderTechnical(proxyTarget))
+	log.Info().Msg("")
 
 	// Check that required dashboard tools are installed and satisfy version requirements.
-	ctx := cmd.Context()
 	if err := checkDashboardToolVersions(ctx, project); err != nil {
 		return err
 	}
 
-	// Resolve project dashboard directory.
-	dashboardPath := project.GetDashboardDir()
-
-	// Install dashboard dependencies
-	if err := execChildInteractive(ctx, dashboardPath, "pnpm", []string{"install"}, nil); err != nil {
-		return clierrors.Wrap(err, "Failed to install dashboard dependencies").
-			WithSuggestion("Try `metaplay dev clean-dashboard-artifacts` to remove stale build artifacts before reinstalling.")
+	// Install dashboard dependencies, if not already installed.
+	if o.flagInstall || !hasDashboardDependencies(dashboardPath) {
+		log.Info().Msg("Install dashboard dependencies...")
+		log.Info().Msg(styles.RenderMuted("> pnpm install"))
+		if err := execChildInteractive(ctx, dashboardPath, "pnpm", []string{"install"}, nil); err != nil {
+			return clierrors.Wrap(err, "Failed to install dashboard dependencies").
+				WithSuggestion("Try `metaplay dev clean-dashboard-artifacts` to remove stale build artifacts before reinstalling.")
+		}
+		log.Info().Msg("")
+	} else {
+		log.Info().Msg(styles.RenderMuted("Dashboard dependencies already installed, use --install to re-install them"))
 	}
 
 	// Run the dashboard project in dev mode
 	devArgs := append([]string{"dev"}, o.extraArgs...)
-	if err := execChildInteractive(ctx, dashboardPath, "pnpm", devArgs, nil); err != nil {
+	devEnv := getDevDashboardEnv(proxyTarget, accessToken)
+	log.Info().Msg(styles.RenderMuted(fmt.Sprintf("> pnpm %s", strings.Join(devArgs, " "))))
+	if err := execChildInteractive(ctx, dashboardPath, "pnpm", devArgs, devEnv); err != nil {
 		return clierrors.Wrap(err, "Failed to run the LiveOps Dashboard").
 			WithSuggestion("Try `metaplay dev clean-dashboard-artifacts` to remove stale build artifacts before reinstalling.")
 	}
@@ -79,3 +154,34 @@ func (o *devDashboardOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msgf("Dashboard terminated normally")
 	return nil
 }
+
+// resolveDevDashboardDir returns the directory of the dashboard to run in development mode:
+// the project's custom dashboard, or the SDK's default dashboard if the project has none.
+func resolveDevDashboardDir(project *metaproj.MetaplayProject) (string, error) {
+	if project.UsesCustomDashboard() {
+		return project.GetDashboardDir(), nil
+	}
+
+	dashboardPath := project.GetSdkDefaultDashboardDir()
+	if _, err := os.Stat(filepath.Join(dashboardPath, "package.json")); err != nil {
+		return "", clierrors.Newf("Project does not have a custom dashboard and the SDK default dashboard was not found in %s", filepath.ToSlash(dashboardPath)).
+			WithSuggestion("Initialize a custom dashboard with 'metaplay init dashboard'")
+	}
+	return dashboardPath, nil
+}
+
+// hasDashboardDependencies returns true if the dashboard's dependencies have been installed.
+func hasDashboardDependencies(dashboardPath string) bool {
+	info, err := os.Stat(filepath.Join(dashboardPath, "node_modules"))
+	return err == nil && info.IsDir()
+}
+
+// getDevDashboardEnv returns the environment variables for the dashboard dev server to proxy
+// the API requests to the target game server.
+func getDevDashboardEnv(proxyTarget, accessToken string) []string {
+	env := []string{"METAPLAY_DASHBOARD_PROXY_TARGET=" + proxyTarget}
+	if accessToken != "" {
+		env = append(env, "METAPLAY_ACCESS_TOKEN="+accessToken)
+	}
+	return env
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDevDashboardDir(t *testing.T) {
+	projectDir := t.TempDir()
+	project := &metaproj.MetaplayProject{RelativeDir: projectDir}
+	project.Config.SdkRootDir = "MetaplaySDK"
+
+	// SDK default dashboard is required if the project has no custom dashboard.
+	_, err := resolveDevDashboardDir(project)
+	assert.Error(t, err)
+
+	defaultDashboardDir := filepath.Join(projectDir, "MetaplaySDK", "Frontend", "DefaultDashboard")
+	require.NoError(t, os.MkdirAll(defaultDashboardDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(defaultDashboardDir, "package.json"), []byte("{}"), 0644))
+	dashboardDir, err := resolveDevDashboardDir(project)
+	require.NoError(t, err)
+	assert.Equal(t, defaultDashboardDir, dashboardDir)
+
+	// Custom dashboard takes precedence.
+	project.Config.Features.Dashboard.UseCustom = true
+	project.Config.Features.Dashboard.RootDir = "Backend/Dashboard"
+	dashboardDir, err = resolveDevDashboardDir(project)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projectDir, "Backend", "Dashboard"), dashboardDir)
+}
+
+func TestHasDashboardDependencies(t *testing.T) {
+	dashboardDir := t.TempDir()
+	assert.False(t, hasDashboardDependencies(dashboardDir))
+
+	require.NoError(t, os.Mkdir(filepath.Join(dashboardDir, "node_modules"), 0755))
+	assert.True(t, hasDashboardDependencies(dashboardDir))
+}
+
+func TestGetDevDashboardEnv(t *testing.T) {
+	assert.Equal(t, []string{"METAPLAY_DASHBOARD_PROXY_TARGET=http://localhost:5550"}, getDevDashboardEnv(localAdminAPIBaseURL, ""))
+	assert.Equal(t,
+		[]string{"METAPLAY_DASHBOARD_PROXY_TARGET=https://nimbly-admin.p1.metaplay.io", "METAPLAY_ACCESS_TOKEN=token"},
+		getDevDashboardEnv("https://nimbly-admin.p1.metaplay.io", "token"))
+}
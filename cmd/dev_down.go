@@ -0,0 +1,93 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/devstack"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Stop the local full stack started with 'metaplay dev up'.
+type devDownOpts struct {
+	UsePositionalArgs
+
+	flagVolumes bool
+}
+
+func init() {
+	o := devDownOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "down [flags]",
+		Short: "Stop the local stack started with 'metaplay dev up'",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Stop and remove the game server and database containers, and the Docker network, of
+			the project's local stack started with 'metaplay dev up'.
+
+			The database volume is kept so that the data survives across runs, unless --volumes
+			is given.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay dev up' starts the local stack.
+		`),
+		Example: renderExample(`
+			# Stop the local stack.
+			metaplay dev down
+
+			# Stop the local stack and delete the database.
+			metaplay dev down --volumes
+		`),
+	}
+
+	devCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagVolumes, "volumes", false, "Also delete the database volume")
+}
+
+func (o *devDownOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *devDownOpts) Run(cmd *cobra.Command) error {
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Stop Local Stack"))
+	log.Info().Msg("")
+
+	stack, err := devstack.New(devstack.Options{ProjectID: project.Config.ProjectHumanID})
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	removed, err := stack.Down(cmd.Context(), o.flagVolumes)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to stop the local stack").
+			WithSuggestion("Ensure Docker Desktop is installed and running")
+	}
+
+	for _, name := range removed {
+		log.Info().Msgf("%s Removed container %s", styles.RenderSuccess("✓"), styles.RenderTechnical(name))
+	}
+	if o.flagVolumes {
+		log.Info().Msgf("%s Removed database volume %s", styles.RenderSuccess("✓"), styles.RenderTechnical(stack.DatabaseVolumeName()))
+	}
+	if len(removed) == 0 && !o.flagVolumes {
+		log.Info().Msg("No running local stack found")
+	}
+	return nil
+}
@@ -5,10 +5,14 @@
 package cmd
 
 import (
+	"maps"
+	"os"
+	"slices"
 	"strings"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -38,6 +42,10 @@ func init() {
 
 			The LiveOps Dashboard is served at http://localhost:5550.
 
+			Developer-specific environment variables from the project's .metaplay/env.local.yaml (if
+			any) are injected into the game server container. The variables under 'env' and 'server'
+			are used, like with 'metaplay dev server'.
+
 			{Arguments}
 
 			Related commands:
@@ -74,31 +82,18 @@ func (o *devImageOpts) Run(cmd *cobra.Command) error {
 			WithSuggestion("Ensure Docker Desktop is installed and running")
 	}
 
-	// If no docker image specified, scan the images matching project from the local docker repo
-	// and then let the user choose from the images.
-	switch o.argImageTag {
-	case "":
-		selectedImage, err := selectDockerImageInteractively("Select Image to Run Locally", project.Config.ProjectHumanID)
-		if err != nil {
-			return err
-		}
-		o.argImageTag = selectedImage.RepoTag
-	case "latest-local":
-		// Resolve the local docker images matching project human ID.
-		localImages, err := envapi.ReadLocalDockerImagesByProjectID(project.Config.ProjectHumanID)
-		if err != nil {
-			return err
-		}
-
-		// If there are no images for this project, error out.
-		if len(localImages) == 0 {
-			return clierrors.Newf("No Docker images matching project '%s' found locally", project.Config.ProjectHumanID).
-				WithSuggestion("Build an image first with 'metaplay build image'")
-		}
+	// Resolve the docker image to run.
+	o.argImageTag, err = resolveLocalImageToRun(project, o.argImageTag)
+	if err != nil {
+		return err
+	}
 
-		// Use the first entry (they are reverse sorted by creation time).
-		o.argImageTag = localImages[0].RepoTag
+	// Load the developer-specific environment variables.
+	localEnv, err := loadLocalEnvFile(ctx, project)
+	if err != nil {
+		return err
 	}
+	serverEnv := localEnv.ServerEnv()
 
 	// Construct docker run args.
 	dockerRunArgs := []string{
@@ -109,6 +104,13 @@ func (o *devImageOpts) Run(cmd *cobra.Command) error {
 		"-p=127.0.0.1:8585:8585", // Health probe proxy
 		"-p=127.0.0.1:8888:8888", // SystemHttpServer
 		"-p=127.0.0.1:9090:9090", // Metrics
+	}
+	// Only pass the variable names on the command line (which is logged), the values are
+	// forwarded from the docker process' environment.
+	for _, name := range slices.Sorted(maps.Keys(serverEnv)) {
+		dockerRunArgs = append(dockerRunArgs, "-e", name)
+	}
+	dockerRunArgs = append(dockerRunArgs,
 		o.argImageTag,
 		"gameserver",                  // Inform entrypoint to start gameserver
 		"-AdminApiListenHost=0.0.0.0", // Listen to all traffic
@@ -118,7 +120,7 @@ func (o *devImageOpts) Run(cmd *cobra.Command) error {
 		"--AdminApi:WebRootPath=wwwroot",
 		"--Database:Backend=Sqlite",
 		"--Database:SqliteInMemory=true",
-	}
+	)
 	dockerRunArgs = append(dockerRunArgs, o.extraArgs...)
 
 	log.Info().Msg("")
@@ -126,7 +128,11 @@ func (o *devImageOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg("")
 
 	// Run the docker image.
-	if err := executeCommand(ctx, ".", nil, "docker", dockerRunArgs...); err != nil {
+	var dockerEnv []string
+	if len(serverEnv) > 0 {
+		dockerEnv = append(os.Environ(), formatEnvVars(serverEnv)...)
+	}
+	if err := executeCommand(ctx, ".", dockerEnv, "docker", dockerRunArgs...); err != nil {
 		return clierrors.Wrap(err, "Docker run failed")
 	}
 
@@ -134,3 +140,36 @@ func (o *devImageOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msgf("Docker container terminated normally")
 	return nil
 }
+
+// resolveLocalImageToRun resolves the local docker image to run. If no image is specified, the
+// user chooses from the project's local images. The special value 'latest-local' resolves to
+// the latest built local image of the project.
+func resolveLocalImageToRun(project *metaproj.MetaplayProject, imageTag string) (string, error) {
+	switch imageTag {
+	case "":
+		// Scan the images matching project from the local docker repo and then let the user
+		// choose from the images.
+		selectedImage, err := selectDockerImageInteractively("Select Image to Run Locally", project.Config.ProjectHumanID)
+		if err != nil {
+			return "", err
+		}
+		return selectedImage.RepoTag, nil
+	case "latest-local":
+		// Resolve the local docker images matching project human ID.
+		localImages, err := envapi.ReadLocalDockerImagesByProjectID(project.Config.ProjectHumanID)
+		if err != nil {
+			return "", err
+		}
+
+		// If there are no images for this project, error out.
+		if len(localImages) == 0 {
+			return "", clierrors.Newf("No Docker images matching project '%s' found locally", project.Config.ProjectHumanID).
+				WithSuggestion("Build an image first with 'metaplay build image'")
+		}
+
+		// Use the first entry (they are reverse sorted by creation time).
+		return localImages[0].RepoTag, nil
+	default:
+		return imageTag, nil
+	}
+}
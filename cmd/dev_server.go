@@ -5,19 +5,32 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
+// Default runtime options files for running the game server locally, relative to Backend/Server.
+var defaultDevServerOptionsFiles = []string{"Config/Options.base.yaml", "Config/Options.dev.yaml"}
+
 // Run the game server locally.
 type devServerOpts struct {
 	UsePositionalArgs
 
-	flagWatch bool
-	extraArgs []string
+	flagWatch   bool
+	flagOptions []string
+	flagRawLogs bool
+	extraArgs   []string
 }
 
 func init() {
@@ -38,6 +51,26 @@ func init() {
 			This command is roughly equivalent to running:
 			Backend/Server$ dotnet run EXTRA_ARGS
 
+			The runtime options files are passed to the game server with METAPLAY_OPTIONS. By
+			default, Config/Options.base.yaml and Config/Options.dev.yaml are used (the ones that
+			exist). The files can be configured with 'devServer.optionsFiles' in the
+			metaplay-project.yaml, or overridden with --options. The paths are relative to the
+			Backend/Server directory.
+
+			JSON-formatted log lines from the game server are rendered in a human-readable form,
+			colored by log level. Use --raw-logs to show the log lines as-is.
+
+			Developer-specific environment variables, eg, secrets, can be defined in the project's
+			.metaplay/env.local.yaml (which should be gitignored). The variables under 'env' and
+			'server' are passed to the game server, eg:
+
+			  env:
+			    METAPLAY_OPTS: --Database:Backend=Sqlite
+			  server:
+			    MY_API_KEY: secret
+
+			Press Ctrl+C to gracefully shut down the game server.
+
 			{Arguments}
 		`),
 		Example: renderExample(`
@@ -52,10 +85,15 @@ func init() {
 
 			# Run with file watching (auto-restart on code changes).
 			metaplay dev server --watch
+
+			# Run with custom runtime options files.
+			metaplay dev server --options Config/Options.base.yaml --options Config/Options.mytest.yaml
 		`),
 	}
 
 	cmd.Flags().BoolVarP(&o.flagWatch, "watch", "w", false, "Enable file watching to auto-restart on code changes")
+	cmd.Flags().StringArrayVar(&o.flagOptions, "options", nil, "Runtime options file to use, relative to Backend/Server (can be repeated; overrides the project config)")
+	cmd.Flags().BoolVar(&o.flagRawLogs, "raw-logs", false, "Show the game server log lines as-is, without rendering JSON-formatted lines")
 
 	devCmd.AddCommand(cmd)
 }
@@ -87,26 +125,131 @@ func (o *devServerOpts) Run(cmd *cobra.Command) error {
 	// Resolve server path.
 	serverPath := project.GetServerDir()
 
+	// Resolve the runtime options files.
+	optionsFiles, err := resolveDevServerOptionsFiles(project, serverPath, o.flagOptions)
+	if err != nil {
+		return err
+	}
+	serverEnv := slicesClone(commonDotnetEnvVars)
+	if len(optionsFiles) > 0 {
+		log.Info().Msgf("Runtime options files: %s", styles.RenderTechnical(strings.Join(optionsFiles, ", ")))
+		serverEnv = append(serverEnv, "METAPLAY_OPTIONS="+strings.Join(optionsFiles, ";"))
+	}
+
+	// Inject the developer-specific environment variables (these take precedence).
+	localEnv, err := loadLocalEnvFile(ctx, project)
+	if err != nil {
+		return err
+	}
+	serverEnv = append(serverEnv, formatEnvVars(localEnv.ServerEnv())...)
+	log.Info().Msg("")
+
+	// Render the game server logs in human-readable form, unless disabled.
+	var serverOutput io.Writer = os.Stdout
+	if !o.flagRawLogs {
+		logWriter := newDevServerLogWriter(os.Stdout)
+		defer logWriter.Flush()
+		serverOutput = logWriter
+	}
+
 	if o.flagWatch {
 		// Run with file watching (auto-restart on code changes).
 		watchArgs := append([]string{"watch", "run", "--no-hot-reload", "/p:Configuration=Watch"}, o.extraArgs...)
-		if err := execChildInteractive(ctx, serverPath, "dotnet", watchArgs, commonDotnetEnvVars); err != nil {
-			return fmt.Errorf("game server exited with error: %s", err)
-		}
+		err = execChildInteractiveWithOutput(ctx, serverPath, "dotnet", watchArgs, serverEnv, serverOutput)
 	} else {
 		// Build the game server .NET project.
 		if err := execChildInteractive(ctx, serverPath, "dotnet", []string{"build"}, commonDotnetEnvVars); err != nil {
-			return fmt.Errorf("failed to build the game server .NET project: %s", err)
+			return fmt.Errorf("failed to build the game server .NET project: %w", err)
 		}
 
 		// Run the game server (skip build).
 		runArgs := append([]string{"run", "--no-build"}, o.extraArgs...)
-		if err := execChildInteractive(ctx, serverPath, "dotnet", runArgs, commonDotnetEnvVars); err != nil {
-			return fmt.Errorf("game server exited with error: %s", err)
-		}
+		err = execChildInteractiveWithOutput(ctx, serverPath, "dotnet", runArgs, serverEnv, serverOutput)
+	}
+
+	// Ctrl+C is forwarded to the game server, which shuts down gracefully.
+	if _, ok := errors.AsType[*SignaledError](err); ok || (err != nil && ctx.Err() != nil) {
+		log.Info().Msg("")
+		log.Info().Msgf("Game server stopped")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("game server exited with error: %w", err)
 	}
 
 	// The server exited normally
 	log.Info().Msgf("Game server terminated normally")
 	return nil
 }
+
+// resolveDevServerOptionsFiles returns the runtime options files to run the game server with,
+// relative to the server directory. The files given on the command line take precedence over
+// the ones in the project config. The explicitly specified files must exist, whereas the default
+// files are only used if they exist.
+func resolveDevServerOptionsFiles(project *metaproj.MetaplayProject, serverDir string, flagOptions []string) ([]string, error) {
+	optionsFiles := flagOptions
+	if len(optionsFiles) == 0 && project.Config.DevServer != nil {
+		optionsFiles = project.Config.DevServer.OptionsFiles
+	}
+	isExplicit := len(optionsFiles) > 0
+	if !isExplicit {
+		optionsFiles = defaultDevServerOptionsFiles
+	}
+
+	resolved := []string{}
+	for _, optionsFile := range optionsFiles {
+		if _, err := os.Stat(filepath.Join(serverDir, optionsFile)); err != nil {
+			if !isExplicit {
+				continue
+			}
+			return nil, clierrors.Newf("Runtime options file '%s' not found in %s", optionsFile, serverDir).
+				WithSuggestion("Specify the paths relative to the Backend/Server directory")
+		}
+		resolved = append(resolved, filepath.ToSlash(optionsFile))
+	}
+	return resolved, nil
+}
+
+// devServerLogWriter renders the JSON-formatted log lines from the game server in a
+// human-readable form. Other lines are written as-is.
+type devServerLogWriter struct {
+	output  io.Writer
+	pending []byte // Incomplete last line of the output so far
+}
+
+func newDevServerLogWriter(output io.Writer) *devServerLogWriter {
+	return &devServerLogWriter{output: output}
+}
+
+// Write implements io.Writer. Complete lines are written out immediately.
+func (w *devServerLogWriter) Write(data []byte) (int, error) {
+	w.pending = append(w.pending, data...)
+	for {
+		lineEnd := bytes.IndexByte(w.pending, '\n')
+		if lineEnd < 0 {
+			break
+		}
+		if err := w.writeLine(string(w.pending[:lineEnd])); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[lineEnd+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes out any incomplete last line.
+func (w *devServerLogWriter) Flush() {
+	if len(w.pending) > 0 {
+		_ = w.writeLine(string(w.pending))
+		w.pending = nil
+	}
+}
+
+func (w *devServerLogWriter) writeLine(line string) error {
+	line = strings.TrimSuffix(line, "\r")
+	if structured := parseStructuredLogLine(line); structured != nil {
+		line = renderLogEntryMessage(LogEntry{message: structured.render(), level: structured.level})
+	}
+	_, err := fmt.Fprintln(w.output, line)
+	return err
+}
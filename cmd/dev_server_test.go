@@ -0,0 +1,60 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDevServerOptionsFiles(t *testing.T) {
+	serverDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(serverDir, "Config"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(serverDir, "Config", "Options.base.yaml"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(serverDir, "Config", "Options.test.yaml"), nil, 0644))
+	project := &metaproj.MetaplayProject{}
+
+	// Only the existing default files are used.
+	optionsFiles, err := resolveDevServerOptionsFiles(project, serverDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Config/Options.base.yaml"}, optionsFiles)
+
+	// Project config overrides the defaults.
+	project.Config.DevServer = &metaproj.DevServerConfig{OptionsFiles: []string{"Config/Options.base.yaml", "Config/Options.test.yaml"}}
+	optionsFiles, err = resolveDevServerOptionsFiles(project, serverDir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Config/Options.base.yaml", "Config/Options.test.yaml"}, optionsFiles)
+
+	// Command line overrides the project config.
+	optionsFiles, err = resolveDevServerOptionsFiles(project, serverDir, []string{"Config/Options.test.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Config/Options.test.yaml"}, optionsFiles)
+
+	// Explicitly specified files must exist.
+	_, err = resolveDevServerOptionsFiles(project, serverDir, []string{"Config/Options.missing.yaml"})
+	assert.Error(t, err)
+}
+
+func TestDevServerLogWriter(t *testing.T) {
+	var output bytes.Buffer
+	writer := newDevServerLogWriter(&output)
+
+	_, err := writer.Write([]byte("Building...\n{\"@m\":\"Server started\",\"SourceContext\":\"Glob"))
+	require.NoError(t, err)
+	assert.Equal(t, "Building...\n", output.String())
+
+	_, err = writer.Write([]byte("alAppActor\"}\r\n{\"@l\":\"Warning\",\"@m\":\"Slow"))
+	require.NoError(t, err)
+	writer.Flush()
+
+	assert.Contains(t, output.String(), "INF [GlobalAppActor] Server started\n")
+	assert.Contains(t, output.String(), "{\"@l\":\"Warning\",\"@m\":\"Slow\n")
+}
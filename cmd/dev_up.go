@@ -0,0 +1,262 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/devstack"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Start the local full stack: game server and database containers, and optionally the dashboard.
+type devUpOpts struct {
+	UsePositionalArgs
+
+	argImageTag       string
+	extraArgs         []string
+	flagDetach        bool
+	flagDashboard     bool
+	flagDatabaseImage string
+}
+
+func init() {
+	o := devUpOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argImageTag, "IMAGE:TAG", "Game server docker image name and tag, eg, 'mygame:364cff09', or 'latest-local' for the latest built image.")
+	args.SetExtraArgs(&o.extraArgs, "Passed as-is to the game server.")
+
+	cmd := &cobra.Command{
+		Use:   "up [IMAGE:TAG] [flags] [-- EXTRA_ARGS]",
+		Short: "Start the game server with a MySQL database locally",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Start a local full stack for development and testing: the game server image with a
+			MariaDB database, connected with a private Docker network.
+
+			This command:
+			- Creates the Docker network and the database volume (if missing)
+			- Starts the database container and waits for it to be healthy
+			- Starts the game server container and waits for it to be ready
+			- Streams the combined logs of the containers until Ctrl+C, and then stops the stack
+
+			With --detach, the command exits after the stack is ready and leaves the containers
+			running in the background. Use 'metaplay dev down' to stop them. The database files
+			are kept in a Docker volume across runs, use 'metaplay dev down --volumes' to delete
+			the database.
+
+			With --dashboard, the LiveOps Dashboard is also run in development mode (see
+			'metaplay dev dashboard') against the game server.
+
+			The game server's ports are published on localhost: the LiveOps Dashboard is served at
+			http://localhost:5550 and clients can connect to localhost:9339. Developer-specific
+			environment variables from .metaplay/env.local.yaml are passed to the game server.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay dev down' stops the local stack.
+			- 'metaplay build image' builds the game server image.
+			- 'metaplay dev image' runs the game server image alone with an in-memory database.
+		`),
+		Example: renderExample(`
+			# Choose the game server image interactively and start the stack.
+			metaplay dev up
+
+			# Start the stack with the latest built image, in the background.
+			metaplay dev up latest-local --detach
+
+			# Start the stack and the LiveOps Dashboard in development mode.
+			metaplay dev up latest-local --dashboard
+
+			# Pass extra arguments to the game server.
+			metaplay dev up mygame:364cff09 -- --Player:ForceFullDebugConfigForBots=false
+		`),
+	}
+
+	devCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagDetach, "detach", "d", false, "Leave the stack running in the background once it is ready")
+	flags.BoolVar(&o.flagDashboard, "dashboard", false, "Also run the LiveOps Dashboard in development mode")
+	flags.StringVar(&o.flagDatabaseImage, "database-image", devstack.DefaultDatabaseImage, "MariaDB-compatible docker image to use for the database")
+}
+
+func (o *devUpOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagDetach && o.flagDashboard {
+		return clierrors.NewUsageError("--dashboard cannot be used with --detach").
+			WithSuggestion("Run the dashboard separately with 'metaplay dev dashboard'")
+	}
+	return nil
+}
+
+func (o *devUpOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Check that docker is installed and running
+	if err := checkCommand(ctx, "docker", "info"); err != nil {
+		return clierrors.New("Failed to invoke Docker").
+			WithSuggestion("Ensure Docker Desktop is installed and running")
+	}
+
+	// Resolve the game server image to run.
+	imageTag, err := resolveLocalImageToRun(project, o.argImageTag)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the dashboard before starting anything, so that misconfigurations fail early.
+	dashboardPath := ""
+	if o.flagDashboard {
+		dashboardPath, err = resolveDevDashboardDir(project)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Start Local Stack"))
+	log.Info().Msg("")
+	log.Info().Msgf("Game server image: %s", styles.RenderTechnical(imageTag))
+	log.Info().Msgf("Database image:    %s", styles.RenderTechnical(o.flagDatabaseImage))
+	log.Info().Msg("")
+
+	// Inject the developer-specific environment variables into the game server.
+	localEnv, err := loadLocalEnvFile(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	stack, err := devstack.New(devstack.Options{
+		ProjectID:     project.Config.ProjectHumanID,
+		ServerImage:   imageTag,
+		DatabaseImage: o.flagDatabaseImage,
+		ServerEnv:     localEnv.ServerEnv(),
+		ServerArgs:    o.extraArgs,
+	})
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	// Start the stack.
+	runner := tui.NewTaskRunner()
+	runner.AddTaskWithSlowHint("Prepare network and database volume", tui.SlowHint{
+		Threshold: 30 * time.Second,
+		Message:   fmt.Sprintf("Pulling the database image %s can take a while on the first run.", o.flagDatabaseImage),
+	}, func(output *tui.TaskOutput) error {
+		return stack.Prepare(ctx)
+	})
+	runner.AddTask("Start database", func(output *tui.TaskOutput) error {
+		return stack.StartDatabase(ctx)
+	})
+	runner.AddTaskWithSlowHint("Start game server", tui.SlowHint{
+		Threshold: time.Minute,
+		Message:   fmt.Sprintf("Check the game server logs with 'docker logs %s'.", stack.ServerContainerName()),
+	}, func(output *tui.TaskOutput) error {
+		return stack.StartServer(ctx)
+	})
+	if err := runner.Run(); err != nil {
+		if ctx.Err() != nil {
+			o.stopStack(stack)
+			return nil
+		}
+		return clierrors.Wrap(err, "Failed to start the local stack").
+			WithSuggestion(fmt.Sprintf("Check the container logs with 'docker logs %s', and stop the stack with 'metaplay dev down'", stack.ServerContainerName()))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("%s Local stack is ready", styles.RenderSuccess("✓"))
+	log.Info().Msgf("  LiveOps Dashboard:  %s", styles.RenderTechnical("http://localhost:5550"))
+	log.Info().Msgf("  Game server:        %s", styles.RenderTechnical("localhost:9339"))
+	log.Info().Msg("")
+
+	if o.flagDetach {
+		log.Info().Msgf("The stack is running in the background, stop it with %s", styles.RenderPrompt("metaplay dev down"))
+		return nil
+	}
+
+	// Run the dashboard in the background, if requested.
+	dashboardCtx, stopDashboard := context.WithCancel(ctx)
+	defer stopDashboard()
+	dashboardDone := make(chan error, 1)
+	if o.flagDashboard {
+		if err := checkDashboardToolVersions(ctx, project); err != nil {
+			o.stopStack(stack)
+			return err
+		}
+		go func() {
+			dashboardDone <- o.runDashboard(dashboardCtx, dashboardPath)
+		}()
+	}
+
+	// Stream the logs until Ctrl+C or the containers exit.
+	log.Info().Msg(styles.RenderMuted("Streaming logs, press Ctrl+C to stop the stack..."))
+	log.Info().Msg("")
+	logsErr := stack.StreamLogs(ctx, os.Stdout)
+
+	o.stopStack(stack)
+	if o.flagDashboard {
+		// Stop the dashboard too, if the containers exited on their own.
+		stopDashboard()
+		select {
+		case err := <-dashboardDone:
+			if err != nil && ctx.Err() == nil && dashboardCtx.Err() == nil {
+				return err
+			}
+		case <-time.After(10 * time.Second):
+			log.Warn().Msg("The LiveOps Dashboard did not stop in time")
+		}
+	}
+	if logsErr != nil && ctx.Err() == nil {
+		return clierrors.Wrap(logsErr, "Failed to stream the container logs")
+	}
+	return nil
+}
+
+// runDashboard installs the dashboard dependencies (if missing) and runs the dashboard in
+// development mode against the local game server.
+func (o *devUpOpts) runDashboard(ctx context.Context, dashboardPath string) error {
+	if !hasDashboardDependencies(dashboardPath) {
+		if err := execChildInteractive(ctx, dashboardPath, "pnpm", []string{"install"}, nil); err != nil {
+			return clierrors.Wrap(err, "Failed to install dashboard dependencies")
+		}
+	}
+
+	devEnv := getDevDashboardEnv(localAdminAPIBaseURL, "")
+	err := execChildInteractive(ctx, dashboardPath, "pnpm", []string{"dev"}, devEnv)
+	var signaledErr *SignaledError
+	if err != nil && !errors.As(err, &signaledErr) {
+		return clierrors.Wrap(err, "Failed to run the LiveOps Dashboard")
+	}
+	return nil
+}
+
+// stopStack stops and removes the stack's containers, keeping the database volume.
+func (o *devUpOpts) stopStack(stack *devstack.Stack) {
+	log.Info().Msg("")
+	log.Info().Msg("Stopping the local stack...")
+	if _, err := stack.Down(context.Background(), false); err != nil {
+		log.Warn().Msgf("Failed to stop the local stack: %v", err)
+		log.Warn().Msgf("Stop it manually with %s", styles.RenderPrompt("metaplay dev down"))
+		return
+	}
+	log.Info().Msgf("%s Local stack stopped", styles.RenderSuccess("✓"))
+}
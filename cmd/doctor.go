@@ -0,0 +1,448 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/httputil"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Free disk space below which building images and running the server locally is likely to fail.
+const (
+	doctorDiskSpaceWarnBytes = 20 * 1024 * 1024 * 1024
+	doctorDiskSpaceFailBytes = 5 * 1024 * 1024 * 1024
+)
+
+// Check the local toolchain against the requirements of the project's Metaplay SDK.
+type doctorOpts struct {
+	flagTimeout time.Duration
+}
+
+// doctorStatus is the outcome of a single doctor check.
+type doctorStatus int
+
+const (
+	doctorStatusPass doctorStatus = iota
+	doctorStatusWarn
+	doctorStatusFail
+)
+
+// doctorCheckResult is the result of a single doctor check.
+type doctorCheckResult struct {
+	name        string       // Human-readable name of the checked tool or resource, eg, ".NET SDK".
+	status      doctorStatus // Outcome of the check.
+	details     string       // What was found, eg, the detected version.
+	remediation string       // How to fix the problem (for warnings and failures).
+}
+
+// doctorToolCheck describes how to evaluate the version of a locally installed tool.
+type doctorToolCheck struct {
+	name             string           // Human-readable name of the tool, eg, "Node.js".
+	minimumVersion   *version.Version // Minimum required version, or nil if not known.
+	newerMajorStatus doctorStatus     // Status when the major version is more recent than the minimum.
+	required         bool             // Whether the tool is required; problems with optional tools are only warnings.
+	remediation      string           // How to install or upgrade the tool.
+}
+
+func init() {
+	o := doctorOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor [flags]",
+		Short: "Check that the local tools needed for Metaplay development are installed and up to date",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Check the local development environment against the requirements of the project's
+			Metaplay SDK, and show how to fix any problems found:
+			- .NET SDK version, against the SDK's minimum version.
+			- Docker engine and buildx versions.
+			- Node.js and pnpm versions, against the SDK's recommended versions. These are only
+			  required when the project uses a custom LiveOps Dashboard.
+			- Helm CLI (optional, as the Metaplay CLI has Helm built in).
+			- Free disk space for building images.
+			- Network access to the Metaplay portal and the Helm chart repository.
+
+			Each check is reported as passed, warning, or failed. The command exits with an error if
+			any of the checks fail.
+
+			When run outside a project directory, the versions required by the SDK are not known, so
+			only the presence of the tools is checked.
+
+			Related commands:
+			- 'metaplay status services' to check the reachability of the upstream services in more detail.
+			- 'metaplay update sdk' to update the Metaplay SDK, which may change the required versions.
+		`),
+		Example: renderExample(`
+			# Check the local development environment.
+			metaplay doctor
+
+			# Use a longer timeout for the network checks.
+			metaplay doctor --timeout=15s
+		`),
+	}
+
+	cmd.GroupID = "other"
+	rootCmd.AddCommand(cmd)
+
+	cmd.Flags().DurationVar(&o.flagTimeout, "timeout", 5*time.Second, "Timeout for each of the network checks")
+}
+
+func (o *doctorOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagTimeout <= 0 {
+		return clierrors.NewUsageErrorf("Invalid --timeout value %v", o.flagTimeout).
+			WithSuggestion("Use a positive duration, e.g., '5s'")
+	}
+	return nil
+}
+
+func (o *doctorOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Resolve the project for the SDK's version requirements, if in a project directory.
+	project, err := tryResolveProject()
+	if err != nil {
+		log.Debug().Msgf("Failed to resolve project: %v", err)
+		project = nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Metaplay Doctor"))
+	log.Info().Msg("")
+	if project != nil {
+		log.Info().Msgf("Metaplay SDK: %s", styles.RenderTechnical(project.VersionMetadata.SdkVersion.String()))
+	} else {
+		log.Info().Msg(styles.RenderWarning("Not in a Metaplay project directory: only checking the presence of the tools"))
+	}
+	log.Info().Msg("")
+
+	results := []doctorCheckResult{}
+	results = append(results, checkDoctorTools(ctx, project)...)
+	results = append(results, checkDoctorDiskSpace(project))
+	results = append(results, checkDoctorNetwork(project, o.flagTimeout)...)
+
+	// Print the results.
+	numWarnings := 0
+	numFailures := 0
+	for _, result := range results {
+		log.Info().Msgf("%s %s %s", renderDoctorStatus(result.status), fmt.Sprintf("%-24s", result.name), result.details)
+		if result.status != doctorStatusPass && result.remediation != "" {
+			for _, line := range strings.Split(strings.TrimSpace(result.remediation), "\n") {
+				log.Info().Msgf("    %s", styles.RenderMuted(line))
+			}
+		}
+		switch result.status {
+		case doctorStatusWarn:
+			numWarnings++
+		case doctorStatusFail:
+			numFailures++
+		}
+	}
+	log.Info().Msg("")
+
+	if numFailures > 0 {
+		return clierrors.Newf("%d of %d checks failed", numFailures, len(results)).
+			WithSuggestion("Follow the steps shown under the failed checks and run 'metaplay doctor' again")
+	}
+
+	if numWarnings > 0 {
+		log.Info().Msg(styles.RenderWarning(fmt.Sprintf("All checks passed, with %d warnings", numWarnings)))
+		return nil
+	}
+
+	log.Info().Msg(styles.RenderSuccess("✅ All checks passed"))
+	return nil
+}
+
+// checkDoctorTools checks the versions of the locally installed tools.
+func checkDoctorTools(ctx context.Context, project *metaproj.MetaplayProject) []doctorCheckResult {
+	// Without a project, the required versions are not known.
+	var minDotnetVersion, recommendedNodeVersion, recommendedPnpmVersion *version.Version
+	dashboardToolsRequired := false
+	if project != nil {
+		minDotnetVersion = project.VersionMetadata.MinDotnetSdkVersion
+		recommendedNodeVersion = project.VersionMetadata.RecommendedNodeVersion
+		recommendedPnpmVersion = project.VersionMetadata.RecommendedPnpmVersion
+		dashboardToolsRequired = project.UsesCustomDashboard()
+	}
+
+	dotnetCheck := doctorToolCheck{
+		name:             ".NET SDK",
+		minimumVersion:   minDotnetVersion,
+		newerMajorStatus: doctorStatusPass,
+		required:         true,
+		remediation:      getDotnetInstallInstructions(),
+	}
+	nodeCheck := doctorToolCheck{
+		name:             "Node.js",
+		minimumVersion:   recommendedNodeVersion,
+		newerMajorStatus: doctorStatusWarn,
+		required:         dashboardToolsRequired,
+		remediation:      "Install or upgrade Node.js from https://nodejs.org/ (or via a version manager such as nvm, fnm, or volta)",
+	}
+	pnpmCheck := doctorToolCheck{
+		name:             "pnpm",
+		minimumVersion:   recommendedPnpmVersion,
+		newerMajorStatus: doctorStatusFail,
+		required:         dashboardToolsRequired,
+		remediation:      "Install pnpm from https://pnpm.io/installation, or upgrade with 'pnpm self-update' or via corepack",
+	}
+	helmCheck := doctorToolCheck{
+		name:             "Helm CLI",
+		newerMajorStatus: doctorStatusPass,
+		required:         false,
+		remediation:      "Optional: install Helm from https://helm.sh/docs/intro/install/ for inspecting deployments manually",
+	}
+
+	results := []doctorCheckResult{}
+	dotnetOutput, dotnetErr := runDoctorToolVersion(ctx, "dotnet", "--version")
+	results = append(results, evaluateToolVersion(dotnetCheck, dotnetOutput, dotnetErr))
+	results = append(results, checkDoctorDocker(ctx), checkDoctorBuildx(ctx))
+	nodeOutput, nodeErr := runDoctorToolVersion(ctx, "node", "--version")
+	results = append(results, evaluateToolVersion(nodeCheck, nodeOutput, nodeErr))
+	pnpmOutput, pnpmErr := runDoctorToolVersion(ctx, "pnpm", "--version")
+	results = append(results, evaluateToolVersion(pnpmCheck, pnpmOutput, pnpmErr))
+	helmOutput, helmErr := runDoctorToolVersion(ctx, "helm", "version", "--short")
+	results = append(results, evaluateToolVersion(helmCheck, helmOutput, helmErr))
+	return results
+}
+
+// runDoctorToolVersion runs the version command of a tool and returns its trimmed output.
+func runDoctorToolVersion(ctx context.Context, binary string, args ...string) (string, error) {
+	if _, err := exec.LookPath(binary); err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("'%s %s' failed: %w", binary, strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// evaluateToolVersion evaluates the output of a tool's version command against the requirements.
+func evaluateToolVersion(check doctorToolCheck, output string, runErr error) doctorCheckResult {
+	result := doctorCheckResult{name: check.name, remediation: check.remediation}
+
+	// Problems with optional tools are only warnings.
+	problemStatus := doctorStatusFail
+	if !check.required {
+		problemStatus = doctorStatusWarn
+	}
+
+	if runErr != nil {
+		result.status = problemStatus
+		if errors.Is(runErr, exec.ErrNotFound) {
+			result.details = "not installed or not in PATH"
+		} else {
+			result.details = runErr.Error()
+		}
+		return result
+	}
+
+	// Parse the version from the output, eg, "v22.13.1" or "v3.17.0+g301108e".
+	versionStr := strings.TrimPrefix(strings.Fields(output + " ")[0], "v")
+	installedVersion, err := version.NewVersion(versionStr)
+	if err != nil {
+		result.status = doctorStatusWarn
+		result.details = fmt.Sprintf("unable to parse version from '%s'", truncateForLog(output, 80))
+		return result
+	}
+
+	if check.minimumVersion == nil {
+		result.status = doctorStatusPass
+		result.details = styles.RenderTechnical(installedVersion.String())
+		return result
+	}
+
+	badge := styles.RenderMuted(fmt.Sprintf("[minimum: %s]", check.minimumVersion))
+	result.details = fmt.Sprintf("%s %s", styles.RenderTechnical(installedVersion.String()), badge)
+	switch {
+	case installedVersion.LessThan(check.minimumVersion):
+		result.status = problemStatus
+		result.details = fmt.Sprintf("%s %s", result.details, styles.RenderError("[too old]"))
+	case installedVersion.Segments()[0] > check.minimumVersion.Segments()[0] && check.newerMajorStatus != doctorStatusPass:
+		result.status = min(check.newerMajorStatus, problemStatus)
+		result.details = fmt.Sprintf("%s %s", result.details, styles.RenderWarning("[major version is more recent than expected]"))
+		result.remediation = fmt.Sprintf("Install version %d.x if you encounter any problems", check.minimumVersion.Segments()[0])
+	default:
+		result.status = doctorStatusPass
+	}
+	return result
+}
+
+// checkDoctorDocker checks that the Docker daemon is running and recent enough.
+func checkDoctorDocker(ctx context.Context) doctorCheckResult {
+	result := doctorCheckResult{name: "Docker engine"}
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		result.status = doctorStatusFail
+		result.details = "not installed or not in PATH"
+		result.remediation = "Install Docker Desktop (or Docker Engine on Linux): https://docs.docker.com/get-started/get-docker/"
+		return result
+	}
+
+	versionInfo, upgradeRecommended, err := checkDockerVersion(ctx)
+	return evaluateDockerVersion(versionInfo, upgradeRecommended, err)
+}
+
+// evaluateDockerVersion evaluates the result of checkDockerVersion().
+func evaluateDockerVersion(versionInfo *dockerVersionInfo, upgradeRecommended bool, err error) doctorCheckResult {
+	result := doctorCheckResult{name: "Docker engine"}
+	badge := styles.RenderMuted(fmt.Sprintf("[recommended: %s]", recommendedDockerEngineVersion))
+	switch {
+	case err != nil:
+		result.status = doctorStatusFail
+		result.details = "Docker daemon is not running or not reachable"
+		result.remediation = "Start Docker Desktop (or the Docker service on Linux) and check that 'docker version' works"
+	case versionInfo == nil:
+		result.status = doctorStatusWarn
+		result.details = "unable to check the version"
+		result.remediation = "Check that 'docker version' works and reports the server version"
+	case upgradeRecommended:
+		result.status = doctorStatusWarn
+		result.details = fmt.Sprintf("%s %s %s", styles.RenderTechnical(versionInfo.Server.Version), badge, styles.RenderWarning("[old]"))
+		result.remediation = "Upgrade Docker Desktop (or Docker Engine on Linux) to the latest version"
+	default:
+		result.status = doctorStatusPass
+		result.details = fmt.Sprintf("%s %s", styles.RenderTechnical(versionInfo.Server.Version), badge)
+	}
+	return result
+}
+
+// checkDoctorBuildx checks that Docker buildx is available. It is the default build engine,
+// but images can also be built with '--engine=buildkit' without it.
+func checkDoctorBuildx(ctx context.Context) doctorCheckResult {
+	result := doctorCheckResult{name: "Docker buildx"}
+	output, err := runDoctorToolVersion(ctx, "docker", "buildx", "version")
+	if err != nil {
+		result.status = doctorStatusWarn
+		result.details = "not available"
+		result.remediation = "Install Docker buildx (included in Docker Desktop), or build images with 'metaplay build image --engine=buildkit'"
+		return result
+	}
+
+	// Output is eg, "github.com/docker/buildx v0.21.1 7c2359c".
+	fields := strings.Fields(output)
+	versionStr := output
+	if len(fields) >= 2 {
+		versionStr = fields[1]
+	}
+	result.status = doctorStatusPass
+	result.details = styles.RenderTechnical(strings.TrimPrefix(versionStr, "v"))
+	return result
+}
+
+// checkDoctorDiskSpace checks that there is enough free disk space for building images.
+func checkDoctorDiskSpace(project *metaproj.MetaplayProject) doctorCheckResult {
+	path := "."
+	if project != nil {
+		path = project.RelativeDir
+	}
+	availableBytes, err := getAvailableDiskSpace(path)
+	if err != nil {
+		log.Debug().Msgf("Failed to get available disk space for %s: %v", path, err)
+		return doctorCheckResult{
+			name:    "Disk space",
+			status:  doctorStatusWarn,
+			details: "unable to check the available disk space",
+		}
+	}
+	return evaluateDiskSpace(availableBytes)
+}
+
+// evaluateDiskSpace evaluates the available disk space against the thresholds.
+func evaluateDiskSpace(availableBytes uint64) doctorCheckResult {
+	result := doctorCheckResult{
+		name:    "Disk space",
+		details: fmt.Sprintf("%s available", styles.RenderTechnical(formatImageSize(int64(availableBytes)))),
+	}
+	switch {
+	case availableBytes < doctorDiskSpaceFailBytes:
+		result.status = doctorStatusFail
+	case availableBytes < doctorDiskSpaceWarnBytes:
+		result.status = doctorStatusWarn
+	default:
+		result.status = doctorStatusPass
+	}
+	if result.status != doctorStatusPass {
+		result.remediation = fmt.Sprintf("Free up disk space; at least %s is recommended. Old Docker images can be removed with 'docker system prune'",
+			formatImageSize(doctorDiskSpaceWarnBytes))
+	}
+	return result
+}
+
+// checkDoctorNetwork checks the access to the Metaplay portal and the Helm chart repository.
+func checkDoctorNetwork(project *metaproj.MetaplayProject, timeout time.Duration) []doctorCheckResult {
+	helmChartRepo := defaultHelmChartRepository
+	if project != nil {
+		helmChartRepo = coalesceString(project.Config.HelmChartRepository, defaultHelmChartRepository)
+	}
+	checks := []serviceCheck{
+		{name: "Portal", url: portalapi.ResolveBaseURL()},
+		helmChartRepositoryCheck(helmChartRepo),
+	}
+
+	// Run the checks concurrently.
+	probes := make([]serviceCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for ndx, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statusCode, latency, err := httputil.ProbeURL(check.url, timeout)
+			probes[ndx] = serviceCheckResult{check: check, statusCode: statusCode, latency: latency, err: err}
+		}()
+	}
+	wg.Wait()
+
+	results := make([]doctorCheckResult, len(probes))
+	for ndx, probe := range probes {
+		result := doctorCheckResult{
+			name:    probe.check.name,
+			status:  doctorStatusPass,
+			details: fmt.Sprintf("%s %s", styles.RenderTechnical(probe.check.url), styles.RenderMuted(fmt.Sprintf("(%v)", probe.latency.Round(time.Millisecond)))),
+		}
+		if !probe.isHealthy() {
+			result.status = doctorStatusFail
+			result.remediation = "Check your network connection and proxy settings. Run 'metaplay status services' to check the upstream services"
+			if probe.err != nil {
+				result.details = fmt.Sprintf("%s %s", styles.RenderTechnical(probe.check.url), styles.RenderError("[unreachable]"))
+			} else {
+				result.details = fmt.Sprintf("%s %s", styles.RenderTechnical(probe.check.url), styles.RenderError(fmt.Sprintf("[HTTP %d]", probe.statusCode)))
+			}
+		}
+		results[ndx] = result
+	}
+	return results
+}
+
+// renderDoctorStatus renders the status of a check, eg, "✓ pass".
+func renderDoctorStatus(status doctorStatus) string {
+	switch status {
+	case doctorStatusPass:
+		return styles.RenderSuccess("✓ pass")
+	case doctorStatusWarn:
+		return styles.RenderWarning("! warn")
+	default:
+		return styles.RenderError("✗ fail")
+	}
+}
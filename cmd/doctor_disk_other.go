@@ -0,0 +1,21 @@
+//go:build !windows
+
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"syscall"
+)
+
+// getAvailableDiskSpace returns the number of bytes available to the current user on the
+// filesystem containing the given path.
+func getAvailableDiskSpace(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
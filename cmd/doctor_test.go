@@ -0,0 +1,76 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateToolVersion(t *testing.T) {
+	nodeCheck := doctorToolCheck{
+		name:             "Node.js",
+		minimumVersion:   version.Must(version.NewVersion("22.13.1")),
+		newerMajorStatus: doctorStatusWarn,
+		required:         true,
+	}
+	pnpmCheck := doctorToolCheck{
+		name:             "pnpm",
+		minimumVersion:   version.Must(version.NewVersion("9.15.0")),
+		newerMajorStatus: doctorStatusFail,
+		required:         false,
+	}
+
+	testCases := []struct {
+		name   string
+		check  doctorToolCheck
+		output string
+		runErr error
+		want   doctorStatus
+	}{
+		{"recent enough", nodeCheck, "v22.14.0", nil, doctorStatusPass},
+		{"too old", nodeCheck, "v20.19.5", nil, doctorStatusFail},
+		{"newer major", nodeCheck, "v24.1.0", nil, doctorStatusWarn},
+		{"not installed", nodeCheck, "", exec.ErrNotFound, doctorStatusFail},
+		{"command failed", nodeCheck, "", errors.New("exit status 1"), doctorStatusFail},
+		{"unparseable version", nodeCheck, "not a version", nil, doctorStatusWarn},
+		{"optional too old", pnpmCheck, "8.15.9", nil, doctorStatusWarn},
+		{"optional newer major", pnpmCheck, "10.0.0", nil, doctorStatusWarn},
+		{"optional not installed", pnpmCheck, "", fmt.Errorf("lookup: %w", exec.ErrNotFound), doctorStatusWarn},
+		{"no minimum", doctorToolCheck{name: "Helm CLI"}, "v3.17.0+g301108e", nil, doctorStatusPass},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := evaluateToolVersion(tc.check, tc.output, tc.runErr)
+			assert.Equal(t, tc.want, result.status)
+			assert.Equal(t, tc.check.name, result.name)
+		})
+	}
+}
+
+func TestEvaluateDockerVersion(t *testing.T) {
+	versionInfo := &dockerVersionInfo{}
+	versionInfo.Server.Version = "28.1.1"
+
+	assert.Equal(t, doctorStatusPass, evaluateDockerVersion(versionInfo, false, nil).status)
+	assert.Equal(t, doctorStatusWarn, evaluateDockerVersion(versionInfo, true, nil).status)
+	assert.Equal(t, doctorStatusWarn, evaluateDockerVersion(nil, false, nil).status)
+	assert.Equal(t, doctorStatusFail, evaluateDockerVersion(nil, false, errors.New("daemon not running")).status)
+}
+
+func TestEvaluateDiskSpace(t *testing.T) {
+	const GB = 1024 * 1024 * 1024
+	assert.Equal(t, doctorStatusPass, evaluateDiskSpace(100*GB).status)
+	assert.Equal(t, doctorStatusWarn, evaluateDiskSpace(10*GB).status)
+	assert.Equal(t, doctorStatusFail, evaluateDiskSpace(1*GB).status)
+	assert.Empty(t, evaluateDiskSpace(100*GB).remediation)
+	assert.NotEmpty(t, evaluateDiskSpace(1*GB).remediation)
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -144,6 +145,12 @@ func execChildTask(ctx context.Context, workingDir string, binary string, args [
 // start the next pipeline step (e.g. `pnpm install` after a Ctrl+C during the
 // preceding version check).
 func execChildInteractive(ctx context.Context, workingDir string, binary string, args []string, extraEnv []string) error {
+	return execChildInteractiveWithOutput(ctx, workingDir, binary, args, extraEnv, os.Stdout)
+}
+
+// execChildInteractiveWithOutput is like execChildInteractive but writes the child's stdout
+// into the given writer, eg, to post-process the output.
+func execChildInteractiveWithOutput(ctx context.Context, workingDir string, binary string, args []string, extraEnv []string, stdout io.Writer) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
@@ -152,7 +159,7 @@ func execChildInteractive(ctx context.Context, workingDir string, binary string,
 	cmd := exec.CommandContext(ctx, binary, args...)
 	cmd.Dir = workingDir
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 
 	// If extraEnv is given, append it to the current process's env variables.
@@ -0,0 +1,47 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
+)
+
+// dryRunPlan collects the side effects of a mutating command when run with --dry-run. The
+// command runs all its checks as usual, but instead of executing each mutating step, it
+// describes the step here. The collected steps are printed at the end with Print().
+type dryRunPlan struct {
+	steps []string
+}
+
+// addDryRunFlag registers the common --dry-run flag for a mutating command.
+func addDryRunFlag(flags *pflag.FlagSet, dryRun *bool) {
+	flags.BoolVar(dryRun, "dry-run", false, "Show what would be done without making any changes")
+}
+
+// Addf adds a step that would be executed without --dry-run.
+func (p *dryRunPlan) Addf(format string, args ...any) {
+	p.steps = append(p.steps, fmt.Sprintf(format, args...))
+}
+
+// Print shows the collected steps.
+func (p *dryRunPlan) Print() {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Dry Run"))
+	log.Info().Msg("")
+	if len(p.steps) == 0 {
+		log.Info().Msg("Nothing would be changed.")
+	} else {
+		log.Info().Msg("The following steps would be performed:")
+		for ndx, step := range p.steps {
+			log.Info().Msgf("  %d. %s", ndx+1, step)
+		}
+	}
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderMuted("Dry-run mode: no changes were made"))
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/kubeutil"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunPlan(t *testing.T) {
+	plan := dryRunPlan{}
+	plan.Addf("Push image %s", "mygame:1234")
+	plan.Addf("Upgrade Helm release %s", "nimbly-gameserver")
+	assert.Equal(t, []string{"Push image mygame:1234", "Upgrade Helm release nimbly-gameserver"}, plan.steps)
+}
+
+func TestAddDryRunFlag(t *testing.T) {
+	var dryRun bool
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	addDryRunFlag(flags, &dryRun)
+	require.NoError(t, flags.Parse([]string{"--dry-run"}))
+	assert.True(t, dryRun)
+}
+
+func TestAddDatabaseResetSteps(t *testing.T) {
+	shards := []kubeutil.DatabaseShardConfig{
+		{ShardIndex: 0, DatabaseName: "db0"},
+		{ShardIndex: 1, DatabaseName: "db1"},
+	}
+	allShardTables := map[int][]string{
+		0: {"MetaInfo", "Players", "Guilds"},
+		1: {"MetaInfo"},
+	}
+
+	plan := dryRunPlan{}
+	addDatabaseResetSteps(&plan, shards, allShardTables)
+	assert.Equal(t, []string{
+		"Shard 0 (db0): INSERT INTO MetaInfo (Version, Timestamp, MasterVersion, NumShards) SELECT Version + 1, NOW(), -4004, 0 FROM MetaInfo WHERE Version = (SELECT MAX(Version) FROM MetaInfo);",
+		"Shard 0 (db0): DROP TABLE IF EXISTS `Players`;",
+		"Shard 0 (db0): DROP TABLE IF EXISTS `Guilds`;",
+		"Shard 1 (db1): DROP TABLE IF EXISTS `MetaInfo`;",
+		"Shard 0 (db0): DROP TABLE IF EXISTS `MetaInfo`;",
+	}, plan.steps)
+}
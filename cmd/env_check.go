@@ -0,0 +1,167 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Check the health of the game server in an environment.
+type envCheckOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagFormat     string
+}
+
+// envCheckReport is the report of 'metaplay env check'.
+type envCheckReport struct {
+	Environment string                          `json:"environment"`
+	Healthy     bool                            `json:"healthy"`
+	Checks      []envapi.EnvironmentCheckResult `json:"checks"`
+}
+
+func init() {
+	o := envCheckOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "check ENVIRONMENT [flags]",
+		Short: "Check the health of the game server in the target environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Check the health of the game server in the target environment.
+
+			The same checks are run as after deploying a game server with 'metaplay deploy server':
+			- The game server pods are healthy and ready.
+			- The game server domain name resolves.
+			- The game server accepts client connections (TLS handshake and protocol header).
+			- The LiveOps Dashboard domain name resolves.
+			- The LiveOps Dashboard responds to HTTP requests.
+
+			Unlike when deploying, the checks are only run once, without waiting for the game server
+			to become ready. The results are shown as a pass/fail table, and the command exits with
+			a non-zero exit code if any of the checks fail, so it can be used in monitoring scripts.
+
+			Use --format=json to get the results in JSON format.
+			WARNING: The JSON output is subject to change!
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy status ENVIRONMENT' to show the status of the game server deployment.
+			- 'metaplay debug server-status ENVIRONMENT' to wait for the game server to be healthy.
+		`),
+		Example: renderExample(`
+			# Check the health of the game server in environment nimbly.
+			metaplay env check nimbly
+
+			# Output the results as JSON, eg, for a monitoring script.
+			metaplay env check nimbly --format=json
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+	flags.StringVar(&o.flagFormat, "output", "text", "Alias for --format")
+}
+
+func (o *envCheckOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *envCheckOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Run the checks.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	if o.flagFormat == "text" {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Environment Health Check"))
+		log.Info().Msg("")
+		log.Info().Msgf("Checking environment %s...", styles.RenderTechnical(envConfig.Name))
+	}
+	checks, err := targetEnv.CheckEnvironmentHealth(ctx)
+	if err != nil {
+		return err
+	}
+
+	report := envCheckReport{
+		Environment: envConfig.HumanID,
+		Healthy:     true,
+		Checks:      checks,
+	}
+	numFailed := 0
+	for _, check := range checks {
+		if check.Status != envapi.CheckPassed {
+			report.Healthy = false
+		}
+		if check.Status == envapi.CheckFailed {
+			numFailed++
+		}
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal health check results as JSON")
+		}
+		log.Info().Msg(string(reportJSON))
+	} else {
+		log.Info().Msg("")
+		for _, check := range checks {
+			log.Info().Msgf("  %s %-40s %s", renderCheckStatus(check.Status), check.Name, styles.RenderMuted(check.Message))
+		}
+		log.Info().Msg("")
+	}
+
+	if !report.Healthy {
+		return clierrors.Newf("%d of %d health checks failed in environment '%s'", numFailed, len(checks), envConfig.HumanID).
+			WithSuggestion(fmt.Sprintf("Check the game server status with 'metaplay deploy status %s'", envConfig.HumanID))
+	}
+	if o.flagFormat == "text" {
+		log.Info().Msg(styles.RenderSuccess("✅ All health checks passed"))
+	}
+	return nil
+}
+
+// renderCheckStatus renders the status of a health check with a color matching the outcome.
+func renderCheckStatus(status envapi.EnvironmentCheckStatus) string {
+	switch status {
+	case envapi.CheckPassed:
+		return styles.RenderSuccess("PASS")
+	case envapi.CheckFailed:
+		return styles.RenderError("FAIL")
+	default:
+		return styles.RenderMuted("SKIP")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/spf13/cobra"
+)
+
+// Export a kubeconfig with short-lived credentials for using kubectl, k9s, Lens, etc.
+// directly against the environment.
+type envGetKubeConfigOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagOutput     string
+	flagExecPlugin bool
+}
+
+func init() {
+	o := envGetKubeConfigOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "get-kubeconfig ENVIRONMENT [flags]",
+		Short: "Export a kubeconfig for accessing the environment with kubectl",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Export a kubeconfig for accessing the target environment's Kubernetes namespace directly
+			with tools like kubectl, k9s, or Lens.
+
+			By default, short-lived credentials are embedded in the kubeconfig. They expire soon after
+			the export, after which the command must be run again.
+
+			With --exec-plugin, the kubeconfig instead invokes the Metaplay CLI as a client-go exec
+			credential plugin, which fetches fresh short-lived credentials on demand using your
+			current session. The kubeconfig pins the credential profile active at the time of the
+			export (with METAPLAY_PROFILE), so switching profiles later doesn't affect it. The
+			'metaplay' executable must be in the PATH of the tool using the kubeconfig.
+
+			The kubeconfig is written to the file given with --output, or printed to stdout.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay get kubeconfig ENVIRONMENT' to get the kubeconfig using a custom auth provider.
+			- 'metaplay debug shell ENVIRONMENT' to start a shell in a game server pod.
+		`),
+		Example: renderExample(`
+			# Export a kubeconfig with embedded short-lived credentials for environment 'nimbly'.
+			metaplay env get-kubeconfig nimbly --output=kubeconfig.yaml
+
+			# Export a kubeconfig that fetches fresh credentials using the CLI when needed.
+			metaplay env get-kubeconfig nimbly --exec-plugin --output=kubeconfig.yaml
+
+			# Use the kubeconfig with kubectl.
+			KUBECONFIG=kubeconfig.yaml kubectl get pods
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.flagOutput, "output", "o", "", "Path of the output file where to write kubeconfig (written to stdout if not specified)")
+	flags.BoolVar(&o.flagExecPlugin, "exec-plugin", false, "Invoke the CLI as an exec credential plugin to fetch fresh credentials instead of embedding them")
+}
+
+func (o *envGetKubeConfigOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *envGetKubeConfigOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the auth provider of the environment, needed for the exec plugin.
+	authProvider, err := getAuthProvider(project, envConfig.AuthProvider)
+	if err != nil {
+		return err
+	}
+
+	// Create environment helper.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Generate kubeconfig and write it to a file or stdout.
+	credentialsType := "static"
+	if o.flagExecPlugin {
+		credentialsType = "dynamic"
+	}
+	kubeconfigPayload, err := generateKubeConfig(targetEnv, authProvider, tokenSet, credentialsType)
+	if err != nil {
+		return err
+	}
+	return writeKubeConfig(kubeconfigPayload, o.flagOutput)
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/pkg/browser"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Show the metrics of an environment, or run ad-hoc Prometheus queries.
+type envMetricsOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagQuery      string
+	flagOpen       bool
+	flagGrafanaURL string
+	flagFormat     string
+}
+
+// envMetricsOverviewQuery is one of the queries shown by default in 'env metrics'.
+type envMetricsOverviewQuery struct {
+	title       string
+	promql      string // Query with '%s' for the namespace
+	formatValue func(value float64) string
+}
+
+// Queries for the resource usage of the game server pods, shown by default.
+var envMetricsOverviewQueries = []envMetricsOverviewQuery{
+	{
+		title:       "CPU usage (cores)",
+		promql:      `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="%s", container="shard-server"}[5m]))`,
+		formatValue: func(value float64) string { return fmt.Sprintf("%.3f", value) },
+	},
+	{
+		title:       "Memory usage",
+		promql:      `sum by (pod) (container_memory_working_set_bytes{namespace="%s", container="shard-server"})`,
+		formatValue: func(value float64) string { return humanize.IBytes(uint64(value)) },
+	},
+}
+
+func init() {
+	o := envMetricsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "metrics ENVIRONMENT [flags]",
+		Short: "[preview] Show the metrics of the target environment or run Prometheus queries",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			PREVIEW: This command is in preview and subject to change!
+
+			Show the metrics endpoints of the target environment, along with the CPU and memory
+			usage of each game server pod. The Prometheus endpoint and its credentials are resolved
+			from the environment details.
+
+			Use --query to run an ad-hoc PromQL query instead, eg, to check the game server's own
+			metrics. The query is evaluated at the current time and must return an instant vector
+			or a scalar.
+
+			Use --open to open Grafana in the browser. With --query, the query is opened in Grafana's
+			Explore view. Grafana is assumed to be at https://grafana.<stack domain>; use --grafana-url
+			for stacks where it is elsewhere.
+
+			Use --format=json to get the query results in JSON format.
+			WARNING: The JSON output is subject to change!
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay env check ENVIRONMENT' to check the health of the game server.
+			- 'metaplay get environment-info ENVIRONMENT' to show the environment details.
+		`),
+		Example: renderExample(`
+			# Show the CPU and memory usage of the game server pods in environment nimbly.
+			metaplay env metrics nimbly
+
+			# Run an ad-hoc Prometheus query.
+			metaplay env metrics nimbly --query='sum(kube_pod_container_status_restarts_total{namespace="lovely-wombats-build-nimbly"})'
+
+			# Open the query in Grafana.
+			metaplay env metrics nimbly --query='up' --open
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagQuery, "query", "", "PromQL query to run, eg, 'up'")
+	flags.BoolVar(&o.flagOpen, "open", false, "Open Grafana in the browser")
+	flags.StringVar(&o.flagGrafanaURL, "grafana-url", "", "Base URL of Grafana (defaults to https://grafana.<stack domain>)")
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *envMetricsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	if o.flagFormat == "json" && o.flagQuery == "" && !o.flagOpen {
+		return clierrors.NewUsageError("--format=json requires --query").
+			WithSuggestion("Specify the query to run, eg, --query='up'")
+	}
+	if o.flagGrafanaURL != "" {
+		if parsed, err := url.Parse(o.flagGrafanaURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return clierrors.NewUsageErrorf("Invalid --grafana-url %q", o.flagGrafanaURL).
+				WithSuggestion("Specify the full URL, eg, --grafana-url=https://grafana.example.com")
+		}
+	}
+	return nil
+}
+
+func (o *envMetricsOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Open Grafana in the browser.
+	grafanaURL := coalesceString(strings.TrimSuffix(o.flagGrafanaURL, "/"), "https://grafana."+envConfig.StackDomain)
+	if o.flagOpen {
+		openURL := grafanaURL
+		if o.flagQuery != "" {
+			openURL = buildGrafanaExploreURL(grafanaURL, o.flagQuery)
+		}
+		log.Info().Msgf("Opening %s in the browser...", styles.RenderTechnical(openURL))
+		if err := browser.OpenURL(openURL); err != nil {
+			return clierrors.Wrap(err, "Failed to open the browser").
+				WithSuggestion(fmt.Sprintf("Open %s manually", openURL))
+		}
+		if o.flagQuery == "" {
+			return nil
+		}
+	}
+
+	// Resolve the Prometheus endpoint.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	promClient, err := targetEnv.NewPrometheusClient()
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to resolve the Prometheus endpoint of the environment")
+	}
+
+	// Run an ad-hoc query.
+	if o.flagQuery != "" {
+		samples, err := promClient.Query(ctx, o.flagQuery)
+		if err != nil {
+			return clierrors.Wrap(err, "Prometheus query failed").
+				WithSuggestion("Check the PromQL syntax of the query")
+		}
+
+		if o.flagFormat == "json" {
+			samplesJSON, err := json.MarshalIndent(samples, "", "  ")
+			if err != nil {
+				return clierrors.Wrap(err, "Failed to marshal query results as JSON")
+			}
+			log.Info().Msg(string(samplesJSON))
+			return nil
+		}
+
+		log.Info().Msg("")
+		if len(samples) == 0 {
+			log.Info().Msg(styles.RenderMuted("The query returned no results"))
+		}
+		for _, sample := range samples {
+			log.Info().Msgf("  %s %s", sample.LabelsString(), styles.RenderTechnical(fmt.Sprintf("%g", sample.Value)))
+		}
+		log.Info().Msg("")
+		return nil
+	}
+
+	// Show the overview.
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Environment Metrics"))
+	log.Info().Msg("")
+	log.Info().Msgf("  %-20s %s", "Prometheus:", styles.RenderTechnical(promClient.BaseURL))
+	log.Info().Msgf("  %-20s %s", "Grafana:", styles.RenderTechnical(grafanaURL))
+	for _, query := range envMetricsOverviewQueries {
+		log.Info().Msg("")
+		log.Info().Msg(query.title + ":")
+		samples, err := promClient.Query(ctx, fmt.Sprintf(query.promql, envConfig.GetKubernetesNamespace()))
+		if err != nil {
+			log.Info().Msgf("  %s", styles.RenderError(err.Error()))
+			continue
+		}
+		if len(samples) == 0 {
+			log.Info().Msgf("  %s", styles.RenderMuted("No game server pods found"))
+		}
+		for _, sample := range samples {
+			log.Info().Msgf("  %-20s %s", sample.Labels["pod"], styles.RenderTechnical(query.formatValue(sample.Value)))
+		}
+	}
+	log.Info().Msg("")
+	return nil
+}
+
+// buildGrafanaExploreURL returns the URL of Grafana's Explore view with the query filled in.
+func buildGrafanaExploreURL(grafanaURL string, promql string) string {
+	left, _ := json.Marshal(map[string]any{
+		"queries": []map[string]string{{"refId": "A", "expr": promql}},
+		"range":   map[string]string{"from": "now-1h", "to": "now"},
+	})
+	return grafanaURL + "/explore?left=" + url.QueryEscape(string(left))
+}
@@ -0,0 +1,29 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGrafanaExploreURL(t *testing.T) {
+	exploreURL := buildGrafanaExploreURL("https://grafana.p1.metaplay.io", `sum(up{namespace="nimbly"})`)
+	require.True(t, strings.HasPrefix(exploreURL, "https://grafana.p1.metaplay.io/explore?left="))
+
+	parsed, err := url.Parse(exploreURL)
+	require.NoError(t, err)
+	var left struct {
+		Queries []map[string]string `json:"queries"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(parsed.Query().Get("left")), &left))
+	require.Len(t, left.Queries, 1)
+	assert.Equal(t, `sum(up{namespace="nimbly"})`, left.Queries[0]["expr"])
+}
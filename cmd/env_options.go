@@ -0,0 +1,434 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Admin API endpoint for the game server's effective runtime options.
+const runtimeOptionsPath = "/api/runtimeOptions"
+
+// Show the effective runtime options of the game server in an environment.
+type envOptionsOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagSection    string
+	flagDiff       bool
+	flagFormat     string
+}
+
+// runtimeOptionsResponse is the response of the admin API's runtime options endpoint.
+type runtimeOptionsResponse struct {
+	Options []runtimeOptionsSection `json:"options"`
+}
+
+// runtimeOptionsSection is a single runtime options section (eg, 'Logging') with its
+// effective values.
+type runtimeOptionsSection struct {
+	Name   string         `json:"name"`
+	Values map[string]any `json:"values"`
+}
+
+// runtimeOptionDiff is a runtime option whose deployed value differs from the local files.
+type runtimeOptionDiff struct {
+	Path     string `json:"path"`               // Path of the option, eg, 'Logging.Level'
+	Status   string `json:"status"`             // "changed" or "missing"
+	Local    any    `json:"local"`              // Value in the local files
+	Deployed any    `json:"deployed,omitempty"` // Value in the deployed server, if any
+}
+
+func init() {
+	o := envOptionsOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "options ENVIRONMENT [flags]",
+		Short: "Show the effective runtime options of the game server in an environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show the effective runtime options of the game server running in the target
+			environment, as reported by its admin API. The effective options are the result of
+			merging Options.base.yaml, the environment-specific options file, and any overrides
+			from the Helm values.
+
+			With --diff, the deployed options are compared against the local runtime options
+			files in the project, to catch drift between what is committed and what is deployed.
+			The local files are the ones the deployment uses: Options.base.yaml and the options
+			file of the environment type (eg, Options.production.yaml), or the files listed in
+			'config.files' in the environment's Helm values file. Only the options set in the
+			local files are compared, as the deployed options also include all the defaults.
+			The command fails if any differences are found.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay validate runtime-options' to validate the local runtime options files.
+			- 'metaplay deploy server ...' to deploy the local runtime options files.
+		`),
+		Example: renderExample(`
+			# Show the runtime options of the game server in environment 'nimbly'.
+			metaplay env options nimbly
+
+			# Show only the 'Logging' section.
+			metaplay env options nimbly --section Logging
+
+			# Compare the deployed options against the local files.
+			metaplay env options nimbly --diff
+
+			# Output the options as JSON.
+			metaplay env options nimbly --format json
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagSection, "section", "", "Only show the given runtime options section, eg, 'Logging'")
+	flags.BoolVar(&o.flagDiff, "diff", false, "Compare the deployed options against the local runtime options files")
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *envOptionsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *envOptionsOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Comparing against the local files requires a project.
+	if project == nil && o.flagDiff {
+		return clierrors.NewUsageError("Comparing against the local runtime options files requires a Metaplay project").
+			WithSuggestion("Run the command in a Metaplay project directory")
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the effective options from the game server.
+	adminClient := metahttp.NewJSONClient(tokenSet, getAdminAPIBaseURL(envConfig))
+	sections, err := fetchRuntimeOptions(ctx, adminClient)
+	if err != nil {
+		return err
+	}
+	if o.flagSection != "" {
+		sections = slices.DeleteFunc(sections, func(section runtimeOptionsSection) bool {
+			return !strings.EqualFold(section.Name, o.flagSection)
+		})
+		if len(sections) == 0 {
+			return clierrors.Newf("Runtime options section '%s' not found in the game server", o.flagSection).
+				WithSuggestion("Run 'metaplay env options ENVIRONMENT' to list all the sections")
+		}
+	}
+
+	if !o.flagDiff {
+		return o.renderOptions(envConfig, sections)
+	}
+
+	// Compare against the local files.
+	localOptions, localFiles, err := loadLocalRuntimeOptions(project, envConfig)
+	if err != nil {
+		return err
+	}
+	if o.flagSection != "" {
+		for name := range localOptions {
+			if !strings.EqualFold(name, o.flagSection) {
+				delete(localOptions, name)
+			}
+		}
+	}
+	diffs := diffRuntimeOptions(localOptions, runtimeOptionsSectionsToMap(sections))
+
+	if o.flagFormat == "json" {
+		diffsJSON, err := json.MarshalIndent(diffs, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal runtime options differences as JSON")
+		}
+		log.Info().Msg(string(diffsJSON))
+	} else {
+		renderRuntimeOptionsDiff(envConfig, localFiles, diffs)
+	}
+
+	if len(diffs) > 0 {
+		return clierrors.Newf("Found %d difference(s) between the deployed and the local runtime options", len(diffs)).
+			WithSuggestion(fmt.Sprintf("Re-deploy the game server with 'metaplay deploy server %s' or update the local files", o.argEnvironment))
+	}
+	return nil
+}
+
+// renderOptions prints the runtime options sections in the requested format.
+func (o *envOptionsOpts) renderOptions(envConfig *metaproj.ProjectEnvironmentConfig, sections []runtimeOptionsSection) error {
+	if o.flagFormat == "json" {
+		optionsJSON, err := json.MarshalIndent(runtimeOptionsSectionsToMap(sections), "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal runtime options as JSON")
+		}
+		log.Info().Msg(string(optionsJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Runtime Options"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.Name))
+	log.Info().Msg("")
+
+	for _, section := range sections {
+		log.Info().Msg(styles.RenderTechnical(section.Name + ":"))
+		if len(section.Values) == 0 {
+			log.Info().Msg(styles.RenderMuted("  (no options)"))
+		} else {
+			valuesYAML, err := yaml.Marshal(section.Values)
+			if err != nil {
+				return clierrors.Wrapf(err, "Failed to format runtime options section %s", section.Name)
+			}
+			for _, line := range strings.Split(strings.TrimRight(string(valuesYAML), "\n"), "\n") {
+				log.Info().Msg("  " + line)
+			}
+		}
+		log.Info().Msg("")
+	}
+	return nil
+}
+
+// fetchRuntimeOptions fetches the effective runtime options sections from the game server,
+// sorted by name.
+func fetchRuntimeOptions(ctx context.Context, adminClient *metahttp.Client) ([]runtimeOptionsSection, error) {
+	response, err := adminClient.Resty.R().SetContext(ctx).Get(runtimeOptionsPath)
+	if err != nil {
+		return nil, clierrors.Wrap(err, "Failed to fetch runtime options from the game server")
+	}
+	if response.IsError() {
+		return nil, clierrors.Newf("Failed to fetch runtime options from the game server: %s", response.Status()).
+			WithDetails(string(response.Body())).
+			WithSuggestion("Check that the game server is running with 'metaplay deploy status ENVIRONMENT'")
+	}
+
+	var options runtimeOptionsResponse
+	if err := json.Unmarshal(response.Body(), &options); err != nil {
+		return nil, clierrors.Wrap(err, "Failed to parse runtime options response")
+	}
+	slices.SortFunc(options.Options, func(a, b runtimeOptionsSection) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return options.Options, nil
+}
+
+// runtimeOptionsSectionsToMap converts the sections to a map from the section name to its values,
+// in the same shape as the runtime options files.
+func runtimeOptionsSectionsToMap(sections []runtimeOptionsSection) map[string]any {
+	result := map[string]any{}
+	for _, section := range sections {
+		result[section.Name] = section.Values
+	}
+	return result
+}
+
+// resolveLocalRuntimeOptionsFiles returns the runtime options files (relative to the server
+// directory) that a deployment to the environment uses: the 'config.files' from the environment's
+// Helm values files, if set, or Options.base.yaml and the environment-type specific file.
+func resolveLocalRuntimeOptionsFiles(project *metaproj.MetaplayProject, envConfig *metaproj.ProjectEnvironmentConfig) ([]string, error) {
+	files := []string{"./Config/Options.base.yaml", envConfig.GetEnvironmentSpecificRuntimeOptionsFile()}
+
+	// The last values file to set 'config.files' wins, as in Helm.
+	for _, valuesFile := range project.GetServerValuesFiles(envConfig) {
+		content, err := os.ReadFile(valuesFile)
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read Helm values file %s", valuesFile)
+		}
+		var values struct {
+			Config struct {
+				Files []string `yaml:"files"`
+			} `yaml:"config"`
+		}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to parse Helm values file %s", valuesFile)
+		}
+		if len(values.Config.Files) > 0 {
+			files = values.Config.Files
+		}
+	}
+	return files, nil
+}
+
+// loadLocalRuntimeOptions loads and merges the local runtime options files used by the
+// environment. Returns the merged options and the paths of the files that were merged.
+func loadLocalRuntimeOptions(project *metaproj.MetaplayProject, envConfig *metaproj.ProjectEnvironmentConfig) (map[string]any, []string, error) {
+	files, err := resolveLocalRuntimeOptionsFiles(project, envConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := map[string]any{}
+	loadedFiles := []string{}
+	for _, file := range files {
+		filePath := filepath.Join(project.GetServerDir(), file)
+		content, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			log.Warn().Msgf("Runtime options file %s not found, skipping it", filepath.ToSlash(filePath))
+			continue
+		} else if err != nil {
+			return nil, nil, clierrors.Wrapf(err, "Failed to read runtime options file %s", filePath)
+		}
+
+		var options map[string]any
+		if err := yaml.Unmarshal(content, &options); err != nil {
+			return nil, nil, clierrors.Wrapf(err, "Failed to parse runtime options file %s", filePath)
+		}
+		mergeRuntimeOptions(merged, options)
+		loadedFiles = append(loadedFiles, filePath)
+	}
+	return merged, loadedFiles, nil
+}
+
+// mergeRuntimeOptions merges the src options into dst, recursing into nested objects. The keys
+// are matched case-insensitively, like in the .NET configuration system.
+func mergeRuntimeOptions(dst map[string]any, src map[string]any) {
+	for key, srcValue := range src {
+		dstKey := findRuntimeOptionKey(dst, key)
+		if dstKey == "" {
+			dstKey = key
+		}
+
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		dstMap, dstIsMap := dst[dstKey].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeRuntimeOptions(dstMap, srcMap)
+		} else {
+			dst[dstKey] = srcValue
+		}
+	}
+}
+
+// findRuntimeOptionKey returns the key in the options that matches the given key
+// case-insensitively, or an empty string if there is none.
+func findRuntimeOptionKey(options map[string]any, key string) string {
+	if _, found := options[key]; found {
+		return key
+	}
+	for existing := range options {
+		if strings.EqualFold(existing, key) {
+			return existing
+		}
+	}
+	return ""
+}
+
+// diffRuntimeOptions compares the options set in the local files against the deployed ones.
+// Options that only exist in the deployed options are not reported, as they are the defaults
+// of the options not set in the local files. The differences are sorted by path.
+func diffRuntimeOptions(local map[string]any, deployed map[string]any) []runtimeOptionDiff {
+	diffs := []runtimeOptionDiff{}
+	var diffRecursive func(path string, local map[string]any, deployed map[string]any)
+	diffRecursive = func(path string, local map[string]any, deployed map[string]any) {
+		for key, localValue := range local {
+			optionPath := key
+			if path != "" {
+				optionPath = path + "." + key
+			}
+
+			deployedKey := findRuntimeOptionKey(deployed, key)
+			if deployedKey == "" {
+				diffs = append(diffs, runtimeOptionDiff{Path: optionPath, Status: "missing", Local: localValue})
+				continue
+			}
+			deployedValue := deployed[deployedKey]
+
+			localMap, localIsMap := localValue.(map[string]any)
+			deployedMap, deployedIsMap := deployedValue.(map[string]any)
+			if localIsMap && deployedIsMap {
+				diffRecursive(optionPath, localMap, deployedMap)
+			} else if !runtimeOptionValuesEqual(localValue, deployedValue) {
+				diffs = append(diffs, runtimeOptionDiff{Path: optionPath, Status: "changed", Local: localValue, Deployed: deployedValue})
+			}
+		}
+	}
+	diffRecursive("", local, deployed)
+
+	slices.SortFunc(diffs, func(a, b runtimeOptionDiff) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return diffs
+}
+
+// runtimeOptionValuesEqual compares a value from the YAML files to a value from the admin API.
+// The values are compared by their string representation, case-insensitively, as the .NET
+// configuration system parses, eg, 'True' and 'true' the same way.
+func runtimeOptionValuesEqual(local any, deployed any) bool {
+	return strings.EqualFold(formatRuntimeOptionValue(local), formatRuntimeOptionValue(deployed))
+}
+
+// formatRuntimeOptionValue formats the option value for comparing and displaying it.
+func formatRuntimeOptionValue(value any) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valueJSON)
+}
+
+// renderRuntimeOptionsDiff prints the differences between the deployed and local options.
+func renderRuntimeOptionsDiff(envConfig *metaproj.ProjectEnvironmentConfig, localFiles []string, diffs []runtimeOptionDiff) {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Runtime Options Diff"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.Name))
+	log.Info().Msg("Local files:")
+	for _, file := range localFiles {
+		log.Info().Msgf("  %s", styles.RenderTechnical(filepath.ToSlash(file)))
+	}
+	log.Info().Msg("")
+
+	if len(diffs) == 0 {
+		log.Info().Msg(styles.RenderSuccess("✅ The deployed runtime options match the local files"))
+		log.Info().Msg("")
+		return
+	}
+
+	for _, diff := range diffs {
+		switch diff.Status {
+		case "missing":
+			log.Info().Msgf("%s %s %s", styles.RenderError("✗"), styles.RenderTechnical(diff.Path), styles.RenderMuted("(not in the deployed options)"))
+			log.Info().Msgf("    local:    %s", formatRuntimeOptionValue(diff.Local))
+		default:
+			log.Info().Msgf("%s %s", styles.RenderWarning("~"), styles.RenderTechnical(diff.Path))
+			log.Info().Msgf("    local:    %s", formatRuntimeOptionValue(diff.Local))
+			log.Info().Msgf("    deployed: %s", formatRuntimeOptionValue(diff.Deployed))
+		}
+	}
+	log.Info().Msg("")
+}
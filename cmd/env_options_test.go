@@ -0,0 +1,94 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRuntimeOptions(t *testing.T) {
+	dst := map[string]any{
+		"Logging": map[string]any{"Level": "Information", "Format": "Text"},
+		"System":  map[string]any{"Port": 9339},
+	}
+	mergeRuntimeOptions(dst, map[string]any{
+		"logging": map[string]any{"level": "Debug"},
+		"Other":   map[string]any{"Enabled": true},
+	})
+
+	assert.Equal(t, map[string]any{
+		"Logging": map[string]any{"Level": "Debug", "Format": "Text"},
+		"System":  map[string]any{"Port": 9339},
+		"Other":   map[string]any{"Enabled": true},
+	}, dst)
+}
+
+func TestDiffRuntimeOptions(t *testing.T) {
+	local := map[string]any{
+		"Logging": map[string]any{
+			"Level":   "Debug",
+			"Colored": "True",
+		},
+		"System": map[string]any{
+			"Port":  9339,
+			"Hosts": []any{"a", "b"},
+		},
+		"Removed": map[string]any{"Key": "value"},
+	}
+	deployed := map[string]any{
+		"Logging": map[string]any{
+			"Level":   "Information",
+			"Colored": true,
+			"Format":  "Json",
+		},
+		"system": map[string]any{
+			"port":  float64(9339),
+			"hosts": []any{"a", "c"},
+		},
+	}
+
+	diffs := diffRuntimeOptions(local, deployed)
+	assert.Equal(t, []runtimeOptionDiff{
+		{Path: "Logging.Level", Status: "changed", Local: "Debug", Deployed: "Information"},
+		{Path: "Removed", Status: "missing", Local: map[string]any{"Key": "value"}},
+		{Path: "System.Hosts", Status: "changed", Local: []any{"a", "b"}, Deployed: []any{"a", "c"}},
+	}, diffs)
+
+	assert.Empty(t, diffRuntimeOptions(map[string]any{}, deployed))
+}
+
+func TestLoadLocalRuntimeOptions(t *testing.T) {
+	projectDir := t.TempDir()
+	project := &metaproj.MetaplayProject{RelativeDir: projectDir}
+	project.Config.BackendDir = "Backend"
+	envConfig := &metaproj.ProjectEnvironmentConfig{Type: portalapi.EnvironmentTypeProduction}
+
+	configDir := filepath.Join(projectDir, "Backend", "Server", "Config")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "Options.base.yaml"), []byte("Logging:\n  Level: Information\n  Format: Text\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "Options.production.yaml"), []byte("Logging:\n  Level: Warning\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "Options.custom.yaml"), []byte("Logging:\n  Format: Json\n"), 0644))
+
+	// Options.base.yaml and the environment-type specific file by default.
+	options, files, err := loadLocalRuntimeOptions(project, envConfig)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, map[string]any{"Logging": map[string]any{"Level": "Warning", "Format": "Text"}}, options)
+
+	// The files listed in the Helm values file override the defaults.
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "values.yaml"), []byte("config:\n  files:\n    - ./Config/Options.base.yaml\n    - ./Config/Options.custom.yaml\n"), 0644))
+	envConfig.ServerValuesFile = "values.yaml"
+	options, files, err = loadLocalRuntimeOptions(project, envConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(configDir, "Options.base.yaml"), filepath.Join(configDir, "Options.custom.yaml")}, files)
+	assert.Equal(t, map[string]any{"Logging": map[string]any{"Level": "Information", "Format": "Json"}}, options)
+}
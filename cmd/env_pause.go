@@ -0,0 +1,251 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Pause (hibernate) the game server of an environment to save costs.
+type envPauseOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagYes        bool
+	flagDryRun     bool
+}
+
+func init() {
+	o := envPauseOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "pause ENVIRONMENT [flags]",
+		Short: "Pause the game server to save costs, keeping its configuration for resuming",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Pause (hibernate) the game server in the target environment to cut the costs of a
+			rarely used environment, eg, a development environment that is only needed now and then.
+
+			The game server Helm release, including its chart, image, and values, is saved into the
+			'metaplay-paused-gameserver' secret in the environment, and the release is then
+			uninstalled, which stops all the game server pods. The database, the secrets, and the
+			rest of the environment are kept as is. Use 'metaplay env resume' to install the game
+			server again exactly as it was.
+
+			While paused, the game server is not reachable. Deploying the game server with
+			'metaplay deploy server' also brings the environment back, with the new image and
+			values; the saved release is then removed by the next 'metaplay env resume'.
+
+			The game server pods and their resource requests that are freed are shown before
+			pausing. Pausing staging and production environments requires confirmation, or --yes
+			in non-interactive mode.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay env resume ENVIRONMENT' to resume the paused game server.
+			- 'metaplay env scale ENVIRONMENT' to change the size of the game server instead.
+		`),
+		Example: renderExample(`
+			# Pause the game server in environment 'nimbly'.
+			metaplay env pause nimbly
+
+			# Show what would be paused without pausing.
+			metaplay env pause nimbly --dry-run
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt for staging and production environments")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *envPauseOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *envPauseOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment and a Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm and find the game server release.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+	if existingRelease == nil {
+		paused, err := targetEnv.GetPausedGameServer(ctx)
+		if err != nil {
+			return err
+		}
+		if paused != nil {
+			log.Info().Msgf("Environment %s is already paused.", styles.RenderTechnical(envConfig.Name))
+			return nil
+		}
+		return clierrors.Newf("No game server deployed in environment '%s'", envConfig.HumanID)
+	}
+
+	// Show what is freed by pausing.
+	topology, err := envapi.FetchGameServerShardSetTopology(ctx, kubeCli)
+	if err != nil {
+		return err
+	}
+	numPods, cpuRequests, memoryRequests := sumShardSetRequests(topology)
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Pause Game Server"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment:    %s (%s)", styles.RenderTechnical(envConfig.Name), envConfig.Type)
+	printGameServerReleaseSummary(existingRelease)
+	log.Info().Msgf("Pods to stop:   %s", styles.RenderTechnical(fmt.Sprintf("%d", numPods)))
+	log.Info().Msgf("Freed requests: %s CPU, %s memory", styles.RenderTechnical(cpuRequests.String()), styles.RenderTechnical(memoryRequests.String()))
+	log.Info().Msg("")
+	renderShardSetTopology(topology)
+	log.Info().Msg("")
+	log.Info().Msg("While paused, the game server is not reachable. The database, secrets, and the rest of")
+	log.Info().Msg("the environment are kept.")
+	log.Info().Msg("")
+
+	// With --dry-run, only show the steps.
+	if o.flagDryRun {
+		dryRun := dryRunPlan{}
+		dryRun.Addf("Save Helm release %s into secret %s", styles.RenderTechnical(existingRelease.Name), styles.RenderTechnical("metaplay-paused-gameserver"))
+		dryRun.Addf("Uninstall Helm release %s", styles.RenderTechnical(existingRelease.Name))
+		dryRun.Print()
+		return nil
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// Require confirmation for staging and production.
+	confirmed, err := confirmEnvironmentStateChange(ctx, envConfig, o.flagYes, fmt.Sprintf("Pause the game server in %s environment '%s'?", envConfig.Type, envConfig.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Pausing canceled."))
+		return nil
+	}
+
+	taskRunner := tui.NewTaskRunner()
+	taskRunner.AddTask("Save game server Helm release", func(output *tui.TaskOutput) error {
+		return targetEnv.SavePausedGameServer(ctx, existingRelease, time.Now())
+	})
+	taskRunner.AddTask(fmt.Sprintf("Uninstall Helm release %s", existingRelease.Name), func(output *tui.TaskOutput) error {
+		return helmutil.UninstallRelease(actionConfig, existingRelease)
+	})
+	if err := taskRunner.Run(); err != nil {
+		return err
+	}
+
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Game server in %s paused", envConfig.Name)))
+	log.Info().Msg("")
+	log.Info().Msgf("Resume it with: %s", styles.RenderPrompt(fmt.Sprintf("metaplay env resume %s", o.argEnvironment)))
+	return nil
+}
+
+// confirmEnvironmentStateChange asks for confirmation to change the state of the game server,
+// eg, to pause, resume, or scale it, in staging and production environments. Other environments
+// don't need confirmation.
+func confirmEnvironmentStateChange(ctx context.Context, envConfig *metaproj.ProjectEnvironmentConfig, autoConfirm bool, question string) (bool, error) {
+	if envConfig.Type != portalapi.EnvironmentTypeProduction && envConfig.Type != portalapi.EnvironmentTypeStaging {
+		return true, nil
+	}
+	if autoConfirm {
+		return true, nil
+	}
+	if !tui.CanAskQuestions() {
+		return false, clierrors.Newf("Confirmation required for %s environment '%s'", envConfig.Type, envConfig.Name).
+			WithSuggestion("Use --yes to confirm in non-interactive mode")
+	}
+	confirmed, err := tui.DoConfirmQuestion(ctx, question)
+	if err != nil {
+		return false, err
+	}
+	log.Info().Msg("")
+	return confirmed, nil
+}
+
+// printGameServerReleaseSummary prints the Helm release, chart version, and image tag of the
+// game server release.
+func printGameServerReleaseSummary(rel *release.Release) {
+	log.Info().Msgf("Helm release:   %s", styles.RenderTechnical(rel.Name))
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		log.Info().Msgf("Chart version:  %s", styles.RenderTechnical(rel.Chart.Metadata.Version))
+	}
+	if imageConfig, ok := rel.Config["image"].(map[string]any); ok {
+		if tag, ok := imageConfig["tag"].(string); ok {
+			log.Info().Msgf("Image tag:      %s", styles.RenderTechnical(tag))
+		}
+	}
+}
+
+// sumShardSetRequests returns the total number of pods and their CPU and memory requests over all
+// the shard sets.
+func sumShardSetRequests(topology []envapi.ShardSetTopology) (int32, resource.Quantity, resource.Quantity) {
+	var numPods int32
+	cpu := resource.Quantity{Format: resource.DecimalSI}
+	memory := resource.Quantity{Format: resource.BinarySI}
+	for _, shardSet := range topology {
+		numPods += shardSet.Replicas
+		for range shardSet.Replicas {
+			if quantity, err := resource.ParseQuantity(shardSet.CPURequest); err == nil {
+				cpu.Add(quantity)
+			}
+			if quantity, err := resource.ParseQuantity(shardSet.MemoryRequest); err == nil {
+				memory.Add(quantity)
+			}
+		}
+	}
+	return numPods, cpu, memory
+}
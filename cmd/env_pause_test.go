@@ -0,0 +1,30 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSumShardSetRequests(t *testing.T) {
+	topology := []envapi.ShardSetTopology{
+		{Name: "all", Replicas: 2, CPURequest: "500m", MemoryRequest: "1Gi"},
+		{Name: "logic", Replicas: 1, CPURequest: "1", MemoryRequest: "512Mi"},
+		{Name: "unknown", Replicas: 1},
+	}
+
+	numPods, cpu, memory := sumShardSetRequests(topology)
+	assert.Equal(t, int32(4), numPods)
+	assert.Equal(t, "2", cpu.String())
+	assert.Equal(t, "2560Mi", memory.String())
+
+	numPods, cpu, memory = sumShardSetRequests(nil)
+	assert.Equal(t, int32(0), numPods)
+	assert.Equal(t, "0", cpu.String())
+	assert.Equal(t, "0", memory.String())
+}
@@ -0,0 +1,417 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Propagate an environment renamed in the portal (and thus its new slug) to the local project
+// files.
+type envRenameSlugOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	argNewName     string
+	flagYes        bool
+	flagPlanOnly   bool
+}
+
+func init() {
+	o := envRenameSlugOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgumentOpt(&o.argNewName, "NEW_NAME", "Expected new name of the environment, eg, 'Staging EU'. Defaults to the name in the portal.")
+
+	cmd := &cobra.Command{
+		Use:   "rename-slug ENVIRONMENT [NEW_NAME] [flags]",
+		Short: "Propagate an environment renamed in the portal to the project files",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Propagate an environment renamed in the portal to the local project files.
+
+			The environment's slug is derived from its name and is used, eg, in the names of the
+			CI files generated with 'metaplay init ci'. When the name changes only in the portal,
+			the local files silently disagree with it. This command updates all of them to the
+			name in the portal:
+			- The environment name in metaplay-project.yaml.
+			- The CI files generated by 'metaplay init ci' in the project directory: the files
+			  named after the old slug are renamed and the environment name in them is updated.
+
+			The environment must first be renamed in the portal, as the CLI cannot rename it. If
+			NEW_NAME is given, it must match the name in the portal, to catch renames that haven't
+			been made in the portal yet.
+
+			The environment's human ID (eg, 'lovely-wombats-build-nimbly') is immutable and is
+			not affected.
+
+			Helm releases cannot be renamed. Deployed Helm releases in the environment whose name
+			still contains the old slug are reported, so they can be re-deployed manually.
+
+			Use --plan-only to show the planned changes without making any.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay update project-environments' to sync all the environments from the portal.
+			- 'metaplay init ci' to re-generate the CI files.
+		`),
+		Example: renderExample(`
+			# Update the project files after renaming environment nimbly in the portal.
+			metaplay env rename-slug nimbly
+
+			# Check that the environment was renamed to 'Staging EU' in the portal.
+			metaplay env rename-slug nimbly "Staging EU"
+
+			# Show the planned changes without making them.
+			metaplay env rename-slug nimbly --plan-only
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned changes without making any")
+}
+
+func (o *envRenameSlugOpts) Prepare(cmd *cobra.Command, args []string) error {
+	o.argNewName = strings.TrimSpace(o.argNewName)
+
+	if !tui.IsInteractiveMode() && !o.flagYes && !o.flagPlanOnly {
+		return clierrors.NewUsageError("Confirmation required in non-interactive mode").
+			WithSuggestion("Use --yes to confirm the rename, or --plan-only to only show the planned changes")
+	}
+
+	return nil
+}
+
+func (o *envRenameSlugOpts) Run(cmd *cobra.Command) error {
+	// Resolve project and environment.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// The environment must belong to this project in the portal.
+	portalEnv, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet)
+	if err != nil {
+		return err
+	}
+	if portalEnv == nil {
+		return clierrors.Newf("Environment '%s' is not managed by the portal", envConfig.HumanID).
+			WithDetails("Only Metaplay-hosted environments can be renamed.")
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// The environment can only be renamed in the portal.
+	if o.argNewName == "" {
+		o.argNewName = portalEnv.Name
+	} else if portalEnv.Name != o.argNewName {
+		return clierrors.Newf("Environment '%s' is named '%s' in the portal, not '%s'", envConfig.HumanID, portalEnv.Name, o.argNewName).
+			WithSuggestion(fmt.Sprintf("Rename the environment in the portal (%s) first, then run this command again", common.PortalBaseURL))
+	}
+
+	// Resolve the old and new slugs.
+	projectHumanID := project.Config.ProjectHumanID
+	renamedEnvConfig := *envConfig
+	renamedEnvConfig.Name = o.argNewName
+
+	// The name is embedded in the CI files, so it must be safe for the templates.
+	if err := validateCIEnvironment(renamedEnvConfig); err != nil {
+		return err
+	}
+	oldSlug := sanitizeEnvNameForFileName(*envConfig, projectHumanID)
+	newSlug := sanitizeEnvNameForFileName(renamedEnvConfig, projectHumanID)
+
+	// Plan the changes to the local files.
+	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
+	if envConfig.Name != o.argNewName {
+		configPath := filepath.Join(project.RelativeDir, metaproj.ConfigFileName)
+		configBytes, err := os.ReadFile(configPath)
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to read metaplay-project.yaml")
+		}
+		newConfigBytes, err := renameEnvironmentInProjectConfig(configBytes, project.Config.Environments, envConfig.HumanID, o.argNewName)
+		if err != nil {
+			return err
+		}
+		plan.AddUpdate(configPath, newConfigBytes, 0644, fmt.Sprintf("rename environment '%s' to '%s'", envConfig.Name, o.argNewName))
+	}
+	removedFiles, err := collectRenamedCIFiles(plan, project.RelativeDir, envConfig.HumanID, envConfig.Name, o.argNewName, oldSlug, newSlug)
+	if err != nil {
+		return err
+	}
+	if err := plan.Scan(); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Rename Environment"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment ID:  %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("Portal name:     %s", styles.RenderTechnical(portalEnv.Name))
+	log.Info().Msgf("Local name:      %s", renderRename(envConfig.Name, o.argNewName))
+	log.Info().Msgf("Slug:            %s", renderRename(oldSlug, newSlug))
+	log.Info().Msg("")
+
+	if plan.FilesToWrite() == 0 && len(removedFiles) == 0 {
+		log.Info().Msg("The environment name is already up to date in the project files, nothing to do.")
+		return nil
+	}
+
+	if plan.FilesToWrite() > 0 {
+		log.Info().Msg("Files to be modified:")
+		plan.Preview(false)
+
+		// Wait for any read-only files to become writable (must be immediately
+		// after Preview so the cursor math for in-place redraw is correct).
+		if !o.flagPlanOnly {
+			if err := plan.WaitForWritable(cmd.Context(), false); err != nil {
+				return err
+			}
+		}
+		log.Info().Msg("")
+	}
+	if len(removedFiles) > 0 {
+		log.Info().Msg("Files to be removed (renamed to the new slug):")
+		for _, path := range removedFiles {
+			log.Info().Msgf("  %s %s", styles.RenderError("-"), filepath.ToSlash(path))
+		}
+		log.Info().Msg("")
+	}
+
+	if o.flagPlanOnly {
+		log.Info().Msg(styles.RenderMuted("Plan-only mode: no changes were made"))
+		return nil
+	}
+
+	if !o.flagYes {
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Update the project files?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Aborted.")
+			return nil
+		}
+	}
+
+	taskRunner := tui.NewTaskRunner()
+	taskRunner.AddTask("Update project files", func(output *tui.TaskOutput) error {
+		if err := plan.Execute(); err != nil {
+			return err
+		}
+		for _, path := range removedFiles {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+	if err := taskRunner.Run(); err != nil {
+		return err
+	}
+
+	// Report the Helm releases that still carry the old naming. This is best-effort:
+	// the rename itself has already succeeded.
+	if oldSlug != newSlug {
+		targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+		staleReleases, err := findReleasesWithOldSlug(targetEnv, envConfig, oldSlug)
+		if err != nil {
+			log.Warn().Msgf("Unable to check the Helm releases for the old naming: %v", err)
+		} else if len(staleReleases) > 0 {
+			log.Info().Msg("")
+			log.Warn().Msgf("The following Helm releases still carry the old slug '%s':", oldSlug)
+			for _, name := range staleReleases {
+				log.Warn().Msgf("  %s", name)
+			}
+			log.Warn().Msg("Helm releases cannot be renamed. Re-deploy with --helm-release-name after removing the old release, if needed.")
+		}
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Project files updated to environment name '%s'!", o.argNewName)))
+	log.Info().Msg("")
+	log.Info().Msg("Remember to commit the changed files into your version control.")
+	return nil
+}
+
+// renderRename renders an 'old -> new' pair, or only the value if unchanged.
+func renderRename(oldValue, newValue string) string {
+	if oldValue == newValue {
+		return fmt.Sprintf("%s %s", styles.RenderTechnical(newValue), styles.RenderMuted("(unchanged)"))
+	}
+	return fmt.Sprintf("%s -> %s", styles.RenderTechnical(oldValue), styles.RenderTechnical(newValue))
+}
+
+// renameEnvironmentInProjectConfig returns the metaplay-project.yaml contents with the name of
+// the environment humanID changed to newName. The rest of the file is kept intact.
+func renameEnvironmentInProjectConfig(configBytes []byte, environments []metaproj.ProjectEnvironmentConfig, humanID, newName string) ([]byte, error) {
+	envNdx := -1
+	for ndx, env := range environments {
+		if env.HumanID == humanID {
+			envNdx = ndx
+			break
+		}
+	}
+	if envNdx == -1 {
+		return nil, clierrors.Newf("Environment '%s' not found in metaplay-project.yaml", humanID)
+	}
+
+	root, err := parser.ParseBytes(configBytes, parser.ParseComments)
+	if err != nil {
+		return nil, clierrors.Wrap(err, "Failed to parse metaplay-project.yaml")
+	}
+
+	namePath, err := yaml.PathString(fmt.Sprintf("$.environments[%d].name", envNdx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create environment name path: %v", err)
+	}
+	if err := namePath.ReplaceWithReader(root, strings.NewReader(strconv.Quote(newName))); err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to update the name of environment '%s' in metaplay-project.yaml", humanID)
+	}
+
+	return []byte(root.String()), nil
+}
+
+// collectRenamedCIFiles adds the CI files generated by 'metaplay init ci' with the environment
+// renamed to the plan. The per-environment files named after the old slug are moved to the new
+// slug: the returned old files must be removed after executing the plan.
+func collectRenamedCIFiles(plan *filesetwriter.Plan, projectDir, humanID, oldName, newName, oldSlug, newSlug string) ([]string, error) {
+	var removedFiles []string
+
+	// Per-environment files of GitHub Actions and generic CI.
+	perEnvFiles := []struct {
+		oldPath string
+		newPath string
+	}{
+		{
+			filepath.Join(projectDir, ".github", "workflows", fmt.Sprintf("deploy-server-%s.yaml", oldSlug)),
+			filepath.Join(projectDir, ".github", "workflows", fmt.Sprintf("deploy-server-%s.yaml", newSlug)),
+		},
+		{
+			filepath.Join(projectDir, fmt.Sprintf("deploy-server-%s.sh", oldSlug)),
+			filepath.Join(projectDir, fmt.Sprintf("deploy-server-%s.sh", newSlug)),
+		},
+	}
+	for _, file := range perEnvFiles {
+		content, info, err := readFileIfExists(file.oldPath)
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			continue
+		}
+
+		newContent := renameEnvironmentInCIContent(string(content), humanID, oldName, newName)
+		if file.oldPath == file.newPath {
+			plan.AddUpdate(file.newPath, []byte(newContent), info.Mode().Perm(), "update environment name")
+		} else {
+			plan.Add(file.newPath, []byte(newContent), info.Mode().Perm())
+			removedFiles = append(removedFiles, file.oldPath)
+		}
+	}
+
+	// Bitbucket Pipelines has all the environments in a single file.
+	bitbucketPath := filepath.Join(projectDir, "bitbucket-pipelines.yml")
+	content, info, err := readFileIfExists(bitbucketPath)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		newContent := renameEnvironmentInCIContent(string(content), humanID, oldName, newName)
+		plan.AddUpdate(bitbucketPath, []byte(newContent), info.Mode().Perm(), "update environment name")
+	}
+
+	return removedFiles, nil
+}
+
+// readFileIfExists reads the file at path. Returns nil info if the file doesn't exist.
+func readFileIfExists(path string) ([]byte, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, clierrors.Wrapf(err, "Failed to access %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, clierrors.Wrapf(err, "Failed to read %s", path)
+	}
+	return content, info, nil
+}
+
+// renameEnvironmentInCIContent replaces the environment's display name in the content of a CI
+// file generated by 'metaplay init ci'. The templates always render the display name followed by
+// the human ID in parentheses, so only those occurrences are replaced.
+func renameEnvironmentInCIContent(content, humanID, oldName, newName string) string {
+	return strings.ReplaceAll(content, fmt.Sprintf("%s (%s)", oldName, humanID), fmt.Sprintf("%s (%s)", newName, humanID))
+}
+
+// findReleasesWithOldSlug returns the names of the Helm releases in the environment whose name
+// contains the old slug. The human ID part of the names is ignored, as it doesn't change.
+func findReleasesWithOldSlug(targetEnv *envapi.TargetEnvironment, envConfig *metaproj.ProjectEnvironmentConfig, oldSlug string) ([]string, error) {
+	kubeconfigPayload, err := targetEnv.GetKubeConfigWithEmbeddedCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	actionConfig, err := helmutil.NewActionConfig(kubeconfigPayload, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseNames []string
+	for _, chartName := range []string{metaplayGameServerChartName, metaplayLoadTestChartName} {
+		releases, err := helmutil.HelmListReleases(actionConfig, chartName)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			releaseNames = append(releaseNames, release.Name)
+		}
+	}
+
+	return filterReleasesWithOldSlug(releaseNames, envConfig.HumanID, oldSlug), nil
+}
+
+// filterReleasesWithOldSlug returns the release names that contain oldSlug outside of humanID.
+func filterReleasesWithOldSlug(releaseNames []string, humanID, oldSlug string) []string {
+	var result []string
+	for _, name := range releaseNames {
+		if strings.Contains(strings.ReplaceAll(name, humanID, ""), oldSlug) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameEnvironmentInProjectConfig(t *testing.T) {
+	config := `# Project config
+projectID: lovely-wombats
+environments:
+  - name: Develop
+    humanId: lovely-wombats-build-nimbly
+    type: development
+  - name: Staging
+    humanId: lovely-wombats-build-quick # staging env
+    type: staging
+`
+	environments := []metaproj.ProjectEnvironmentConfig{
+		{Name: "Develop", HumanID: "lovely-wombats-build-nimbly"},
+		{Name: "Staging", HumanID: "lovely-wombats-build-quick"},
+	}
+
+	result, err := renameEnvironmentInProjectConfig([]byte(config), environments, "lovely-wombats-build-quick", "Staging EU")
+	require.NoError(t, err)
+	assert.Contains(t, string(result), "# Project config")
+	assert.Contains(t, string(result), "name: Develop")
+	assert.Contains(t, string(result), `name: "Staging EU"`)
+	assert.Contains(t, string(result), "# staging env")
+	assert.NotContains(t, string(result), "name: Staging\n")
+
+	_, err = renameEnvironmentInProjectConfig([]byte(config), environments, "lovely-wombats-build-missing", "Other")
+	assert.Error(t, err)
+}
+
+func TestRenameEnvironmentInCIContent(t *testing.T) {
+	content := "name: Deploy game server to Staging (lovely-wombats-build-quick)\nrun: metaplay deploy server lovely-wombats-build-quick\n# Staging is not replaced here\n"
+	result := renameEnvironmentInCIContent(content, "lovely-wombats-build-quick", "Staging", "Staging EU")
+	assert.Equal(t, "name: Deploy game server to Staging EU (lovely-wombats-build-quick)\nrun: metaplay deploy server lovely-wombats-build-quick\n# Staging is not replaced here\n", result)
+}
+
+func TestCollectRenamedCIFiles(t *testing.T) {
+	dir := t.TempDir()
+	workflowsDir := filepath.Join(dir, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflowsDir, 0755))
+	oldWorkflowPath := filepath.Join(workflowsDir, "deploy-server-staging.yaml")
+	require.NoError(t, os.WriteFile(oldWorkflowPath, []byte("name: Deploy game server to Staging (lovely-wombats-build-quick)\n"), 0644))
+
+	plan := filesetwriter.NewPlan(false)
+	removedFiles, err := collectRenamedCIFiles(plan, dir, "lovely-wombats-build-quick", "Staging", "Staging EU", "staging", "staging-eu")
+	require.NoError(t, err)
+	assert.Equal(t, []string{oldWorkflowPath}, removedFiles)
+
+	require.NoError(t, plan.Scan())
+	results := plan.Results()
+	require.Len(t, results, 1)
+	assert.Equal(t, filepath.Join(workflowsDir, "deploy-server-staging-eu.yaml"), results[0].File.Path)
+	assert.Equal(t, "name: Deploy game server to Staging EU (lovely-wombats-build-quick)\n", string(results[0].File.Content))
+}
+
+func TestFilterReleasesWithOldSlug(t *testing.T) {
+	releaseNames := []string{"lovely-wombats-build-quick-gameserver", "staging-gameserver", "staging-loadtest"}
+	assert.Equal(t, []string{"staging-gameserver", "staging-loadtest"}, filterReleasesWithOldSlug(releaseNames, "lovely-wombats-build-quick", "staging"))
+	assert.Empty(t, filterReleasesWithOldSlug(releaseNames, "lovely-wombats-build-quick", "quick"))
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// Resume the game server of an environment paused with 'metaplay env pause'.
+type envResumeOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagYes        bool
+	flagDryRun     bool
+}
+
+func init() {
+	o := envResumeOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "resume ENVIRONMENT [flags]",
+		Short: "Resume the game server paused with 'metaplay env pause'",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Resume the game server in the target environment that was paused with
+			'metaplay env pause'.
+
+			The game server Helm release saved when pausing is installed again with the same chart,
+			image, and values, and the command waits for the game server to be ready. The saved
+			release is then removed from the environment.
+
+			Resuming staging and production environments requires confirmation, or --yes in
+			non-interactive mode.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay env pause ENVIRONMENT' to pause the game server.
+			- 'metaplay deploy server ENVIRONMENT' to deploy a new version of the game server.
+		`),
+		Example: renderExample(`
+			# Resume the game server in environment 'nimbly'.
+			metaplay env resume nimbly
+
+			# Show what would be resumed without resuming.
+			metaplay env resume nimbly --dry-run
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt for staging and production environments")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *envResumeOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *envResumeOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment and a Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+
+	// Find the release saved when pausing.
+	paused, err := targetEnv.GetPausedGameServer(ctx)
+	if err != nil {
+		return err
+	}
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+	if existingRelease != nil {
+		// The game server was deployed after pausing, so the saved release is stale.
+		if paused != nil && !o.flagDryRun {
+			if err := targetEnv.DeletePausedGameServer(ctx); err != nil {
+				return err
+			}
+			log.Debug().Msg("Removed the stale paused game server release")
+		}
+		log.Info().Msgf("The game server in environment %s is not paused.", styles.RenderTechnical(envConfig.Name))
+		return nil
+	}
+	if paused == nil {
+		return clierrors.Newf("Environment '%s' is not paused and has no game server deployed", envConfig.HumanID).
+			WithSuggestion(fmt.Sprintf("Deploy the game server with 'metaplay deploy server %s'", o.argEnvironment))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Resume Game Server"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment:    %s (%s)", styles.RenderTechnical(envConfig.Name), envConfig.Type)
+	printGameServerReleaseSummary(paused.Release)
+	if !paused.PausedAt.IsZero() {
+		log.Info().Msgf("Paused at:      %s", styles.RenderTechnical(paused.PausedAt.Local().Format(time.RFC1123)))
+	}
+	log.Info().Msg("")
+
+	// With --dry-run, only show the steps.
+	if o.flagDryRun {
+		dryRun := dryRunPlan{}
+		dryRun.Addf("Install Helm release %s with the saved chart and values", styles.RenderTechnical(paused.Release.Name))
+		dryRun.Addf("Wait for the game server to be ready")
+		dryRun.Addf("Remove the saved release from secret %s", styles.RenderTechnical("metaplay-paused-gameserver"))
+		dryRun.Print()
+		return nil
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// Require confirmation for staging and production.
+	confirmed, err := confirmEnvironmentStateChange(ctx, envConfig, o.flagYes, fmt.Sprintf("Resume the game server in %s environment '%s'?", envConfig.Type, envConfig.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Resuming canceled."))
+		return nil
+	}
+
+	// Use the environment's post-renderer, like when deploying.
+	var postRenderer postrender.PostRenderer
+	if project != nil {
+		if postRendererPath := project.GetServerPostRenderer(envConfig); postRendererPath != "" {
+			postRenderer, err = helmutil.NewPostRenderer(postRendererPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	taskRunner := tui.NewTaskRunner()
+	taskRunner.AddTask(fmt.Sprintf("Install Helm release %s", paused.Release.Name), func(output *tui.TaskOutput) error {
+		_, err := helmutil.ReinstallRelease(output, actionConfig, paused.Release, postRenderer, 5*time.Minute)
+		return err
+	})
+	if err := targetEnv.WaitForServerToBeReady(ctx, taskRunner); err != nil {
+		return err
+	}
+	if err := taskRunner.Run(); err != nil {
+		return err
+	}
+
+	// The saved release is no longer needed.
+	if err := targetEnv.DeletePausedGameServer(ctx); err != nil {
+		return err
+	}
+
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Game server in %s resumed", envConfig.Name)))
+	return nil
+}
@@ -0,0 +1,422 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Scale the game server shard sets of an environment by patching the deployed Helm values.
+type envScaleOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagShard      string
+	flagReplicas   int
+	flagCPU        string
+	flagMemory     string
+	flagYes        bool
+	flagDryRun     bool
+
+	hasReplicas bool // Was --replicas specified?
+}
+
+// shardScaleChange is the change to make to a shard set's config in the Helm values. Empty
+// fields are left unchanged.
+type shardScaleChange struct {
+	Replicas *int   // Number of nodes (pods) in the shard set
+	CPU      string // CPU request, eg, '2000m'
+	Memory   string // Memory request, eg, '4Gi'
+}
+
+func init() {
+	o := envScaleOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "scale ENVIRONMENT [flags]",
+		Short: "Change the number of pods or resources of the game server shard sets",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Change the number of pods (replicas) or the CPU and memory requests of a game server
+			shard set in the target environment.
+
+			The shard config in the values of the deployed game server Helm release is patched,
+			and the release is upgraded with the same chart version and image, so there is no need
+			to edit the Helm values files and re-deploy the game server manually. The command
+			waits for the game server to be ready after the upgrade.
+
+			The current shard topology, from the game server StatefulSets, is shown first. In
+			interactive mode, the shard set to scale is chosen from a list if --shard is not
+			specified and there are multiple shard sets. Without any of --replicas, --cpu, or
+			--memory, only the topology is shown.
+
+			Singleton shard sets always have exactly one pod, so only their resources can be
+			changed.
+
+			Scaling staging and production environments requires confirmation, or --yes in
+			non-interactive mode.
+
+			Note: The next 'metaplay deploy server' uses the shard config from the Helm values
+			files again. To keep the new config, add it to the environment's Helm values file;
+			the config to add is shown after scaling.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy status ENVIRONMENT' to show the status of the game server pods.
+			- 'metaplay deploy server ENVIRONMENT' to deploy the game server.
+		`),
+		Example: renderExample(`
+			# Show the current shard topology of environment 'nimbly'.
+			metaplay env scale nimbly
+
+			# Scale the 'all' shard set to 3 pods with 2 CPUs and 4GiB of memory each.
+			metaplay env scale nimbly --shard=all --replicas=3 --cpu=2000m --memory=4Gi
+
+			# Show what would be changed without upgrading the release.
+			metaplay env scale nimbly --shard=all --memory=4Gi --dry-run
+		`),
+	}
+	envCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagShard, "shard", "", "Name of the shard set to scale, eg, 'all' (default: the only shard set)")
+	flags.IntVar(&o.flagReplicas, "replicas", 0, "Number of pods in the shard set")
+	flags.StringVar(&o.flagCPU, "cpu", "", "CPU request of each pod, eg, '2000m' or '2'")
+	flags.StringVar(&o.flagMemory, "memory", "", "Memory request of each pod, eg, '4Gi'")
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt for staging and production environments")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *envScaleOpts) Prepare(cmd *cobra.Command, args []string) error {
+	o.hasReplicas = cmd.Flags().Changed("replicas")
+	if o.hasReplicas && o.flagReplicas < 1 {
+		return clierrors.NewUsageErrorf("Invalid --replicas %d", o.flagReplicas).
+			WithSuggestion("The shard set must have at least one pod; use 'metaplay remove server' to remove the game server")
+	}
+	if o.flagCPU != "" {
+		if _, err := resource.ParseQuantity(o.flagCPU); err != nil {
+			return clierrors.NewUsageErrorf("Invalid --cpu '%s'", o.flagCPU).
+				WithSuggestion("Specify the CPU request as a Kubernetes quantity, eg, '2000m' or '2'")
+		}
+	}
+	if o.flagMemory != "" {
+		if _, err := resource.ParseQuantity(o.flagMemory); err != nil {
+			return clierrors.NewUsageErrorf("Invalid --memory '%s'", o.flagMemory).
+				WithSuggestion("Specify the memory request as a Kubernetes quantity, eg, '4Gi' or '4096Mi'")
+		}
+	}
+	return nil
+}
+
+func (o *envScaleOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment and a Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm and find the game server release.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+	existingRelease, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err != nil {
+		return err
+	}
+	if existingRelease == nil {
+		return clierrors.Newf("No game server deployed in environment '%s'", envConfig.HumanID).
+			WithSuggestion(fmt.Sprintf("Deploy the game server first with 'metaplay deploy server %s'", o.argEnvironment))
+	}
+
+	// Show the current topology.
+	topology, err := envapi.FetchGameServerShardSetTopology(ctx, kubeCli)
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Game Server Shard Topology"))
+	log.Info().Msg("")
+	log.Info().Msgf("Environment:  %s", styles.RenderTechnical(envConfig.Name))
+	log.Info().Msgf("Helm release: %s", styles.RenderTechnical(existingRelease.Name))
+	log.Info().Msg("")
+	renderShardSetTopology(topology)
+	log.Info().Msg("")
+
+	change := shardScaleChange{CPU: o.flagCPU, Memory: o.flagMemory}
+	if o.hasReplicas {
+		change.Replicas = &o.flagReplicas
+	}
+	if change.Replicas == nil && change.CPU == "" && change.Memory == "" {
+		log.Info().Msg(styles.RenderMuted("Specify --replicas, --cpu, or --memory to scale a shard set."))
+		return nil
+	}
+
+	// Resolve the shard set to scale from the release's values.
+	shards, err := getReleaseShardsConfig(existingRelease.Config)
+	if err != nil {
+		return err
+	}
+	shardName, err := o.resolveShardName(shards, topology)
+	if err != nil {
+		return err
+	}
+	newShards, err := patchShardsConfig(shards, shardName, change)
+	if err != nil {
+		return err
+	}
+
+	// Show the change.
+	log.Info().Msgf("Scale shard set %s:", styles.RenderTechnical(shardName))
+	if change.Replicas != nil {
+		log.Info().Msgf("  Replicas: %s", styles.RenderTechnical(fmt.Sprintf("%d", *change.Replicas)))
+	}
+	if change.CPU != "" {
+		log.Info().Msgf("  CPU:      %s", styles.RenderTechnical(change.CPU))
+	}
+	if change.Memory != "" {
+		log.Info().Msgf("  Memory:   %s", styles.RenderTechnical(change.Memory))
+	}
+	log.Info().Msg("")
+
+	// With --dry-run, only show the steps.
+	if o.flagDryRun {
+		dryRun := dryRunPlan{}
+		dryRun.Addf("Upgrade Helm release %s with the patched shard config", styles.RenderTechnical(existingRelease.Name))
+		dryRun.Addf("Wait for the game server to be ready")
+		dryRun.Print()
+		return nil
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// Require confirmation for staging and production.
+	confirmed, err := confirmEnvironmentStateChange(ctx, envConfig, o.flagYes, fmt.Sprintf("Upgrade the game server in %s environment '%s' with the new shard config?", envConfig.Type, envConfig.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Scaling canceled."))
+		return nil
+	}
+
+	// Use the environment's post-renderer, like when deploying.
+	var postRenderer postrender.PostRenderer
+	if project != nil {
+		if postRendererPath := project.GetServerPostRenderer(envConfig); postRendererPath != "" {
+			postRenderer, err = helmutil.NewPostRenderer(postRendererPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Upgrade the release with the patched values and wait for the game server to be ready.
+	newValues := maps.Clone(existingRelease.Config)
+	newValues["shards"] = newShards
+	taskRunner := tui.NewTaskRunner()
+	taskRunner.AddTask("Upgrade game server shard config using Helm", func(output *tui.TaskOutput) error {
+		_, err := helmutil.UpgradeReleaseValues(output, actionConfig, existingRelease, newValues, postRenderer, 5*time.Minute)
+		return err
+	})
+	if err := targetEnv.WaitForServerToBeReady(ctx, taskRunner); err != nil {
+		return err
+	}
+	if err := taskRunner.Run(); err != nil {
+		return err
+	}
+
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Shard set %s scaled", shardName)))
+	log.Info().Msg("")
+
+	// Show how to persist the change in the values file.
+	shardsYAML, err := yaml.Marshal(map[string]any{"shards": newShards})
+	if err == nil {
+		log.Info().Msg("The next 'metaplay deploy server' uses the shard config from the Helm values files.")
+		log.Info().Msg("To keep the new config, add the following to the environment's Helm values file:")
+		log.Info().Msg("")
+		for _, line := range strings.Split(strings.TrimRight(string(shardsYAML), "\n"), "\n") {
+			log.Info().Msg("  " + styles.RenderTechnical(line))
+		}
+	}
+	return nil
+}
+
+// resolveShardName resolves the shard set to scale: the one given with --shard, the only one in
+// the config, or one chosen by the user in interactive mode.
+func (o *envScaleOpts) resolveShardName(shards []any, topology []envapi.ShardSetTopology) (string, error) {
+	names := getShardNames(shards)
+	if o.flagShard != "" {
+		for _, name := range names {
+			if name == o.flagShard {
+				return name, nil
+			}
+		}
+		return "", clierrors.Newf("Shard set '%s' not found in the game server config", o.flagShard).
+			WithSuggestion(fmt.Sprintf("Available shard sets: %s", strings.Join(names, ", ")))
+	}
+
+	if len(names) == 1 {
+		return names[0], nil
+	}
+	if !tui.CanAskQuestions() {
+		return "", clierrors.NewUsageError("Multiple shard sets in the game server config, specify the one to scale with --shard").
+			WithSuggestion(fmt.Sprintf("Available shard sets: %s", strings.Join(names, ", ")))
+	}
+
+	chosen, err := tui.ChooseFromTableDialog("Choose Shard Set to Scale", []string{"Shard set", "Ready", "CPU", "Memory"}, names, func(name *string) []string {
+		shardSet := findShardSetTopology(topology, *name)
+		if shardSet == nil {
+			return []string{*name, "-", "-", "-"}
+		}
+		return []string{*name, fmt.Sprintf("%d/%d", shardSet.ReadyReplicas, shardSet.Replicas), shardSet.CPURequest, shardSet.MemoryRequest}
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Info().Msgf(" %s %s", styles.RenderSuccess("✓"), *chosen)
+	log.Info().Msg("")
+	return *chosen, nil
+}
+
+// renderShardSetTopology prints the shard sets with their sizes and resource requests.
+func renderShardSetTopology(topology []envapi.ShardSetTopology) {
+	if len(topology) == 0 {
+		log.Info().Msg(styles.RenderMuted("No game server shard sets found"))
+		return
+	}
+	log.Info().Msgf("  %-24s %-8s %-10s %s", "SHARD SET", "READY", "CPU", "MEMORY")
+	for _, shardSet := range topology {
+		log.Info().Msgf("  %-24s %-8s %-10s %s",
+			shardSet.Name,
+			fmt.Sprintf("%d/%d", shardSet.ReadyReplicas, shardSet.Replicas),
+			coalesceString(shardSet.CPURequest, "-"),
+			coalesceString(shardSet.MemoryRequest, "-"))
+	}
+}
+
+// findShardSetTopology returns the shard set (StatefulSet) for the shard config name. The
+// StatefulSets may be prefixed, eg, 'mygame-all' for the shard 'all'.
+func findShardSetTopology(topology []envapi.ShardSetTopology, shardName string) *envapi.ShardSetTopology {
+	for ndx := range topology {
+		if topology[ndx].Name == shardName || strings.HasSuffix(topology[ndx].Name, "-"+shardName) {
+			return &topology[ndx]
+		}
+	}
+	return nil
+}
+
+// getReleaseShardsConfig returns the 'shards' list from the Helm release's values.
+func getReleaseShardsConfig(values map[string]any) ([]any, error) {
+	shards, ok := values["shards"].([]any)
+	if !ok || len(shards) == 0 {
+		return nil, clierrors.New("The deployed game server Helm release has no shard config").
+			WithSuggestion("Re-deploy the game server with 'metaplay deploy server' to use the latest Helm chart")
+	}
+	return shards, nil
+}
+
+// getShardNames returns the names of the shard sets in the shards config.
+func getShardNames(shards []any) []string {
+	names := []string{}
+	for _, shard := range shards {
+		if shardMap, ok := shard.(map[string]any); ok {
+			if name, ok := shardMap["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// patchShardsConfig returns a copy of the shards config with the change applied to the named
+// shard set. The other shard sets and the original config are not modified.
+func patchShardsConfig(shards []any, shardName string, change shardScaleChange) ([]any, error) {
+	result := make([]any, len(shards))
+	found := false
+	for ndx, shard := range shards {
+		shardMap, ok := shard.(map[string]any)
+		if !ok || shardMap["name"] != shardName {
+			result[ndx] = shard
+			continue
+		}
+		found = true
+
+		patched := maps.Clone(shardMap)
+		if change.Replicas != nil {
+			if singleton, _ := patched["singleton"].(bool); singleton && *change.Replicas != 1 {
+				return nil, clierrors.Newf("Shard set '%s' is a singleton and always has exactly one pod", shardName).
+					WithSuggestion("Only --cpu and --memory can be changed for singleton shard sets")
+			}
+			_, hasMin := patched["minNodeCount"]
+			_, hasMax := patched["maxNodeCount"]
+			if hasMin || hasMax {
+				return nil, clierrors.Newf("Shard set '%s' uses dynamic scaling with minNodeCount and maxNodeCount", shardName).
+					WithSuggestion("Change the node counts in the Helm values file and re-deploy the game server")
+			}
+			patched["nodeCount"] = *change.Replicas
+		}
+		if change.CPU != "" || change.Memory != "" {
+			requests := map[string]any{}
+			if existing, ok := patched["requests"].(map[string]any); ok {
+				requests = maps.Clone(existing)
+			}
+			if change.CPU != "" {
+				requests["cpu"] = change.CPU
+			}
+			if change.Memory != "" {
+				requests["memory"] = change.Memory
+			}
+			patched["requests"] = requests
+		}
+		result[ndx] = patched
+	}
+
+	if !found {
+		return nil, clierrors.Newf("Shard set '%s' not found in the game server config", shardName)
+	}
+	return result, nil
+}
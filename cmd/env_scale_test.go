@@ -0,0 +1,75 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testShardsConfig() []any {
+	return []any{
+		map[string]any{
+			"name":      "all",
+			"singleton": false,
+			"nodeCount": 1,
+			"requests":  map[string]any{"cpu": "500m", "memory": "1Gi"},
+		},
+		map[string]any{
+			"name":      "logic",
+			"singleton": true,
+			"requests":  map[string]any{"cpu": "250m", "memory": "512Mi"},
+		},
+		map[string]any{
+			"name":         "service",
+			"minNodeCount": 1,
+			"maxNodeCount": 4,
+		},
+	}
+}
+
+func TestPatchShardsConfig(t *testing.T) {
+	shards := testShardsConfig()
+	replicas := 3
+
+	patched, err := patchShardsConfig(shards, "all", shardScaleChange{Replicas: &replicas, CPU: "2000m"})
+	require.NoError(t, err)
+
+	all := patched[0].(map[string]any)
+	assert.Equal(t, 3, all["nodeCount"])
+	assert.Equal(t, map[string]any{"cpu": "2000m", "memory": "1Gi"}, all["requests"])
+
+	// Other shard sets and the original config are left intact.
+	assert.Equal(t, shards[1], patched[1])
+	original := shards[0].(map[string]any)
+	assert.Equal(t, 1, original["nodeCount"])
+	assert.Equal(t, "500m", original["requests"].(map[string]any)["cpu"])
+}
+
+func TestPatchShardsConfigSingleton(t *testing.T) {
+	replicas := 2
+	_, err := patchShardsConfig(testShardsConfig(), "logic", shardScaleChange{Replicas: &replicas})
+	assert.Error(t, err)
+
+	// Resources of singletons can be changed.
+	patched, err := patchShardsConfig(testShardsConfig(), "logic", shardScaleChange{Memory: "1Gi"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"cpu": "250m", "memory": "1Gi"}, patched[1].(map[string]any)["requests"])
+}
+
+func TestPatchShardsConfigErrors(t *testing.T) {
+	replicas := 2
+	_, err := patchShardsConfig(testShardsConfig(), "service", shardScaleChange{Replicas: &replicas})
+	assert.Error(t, err)
+
+	_, err = patchShardsConfig(testShardsConfig(), "missing", shardScaleChange{CPU: "1"})
+	assert.Error(t, err)
+}
+
+func TestGetShardNames(t *testing.T) {
+	assert.Equal(t, []string{"all", "logic", "service"}, getShardNames(testShardsConfig()))
+}
@@ -0,0 +1,333 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Number of recent warning and error log lines shown in 'env watch'.
+const envWatchNumLogLines = 10
+
+// Watch the state of the game server in an environment live.
+type envWatchOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+}
+
+// envWatchLogLine is a warning or error log line from a game server pod.
+type envWatchLogLine struct {
+	podName string
+	level   logLevel
+	message string // Rendered log line, eg, 'WRN [PlayerActor] Player session timed out'
+}
+
+// envWatchState is the latest known state of the environment, updated from the watches.
+type envWatchState struct {
+	mu          sync.Mutex
+	envName     string
+	pods        []envapi.GameServerPodInfo
+	release     *helmReleaseInfo // Game server Helm release (nil if not deployed)
+	releaseErr  error            // Error from fetching the release, if any
+	logLines    []envWatchLogLine
+	logStreams  map[string]context.CancelFunc // Log streams by container ID
+	lastUpdated time.Time
+}
+
+func init() {
+	o := envWatchOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "watch ENVIRONMENT [flags]",
+		Short: "[preview] Watch the game server in the target environment live",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			PREVIEW: This command is in preview and subject to change!
+
+			Watch the game server in the target environment live. The view shows:
+			- The state of the game server Helm release.
+			- The phase, restart count and age of each game server pod.
+			- The most recent warning and error log lines from the game server pods.
+
+			The view is updated as soon as anything changes, using Kubernetes watches. Only the
+			structured (JSON) log lines are recognized for the log levels. Press q to quit.
+
+			This command requires an interactive terminal.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay deploy status ENVIRONMENT' to show the status of the game server once.
+			- 'metaplay env check ENVIRONMENT' to check the health of the game server.
+			- 'metaplay debug logs ENVIRONMENT' to show the full game server logs.
+		`),
+		Example: renderExample(`
+			# Watch the game server in environment nimbly.
+			metaplay env watch nimbly
+		`),
+	}
+	envCmd.AddCommand(cmd)
+}
+
+func (o *envWatchOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if !tui.IsInteractiveMode() {
+		return clierrors.NewUsageError("'metaplay env watch' requires an interactive terminal").
+			WithSuggestion("Use 'metaplay deploy status' or 'metaplay env check' in non-interactive sessions")
+	}
+	return nil
+}
+
+func (o *envWatchOpts) Run(cmd *cobra.Command) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create a Kubernetes client.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	// Configure Helm.
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+
+	state := &envWatchState{
+		envName:    envConfig.Name,
+		logStreams: map[string]context.CancelFunc{},
+	}
+	view := tui.NewLiveView(state.render)
+
+	// Start the watches. The initial state is received before they return.
+	log.Info().Msgf("Connecting to environment %s...", styles.RenderTechnical(envConfig.Name))
+	err = envapi.WatchHelmReleases(ctx, kubeCli, func() {
+		state.updateRelease(actionConfig)
+		view.Refresh()
+	})
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to watch the Helm releases")
+	}
+	err = envapi.WatchGameServerPods(ctx, kubeCli, func(pods []envapi.GameServerPodInfo) {
+		state.updatePods(ctx, kubeCli, pods, view.Refresh)
+		view.Refresh()
+	})
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to watch the game server pods")
+	}
+
+	return view.Run()
+}
+
+// updateRelease fetches the latest game server Helm release.
+func (state *envWatchState) updateRelease(actionConfig *action.Configuration) {
+	var releaseInfo *helmReleaseInfo
+	release, err := helmutil.GetExistingRelease(actionConfig, metaplayGameServerChartName)
+	if err == nil && release != nil {
+		releaseInfo, err = getHelmReleaseInfo(release)
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.release = releaseInfo
+	state.releaseErr = err
+	state.lastUpdated = time.Now()
+}
+
+// updatePods updates the pods, and starts following the logs of the new game server containers
+// and stops following the ones that are gone.
+func (state *envWatchState) updatePods(ctx context.Context, kubeCli *envapi.KubeClient, pods []envapi.GameServerPodInfo, onLogLine func()) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.pods = pods
+	state.lastUpdated = time.Now()
+
+	running := map[string]bool{}
+	for _, pod := range pods {
+		if !pod.Running || pod.ContainerID == "" {
+			continue
+		}
+		running[pod.ContainerID] = true
+		if _, found := state.logStreams[pod.ContainerID]; !found {
+			streamCtx, cancel := context.WithCancel(ctx)
+			state.logStreams[pod.ContainerID] = cancel
+			go state.followPodWarnings(streamCtx, kubeCli, pod.Name, onLogLine)
+		}
+	}
+	for containerID, cancel := range state.logStreams {
+		if !running[containerID] {
+			cancel()
+			delete(state.logStreams, containerID)
+		}
+	}
+}
+
+// followPodWarnings follows the logs of the game server container in the pod and collects the
+// warning and error lines. Only the lines logged after starting are collected, to avoid showing
+// the same lines again after the pod has been watched for a while.
+func (state *envWatchState) followPodWarnings(ctx context.Context, kubeCli *envapi.KubeClient, podName string, onLogLine func()) {
+	var numTailLines int64 = 0
+	req := kubeCli.Clientset.CoreV1().Pods(kubeCli.Namespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: "shard-server",
+		Follow:    true,
+		TailLines: &numTailLines,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		log.Debug().Msgf("Failed to follow logs of pod %s: %v", podName, err)
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	filter := logEntryFilter{minLevel: logLevelWarning}
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		structured := parseStructuredLogLine(scanner.Text())
+		if !filter.matches(scanner.Text(), structured) {
+			continue
+		}
+
+		state.mu.Lock()
+		state.logLines = appendEnvWatchLogLine(state.logLines, envWatchLogLine{
+			podName: podName,
+			level:   structured.level,
+			message: structured.render(),
+		})
+		state.mu.Unlock()
+		onLogLine()
+	}
+}
+
+// appendEnvWatchLogLine appends the line and drops the oldest lines beyond the shown amount.
+func appendEnvWatchLogLine(lines []envWatchLogLine, line envWatchLogLine) []envWatchLogLine {
+	lines = append(lines, line)
+	if len(lines) > envWatchNumLogLines {
+		lines = lines[len(lines)-envWatchNumLogLines:]
+	}
+	return lines
+}
+
+// render renders the current state of the environment for the live view.
+func (state *envWatchState) render(width int) string {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n%s %s\n\n", styles.RenderTitle("Environment"), styles.RenderTechnical(state.envName))
+
+	// Helm release.
+	sb.WriteString("Helm release:\n")
+	switch {
+	case state.releaseErr != nil:
+		fmt.Fprintf(&sb, "  %s\n", styles.RenderError(fmt.Sprintf("Failed to get the release: %v", state.releaseErr)))
+	case state.release == nil:
+		fmt.Fprintf(&sb, "  %s\n", styles.RenderMuted("No game server deployed"))
+	default:
+		fmt.Fprintf(&sb, "  %s %s, revision %d, chart %s, deployed %s\n",
+			styles.RenderTechnical(state.release.Name),
+			renderHelmReleaseStatus(state.release.Status),
+			state.release.Revision,
+			state.release.ChartVersion,
+			humanize.Time(state.release.LastDeployed))
+	}
+
+	// Pods.
+	sb.WriteString("\nGame server pods:\n")
+	if len(state.pods) == 0 {
+		fmt.Fprintf(&sb, "  %s\n", styles.RenderMuted("No game server pods"))
+	} else {
+		fmt.Fprintf(&sb, "  %s\n", styles.RenderMuted(fmt.Sprintf("%-24s %-10s %-9s %-8s %s", "NAME", "PHASE", "RESTARTS", "AGE", "MESSAGE")))
+		for _, pod := range state.pods {
+			restarts := fmt.Sprintf("%-9d", pod.RestartCount)
+			if pod.RestartCount > 0 {
+				restarts = styles.RenderWarning(restarts)
+			}
+			fmt.Fprintf(&sb, "  %-24s %s %s %-8s %s\n",
+				pod.Name,
+				renderPodPhase(pod.Phase)+strings.Repeat(" ", max(0, 10-len(pod.Phase))),
+				restarts,
+				formatAge(time.Since(pod.CreatedAt)),
+				styles.RenderMuted(truncateEnvWatchLine(pod.Message, width-60)))
+		}
+	}
+
+	// Log lines.
+	sb.WriteString("\nRecent warnings and errors:\n")
+	if len(state.logLines) == 0 {
+		fmt.Fprintf(&sb, "  %s\n", styles.RenderMuted("None since the watch was started"))
+	}
+	for _, line := range state.logLines {
+		// Only show the first line of multi-line entries, eg, with exceptions.
+		message, _, _ := strings.Cut(line.message, "\n")
+		message = truncateEnvWatchLine(message, width-len(line.podName)-3)
+		if line.level >= logLevelError {
+			message = styles.RenderError(message)
+		} else {
+			message = styles.RenderWarning(message)
+		}
+		fmt.Fprintf(&sb, "  %s %s\n", styles.RenderMuted(line.podName), message)
+	}
+
+	fmt.Fprintf(&sb, "\n%s", styles.RenderMuted(fmt.Sprintf("Last updated %s", state.lastUpdated.Format(time.TimeOnly))))
+	return sb.String()
+}
+
+// renderHelmReleaseStatus renders the Helm release status with a color matching its health.
+func renderHelmReleaseStatus(status string) string {
+	switch status {
+	case "deployed":
+		return styles.RenderSuccess(status)
+	case "failed":
+		return styles.RenderError(status)
+	default:
+		return styles.RenderWarning(status)
+	}
+}
+
+// truncateEnvWatchLine truncates the line to the given width, if the width is known.
+func truncateEnvWatchLine(line string, width int) string {
+	runes := []rune(line)
+	if width <= 0 || len(runes) <= width {
+		return line
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendEnvWatchLogLine(t *testing.T) {
+	var lines []envWatchLogLine
+	for ndx := range envWatchNumLogLines + 3 {
+		lines = appendEnvWatchLogLine(lines, envWatchLogLine{podName: "all-0", message: fmt.Sprintf("line %d", ndx)})
+	}
+	assert.Len(t, lines, envWatchNumLogLines)
+	assert.Equal(t, "line 3", lines[0].message)
+	assert.Equal(t, fmt.Sprintf("line %d", envWatchNumLogLines+2), lines[len(lines)-1].message)
+}
+
+func TestTruncateEnvWatchLine(t *testing.T) {
+	assert.Equal(t, "short", truncateEnvWatchLine("short", 10))
+	assert.Equal(t, "unlimited width", truncateEnvWatchLine("unlimited width", 0))
+	assert.Equal(t, "too lon...", truncateEnvWatchLine("too long for the terminal", 10))
+	assert.Equal(t, "to", truncateEnvWatchLine("too long", 2))
+}
+
+func TestEnvWatchStateRender(t *testing.T) {
+	state := &envWatchState{
+		envName: "nimbly",
+		release: &helmReleaseInfo{
+			Name:         "lovely-wombats-nimbly-gameserver",
+			Status:       "deployed",
+			Revision:     3,
+			ChartVersion: "0.8.1",
+			LastDeployed: time.Now().Add(-time.Hour),
+		},
+		pods: []envapi.GameServerPodInfo{
+			{Name: "all-0", Phase: envapi.PhaseReady, RestartCount: 1, CreatedAt: time.Now().Add(-10 * time.Minute)},
+		},
+		logLines: []envWatchLogLine{
+			{podName: "all-0", level: logLevelError, message: "ERR [PlayerActor] Something failed\nStack trace"},
+		},
+	}
+
+	output := state.render(200)
+	assert.Contains(t, output, "lovely-wombats-nimbly-gameserver")
+	assert.Contains(t, output, "revision 3, chart 0.8.1")
+	assert.Contains(t, output, "all-0")
+	assert.Contains(t, output, "ERR [PlayerActor] Something failed")
+	assert.NotContains(t, output, "Stack trace")
+
+	// Release errors and missing pods are shown.
+	state = &envWatchState{envName: "nimbly", releaseErr: errors.New("forbidden")}
+	output = state.render(0)
+	assert.Contains(t, output, "Failed to get the release: forbidden")
+	assert.Contains(t, output, "No game server pods")
+	assert.Contains(t, output, "None since the watch was started")
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Extended documentation of the commands, one markdown file per command. The files are named
+// after the command path without the 'metaplay' prefix, eg, 'deploy-server.md'.
+//
+//go:embed explain_docs/*.md
+var explainDocsFS embed.FS
+
+// Explain a command in depth.
+type explainOpts struct {
+	UsePositionalArgs
+
+	argCommand []string
+}
+
+func init() {
+	o := explainOpts{}
+
+	args := o.Arguments()
+	args.SetExtraArgs(&o.argCommand, "Command to explain, eg, 'deploy server'. Lists the documented commands if omitted.")
+
+	cmd := &cobra.Command{
+		Use:   "explain [COMMAND...]",
+		Short: "Explain what a command does, what it needs and how to fix common failures",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show extended documentation for a command, beyond the '--help' output:
+			- What the command does and what it touches, eg, your cloud environment or local Docker.
+			- What is needed before running it.
+			- Worked examples of typical use.
+			- Typical failures and how to fix them.
+
+			Only the most commonly used commands are documented. Run without arguments to list
+			them. For other commands, the '--help' output is shown instead.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay COMMAND --help' to show the flags and arguments of a command.
+		`),
+		Example: renderExample(`
+			# List the commands with extended documentation.
+			metaplay explain
+
+			# Explain how deploying a game server works.
+			metaplay explain deploy server
+		`),
+	}
+
+	cmd.GroupID = "other"
+	rootCmd.AddCommand(cmd)
+}
+
+func (o *explainOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *explainOpts) Run(cmd *cobra.Command) error {
+	// Without arguments, list the documented commands.
+	if len(o.argCommand) == 0 {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Commands with extended documentation"))
+		log.Info().Msg("")
+		for _, target := range getExplainedCommands() {
+			log.Info().Msgf("  %s %s", styles.RenderTechnical(fmt.Sprintf("%-16s", getExplainTopic(target))), target.Short)
+		}
+		log.Info().Msg("")
+		log.Info().Msgf("Run %s to explain a command.", styles.RenderPrompt("metaplay explain COMMAND"))
+		return nil
+	}
+
+	// Resolve the command, so that aliases work too.
+	target, remainingArgs, err := rootCmd.Find(o.argCommand)
+	if err != nil || target == rootCmd || len(remainingArgs) > 0 {
+		return clierrors.NewUsageErrorf("Unknown command 'metaplay %s'", strings.Join(o.argCommand, " ")).
+			WithSuggestion("Run 'metaplay explain' to list the documented commands")
+	}
+
+	// Fall back to the long help of commands without extended documentation.
+	content, found := readExplainDoc(target)
+	if !found {
+		log.Info().Msgf("No extended documentation for %s, showing its help instead.", styles.RenderTechnical(target.CommandPath()))
+		log.Info().Msg("")
+		log.Info().Msg(coalesceString(target.Long, target.Short))
+		log.Info().Msg("")
+		log.Info().Msgf("Run %s to list the commands with extended documentation.", styles.RenderPrompt("metaplay explain"))
+		return nil
+	}
+
+	printMarkdownContent(content)
+	log.Info().Msgf("Run %s for all the flags and arguments.", styles.RenderPrompt(target.CommandPath()+" --help"))
+	return nil
+}
+
+// getExplainTopic returns the name of the command without the 'metaplay' prefix, eg, 'deploy server'.
+func getExplainTopic(target *cobra.Command) string {
+	return strings.TrimPrefix(target.CommandPath(), rootCmd.Name()+" ")
+}
+
+// getExplainDocPath returns the path of the extended documentation of the command in the
+// embedded filesystem, eg, 'explain_docs/deploy-server.md'.
+func getExplainDocPath(target *cobra.Command) string {
+	return path.Join("explain_docs", strings.ReplaceAll(getExplainTopic(target), " ", "-")+".md")
+}
+
+// readExplainDoc reads the extended documentation of the command, if it has any.
+func readExplainDoc(target *cobra.Command) ([]byte, bool) {
+	content, err := explainDocsFS.ReadFile(getExplainDocPath(target))
+	if err != nil {
+		return nil, false
+	}
+	return content, true
+}
+
+// getExplainedCommands returns the commands that have extended documentation, sorted by name.
+func getExplainedCommands() []*cobra.Command {
+	// The pattern is constant and valid, so globbing cannot fail.
+	docPaths, _ := fs.Glob(explainDocsFS, "explain_docs/*.md")
+
+	var commands []*cobra.Command
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		if cmd != rootCmd && slices.Contains(docPaths, getExplainDocPath(cmd)) {
+			commands = append(commands, cmd)
+		}
+		for _, child := range cmd.Commands() {
+			visit(child)
+		}
+	}
+	visit(rootCmd)
+
+	slices.SortFunc(commands, func(a, b *cobra.Command) int {
+		return strings.Compare(a.CommandPath(), b.CommandPath())
+	})
+	return commands
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainDocsMatchCommands(t *testing.T) {
+	docPaths, err := fs.Glob(explainDocsFS, "explain_docs/*.md")
+	require.NoError(t, err)
+	require.NotEmpty(t, docPaths)
+
+	// Each doc must belong to an existing command, so that renamed commands don't leave
+	// orphaned docs behind.
+	commands := getExplainedCommands()
+	assert.Len(t, commands, len(docPaths))
+
+	for _, target := range commands {
+		content, found := readExplainDoc(target)
+		require.True(t, found, target.CommandPath())
+
+		doc := string(content)
+		assert.True(t, strings.HasPrefix(doc, "# "+target.CommandPath()+"\n"), "%s: doc must start with the command title", target.CommandPath())
+		for _, section := range []string{"## What it touches", "## Prerequisites", "## Worked examples", "## Typical failures and fixes", "## Related commands"} {
+			assert.Contains(t, doc, "\n"+section+"\n", "%s: missing section", target.CommandPath())
+		}
+	}
+}
+
+func TestExplainResolvesAliases(t *testing.T) {
+	// 'srv' is an alias of 'deploy server'.
+	target, _, err := rootCmd.Find([]string{"deploy", "srv"})
+	require.NoError(t, err)
+	assert.Equal(t, "explain_docs/deploy-server.md", getExplainDocPath(target))
+	assert.Equal(t, "deploy server", getExplainTopic(target))
+
+	_, found := readExplainDoc(target)
+	assert.True(t, found)
+
+	version, _, err := rootCmd.Find([]string{"version"})
+	require.NoError(t, err)
+	_, found = readExplainDoc(version)
+	assert.False(t, found)
+}
@@ -5,6 +5,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -104,29 +105,6 @@ func (o *getEnvironmentInfoOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	// Get Kubernetes client to fetch database information
-	kubeCli, err := targetEnv.GetPrimaryKubeClient()
-	if err != nil {
-		log.Debug().Err(err).Msg("Failed to get Kubernetes client for database info")
-		kubeCli = nil // Continue without database info
-	}
-
-	// Fetch database shard configuration if Kubernetes client is available
-	var shards []kubeutil.DatabaseShardConfig
-	if kubeCli != nil {
-		log.Debug().Str("namespace", kubeCli.Namespace).Msg("Fetching database shard configuration")
-		shards, err = kubeutil.FetchDatabaseShardsFromSecret(cmd.Context(), kubeCli, kubeCli.Namespace)
-		if err != nil {
-			log.Debug().Err(err).Msg("Failed to fetch database shard configuration")
-			shards = nil // Continue without database info
-		} else {
-			// Fill in shard indices
-			for shardNdx := range shards {
-				shards[shardNdx].ShardIndex = shardNdx
-			}
-		}
-	}
-
 	// Only fetch portal info if targeting a managed stack.
 	var portalInfo *portalapi.EnvironmentInfo
 	authProviderName := coalesceString(envConfig.AuthProvider, "metaplay")
@@ -191,15 +169,43 @@ func (o *getEnvironmentInfoOpts) Run(cmd *cobra.Command) error {
 		log.Info().Msgf("  Email domain:         %s", styles.RenderTechnical(oauth2Client.EmailDomain))
 		log.Info().Msgf("")
 
-		// Database information
+		// Database information (only fetched for the text output as it requires Kubernetes access)
 		// \todo Show high-level information like the database type (eg, local Maria vs Aurora RDS)
-		shard0 := shards[0]
-		shardBadge := styles.RenderMuted("[shard #0]")
+		shards := tryFetchDatabaseShards(cmd.Context(), targetEnv)
 		log.Info().Msgf("Database:")
-		log.Info().Msgf("  Shards:               %s", styles.RenderTechnical(fmt.Sprintf("%d", len(shards))))
-		log.Info().Msgf("  Database name:        %s", styles.RenderTechnical(shard0.DatabaseName))
-		log.Info().Msgf("  Read-write host:      %s %s", styles.RenderTechnical(shard0.ReadWriteHost), shardBadge)
-		log.Info().Msgf("  Read-only host:       %s %s", styles.RenderTechnical(shard0.ReadOnlyHost), shardBadge)
+		if len(shards) == 0 {
+			log.Info().Msgf("  %s", styles.RenderMuted("Database information not available"))
+		} else {
+			shard0 := shards[0]
+			shardBadge := styles.RenderMuted("[shard #0]")
+			log.Info().Msgf("  Shards:               %s", styles.RenderTechnical(fmt.Sprintf("%d", len(shards))))
+			log.Info().Msgf("  Database name:        %s", styles.RenderTechnical(shard0.DatabaseName))
+			log.Info().Msgf("  Read-write host:      %s %s", styles.RenderTechnical(shard0.ReadWriteHost), shardBadge)
+			log.Info().Msgf("  Read-only host:       %s %s", styles.RenderTechnical(shard0.ReadOnlyHost), shardBadge)
+		}
 	}
 	return nil
 }
+
+// tryFetchDatabaseShards fetches the database shard configuration of the environment from
+// Kubernetes. Returns nil if the information is not available, eg, due to missing access.
+func tryFetchDatabaseShards(ctx context.Context, targetEnv *envapi.TargetEnvironment) []kubeutil.DatabaseShardConfig {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to get Kubernetes client for database info")
+		return nil
+	}
+
+	log.Debug().Str("namespace", kubeCli.Namespace).Msg("Fetching database shard configuration")
+	shards, err := kubeutil.FetchDatabaseShardsFromSecret(ctx, kubeCli, kubeCli.Namespace)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to fetch database shard configuration")
+		return nil
+	}
+
+	// Fill in shard indices
+	for shardNdx := range shards {
+		shards[shardNdx].ShardIndex = shardNdx
+	}
+	return shards
+}
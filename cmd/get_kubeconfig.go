@@ -53,6 +53,9 @@ func init() {
 			argument AUTH_PROVIDER.
 
 			{Arguments}
+
+			Related commands:
+			- 'metaplay env get-kubeconfig ENVIRONMENT' to export a kubeconfig for the environment's auth provider.
 		`),
 		Example: renderExample(`
 			# Get KubeConfig for environment nimbly with dynamic credentials
@@ -115,37 +118,53 @@ func (o *getKubeConfigOpts) Run(cmd *cobra.Command) error {
 		}
 	}
 
-	// Generate kubeconfig
+	// Generate kubeconfig and write it to a file or stdout.
+	kubeconfigPayload, err := generateKubeConfig(targetEnv, authProvider, tokenSet, credentialsType)
+	if err != nil {
+		return err
+	}
+	return writeKubeConfig(kubeconfigPayload, o.flagOutput)
+}
+
+// generateKubeConfig generates the kubeconfig for the target environment with the given
+// credentials type: 'dynamic' invokes the CLI as an exec credential plugin to fetch fresh
+// credentials, and 'static' embeds short-lived credentials in the kubeconfig.
+func generateKubeConfig(targetEnv *envapi.TargetEnvironment, authProvider *auth.AuthProviderConfig, tokenSet *auth.TokenSet, credentialsType string) (string, error) {
 	var kubeconfigPayload string
+	var err error
 	switch credentialsType {
 	case "dynamic":
 		// Fetch the userinfo for an email.
 		var userinfo *auth.UserInfoResponse
 		userinfo, err = auth.FetchUserInfo(authProvider, tokenSet)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		kubeconfigPayload, err = targetEnv.GetKubeConfigWithExecCredential(userinfo.Email)
 	case "static":
 		kubeconfigPayload, err = targetEnv.GetKubeConfigWithEmbeddedCredentials()
 	default:
-		return clierrors.NewUsageErrorf("Invalid credentials type '%s'", credentialsType).
+		return "", clierrors.NewUsageErrorf("Invalid credentials type '%s'", credentialsType).
 			WithSuggestion("Use --type=static or --type=dynamic")
 	}
 
 	if err != nil {
-		return clierrors.Wrap(err, "Failed to get environment kubeconfig")
+		return "", clierrors.Wrap(err, "Failed to get environment kubeconfig")
 	}
+	return kubeconfigPayload, nil
+}
 
-	// Write the kubeconfig payload to a file or stdout.
-	if o.flagOutput != "" {
-		log.Debug().Msgf("Write kubeconfig to file %s", o.flagOutput)
-		err = os.WriteFile(o.flagOutput, []byte(kubeconfigPayload), 0600)
+// writeKubeConfig writes the kubeconfig payload to the output file, or to stdout if no
+// output file is given.
+func writeKubeConfig(kubeconfigPayload string, outputPath string) error {
+	if outputPath != "" {
+		log.Debug().Msgf("Write kubeconfig to file %s", outputPath)
+		err := os.WriteFile(outputPath, []byte(kubeconfigPayload), 0600)
 		if err != nil {
 			return fmt.Errorf("failed to write kubeconfig to file: %v", err)
 		}
-		log.Info().Msgf("Wrote kubeconfig to %s", o.flagOutput)
+		log.Info().Msgf("Wrote kubeconfig to %s", outputPath)
 	} else {
 		log.Info().Msg(kubeconfigPayload)
 	}
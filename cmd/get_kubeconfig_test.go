@@ -0,0 +1,37 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteKubeConfig(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "kubeconfig.yaml")
+	require.NoError(t, writeKubeConfig("apiVersion: v1\n", outputPath))
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\n", string(content))
+
+	// The kubeconfig contains credentials, so it must only be readable by the user.
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestGenerateKubeConfigInvalidType(t *testing.T) {
+	_, err := generateKubeConfig(nil, nil, nil, "bogus")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid credentials type 'bogus'")
+}
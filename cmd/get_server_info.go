@@ -216,12 +216,12 @@ func (o *getServerInfoOpts) gatherDeployedServerInfo(ctx context.Context, target
 	var helmInfo *helmReleaseInfo
 	var imageInfo *deploymentImageInfo
 	if existingRelease != nil {
-		helmInfo, err = o.getHelmReleaseInfo(existingRelease)
+		helmInfo, err = getHelmReleaseInfo(existingRelease)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get Helm release info: %w", err)
 		}
 
-		imageInfo, err = o.getImageInfo(ctx, targetEnv, existingRelease)
+		imageInfo, err = getDeployedImageInfo(ctx, targetEnv, existingRelease)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get image info: %w", err)
 		}
@@ -236,7 +236,7 @@ func (o *getServerInfoOpts) gatherDeployedServerInfo(ctx context.Context, target
 }
 
 // Extract the Helm release information from a release object into a simpler info class.
-func (o *getServerInfoOpts) getHelmReleaseInfo(releaseInfo *release.Release) (*helmReleaseInfo, error) {
+func getHelmReleaseInfo(releaseInfo *release.Release) (*helmReleaseInfo, error) {
 	helmInfo := &helmReleaseInfo{
 		Name:         releaseInfo.Name,
 		Status:       releaseInfo.Info.Status.String(),
@@ -254,7 +254,7 @@ func (o *getServerInfoOpts) getHelmReleaseInfo(releaseInfo *release.Release) (*h
 }
 
 // Extract information about the docker image used in the game server deployment.
-func (o *getServerInfoOpts) getImageInfo(ctx context.Context, targetEnv *envapi.TargetEnvironment, existingRelease *release.Release) (*deploymentImageInfo, error) {
+func getDeployedImageInfo(ctx context.Context, targetEnv *envapi.TargetEnvironment, existingRelease *release.Release) (*deploymentImageInfo, error) {
 	// Extract image information from Helm release values.
 	var imageTag, fullImageRef string
 	if existingRelease.Config != nil {
@@ -276,14 +276,8 @@ func (o *getServerInfoOpts) getImageInfo(ctx context.Context, targetEnv *envapi.
 		return nil, fmt.Errorf("no image information found in Helm release")
 	}
 
-	// Get environment details.
-	envDetails, err := targetEnv.GetDetails()
-	if err != nil {
-		return nil, err
-	}
-
 	// Get docker credentials for the image registry.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return nil, err
 	}
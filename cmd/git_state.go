@@ -0,0 +1,45 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// isGitWorkingTreeDirty returns true if the git working tree at dir has uncommitted changes to
+// the tracked files. Untracked files are ignored as build outputs and such are commonly left
+// untracked. Returns an error if the directory is not in a git repository or git is not available.
+func isGitWorkingTreeDirty(ctx context.Context, dir string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// isGitCommitPushed returns whether the commit is contained in any remote-tracking branch of the
+// git repository at dir, based on the last fetch. Returns an error if the commit is not known
+// in the repository, or the directory is not in a git repository.
+func isGitCommitPushed(ctx context.Context, dir string, commitID string) (bool, error) {
+	// Check that the commit exists locally.
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-e", commitID+"^{commit}")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return false, err
+	}
+
+	// List the remote branches containing the commit.
+	cmd = exec.CommandContext(ctx, "git", "branch", "--remotes", "--contains", commitID)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// imageDiffOpts holds the options for the 'image diff' command.
+type imageDiffOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	argImageTagA   string
+	argImageTagB   string
+	flagFormat     string
+	flagLimit      int
+	flagLayersOnly bool
+}
+
+func init() {
+	o := imageDiffOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argImageTagA, "TAG_A", "Docker image tag of the baseline image, eg, '364cff09'.")
+	args.AddStringArgument(&o.argImageTagB, "TAG_B", "Docker image tag of the image to compare against the baseline, eg, '1a27c25753'.")
+
+	cmd := &cobra.Command{
+		Use:   "diff ENVIRONMENT TAG_A TAG_B [flags]",
+		Short: "Compare the layers and files of two Docker images in the target environment's repository",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Compare two game server Docker images in the target environment's image repository.
+
+			Shows the layers added and removed between the images, the change in total (compressed)
+			image size, and the files that were added, removed or modified, sorted by the largest
+			size change first. Useful for finding out why an image suddenly grew in size.
+
+			Comparing the files requires downloading both images in full, which can take a while for
+			large images. Use --layers-only to only compare the layers.
+
+			{Arguments}
+
+			Related commands:
+			- List the images in the repository using 'metaplay image list ...'.
+			- Pull an image to the local machine using 'metaplay image pull ...'.
+		`),
+		Example: renderExample(`
+			# Compare image '1a27c25753' against '364cff09' in environment 'lovely-wombats-build-nimbly'.
+			metaplay image diff lovely-wombats-build-nimbly 364cff09 1a27c25753
+
+			# Only compare the image layers, skipping the file comparison.
+			metaplay image diff lovely-wombats-build-nimbly 364cff09 1a27c25753 --layers-only
+
+			# Output all changed files in JSON format.
+			metaplay image diff lovely-wombats-build-nimbly 364cff09 1a27c25753 --format=json --limit=0
+		`),
+	}
+
+	imageCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+	flags.IntVar(&o.flagLimit, "limit", 20, "Maximum number of changed files to show (0 for all)")
+	flags.BoolVar(&o.flagLayersOnly, "layers-only", false, "Only compare the image layers, not the files in them")
+}
+
+func (o *imageDiffOpts) Prepare(cmd *cobra.Command, args []string) error {
+	for _, tag := range []string{o.argImageTagA, o.argImageTagB} {
+		if tag == "" || strings.Contains(tag, ":") {
+			return clierrors.NewUsageErrorf("Invalid image tag '%s'", tag).
+				WithDetails("Tag must be a valid docker tag (cannot be empty or contain ':')").
+				WithSuggestion("Use just the tags, for example 'metaplay image diff lovely-wombats-build-nimbly 364cff09 1a27c25753'")
+		}
+	}
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	if o.flagLimit < 0 {
+		return clierrors.NewUsageErrorf("Invalid limit %d", o.flagLimit).
+			WithSuggestion("Use a non-negative number (0 for all)")
+	}
+	return nil
+}
+
+func (o *imageDiffOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Get environment details.
+	envDetails, err := targetEnv.GetDetails()
+	if err != nil {
+		return err
+	}
+
+	// Get docker credentials.
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
+	if err != nil {
+		return err
+	}
+
+	imageRefA := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, o.argImageTagA)
+	imageRefB := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, o.argImageTagB)
+
+	// Compute the diff. Use a task runner for progress in text mode, keep JSON output clean.
+	var diff *envapi.ImageDiff
+	computeDiff := func() error {
+		diff, err = envapi.FetchRemoteDockerImageDiff(dockerCredentials, imageRefA, imageRefB, !o.flagLayersOnly)
+		return err
+	}
+	if o.flagFormat == "json" {
+		if err := computeDiff(); err != nil {
+			return err
+		}
+	} else {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Compare Docker Images"))
+		log.Info().Msg("")
+		log.Info().Msgf("Environment: %s", styles.RenderTechnical(envConfig.HumanID))
+		log.Info().Msgf("Image A:     %s", styles.RenderTechnical(o.argImageTagA))
+		log.Info().Msgf("Image B:     %s", styles.RenderTechnical(o.argImageTagB))
+		log.Info().Msg("")
+
+		taskRunner := tui.NewTaskRunner()
+		taskTitle := "Compare image layers and files"
+		if o.flagLayersOnly {
+			taskTitle = "Compare image layers"
+		}
+		taskRunner.AddTask(taskTitle, func(output *tui.TaskOutput) error {
+			return computeDiff()
+		})
+		if err := taskRunner.Run(); err != nil {
+			return err
+		}
+	}
+
+	// Apply limit to the changed files.
+	numFileChanges := len(diff.FileChanges)
+	if o.flagLimit > 0 && numFileChanges > o.flagLimit {
+		diff.FileChanges = diff.FileChanges[:o.flagLimit]
+	}
+
+	if o.flagFormat == "json" {
+		diffJSON, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal image diff as JSON")
+		}
+		log.Info().Msg(string(diffJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Size"))
+	log.Info().Msg("")
+	log.Info().Msgf("  Image A:    %s", formatImageSize(diff.SizeA))
+	log.Info().Msgf("  Image B:    %s", formatImageSize(diff.SizeB))
+	log.Info().Msgf("  Change:     %s", renderImageSizeDelta(diff.SizeDelta))
+	log.Info().Msg("")
+
+	log.Info().Msg(styles.RenderTitle("Layers"))
+	log.Info().Msg("")
+	log.Info().Msgf("  Shared:     %d", diff.NumSharedLayers)
+	log.Info().Msgf("  Added:      %d", len(diff.AddedLayers))
+	log.Info().Msgf("  Removed:    %d", len(diff.RemovedLayers))
+	if len(diff.AddedLayers) > 0 || len(diff.RemovedLayers) > 0 {
+		log.Info().Msg("")
+		for _, layer := range diff.RemovedLayers {
+			log.Info().Msgf("  %s %10s  %s", styles.RenderError("-"), formatImageSize(layer.Size), renderImageLayerSource(layer))
+		}
+		for _, layer := range diff.AddedLayers {
+			log.Info().Msgf("  %s %10s  %s", styles.RenderSuccess("+"), formatImageSize(layer.Size), renderImageLayerSource(layer))
+		}
+	}
+	log.Info().Msg("")
+
+	if !o.flagLayersOnly {
+		log.Info().Msg(styles.RenderTitle("Changed Files"))
+		log.Info().Msg("")
+		if numFileChanges == 0 {
+			log.Info().Msg("  No files changed.")
+		} else {
+			log.Info().Msgf("  %-8s  %11s  %s", "CHANGE", "SIZE DELTA", "PATH")
+			for _, change := range diff.FileChanges {
+				log.Info().Msgf("  %-8s  %11s  %s", change.Change, renderImageSizeDelta(change.SizeDelta), styles.RenderTechnical(change.Path))
+			}
+			if len(diff.FileChanges) < numFileChanges {
+				log.Info().Msg("")
+				log.Info().Msg(styles.RenderMuted(fmt.Sprintf("  Showing %d of %d changed files. Use --limit to see more.", len(diff.FileChanges), numFileChanges)))
+			}
+		}
+		log.Info().Msg("")
+	}
+
+	return nil
+}
+
+// renderImageSizeDelta renders a size change with an explicit sign.
+func renderImageSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatImageSize(-delta)
+	}
+	return "+" + formatImageSize(delta)
+}
+
+// renderImageLayerSource describes where the layer came from: the Dockerfile instruction if
+// known, otherwise the layer digest.
+func renderImageLayerSource(layer envapi.ImageLayerInfo) string {
+	if layer.CreatedBy == "" {
+		return styles.RenderMuted(layer.Digest)
+	}
+	createdBy := strings.TrimPrefix(layer.CreatedBy, "/bin/sh -c ")
+	createdBy = strings.TrimPrefix(createdBy, "#(nop) ")
+	if len(createdBy) > 100 {
+		createdBy = createdBy[:97] + "..."
+	}
+	return createdBy
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// imageInspectResult is the Metaplay metadata of a remote image, as output by 'image inspect'.
+type imageInspectResult struct {
+	Image       string    `json:"image"`
+	Tag         string    `json:"tag"`
+	ProjectID   string    `json:"projectId"`
+	SdkVersion  string    `json:"sdkVersion"`
+	CommitID    string    `json:"commitId"`
+	BuildNumber string    `json:"buildNumber"`
+	CreatedAt   time.Time `json:"createdAt"`
+	IsDirty     bool      `json:"isDirty"`
+	Platforms   []string  `json:"platforms"`
+}
+
+// Inspect the Metaplay metadata of an image in the environment's image repository.
+type imageInspectOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	argImageTag    string
+	flagFormat     string
+}
+
+func init() {
+	o := imageInspectOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argImageTag, "TAG", "Docker image tag to inspect, eg, '364cff09'.")
+
+	cmd := &cobra.Command{
+		Use:   "inspect ENVIRONMENT TAG [flags]",
+		Short: "Show the Metaplay metadata of an image in the target environment's image repository",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show the Metaplay metadata of a game server image in the target environment's image
+			repository (ECR): the project ID, Metaplay SDK version, commit ID, build number, and
+			creation time from the image labels, and the platforms that the image is built for.
+
+			The image is inspected in the registry directly, without pulling it and without needing
+			the docker CLI.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay image list ENVIRONMENT' lists the images in the repository.
+			- 'metaplay deploy server ENVIRONMENT TAG' deploys the image into the environment.
+		`),
+		Example: renderExample(`
+			# Show the metadata of the image with tag '364cff09' in environment 'nimbly'.
+			metaplay image inspect nimbly 364cff09
+
+			# Output the metadata as JSON.
+			metaplay image inspect nimbly 364cff09 --format=json
+		`),
+	}
+	imageCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *imageInspectOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.argImageTag == "" || strings.Contains(o.argImageTag, ":") {
+		return clierrors.NewUsageErrorf("Invalid TAG '%s'", o.argImageTag).
+			WithDetails("Tag must be a valid docker tag (cannot be empty or contain ':')").
+			WithSuggestion("Use just the tag, for example 'metaplay image inspect lovely-wombats-build-nimbly 364cff09'")
+	}
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *imageInspectOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Get environment details.
+	envDetails, err := targetEnv.GetDetails()
+	if err != nil {
+		return err
+	}
+
+	// Get docker credentials.
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
+	if err != nil {
+		return err
+	}
+
+	// Check that the image exists to give a clear error.
+	remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, o.argImageTag)
+	_, exists, err := envapi.FetchRemoteDockerImageDigests(dockerCredentials, remoteImageName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return clierrors.Newf("Image '%s' not found in the environment's container registry", o.argImageTag).
+			WithSuggestion(fmt.Sprintf("List the available images with 'metaplay image list %s'", o.argEnvironment))
+	}
+
+	// Fetch the image metadata and platforms.
+	imageInfo, err := envapi.FetchRemoteDockerImageMetadata(dockerCredentials, remoteImageName)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to read the Metaplay metadata of image '%s'", o.argImageTag).
+			WithSuggestion("Check that the image was built with 'metaplay build image'")
+	}
+	platforms, err := envapi.FetchRemoteDockerImagePlatforms(dockerCredentials, remoteImageName)
+	if err != nil {
+		return err
+	}
+
+	result := imageInspectResult{
+		Image:       remoteImageName,
+		Tag:         o.argImageTag,
+		ProjectID:   imageInfo.ProjectID,
+		SdkVersion:  imageInfo.SdkVersion,
+		CommitID:    imageInfo.CommitID,
+		BuildNumber: imageInfo.BuildNumber,
+		CreatedAt:   imageInfo.CreatedTime,
+		IsDirty:     imageInfo.IsDirty,
+		Platforms:   platforms,
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal image metadata as JSON")
+		}
+		log.Info().Msg(string(resultJSON))
+		return nil
+	}
+
+	dirtyStr := "no"
+	if result.IsDirty {
+		dirtyStr = styles.RenderWarning("yes (built from uncommitted changes)")
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Docker Image"))
+	log.Info().Msg("")
+	log.Info().Msgf("Image:         %s", styles.RenderTechnical(result.Image))
+	log.Info().Msgf("Project ID:    %s", styles.RenderTechnical(result.ProjectID))
+	log.Info().Msgf("Metaplay SDK:  %s", styles.RenderTechnical(result.SdkVersion))
+	log.Info().Msgf("Commit ID:     %s", styles.RenderTechnical(result.CommitID))
+	log.Info().Msgf("Build number:  %s", styles.RenderTechnical(result.BuildNumber))
+	log.Info().Msgf("Created:       %s %s", styles.RenderTechnical(result.CreatedAt.Format(time.RFC3339)), styles.RenderMuted(fmt.Sprintf("(%s)", humanize.Time(result.CreatedAt))))
+	log.Info().Msgf("Platforms:     %s", styles.RenderTechnical(strings.Join(result.Platforms, ", ")))
+	log.Info().Msgf("Uncommitted:   %s", dirtyStr)
+	log.Info().Msg("")
+	log.Info().Msg("Deploy the image using:")
+	log.Info().Msgf(styles.RenderTechnical("  metaplay deploy server %s %s"), o.argEnvironment, o.argImageTag)
+	log.Info().Msg("")
+	return nil
+}
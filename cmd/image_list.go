@@ -21,8 +21,9 @@ import (
 // imageListEntry combines ECR image info with optional metadata from image labels.
 type imageListEntry struct {
 	envapi.ECRImage
-	SdkVersion string `json:"sdkVersion,omitempty"`
-	CommitID   string `json:"commitId,omitempty"`
+	SdkVersion  string `json:"sdkVersion,omitempty"`
+	CommitID    string `json:"commitId,omitempty"`
+	BuildNumber string `json:"buildNumber,omitempty"`
 }
 
 type imageListOpts struct {
@@ -49,8 +50,10 @@ func init() {
 			{Arguments}
 
 			Related commands:
+			- Show the metadata of an image using 'metaplay image inspect ...'.
 			- Pull an image to the local machine using 'metaplay image pull ...'.
 			- Push a built image to the repository using 'metaplay image push ...'.
+			- Delete old images from the repository using 'metaplay image prune ...'.
 		`),
 		Example: renderExample(`
 			# List the 20 most recent images in environment 'lovely-wombats-build-nimbly'.
@@ -103,13 +106,13 @@ func (o *imageListOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Get docker credentials for metadata fetching.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return err
 	}
 
 	// List images from ECR.
-	images, err := targetEnv.ListECRImages(envDetails, o.flagLimit)
+	images, err := targetEnv.ListECRImages(o.flagLimit)
 	if err != nil {
 		return err
 	}
@@ -158,7 +161,7 @@ func (o *imageListOpts) Run(cmd *cobra.Command) error {
 			}
 
 			// Print header
-			log.Info().Msgf("  %-*s  %-*s  %-12s  %-16s  %s", tagW, "TAG", sdkW, "SDK", "COMMIT", "PUSHED", "SIZE")
+			log.Info().Msgf("  %-*s  %-*s  %-12s  %-8s  %-16s  %s", tagW, "TAG", sdkW, "SDK", "COMMIT", "BUILD", "PUSHED", "SIZE")
 			log.Info().Msg("")
 
 			for _, e := range entries {
@@ -171,10 +174,11 @@ func (o *imageListOpts) Run(cmd *cobra.Command) error {
 				}
 
 				// Pad plain text before applying ANSI styles.
-				log.Info().Msgf("  %s  %s  %-12s  %s  %s",
+				log.Info().Msgf("  %s  %s  %-12s  %-8s  %s  %s",
 					styles.RenderTechnical(fmt.Sprintf("%-*s", tagW, tag)),
 					fmt.Sprintf("%-*s", sdkW, e.SdkVersion),
 					commit,
+					e.BuildNumber,
 					styles.RenderMuted(fmt.Sprintf("%-16s", pushed)),
 					size,
 				)
@@ -193,7 +197,8 @@ func (o *imageListOpts) Run(cmd *cobra.Command) error {
 	return nil
 }
 
-// fetchImageMetadata enriches ECR images with SDK version and commit ID from Docker image labels.
+// fetchImageMetadata enriches ECR images with SDK version, commit ID, and build number from Docker
+// image labels.
 // Metadata is fetched concurrently with up to 10 requests in flight.
 func fetchImageMetadata(images []envapi.ECRImage, ecrRepo string, creds *envapi.DockerCredentials) []imageListEntry {
 	return syncutil.ParallelMap(images, 10, func(img envapi.ECRImage) imageListEntry {
@@ -209,6 +214,7 @@ func fetchImageMetadata(images []envapi.ECRImage, ecrRepo string, creds *envapi.
 		}
 		entry.SdkVersion = info.SdkVersion
 		entry.CommitID = info.CommitID
+		entry.BuildNumber = info.BuildNumber
 		return entry
 	})
 }
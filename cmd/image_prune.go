@@ -0,0 +1,265 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Delete old images from the environment's image repository.
+type imagePruneOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagKeep       int
+	flagOlderThan  string
+	flagDryRun     bool
+	flagYes        bool
+
+	olderThan time.Duration
+}
+
+func init() {
+	o := imagePruneOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+
+	cmd := &cobra.Command{
+		Use:   "prune ENVIRONMENT [flags]",
+		Short: "Delete old Docker images from the target environment's image repository",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Delete old Docker images from the target environment's image repository (ECR).
+
+			The most recently pushed images (--keep) are always kept. Of the rest, the images pushed
+			longer ago than --older-than are deleted. The images currently deployed into the
+			environment (game server and bot clients) are never deleted.
+
+			The images to delete are listed and must be confirmed before deleting. Use --dry-run to
+			only list the images, or --yes to skip the confirmation, eg, in CI.
+
+			{Arguments}
+
+			Related commands:
+			- List the images in the repository using 'metaplay image list ...'.
+		`),
+		Example: renderExample(`
+			# Delete the images older than 30 days, keeping the 20 most recent ones.
+			metaplay image prune nimbly
+
+			# Keep only the 10 most recent images, regardless of their age.
+			metaplay image prune nimbly --keep=10 --older-than=0
+
+			# List the images that would be deleted without deleting anything.
+			metaplay image prune nimbly --older-than=14d --dry-run
+		`),
+	}
+	imageCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.IntVar(&o.flagKeep, "keep", 20, "Number of most recently pushed images to always keep")
+	flags.StringVar(&o.flagOlderThan, "older-than", "30d", "Only delete images pushed longer ago than this, eg, '30d' or '72h' (0 for any age)")
+	flags.BoolVar(&o.flagYes, "yes", false, "Delete the images without confirmation")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *imagePruneOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagKeep < 0 {
+		return clierrors.NewUsageErrorf("Invalid --keep %d", o.flagKeep).
+			WithSuggestion("Use a non-negative number")
+	}
+
+	olderThan, err := parseRetentionAge(o.flagOlderThan)
+	if err != nil {
+		return clierrors.NewUsageErrorf("Invalid --older-than %q", o.flagOlderThan).
+			WithSuggestion("Use a number of days like '30d', or a duration like '72h'")
+	}
+	o.olderThan = olderThan
+	return nil
+}
+
+func (o *imagePruneOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationDestroy); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Prune Docker Images"))
+	log.Info().Msg("")
+	log.Info().Msgf("Target environment: %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("Keep most recent:   %s", styles.RenderTechnical(strconv.Itoa(o.flagKeep)))
+	log.Info().Msgf("Delete older than:  %s", styles.RenderTechnical(o.flagOlderThan))
+	log.Info().Msg("")
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Resolve the deployed image tags, which must not be deleted.
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+	actionConfig, err := helmutil.NewActionConfig(kubeCli.KubeConfig, envConfig.GetKubernetesNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to initialize Helm config: %v", err)
+	}
+	deployedTags := []string{}
+	for _, chartName := range []string{metaplayGameServerChartName, metaplayLoadTestChartName} {
+		releases, err := helmutil.HelmListReleases(actionConfig, chartName)
+		if err != nil {
+			return err
+		}
+		for _, rel := range releases {
+			if tag := getReleaseImageTag(rel); tag != "" {
+				deployedTags = append(deployedTags, tag)
+			}
+		}
+	}
+	log.Debug().Msgf("Deployed image tags: %v", deployedTags)
+
+	// List all the images in the repository.
+	images, err := targetEnv.ListECRImages(0)
+	if err != nil {
+		return err
+	}
+
+	toDelete := selectImagesToPrune(images, o.flagKeep, o.olderThan, deployedTags, time.Now())
+	if len(toDelete) == 0 {
+		log.Info().Msgf("%s No images to delete (%d image(s) in the repository)", styles.RenderSuccess("✓"), len(images))
+		return nil
+	}
+
+	// Show the images to delete.
+	var totalSize int64
+	log.Info().Msgf("Images to delete:")
+	for _, image := range toDelete {
+		totalSize += image.SizeBytes
+		log.Info().Msgf("  %s %s  %s  %s",
+			styles.RenderError("-"),
+			styles.RenderTechnical(strings.Join(image.Tags, ", ")),
+			styles.RenderMuted(image.PushedAt.Format("2006-01-02 15:04")),
+			formatImageSize(image.SizeBytes))
+	}
+	log.Info().Msg("")
+	log.Info().Msgf("%d of %d image(s) will be deleted, freeing %s", len(toDelete), len(images), formatImageSize(totalSize))
+	log.Info().Msg("")
+
+	if o.flagDryRun {
+		dryRun := dryRunPlan{}
+		dryRun.Addf("Delete %d image(s) from the repository of environment %s", len(toDelete), styles.RenderTechnical(envConfig.HumanID))
+		dryRun.Print()
+		return nil
+	}
+
+	// Ask for confirmation.
+	if !o.flagYes {
+		if !tui.IsInteractiveMode() {
+			return clierrors.New("Confirmation required to delete the images").
+				WithSuggestion("Use --yes to delete the images in non-interactive mode, or --dry-run to only list them")
+		}
+		confirmed, err := tui.DoConfirmQuestion(ctx, fmt.Sprintf("Delete %d image(s)?", len(toDelete)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg(styles.RenderMuted("Prune canceled."))
+			return nil
+		}
+	}
+
+	// Delete the images.
+	digests := make([]string, len(toDelete))
+	for ndx, image := range toDelete {
+		digests[ndx] = image.Digest
+	}
+	if err := targetEnv.DeleteECRImages(digests); err != nil {
+		return clierrors.Wrap(err, "Failed to delete the images")
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("✅ %s", styles.RenderSuccess(fmt.Sprintf("Deleted %d image(s)", len(toDelete))))
+	return nil
+}
+
+// selectImagesToPrune returns the images to delete, newest first: the images that are not among
+// the keep most recently pushed ones, were pushed longer than olderThan ago (if non-zero), and
+// have none of the protected tags.
+func selectImagesToPrune(images []envapi.ECRImage, keep int, olderThan time.Duration, protectedTags []string, now time.Time) []envapi.ECRImage {
+	sorted := slices.Clone(images)
+	slices.SortStableFunc(sorted, func(a, b envapi.ECRImage) int {
+		return b.PushedAt.Compare(a.PushedAt)
+	})
+
+	result := []envapi.ECRImage{}
+	for ndx, image := range sorted {
+		if ndx < keep {
+			continue
+		}
+		if olderThan > 0 && now.Sub(image.PushedAt) < olderThan {
+			continue
+		}
+		if slices.ContainsFunc(image.Tags, func(tag string) bool { return slices.Contains(protectedTags, tag) }) {
+			continue
+		}
+		result = append(result, image)
+	}
+	return result
+}
+
+// parseRetentionAge parses an age given as a number of days, eg, '30d', or as a Go duration,
+// eg, '72h'. A plain '0' means no age limit.
+func parseRetentionAge(value string) (time.Duration, error) {
+	if value == "0" {
+		return 0, nil
+	}
+	if days, found := strings.CutSuffix(value, "d"); found {
+		numDays, err := strconv.Atoi(days)
+		if err != nil || numDays < 0 {
+			return 0, fmt.Errorf("invalid number of days in '%s'", value)
+		}
+		return time.Duration(numDays) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("negative duration '%s'", value)
+	}
+	return duration, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectImagesToPrune(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * 24 * time.Hour) }
+	images := []envapi.ECRImage{
+		{Tags: []string{"old-deployed"}, PushedAt: daysAgo(90)},
+		{Tags: []string{"newest"}, PushedAt: daysAgo(1)},
+		{Tags: []string{"old"}, PushedAt: daysAgo(60)},
+		{Tags: []string{"recent"}, PushedAt: daysAgo(10)},
+		{Tags: []string{"middle"}, PushedAt: daysAgo(40)},
+	}
+	tagsOf := func(images []envapi.ECRImage) []string {
+		tags := []string{}
+		for _, image := range images {
+			tags = append(tags, image.Tags[0])
+		}
+		return tags
+	}
+
+	// Keep the 2 most recent, delete the ones older than 30 days, except the deployed one.
+	toDelete := selectImagesToPrune(images, 2, 30*24*time.Hour, []string{"old-deployed"}, now)
+	assert.Equal(t, []string{"middle", "old"}, tagsOf(toDelete))
+
+	// Keep only the most recent one, regardless of age.
+	toDelete = selectImagesToPrune(images, 1, 0, nil, now)
+	assert.Equal(t, []string{"recent", "middle", "old", "old-deployed"}, tagsOf(toDelete))
+
+	// Keeping more images than exist deletes nothing.
+	assert.Empty(t, selectImagesToPrune(images, 10, 0, nil, now))
+}
+
+func TestParseRetentionAge(t *testing.T) {
+	age, err := parseRetentionAge("30d")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, age)
+
+	age, err = parseRetentionAge("72h")
+	require.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, age)
+
+	age, err = parseRetentionAge("0")
+	require.NoError(t, err)
+	assert.Zero(t, age)
+
+	for _, invalid := range []string{"", "abc", "-1d", "xd", "-5h"} {
+		_, err := parseRetentionAge(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -101,7 +101,7 @@ func (o *imagePullOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Get docker credentials.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return err
 	}
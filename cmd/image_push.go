@@ -18,6 +18,7 @@ import (
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
 	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -29,6 +30,9 @@ type imagePushOpts struct {
 
 	argEnvironment string
 	argImageName   string
+	flagDryRun     bool
+	flagSign       bool
+	flagSignKey    string
 }
 
 func init() {
@@ -36,15 +40,29 @@ func init() {
 
 	args := o.Arguments()
 	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment ID, eg, 'lovely-wombats-build-nimbly'.")
-	args.AddStringArgument(&o.argImageName, "IMAGE:TAG", "Docker image name and tag, eg, 'mygame:364cff09'.")
+	args.AddStringArgument(&o.argImageName, "[IMAGE:]TAG", "Docker image name and tag, eg, 'mygame:364cff09', or only the tag if the project specifies 'imageNaming'.")
 
 	cmd := &cobra.Command{
-		Use:   "push ENVIRONMENT IMAGE:TAG",
+		Use:   "push ENVIRONMENT [IMAGE:]TAG",
 		Short: "Push a built server Docker image to the target environment's docker image repository",
 		Run:   runCommand(&o),
 		Long: renderLong(&o, `
 			Push a built game server docker image to the target environment's image repository.
 
+			If the project specifies an 'imageNaming' template in metaplay-project.yaml, the image
+			can also be given as only the tag: the name of the server image is then resolved from
+			the template.
+
+			With --sign, the pushed image is signed with cosign (https://docs.sigstore.dev/), which
+			must be installed. The signature is bound to the image's digest and stored in the same
+			repository. The image is signed with the key given with --sign-key or 'imageSigning.key'
+			in metaplay-project.yaml (a file path or a KMS URI), or keyless using your OIDC identity
+			if no key is specified (eg, the workflow identity in GitHub Actions). 'metaplay deploy
+			server' verifies the signature when 'requireSignedImages' is enabled in the project.
+
+			With --dry-run, the remote repository is checked and the push that would be performed
+			is shown, but nothing is tagged or pushed.
+
 			{Arguments}
 
 			Related commands:
@@ -54,19 +72,32 @@ func init() {
 		Example: renderExample(`
 			# Push the docker image 'mygame:1a27c25753' into environment 'nimbly'.
 			metaplay image push nimbly mygame:1a27c25753
+
+			# Push the server image with tag '1a27c25753' named using the project's 'imageNaming'.
+			metaplay image push nimbly 1a27c25753
+
+			# Push the image and sign it keyless with cosign.
+			metaplay image push nimbly mygame:1a27c25753 --sign
+
+			# Push the image and sign it with a key in AWS KMS.
+			metaplay image push nimbly mygame:1a27c25753 --sign --sign-key=awskms:///alias/image-signing
+
+			# Check what would be pushed without pushing anything.
+			metaplay image push nimbly mygame:1a27c25753 --dry-run
 		`),
 	}
 	imageCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagSign, "sign", false, "Sign the pushed image with cosign")
+	flags.StringVar(&o.flagSignKey, "sign-key", "", "Cosign key to sign the image with, eg, 'cosign.key' or 'awskms:///alias/my-key' (default to 'imageSigning.key' in metaplay-project.yaml, or keyless)")
+	addDryRunFlag(flags, &o.flagDryRun)
 }
 
 func (o *imagePushOpts) Prepare(cmd *cobra.Command, args []string) error {
-	// Validate docker image name: must be a repository:tag pair.
-	if !strings.Contains(o.argImageName, ":") {
-		return clierrors.NewUsageErrorf("Invalid image name '%s'", o.argImageName).
-			WithDetails("Image name must include a tag (e.g., 'mygame:abc123')").
-			WithSuggestion("Use format NAME:TAG, for example 'metaplay image push develop mygame:abc123'")
+	if o.flagSignKey != "" && !o.flagSign {
+		return clierrors.NewUsageError("--sign-key can only be used with --sign")
 	}
-
 	return nil
 }
 
@@ -77,18 +108,47 @@ func (o *imagePushOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Resolve the full image name.
+	imageName, err := resolveLocalServerImageName(project, o.argImageName)
+	if err != nil {
+		return err
+	}
+	o.argImageName = imageName
+
 	// Resolve environment.
 	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
 	if err != nil {
 		return err
 	}
 
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// Resolve the signing key and check that cosign is available before pushing.
+	signKey := ""
+	if o.flagSign {
+		signKey = resolveImageSigningKey(project, o.flagSignKey)
+		if err := checkCosignInstalled(cmd.Context()); err != nil {
+			return err
+		}
+	}
+
 	// Log attempt
 	log.Info().Msg("")
 	log.Info().Msg(styles.RenderTitle("Push Docker Image to Cloud"))
 	log.Info().Msg("")
 	log.Info().Msgf("Target environment: %s", styles.RenderTechnical(envConfig.HumanID))
 	log.Info().Msgf("Docker image name: %s", styles.RenderTechnical(o.argImageName))
+	if o.flagSign {
+		log.Info().Msgf("Sign with cosign: %s", styles.RenderTechnical(coalesceString(signKey, "keyless")))
+	}
 	log.Info().Msg("")
 
 	// Create TargetEnvironment.
@@ -101,12 +161,31 @@ func (o *imagePushOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Get docker credentials.
-	dockerCredentials, err := targetEnv.GetDockerCredentials(envDetails)
+	dockerCredentials, err := targetEnv.GetDockerCredentials()
 	if err != nil {
 		return err
 	}
 	log.Debug().Msgf("Got docker credentials: username=%s", dockerCredentials.Username)
 
+	// In dry-run mode, only check whether the image would be pushed.
+	if o.flagDryRun {
+		dstImageName, needsPush, err := checkDockerImagePushNeeded(o.argImageName, envDetails.Deployment.EcrRepo, dockerCredentials)
+		if err != nil {
+			return err
+		}
+		dryRun := dryRunPlan{}
+		if needsPush {
+			dryRun.Addf("Push image %s as %s", styles.RenderTechnical(o.argImageName), styles.RenderTechnical(dstImageName))
+		} else {
+			log.Info().Msgf("Image %s is already present in the repository (identical digest)", styles.RenderTechnical(dstImageName))
+		}
+		if o.flagSign {
+			dryRun.Addf("Sign image %s with cosign (%s)", styles.RenderTechnical(dstImageName), coalesceString(signKey, "keyless"))
+		}
+		dryRun.Print()
+		return nil
+	}
+
 	// Use task runner to push the image.
 	taskRunner := tui.NewTaskRunner()
 
@@ -118,6 +197,19 @@ func (o *imagePushOpts) Run(cmd *cobra.Command) error {
 		return err
 	})
 
+	// Sign the pushed image. Already present images are signed too, as they may be unsigned.
+	if o.flagSign {
+		taskRunner.AddTask("Sign docker image with cosign", func(output *tui.TaskOutput) error {
+			imageTag, err := extractDockerImageTag(o.argImageName)
+			if err != nil {
+				return err
+			}
+			remoteImageName := fmt.Sprintf("%s:%s", envDetails.Deployment.EcrRepo, imageTag)
+			_, err = signRemoteImage(cmd.Context(), output, dockerCredentials, remoteImageName, signKey)
+			return err
+		})
+	}
+
 	// Run the tasks.
 	if err = taskRunner.Run(); err != nil {
 		return err
@@ -129,9 +221,84 @@ func (o *imagePushOpts) Run(cmd *cobra.Command) error {
 	} else {
 		log.Info().Msg(styles.RenderSuccess("✅ Image already present in repository; nothing to push."))
 	}
+	if o.flagSign {
+		log.Info().Msg(styles.RenderSuccess("✅ Signed the image with cosign."))
+	}
 	return nil
 }
 
+// resolveImageSigningKey returns the cosign key to sign images with: the key given with --sign-key,
+// or the project's 'imageSigning.key'. Empty means keyless signing. The project may be nil.
+func resolveImageSigningKey(project *metaproj.MetaplayProject, flagSignKey string) string {
+	if flagSignKey != "" || project == nil || project.Config.ImageSigning == nil {
+		return flagSignKey
+	}
+	return project.Config.ImageSigning.Key
+}
+
+// resolveLocalServerImageName resolves the name of a local server image from the [IMAGE:]TAG
+// argument. A 'NAME:TAG' is used as-is. With only the tag, the name is resolved using the
+// project's 'imageNaming' template. The project may be nil.
+func resolveLocalServerImageName(project *metaproj.MetaplayProject, imageNameTag string) (string, error) {
+	// Full name specified, use as-is.
+	if strings.Contains(imageNameTag, ":") {
+		return imageNameTag, nil
+	}
+
+	// Only the tag specified: requires a naming template.
+	if project == nil || project.Config.ImageNaming == "" {
+		return "", clierrors.NewUsageErrorf("Invalid image name '%s'", imageNameTag).
+			WithDetails("Image name must include a tag (e.g., 'mygame:abc123'), unless the project specifies 'imageNaming'").
+			WithSuggestion("Use format NAME:TAG, for example 'metaplay image push develop mygame:abc123'")
+	}
+
+	return resolveProjectImageName(project, "server", imageNameTag)
+}
+
+// checkDockerImagePushNeeded resolves the name of the local image 'imageName' in the destination
+// repository and checks whether the image needs to be pushed there. An identical image already in
+// the repository doesn't need to be pushed. A different image with the same tag is an error.
+func checkDockerImagePushNeeded(imageName, dstRepoName string, dockerCredentials *envapi.DockerCredentials) (string, bool, error) {
+	// Extract tag from source image.
+	imageTag, err := extractDockerImageTag(imageName)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Resolve destination image name.
+	dstImageName := fmt.Sprintf("%s:%s", dstRepoName, imageTag)
+
+	// Check whether the tag already exists in the remote repository. Image tags must be unique
+	// per build: re-using a tag (e.g. 'latest', a bare commit SHA, or any tag that has already
+	// been pushed) means a deployed environment can't reliably resolve which artifact it's
+	// running. We therefore refuse to overwrite a tag that already holds a different image.
+	remoteDigests, exists, err := envapi.FetchRemoteDockerImageDigests(dockerCredentials, dstImageName)
+	if err != nil {
+		return "", false, err
+	}
+	if !exists {
+		return dstImageName, true, nil
+	}
+
+	// Resolve the local image's ID so we can tell apart "same image, re-pushed" (a harmless
+	// no-op we can skip) from "different image, same tag" (a hard error). The local image ID is
+	// the config digest under the legacy image store and the manifest digest under the
+	// containerd image store, so accept a match against either remote digest.
+	localImage, err := envapi.ReadLocalDockerImageMetadata(imageName)
+	if err != nil {
+		return "", false, err
+	}
+
+	if localImage.ImageID == remoteDigests.ConfigDigest || localImage.ImageID == remoteDigests.ManifestDigest {
+		return dstImageName, false, nil
+	}
+
+	// Same tag, different image content: refuse to overwrite.
+	return "", false, clierrors.Newf("Image tag '%s' already exists in the environment's repository with different content", imageTag).
+		WithDetails("Re-using an image tag is not supported: each build must be pushed with a unique tag.").
+		WithSuggestion("Rebuild with a unique tag and push that. A '<timestamp>-<commit>' tag (e.g. '20260601-153000-1a27c25') is recommended; 'metaplay build image' without a tag generates one automatically.")
+}
+
 // Extract the tag from a full 'name:tag' docker image name.
 func extractDockerImageTag(imageName string) (string, error) {
 	// Check if the image name is empty
@@ -161,44 +328,16 @@ func pushDockerImage(ctx context.Context, output *tui.TaskOutput, imageName, dst
 		return false, err
 	}
 
-	// Extract tag from source image.
-	imageTag, err := extractDockerImageTag(imageName)
-	if err != nil {
-		return false, err
-	}
-
-	// Resolve source and destination image names.
+	// Resolve the destination image and check whether it needs to be pushed.
 	srcImageName := imageName
-	dstImageName := fmt.Sprintf("%s:%s", dstRepoName, imageTag)
-
-	// Check whether the tag already exists in the remote repository. Image tags must be unique
-	// per build: re-using a tag (e.g. 'latest', a bare commit SHA, or any tag that has already
-	// been pushed) means a deployed environment can't reliably resolve which artifact it's
-	// running. We therefore refuse to overwrite a tag that already holds a different image.
-	remoteDigests, exists, err := envapi.FetchRemoteDockerImageDigests(dockerCredentials, dstImageName)
+	dstImageName, needsPush, err := checkDockerImagePushNeeded(imageName, dstRepoName, dockerCredentials)
 	if err != nil {
 		return false, err
 	}
-	if exists {
-		// Resolve the local image's ID so we can tell apart "same image, re-pushed" (a harmless
-		// no-op we can skip) from "different image, same tag" (a hard error). The local image ID is
-		// the config digest under the legacy image store and the manifest digest under the
-		// containerd image store, so accept a match against either remote digest.
-		localImage, err := envapi.ReadLocalDockerImageMetadata(srcImageName)
-		if err != nil {
-			return false, err
-		}
-
-		if localImage.ImageID == remoteDigests.ConfigDigest || localImage.ImageID == remoteDigests.ManifestDigest {
-			// Identical image already in the repository: nothing to do.
-			output.AppendLinef("Image %s is already present in the repository (identical digest), skipping push", dstImageName)
-			return false, nil
-		}
-
-		// Same tag, different image content: refuse to overwrite.
-		return false, clierrors.Newf("Image tag '%s' already exists in the environment's repository with different content", imageTag).
-			WithDetails("Re-using an image tag is not supported: each build must be pushed with a unique tag.").
-			WithSuggestion("Rebuild with a unique tag and push that. A '<timestamp>-<commit>' tag (e.g. '20260601-153000-1a27c25') is recommended; 'metaplay build image' without a tag generates one automatically.")
+	if !needsPush {
+		// Identical image already in the repository: nothing to do.
+		output.AppendLinef("Image %s is already present in the repository (identical digest), skipping push", dstImageName)
+		return false, nil
 	}
 
 	// If names don't match, tag the source image as the destination.
@@ -6,10 +6,12 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -28,6 +30,9 @@ type CIProvider string
 const (
 	CIProviderGitHubActions CIProvider = "github"
 	CIProviderBitbucket     CIProvider = "bitbucket"
+	CIProviderGitLab        CIProvider = "gitlab"
+	CIProviderAzureDevOps   CIProvider = "azure"
+	CIProviderJenkins       CIProvider = "jenkins"
 	CIProviderGeneric       CIProvider = "generic"
 )
 
@@ -41,20 +46,51 @@ type ciProviderInfo struct {
 var ciProviders = []ciProviderInfo{
 	{CIProviderGitHubActions, "GitHub Actions", "Deploy using Metaplay's reusable workflows"},
 	{CIProviderBitbucket, "Bitbucket Pipelines", "Deploy using Bitbucket's native CI/CD"},
+	{CIProviderGitLab, "GitLab CI", "Deploy using GitLab CI/CD pipelines"},
+	{CIProviderAzureDevOps, "Azure DevOps", "Deploy using Azure Pipelines"},
+	{CIProviderJenkins, "Jenkins", "Deploy using a Jenkins declarative pipeline"},
 	{CIProviderGeneric, "Generic CI", "Deploy using any other CI system using a generic script"},
 }
 
+// CIWorkflow represents a type of CI workflow to generate.
+type CIWorkflow string
+
+const (
+	CIWorkflowDeploy   CIWorkflow = "deploy"
+	CIWorkflowValidate CIWorkflow = "validate"
+	CIWorkflowNightly  CIWorkflow = "nightly"
+)
+
+// ciWorkflowInfo contains display information for a CI workflow type.
+type ciWorkflowInfo struct {
+	ID          CIWorkflow
+	Name        string
+	Description string
+}
+
+var ciWorkflows = []ciWorkflowInfo{
+	{CIWorkflowDeploy, "Deploy", "Build the game server and deploy it into the environments"},
+	{CIWorkflowValidate, "PR validation", "Build the game server and run the integration tests for pull requests"},
+	{CIWorkflowNightly, "Nightly", "Build the game server and run the integration tests every night"},
+}
+
 type initCIOpts struct {
-	flagCIProvider  string // CI provider to use (github, bitbucket, generic)
+	flagCIProvider  string // CI provider to use (github, bitbucket, gitlab, azure, jenkins, generic)
 	flagEnvironment string // Target environment human ID
+	flagWorkflows   string // Comma-separated list of workflows to generate (deploy, validate, nightly)
 	flagOnConflict  string // Conflict resolution: overwrite, rename, skip
 	flagAutoConfirm bool   // Automatically confirm file writes
 	flagOutputDir   string // Output directory for CI files (defaults to project root)
+	flagPlanJSON    bool   // Output the file plan as JSON without writing anything
+	flagPlanOnly    bool   // Show the file plan without writing anything
+
+	flagCreateMachineUser string // Name of the machine user to create for CI (empty to not create)
 
 	projectDir   string                              // Resolved project directory
 	project      *metaproj.MetaplayProject           // Loaded project
 	environments []metaproj.ProjectEnvironmentConfig // Resolved target environments (from flag)
 	ciProvider   CIProvider                          // Selected CI provider
+	workflows    []CIWorkflow                        // Selected workflows to generate
 }
 
 func init() {
@@ -70,15 +106,36 @@ func init() {
 			This command generates CI/CD configuration files for your chosen provider:
 			- GitHub Actions: Creates workflow files using Metaplay's reusable workflows
 			- Bitbucket Pipelines: Creates pipeline configuration for Bitbucket
+			- GitLab CI: Creates a .gitlab-ci.yml with a deploy job for each environment
+			- Azure DevOps: Creates an azure-pipelines.yml with a deploy stage for each environment
+			- Jenkins: Creates a parameterized Jenkinsfile with a deploy stage for each environment
 			- Generic CI: Creates shell scripts for use with any CI system
 
 			The generated files include all necessary steps to build and deploy your game server
 			to the selected environment(s).
 
+			Use --workflows to choose which workflows to generate (in interactive mode, you are asked):
+			- deploy: Build the game server and deploy it into the selected environment(s)
+			- validate: Build the game server and run the integration tests for pull requests
+			- nightly: Build the game server and run the integration tests on a nightly schedule
+			In non-interactive mode, only the deploy workflow is generated by default. The validate
+			and nightly workflows don't deploy, so no environment or machine user is needed for them.
+
+			Use --plan-only to only show which files would be written, or --plan-json to output the
+			planned files, actions and diffs as JSON (eg, for code review bots). Neither writes
+			any files. The --on-conflict policy is applied to the plan if specified. The --dry-run
+			flag is an alias for --plan-only.
+
+			The CI jobs authenticate with a machine user. Use --create-machine-user to create one
+			in the Metaplay portal after writing the files; in interactive mode, you are asked
+			whether to create one. The credentials are printed only once, for storing them in your
+			CI system's secrets.
+
 			Prerequisites:
 			- A Metaplay project with metaplay-project.yaml
 			- At least one environment configured in the project
-			- A machine user created in the Metaplay portal for CI authentication
+			- A machine user for CI authentication, created in the Metaplay portal or with
+			  --create-machine-user
 		`),
 		Example: renderExample(`
 			# Interactive setup - choose CI provider and environment
@@ -87,21 +144,53 @@ func init() {
 			# Initialize GitHub Actions for a specific environment
 			metaplay init ci --provider=github --environment=nimbly
 
+			# Initialize GitLab CI for multiple environments
+			metaplay init ci --provider=gitlab --environment=nimbly,prod --yes
+
+			# Initialize Azure DevOps pipelines for all environments
+			metaplay init ci --provider=azure --environment=all --yes
+
+			# Initialize a Jenkins pipeline for all environments
+			metaplay init ci --provider=jenkins --environment=all --yes
+
+			# Also generate the PR validation and nightly workflows
+			metaplay init ci --provider=github --environment=all --workflows=deploy,validate,nightly --yes
+
+			# Only generate the PR validation workflow
+			metaplay init ci --provider=gitlab --workflows=validate --yes
+
 			# Initialize for multiple environments, overwriting existing files
 			metaplay init ci --provider=github --environment=nimbly,prod --on-conflict=overwrite --yes
 
+			# Also create a machine user for the CI jobs and print its credentials
+			metaplay init ci --provider=github --environment=nimbly --create-machine-user=github-ci
+
 			# Re-generate files with .new suffix to compare against existing ones
 			metaplay init ci --provider=github --environment=all --on-conflict=rename --yes
+
+			# Output the changes that re-generating the files would make as JSON
+			metaplay init ci --provider=github --environment=all --plan-json
+
+			# Record the answers to the interactive questions into a file
+			metaplay init ci --record-answers answers.yaml
+
+			# Replay the recorded answers non-interactively, eg, in CI
+			metaplay init ci --answers answers.yaml
 		`),
 	}
 
 	// Register flags.
 	flags := cmd.Flags()
-	flags.StringVar(&o.flagCIProvider, "provider", "", "CI provider to use: github, bitbucket, or generic")
+	flags.StringVar(&o.flagCIProvider, "provider", "", "CI provider to use: github, bitbucket, gitlab, azure, jenkins, or generic")
 	flags.StringVarP(&o.flagEnvironment, "environment", "e", "", "Target environment(s): human ID, comma-separated list, or 'all'")
+	flags.StringVar(&o.flagWorkflows, "workflows", "", "Workflows to generate: comma-separated list of deploy, validate, and nightly")
 	flags.StringVar(&o.flagOnConflict, "on-conflict", "", "How to handle existing files: overwrite, rename, or skip")
 	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Automatically confirm file writes")
 	flags.StringVar(&o.flagOutputDir, "output-dir", "", "Output directory for CI files (defaults to project root)")
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "dry-run", false, "Alias for --plan-only")
+	flags.StringVar(&o.flagCreateMachineUser, "create-machine-user", "", "Create a machine user with the given name for CI and print its credentials")
 
 	initCmd.AddCommand(cmd)
 }
@@ -123,11 +212,20 @@ func (o *initCIOpts) Prepare(cmd *cobra.Command, args []string) error {
 	if o.flagCIProvider != "" {
 		if !isValidCIProvider(o.flagCIProvider) {
 			return clierrors.NewUsageErrorf("Invalid CI provider '%s'", o.flagCIProvider).
-				WithDetails("Valid options are: github, bitbucket, generic")
+				WithDetails("Valid options are: github, bitbucket, gitlab, azure, jenkins, generic")
 		}
 		o.ciProvider = CIProvider(o.flagCIProvider)
 	}
 
+	// Validate workflows if specified
+	if o.flagWorkflows != "" {
+		workflows, err := parseCIWorkflows(o.flagWorkflows)
+		if err != nil {
+			return err
+		}
+		o.workflows = workflows
+	}
+
 	// Validate --on-conflict if specified
 	if o.flagOnConflict != "" {
 		if !isValidConflictPolicy(o.flagOnConflict) {
@@ -169,15 +267,29 @@ func (o *initCIOpts) Prepare(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Must be either in interactive mode or specify --yes with required flags
-	if !tui.IsInteractiveMode() {
-		if !o.flagAutoConfirm {
+	// Nothing is created in the plan modes.
+	if o.flagCreateMachineUser != "" && (o.flagPlanJSON || o.flagPlanOnly) {
+		return clierrors.NewUsageError("--create-machine-user cannot be used with --plan-only or --plan-json")
+	}
+
+	// The environments are only needed for the deploy workflow, which is the default when the
+	// workflows can't be selected interactively.
+	needsEnvironment := o.flagWorkflows == "" || o.hasWorkflow(CIWorkflowDeploy)
+
+	// JSON output must not be mixed with interactive selections.
+	if o.flagPlanJSON && (o.flagCIProvider == "" || (o.flagEnvironment == "" && needsEnvironment)) {
+		return clierrors.NewUsageError("--provider and --environment are required with --plan-json")
+	}
+
+	// Must be either in interactive mode (or replaying answers) or specify --yes with required flags
+	if !tui.CanAskQuestions() {
+		if !o.flagAutoConfirm && !o.flagPlanJSON && !o.flagPlanOnly {
 			return clierrors.NewUsageError("Use --yes to automatically confirm changes when running in non-interactive mode")
 		}
 		if o.flagCIProvider == "" {
 			return clierrors.NewUsageError("--provider is required in non-interactive mode")
 		}
-		if o.flagEnvironment == "" {
+		if o.flagEnvironment == "" && needsEnvironment {
 			return clierrors.NewUsageError("--environment is required in non-interactive mode")
 		}
 	}
@@ -185,6 +297,40 @@ func (o *initCIOpts) Prepare(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// createMachineUser creates a machine user for the CI jobs and prints its credentials, if requested
+// with --create-machine-user or, in interactive mode, confirmed by the user. Returns whether the
+// machine user was created.
+func (o *initCIOpts) createMachineUser(ctx context.Context) (bool, error) {
+	name := o.flagCreateMachineUser
+	if name == "" {
+		if !tui.CanAskQuestions() {
+			return false, nil
+		}
+		log.Info().Msg("")
+		confirmed, err := tui.DoConfirmQuestion(ctx, "Create a machine user for the CI jobs in the Metaplay portal now?")
+		if err != nil {
+			return false, err
+		}
+		if !confirmed {
+			return false, nil
+		}
+		name = fmt.Sprintf("%s-ci", o.ciProvider)
+	}
+
+	portalClient, projectInfo, err := resolvePortalProject(ctx, o.project)
+	if err != nil {
+		return false, err
+	}
+	creds, err := portalClient.CreateMachineUser(projectInfo.UUID, name, defaultMachineUserRole)
+	if err != nil {
+		return false, err
+	}
+	if err := printMachineUserCredentials(creds, "text"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // conflictOption is used for the interactive conflict resolution dialog.
 type conflictOption struct {
 	Policy      filesetwriter.ConflictPolicy
@@ -202,15 +348,17 @@ func (o *initCIOpts) Run(cmd *cobra.Command) error {
 	ctx := cmd.Context()
 
 	// Show prerequisite information
-	log.Info().Msg("")
-	log.Info().Msg(styles.RenderTitle("Prerequisites"))
-	log.Info().Msg("")
-	log.Info().Msg("Before proceeding, ensure you have:")
-	log.Info().Msg("  a) Created a machine user in the Metaplay portal")
-	log.Info().Msgf("  b) Given it the %s role", styles.RenderTechnical("game-admin"))
-	log.Info().Msg("  c) Stored its credentials in your CI system")
-	log.Info().Msg("")
-	log.Info().Msgf("For instructions, see: %s", styles.RenderTechnical("https://docs.metaplay.io/cloud-deployments/setup-ci-pipeline"))
+	if !o.flagPlanJSON {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Prerequisites"))
+		log.Info().Msg("")
+		log.Info().Msg("Before proceeding, ensure you have:")
+		log.Info().Msgf("  a) Created a machine user in the Metaplay portal (or use %s)", styles.RenderTechnical("--create-machine-user"))
+		log.Info().Msgf("  b) Given it the %s role", styles.RenderTechnical("game-admin"))
+		log.Info().Msg("  c) Stored its credentials in your CI system")
+		log.Info().Msg("")
+		log.Info().Msgf("For instructions, see: %s", styles.RenderTechnical("https://docs.metaplay.io/cloud-deployments/setup-ci-pipeline"))
+	}
 
 	// Select CI provider if not specified
 	if o.ciProvider == "" {
@@ -228,13 +376,41 @@ func (o *initCIOpts) Run(cmd *cobra.Command) error {
 		log.Info().Msgf(" %s %s", styles.RenderSuccess("✓"), provider.Name)
 	}
 
-	// Select environments to configure
+	// Select workflows to generate if not specified
+	if len(o.workflows) == 0 {
+		if tui.CanAskQuestions() && !o.flagPlanJSON {
+			selected, err := tui.ChooseMultipleFromListDialog(
+				"Select Workflows",
+				ciWorkflows,
+				func(w *ciWorkflowInfo) (string, string) {
+					return w.Name, w.Description
+				},
+			)
+			if err != nil {
+				return err
+			}
+			if len(selected) == 0 {
+				return clierrors.NewUsageError("No workflows selected")
+			}
+			for _, workflow := range selected {
+				o.workflows = append(o.workflows, workflow.ID)
+				log.Info().Msgf(" %s %s", styles.RenderSuccess("✓"), workflow.Name)
+			}
+		} else {
+			o.workflows = []CIWorkflow{CIWorkflowDeploy}
+		}
+	}
+
+	// Select environments to configure (only needed for the deploy workflow)
 	var environments []metaproj.ProjectEnvironmentConfig
-	if o.flagEnvironment == "all" {
+	switch {
+	case !o.hasWorkflow(CIWorkflowDeploy):
+		// The validate and nightly workflows don't target any environment.
+	case o.flagEnvironment == "all":
 		environments = o.project.Config.Environments
-	} else if len(o.environments) > 0 {
+	case len(o.environments) > 0:
 		environments = o.environments
-	} else {
+	default:
 		// Interactive multi-select
 		selected, err := tui.ChooseMultipleFromListDialog(
 			"Select Target Environments",
@@ -267,6 +443,17 @@ func (o *initCIOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// With --plan-json or --plan-only, show the plan (with --on-conflict applied) and stop.
+	if o.flagPlanJSON || o.flagPlanOnly {
+		if o.flagOnConflict != "" && plan.HasConflicts() {
+			plan.SetConflictPolicy(parseConflictPolicy(o.flagOnConflict), ".new")
+			if err := plan.Scan(); err != nil {
+				return err
+			}
+		}
+		return showPlanOnly(plan, o.flagPlanJSON, false)
+	}
+
 	// If all files are unchanged, nothing to do.
 	if plan.FilesToWrite() == 0 {
 		log.Info().Msg("")
@@ -360,6 +547,12 @@ func (o *initCIOpts) Run(cmd *cobra.Command) error {
 
 	log.Info().Msg("")
 	log.Info().Msg(styles.RenderSuccess("CI configuration initialized successfully!"))
+
+	// Create the machine user for the CI jobs, if requested.
+	createdMachineUser, err := o.createMachineUser(ctx)
+	if err != nil {
+		return err
+	}
 	log.Info().Msg("")
 
 	// Build provider-specific next steps
@@ -372,9 +565,24 @@ func (o *initCIOpts) Run(cmd *cobra.Command) error {
 		steps = append(steps, "Configure the workflow triggers in the generated .yaml files.")
 	case CIProviderBitbucket:
 		steps = append(steps, "Configure the pipeline triggers in bitbucket-pipelines.yml.")
+	case CIProviderGitLab:
+		steps = append(steps, "Configure the job rules in .gitlab-ci.yml, and store the credentials as a masked CI/CD variable METAPLAY_CREDENTIALS.")
+	case CIProviderAzureDevOps:
+		steps = append(steps, "Configure the pipeline triggers in azure-pipelines.yml, and store the credentials as a secret pipeline variable METAPLAY_CREDENTIALS.")
+	case CIProviderJenkins:
+		steps = append(steps, "Create a Pipeline job for the Jenkinsfile, and store the credentials as a 'Secret text' credential with the ID 'metaplay-credentials'.")
 	case CIProviderGeneric:
 		steps = append(steps, "Integrate the generated deploy scripts into your CI system.")
 	}
+	if o.hasWorkflow(CIWorkflowNightly) {
+		switch o.ciProvider {
+		case CIProviderBitbucket, CIProviderGitLab, CIProviderGeneric:
+			steps = append(steps, "Create a schedule for running the nightly pipeline in your CI system.")
+		}
+	}
+	if createdMachineUser {
+		steps = append(steps, "Store the machine user credentials printed above in your CI system as METAPLAY_CREDENTIALS.")
+	}
 	steps = append(steps, "Commit the changed files into your version control.")
 
 	printNumberedSteps(steps)
@@ -437,16 +645,43 @@ func (o *initCIOpts) collectCIFiles(plan *filesetwriter.Plan, outputDir string,
 		}
 	}
 
-	if o.ciProvider == CIProviderBitbucket {
-		return o.collectBitbucketFile(plan, outputDir, environments)
+	// The environments are only used by the deploy workflow.
+	if !o.hasWorkflow(CIWorkflowDeploy) {
+		environments = nil
+	} else if len(environments) == 0 {
+		return clierrors.NewUsageError("No environments selected").
+			WithSuggestion("Select at least one environment, or use --environment=all")
 	}
 
-	for _, env := range environments {
-		if err := o.collectCIFile(plan, outputDir, env); err != nil {
-			return err
+	// Providers with a single pipeline file for all the workflows and environments.
+	switch o.ciProvider {
+	case CIProviderBitbucket:
+		return o.collectPipelineFile(plan, filepath.Join(outputDir, "bitbucket-pipelines.yml"), bitbucketPipelinesTmpl, environments)
+	case CIProviderGitLab:
+		return o.collectPipelineFile(plan, filepath.Join(outputDir, ".gitlab-ci.yml"), gitlabCITmpl, environments)
+	}
+
+	// Deploy files for the other providers.
+	if len(environments) > 0 {
+		switch o.ciProvider {
+		case CIProviderAzureDevOps:
+			if err := o.collectPipelineFile(plan, filepath.Join(outputDir, "azure-pipelines.yml"), azurePipelinesTmpl, environments); err != nil {
+				return err
+			}
+		case CIProviderJenkins:
+			if err := o.collectPipelineFile(plan, filepath.Join(outputDir, "Jenkinsfile"), jenkinsfileTmpl, environments); err != nil {
+				return err
+			}
+		default:
+			for _, env := range environments {
+				if err := o.collectCIFile(plan, outputDir, env); err != nil {
+					return err
+				}
+			}
 		}
 	}
-	return nil
+
+	return o.collectValidateFiles(plan, outputDir)
 }
 
 // collectCIFile renders a single GitHub Actions or Generic CI file and adds it to the plan.
@@ -484,26 +719,105 @@ func (o *initCIOpts) collectCIFile(plan *filesetwriter.Plan, outputDir string, e
 	return nil
 }
 
-// collectBitbucketFile renders a single Bitbucket Pipelines file and adds it to the plan.
-func (o *initCIOpts) collectBitbucketFile(plan *filesetwriter.Plan, outputDir string, environments []metaproj.ProjectEnvironmentConfig) error {
-	var envData []bitbucketEnvironmentData
+// collectPipelineFile renders a single pipeline file with jobs for all the environments (eg,
+// Bitbucket Pipelines or GitLab CI) and adds it to the plan. The Bitbucket Pipelines and GitLab CI
+// files also include the jobs for the validate and nightly workflows, if selected.
+func (o *initCIOpts) collectPipelineFile(plan *filesetwriter.Plan, filePath string, tmpl *template.Template, environments []metaproj.ProjectEnvironmentConfig) error {
+	var envData []ciPipelineEnvironmentData
 	for _, env := range environments {
-		envData = append(envData, bitbucketEnvironmentData{
+		envData = append(envData, ciPipelineEnvironmentData{
 			DisplayName: env.Name,
 			HumanID:     env.HumanID,
+			Identifier:  strings.ReplaceAll(env.HumanID, "-", "_"),
 		})
 	}
 
-	content, err := renderTemplate(bitbucketPipelinesTmpl, bitbucketTemplateData{Environments: envData})
+	data := ciPipelineTemplateData{
+		Environments: envData,
+		Validate:     o.hasWorkflow(CIWorkflowValidate),
+		Nightly:      o.hasWorkflow(CIWorkflowNightly),
+	}
+	content, err := renderTemplate(tmpl, data)
 	if err != nil {
-		return clierrors.Wrap(err, "Failed to render Bitbucket Pipelines template")
+		return clierrors.Wrapf(err, "Failed to render %s template", filepath.Base(filePath))
 	}
 
-	filePath := filepath.Join(outputDir, "bitbucket-pipelines.yml")
 	plan.Add(filePath, []byte(content), 0644)
 	return nil
 }
 
+// collectValidateFiles renders the files for the validate and nightly workflows of the providers
+// that use a separate file for each workflow, and adds them to the plan.
+func (o *initCIOpts) collectValidateFiles(plan *filesetwriter.Plan, outputDir string) error {
+	for _, workflow := range []CIWorkflow{CIWorkflowValidate, CIWorkflowNightly} {
+		if !o.hasWorkflow(workflow) {
+			continue
+		}
+
+		var filePath string
+		var tmpl *template.Template
+		perm := os.FileMode(0644)
+		switch o.ciProvider {
+		case CIProviderGitHubActions:
+			filePath = filepath.Join(outputDir, ".github", "workflows", fmt.Sprintf("%s-server.yaml", workflow))
+			tmpl = githubActionsValidateTmpl
+		case CIProviderAzureDevOps:
+			filePath = filepath.Join(outputDir, fmt.Sprintf("azure-pipelines-%s.yml", workflow))
+			tmpl = azurePipelinesValidateTmpl
+		case CIProviderJenkins:
+			filePath = filepath.Join(outputDir, fmt.Sprintf("Jenkinsfile.%s", workflow))
+			tmpl = jenkinsfileValidateTmpl
+		case CIProviderGeneric:
+			// The same script is used for both validating and the nightly schedule.
+			if workflow == CIWorkflowNightly && o.hasWorkflow(CIWorkflowValidate) {
+				continue
+			}
+			filePath = filepath.Join(outputDir, "validate-server.sh")
+			tmpl = genericCIValidateTmpl
+			perm = 0755
+		default:
+			return clierrors.Newf("Unknown CI provider: %s", o.ciProvider)
+		}
+
+		content, err := renderTemplate(tmpl, ciValidateTemplateData{Nightly: workflow == CIWorkflowNightly})
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to render %s template", filepath.Base(filePath))
+		}
+		plan.Add(filePath, []byte(content), perm)
+	}
+	return nil
+}
+
+// hasWorkflow returns true if the workflow is selected to be generated.
+func (o *initCIOpts) hasWorkflow(workflow CIWorkflow) bool {
+	return slices.Contains(o.workflows, workflow)
+}
+
+// parseCIWorkflows parses a comma-separated list of workflows, eg, 'deploy,validate'.
+func parseCIWorkflows(value string) ([]CIWorkflow, error) {
+	var workflows []CIWorkflow
+	for part := range strings.SplitSeq(value, ",") {
+		workflow := CIWorkflow(strings.TrimSpace(part))
+		if workflow == "" {
+			continue
+		}
+		switch workflow {
+		case CIWorkflowDeploy, CIWorkflowValidate, CIWorkflowNightly:
+			if !slices.Contains(workflows, workflow) {
+				workflows = append(workflows, workflow)
+			}
+		default:
+			return nil, clierrors.NewUsageErrorf("Invalid workflow '%s'", workflow).
+				WithDetails("Valid options are: deploy, validate, nightly")
+		}
+	}
+	if len(workflows) == 0 {
+		return nil, clierrors.NewUsageError("No workflows specified with --workflows").
+			WithDetails("Valid options are: deploy, validate, nightly")
+	}
+	return workflows, nil
+}
+
 func isValidConflictPolicy(value string) bool {
 	switch value {
 	case "overwrite", "rename", "skip":
@@ -526,7 +840,7 @@ func parseConflictPolicy(value string) filesetwriter.ConflictPolicy {
 
 func isValidCIProvider(provider string) bool {
 	switch CIProvider(provider) {
-	case CIProviderGitHubActions, CIProviderBitbucket, CIProviderGeneric:
+	case CIProviderGitHubActions, CIProviderBitbucket, CIProviderGitLab, CIProviderAzureDevOps, CIProviderJenkins, CIProviderGeneric:
 		return true
 	default:
 		return false
@@ -539,23 +853,42 @@ type ciTemplateData struct {
 	EnvironmentHumanID     string
 }
 
-// bitbucketEnvironmentData contains data for a single environment in the Bitbucket template.
-type bitbucketEnvironmentData struct {
+// ciPipelineEnvironmentData contains data for a single environment in the single-file pipeline
+// templates (Bitbucket Pipelines, GitLab CI, Azure DevOps, and Jenkins).
+type ciPipelineEnvironmentData struct {
 	DisplayName string
 	HumanID     string
+	Identifier  string // Human ID with dashes replaced by underscores, eg, for Azure stage names
 }
 
-// bitbucketTemplateData contains the data passed to the Bitbucket Pipelines template.
-type bitbucketTemplateData struct {
-	Environments []bitbucketEnvironmentData
+// ciPipelineTemplateData contains the data passed to the single-file pipeline templates.
+type ciPipelineTemplateData struct {
+	Environments []ciPipelineEnvironmentData
+	Validate     bool // Include the pull request validation jobs (Bitbucket Pipelines and GitLab CI)
+	Nightly      bool // Include the nightly jobs (Bitbucket Pipelines and GitLab CI)
+}
+
+// ciValidateTemplateData contains the data passed to the validate and nightly workflow templates.
+type ciValidateTemplateData struct {
+	Nightly bool // Nightly scheduled workflow, otherwise a pull request validation workflow
 }
 
 // Parsed CI templates (parsed once at package init).
-// The GitHub Actions template uses [[.Field]] delimiters to avoid conflicts with GitHub's ${{ }} syntax.
+// The GitHub Actions and Azure DevOps templates use [[.Field]] delimiters to avoid conflicts with
+// their ${{ }} expression syntax. The Jenkins validate template uses them for consistency with the
+// other validate templates.
 var (
 	githubActionsTmpl      = template.Must(template.New("github").Delims("[[", "]]").Parse(githubActionsTemplate))
 	bitbucketPipelinesTmpl = template.Must(template.New("bitbucket").Parse(bitbucketPipelinesTemplate))
+	gitlabCITmpl           = template.Must(template.New("gitlab").Parse(gitlabCITemplate))
+	azurePipelinesTmpl     = template.Must(template.New("azure").Delims("[[", "]]").Parse(azurePipelinesTemplate))
+	jenkinsfileTmpl        = template.Must(template.New("jenkins").Parse(jenkinsfileTemplate))
 	genericCITmpl          = template.Must(template.New("generic").Parse(genericCITemplate))
+
+	githubActionsValidateTmpl  = template.Must(template.New("github-validate").Delims("[[", "]]").Parse(githubActionsValidateTemplate))
+	azurePipelinesValidateTmpl = template.Must(template.New("azure-validate").Delims("[[", "]]").Parse(azurePipelinesValidateTemplate))
+	jenkinsfileValidateTmpl    = template.Must(template.New("jenkins-validate").Delims("[[", "]]").Parse(jenkinsfileValidateTemplate))
+	genericCIValidateTmpl      = template.Must(template.New("generic-validate").Parse(genericCIValidateTemplate))
 )
 
 func renderTemplate(tmpl *template.Template, data any) (string, error) {
@@ -618,11 +951,45 @@ definitions:
     docker-6gb:
       type: docker
       memory: 6144
+{{- if or .Validate .Nightly}}
+
+  steps:
+    # Build the game server and run the integration tests, without deploying
+    - step: &build-and-test-server
+        runtime:
+          cloud:
+            version: 3
+        size: 2x
+        name: 'Build server and run integration tests'
+        services:
+          - docker-6gb
+        script:
+          # Exit on failures
+          - set -eo pipefail
+          # Install metaplay CLI & ensure it's in path
+          - export PATH="$HOME/.local/bin:$PATH"
+          - bash <(curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh)
+          # Build the game server docker image
+          - metaplay build image gameserver:$BITBUCKET_COMMIT
+          # Run the integration tests
+          - metaplay test integration
+{{- end}}
 
 pipelines:
+{{- if .Validate}}
+  # Validate all pull requests by building the game server and running the integration tests
+  pull-requests:
+    '**':
+      - step: *build-and-test-server
+{{end}}{{if or .Nightly .Environments}}
   # TODO: You should customize this to fit your branching strategy, now needs to be triggered manually
   #       See: https://support.atlassian.com/bitbucket-cloud/docs/bitbucket-pipelines-configuration-reference/
-  custom:{{range .Environments}}
+  custom:{{if .Nightly}}
+    # Build the game server and run the integration tests, run this from a nightly schedule
+    # See: https://support.atlassian.com/bitbucket-cloud/docs/pipeline-triggers/
+    nightly-build-and-test-server:
+      - step: *build-and-test-server
+{{end}}{{range .Environments}}
     # Build and deploy the game server into the '{{.HumanID}}' environment
     build-deploy-server-{{.HumanID}}:
       - step:
@@ -648,8 +1015,221 @@ pipelines:
             - metaplay build image gameserver:$IMAGE_TAG
             # Deploy the game server
             - metaplay deploy server {{.HumanID}} gameserver:$IMAGE_TAG
+{{end}}{{end}}`
+
+// GitLab CI template
+const gitlabCITemplate = `# GitLab CI pipeline for building the game server and deploying it into the cloud.
+# See: https://docs.gitlab.com/ci/yaml/
+
+stages:{{if or .Validate .Nightly}}
+  - test{{end}}{{if .Environments}}
+  - deploy{{end}}
+{{- if or .Validate .Nightly}}
+
+# Build the game server and run the integration tests, without deploying
+build-and-test-server:
+  stage: test
+  image: docker:28
+  services:
+    # Docker-in-Docker is used for building the game server image and running the tests
+    - docker:28-dind
+  variables:
+    DOCKER_HOST: tcp://docker:2376
+    DOCKER_TLS_CERTDIR: "/certs"
+    GIT_DEPTH: 5
+  script:
+    # Install metaplay CLI & ensure it's in path
+    - apk add --no-cache bash curl git git-lfs
+    - export PATH="$HOME/.local/bin:$PATH"
+    - curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh | bash
+    # Build the game server docker image
+    - metaplay build image gameserver:$CI_COMMIT_SHA
+    # Run the integration tests
+    - metaplay test integration
+  rules:{{if .Validate}}
+    # Validate all merge requests
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event"{{end}}{{if .Nightly}}
+    # Run from the nightly pipeline schedule
+    # See: https://docs.gitlab.com/ci/pipelines/schedules/
+    - if: $CI_PIPELINE_SOURCE == "schedule"{{end}}
+{{- end}}
+{{- if .Environments}}
+
+# Common configuration for the deploy jobs below
+.deploy-server:
+  stage: deploy
+  image: docker:28
+  services:
+    # Docker-in-Docker is used for building the game server image
+    - docker:28-dind
+  variables:
+    DOCKER_HOST: tcp://docker:2376
+    DOCKER_TLS_CERTDIR: "/certs"
+    GIT_DEPTH: 5
+  before_script:
+    # Install metaplay CLI & ensure it's in path
+    - apk add --no-cache bash curl git git-lfs
+    - export PATH="$HOME/.local/bin:$PATH"
+    - curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh | bash
+    # Login to Metaplay cloud (using machine user with credentials from the METAPLAY_CREDENTIALS CI/CD variable)
+    - metaplay auth machine-login
+  script:
+    # Generate unique image tag
+    - export IMAGE_TAG=$(date -u +%Y%m%d-%H%M%S)-$CI_COMMIT_SHA
+    # Build the game server docker image
+    - metaplay build image gameserver:$IMAGE_TAG
+    # Deploy the game server
+    - metaplay deploy server $METAPLAY_ENVIRONMENT gameserver:$IMAGE_TAG
+
+# TODO: You should customize the rules to fit your branching strategy, now the jobs need to be triggered manually
+#       See: https://docs.gitlab.com/ci/jobs/job_rules/
+{{range .Environments}}
+# Build and deploy the game server into the '{{.HumanID}}' environment
+deploy-server-{{.HumanID}}:
+  extends: .deploy-server
+  environment:
+    name: {{.HumanID}}
+  variables:
+    METAPLAY_ENVIRONMENT: {{.HumanID}}
+  rules:
+    - when: manual
+{{end}}{{else}}
 {{end}}`
 
+// Azure DevOps template
+const azurePipelinesTemplate = `# Azure DevOps pipeline for building the game server and deploying it into the cloud.
+# See: https://learn.microsoft.com/azure/devops/pipelines/yaml-schema/
+
+# TODO: You should customize this to fit your branching strategy, now the pipeline needs to be run manually
+#       See: https://learn.microsoft.com/azure/devops/pipelines/build/triggers
+trigger: none
+pr: none
+
+parameters:
+  - name: environment
+    displayName: Target environment
+    type: string
+    default: [[(index .Environments 0).HumanID]]
+    values:[[range .Environments]]
+      - [[.HumanID]][[end]]
+
+pool:
+  vmImage: ubuntu-latest
+
+stages:[[range .Environments]]
+  # Build and deploy the game server into the '[[.HumanID]]' environment
+  - stage: deploy_server_[[.Identifier]]
+    displayName: 'Build server and deploy to [[.DisplayName]] ([[.HumanID]])'
+    condition: eq('${{ parameters.environment }}', '[[.HumanID]]')
+    dependsOn: []
+    jobs:
+      - job: build_and_deploy
+        steps:
+          - checkout: self
+            lfs: true
+            fetchDepth: 5
+          - script: |
+              set -eo pipefail
+              # Install metaplay CLI & ensure it's in path
+              bash <(curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh)
+              export PATH="$HOME/.local/bin:$PATH"
+              # Login to Metaplay cloud (using machine user with credentials from the METAPLAY_CREDENTIALS secret variable)
+              metaplay auth machine-login
+              # Generate unique image tag
+              export IMAGE_TAG=$(date -u +%Y%m%d-%H%M%S)-$BUILD_SOURCEVERSION
+              # Build the game server docker image
+              metaplay build image gameserver:$IMAGE_TAG
+              # Deploy the game server
+              metaplay deploy server [[.HumanID]] gameserver:$IMAGE_TAG
+            displayName: Build and deploy server
+            env:
+              # Secret variables must be mapped explicitly into the environment
+              METAPLAY_CREDENTIALS: $(METAPLAY_CREDENTIALS)
+[[end]]`
+
+// Jenkins declarative pipeline template
+const jenkinsfileTemplate = `// Jenkins pipeline for building the game server and deploying it into the cloud.
+// See: https://www.jenkins.io/doc/book/pipeline/syntax/
+//
+// Requirements:
+// - Docker Pipeline plugin, and agents with Docker available
+// - Credentials Binding plugin, with the Metaplay machine user credentials stored as a
+//   'Secret text' credential with the ID 'metaplay-credentials'
+
+// TODO: You should customize this to fit your branching strategy, now the pipeline needs to be run manually
+pipeline {
+  agent {
+    docker {
+      image 'docker:28'
+      // Use the host's Docker daemon for building the game server image
+      args '-u root -v /var/run/docker.sock:/var/run/docker.sock'
+    }
+  }
+
+  parameters {
+    choice(name: 'ENVIRONMENT', choices: [{{range $i, $env := .Environments}}{{if $i}}, {{end}}'{{$env.HumanID}}'{{end}}], description: 'Environment to deploy the game server into')
+  }
+
+  options {
+    disableConcurrentBuilds()
+  }
+
+  environment {
+    // Machine user credentials for logging into Metaplay cloud
+    METAPLAY_CREDENTIALS = credentials('metaplay-credentials')
+    // Unique image tag for the build
+    IMAGE_TAG = "${env.BUILD_NUMBER}-${env.GIT_COMMIT}"
+  }
+
+  stages {
+    stage('Setup') {
+      steps {
+        // Install metaplay CLI & login to Metaplay cloud
+        sh '''
+          apk add --no-cache bash curl git git-lfs
+          curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh | bash
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay auth machine-login
+        '''
+      }
+    }
+
+    stage('Build image') {
+      steps {
+        // Build the game server docker image
+        sh '''
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay build image gameserver:$IMAGE_TAG
+        '''
+      }
+    }
+
+    stage('Push image') {
+      steps {
+        // Push the image into the target environment's image repository
+        sh '''
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay image push $ENVIRONMENT gameserver:$IMAGE_TAG
+        '''
+      }
+    }
+{{range .Environments}}
+    // Deploy the game server into the '{{.HumanID}}' environment
+    stage('Deploy to {{.DisplayName}} ({{.HumanID}})') {
+      when {
+        expression { params.ENVIRONMENT == '{{.HumanID}}' }
+      }
+      steps {
+        sh '''
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay deploy server {{.HumanID}} gameserver:$IMAGE_TAG
+        '''
+      }
+    }
+{{end}}  }
+}
+`
+
 // Generic CI template
 const genericCITemplate = `#!/bin/bash
 # CI script for deploying to {{.EnvironmentDisplayName}} ({{.EnvironmentHumanID}})
@@ -686,3 +1266,172 @@ metaplay build image gameserver:$IMAGE_TAG --commit-id=$COMMIT_ID --build-number
 echo "Deploying game server to {{.EnvironmentHumanID}}..."
 metaplay deploy server {{.EnvironmentHumanID}} gameserver:$IMAGE_TAG
 `
+
+// GitHub Actions template for the validation workflows (PR validation and nightly)
+const githubActionsValidateTemplate = `[[if .Nightly]]name: Nightly game server build and tests
+
+# Configure when this Github Action is triggered
+on:
+  # Enable manual triggering
+  workflow_dispatch:
+
+  # Run every night (times are in UTC)
+  schedule:
+    - cron: '0 2 * * *'
+[[else]]name: Validate game server
+
+# Configure when this Github Action is triggered
+on:
+  # Validate all pull requests
+  pull_request:
+
+  # Enable manual triggering
+  workflow_dispatch:
+[[end]]
+jobs:
+  # Build the server and run the integration tests, without deploying
+  build-and-test-server:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Checkout repo
+        uses: actions/checkout@v6
+
+      - name: Install Metaplay CLI
+        run: |
+          bash <(curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh)
+          echo "$HOME/.local/bin" >> $GITHUB_PATH
+
+      - name: Build server image
+        run: metaplay build image gameserver:${{ github.sha }}
+
+      - name: Run integration tests
+        run: metaplay test integration
+`
+
+// Azure DevOps template for the validation pipelines (PR validation and nightly)
+const azurePipelinesValidateTemplate = `[[if .Nightly]]# Azure DevOps pipeline for building the game server and running the integration tests every night.
+# See: https://learn.microsoft.com/azure/devops/pipelines/process/scheduled-triggers
+
+trigger: none
+pr: none
+
+# Run every night (times are in UTC)
+schedules:
+  - cron: '0 2 * * *'
+    displayName: Nightly build
+    branches:
+      include:
+        - main
+    always: true
+[[else]]# Azure DevOps pipeline for validating pull requests by building the game server and running the
+# integration tests. Configure it as the build validation policy of your branches.
+# See: https://learn.microsoft.com/azure/devops/repos/git/branch-policies#build-validation
+
+trigger: none
+[[end]]
+pool:
+  vmImage: ubuntu-latest
+
+steps:
+  - checkout: self
+    lfs: true
+    fetchDepth: 5
+  - script: |
+      set -eo pipefail
+      # Install metaplay CLI & ensure it's in path
+      bash <(curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh)
+      export PATH="$HOME/.local/bin:$PATH"
+      # Build the game server docker image
+      metaplay build image gameserver:$BUILD_SOURCEVERSION
+      # Run the integration tests
+      metaplay test integration
+    displayName: Build server and run integration tests
+`
+
+// Jenkins declarative pipeline template for the validation pipelines (PR validation and nightly)
+const jenkinsfileValidateTemplate = `[[if .Nightly]]// Jenkins pipeline for building the game server and running the integration tests every night.
+[[else]]// Jenkins pipeline for validating pull requests by building the game server and running the
+// integration tests. Use it with a Multibranch Pipeline job to build the pull requests.
+[[end]]// See: https://www.jenkins.io/doc/book/pipeline/syntax/
+//
+// Requirements:
+// - Docker Pipeline plugin, and agents with Docker available
+pipeline {
+  agent {
+    docker {
+      image 'docker:28'
+      // Use the host's Docker daemon for building the images and running the tests
+      args '-u root -v /var/run/docker.sock:/var/run/docker.sock'
+    }
+  }
+[[if .Nightly]]
+  // Run every night
+  triggers {
+    cron('H 2 * * *')
+  }
+[[end]]
+  options {
+    disableConcurrentBuilds()
+  }
+
+  stages {
+    stage('Setup') {
+      steps {
+        // Install metaplay CLI
+        sh '''
+          apk add --no-cache bash curl git git-lfs
+          curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh | bash
+        '''
+      }
+    }
+
+    stage('Build image') {
+      steps {
+        // Build the game server docker image
+        sh '''
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay build image gameserver:$BUILD_NUMBER
+        '''
+      }
+    }
+
+    stage('Integration tests') {
+      steps {
+        sh '''
+          export PATH="$HOME/.local/bin:$PATH"
+          metaplay test integration
+        '''
+      }
+    }
+  }
+}
+`
+
+// Generic CI template for the validation script (PR validation and nightly)
+const genericCIValidateTemplate = `#!/bin/bash
+# CI script for building the game server and running the integration tests, without deploying
+#
+# Run this script for validating pull requests and from your CI system's nightly schedule.
+# No Metaplay credentials are needed, but Docker must be available.
+
+set -eo pipefail
+
+# Configure build identity
+export COMMIT_ID="${COMMIT_ID:-$(git rev-parse HEAD)}"
+export BUILD_NUMBER="${BUILD_NUMBER:-local}"
+
+# Generate unique image tag
+export IMAGE_TAG="$(date -u +%Y%m%d-%H%M%S)-$COMMIT_ID"
+
+# Always install latest metaplay CLI
+echo "Installing Metaplay CLI..."
+bash <(curl -sSfL --retry 10 --retry-all-errors --retry-max-time 60 https://metaplay.github.io/cli/install.sh)
+
+# Build game server docker image
+echo "Building game server image..."
+metaplay build image gameserver:$IMAGE_TAG --commit-id=$COMMIT_ID --build-number=$BUILD_NUMBER
+
+# Run the integration tests
+echo "Running integration tests..."
+metaplay test integration
+`
@@ -0,0 +1,157 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testCIPipelineTemplateData() ciPipelineTemplateData {
+	return ciPipelineTemplateData{
+		Environments: []ciPipelineEnvironmentData{
+			{DisplayName: "Nimbly", HumanID: "lovely-wombats-build-nimbly", Identifier: "lovely_wombats_build_nimbly"},
+			{DisplayName: "Production", HumanID: "lovely-wombats-build", Identifier: "lovely_wombats_build"},
+		},
+	}
+}
+
+func TestGitLabCITemplate(t *testing.T) {
+	content, err := renderTemplate(gitlabCITmpl, testCIPipelineTemplateData())
+	require.NoError(t, err)
+
+	var pipeline map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(content), &pipeline))
+
+	for _, humanID := range []string{"lovely-wombats-build-nimbly", "lovely-wombats-build"} {
+		job, ok := pipeline["deploy-server-"+humanID].(map[string]any)
+		require.True(t, ok, "missing deploy job for %s", humanID)
+		assert.Equal(t, ".deploy-server", job["extends"])
+		assert.Equal(t, humanID, job["variables"].(map[string]any)["METAPLAY_ENVIRONMENT"])
+	}
+}
+
+func TestAzurePipelinesTemplate(t *testing.T) {
+	content, err := renderTemplate(azurePipelinesTmpl, testCIPipelineTemplateData())
+	require.NoError(t, err)
+
+	var pipeline struct {
+		Parameters []struct {
+			Name    string   `yaml:"name"`
+			Default string   `yaml:"default"`
+			Values  []string `yaml:"values"`
+		} `yaml:"parameters"`
+		Stages []struct {
+			Stage     string `yaml:"stage"`
+			Condition string `yaml:"condition"`
+		} `yaml:"stages"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(content), &pipeline))
+
+	require.Len(t, pipeline.Parameters, 1)
+	assert.Equal(t, "lovely-wombats-build-nimbly", pipeline.Parameters[0].Default)
+	assert.Equal(t, []string{"lovely-wombats-build-nimbly", "lovely-wombats-build"}, pipeline.Parameters[0].Values)
+
+	require.Len(t, pipeline.Stages, 2)
+	assert.Equal(t, "deploy_server_lovely_wombats_build_nimbly", pipeline.Stages[0].Stage)
+	assert.Equal(t, "eq('${{ parameters.environment }}', 'lovely-wombats-build-nimbly')", pipeline.Stages[0].Condition)
+	assert.Equal(t, "deploy_server_lovely_wombats_build", pipeline.Stages[1].Stage)
+	assert.Contains(t, content, "metaplay deploy server lovely-wombats-build gameserver:$IMAGE_TAG")
+}
+
+func TestJenkinsfileTemplate(t *testing.T) {
+	content, err := renderTemplate(jenkinsfileTmpl, testCIPipelineTemplateData())
+	require.NoError(t, err)
+
+	assert.Contains(t, content, "choices: ['lovely-wombats-build-nimbly', 'lovely-wombats-build']")
+	assert.Contains(t, content, "METAPLAY_CREDENTIALS = credentials('metaplay-credentials')")
+	assert.Contains(t, content, "stage('Deploy to Nimbly (lovely-wombats-build-nimbly)')")
+	assert.Contains(t, content, "expression { params.ENVIRONMENT == 'lovely-wombats-build' }")
+	assert.Contains(t, content, "metaplay deploy server lovely-wombats-build gameserver:$IMAGE_TAG")
+	assert.Equal(t, strings.Count(content, "{"), strings.Count(content, "}"), "unbalanced braces in Jenkinsfile")
+}
+
+func TestParseCIWorkflows(t *testing.T) {
+	workflows, err := parseCIWorkflows("deploy, validate,nightly,deploy")
+	require.NoError(t, err)
+	assert.Equal(t, []CIWorkflow{CIWorkflowDeploy, CIWorkflowValidate, CIWorkflowNightly}, workflows)
+
+	_, err = parseCIWorkflows("deploy,lint")
+	assert.Error(t, err)
+
+	_, err = parseCIWorkflows(",")
+	assert.Error(t, err)
+}
+
+func TestPipelineTemplatesWithValidateWorkflows(t *testing.T) {
+	testCases := []struct {
+		name string
+		data ciPipelineTemplateData
+	}{
+		{"deploy-validate-nightly", ciPipelineTemplateData{Environments: testCIPipelineTemplateData().Environments, Validate: true, Nightly: true}},
+		{"validate", ciPipelineTemplateData{Validate: true}},
+		{"nightly", ciPipelineTemplateData{Nightly: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bitbucket, err := renderTemplate(bitbucketPipelinesTmpl, tc.data)
+			require.NoError(t, err)
+			var bitbucketPipeline map[string]any
+			require.NoError(t, yaml.Unmarshal([]byte(bitbucket), &bitbucketPipeline))
+			pipelines := bitbucketPipeline["pipelines"].(map[string]any)
+			assert.Equal(t, tc.data.Validate, pipelines["pull-requests"] != nil)
+			assert.Equal(t, tc.data.Nightly || len(tc.data.Environments) > 0, pipelines["custom"] != nil)
+
+			gitlab, err := renderTemplate(gitlabCITmpl, tc.data)
+			require.NoError(t, err)
+			var gitlabPipeline map[string]any
+			require.NoError(t, yaml.Unmarshal([]byte(gitlab), &gitlabPipeline))
+			job := gitlabPipeline["build-and-test-server"].(map[string]any)
+			assert.Equal(t, "test", job["stage"])
+			assert.Len(t, job["rules"], btoi(tc.data.Validate)+btoi(tc.data.Nightly))
+			assert.Equal(t, len(tc.data.Environments) > 0, gitlabPipeline[".deploy-server"] != nil)
+		})
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	for _, nightly := range []bool{false, true} {
+		data := ciValidateTemplateData{Nightly: nightly}
+
+		github, err := renderTemplate(githubActionsValidateTmpl, data)
+		require.NoError(t, err)
+		var workflow map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(github), &workflow))
+		triggers := workflow["on"].(map[string]any)
+		_, hasSchedule := triggers["schedule"]
+		_, hasPullRequest := triggers["pull_request"]
+		assert.Equal(t, nightly, hasSchedule)
+		assert.Equal(t, !nightly, hasPullRequest)
+		assert.Contains(t, github, "metaplay test integration")
+
+		azure, err := renderTemplate(azurePipelinesValidateTmpl, data)
+		require.NoError(t, err)
+		var pipeline map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(azure), &pipeline))
+		assert.Equal(t, nightly, pipeline["schedules"] != nil)
+
+		jenkins, err := renderTemplate(jenkinsfileValidateTmpl, data)
+		require.NoError(t, err)
+		assert.Equal(t, nightly, strings.Contains(jenkins, "cron('H 2 * * *')"))
+		assert.NotContains(t, jenkins, "metaplay deploy")
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -24,6 +24,8 @@ import (
 )
 
 type initDashboardOpts struct {
+	flagPlanJSON bool // Output the file plan as JSON without writing anything.
+	flagPlanOnly bool // Show the file plan without writing anything.
 }
 
 func init() {
@@ -45,6 +47,10 @@ func init() {
 			3. Update metaplay-project.yaml to refer to your custom dashboard.
 			4. Generate the pnpm-lock.yaml file using 'pnpm install'.
 
+			Use --plan-only to only show which files would be written, or --plan-json to output the
+			planned files, actions and diffs as JSON (eg, for code review bots). Neither writes
+			any files nor runs 'pnpm install'.
+
 			Related commands:
 			- 'metaplay build dashboard' to build the dashboard locally.
 			- 'metaplay dev dashboard' to serve the dashboard locally.
@@ -52,11 +58,16 @@ func init() {
 		Example: renderExample(`
 			# Initialize the custom LiveOps Dashboard in the project.
 			metaplay init dashboard
+
+			# Show the files that would be written, without writing anything.
+			metaplay init dashboard --plan-only
 		`),
 	}
 
 	// Register flags.
-	// flags := cmd.Flags()
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
 
 	initCmd.AddCommand(cmd)
 }
@@ -100,9 +111,11 @@ func renderPnpmWorkspaceContent(entries []string, allowBuildsPackages []string)
 }
 
 func (o *initDashboardOpts) Run(cmd *cobra.Command) error {
-	log.Info().Msg("")
-	log.Info().Msg(styles.RenderTitle("Initialize Custom LiveOps Dashboard in Your Project"))
-	log.Info().Msg("")
+	if !o.flagPlanJSON {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Initialize Custom LiveOps Dashboard in Your Project"))
+		log.Info().Msg("")
+	}
 
 	// Load project config.
 	project, err := resolveProject()
@@ -117,9 +130,12 @@ func (o *initDashboardOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Check that required dashboard tools are installed and satisfy version requirements.
+	// The tools are not needed when only showing the plan.
 	ctx := cmd.Context()
-	if err := checkDashboardToolVersions(ctx, project); err != nil {
-		return err
+	if !o.flagPlanJSON && !o.flagPlanOnly {
+		if err := checkDashboardToolVersions(ctx, project); err != nil {
+			return err
+		}
 	}
 
 	// Resolve project dashboard dir (only Backend/Dashboard supported for now)
@@ -173,6 +189,11 @@ func (o *initDashboardOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// With --plan-json or --plan-only, show the plan and stop.
+	if o.flagPlanJSON || o.flagPlanOnly {
+		return showPlanOnly(plan, o.flagPlanJSON, true)
+	}
+
 	log.Info().Msg("Files to be modified:")
 	plan.Preview(true)
 
@@ -0,0 +1,365 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-yaml/parser"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Version of Playwright used by the scaffolded tests. The npm package and the Docker image must
+// be of the same version for the browsers in the image to be usable.
+const dashboardTestsPlaywrightVersion = "1.48.2"
+
+// Name of the scaffolded test in 'integrationTests.tests' and its build stage in the Dockerfile.
+const (
+	dashboardTestsName   = "custom-dashboard"
+	dashboardTestsTarget = "dashboard-tests"
+)
+
+type initDashboardTestsOpts struct {
+	flagPlanJSON bool // Output the file plan as JSON without writing anything.
+	flagPlanOnly bool // Show the file plan without writing anything.
+}
+
+// dashboardTestsTemplateData is the data used to render the scaffolded files.
+type dashboardTestsTemplateData struct {
+	ProjectHumanID    string // Human ID of the project, eg, 'lovely-wombats-build'
+	TestsDir          string // Path to the tests project, relative to the project root (with forward slashes)
+	PlaywrightVersion string // Version of Playwright to use
+	TestTarget        string // Name of the build stage in the Dockerfile
+}
+
+func init() {
+	o := initDashboardTestsOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "dashboard-tests [flags]",
+		Short: "Initialize Playwright tests for the custom LiveOps Dashboard",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Scaffold a Playwright test project for the project's custom LiveOps Dashboard, and
+			register it to be run by 'metaplay test integration'.
+
+			The project must use a custom dashboard, see 'metaplay init dashboard'.
+
+			This command does the following:
+			1. Populate a Playwright test project into Backend/DashboardTests, with an example test.
+			2. Add a Dockerfile with a 'dashboard-tests' build stage for running the tests in a container.
+			3. Register the tests as the custom integration test 'custom-dashboard' in metaplay-project.yaml.
+
+			The tests are run against the dashboard at DASHBOARD_BASE_URL, which 'metaplay test integration'
+			sets to the dashboard of the game server being tested (also with --environment). When run
+			locally with 'npm test', the tests default to the locally running game server's dashboard at
+			http://localhost:5550. The test results are written into OUTPUT_DIRECTORY (or test-results/).
+
+			Use --plan-only to only show which files would be written, or --plan-json to output the
+			planned files, actions and diffs as JSON (eg, for code review bots). Neither writes any files.
+
+			Related commands:
+			- 'metaplay init dashboard' to initialize the custom dashboard.
+			- 'metaplay test integration --test=custom-dashboard' to run the dashboard tests.
+		`),
+		Example: renderExample(`
+			# Initialize the Playwright tests for the custom LiveOps Dashboard.
+			metaplay init dashboard-tests
+
+			# Show the files that would be written, without writing anything.
+			metaplay init dashboard-tests --plan-only
+
+			# Run the dashboard tests in containers.
+			metaplay test integration --test=custom-dashboard
+		`),
+	}
+
+	// Register flags.
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
+
+	initCmd.AddCommand(cmd)
+}
+
+func (o *initDashboardTestsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *initDashboardTestsOpts) Run(cmd *cobra.Command) error {
+	if !o.flagPlanJSON {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Initialize LiveOps Dashboard Tests in Your Project"))
+		log.Info().Msg("")
+	}
+
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	// The tests are only useful for custom dashboards.
+	if !project.UsesCustomDashboard() {
+		return clierrors.New("The project does not use a custom LiveOps Dashboard").
+			WithSuggestion("Initialize the custom dashboard first with 'metaplay init dashboard'")
+	}
+
+	// Check if the tests have already been initialized.
+	if hasCustomIntegrationTest(project, dashboardTestsName) {
+		log.Info().Msg(styles.RenderSuccess("Dashboard tests are already initialized in this project. Nothing to do."))
+		return nil
+	}
+
+	// Build a plan with all files to write.
+	testsDirRelative := filepath.ToSlash(filepath.Join(project.Config.BackendDir, "DashboardTests"))
+	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
+	templateData := dashboardTestsTemplateData{
+		ProjectHumanID:    project.Config.ProjectHumanID,
+		TestsDir:          testsDirRelative,
+		PlaywrightVersion: dashboardTestsPlaywrightVersion,
+		TestTarget:        dashboardTestsTarget,
+	}
+	if err := collectDashboardTestsFiles(plan, filepath.Join(project.RelativeDir, testsDirRelative), templateData); err != nil {
+		return err
+	}
+
+	// Compute updated metaplay-project.yaml content.
+	configPath, configContent, err := computeProjectConfigDashboardTestsUpdate(project, testsDirRelative)
+	if err != nil {
+		return fmt.Errorf("failed to compute metaplay-project.yaml update: %w", err)
+	}
+	plan.AddUpdate(configPath, configContent, 0644, "register dashboard integration test")
+
+	// Scan the filesystem and show file preview.
+	if err := plan.Scan(); err != nil {
+		return err
+	}
+
+	// With --plan-json or --plan-only, show the plan and stop.
+	if o.flagPlanJSON || o.flagPlanOnly {
+		return showPlanOnly(plan, o.flagPlanJSON, false)
+	}
+
+	log.Info().Msg("Files to be modified:")
+	plan.Preview(false)
+
+	// Wait for any read-only files to become writable before writing.
+	if err := plan.WaitForWritable(cmd.Context(), false); err != nil {
+		return err
+	}
+
+	// Confirm before writing.
+	log.Info().Msg("")
+	if tui.IsInteractiveMode() {
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Aborted.")
+			return nil
+		}
+	}
+
+	// Write all files at once.
+	if err := plan.Execute(); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess("✅ LiveOps Dashboard tests setup successful!"))
+	log.Info().Msg("")
+	log.Info().Msg("The following changes were made to your project:")
+	log.Info().Msgf("- Scaffolded Playwright test project in %s", styles.RenderTechnical(testsDirRelative+"/"))
+	log.Info().Msgf("- Registered the %s integration test in %s", styles.RenderTechnical(dashboardTestsName), styles.RenderTechnical("metaplay-project.yaml"))
+	log.Info().Msg("")
+	log.Info().Msgf("Run the tests in containers with: %s", styles.RenderPrompt("metaplay test integration --test="+dashboardTestsName))
+	log.Info().Msgf("Or against your local server with: %s", styles.RenderPrompt(fmt.Sprintf("cd %s && npm install && npx playwright install chromium && npm test", testsDirRelative)))
+
+	return nil
+}
+
+// hasCustomIntegrationTest returns true if the project has a custom integration test with the given name.
+func hasCustomIntegrationTest(project *metaproj.MetaplayProject, name string) bool {
+	if project.Config.IntegrationTests == nil {
+		return false
+	}
+	return slices.ContainsFunc(project.Config.IntegrationTests.Tests, func(test metaproj.CustomIntegrationTestConfig) bool {
+		return test.Name == name
+	})
+}
+
+// collectDashboardTestsFiles renders the files of the Playwright test project into the plan.
+func collectDashboardTestsFiles(plan *filesetwriter.Plan, testsDir string, data dashboardTestsTemplateData) error {
+	files := []struct {
+		path     string
+		template string
+	}{
+		{"package.json", dashboardTestsPackageJSONTemplate},
+		{"playwright.config.ts", dashboardTestsPlaywrightConfigTemplate},
+		{filepath.Join("tests", "dashboard.spec.ts"), dashboardTestsSpecTemplate},
+		{"Dockerfile", dashboardTestsDockerfileTemplate},
+		{".gitignore", dashboardTestsGitignoreTemplate},
+	}
+
+	for _, file := range files {
+		tmpl, err := template.New(file.path).Delims("[[", "]]").Parse(file.template)
+		if err != nil {
+			return fmt.Errorf("failed to parse template for %s: %w", file.path, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", file.path, err)
+		}
+		plan.Add(filepath.Join(testsDir, file.path), buf.Bytes(), 0644)
+	}
+	return nil
+}
+
+// computeProjectConfigDashboardTestsUpdate reads the metaplay-project.yaml, adds the dashboard
+// tests to integrationTests.tests, and returns the updated content without writing.
+func computeProjectConfigDashboardTestsUpdate(project *metaproj.MetaplayProject, testsDir string) (string, []byte, error) {
+	// Load the existing metaplay-project.yaml
+	projectConfigFilePath := filepath.Join(project.RelativeDir, metaproj.ConfigFileName)
+	configFileBytes, err := os.ReadFile(projectConfigFilePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read project config file: %v", err)
+	}
+
+	// Parse the YAML to AST
+	root, err := parser.ParseBytes(configFileBytes, parser.ParseComments)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse project config file: %v", err)
+	}
+
+	// Append the test to the existing tests, or add the missing sections.
+	tests := []metaproj.CustomIntegrationTestConfig{{
+		Name:        dashboardTestsName,
+		Description: "Run custom LiveOps Dashboard Playwright tests",
+		Target:      dashboardTestsTarget,
+		Dockerfile:  testsDir + "/Dockerfile",
+	}}
+	type testsSection struct {
+		Tests []metaproj.CustomIntegrationTestConfig `yaml:"tests"`
+	}
+	switch {
+	case project.Config.IntegrationTests != nil && len(project.Config.IntegrationTests.Tests) > 0:
+		err = updateYamlNode(root, "$.integrationTests.tests", tests)
+	case project.Config.IntegrationTests != nil:
+		err = updateYamlNode(root, "$.integrationTests", testsSection{Tests: tests})
+	default:
+		err = updateYamlNode(root, "$", struct {
+			IntegrationTests testsSection `yaml:"integrationTests"`
+		}{testsSection{Tests: tests}})
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	content := root.String()
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return projectConfigFilePath, []byte(content), nil
+}
+
+const dashboardTestsPackageJSONTemplate = `{
+  "name": "[[.ProjectHumanID]]-dashboard-tests",
+  "private": true,
+  "scripts": {
+    "test": "playwright test",
+    "report": "playwright show-report test-results/report"
+  },
+  "devDependencies": {
+    "@playwright/test": "[[.PlaywrightVersion]]"
+  }
+}
+`
+
+const dashboardTestsPlaywrightConfigTemplate = `// Playwright configuration for the custom LiveOps Dashboard tests.
+//
+// The tests are run against the dashboard at DASHBOARD_BASE_URL, which 'metaplay test integration'
+// sets to the dashboard of the game server being tested. Locally, the tests default to the dashboard
+// served by the locally running game server.
+import { defineConfig, devices } from '@playwright/test'
+
+// Ensure a trailing slash, so that relative paths in the tests (eg, './players') resolve
+// against the dashboard root also when it is served from a sub-path.
+const baseURL = (process.env.DASHBOARD_BASE_URL ?? 'http://localhost:5550').replace(/\/*$/, '/')
+
+// 'metaplay test integration' collects the results from OUTPUT_DIRECTORY.
+const outputDirectory = process.env.OUTPUT_DIRECTORY ?? 'test-results'
+
+// Cloud environments require authentication: 'metaplay test integration --environment' passes
+// an access token for the admin API.
+const accessToken = process.env.METAPLAY_ACCESS_TOKEN
+
+export default defineConfig({
+  testDir: './tests',
+  outputDir: outputDirectory + '/artifacts',
+  forbidOnly: !!process.env.CI,
+  retries: process.env.CI ? 1 : 0,
+  reporter: [
+    ['list'],
+    ['junit', { outputFile: outputDirectory + '/junit.xml' }],
+    ['html', { outputFolder: outputDirectory + '/report', open: 'never' }],
+  ],
+  use: {
+    baseURL,
+    extraHTTPHeaders: accessToken ? { Authorization: 'Bearer ' + accessToken } : undefined,
+    trace: 'retain-on-failure',
+    screenshot: 'only-on-failure',
+  },
+  projects: [
+    { name: 'chromium', use: { ...devices['Desktop Chrome'] } },
+  ],
+})
+`
+
+const dashboardTestsSpecTemplate = `import { test, expect } from '@playwright/test'
+
+// Example test for the custom LiveOps Dashboard. Add tests for your own dashboard views here.
+// Use relative paths (eg, './players') with page.goto() to navigate within the dashboard.
+test.describe('LiveOps Dashboard', () => {
+  test('loads the overview page', async ({ page }) => {
+    const response = await page.goto('./')
+    expect(response?.ok()).toBeTruthy()
+    await expect(page.locator('body')).toBeVisible()
+  })
+})
+`
+
+const dashboardTestsDockerfileTemplate = `# Container for running the custom LiveOps Dashboard tests with 'metaplay test integration'.
+# The image is built from the '[[.TestTarget]]' stage, with the build root directory as the
+# context. The location of the project within the build root is passed in PROJECT_ROOT.
+FROM mcr.microsoft.com/playwright:v[[.PlaywrightVersion]]-noble AS [[.TestTarget]]
+ARG PROJECT_ROOT=.
+
+WORKDIR /DashboardTests
+COPY ${PROJECT_ROOT}/[[.TestsDir]]/package.json ./
+RUN npm install --no-audit --no-fund
+COPY ${PROJECT_ROOT}/[[.TestsDir]]/playwright.config.ts ./
+COPY ${PROJECT_ROOT}/[[.TestsDir]]/tests/ ./tests/
+
+ENV CI=true
+CMD ["npx", "playwright", "test"]
+`
+
+const dashboardTestsGitignoreTemplate = `node_modules/
+test-results/
+`
@@ -0,0 +1,90 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectDashboardTestsFiles(t *testing.T) {
+	testsDir := filepath.Join(t.TempDir(), "Backend", "DashboardTests")
+	plan := filesetwriter.NewPlan(false)
+	require.NoError(t, collectDashboardTestsFiles(plan, testsDir, dashboardTestsTemplateData{
+		ProjectHumanID:    "lovely-wombats-build",
+		TestsDir:          "Backend/DashboardTests",
+		PlaywrightVersion: dashboardTestsPlaywrightVersion,
+		TestTarget:        dashboardTestsTarget,
+	}))
+	require.NoError(t, plan.Scan())
+	require.NoError(t, plan.Execute())
+
+	readFile := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(testsDir, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	assert.Contains(t, readFile("package.json"), `"name": "lovely-wombats-build-dashboard-tests"`)
+	assert.Contains(t, readFile("package.json"), `"@playwright/test": "`+dashboardTestsPlaywrightVersion+`"`)
+	assert.Contains(t, readFile("playwright.config.ts"), "process.env.DASHBOARD_BASE_URL")
+
+	// The Docker image must match the Playwright version, and copy the files relative to the build root.
+	dockerfile := readFile("Dockerfile")
+	assert.Contains(t, dockerfile, "FROM mcr.microsoft.com/playwright:v"+dashboardTestsPlaywrightVersion+"-noble AS dashboard-tests")
+	assert.Contains(t, dockerfile, "COPY ${PROJECT_ROOT}/Backend/DashboardTests/tests/ ./tests/")
+	assert.Contains(t, readFile(filepath.Join("tests", "dashboard.spec.ts")), "page.goto('./')")
+}
+
+func TestComputeProjectConfigDashboardTestsUpdate(t *testing.T) {
+	configs := map[string]string{
+		"no integration tests": "projectID: lovely-wombats-build\n# Dashboard\nfeatures:\n  dashboard:\n    useCustom: true\n",
+		"only docker config":   "projectID: lovely-wombats-build\nintegrationTests:\n  docker:\n    buildArgs: [\"--no-cache\"]\n",
+		"existing tests":       "projectID: lovely-wombats-build\nintegrationTests:\n  tests:\n    - name: k6\n      image: grafana/k6:latest\n",
+	}
+
+	for name, configContent := range configs {
+		t.Run(name, func(t *testing.T) {
+			projectDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(projectDir, metaproj.ConfigFileName), []byte(configContent), 0644))
+
+			var config metaproj.ProjectConfig
+			require.NoError(t, yaml.Unmarshal([]byte(configContent), &config))
+			project := &metaproj.MetaplayProject{RelativeDir: projectDir, Config: config}
+			assert.False(t, hasCustomIntegrationTest(project, dashboardTestsName))
+
+			configPath, updated, err := computeProjectConfigDashboardTestsUpdate(project, "Backend/DashboardTests")
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(projectDir, metaproj.ConfigFileName), configPath)
+
+			// The other config is kept and the test is added last.
+			var updatedConfig metaproj.ProjectConfig
+			require.NoError(t, yaml.Unmarshal(updated, &updatedConfig))
+			assert.Equal(t, "lovely-wombats-build", updatedConfig.ProjectHumanID)
+			require.NotNil(t, updatedConfig.IntegrationTests)
+			if config.IntegrationTests != nil {
+				assert.Equal(t, config.IntegrationTests.Docker, updatedConfig.IntegrationTests.Docker)
+				assert.Len(t, updatedConfig.IntegrationTests.Tests, len(config.IntegrationTests.Tests)+1)
+			}
+			tests := updatedConfig.IntegrationTests.Tests
+			assert.Equal(t, metaproj.CustomIntegrationTestConfig{
+				Name:        "custom-dashboard",
+				Description: "Run custom LiveOps Dashboard Playwright tests",
+				Target:      "dashboard-tests",
+				Dockerfile:  "Backend/DashboardTests/Dockerfile",
+			}, tests[len(tests)-1])
+
+			project.Config = updatedConfig
+			assert.True(t, hasCustomIntegrationTest(project, dashboardTestsName))
+		})
+	}
+}
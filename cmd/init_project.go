@@ -5,10 +5,13 @@
 package cmd
 
 import (
+	"archive/zip"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	clierrors "github.com/metaplay/cli/internal/errors"
@@ -32,13 +35,18 @@ type initProjectOpts struct {
 	flagSdkVersion         string // Metaplay SDK version to use (e.g., "34.0").
 	flagSdkSource          string // Path to Metaplay SDK release .zip to use.
 	flagUnityProjectPath   string // Path to the Unity project files within the project.
+	flagUnrealProjectPath  string // Path to the Unreal Engine project (with the .uproject) within the project.
 	flagAutoAgreeContracts bool   // Automatically agree to the terms & conditions.
 	flagAutoConfirm        bool   // Automatically confirm the 'Does this look correct?'
 	flagNoSample           bool   // Skip installing the MetaplayHelloWorld sample.
-
-	projectPath              string // User-provided path to project root (relative or absolute).
-	absoluteProjectPath      string // Absolute path to the project root.
-	relativeUnityProjectPath string // Relative path to the Unity project from the project root.
+	flagServerOnly         bool   // Initialize a server-only project without a game client.
+	flagPlanJSON           bool   // Output the file plan as JSON without writing anything.
+	flagPlanOnly           bool   // Show the file plan without writing anything.
+
+	projectPath               string                // User-provided path to project root (relative or absolute).
+	absoluteProjectPath       string                // Absolute path to the project root.
+	clientEngine              metaproj.ClientEngine // Game engine of the client project (Unity, Unreal Engine or none).
+	relativeClientProjectPath string                // Relative path to the Unity or Unreal project from the project root (empty for server-only).
 }
 
 func init() {
@@ -46,7 +54,7 @@ func init() {
 
 	cmd := &cobra.Command{
 		Use:   "project [flags]",
-		Short: "Initialize Metaplay SDK in an existing Unity project",
+		Short: "Initialize Metaplay SDK in an existing Unity or Unreal Engine project",
 		Run:   runCommand(&o),
 		Long: renderLong(&o, `
 			Integrate Metaplay SDK into an existing project.
@@ -65,6 +73,23 @@ func init() {
 			  - Backend/
 			3. Add reference to the Metaplay Client SDK to your Unity project package.json.
 
+			Unreal Engine projects (with a .uproject file) are also supported, with an SDK version
+			that includes the Unreal Engine client plugin. The client project is auto-detected,
+			or can be specified with --unity-project or --unreal-project. For Unreal Engine projects:
+			- The shared game logic code is placed in SharedCode/ in the project root.
+			- The SDK's MetaplaySDK/ClientUnreal/ is added to the .uproject's
+			  AdditionalPluginDirectories and the Metaplay plugin is enabled.
+			- The metaplay-project.yaml uses 'unrealProjectDir' instead of 'unityProjectDir'.
+
+			Use --server-only to initialize a backend-only project without a game client, eg, for
+			headless services or custom clients. The shared game logic code is placed in SharedCode/
+			in the project root and no client project is required. This also requires an SDK version
+			with support for server-only projects.
+
+			Use --plan-only to only show which files would be written, or --plan-json to output the
+			planned files, actions and diffs as JSON (eg, for code review bots). Neither writes
+			any files into the project.
+
 			Related commands:
 			- 'metaplay build image' builds a docker image to be deployed to the cloud.
 			- 'metaplay update project-environments' updates the environments list in metaplay-project.yaml from the cloud.
@@ -80,11 +105,26 @@ func init() {
 			# Initialize SDK in your project at a specific path.
 			metaplay init project --project ../project-path
 
+			# Initialize SDK in an Unreal Engine project in the MyGame/ directory.
+			metaplay init project --unreal-project MyGame
+
+			# Initialize a server-only project without a game client.
+			metaplay init project --server-only
+
 			# Specify Metaplay SDK version to use (only 34.0 and above are supported).
 			metaplay init project --sdk-version=34.0
 
 			# Use a pre-downloaded Metaplay SDK archive.
 			metaplay init project --sdk-source=metaplay-sdk-release-34.0.zip
+
+			# Output the files that would be written as JSON, without writing anything.
+			metaplay init project --project-id=fancy-gorgeous-bear --sdk-source=metaplay-sdk-release-34.0.zip --plan-json
+
+			# Record the answers to the interactive wizard into a file.
+			metaplay init project --record-answers answers.yaml
+
+			# Replay the recorded answers without prompting.
+			metaplay init project --answers answers.yaml
 		`),
 	}
 
@@ -94,9 +134,13 @@ func init() {
 	flags.StringVar(&o.flagSdkVersion, "sdk-version", "", "Specify Metaplay SDK version to use, defaults to latest (optional)")
 	flags.StringVar(&o.flagSdkSource, "sdk-source", "", "Install from the specified SDK archive file or use existing MetaplaySDK directory, eg, 'metaplay-sdk-release-34.0.zip' (optional)")
 	flags.StringVar(&o.flagUnityProjectPath, "unity-project", "", "Path to the Unity project files within the project (default: auto-detect)")
+	flags.StringVar(&o.flagUnrealProjectPath, "unreal-project", "", "Path to the Unreal Engine project (the directory with the .uproject file) within the project (default: auto-detect)")
 	flags.BoolVar(&o.flagAutoAgreeContracts, "auto-agree", false, "Automatically agree to the privacy policy and terms and conditions")
 	flags.BoolVar(&o.flagAutoConfirm, "yes", false, "Automatically confirm the 'Does this look correct?' confirmation")
 	flags.BoolVar(&o.flagNoSample, "no-sample", false, "Skip installing the MetaplayHelloWorld sample scene")
+	flags.BoolVar(&o.flagServerOnly, "server-only", false, "Initialize a server-only project without a Unity or Unreal Engine client")
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
 
 	initCmd.AddCommand(cmd)
 }
@@ -132,24 +176,18 @@ func (o *initProjectOpts) Prepare(cmd *cobra.Command, args []string) error {
 			WithSuggestion("To re-initialize, first remove the existing metaplay-project.yaml file")
 	}
 
-	// If Unity project path is not specified, try to find it within the project.
-	if o.flagUnityProjectPath == "" {
-		relativeUnityPath, err := findUnityProjectPath(o.absoluteProjectPath)
-		if err != nil {
-			return err
-		}
-		o.relativeUnityProjectPath = relativeUnityPath
-	} else {
-		o.relativeUnityProjectPath = o.flagUnityProjectPath
+	// Resolve the client project (Unity, Unreal Engine or none).
+	if err := o.resolveClientProject(); err != nil {
+		return err
 	}
 
-	// Validate the Unity project path
-	if err := validateUnityProjectPath(o.absoluteProjectPath, o.relativeUnityProjectPath); err != nil {
-		return err
+	// JSON output must not be mixed with interactive selections.
+	if o.flagPlanJSON && o.flagProjectID == "" {
+		return clierrors.NewUsageError("--project-id is required with --plan-json")
 	}
 
-	// Must be either in interactive mode or specify --yes.
-	if !tui.IsInteractiveMode() && !o.flagAutoConfirm {
+	// Must be either in interactive mode (or replaying answers) or specify --yes (unless only showing the plan).
+	if !tui.CanAskQuestions() && !o.flagAutoConfirm && !o.flagPlanJSON && !o.flagPlanOnly {
 		return fmt.Errorf("use --yes to automatically confirm changes when running in non-interactive mode")
 	}
 
@@ -244,24 +282,33 @@ func (o *initProjectOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
-	log.Info().Msg("")
-	log.Info().Msg(styles.RenderTitle("Integrate Metaplay SDK to Your Project"))
-	log.Info().Msg("")
-
-	log.Info().Msgf("Project:            %s %s", styles.RenderTechnical(targetProject.Name), styles.RenderMuted(fmt.Sprintf("[%s]", targetProject.HumanID)))
-	log.Info().Msgf("Project root:       %s", styles.RenderTechnical(o.absoluteProjectPath))
-	log.Info().Msgf("Unity project dir:  %s", styles.RenderTechnical(filepath.Join(o.absoluteProjectPath, o.relativeUnityProjectPath)))
-	if sdkVersionInfo != nil {
-		log.Info().Msgf("Metaplay version:   %s %s", styles.RenderTechnical(sdkVersionInfo.Version), sdkVersionBadge)
-		log.Info().Msgf("Metaplay SDK dir:   %s%s", styles.RenderTechnical("MetaplaySDK"), styles.RenderAttention(" [new]"))
-	} else if isDirectory(metaplaySdkSource) {
-		log.Info().Msgf("Metaplay SDK:       %s %s", styles.RenderTechnical(metaplaySdkSource), styles.RenderAttention("[use existing]"))
-	} else {
-		log.Info().Msgf("Metaplay SDK:       %s", styles.RenderTechnical(metaplaySdkSource))
-		log.Info().Msgf("Metaplay SDK dir:   %s%s", styles.RenderTechnical("MetaplaySDK"), styles.RenderAttention(" [new]"))
+	if !o.flagPlanJSON {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle("Integrate Metaplay SDK to Your Project"))
+		log.Info().Msg("")
+
+		log.Info().Msgf("Project:            %s %s", styles.RenderTechnical(targetProject.Name), styles.RenderMuted(fmt.Sprintf("[%s]", targetProject.HumanID)))
+		log.Info().Msgf("Project root:       %s", styles.RenderTechnical(o.absoluteProjectPath))
+		switch o.clientEngine {
+		case metaproj.ClientEngineUnreal:
+			log.Info().Msgf("Unreal project dir: %s", styles.RenderTechnical(filepath.Join(o.absoluteProjectPath, o.relativeClientProjectPath)))
+		case metaproj.ClientEngineNone:
+			log.Info().Msgf("Client project:     %s", styles.RenderMuted("none (server-only)"))
+		default:
+			log.Info().Msgf("Unity project dir:  %s", styles.RenderTechnical(filepath.Join(o.absoluteProjectPath, o.relativeClientProjectPath)))
+		}
+		if sdkVersionInfo != nil {
+			log.Info().Msgf("Metaplay version:   %s %s", styles.RenderTechnical(sdkVersionInfo.Version), sdkVersionBadge)
+			log.Info().Msgf("Metaplay SDK dir:   %s%s", styles.RenderTechnical("MetaplaySDK"), styles.RenderAttention(" [new]"))
+		} else if isDirectory(metaplaySdkSource) {
+			log.Info().Msgf("Metaplay SDK:       %s %s", styles.RenderTechnical(metaplaySdkSource), styles.RenderAttention("[use existing]"))
+		} else {
+			log.Info().Msgf("Metaplay SDK:       %s", styles.RenderTechnical(metaplaySdkSource))
+			log.Info().Msgf("Metaplay SDK dir:   %s%s", styles.RenderTechnical("MetaplaySDK"), styles.RenderAttention(" [new]"))
+		}
+		log.Info().Msgf("Game backend dir:   %s%s", styles.RenderTechnical("Backend"), styles.RenderAttention(" [new]"))
+		log.Info().Msg("")
 	}
-	log.Info().Msgf("Game backend dir:   %s%s", styles.RenderTechnical("Backend"), styles.RenderAttention(" [new]"))
-	log.Info().Msg("")
 
 	// --- Step 1: Download SDK zip (with progress bar) ---
 	var relativePathToSdk string
@@ -298,15 +345,21 @@ func (o *initProjectOpts) Run(cmd *cobra.Command) error {
 		log.Debug().Msgf("SDK archive validated: v%s", sdkMetadata.SdkVersion)
 	}
 
+	// Unreal Engine and server-only projects require the SDK to support them.
+	if err := o.checkSdkSupportsClientEngine(sdkZipPath, filepath.Join(o.projectPath, relativePathToSdk)); err != nil {
+		return err
+	}
+
 	// --- Step 3: Collect project files ---
 	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
 
 	// Render metaplay-project.yaml content.
 	yamlContent, projectConfig, err := metaproj.RenderProjectConfigYAML(
 		sdkMetadata,
-		o.relativeUnityProjectPath,
+		o.clientEngine,
+		o.relativeClientProjectPath,
 		relativePathToSdk,
-		filepath.Join(o.relativeUnityProjectPath, "Assets", "SharedCode"),
+		o.getSharedCodePath(),
 		"Backend", // game backend dir
 		"",        // game dashboard dir
 		targetProject,
@@ -329,23 +382,37 @@ func (o *initProjectOpts) Run(cmd *cobra.Command) error {
 		"PROJECT_DISPLAY_NAME":      targetProject.Name,
 		"BACKEND_SOLUTION_FILENAME": "Server.sln",
 	}
+	templateFileName := o.getProjectTemplateFileName()
 	if sdkZipPath != "" {
-		err = collectFromTemplateInZip(plan, sdkZipPath, "project_template.json", ".", projectConfig, templateReplacements, o.flagNoSample)
+		err = collectFromTemplateInZip(plan, sdkZipPath, templateFileName, ".", projectConfig, templateReplacements, o.flagNoSample)
 	} else {
-		err = collectFromTemplate(plan, project, ".", "project_template.json", templateReplacements, o.flagNoSample)
+		err = collectFromTemplate(plan, project, ".", templateFileName, templateReplacements, o.flagNoSample)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to collect SDK template files: %w", err)
 	}
 
-	// Compute manifest.json update.
-	log.Debug().Msgf("Compute Metaplay Client SDK reference for Unity manifest.json")
-	manifestPath, manifestContent, err := computeManifestUpdate(project)
-	if err != nil {
-		return err
+	// Add the reference to the Metaplay client SDK into the client project (if any).
+	switch o.clientEngine {
+	case metaproj.ClientEngineUnreal:
+		log.Debug().Msgf("Compute Metaplay plugin reference for the Unreal .uproject")
+		uprojectPath, uprojectContent, err := computeUnrealProjectUpdate(project)
+		if err != nil {
+			return err
+		}
+		plan.AddUpdate(uprojectPath, uprojectContent, 0644, "add the Metaplay plugin from MetaplaySDK")
+	case metaproj.ClientEngineUnity:
+		log.Debug().Msgf("Compute Metaplay Client SDK reference for Unity manifest.json")
+		manifestPath, manifestContent, err := computeManifestUpdate(project)
+		if err != nil {
+			return err
+		}
+		plan.AddUpdate(manifestPath, manifestContent, 0644, "add reference to io.metaplay.unitysdk")
 	}
-	plan.AddUpdate(manifestPath, manifestContent, 0644, "add reference to io.metaplay.unitysdk")
 	plan.Add(configFilePath, []byte(yamlContent), 0644)
+	if err := addGitManagedBlocksToPlan(plan, o.projectPath); err != nil {
+		return err
+	}
 
 	// --- Step 4: Add zip extraction to plan ---
 	if sdkZipPath != "" {
@@ -357,6 +424,11 @@ func (o *initProjectOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// With --plan-json or --plan-only, show the plan and stop.
+	if o.flagPlanJSON || o.flagPlanOnly {
+		return showPlanOnly(plan, o.flagPlanJSON, true)
+	}
+
 	log.Info().Msg("")
 	log.Info().Msg("Files to be modified:")
 	plan.Preview(true)
@@ -389,16 +461,128 @@ func (o *initProjectOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg("")
 	log.Info().Msg("The following changes were made to your project:")
 	log.Info().Msgf("- Added project configuration file %s", styles.RenderTechnical("metaplay-project.yaml"))
-	log.Info().Msgf("- Added shared game logic code at %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeUnityProjectPath, "Assets/SharedCode/"))))
-	if !o.flagNoSample {
-		log.Info().Msgf("- Added sample scene in %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeUnityProjectPath, "Assets/MetaplayHelloWorld/"))))
+	log.Info().Msgf("- Added shared game logic code at %s", styles.RenderTechnical(filepath.ToSlash(o.getSharedCodePath())+"/"))
+	switch o.clientEngine {
+	case metaproj.ClientEngineUnreal:
+		log.Info().Msgf("- Added the Metaplay plugin from %s to %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(relativePathToSdk, metaproj.UnrealPluginsSdkDir))), styles.RenderTechnical(".uproject"))
+	case metaproj.ClientEngineUnity:
+		if !o.flagNoSample {
+			log.Info().Msgf("- Added sample scene in %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeClientProjectPath, "Assets/MetaplayHelloWorld/"))))
+		}
+		log.Info().Msgf("- Added pre-built game config archive to %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeClientProjectPath, "Assets/StreamingAssets/"))))
+		log.Info().Msgf("- Added reference to Metaplay Client SDK in %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeClientProjectPath, "Packages/manifest.json"))))
+	}
+	log.Info().Msgf("- Added ignore rules for the files generated by the CLI to %s and %s", styles.RenderTechnical(".gitignore"), styles.RenderTechnical(".gitattributes"))
+
+	return nil
+}
+
+// resolveClientProject resolves the client project from the flags, or by searching the project
+// for a Unity project first and then for an Unreal Engine project.
+func (o *initProjectOpts) resolveClientProject() error {
+	switch {
+	case o.flagUnityProjectPath != "" && o.flagUnrealProjectPath != "":
+		return clierrors.NewUsageError("--unity-project and --unreal-project cannot be used together")
+	case o.flagServerOnly && (o.flagUnityProjectPath != "" || o.flagUnrealProjectPath != ""):
+		return clierrors.NewUsageError("--server-only cannot be used with --unity-project or --unreal-project")
+	case o.flagServerOnly:
+		o.clientEngine = metaproj.ClientEngineNone
+		o.relativeClientProjectPath = ""
+		return nil
+	case o.flagUnityProjectPath != "":
+		o.clientEngine = metaproj.ClientEngineUnity
+		o.relativeClientProjectPath = o.flagUnityProjectPath
+	case o.flagUnrealProjectPath != "":
+		o.clientEngine = metaproj.ClientEngineUnreal
+		o.relativeClientProjectPath = o.flagUnrealProjectPath
+	default:
+		if relativeUnityPath, err := findUnityProjectPath(o.absoluteProjectPath); err == nil {
+			o.clientEngine = metaproj.ClientEngineUnity
+			o.relativeClientProjectPath = relativeUnityPath
+		} else if relativeUnrealPath, err := findUnrealProjectPath(o.absoluteProjectPath); err == nil {
+			o.clientEngine = metaproj.ClientEngineUnreal
+			o.relativeClientProjectPath = relativeUnrealPath
+		} else {
+			return clierrors.Newf("Unable to find a Unity or Unreal Engine project within %s", o.absoluteProjectPath).
+				WithSuggestion("Specify the client project with --unity-project or --unreal-project, or use --server-only for a project without a client")
+		}
+	}
+
+	// Validate the client project path.
+	if o.clientEngine == metaproj.ClientEngineUnreal {
+		return validateUnrealProjectPath(o.absoluteProjectPath, o.relativeClientProjectPath)
+	}
+	return validateUnityProjectPath(o.absoluteProjectPath, o.relativeClientProjectPath)
+}
+
+// getSharedCodePath returns the path to the shared game logic code, relative to the project root.
+// Unity projects have it within the Unity project's Assets/. Unreal and server-only projects have
+// it in the project root as the C# code is not part of any client project.
+func (o *initProjectOpts) getSharedCodePath() string {
+	if o.clientEngine != metaproj.ClientEngineUnity {
+		return "SharedCode"
+	}
+	return filepath.Join(o.relativeClientProjectPath, "Assets", "SharedCode")
+}
+
+// getProjectTemplateFileName returns the name of the SDK's installer template for the client engine.
+func (o *initProjectOpts) getProjectTemplateFileName() string {
+	switch o.clientEngine {
+	case metaproj.ClientEngineUnreal:
+		return "project_template_unreal.json"
+	case metaproj.ClientEngineNone:
+		return "project_template_server.json"
+	default:
+		return "project_template.json"
 	}
-	log.Info().Msgf("- Added pre-built game config archive to %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeUnityProjectPath, "Assets/StreamingAssets/"))))
-	log.Info().Msgf("- Added reference to Metaplay Client SDK in %s", styles.RenderTechnical(filepath.ToSlash(filepath.Join(o.relativeUnityProjectPath, "Packages/manifest.json"))))
+}
 
+// checkSdkSupportsClientEngine checks that the SDK includes the files needed for the client
+// engine: the Unreal Engine client plugin or the server-only project template. The files are
+// checked in the SDK archive (if non-empty) or in the existing SDK directory.
+func (o *initProjectOpts) checkSdkSupportsClientEngine(sdkZipPath string, sdkRootDir string) error {
+	switch o.clientEngine {
+	case metaproj.ClientEngineUnreal:
+		found, err := sdkContainsPath(sdkZipPath, sdkRootDir, metaproj.UnrealPluginsSdkDir+"/")
+		if err != nil {
+			return err
+		}
+		if !found {
+			return clierrors.New("This Metaplay SDK version does not include the Unreal Engine client plugin").
+				WithDetails(fmt.Sprintf("The plugin is expected in MetaplaySDK/%s/", metaproj.UnrealPluginsSdkDir)).
+				WithSuggestion("Use an SDK version with Unreal Engine support, or use --unity-project for a Unity project")
+		}
+	case metaproj.ClientEngineNone:
+		found, err := sdkContainsPath(sdkZipPath, sdkRootDir, "Installer/"+o.getProjectTemplateFileName())
+		if err != nil {
+			return err
+		}
+		if !found {
+			return clierrors.New("This Metaplay SDK version does not support server-only projects").
+				WithDetails(fmt.Sprintf("The project template is expected in MetaplaySDK/Installer/%s", o.getProjectTemplateFileName())).
+				WithSuggestion("Use an SDK version with server-only project support, or initialize with a Unity or Unreal Engine project")
+		}
+	}
 	return nil
 }
 
+// sdkContainsPath checks whether the SDK contains the given path (relative to MetaplaySDK/, with
+// a trailing slash for directories), either in the SDK archive (if non-empty) or in the SDK directory.
+func sdkContainsPath(sdkZipPath string, sdkRootDir string, relPath string) (bool, error) {
+	if sdkZipPath == "" {
+		_, err := os.Stat(filepath.Join(sdkRootDir, filepath.FromSlash(relPath)))
+		return err == nil, nil
+	}
+
+	reader, err := zip.OpenReader(sdkZipPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open SDK archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+	entryPath := "MetaplaySDK/" + relPath
+	return slices.ContainsFunc(reader.File, func(f *zip.File) bool { return strings.HasPrefix(f.Name, entryPath) }), nil
+}
+
 // ensureSdkDownloadContractsAccepted ensures that the user has agreed to the Privacy Policy and Terms & Conditions
 // before SDK download. If auto-agree is specified, the contracts are accepted automatically, otherwise the user is
 // prompted to agree to the contracts.
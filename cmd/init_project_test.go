@@ -0,0 +1,63 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveClientProjectServerOnly(t *testing.T) {
+	o := initProjectOpts{absoluteProjectPath: t.TempDir(), flagServerOnly: true}
+	require.NoError(t, o.resolveClientProject())
+	assert.Equal(t, metaproj.ClientEngineNone, o.clientEngine)
+	assert.Equal(t, "", o.relativeClientProjectPath)
+	assert.Equal(t, "SharedCode", o.getSharedCodePath())
+	assert.Equal(t, "project_template_server.json", o.getProjectTemplateFileName())
+
+	o = initProjectOpts{absoluteProjectPath: t.TempDir(), flagServerOnly: true, flagUnityProjectPath: "Unity"}
+	assert.Error(t, o.resolveClientProject())
+
+	// Without a client project or --server-only, the resolving fails.
+	o = initProjectOpts{absoluteProjectPath: t.TempDir()}
+	assert.ErrorContains(t, o.resolveClientProject(), "Unable to find a Unity or Unreal Engine project")
+}
+
+func TestSdkContainsPath(t *testing.T) {
+	// SDK directory.
+	sdkDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sdkDir, "Installer"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sdkDir, "Installer", "project_template_server.json"), []byte("{}"), 0644))
+
+	found, err := sdkContainsPath("", sdkDir, "Installer/project_template_server.json")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = sdkContainsPath("", sdkDir, "ClientUnreal/")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// SDK archive.
+	zipPath := filepath.Join(t.TempDir(), "sdk.zip")
+	zipFile, err := os.Create(zipPath)
+	require.NoError(t, err)
+	zipWriter := zip.NewWriter(zipFile)
+	_, err = zipWriter.Create("MetaplaySDK/ClientUnreal/Metaplay/Metaplay.uplugin")
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+	require.NoError(t, zipFile.Close())
+
+	found, err = sdkContainsPath(zipPath, "", "ClientUnreal/")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = sdkContainsPath(zipPath, "", "Installer/project_template_server.json")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
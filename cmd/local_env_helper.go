@@ -0,0 +1,65 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// loadLocalEnvFile loads the developer-specific environment variables from the project's
+// .metaplay/env.local.yaml (if any). Warns if the file is not gitignored, as it may contain
+// secrets. The values are never logged.
+func loadLocalEnvFile(ctx context.Context, project *metaproj.MetaplayProject) (*metaproj.LocalEnvFile, error) {
+	envFile, err := metaproj.LoadLocalEnvFile(project.RelativeDir)
+	if err != nil {
+		return nil, clierrors.Wrap(err, "Failed to load the local environment variables file").
+			WithSuggestion(fmt.Sprintf("Check the syntax of %s: it should have 'env', 'server', and/or 'botClient' maps of variable names to values", metaproj.LocalEnvFilePath))
+	}
+	if envFile.IsEmpty() {
+		return envFile, nil
+	}
+
+	numVars := len(envFile.Env) + len(envFile.Server) + len(envFile.BotClient)
+	log.Info().Msgf("Using %d local environment variable(s) from %s", numVars, styles.RenderTechnical(metaproj.LocalEnvFilePath))
+	if isCommittable, err := isFileCommittable(ctx, project.RelativeDir, metaproj.LocalEnvFilePath); err == nil && isCommittable {
+		log.Warn().Msgf("%s %s is not gitignored, add it to .gitignore (or run 'metaplay update gitignore') to avoid committing secrets by accident", styles.RenderWarning("Warning:"), metaproj.LocalEnvFilePath)
+	}
+	return envFile, nil
+}
+
+// isFileCommittable returns true if the file in the git repository is not ignored by git.
+// Returns an error if the directory is not in a git repository or git is not available.
+func isFileCommittable(ctx context.Context, repoDir, filePath string) (bool, error) {
+	cmd := exec.CommandContext(ctx, "git", "check-ignore", "--quiet", "--no-index", filePath)
+	cmd.Dir = repoDir
+	err := cmd.Run()
+	if err == nil {
+		return false, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+// formatEnvVars returns the environment variables in the 'NAME=value' format, sorted by name.
+func formatEnvVars(env map[string]string) []string {
+	result := make([]string, 0, len(env))
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		result = append(result, fmt.Sprintf("%s=%s", name, env[name]))
+	}
+	return result
+}
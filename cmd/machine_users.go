@@ -0,0 +1,144 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Default role of created machine users: the role needed for building and deploying game servers.
+const defaultMachineUserRole = "game-admin"
+
+// machine-users is a group of commands to manage the project's machine users in the portal.
+var machineUsersCmd = &cobra.Command{
+	Use:     "machine-users",
+	Aliases: []string{"machine-user"},
+	Short:   "Manage the project's machine users for CI",
+	Long:    "Commands for managing the project's machine users in the Metaplay portal. Machine users are used for authenticating CI jobs with 'metaplay auth machine-login'.",
+}
+
+func init() {
+	rootCmd.AddCommand(machineUsersCmd)
+}
+
+// resolvePortalProject resolves the project in the portal, logging in with Metaplay Auth if needed,
+// as the machine users are managed in the portal.
+func resolvePortalProject(ctx context.Context, project *metaproj.MetaplayProject) (*portalapi.Client, *portalapi.ProjectInfo, error) {
+	authProvider, err := getAuthProvider(project, "metaplay")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokenSet, err := tui.RequireLoggedIn(ctx, authProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	portalClient := portalapi.NewClient(tokenSet)
+	projectInfo, err := portalClient.FetchProjectInfo(project.Config.ProjectHumanID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return portalClient, projectInfo, nil
+}
+
+// findMachineUser finds the machine user by its name, ID or client ID.
+func findMachineUser(machineUsers []portalapi.MachineUser, nameOrID string) (*portalapi.MachineUser, error) {
+	ndx := slices.IndexFunc(machineUsers, func(user portalapi.MachineUser) bool {
+		return user.Name == nameOrID || user.UID == nameOrID || user.ClientID == nameOrID
+	})
+	if ndx == -1 {
+		return nil, clierrors.Newf("Machine user '%s' not found", nameOrID).
+			WithSuggestion("Run 'metaplay machine-users list' to see the project's machine users")
+	}
+	return &machineUsers[ndx], nil
+}
+
+// formatPortalTime formats an ISO8601 timestamp from the portal relative to now, eg, '3 days ago'.
+func formatPortalTime(timestamp *string) string {
+	if timestamp == nil || *timestamp == "" {
+		return "never"
+	}
+	parsed, err := time.Parse(time.RFC3339, *timestamp)
+	if err != nil {
+		return *timestamp
+	}
+	return humanize.Time(parsed)
+}
+
+// printMachineUserCredentials prints the credentials of a created or rotated machine user. The
+// client secret cannot be retrieved from the portal again, so it must be stored right away.
+func printMachineUserCredentials(creds *portalapi.MachineUserCredentials, format string) error {
+	if format == "json" {
+		credsJSON, err := json.MarshalIndent(struct {
+			*portalapi.MachineUserCredentials
+			Credentials string `json:"credentials"`
+		}{creds, creds.CredentialsString()}, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal machine user credentials as JSON")
+		}
+		log.Info().Msg(string(credsJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("Machine user:  %s", styles.RenderTechnical(creds.Name))
+	log.Info().Msgf("Role:          %s", styles.RenderTechnical(creds.Role))
+	log.Info().Msgf("Credentials:   %s", styles.RenderTechnical(creds.CredentialsString()))
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderAttention("The credentials are only shown once, store them now!"))
+	log.Info().Msgf("Store them in a secret named %s in your CI system; 'metaplay auth machine-login' reads them from there.", styles.RenderTechnical("METAPLAY_CREDENTIALS"))
+	return nil
+}
+
+// confirmMachineUserChange asks for confirmation before a change that breaks the existing
+// credentials of a machine user, unless confirmed with --yes.
+func confirmMachineUserChange(ctx context.Context, autoConfirm bool, question string) (bool, error) {
+	if autoConfirm {
+		return true, nil
+	}
+	if !tui.IsInteractiveMode() {
+		return false, clierrors.New("Confirmation required to modify the machine user").
+			WithSuggestion("Use --yes to confirm in non-interactive mode")
+	}
+	return tui.DoConfirmQuestion(ctx, question)
+}
+
+// validateMachineUserFormat checks the --format flag of the machine user commands.
+func validateMachineUserFormat(format string) error {
+	if format != "text" && format != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", format).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+// renderMachineUsersTable renders the machine users as a table.
+func renderMachineUsersTable(machineUsers []portalapi.MachineUser) []string {
+	header := fmt.Sprintf("%-24s %-12s %-36s %-16s %s", "NAME", "ROLE", "CLIENT ID", "CREATED", "LAST USED")
+	lines := []string{styles.RenderMuted(header)}
+	for _, user := range machineUsers {
+		lines = append(lines, fmt.Sprintf("%-24s %-12s %-36s %-16s %s",
+			user.Name,
+			user.Role,
+			user.ClientID,
+			formatPortalTime(&user.CreatedAt),
+			formatPortalTime(user.LastUsedAt)))
+	}
+	return lines
+}
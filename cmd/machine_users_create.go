@@ -0,0 +1,92 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/spf13/cobra"
+)
+
+// Create a machine user in the project.
+type machineUsersCreateOpts struct {
+	UsePositionalArgs
+
+	argName    string
+	flagRole   string
+	flagFormat string
+}
+
+func init() {
+	o := machineUsersCreateOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argName, "NAME", "Name of the machine user, eg, 'github-ci'.")
+
+	cmd := &cobra.Command{
+		Use:   "create NAME [flags]",
+		Short: "Create a machine user and print its credentials",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Create a machine user in the project in the Metaplay portal and print its credentials.
+
+			The credentials are only shown once: store them in your CI system's secrets right away,
+			eg, as METAPLAY_CREDENTIALS for 'metaplay auth machine-login'. If they are lost, use
+			'metaplay machine-users rotate' to get new ones.
+
+			The machine user gets the 'game-admin' role by default, which allows building and
+			deploying game servers.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay machine-users list' to list the project's machine users.
+			- 'metaplay init ci' to set up CI, optionally creating the machine user.
+			- 'metaplay auth machine-login' to log in with the credentials in CI.
+		`),
+		Example: renderExample(`
+			# Create a machine user for GitHub Actions.
+			metaplay machine-users create github-ci
+
+			# Create a machine user and output the credentials as JSON, eg, for a script.
+			metaplay machine-users create github-ci --format=json
+		`),
+	}
+	machineUsersCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagRole, "role", defaultMachineUserRole, "Role of the machine user in the project")
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *machineUsersCreateOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if strings.TrimSpace(o.argName) == "" {
+		return clierrors.NewUsageError("The machine user name must not be empty")
+	}
+	if o.flagRole == "" {
+		return clierrors.NewUsageError("--role must not be empty")
+	}
+	return validateMachineUserFormat(o.flagFormat)
+}
+
+func (o *machineUsersCreateOpts) Run(cmd *cobra.Command) error {
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	portalClient, projectInfo, err := resolvePortalProject(cmd.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	creds, err := portalClient.CreateMachineUser(projectInfo.UUID, o.argName, o.flagRole)
+	if err != nil {
+		return err
+	}
+
+	return printMachineUserCredentials(creds, o.flagFormat)
+}
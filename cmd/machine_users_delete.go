@@ -0,0 +1,98 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Delete a machine user from the project.
+type machineUsersDeleteOpts struct {
+	UsePositionalArgs
+
+	argName         string
+	flagAutoConfirm bool
+}
+
+func init() {
+	o := machineUsersDeleteOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argName, "NAME", "Name, ID or client ID of the machine user.")
+
+	cmd := &cobra.Command{
+		Use:   "delete NAME [flags]",
+		Short: "Delete a machine user",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Delete a machine user from the project in the Metaplay portal. Its credentials stop
+			working immediately, so CI jobs using them fail to log in.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay machine-users list' to list the project's machine users.
+			- 'metaplay machine-users rotate NAME' to replace the secret instead.
+		`),
+		Example: renderExample(`
+			# Delete machine user 'github-ci'.
+			metaplay machine-users delete github-ci
+
+			# Delete without confirmation.
+			metaplay machine-users delete github-ci --yes
+		`),
+	}
+	machineUsersCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Delete without confirmation")
+}
+
+func (o *machineUsersDeleteOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *machineUsersDeleteOpts) Run(cmd *cobra.Command) error {
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	portalClient, projectInfo, err := resolvePortalProject(cmd.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	machineUsers, err := portalClient.FetchMachineUsers(projectInfo.UUID)
+	if err != nil {
+		return err
+	}
+	machineUser, err := findMachineUser(machineUsers, o.argName)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := confirmMachineUserChange(cmd.Context(), o.flagAutoConfirm,
+		fmt.Sprintf("Delete machine user '%s'? Its credentials stop working immediately.", machineUser.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Deletion canceled."))
+		return nil
+	}
+
+	if err := portalClient.DeleteMachineUser(machineUser.UID); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("✅ Machine user %s deleted", styles.RenderTechnical(machineUser.Name))
+	return nil
+}
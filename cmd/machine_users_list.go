@@ -0,0 +1,94 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// List the project's machine users.
+type machineUsersListOpts struct {
+	flagFormat string
+}
+
+func init() {
+	o := machineUsersListOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the project's machine users",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			List the machine users of the project in the Metaplay portal, with their roles, client
+			IDs, and when they were created and last used. The client secrets are never shown.
+
+			The project is resolved from the metaplay-project.yaml.
+
+			Related commands:
+			- 'metaplay machine-users create NAME' to create a new machine user.
+			- 'metaplay machine-users rotate NAME' to replace the secret of a machine user.
+			- 'metaplay machine-users delete NAME' to delete a machine user.
+		`),
+		Example: renderExample(`
+			# List the project's machine users.
+			metaplay machine-users list
+
+			# List the machine users as JSON.
+			metaplay machine-users list --format=json
+		`),
+	}
+	machineUsersCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *machineUsersListOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return validateMachineUserFormat(o.flagFormat)
+}
+
+func (o *machineUsersListOpts) Run(cmd *cobra.Command) error {
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	portalClient, projectInfo, err := resolvePortalProject(cmd.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	machineUsers, err := portalClient.FetchMachineUsers(projectInfo.UUID)
+	if err != nil {
+		return err
+	}
+
+	if o.flagFormat == "json" {
+		usersJSON, err := json.MarshalIndent(machineUsers, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal machine users as JSON")
+		}
+		log.Info().Msg(string(usersJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("%s %s", styles.RenderTitle("Machine Users of"), styles.RenderTechnical(projectInfo.HumanID))
+	log.Info().Msg("")
+	if len(machineUsers) == 0 {
+		log.Info().Msg("No machine users found in the project.")
+		log.Info().Msgf("Create one with %s.", styles.RenderPrompt("metaplay machine-users create NAME"))
+		return nil
+	}
+	for _, line := range renderMachineUsersTable(machineUsers) {
+		log.Info().Msg(line)
+	}
+	return nil
+}
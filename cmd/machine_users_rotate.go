@@ -0,0 +1,100 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Rotate the client secret of a machine user.
+type machineUsersRotateOpts struct {
+	UsePositionalArgs
+
+	argName         string
+	flagAutoConfirm bool
+	flagFormat      string
+}
+
+func init() {
+	o := machineUsersRotateOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argName, "NAME", "Name, ID or client ID of the machine user.")
+
+	cmd := &cobra.Command{
+		Use:   "rotate NAME [flags]",
+		Short: "Replace the secret of a machine user and print the new credentials",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Replace the client secret of a machine user with a new one and print the new
+			credentials. The old credentials stop working immediately, so update them in your CI
+			system's secrets right away. The new credentials are only shown once.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay machine-users list' to list the project's machine users.
+			- 'metaplay machine-users delete NAME' to delete a machine user.
+		`),
+		Example: renderExample(`
+			# Rotate the secret of machine user 'github-ci'.
+			metaplay machine-users rotate github-ci
+
+			# Rotate without confirmation and output the credentials as JSON.
+			metaplay machine-users rotate github-ci --yes --format=json
+		`),
+	}
+	machineUsersCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Rotate without confirmation")
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *machineUsersRotateOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return validateMachineUserFormat(o.flagFormat)
+}
+
+func (o *machineUsersRotateOpts) Run(cmd *cobra.Command) error {
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	portalClient, projectInfo, err := resolvePortalProject(cmd.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	machineUsers, err := portalClient.FetchMachineUsers(projectInfo.UUID)
+	if err != nil {
+		return err
+	}
+	machineUser, err := findMachineUser(machineUsers, o.argName)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := confirmMachineUserChange(cmd.Context(), o.flagAutoConfirm,
+		fmt.Sprintf("Rotate the secret of machine user '%s'? The current credentials stop working immediately.", machineUser.Name))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		log.Info().Msg(styles.RenderMuted("Rotation canceled."))
+		return nil
+	}
+
+	creds, err := portalClient.RotateMachineUserSecret(machineUser.UID)
+	if err != nil {
+		return err
+	}
+
+	return printMachineUserCredentials(creds, o.flagFormat)
+}
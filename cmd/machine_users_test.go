@@ -0,0 +1,50 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMachineUser(t *testing.T) {
+	machineUsers := []portalapi.MachineUser{
+		{UID: "11111111-aaaa", Name: "github-ci", ClientID: "client-github"},
+		{UID: "22222222-bbbb", Name: "bitbucket-ci", ClientID: "client-bitbucket"},
+	}
+
+	for _, nameOrID := range []string{"bitbucket-ci", "22222222-bbbb", "client-bitbucket"} {
+		user, err := findMachineUser(machineUsers, nameOrID)
+		require.NoError(t, err, nameOrID)
+		assert.Equal(t, "bitbucket-ci", user.Name)
+	}
+
+	_, err := findMachineUser(machineUsers, "gitlab-ci")
+	assert.ErrorContains(t, err, "Machine user 'gitlab-ci' not found")
+}
+
+func TestFormatPortalTime(t *testing.T) {
+	assert.Equal(t, "never", formatPortalTime(nil))
+	empty := ""
+	assert.Equal(t, "never", formatPortalTime(&empty))
+
+	invalid := "yesterday"
+	assert.Equal(t, "yesterday", formatPortalTime(&invalid))
+
+	recent := time.Now().Add(-3 * time.Hour).UTC().Format(time.RFC3339)
+	assert.Equal(t, "3 hours ago", formatPortalTime(&recent))
+}
+
+func TestMachineUserCredentialsString(t *testing.T) {
+	creds := portalapi.MachineUserCredentials{
+		MachineUser:  portalapi.MachineUser{ClientID: "client-github"},
+		ClientSecret: "s3cret",
+	}
+	assert.Equal(t, "client-github+s3cret", creds.CredentialsString())
+}
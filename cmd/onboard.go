@@ -0,0 +1,427 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Name of the file (in the CLI config directory) where the onboarding progress is persisted.
+const onboardingStateFileName = "onboarding.json"
+
+// Steps of the onboarding wizard, in order.
+const (
+	onboardingStepLogin         = "login"
+	onboardingStepSelectProject = "select-project"
+	onboardingStepInitProject   = "init-project"
+	onboardingStepRunServer     = "run-server"
+)
+
+// onboardingSteps lists the steps with their descriptions, in order.
+var onboardingSteps = []struct {
+	id          string
+	description string
+}{
+	{onboardingStepLogin, "Sign in to the Metaplay portal"},
+	{onboardingStepSelectProject, "Select your project in the portal"},
+	{onboardingStepInitProject, "Integrate the Metaplay SDK into your project"},
+	{onboardingStepRunServer, "Run the game server locally"},
+}
+
+// onboardingState is the persisted progress of the onboarding wizard, so that an interrupted
+// onboarding can be resumed.
+type onboardingState struct {
+	Dismissed      bool     `json:"dismissed,omitempty"`      // User declined the wizard when it was offered on first run.
+	ProjectID      string   `json:"projectId,omitempty"`      // Human ID of the selected portal project.
+	ProjectDir     string   `json:"projectDir,omitempty"`     // Absolute path to the directory being onboarded.
+	CompletedSteps []string `json:"completedSteps,omitempty"` // Steps completed so far.
+}
+
+// isCompleted returns true if the given step has been completed.
+func (state *onboardingState) isCompleted(step string) bool {
+	return slices.Contains(state.CompletedSteps, step)
+}
+
+// markCompleted marks the given step completed.
+func (state *onboardingState) markCompleted(step string) {
+	if !state.isCompleted(step) {
+		state.CompletedSteps = append(state.CompletedSteps, step)
+	}
+}
+
+// hasProgress returns true if any of the steps have been completed.
+func (state *onboardingState) hasProgress() bool {
+	return len(state.CompletedSteps) > 0
+}
+
+// isFinished returns true if all of the steps have been completed.
+func (state *onboardingState) isFinished() bool {
+	for _, step := range onboardingSteps {
+		if !state.isCompleted(step.id) {
+			return false
+		}
+	}
+	return true
+}
+
+// Guide a new user through signing in, selecting a project, integrating the SDK into the
+// project, and running the game server locally.
+type onboardOpts struct {
+	flagRestart bool
+}
+
+var onboardCmd *cobra.Command
+
+func init() {
+	o := onboardOpts{}
+
+	onboardCmd = &cobra.Command{
+		Use:     "onboard [flags]",
+		Aliases: []string{"get-started"},
+		Short:   "Get started with Metaplay using a guided setup",
+		Run:     runCommand(&o),
+		Long: renderLong(&o, `
+			Get started with Metaplay using an interactive, guided setup. The setup takes you through:
+			1. Signing in to the Metaplay portal.
+			2. Selecting an existing project in the portal (or creating a new one).
+			3. Integrating the Metaplay SDK into your Unity project in the current directory.
+			4. Running the game server locally.
+
+			The progress is saved after each step. If the setup is interrupted, run the command
+			again in the same directory to resume where you left off. Use --restart to start over.
+
+			The guided setup is also offered when running 'metaplay' without arguments for the
+			first time.
+
+			Related commands:
+			- 'metaplay auth login' to sign in.
+			- 'metaplay init project' to integrate the Metaplay SDK into your project.
+			- 'metaplay dev server' to run the game server locally.
+		`),
+		Example: renderExample(`
+			# Start (or resume) the guided setup in your Unity project directory.
+			MyGame$ metaplay onboard
+
+			# Discard the earlier progress and start over.
+			MyGame$ metaplay onboard --restart
+		`),
+	}
+	rootCmd.AddCommand(onboardCmd)
+
+	onboardCmd.Flags().BoolVar(&o.flagRestart, "restart", false, "Discard the saved progress and start the setup from the beginning")
+}
+
+func (o *onboardOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if !tui.IsInteractiveMode() {
+		return clierrors.NewUsageError("The guided setup requires interactive mode").
+			WithSuggestion("Run the individual steps instead: 'metaplay auth login', 'metaplay init project', and 'metaplay dev server'")
+	}
+	return nil
+}
+
+func (o *onboardOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Resolve the directory being onboarded.
+	projectDir, err := filepath.Abs(coalesceString(flagProjectConfigPath, "."))
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	// Load the earlier progress, unless restarting.
+	state := &onboardingState{}
+	if !o.flagRestart {
+		state, err = loadOnboardingState()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Progress can only be resumed in the same directory.
+	if state.hasProgress() && state.ProjectDir != "" && state.ProjectDir != projectDir {
+		return clierrors.Newf("Guided setup was started in another directory: %s", state.ProjectDir).
+			WithSuggestion("Run 'metaplay onboard' in that directory to resume, or use --restart to start over here")
+	}
+	state.Dismissed = false
+	state.ProjectDir = projectDir
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Get Started with Metaplay"))
+	log.Info().Msg("")
+	if state.hasProgress() {
+		log.Info().Msg("Resuming the guided setup:")
+	} else {
+		log.Info().Msg("This guided setup takes you through the following steps:")
+	}
+	for ndx, step := range onboardingSteps {
+		marker := styles.RenderMuted(fmt.Sprintf("%d.", ndx+1))
+		if state.isCompleted(step.id) {
+			marker = styles.RenderSuccess("✓ ")
+		}
+		log.Info().Msgf("  %s %s", marker, step.description)
+	}
+	log.Info().Msg("")
+
+	// Step 1: Sign in.
+	authProvider := auth.NewMetaplayAuthProvider()
+	if !state.isCompleted(onboardingStepLogin) {
+		sessionState, err := auth.LoadSessionState(authProvider.GetSessionID())
+		if err != nil {
+			log.Debug().Msgf("Failed to load existing session: %v", err)
+		}
+		if sessionState == nil {
+			log.Info().Msg(styles.RenderTitle("Step 1: Sign in"))
+			log.Info().Msg("")
+			if err := auth.LoginWithBrowser(ctx, authProvider); err != nil {
+				return err
+			}
+			log.Info().Msg("")
+		}
+		state.markCompleted(onboardingStepLogin)
+		if err := saveOnboardingState(state); err != nil {
+			return err
+		}
+	}
+
+	// If the directory already has a Metaplay project, the project steps are done.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+	if project != nil {
+		state.ProjectID = project.Config.ProjectHumanID
+		state.markCompleted(onboardingStepSelectProject)
+		state.markCompleted(onboardingStepInitProject)
+		if err := saveOnboardingState(state); err != nil {
+			return err
+		}
+	}
+
+	// Step 2: Select the project in the portal.
+	if !state.isCompleted(onboardingStepSelectProject) {
+		log.Info().Msg(styles.RenderTitle("Step 2: Select your project"))
+
+		tokenSet, err := tui.RequireLoggedIn(ctx, authProvider)
+		if err != nil {
+			return err
+		}
+
+		options := []string{"existing", "new"}
+		selected, err := tui.ChooseFromListDialog("Which project do you want to use?", options, func(opt *string) (string, string) {
+			if *opt == "existing" {
+				return "Existing project", "Choose from the projects you have access to in the portal"
+			}
+			return "New project", "Create a new project in the portal first"
+		})
+		if err != nil {
+			return err
+		}
+
+		if *selected == "new" {
+			if err := saveOnboardingState(state); err != nil {
+				return err
+			}
+			log.Info().Msg("")
+			log.Info().Msgf("Create your project in the Metaplay portal at %s.", styles.RenderTechnical("https://portal.metaplay.dev"))
+			log.Info().Msgf("Then run %s again to continue from here.", styles.RenderPrompt("metaplay onboard"))
+			return nil
+		}
+
+		portalClient := portalapi.NewClient(tokenSet)
+		projectInfo, err := chooseOrgAndProject(portalClient, "")
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf(" %s %s %s", styles.RenderSuccess("✓"), projectInfo.Name, styles.RenderMuted(fmt.Sprintf("[%s]", projectInfo.HumanID)))
+		log.Info().Msg("")
+
+		state.ProjectID = projectInfo.HumanID
+		state.markCompleted(onboardingStepSelectProject)
+		if err := saveOnboardingState(state); err != nil {
+			return err
+		}
+	}
+
+	// Step 3: Integrate the SDK into the project.
+	if !state.isCompleted(onboardingStepInitProject) {
+		log.Info().Msg(styles.RenderTitle("Step 3: Integrate the Metaplay SDK"))
+
+		initOpts := &initProjectOpts{}
+		initOpts.flagProjectID = state.ProjectID
+		if err := initOpts.Prepare(cmd, nil); err != nil {
+			return err
+		}
+		if err := initOpts.Run(cmd); err != nil {
+			return err
+		}
+
+		// The project init can be canceled by the user without an error.
+		project, err := tryResolveProject()
+		if err != nil {
+			return err
+		}
+		if project == nil {
+			log.Info().Msgf("Run %s again to continue from here.", styles.RenderPrompt("metaplay onboard"))
+			return nil
+		}
+
+		state.markCompleted(onboardingStepInitProject)
+		if err := saveOnboardingState(state); err != nil {
+			return err
+		}
+		log.Info().Msg("")
+	}
+
+	// Step 4: Run the game server locally.
+	if !state.isCompleted(onboardingStepRunServer) {
+		log.Info().Msg(styles.RenderTitle("Step 4: Run the game server locally"))
+		log.Info().Msg("")
+		log.Info().Msgf("The game server runs until you stop it by pressing %s.", styles.RenderPrompt("q"))
+		log.Info().Msg("")
+
+		confirmed, err := tui.DoConfirmQuestion(ctx, "Start the game server now?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msgf("Start the game server later with %s, or run %s again to continue from here.", styles.RenderPrompt("metaplay dev server"), styles.RenderPrompt("metaplay onboard"))
+			return nil
+		}
+
+		// The server runs until stopped by the user, so mark the step completed before starting it.
+		state.markCompleted(onboardingStepRunServer)
+		if err := saveOnboardingState(state); err != nil {
+			return err
+		}
+
+		serverOpts := &devServerOpts{}
+		if err := serverOpts.Prepare(cmd, nil); err != nil {
+			return err
+		}
+		return serverOpts.Run(cmd)
+	}
+
+	log.Info().Msg(styles.RenderSuccess("✅ You're all set up!"))
+	log.Info().Msg("")
+	log.Info().Msg("Next steps:")
+	log.Info().Msgf("  - Run the game server locally with %s", styles.RenderPrompt("metaplay dev server"))
+	log.Info().Msgf("  - Build and deploy the game server to the cloud with %s and %s", styles.RenderPrompt("metaplay build image"), styles.RenderPrompt("metaplay deploy server"))
+	log.Info().Msgf("  - Read the documentation at %s", styles.RenderTechnical("https://docs.metaplay.io"))
+	return nil
+}
+
+// shouldOfferOnboarding returns true if this looks like the first run of the CLI: no one has
+// signed in, there is no Metaplay project in the current directory, and the guided setup has
+// not been started or dismissed earlier.
+func shouldOfferOnboarding() bool {
+	if !tui.IsInteractiveMode() {
+		return false
+	}
+
+	state, err := loadOnboardingState()
+	if err != nil || state.Dismissed || state.hasProgress() {
+		return false
+	}
+
+	sessionState, err := auth.LoadSessionState(auth.NewMetaplayAuthProvider().GetSessionID())
+	if err != nil || sessionState != nil {
+		return false
+	}
+
+	project, err := tryResolveProject()
+	return err == nil && project == nil
+}
+
+// offerOnboarding offers the guided setup on the first run. Returns true if the guided setup
+// was run, false if the user declined it (in which case it is not offered again).
+func offerOnboarding(cmd *cobra.Command, args []string) bool {
+	log.Info().Msg("")
+	log.Info().Msg("Welcome to Metaplay! It looks like this is your first time using the Metaplay CLI.")
+	log.Info().Msg("")
+	confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Do you want to get started with a guided setup?")
+	if err != nil || !confirmed {
+		if err := saveOnboardingState(&onboardingState{Dismissed: true}); err != nil {
+			log.Debug().Msgf("Failed to save onboarding state: %v", err)
+		}
+		log.Info().Msgf("You can start the guided setup later with %s.", styles.RenderPrompt("metaplay onboard"))
+		log.Info().Msg("")
+		return false
+	}
+
+	runCommand(&onboardOpts{})(cmd, args)
+	return true
+}
+
+// resolveOnboardingStateFilePath returns the path to the persisted onboarding state. The state
+// written by older CLI versions in the config directory is moved to the state directory.
+func resolveOnboardingStateFilePath() (string, error) {
+	configDir, err := auth.ResolveConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	stateDir, err := common.ResolveStateDir(common.StateDirState)
+	if err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(stateDir, onboardingStateFileName)
+	if err := common.MigrateLegacyStatePath(filepath.Join(configDir, onboardingStateFileName), filePath); err != nil {
+		return "", fmt.Errorf("failed to migrate onboarding state: %w", err)
+	}
+	return filePath, nil
+}
+
+// loadOnboardingState loads the persisted onboarding state. Returns an empty state if the
+// onboarding has not been started.
+func loadOnboardingState() (*onboardingState, error) {
+	filePath, err := resolveOnboardingStateFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	stateJSON, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &onboardingState{}, nil
+		}
+		return nil, fmt.Errorf("failed to read onboarding state: %w", err)
+	}
+
+	var state onboardingState
+	if err := json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal onboarding state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveOnboardingState persists the onboarding state.
+func saveOnboardingState(state *onboardingState) error {
+	filePath, err := resolveOnboardingStateFilePath()
+	if err != nil {
+		return err
+	}
+
+	stateJSON, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal onboarding state: %w", err)
+	}
+	if err := os.WriteFile(filePath, stateJSON, 0600); err != nil {
+		return fmt.Errorf("failed to write onboarding state: %w", err)
+	}
+	return nil
+}
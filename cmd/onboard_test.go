@@ -0,0 +1,30 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnboardingStateSteps(t *testing.T) {
+	state := &onboardingState{}
+	assert.False(t, state.hasProgress())
+	assert.False(t, state.isFinished())
+
+	state.markCompleted(onboardingStepLogin)
+	state.markCompleted(onboardingStepLogin)
+	assert.Equal(t, []string{onboardingStepLogin}, state.CompletedSteps)
+	assert.True(t, state.hasProgress())
+	assert.True(t, state.isCompleted(onboardingStepLogin))
+	assert.False(t, state.isCompleted(onboardingStepSelectProject))
+
+	state.markCompleted(onboardingStepSelectProject)
+	state.markCompleted(onboardingStepInitProject)
+	assert.False(t, state.isFinished())
+	state.markCompleted(onboardingStepRunServer)
+	assert.True(t, state.isFinished())
+}
@@ -0,0 +1,363 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	goversion "github.com/hashicorp/go-version"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/version"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/rs/zerolog/log"
+)
+
+// How long a fetched organization policy is used before fetching it again from the portal.
+const orgPolicyCacheTTL = 15 * time.Minute
+
+// Kind of operation checked against the organization policy.
+type orgPolicyOperation int
+
+const (
+	orgPolicyOperationDeploy  orgPolicyOperation = iota // Deploying into the environment, including activating game configs and localizations (subject to deploy windows).
+	orgPolicyOperationModify                            // Other non-destructive modifications, eg, pushing images, updating secrets, or uploading game configs.
+	orgPolicyOperationDestroy                           // Destructive operations, refused in protected environments.
+)
+
+// orgPolicyCacheEntry is the on-disk cache of a project's organization policy.
+type orgPolicyCacheEntry struct {
+	OrganizationUUID string                       `json:"organizationId"`
+	FetchedAt        time.Time                    `json:"fetchedAt"`
+	Policy           portalapi.OrganizationPolicy `json:"policy"`
+}
+
+// checkOrganizationPolicy resolves the policy of the organization owning the project and checks
+// that the operation into the target environment is allowed by it. The returned policy is used
+// by the callers for operation-specific checks. For environments resolved directly from the
+// portal (without a project), the owning project is resolved from the portal. If that fails, the
+// operation is refused, so that the policy cannot be bypassed by running outside the project.
+func checkOrganizationPolicy(project *metaproj.MetaplayProject, envConfig *metaproj.ProjectEnvironmentConfig, tokenSet *auth.TokenSet, operation orgPolicyOperation) (*portalapi.OrganizationPolicy, error) {
+	var projectHumanID string
+	if project != nil {
+		projectHumanID = project.Config.ProjectHumanID
+	} else {
+		var err error
+		projectHumanID, err = resolveEnvironmentProjectHumanID(envConfig, tokenSet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	policy, err := resolveOrganizationPolicy(projectHumanID, tokenSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPolicyMinimumCliVersion(policy, version.AppVersion); err != nil {
+		return nil, err
+	}
+
+	if operation == orgPolicyOperationDestroy && slices.Contains(policy.ProtectedEnvironments, envConfig.HumanID) {
+		return nil, clierrors.Newf("Environment '%s' is protected by your organization's policy", envConfig.HumanID).
+			WithDetails("Destructive operations are not allowed in protected environments.").
+			WithSuggestion("Ask an organization admin to remove the protection in the portal, if this is intended")
+	}
+
+	if operation == orgPolicyOperationDeploy {
+		if err := checkPolicyDeployWindows(policy, envConfig.Type, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// resolveEnvironmentProjectHumanID resolves the human ID of the project owning the environment
+// from the portal, for environments that were not resolved via metaplay-project.yaml.
+func resolveEnvironmentProjectHumanID(envConfig *metaproj.ProjectEnvironmentConfig, tokenSet *auth.TokenSet) (string, error) {
+	portalClient := portalapi.NewClient(tokenSet)
+	envInfo, err := portalClient.FetchEnvironmentInfoByHumanID(envConfig.HumanID, envConfig.StackDomain)
+	if err != nil {
+		return "", clierrors.Wrapf(err, "Failed to resolve the project of environment '%s' to check your organization's policy", envConfig.HumanID).
+			WithSuggestion("Check your network connection and try again")
+	}
+
+	orgsAndProjects, err := portalClient.FetchUserOrgsAndProjects()
+	if err != nil {
+		return "", clierrors.Wrapf(err, "Failed to resolve the project of environment '%s' to check your organization's policy", envConfig.HumanID).
+			WithSuggestion("Check your network connection and try again")
+	}
+
+	projectHumanID := findProjectHumanIDByUUID(orgsAndProjects, envInfo.ProjectUID)
+	if projectHumanID == "" {
+		return "", clierrors.Newf("Unable to resolve the project of environment '%s' to check your organization's policy", envConfig.HumanID).
+			WithSuggestion("Run the command in the project directory (with metaplay-project.yaml), or check that you have access to the project in the portal")
+	}
+	return projectHumanID, nil
+}
+
+// findProjectHumanIDByUUID returns the human ID of the project with the UUID, or an empty string
+// if none of the organizations have the project.
+func findProjectHumanIDByUUID(orgsAndProjects []portalapi.OrganizationWithProjects, projectUUID string) string {
+	for _, org := range orgsAndProjects {
+		for _, project := range org.Projects {
+			if project.UUID == projectUUID {
+				return project.HumanID
+			}
+		}
+	}
+	return ""
+}
+
+// resolveOrganizationPolicy returns the policy of the organization owning the project. A recently
+// fetched policy is used from the cache, otherwise the policy is fetched from the portal. If the
+// portal cannot be reached, the previously cached policy is used regardless of its age.
+func resolveOrganizationPolicy(projectHumanID string, tokenSet *auth.TokenSet) (*portalapi.OrganizationPolicy, error) {
+	cachePath, err := getOrgPolicyCachePath(projectHumanID)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := readOrgPolicyCache(cachePath)
+	if err != nil {
+		log.Debug().Msgf("Ignoring unreadable organization policy cache %s: %v", cachePath, err)
+		cached = nil
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < orgPolicyCacheTTL {
+		log.Debug().Msgf("Using cached organization policy (fetched at %s)", cached.FetchedAt.Format(time.RFC3339))
+		return &cached.Policy, nil
+	}
+
+	entry, fetchErr := fetchOrganizationPolicy(projectHumanID, tokenSet)
+	if fetchErr != nil {
+		if cached == nil {
+			return nil, clierrors.Wrap(fetchErr, "Failed to fetch your organization's policy from the portal").
+				WithSuggestion("Check your network connection and try again")
+		}
+		log.Warn().Msgf("Failed to fetch your organization's policy, using the policy cached at %s: %v", cached.FetchedAt.Format(time.RFC3339), fetchErr)
+		return &cached.Policy, nil
+	}
+
+	if err := writeOrgPolicyCache(cachePath, entry); err != nil {
+		log.Debug().Msgf("Failed to write organization policy cache %s: %v", cachePath, err)
+	}
+	return &entry.Policy, nil
+}
+
+// fetchOrganizationPolicy fetches the policy of the organization owning the project from the portal.
+func fetchOrganizationPolicy(projectHumanID string, tokenSet *auth.TokenSet) (*orgPolicyCacheEntry, error) {
+	portalClient := portalapi.NewClient(tokenSet)
+	projectInfo, err := portalClient.FetchProjectInfo(projectHumanID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := portalClient.FetchOrganizationPolicy(projectInfo.OrganizationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orgPolicyCacheEntry{
+		OrganizationUUID: projectInfo.OrganizationUUID,
+		FetchedAt:        time.Now(),
+		Policy:           *policy,
+	}, nil
+}
+
+// getOrgPolicyCachePath returns the path of the organization policy cache file of the project.
+// The cache written by older CLI versions in the config directory is moved to the cache directory.
+func getOrgPolicyCachePath(projectHumanID string) (string, error) {
+	configDir, err := auth.ResolveConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := common.ResolveStateDir(common.StateDirCache)
+	if err != nil {
+		return "", err
+	}
+
+	policiesDir := filepath.Join(cacheDir, "org-policies")
+	if err := common.MigrateLegacyStatePath(filepath.Join(configDir, "org-policies"), policiesDir); err != nil {
+		return "", fmt.Errorf("failed to migrate organization policy cache: %w", err)
+	}
+	return filepath.Join(policiesDir, projectHumanID+".json"), nil
+}
+
+// readOrgPolicyCache reads the cached organization policy. Returns nil if there is no cache.
+func readOrgPolicyCache(path string) (*orgPolicyCacheEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entry orgPolicyCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse organization policy cache: %w", err)
+	}
+	return &entry, nil
+}
+
+// writeOrgPolicyCache writes the organization policy to the cache.
+func writeOrgPolicyCache(path string, entry *orgPolicyCacheEntry) error {
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// checkPolicyMinimumCliVersion checks that the CLI version is not older than the minimum version
+// required by the policy. Development builds are always allowed.
+func checkPolicyMinimumCliVersion(policy *portalapi.OrganizationPolicy, cliVersion string) error {
+	if policy.MinimumCliVersion == "" || cliVersion == "dev" {
+		return nil
+	}
+
+	minVersion, err := goversion.NewVersion(policy.MinimumCliVersion)
+	if err != nil {
+		return clierrors.Newf("Invalid minimum CLI version '%s' in your organization's policy", policy.MinimumCliVersion).
+			WithSuggestion("Ask an organization admin to fix the policy in the portal")
+	}
+	currentVersion, err := goversion.NewVersion(cliVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse CLI version '%s': %w", cliVersion, err)
+	}
+
+	if currentVersion.LessThan(minVersion) {
+		return clierrors.Newf("Your organization's policy requires Metaplay CLI %s or later, you have %s", policy.MinimumCliVersion, cliVersion).
+			WithSuggestion("Update the CLI with 'metaplay update cli'")
+	}
+	return nil
+}
+
+// checkPolicyDeployWindows checks that deploying into an environment of the given type is allowed
+// at the given time. Deploying is allowed at any time if no deploy windows apply to the type.
+func checkPolicyDeployWindows(policy *portalapi.OrganizationPolicy, envType portalapi.EnvironmentType, now time.Time) error {
+	applicable := []portalapi.DeployWindow{}
+	for _, window := range policy.DeployWindows {
+		if len(window.EnvironmentTypes) == 0 || slices.Contains(window.EnvironmentTypes, envType) {
+			applicable = append(applicable, window)
+		}
+	}
+	if len(applicable) == 0 {
+		return nil
+	}
+
+	descriptions := []string{}
+	for _, window := range applicable {
+		isOpen, err := isDeployWindowOpen(window, now)
+		if err != nil {
+			return clierrors.Wrap(err, "Invalid deploy window in your organization's policy").
+				WithSuggestion("Ask an organization admin to fix the policy in the portal")
+		}
+		if isOpen {
+			return nil
+		}
+		descriptions = append(descriptions, describeDeployWindow(window))
+	}
+
+	return clierrors.Newf("Deploying into %s environments is not allowed at this time by your organization's policy", envType).
+		WithDetails(fmt.Sprintf("Allowed deploy windows: %s", strings.Join(descriptions, "; "))).
+		WithSuggestion("Deploy again during an allowed window")
+}
+
+// isDeployWindowOpen reports whether the time is within the deploy window. Windows that end
+// before they start, eg, 'fri 22:00-02:00', wrap past midnight into the next day.
+func isDeployWindowOpen(window portalapi.DeployWindow, now time.Time) (bool, error) {
+	location := time.UTC
+	if window.Timezone != "" {
+		loc, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("invalid timezone '%s': %w", window.Timezone, err)
+		}
+		location = loc
+	}
+
+	start, err := time.Parse("15:04", window.Start)
+	if err != nil {
+		return false, fmt.Errorf("invalid start time '%s', expecting 'HH:MM'", window.Start)
+	}
+	end, err := time.Parse("15:04", window.End)
+	if err != nil {
+		return false, fmt.Errorf("invalid end time '%s', expecting 'HH:MM'", window.End)
+	}
+
+	// A window that doesn't end after it starts wraps past midnight, eg, 22:00-02:00 (and a
+	// window with equal start and end is open for 24 hours). The days refer to the day that the
+	// window starts on, so the hours after midnight belong to the previous day's window.
+	local := now.In(location)
+	windowDay := local
+	minuteOfDay := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+	if startMinute < endMinute {
+		if minuteOfDay < startMinute || minuteOfDay >= endMinute {
+			return false, nil
+		}
+	} else if minuteOfDay < endMinute {
+		windowDay = local.AddDate(0, 0, -1)
+	} else if minuteOfDay < startMinute {
+		return false, nil
+	}
+
+	if len(window.Days) > 0 {
+		weekday := strings.ToLower(windowDay.Weekday().String()[:3])
+		if !slices.ContainsFunc(window.Days, func(day string) bool { return strings.EqualFold(day, weekday) }) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// describeDeployWindow formats the deploy window for humans, eg, 'mon,tue 09:00-17:00 UTC'.
+func describeDeployWindow(window portalapi.DeployWindow) string {
+	days := "every day"
+	if len(window.Days) > 0 {
+		days = strings.Join(window.Days, ",")
+	}
+	timezone := coalesceString(window.Timezone, "UTC")
+	return fmt.Sprintf("%s %s-%s %s", days, window.Start, window.End, timezone)
+}
+
+// checkPolicyChartVersion checks that the game server Helm chart version is not blocked by the policy.
+func checkPolicyChartVersion(policy *portalapi.OrganizationPolicy, chartVersion string) error {
+	if len(policy.BlockedChartVersions) == 0 || chartVersion == "local" {
+		return nil
+	}
+
+	parsedVersion, err := goversion.NewVersion(chartVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse Helm chart version '%s': %w", chartVersion, err)
+	}
+
+	for _, blocked := range policy.BlockedChartVersions {
+		constraints, err := goversion.NewConstraint(blocked)
+		if err != nil {
+			return clierrors.Newf("Invalid blocked chart version '%s' in your organization's policy", blocked).
+				WithSuggestion("Ask an organization admin to fix the policy in the portal")
+		}
+		if constraints.Check(parsedVersion) {
+			return clierrors.Newf("Helm chart version %s is blocked by your organization's policy (matches '%s')", chartVersion, blocked).
+				WithSuggestion("Use a different chart version with --helm-chart-version, or update 'serverChartVersion' in metaplay-project.yaml")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPolicyMinimumCliVersion(t *testing.T) {
+	policy := &portalapi.OrganizationPolicy{MinimumCliVersion: "1.8.0"}
+
+	assert.NoError(t, checkPolicyMinimumCliVersion(policy, "1.8.0"))
+	assert.NoError(t, checkPolicyMinimumCliVersion(policy, "1.10.2"))
+	assert.NoError(t, checkPolicyMinimumCliVersion(policy, "dev"))
+	assert.Error(t, checkPolicyMinimumCliVersion(policy, "1.7.9"))
+	assert.NoError(t, checkPolicyMinimumCliVersion(&portalapi.OrganizationPolicy{}, "0.1.0"))
+	assert.Error(t, checkPolicyMinimumCliVersion(&portalapi.OrganizationPolicy{MinimumCliVersion: "latest"}, "1.8.0"))
+}
+
+func TestCheckPolicyDeployWindows(t *testing.T) {
+	policy := &portalapi.OrganizationPolicy{
+		DeployWindows: []portalapi.DeployWindow{
+			{
+				EnvironmentTypes: []portalapi.EnvironmentType{portalapi.EnvironmentTypeProduction},
+				Days:             []string{"mon", "tue", "wed", "thu"},
+				Start:            "09:00",
+				End:              "16:00",
+				Timezone:         "Europe/Helsinki",
+			},
+		},
+	}
+
+	// Wednesday 10:30 in Helsinki (UTC+3 in summer).
+	inside := time.Date(2025, 6, 4, 7, 30, 0, 0, time.UTC)
+	assert.NoError(t, checkPolicyDeployWindows(policy, portalapi.EnvironmentTypeProduction, inside))
+
+	// Wednesday 16:00 in Helsinki is past the end of the window.
+	afterEnd := time.Date(2025, 6, 4, 13, 0, 0, 0, time.UTC)
+	assert.Error(t, checkPolicyDeployWindows(policy, portalapi.EnvironmentTypeProduction, afterEnd))
+
+	// Friday is not within the window.
+	friday := time.Date(2025, 6, 6, 7, 30, 0, 0, time.UTC)
+	assert.Error(t, checkPolicyDeployWindows(policy, portalapi.EnvironmentTypeProduction, friday))
+
+	// The window does not apply to development environments.
+	assert.NoError(t, checkPolicyDeployWindows(policy, portalapi.EnvironmentTypeDevelopment, friday))
+
+	// Invalid windows are reported.
+	invalid := &portalapi.OrganizationPolicy{DeployWindows: []portalapi.DeployWindow{{Start: "9am", End: "17:00"}}}
+	assert.Error(t, checkPolicyDeployWindows(invalid, portalapi.EnvironmentTypeDevelopment, inside))
+}
+
+func TestIsDeployWindowOpenOvernight(t *testing.T) {
+	// Friday night maintenance window, from 22:00 until 02:00 on Saturday (UTC).
+	window := portalapi.DeployWindow{Days: []string{"fri"}, Start: "22:00", End: "02:00"}
+
+	testCases := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"friday before the start", time.Date(2025, 6, 6, 21, 59, 0, 0, time.UTC), false},
+		{"friday after the start", time.Date(2025, 6, 6, 23, 0, 0, 0, time.UTC), true},
+		{"saturday before the end", time.Date(2025, 6, 7, 1, 30, 0, 0, time.UTC), true},
+		{"saturday at the end", time.Date(2025, 6, 7, 2, 0, 0, 0, time.UTC), false},
+		{"saturday night", time.Date(2025, 6, 7, 23, 0, 0, 0, time.UTC), false},
+		{"friday early hours belong to thursday", time.Date(2025, 6, 6, 1, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			isOpen, err := isDeployWindowOpen(window, tc.now)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, isOpen)
+		})
+	}
+
+	// A window with equal start and end is open all day.
+	isOpen, err := isDeployWindowOpen(portalapi.DeployWindow{Start: "00:00", End: "00:00"}, time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, isOpen)
+}
+
+func TestFindProjectHumanIDByUUID(t *testing.T) {
+	orgsAndProjects := []portalapi.OrganizationWithProjects{
+		{UUID: "org-1", Projects: []portalapi.ProjectInfo{{UUID: "project-1", HumanID: "lovely-wombats"}}},
+		{UUID: "org-2", Projects: []portalapi.ProjectInfo{{UUID: "project-2", HumanID: "gorgeous-bear"}}},
+	}
+
+	assert.Equal(t, "gorgeous-bear", findProjectHumanIDByUUID(orgsAndProjects, "project-2"))
+	assert.Equal(t, "", findProjectHumanIDByUUID(orgsAndProjects, "project-3"))
+	assert.Equal(t, "", findProjectHumanIDByUUID(orgsAndProjects, ""))
+}
+
+func TestCheckPolicyChartVersion(t *testing.T) {
+	policy := &portalapi.OrganizationPolicy{BlockedChartVersions: []string{"0.8.1", "< 0.7.5"}}
+
+	assert.NoError(t, checkPolicyChartVersion(policy, "0.8.2"))
+	assert.NoError(t, checkPolicyChartVersion(policy, "local"))
+	assert.Error(t, checkPolicyChartVersion(policy, "0.8.1"))
+	assert.Error(t, checkPolicyChartVersion(policy, "0.7.0"))
+}
+
+func TestOrgPolicyCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "org-policies", "gorgeous-bear.json")
+
+	// Missing cache is not an error.
+	entry, err := readOrgPolicyCache(path)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	written := &orgPolicyCacheEntry{
+		OrganizationUUID: "org-uuid",
+		FetchedAt:        time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC),
+		Policy: portalapi.OrganizationPolicy{
+			ProtectedEnvironments: []string{"lovely-wombats-build-nimbly"},
+			RequireImageSigning:   true,
+		},
+	}
+	require.NoError(t, writeOrgPolicyCache(path, written))
+
+	entry, err = readOrgPolicyCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, entry)
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// planOutput is the machine-readable form of a scanned filesetwriter.Plan, output by
+// the scaffolding commands with --plan-json.
+type planOutput struct {
+	FilesToWrite   int              `json:"filesToWrite"`
+	Files          []planFileOutput `json:"files"`
+	ZipExtractions []planZipOutput  `json:"zipExtractions"`
+}
+
+// planFileOutput describes the planned action for a single file.
+type planFileOutput struct {
+	Path      string `json:"path"`                // Target path of the file.
+	WritePath string `json:"writePath,omitempty"` // Path actually written (differs from path for renames, empty if not written).
+	Action    string `json:"action"`              // One of: create, overwrite, skip, rename, update, unchanged.
+	Message   string `json:"message,omitempty"`   // Explanation for updates.
+	ReadOnly  bool   `json:"readOnly,omitempty"`  // Target is read-only and must be made writable first.
+	Diff      string `json:"diff,omitempty"`      // Unified diff against the existing file (empty for binary files).
+}
+
+// planZipOutput describes a zip archive extraction in the plan.
+type planZipOutput struct {
+	ZipPath  string `json:"zipPath"`
+	Prefix   string `json:"prefix"`
+	DestDir  string `json:"destDir"`
+	NumFiles int    `json:"numFiles"`
+}
+
+// buildPlanOutput converts a scanned plan into its machine-readable form. Files that are
+// written get a unified diff against the existing file at the target path (or against an
+// empty file for new files).
+func buildPlanOutput(plan *filesetwriter.Plan) planOutput {
+	output := planOutput{
+		FilesToWrite:   plan.FilesToWrite(),
+		Files:          []planFileOutput{},
+		ZipExtractions: []planZipOutput{},
+	}
+
+	for _, result := range plan.Results() {
+		file := planFileOutput{
+			Path:      filepath.ToSlash(result.File.Path),
+			WritePath: filepath.ToSlash(result.WritePath),
+			Action:    result.Action.String(),
+			Message:   result.File.Message,
+			ReadOnly:  result.ReadOnly,
+		}
+
+		if result.Action != filesetwriter.ActionSkip && result.Action != filesetwriter.ActionUnchanged {
+			var existingContent []byte
+			if result.Exists {
+				content, err := os.ReadFile(result.File.Path)
+				if err != nil {
+					log.Debug().Msgf("Failed to read %s for diff: %v", result.File.Path, err)
+				}
+				existingContent = content
+			}
+			file.Diff = generateUnifiedDiff(file.Path, existingContent, result.File.Content, !result.Exists, false)
+		}
+
+		output.Files = append(output.Files, file)
+	}
+
+	for _, extraction := range plan.ZipExtractions() {
+		output.ZipExtractions = append(output.ZipExtractions, planZipOutput{
+			ZipPath:  extraction.ZipPath,
+			Prefix:   extraction.Prefix,
+			DestDir:  filepath.ToSlash(extraction.DestDir),
+			NumFiles: extraction.FileCount(),
+		})
+	}
+
+	return output
+}
+
+// printPlanJSON prints the scanned plan as JSON.
+func printPlanJSON(plan *filesetwriter.Plan) error {
+	planJSON, err := json.MarshalIndent(buildPlanOutput(plan), "", "  ")
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to marshal file plan as JSON")
+	}
+	log.Info().Msg(string(planJSON))
+	return nil
+}
+
+// showPlanOnly outputs the scanned plan without executing it, either as JSON or as the
+// human-readable preview. Used by the scaffolding commands with --plan-json or --plan-only.
+func showPlanOnly(plan *filesetwriter.Plan, asJSON bool, collapseDirectories bool) error {
+	if asJSON {
+		return printPlanJSON(plan)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg("Files to be modified:")
+	plan.Preview(collapseDirectories)
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderMuted("Plan-only mode: no files were written"))
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPlanOutput(t *testing.T) {
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, "existing.yaml")
+	unchangedPath := filepath.Join(dir, "unchanged.yaml")
+	newPath := filepath.Join(dir, "new.yaml")
+	require.NoError(t, os.WriteFile(existingPath, []byte("a: 1\n"), 0644))
+	require.NoError(t, os.WriteFile(unchangedPath, []byte("b: 2\n"), 0644))
+
+	plan := filesetwriter.NewPlan(false)
+	plan.AddUpdate(existingPath, []byte("a: 2\n"), 0644, "bump a")
+	plan.Add(unchangedPath, []byte("b: 2\n"), 0644)
+	plan.Add(newPath, []byte("c: 3\n"), 0644)
+	require.NoError(t, plan.Scan())
+
+	output := buildPlanOutput(plan)
+	assert.Equal(t, 2, output.FilesToWrite)
+	assert.Empty(t, output.ZipExtractions)
+	require.Len(t, output.Files, 3)
+
+	updated := output.Files[0]
+	assert.Equal(t, "update", updated.Action)
+	assert.Equal(t, "bump a", updated.Message)
+	assert.Contains(t, updated.Diff, "-a: 1")
+	assert.Contains(t, updated.Diff, "+a: 2")
+
+	unchanged := output.Files[1]
+	assert.Equal(t, "unchanged", unchanged.Action)
+	assert.Empty(t, unchanged.WritePath)
+	assert.Empty(t, unchanged.Diff)
+
+	created := output.Files[2]
+	assert.Equal(t, "create", created.Action)
+	assert.Equal(t, filepath.ToSlash(newPath), created.WritePath)
+	assert.Contains(t, created.Diff, "new file mode")
+	assert.Contains(t, created.Diff, "+c: 3")
+}
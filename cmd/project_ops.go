@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/metaplay/cli/internal/tui"
@@ -143,6 +144,41 @@ func validateUnityProjectPath(rootPath string, unityProjectPath string) error {
 	return nil
 }
 
+// Find an Unreal Engine project within the specified root path. Returns the path relative to rootPath.
+func findUnrealProjectPath(rootPath string) (string, error) {
+	return findSubDirectory("Unreal Engine project", rootPath, func(rootPath, relPath string) (bool, error) {
+		// If it's a valid Unreal project directory, return it.
+		err := validateUnrealProjectPath(rootPath, relPath)
+		if err == nil {
+			return true, nil
+		}
+
+		return false, nil
+	})
+}
+
+// Check that the provided Unreal Engine project directory is valid (relative to the project root),
+// ie, it contains exactly one .uproject file.
+func validateUnrealProjectPath(rootPath string, unrealProjectPath string) error {
+	if filepath.IsAbs(unrealProjectPath) {
+		return fmt.Errorf("unreal-project path must be a relative path: %s", unrealProjectPath)
+	}
+	if strings.Contains(unrealProjectPath, "..") {
+		return fmt.Errorf("unreal-project path must not contain '..': %s", unrealProjectPath)
+	}
+
+	unrealProjectPathAbs := filepath.Join(rootPath, unrealProjectPath)
+	if !isDirectory(unrealProjectPathAbs) {
+		return fmt.Errorf("unreal project path does not exist or is not a directory: %s", unrealProjectPathAbs)
+	}
+
+	if _, err := metaproj.FindUnrealProjectFile(unrealProjectPathAbs); err != nil {
+		return fmt.Errorf("%s does not appear to be an Unreal Engine project: %w", unrealProjectPathAbs, err)
+	}
+
+	return nil
+}
+
 // applyReplacements replaces placeholder tokens of the form {{{KEY}}} in the input string
 // using the provided replacements map. It logs discovered placeholders and whether a
 // replacement was provided. Returns the updated string and an error if unreplaced placeholders remain.
@@ -482,16 +518,10 @@ func processTemplateFiles(plan *filesetwriter.Plan, template installerTemplatePr
 // buildTemplateReplacements constructs the replacement map from a project config and
 // extra replacements. Used by both collectFromTemplate and collectFromTemplateInZip.
 func buildTemplateReplacements(config *metaproj.ProjectConfig, extraReplacements map[string]string) map[string]string {
-	unityProjectDir := config.UnityProjectDir
-	if unityProjectDir == "." {
-		unityProjectDir = ""
-	} else if unityProjectDir != "" && !strings.HasSuffix(unityProjectDir, "/") {
-		unityProjectDir = unityProjectDir + "/"
-	}
-
 	templateReplacements := map[string]string{
 		"RELATIVE_PATH_TO_SDK": config.SdkRootDir,
-		"UNITY_PROJECT_DIR":    unityProjectDir,
+		"UNITY_PROJECT_DIR":    toTemplateDirPrefix(config.UnityProjectDir),
+		"UNREAL_PROJECT_DIR":   toTemplateDirPrefix(config.UnrealProjectDir),
 		"PROJECT_HUMAN_ID":     config.ProjectHumanID,
 		"PROJECT_NAME":         config.ProjectHumanID, // Removed in R34
 	}
@@ -506,6 +536,17 @@ func buildTemplateReplacements(config *metaproj.ProjectConfig, extraReplacements
 	return templateReplacements
 }
 
+// toTemplateDirPrefix converts a relative directory to a prefix for the paths in the installer
+// templates: '.' and ” become ” and other directories get a trailing slash, eg, 'Unity/'.
+func toTemplateDirPrefix(dir string) string {
+	if dir == "." {
+		return ""
+	} else if dir != "" && !strings.HasSuffix(dir, "/") {
+		return dir + "/"
+	}
+	return dir
+}
+
 // collectFromTemplate reads the installer template from the SDK on disk and adds all
 // resolved files to the given plan without writing anything to disk.
 // dstPath - Root directory for installed files, relative to metaplay project dir.
@@ -630,3 +671,66 @@ func computeManifestUpdate(project *metaproj.MetaplayProject) (string, []byte, e
 	log.Debug().Msgf("Successfully computed manifest.json update: \"%s\" from \"%s\"", packageName, clientRef)
 	return manifestPath, updatedManifest, nil
 }
+
+// computeUnrealProjectUpdate reads the Unreal project's .uproject file, adds the MetaplaySDK's
+// Unreal plugins directory to its AdditionalPluginDirectories and enables the Metaplay plugin,
+// and returns the updated content without writing.
+func computeUnrealProjectUpdate(project *metaproj.MetaplayProject) (string, []byte, error) {
+	unrealProjectDir := project.GetUnrealProjectDir()
+	uprojectFileName, err := metaproj.FindUnrealProjectFile(unrealProjectDir)
+	if err != nil {
+		return "", nil, err
+	}
+	uprojectPath := filepath.Join(unrealProjectDir, uprojectFileName)
+
+	// Convert the SDK's plugins directory to a relative path from the .uproject file.
+	relativePath, err := filepath.Rel(unrealProjectDir, filepath.Join(project.GetSdkRootDir(), metaproj.UnrealPluginsSdkDir))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to compute relative path: %w", err)
+	}
+	pluginsDir := filepath.ToSlash(relativePath)
+	log.Debug().Msgf("Relative path to MetaplaySDK Unreal plugins (from .uproject): %s", pluginsDir)
+
+	// Read the .uproject file.
+	uprojectData, err := os.ReadFile(uprojectPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", uprojectFileName, err)
+	}
+
+	// Check what is already configured. Only the relevant fields are parsed; the file is
+	// modified with sjson to preserve the rest of its contents and formatting.
+	var uproject struct {
+		Plugins []struct {
+			Name string
+		}
+		AdditionalPluginDirectories []string
+	}
+	if err := json.Unmarshal(uprojectData, &uproject); err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", uprojectFileName, err)
+	}
+
+	// Add the SDK plugins directory.
+	updatedUproject := uprojectData
+	if !slices.Contains(uproject.AdditionalPluginDirectories, pluginsDir) {
+		updatedUproject, err = sjson.SetBytes(updatedUproject, "AdditionalPluginDirectories.-1", pluginsDir)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to update %s: %w", uprojectFileName, err)
+		}
+	}
+
+	// Enable the Metaplay plugin (or update an existing entry to enabled).
+	pluginNdx := slices.IndexFunc(uproject.Plugins, func(plugin struct{ Name string }) bool {
+		return plugin.Name == metaproj.UnrealPluginName
+	})
+	if pluginNdx == -1 {
+		updatedUproject, err = sjson.SetBytes(updatedUproject, "Plugins.-1", map[string]any{"Name": metaproj.UnrealPluginName, "Enabled": true})
+	} else {
+		updatedUproject, err = sjson.SetBytes(updatedUproject, fmt.Sprintf("Plugins.%d.Enabled", pluginNdx), true)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to update %s: %w", uprojectFileName, err)
+	}
+
+	log.Debug().Msgf("Successfully computed %s update: plugin \"%s\" from \"%s\"", uprojectFileName, metaproj.UnrealPluginName, pluginsDir)
+	return uprojectPath, updatedUproject, nil
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUnrealProjectPath(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, "Client", "Content"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "Client", "MyGame.uproject"), []byte(`{}`), 0644))
+
+	relPath, err := findUnrealProjectPath(rootDir)
+	require.NoError(t, err)
+	assert.Equal(t, "Client", relPath)
+
+	assert.NoError(t, validateUnrealProjectPath(rootDir, "Client"))
+	assert.Error(t, validateUnrealProjectPath(rootDir, "Client/Content"))
+	assert.Error(t, validateUnrealProjectPath(rootDir, "../Client"))
+	assert.Error(t, validateUnrealProjectPath(rootDir, filepath.Join(rootDir, "Client")))
+}
+
+func TestComputeUnrealProjectUpdate(t *testing.T) {
+	rootDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(rootDir, "Client"), 0755))
+	uprojectPath := filepath.Join(rootDir, "Client", "MyGame.uproject")
+
+	project := &metaproj.MetaplayProject{
+		RelativeDir: rootDir,
+		Config: metaproj.ProjectConfig{
+			SdkRootDir:       "MetaplaySDK",
+			UnrealProjectDir: "Client",
+		},
+	}
+
+	// Plugin and plugin directory are added to a .uproject without any plugins.
+	require.NoError(t, os.WriteFile(uprojectPath, []byte(`{"FileVersion": 3, "EngineAssociation": "5.4"}`), 0644))
+	path, content, err := computeUnrealProjectUpdate(project)
+	require.NoError(t, err)
+	assert.Equal(t, uprojectPath, path)
+	uproject := parseTestUproject(t, content)
+	assert.Equal(t, 3, uproject.FileVersion)
+	assert.Equal(t, []string{"../MetaplaySDK/ClientUnreal"}, uproject.AdditionalPluginDirectories)
+	assert.Equal(t, []testUprojectPlugin{{Name: "Metaplay", Enabled: true}}, uproject.Plugins)
+
+	// Existing entries are kept and a disabled Metaplay plugin is enabled, without duplicates.
+	require.NoError(t, os.WriteFile(uprojectPath, []byte(`{
+	"Plugins": [
+		{"Name": "OnlineSubsystem", "Enabled": true},
+		{"Name": "Metaplay", "Enabled": false}
+	],
+	"AdditionalPluginDirectories": ["../MetaplaySDK/ClientUnreal"]
+}`), 0644))
+	_, content, err = computeUnrealProjectUpdate(project)
+	require.NoError(t, err)
+	uproject = parseTestUproject(t, content)
+	assert.Equal(t, []string{"../MetaplaySDK/ClientUnreal"}, uproject.AdditionalPluginDirectories)
+	assert.Equal(t, []testUprojectPlugin{{Name: "OnlineSubsystem", Enabled: true}, {Name: "Metaplay", Enabled: true}}, uproject.Plugins)
+}
+
+type testUprojectPlugin struct {
+	Name    string
+	Enabled bool
+}
+
+type testUproject struct {
+	FileVersion                 int
+	Plugins                     []testUprojectPlugin
+	AdditionalPluginDirectories []string
+}
+
+func parseTestUproject(t *testing.T, content []byte) testUproject {
+	var uproject testUproject
+	require.NoError(t, json.Unmarshal(content, &uproject))
+	return uproject
+}
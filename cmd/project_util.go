@@ -88,6 +88,23 @@ func findProjectDirectory() (string, error) {
 
 		// Check if we've reached the root directory
 		if parentDir == absCurrentDir {
+			// Use the project pinned with 'metaplay use', if any.
+			if activeCliContext != nil && activeCliContext.ProjectDir != "" {
+				log.Debug().Msgf("Using project directory '%s' from context '%s'", activeCliContext.ProjectDir, activeCliContext.Name)
+				return activeCliContext.ProjectDir, nil
+			}
+
+			// Use the default project from the user's settings, if any.
+			if userConfig.ProjectPath != "" {
+				configFilePath := filepath.Join(userConfig.ProjectPath, metaproj.ConfigFileName)
+				if _, err := os.Stat(configFilePath); err != nil {
+					return "", clierrors.Newf("No metaplay-project.yaml found in the default project directory '%s'", userConfig.ProjectPath).
+						WithSuggestion("Update the default project with 'metaplay config set projectPath <path>', or remove it with 'metaplay config unset projectPath'")
+				}
+				log.Debug().Msgf("Using the default project directory '%s' from the CLI settings", userConfig.ProjectPath)
+				return userConfig.ProjectPath, nil
+			}
+
 			// We've reached the root and didn't find the config file
 			return "", clierrors.New("Cannot find metaplay-project.yaml").
 				WithSuggestion("Make sure you are in the right directory, or use --project=<path> to specify the project directory")
@@ -181,6 +198,14 @@ func resolveEnvironment(ctx context.Context, project *metaproj.MetaplayProject,
 	var envConfig *metaproj.ProjectEnvironmentConfig
 	var err error
 
+	// Default to the environment pinned with 'metaplay use', if any.
+	if environment == "" {
+		environment = getContextEnvironment()
+		if environment != "" {
+			logContextEnvironment()
+		}
+	}
+
 	// If a metaplay-project.yaml can be located, resolve the environment
 	// from the project config.
 	if project != nil {
@@ -380,3 +405,42 @@ func chooseOrgAndProject(portalClient *portalapi.Client, projectID string) (*por
 		return tui.ChooseOrgAndProject(orgsAndProjects)
 	}
 }
+
+// checkEnvironmentProjectOwnership verifies that the target environment belongs to the project in
+// metaplay-project.yaml, according to the portal. Called before mutating operations so that running
+// a command in the wrong project directory fails early, instead of deploying the wrong game into the
+// environment. Only Metaplay-hosted environments resolved via metaplay-project.yaml are checked.
+// Returns the portal's information about the environment, or nil if the check was not applicable.
+func checkEnvironmentProjectOwnership(project *metaproj.MetaplayProject, envConfig *metaproj.ProjectEnvironmentConfig, tokenSet *auth.TokenSet) (*portalapi.EnvironmentInfo, error) {
+	// Environments resolved directly from the portal have no local project to compare against.
+	if project == nil || envConfig.HostingType != portalapi.HostingTypeMetaplayHosted {
+		return nil, nil
+	}
+
+	portalClient := portalapi.NewClient(tokenSet)
+	envInfo, err := portalClient.FetchEnvironmentInfoByHumanID(envConfig.HumanID, envConfig.StackDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	projectInfo, err := portalClient.FetchProjectInfo(project.Config.ProjectHumanID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateEnvironmentProject(project.Config.ProjectHumanID, projectInfo, envInfo); err != nil {
+		return nil, err
+	}
+	return envInfo, nil
+}
+
+// validateEnvironmentProject checks that the portal environment is owned by the portal project.
+func validateEnvironmentProject(projectHumanID string, projectInfo *portalapi.ProjectInfo, envInfo *portalapi.EnvironmentInfo) error {
+	if envInfo.ProjectUID == projectInfo.UUID {
+		return nil
+	}
+
+	return clierrors.Newf("Environment '%s' does not belong to project '%s'", envInfo.HumanID, projectHumanID).
+		WithDetails(fmt.Sprintf("The portal reports the environment is owned by project %s, but metaplay-project.yaml has project '%s' (%s).", envInfo.ProjectUID, projectHumanID, projectInfo.UUID)).
+		WithSuggestion("Check that you are running the command in the correct project directory, or use --project to specify the project")
+}
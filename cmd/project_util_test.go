@@ -0,0 +1,25 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnvironmentProject(t *testing.T) {
+	projectInfo := &portalapi.ProjectInfo{UUID: "11111111-aaaa", HumanID: "lovely-wombats"}
+
+	ownEnv := &portalapi.EnvironmentInfo{HumanID: "lovely-wombats-build-nimbly", ProjectUID: "11111111-aaaa"}
+	assert.NoError(t, validateEnvironmentProject("lovely-wombats", projectInfo, ownEnv))
+
+	otherEnv := &portalapi.EnvironmentInfo{HumanID: "gorgeous-bear-build-quick", ProjectUID: "22222222-bbbb"}
+	err := validateEnvironmentProject("lovely-wombats", projectInfo, otherEnv)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Environment 'gorgeous-bear-build-quick' does not belong to project 'lovely-wombats'")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"reflect"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/mattn/go-isatty"
@@ -20,6 +21,7 @@ import (
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
 	"github.com/metaplay/cli/internal/version"
+	"github.com/metaplay/cli/pkg/auth"
 	"github.com/metaplay/cli/pkg/common"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/rs/zerolog"
@@ -34,6 +36,8 @@ var flagProjectConfigPath string // Path to Metaplay project (--project or -p).
 var flagVerbose bool             // Verbose logging with (--verbose or -v).
 var flagColorMode string         // Color usage mode for output (yes, no, auto).
 var skipAppVersionCheck bool     // Skip check for a new version of the CLI (--skip-version-check)
+var flagRecordAnswers string     // File to record the answers to interactive questions into (--record-answers).
+var flagAnswers string           // File to replay the answers to interactive questions from (--answers).
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -58,8 +62,11 @@ var rootCmd = &cobra.Command{
 		// Determine if colors can be used
 		hasTerminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 
+		// Load the per-user settings ('metaplay config'). Errors are reported once the logger is up.
+		userConfigErr := loadUserConfig()
+
 		// Determine whether to use colors.
-		colorMode := coalesceString(os.Getenv("METAPLAYCLI_COLOR"), flagColorMode)
+		colorMode := resolveColorMode(cmd)
 		var useColors bool
 		if isTruthy(colorMode) {
 			useColors = true
@@ -67,7 +74,7 @@ var rootCmd = &cobra.Command{
 			useColors = false
 		} else {
 			if colorMode != "auto" {
-				fmt.Fprintf(os.Stderr, "ERROR: Invalid color mode (--color or METAPLAYCLI_COLOR): %s. Allowed values are yes/no/auto.\n", flagColorMode)
+				fmt.Fprintf(os.Stderr, "ERROR: Invalid color mode (--color or METAPLAYCLI_COLOR): %s. Allowed values are yes/no/auto.\n", colorMode)
 				os.Exit(2)
 			}
 			useColors = hasTerminal
@@ -79,6 +86,20 @@ var rootCmd = &cobra.Command{
 		// Initialize zerolog
 		initLogger(useColors, isVerbose)
 
+		// Apply the per-user settings, or warn if they could not be loaded.
+		if userConfigErr != nil {
+			stderrLogger.Warn().Msgf("Ignoring the CLI settings: %v", userConfigErr)
+		}
+		applyUserConfig(cmd)
+
+		// Use the credential profile given with --profile (only available for the auth commands).
+		if flagAuthProfile != "" {
+			if err := auth.SetProfileOverride(flagAuthProfile); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: Invalid --profile: %v\n", err)
+				os.Exit(2)
+			}
+		}
+
 		// Check for common CI environment variables
 		isCI := envutil.IsCI()
 
@@ -101,6 +122,25 @@ var rootCmd = &cobra.Command{
 
 		tui.SetInteractiveMode(isInteractive)
 
+		// Record or replay the answers to the interactive questions, if requested.
+		if flagRecordAnswers != "" && flagAnswers != "" {
+			fmt.Fprintf(os.Stderr, "ERROR: Only one of --record-answers and --answers can be specified\n")
+			os.Exit(2)
+		}
+		if flagRecordAnswers != "" {
+			if err := tui.StartRecordingAnswers(flagRecordAnswers); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: Failed to create answers file (--record-answers): %v\n", err)
+				os.Exit(2)
+			}
+		}
+		if flagAnswers != "" {
+			if err := tui.ReplayAnswers(flagAnswers); err != nil {
+				fmt.Fprintf(os.Stderr, "ERROR: Invalid answers file (--answers): %v\n", err)
+				os.Exit(2)
+			}
+			modeStr += fmt.Sprintf(", answers from %s", flagAnswers)
+		}
+
 		// Silence the boilerplate for commands where it makes no sense.
 		parentCmd := cmd.Parent()
 		isCompletion := parentCmd != nil && parentCmd.Name() == "completion"
@@ -118,11 +158,18 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Check for new CLI version available.
-		isUpdateCliCmd := parentCmd != nil && parentCmd.Name() == "update" && cmd.Use == "cli"
+		isUpdateCliCmd := parentCmd != nil && parentCmd.Name() == "update" && cmd.Name() == "cli"
 		if !skipAppVersionCheck && !isUpdateCliCmd {
 			version.CheckVersion(cmd.Context(), &stderrLogger)
 		}
 	},
+	Run: func(cmd *cobra.Command, args []string) {
+		// On first run, offer the guided setup instead of the help.
+		if shouldOfferOnboarding() && offerOnboarding(cmd, args) {
+			return
+		}
+		_ = cmd.Help()
+	},
 }
 
 // ExecuteContext adds all child commands to the root command and sets flags appropriately.
@@ -154,6 +201,8 @@ func init() {
 	flags.StringVarP(&flagProjectConfigPath, "project", "p", "", "Path to the to project directory (where metaplay-project.yaml is located)")
 	flags.BoolVar(&skipAppVersionCheck, "skip-version-check", false, "Skip the check for a new CLI version being available")
 	flags.StringVar(&flagColorMode, "color", "auto", "Should the output be colored (yes/no/auto)? [env: METAPLAYCLI_COLOR]")
+	flags.StringVar(&flagRecordAnswers, "record-answers", "", "Record the answers to the interactive questions into a file, eg, 'answers.yaml'")
+	flags.StringVar(&flagAnswers, "answers", "", "Answer the interactive questions from a file recorded with --record-answers, also in non-interactive mode")
 
 	// Add command groups to root.
 	coreGroup := &cobra.Group{
@@ -182,22 +231,37 @@ func init() {
 	testCmd.GroupID = "core"
 
 	// Manage project:
+	chartsCmd.GroupID = "project"
+	ciCmd.GroupID = "project"
 	initCmd.GroupID = "project"
+	onboardCmd.GroupID = "project"
 	updateCmd.GroupID = "project"
+	validateCmd.GroupID = "project"
 
 	// Manage resources:
 	databaseCmd.GroupID = "manage"
+	envCmd.GroupID = "manage"
 	getCmd.GroupID = "manage"
 	imageCmd.GroupID = "manage"
+	machineUsersCmd.GroupID = "manage"
 	secretsCmd.GroupID = "manage"
 	removeCmd.GroupID = "manage"
 
 	// Other:
+	anonymizeCmd.GroupID = "other"
 	authCmd.GroupID = "other"
+	configCmd.GroupID = "other"
+	stateCmd.GroupID = "other"
+	statsCmd.GroupID = "other"
+	statusCmd.GroupID = "other"
 	versionCmd.GroupID = "other"
 	rootCmd.SetHelpCommandGroupID("other")
 	rootCmd.SetCompletionCommandGroupID("other")
 
+	// Create the completion command eagerly (instead of on execute) so it can be extended.
+	rootCmd.InitDefaultCompletionCmd()
+	initCompletionDocsCmd(rootCmd)
+
 	// Initialize colored help templates
 	initColoredHelpTemplates(rootCmd)
 }
@@ -329,8 +393,16 @@ func getUsePositionalArgs(opts CommandOptions) (UsePositionalArgs, bool) {
 // CommandOptions.
 func runCommand(opts CommandOptions) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		// Load the context pinned with 'metaplay use', if any.
+		initActiveCliContext(cmd)
+
 		posArgs, hasPosArgs := getUsePositionalArgs(opts)
 		if hasPosArgs {
+			argsWithContext := insertContextEnvironment(posArgs.Arguments(), args, getContextEnvironment())
+			if len(argsWithContext) != len(args) {
+				logContextEnvironment()
+			}
+			args = argsWithContext
 			err := posArgs.Arguments().ParseCommandLine(args)
 			if err != nil {
 				// Add usage suggestion to the error
@@ -362,7 +434,11 @@ func runCommand(opts CommandOptions) func(cmd *cobra.Command, args []string) {
 		}
 
 		// Run the command.
+		startTime := time.Now()
 		err = opts.Run(cmd)
+		elapsed := time.Since(startTime)
+		stderrLogger.Debug().Msgf("Command '%s' finished in %s", cmd.CommandPath(), elapsed.Round(time.Millisecond))
+		recordCommandUsage(cmd, elapsed, err)
 		if err != nil {
 			if wasInterrupted(cmd, err) {
 				exitInterrupted()
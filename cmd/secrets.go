@@ -12,6 +12,7 @@ import (
 var secretsCmd = &cobra.Command{
 	Use:   "secrets",
 	Short: "Manage Kubernetes secrets of an environment",
+	Long:  "Commands for managing the game server's runtime secrets, eg, API keys for third-party services, as Kubernetes secrets in the environment's namespace.",
 }
 
 func init() {
@@ -88,54 +88,65 @@ func init() {
 }
 
 func (o *secretsCreateOpts) Prepare(cmd *cobra.Command, args []string) error {
+	payload, err := parseSecretPayloadFlags(o.flagLiteralValues, o.flagFileValues)
+	if err != nil {
+		return err
+	}
+	o.payloadKeyValuePairs = payload
+	return nil
+}
+
+// parseSecretPayloadFlags resolves the secret entries from the --from-literal ('key=value') and
+// --from-file ('key=filepath') flags. All the keys must be unique.
+func parseSecretPayloadFlags(literalValues, fileValues []string) (map[string][]byte, error) {
 	// Initialize key-value map.
-	o.payloadKeyValuePairs = map[string][]byte{}
+	payload := map[string][]byte{}
 
 	// Resolve literal payload key-value pairs.
-	for _, pair := range o.flagLiteralValues {
+	for _, pair := range literalValues {
 		// Split the literal pair into key and value
 		key, value, ok := strings.Cut(pair, "=")
 		if !ok {
-			return clierrors.NewUsageErrorf("Invalid --from-literal format: '%s'", pair).
+			return nil, clierrors.NewUsageErrorf("Invalid --from-literal format: '%s'", pair).
 				WithSuggestion("Expected 'key=value'")
 		}
 
 		// Check for duplicate keys
-		if _, exists := o.payloadKeyValuePairs[key]; exists {
-			return clierrors.NewUsageErrorf("Duplicate key detected: '%s'", key).
+		if _, exists := payload[key]; exists {
+			return nil, clierrors.NewUsageErrorf("Duplicate key detected: '%s'", key).
 				WithSuggestion("All keys must be unique")
 		}
 
 		// Insert into the map
-		o.payloadKeyValuePairs[key] = []byte(value)
+		payload[key] = []byte(value)
 	}
 
 	// Resolve file entries.
-	for _, pair := range o.flagFileValues {
+	for _, pair := range fileValues {
 		// Split the literal pair into key and value
 		key, filePath, ok := strings.Cut(pair, "=")
 		if !ok {
-			return clierrors.NewUsageErrorf("Invalid --from-file format: '%s'", pair).
+			return nil, clierrors.NewUsageErrorf("Invalid --from-file format: '%s'", pair).
 				WithSuggestion("Expected 'key=filepath'")
 		}
 
 		// Check for duplicate keys
-		if _, exists := o.payloadKeyValuePairs[key]; exists {
-			return clierrors.NewUsageErrorf("Duplicate key detected: '%s'", key).
+		if _, exists := payload[key]; exists {
+			return nil, clierrors.NewUsageErrorf("Duplicate key detected: '%s'", key).
 				WithSuggestion("All keys must be unique")
 		}
 
 		// Read the file content
 		fileContent, err := os.ReadFile(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to read secret from file '%s': %v", filePath, err)
+			return nil, fmt.Errorf("failed to read secret from file '%s': %v", filePath, err)
 		}
 
 		// Insert into the map
-		o.payloadKeyValuePairs[key] = fileContent
+		payload[key] = fileContent
 	}
 
-	return nil
+	return payload, nil
 }
 
 func (o *secretsCreateOpts) Run(cmd *cobra.Command) error {
@@ -151,6 +162,16 @@ func (o *secretsCreateOpts) Run(cmd *cobra.Command) error {
 		return err
 	}
 
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
 	// Create TargetEnvironment.
 	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
 
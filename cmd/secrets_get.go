@@ -0,0 +1,121 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type secretsGetOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	argSecretName  string
+	argKey         string
+}
+
+func init() {
+	o := secretsGetOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argSecretName, "NAME", "Name of the secret, e.g., 'user-some-secret'.")
+	args.AddStringArgumentOpt(&o.argKey, "KEY", "Key of the entry to get, can be omitted if the secret has only one entry.")
+
+	cmd := &cobra.Command{
+		Use:   "get ENVIRONMENT NAME [KEY] [flags]",
+		Short: "Print the value of a user secret entry in the target environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Print the raw value of a single entry of a user secret, eg, for use in scripts.
+
+			The value is written to stdout as-is, without a trailing newline, so that binary
+			values and files can be read back exactly.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay secrets set ENVIRONMENT NAME ...' to set the entries of a user secret.
+			- 'metaplay secrets show ENVIRONMENT NAME ...' to show all the entries of a user secret.
+			- 'metaplay secrets list ENVIRONMENT ...' to list all user secrets.
+		`),
+		Example: renderExample(`
+			# Print the value of the entry 'apikey' in secret 'user-analytics'.
+			metaplay secrets get nimbly user-analytics apikey
+
+			# Write the value of the entry 'credentials' into a file.
+			metaplay secrets get nimbly user-analytics credentials > credentials.json
+		`),
+	}
+
+	secretsCmd.AddCommand(cmd)
+}
+
+func (o *secretsGetOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *secretsGetOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Get the secret.
+	secret, err := targetEnv.GetSecret(cmd.Context(), o.argSecretName)
+	if err != nil {
+		return err
+	}
+
+	value, err := getSecretEntryValue(secret, o.argKey)
+	if err != nil {
+		return err
+	}
+
+	// Write the raw value into stdout.
+	if _, err := os.Stdout.Write(value); err != nil {
+		return fmt.Errorf("failed to write secret value: %w", err)
+	}
+	return nil
+}
+
+// getSecretEntryValue returns the value of the secret's entry with the given key. If the key is
+// empty, the secret must have exactly one entry.
+func getSecretEntryValue(secret *corev1.Secret, key string) ([]byte, error) {
+	keys := slices.Sorted(maps.Keys(secret.Data))
+	if key == "" {
+		if len(keys) != 1 {
+			return nil, clierrors.NewUsageErrorf("Secret '%s' has %d entries, specify the key to get", secret.Name, len(keys)).
+				WithDetails(fmt.Sprintf("Available keys: %s", strings.Join(keys, ", ")))
+		}
+		key = keys[0]
+	}
+
+	value, found := secret.Data[key]
+	if !found {
+		return nil, clierrors.Newf("Secret '%s' has no entry '%s'", secret.Name, key).
+			WithDetails(fmt.Sprintf("Available keys: %s", strings.Join(keys, ", ")))
+	}
+	return value, nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSecretEntryValue(t *testing.T) {
+	single := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "user-single"}, Data: map[string][]byte{"apikey": []byte("abc123")}}
+	multi := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "user-multi"}, Data: map[string][]byte{"username": []byte("foo"), "password": []byte("bar")}}
+
+	// The only entry can be got without a key.
+	value, err := getSecretEntryValue(single, "")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", string(value))
+
+	value, err = getSecretEntryValue(multi, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(value))
+
+	// Key is required with multiple entries.
+	_, err = getSecretEntryValue(multi, "")
+	assert.Error(t, err)
+
+	// Missing key.
+	_, err = getSecretEntryValue(single, "missing")
+	assert.Error(t, err)
+}
+
+func TestParseSecretPayloadFlags(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"user":"foo"}`), 0600))
+
+	payload, err := parseSecretPayloadFlags([]string{"apikey=abc=123"}, []string{"credentials=" + filePath})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"apikey":      []byte("abc=123"),
+		"credentials": []byte(`{"user":"foo"}`),
+	}, payload)
+
+	// Invalid formats and duplicate keys.
+	_, err = parseSecretPayloadFlags([]string{"novalue"}, nil)
+	assert.Error(t, err)
+	_, err = parseSecretPayloadFlags([]string{"apikey=a"}, []string{"apikey=" + filePath})
+	assert.Error(t, err)
+	_, err = parseSecretPayloadFlags(nil, []string{"missing=" + filepath.Join(t.TempDir(), "missing.txt")})
+	assert.Error(t, err)
+}
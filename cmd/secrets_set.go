@@ -0,0 +1,147 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+type secretsSetOpts struct {
+	UsePositionalArgs
+
+	argEnvironment    string
+	argSecretName     string
+	flagLiteralValues []string
+	flagFileValues    []string
+
+	payloadKeyValuePairs map[string][]byte
+}
+
+func init() {
+	o := secretsSetOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argSecretName, "NAME", "Name of the secret, e.g., 'user-some-secret'.")
+
+	cmd := &cobra.Command{
+		Use:   "set ENVIRONMENT NAME [flags]",
+		Short: "Set entries of a user secret in the target environment, creating it if needed",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Set entries of a user secret in the target environment, eg, API keys for third-party
+			services used by the game server. The secret is created if it doesn't exist yet. The
+			other entries of an existing secret are kept.
+
+			Secret name must start with 'user-'. This avoids conflicts with other secrets.
+
+			The secrets are stored as Kubernetes secrets in the environment's namespace, labeled
+			with 'metaplay.io/managed=true'. The game server can access them in the runtime
+			options with the syntax 'kube-secret://<secretName>#<secretKey>'.
+
+			Unlike 'metaplay secrets create' and 'metaplay secrets update', this command works the
+			same whether or not the secret exists, which makes it convenient for scripts and CI.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay secrets get ENVIRONMENT NAME KEY' to read the value of an entry.
+			- 'metaplay secrets list ENVIRONMENT ...' to list all user secrets.
+			- 'metaplay secrets delete ENVIRONMENT NAME ...' to delete a user secret.
+		`),
+		Example: renderExample(`
+			# Set the entry 'apikey' in secret 'user-analytics', creating the secret if needed.
+			# Accessible with URL 'kube-secret://user-analytics#apikey'
+			metaplay secrets set nimbly user-analytics --from-literal=apikey=abc123
+
+			# Set an entry with the value read from a file.
+			metaplay secrets set nimbly user-analytics --from-file=credentials=./credentials.json
+		`),
+	}
+
+	secretsCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringArrayVar(&o.flagLiteralValues, "from-literal", []string{}, "Set an entry using a literal value (e.g., key=value)")
+	flags.StringArrayVar(&o.flagFileValues, "from-file", []string{}, "Set an entry with the value read from a file (e.g., key=filepath)")
+}
+
+func (o *secretsSetOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if !strings.HasPrefix(o.argSecretName, "user-") {
+		return clierrors.NewUsageErrorf("Invalid secret name '%s'", o.argSecretName).
+			WithSuggestion("Secret names must start with 'user-', eg, 'user-" + o.argSecretName + "'")
+	}
+
+	payload, err := parseSecretPayloadFlags(o.flagLiteralValues, o.flagFileValues)
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return clierrors.NewUsageError("No secret entries to set").
+			WithSuggestion("Specify the entries with --from-literal or --from-file")
+	}
+	o.payloadKeyValuePairs = payload
+	return nil
+}
+
+func (o *secretsSetOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project & auth provider.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+
+	// Resolve environment.
+	envConfig, tokenSet, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Check that the environment belongs to this project before modifying it.
+	if _, err := checkEnvironmentProjectOwnership(project, envConfig, tokenSet); err != nil {
+		return err
+	}
+
+	// Check that the operation is allowed by the organization's policy.
+	if _, err := checkOrganizationPolicy(project, envConfig, tokenSet, orgPolicyOperationModify); err != nil {
+		return err
+	}
+
+	// Create TargetEnvironment.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+
+	// Print secret info.
+	secretKeys := make([]string, 0, len(o.payloadKeyValuePairs))
+	for key := range o.payloadKeyValuePairs {
+		secretKeys = append(secretKeys, key)
+	}
+	slices.Sort(secretKeys)
+	log.Info().Msg("")
+	log.Info().Msgf("Set secret:")
+	log.Info().Msgf("  Target environment: %s", styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msgf("  Secret name:        %s", styles.RenderTechnical(o.argSecretName))
+	log.Info().Msgf("  Keys to set:        %s", styles.RenderListTechnical(secretKeys))
+	log.Info().Msg("")
+
+	// Set the entries, creating the secret if needed.
+	created, err := targetEnv.SetSecretValues(cmd.Context(), o.argSecretName, o.payloadKeyValuePairs)
+	if err != nil {
+		return err
+	}
+
+	if created {
+		log.Info().Msgf("✅ Secret %s created", o.argSecretName)
+	} else {
+		log.Info().Msgf("✅ Secret %s updated", o.argSecretName)
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/version"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Path of the file, relative to the project directory, that tells the editor integrations the
+// address and token of the running local API server.
+const serveAPIDiscoveryFilePath = ".metaplay/serve-api.json"
+
+// Serve a localhost-only HTTP API for the editor integrations.
+type serveAPIOpts struct {
+	UsePositionalArgs
+
+	flagPort int
+	flagStop bool
+}
+
+// serveAPIDiscovery is the content of the discovery file.
+type serveAPIDiscovery struct {
+	URL   string `json:"url"`   // Base URL of the API, eg, 'http://127.0.0.1:51234'
+	Token string `json:"token"` // Bearer token required in the requests
+	PID   int    `json:"pid"`   // Process ID of the server
+}
+
+// serveAPIServer handles the requests to the local API.
+type serveAPIServer struct {
+	token       string                                    // Bearer token required in the requests
+	loadProject func() (*metaproj.MetaplayProject, error) // Loads the current project config
+	jobs        *apiJobManager                            // Build and deploy jobs
+	shutdown    func()                                    // Stops the server
+}
+
+func init() {
+	o := serveAPIOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "serve-api [flags]",
+		Short: "[preview] Serve a local HTTP API for editor and IDE integrations",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			PREVIEW: This command is in preview and subject to change!
+
+			Serve a localhost-only HTTP API that the Unity editor window and other IDE plugins can
+			use to integrate with the CLI without spawning subprocesses and parsing their output.
+
+			The server only listens on 127.0.0.1, and all requests must have the header
+			'Authorization: Bearer <token>' with the token generated when starting the server. The
+			address and the token are written to .metaplay/serve-api.json in the project directory
+			for the integrations to find, and the file is removed when the server stops.
+
+			Endpoints:
+			- GET  /api/v1/project: Project ID, directories, and SDK version.
+			- GET  /api/v1/environments: Environments in metaplay-project.yaml.
+			- GET  /api/v1/auth: Whether logged in, and the user info.
+			- GET  /api/v1/jobs: All the started jobs.
+			- POST /api/v1/jobs: Start a job, eg, '{"command": ["deploy", "server", "nimbly"]}'.
+			- GET  /api/v1/jobs/{id}: The job state and exit code.
+			- GET  /api/v1/jobs/{id}/events: Stream the job output and state as server-sent events.
+			- POST /api/v1/jobs/{id}/cancel: Cancel a running job.
+			- POST /api/v1/shutdown: Stop the server.
+
+			The jobs run the CLI commands in non-interactive mode. Allowed commands are
+			'build image', 'build dashboard', 'build game-config', 'build localizations',
+			'deploy server', 'deploy botclient', and 'env check'.
+
+			The server runs until it is stopped with Ctrl-C, the shutdown endpoint, or --stop.
+
+			{Arguments}
+		`),
+		Example: renderExample(`
+			# Start the API server on a random free port.
+			metaplay serve-api
+
+			# Start the API server on port 5570.
+			metaplay serve-api --port=5570
+
+			# Stop the API server running for the project.
+			metaplay serve-api --stop
+		`),
+	}
+	rootCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.IntVar(&o.flagPort, "port", 0, "Port to listen on (default: a random free port)")
+	flags.BoolVar(&o.flagStop, "stop", false, "Stop the API server running for the project")
+}
+
+func (o *serveAPIOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagPort < 0 || o.flagPort > 65535 {
+		return clierrors.NewUsageErrorf("Invalid --port %d", o.flagPort)
+	}
+	return nil
+}
+
+func (o *serveAPIOpts) Run(cmd *cobra.Command) error {
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+	projectDir, err := filepath.Abs(project.RelativeDir)
+	if err != nil {
+		return err
+	}
+	discoveryPath := filepath.Join(projectDir, serveAPIDiscoveryFilePath)
+
+	if o.flagStop {
+		return stopServeAPI(cmd.Context(), discoveryPath)
+	}
+
+	// Listen on localhost only.
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", o.flagPort))
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to listen on port %d", o.flagPort).
+			WithSuggestion("Use --port to choose another port, or omit it to use a random free port")
+	}
+	baseURL := "http://" + listener.Addr().String()
+
+	token, err := generateServeAPIToken()
+	if err != nil {
+		return err
+	}
+
+	// The jobs run this same CLI executable for the project.
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to resolve the CLI executable: %w", err)
+	}
+	jobs := newAPIJobManager(func(ctx context.Context, args []string) *exec.Cmd {
+		fullArgs := append([]string{"--project", projectDir, "--color", "no", "--skip-version-check"}, args...)
+		jobCmd := exec.CommandContext(ctx, executable, fullArgs...)
+		jobCmd.Dir = projectDir
+		return jobCmd
+	})
+	defer jobs.CancelAll()
+
+	ctx, stop := context.WithCancel(cmd.Context())
+	defer stop()
+	server := &serveAPIServer{
+		token:       token,
+		loadProject: func() (*metaproj.MetaplayProject, error) { return loadProject(projectDir) },
+		jobs:        jobs,
+		shutdown:    stop,
+	}
+	httpServer := &http.Server{
+		Handler:           server.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Write the discovery file for the integrations, readable only by the user.
+	discovery := serveAPIDiscovery{URL: baseURL, Token: token, PID: os.Getpid()}
+	if err := writeServeAPIDiscovery(discoveryPath, discovery); err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(discoveryPath) }()
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Metaplay CLI Local API"))
+	log.Info().Msg("")
+	log.Info().Msgf("Listening on:   %s", styles.RenderTechnical(baseURL))
+	log.Info().Msgf("Discovery file: %s", styles.RenderTechnical(discoveryPath))
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderMuted("Press Ctrl-C to stop the server."))
+
+	// Stop the server when the context is canceled, eg, with Ctrl-C or the shutdown endpoint.
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("local API server failed: %w", err)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg("Local API server stopped")
+	return nil
+}
+
+// generateServeAPIToken returns a random bearer token.
+func generateServeAPIToken() (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate the API token: %w", err)
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+// writeServeAPIDiscovery writes the discovery file, readable only by the user.
+func writeServeAPIDiscovery(path string, discovery serveAPIDiscovery) error {
+	content, err := json.MarshalIndent(discovery, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// stopServeAPI stops the API server running for the project using its shutdown endpoint.
+func stopServeAPI(ctx context.Context, discoveryPath string) error {
+	content, err := os.ReadFile(discoveryPath)
+	if os.IsNotExist(err) {
+		log.Info().Msg("No local API server running for the project")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", discoveryPath, err)
+	}
+	var discovery serveAPIDiscovery
+	if err := json.Unmarshal(content, &discovery); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", discoveryPath, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.URL+"/api/v1/shutdown", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+discovery.Token)
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		// The server is not running anymore, eg, it was killed, so clean up the stale file.
+		_ = os.Remove(discoveryPath)
+		log.Info().Msg("No local API server running for the project")
+		return nil
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to stop the local API server: %s", resp.Status)
+	}
+
+	log.Info().Msgf("Stopped the local API server at %s", styles.RenderTechnical(discovery.URL))
+	return nil
+}
+
+// routes returns the handler for all the API endpoints.
+func (s *serveAPIServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/project", s.handleProject)
+	mux.HandleFunc("GET /api/v1/environments", s.handleEnvironments)
+	mux.HandleFunc("GET /api/v1/auth", s.handleAuth)
+	mux.HandleFunc("GET /api/v1/jobs", s.handleListJobs)
+	mux.HandleFunc("POST /api/v1/jobs", s.handleStartJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/events", s.handleJobEvents)
+	mux.HandleFunc("POST /api/v1/jobs/{id}/cancel", s.handleCancelJob)
+	mux.HandleFunc("POST /api/v1/shutdown", s.handleShutdown)
+	return s.authenticate(mux)
+}
+
+// authenticate only lets through the requests to localhost with the right bearer token. The host
+// check protects against DNS rebinding from web pages opened in the browser.
+func (s *serveAPIServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "127.0.0.1" && host != "localhost" {
+			writeAPIError(w, http.StatusForbidden, "only requests to localhost are allowed")
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeAPIJSON writes the value as a JSON response.
+func writeAPIJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeAPIError writes an error response, eg, '{"error": "job not found"}'.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
+
+func (s *serveAPIServer) handleProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.loadProject()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	projectDir, _ := filepath.Abs(project.RelativeDir)
+	sdkVersion := ""
+	if project.VersionMetadata.SdkVersion != nil {
+		sdkVersion = project.VersionMetadata.SdkVersion.String()
+	}
+	response := map[string]any{
+		"project_id":    project.Config.ProjectHumanID,
+		"project_dir":   projectDir,
+		"sdk_root_dir":  project.GetSdkRootDir(),
+		"backend_dir":   project.GetBackendDir(),
+		"client_engine": project.GetClientEngine(),
+		"sdk_version":   sdkVersion,
+		"cli_version":   version.AppVersion,
+	}
+	switch project.GetClientEngine() {
+	case metaproj.ClientEngineUnity:
+		response["unity_project_dir"] = project.GetUnityProjectDir()
+	case metaproj.ClientEngineUnreal:
+		response["unreal_project_dir"] = project.GetUnrealProjectDir()
+	}
+	writeAPIJSON(w, http.StatusOK, response)
+}
+
+func (s *serveAPIServer) handleEnvironments(w http.ResponseWriter, r *http.Request) {
+	project, err := s.loadProject()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	environments := make([]map[string]any, 0, len(project.Config.Environments))
+	for _, envConfig := range project.Config.Environments {
+		environments = append(environments, map[string]any{
+			"name":         envConfig.Name,
+			"human_id":     envConfig.HumanID,
+			"type":         envConfig.Type,
+			"stack_domain": envConfig.StackDomain,
+			"aliases":      envConfig.Aliases,
+		})
+	}
+	writeAPIJSON(w, http.StatusOK, environments)
+}
+
+func (s *serveAPIServer) handleAuth(w http.ResponseWriter, r *http.Request) {
+	project, err := s.loadProject()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	authProvider, err := getAuthProvider(project, "")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tokenSet, err := auth.LoadAndRefreshTokenSet(authProvider)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if tokenSet == nil {
+		writeAPIJSON(w, http.StatusOK, map[string]any{"logged_in": false, "auth_provider": authProvider.Name})
+		return
+	}
+	userInfo, err := auth.FetchUserInfo(authProvider, tokenSet)
+	if err != nil {
+		writeAPIError(w, http.StatusBadGateway, fmt.Sprintf("failed to fetch user info: %v", err))
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{
+		"logged_in":     true,
+		"auth_provider": authProvider.Name,
+		"name":          userInfo.Name,
+		"email":         userInfo.Email,
+	})
+}
+
+func (s *serveAPIServer) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, s.jobs.List())
+}
+
+func (s *serveAPIServer) handleStartJob(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Command []string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	job, err := s.jobs.Start(body.Command)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	info, _, _ := job.Snapshot(0)
+	writeAPIJSON(w, http.StatusCreated, info)
+}
+
+func (s *serveAPIServer) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job := s.jobs.Get(r.PathValue("id"))
+	if job == nil {
+		writeAPIError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	info, _, _ := job.Snapshot(0)
+	writeAPIJSON(w, http.StatusOK, info)
+}
+
+func (s *serveAPIServer) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	job := s.jobs.Get(r.PathValue("id"))
+	if job == nil {
+		writeAPIError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	job.Cancel()
+	info, _, _ := job.Snapshot(0)
+	writeAPIJSON(w, http.StatusOK, info)
+}
+
+// handleJobEvents streams the job's output lines as 'log' events, and the job info as 'state'
+// events when the job starts and ends, until the job ends or the client disconnects.
+func (s *serveAPIServer) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	job := s.jobs.Get(r.PathValue("id"))
+	if job == nil {
+		writeAPIError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(event string, value any) {
+		data, _ := json.Marshal(value)
+		_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	}
+
+	info, _, _ := job.Snapshot(0)
+	writeEvent("state", info)
+	numLinesSent := 0
+	for {
+		info, lines, changed := job.Snapshot(numLinesSent)
+		for _, line := range lines {
+			writeEvent("log", map[string]string{"line": line})
+		}
+		numLinesSent += len(lines)
+		if info.State != apiJobRunning {
+			writeEvent("state", info)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+func (s *serveAPIServer) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, map[string]string{"status": "stopping"})
+	s.shutdown()
+}
@@ -0,0 +1,218 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CLI commands that can be run as jobs through the local API. Only commands that don't need
+// interactive input are allowed; the jobs run in non-interactive mode.
+var serveAPIAllowedJobCommands = [][]string{
+	{"build", "image"},
+	{"build", "dashboard"},
+	{"build", "game-config"},
+	{"build", "localizations"},
+	{"deploy", "server"},
+	{"deploy", "botclient"},
+	{"env", "check"},
+}
+
+// apiJobState is the state of a job run through the local API.
+type apiJobState string
+
+const (
+	apiJobRunning   apiJobState = "running"
+	apiJobSucceeded apiJobState = "succeeded"
+	apiJobFailed    apiJobState = "failed"
+	apiJobCanceled  apiJobState = "canceled"
+)
+
+// apiJobInfo is the JSON representation of a job.
+type apiJobInfo struct {
+	ID        string      `json:"id"`
+	Command   []string    `json:"command"`
+	State     apiJobState `json:"state"`
+	ExitCode  *int        `json:"exit_code,omitempty"`
+	StartedAt time.Time   `json:"started_at"`
+	EndedAt   *time.Time  `json:"ended_at,omitempty"`
+}
+
+// apiJob is a CLI command run in a child process, with its output collected for streaming.
+type apiJob struct {
+	mu      sync.Mutex
+	info    apiJobInfo
+	lines   []string      // Output lines so far
+	changed chan struct{} // Closed and replaced whenever lines or state change
+	cancel  context.CancelFunc
+}
+
+// apiJobManager runs the jobs and keeps track of them.
+type apiJobManager struct {
+	mu         sync.Mutex
+	jobs       map[string]*apiJob
+	nextID     int
+	newCommand func(ctx context.Context, args []string) *exec.Cmd // Creates the child process for the CLI arguments
+}
+
+func newAPIJobManager(newCommand func(ctx context.Context, args []string) *exec.Cmd) *apiJobManager {
+	return &apiJobManager{
+		jobs:       map[string]*apiJob{},
+		newCommand: newCommand,
+	}
+}
+
+// isAllowedJobCommand checks that the arguments start with one of the allowed commands.
+func isAllowedJobCommand(args []string) bool {
+	for _, allowed := range serveAPIAllowedJobCommands {
+		if len(args) >= len(allowed) && slices.Equal(args[:len(allowed)], allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start starts a new job running the CLI with the given arguments.
+func (m *apiJobManager) Start(args []string) (*apiJob, error) {
+	if !isAllowedJobCommand(args) {
+		return nil, fmt.Errorf("command '%s' is not allowed", strings.Join(args, " "))
+	}
+
+	m.mu.Lock()
+	m.nextID++
+	id := fmt.Sprintf("%d", m.nextID)
+	m.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := m.newCommand(ctx, args)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	cmd.Stderr = cmd.Stdout // Both streams into the same pipe, in order
+
+	job := &apiJob{
+		info: apiJobInfo{
+			ID:        id,
+			Command:   args,
+			State:     apiJobRunning,
+			StartedAt: time.Now(),
+		},
+		changed: make(chan struct{}),
+		cancel:  cancel,
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start the command: %w", err)
+	}
+
+	m.mu.Lock()
+	m.jobs[id] = job
+	m.mu.Unlock()
+
+	go job.run(ctx, cmd, stdout)
+	return job, nil
+}
+
+// Get returns the job with the ID, or nil if there is no such job.
+func (m *apiJobManager) Get(id string) *apiJob {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id]
+}
+
+// List returns the infos of all the jobs, oldest first.
+func (m *apiJobManager) List() []apiJobInfo {
+	m.mu.Lock()
+	jobs := make([]*apiJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	m.mu.Unlock()
+
+	infos := make([]apiJobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		info, _, _ := job.Snapshot(0)
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b apiJobInfo) int { return a.StartedAt.Compare(b.StartedAt) })
+	return infos
+}
+
+// CancelAll cancels all the running jobs, eg, when the API server is shutting down.
+func (m *apiJobManager) CancelAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range m.jobs {
+		job.cancel()
+	}
+}
+
+// run collects the output of the child process until it exits.
+func (job *apiJob) run(ctx context.Context, cmd *exec.Cmd, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		job.mu.Lock()
+		job.lines = append(job.lines, scanner.Text())
+		job.notifyLocked()
+		job.mu.Unlock()
+	}
+
+	err := cmd.Wait()
+	endedAt := time.Now()
+	exitCode := cmd.ProcessState.ExitCode()
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.info.EndedAt = &endedAt
+	job.info.ExitCode = &exitCode
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		job.info.State = apiJobCanceled
+	case err == nil:
+		job.info.State = apiJobSucceeded
+	case errors.As(err, &exitErr):
+		job.info.State = apiJobFailed
+	default:
+		job.info.State = apiJobFailed
+		job.lines = append(job.lines, fmt.Sprintf("Failed to run the command: %v", err))
+	}
+	job.notifyLocked()
+}
+
+// notifyLocked wakes up everyone waiting for changes. Must be called with the lock held.
+func (job *apiJob) notifyLocked() {
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+// Snapshot returns the job info, the output lines starting from fromLine, and a channel that
+// is closed when the job changes next.
+func (job *apiJob) Snapshot(fromLine int) (apiJobInfo, []string, <-chan struct{}) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	var lines []string
+	if fromLine < len(job.lines) {
+		lines = slices.Clone(job.lines[fromLine:])
+	}
+	return job.info, lines, job.changed
+}
+
+// Cancel stops the job's child process.
+func (job *apiJob) Cancel() {
+	job.cancel()
+}
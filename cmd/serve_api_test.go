@@ -0,0 +1,142 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServeAPIServer returns a test server whose jobs echo their arguments.
+func newTestServeAPIServer(t *testing.T) (*httptest.Server, *serveAPIServer) {
+	server := &serveAPIServer{
+		token: "secret",
+		loadProject: func() (*metaproj.MetaplayProject, error) {
+			return &metaproj.MetaplayProject{
+				Config: metaproj.ProjectConfig{
+					ProjectHumanID: "lovely-wombats",
+					Environments: []metaproj.ProjectEnvironmentConfig{
+						{Name: "Nimbly", HumanID: "lovely-wombats-nimbly", Type: "development"},
+					},
+				},
+				RelativeDir: t.TempDir(),
+			}, nil
+		},
+		jobs: newAPIJobManager(func(ctx context.Context, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "echo", args...)
+		}),
+		shutdown: func() {},
+	}
+	httpServer := httptest.NewServer(server.routes())
+	t.Cleanup(httpServer.Close)
+	return httpServer, server
+}
+
+func doTestAPIRequest(t *testing.T, method, url, token, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServeAPIAuthentication(t *testing.T) {
+	httpServer, _ := newTestServeAPIServer(t)
+
+	resp := doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/project", "", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/project", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Requests to other hosts are refused, even with the right token.
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/api/v1/project", nil)
+	require.NoError(t, err)
+	req.Host = "evil.example.com"
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/project", "secret", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var project map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&project))
+	assert.Equal(t, "lovely-wombats", project["project_id"])
+}
+
+func TestServeAPIEnvironments(t *testing.T) {
+	httpServer, _ := newTestServeAPIServer(t)
+
+	resp := doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/environments", "secret", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var environments []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&environments))
+	require.Len(t, environments, 1)
+	assert.Equal(t, "lovely-wombats-nimbly", environments[0]["human_id"])
+}
+
+func TestServeAPIJobs(t *testing.T) {
+	httpServer, _ := newTestServeAPIServer(t)
+
+	// Only the allowed commands can be run.
+	resp := doTestAPIRequest(t, http.MethodPost, httpServer.URL+"/api/v1/jobs", "secret", `{"command": ["auth", "logout"]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = doTestAPIRequest(t, http.MethodPost, httpServer.URL+"/api/v1/jobs", "secret", `{"command": ["deploy", "server", "nimbly"]}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var job apiJobInfo
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	assert.Equal(t, apiJobRunning, job.State)
+
+	// Stream the events until the job ends.
+	resp = doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/jobs/"+job.ID+"/events", "secret", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	var events []string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if event, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, event)
+		} else if value, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			data = append(data, value)
+		}
+	}
+	assert.Equal(t, []string{"state", "log", "state"}, events)
+	assert.Equal(t, `{"line":"deploy server nimbly"}`, data[1])
+
+	var finalState apiJobInfo
+	require.NoError(t, json.Unmarshal([]byte(data[2]), &finalState))
+	assert.Equal(t, apiJobSucceeded, finalState.State)
+	require.NotNil(t, finalState.ExitCode)
+	assert.Equal(t, 0, *finalState.ExitCode)
+
+	resp = doTestAPIRequest(t, http.MethodGet, httpServer.URL+"/api/v1/jobs/missing", "secret", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestIsAllowedJobCommand(t *testing.T) {
+	assert.True(t, isAllowedJobCommand([]string{"build", "image"}))
+	assert.True(t, isAllowedJobCommand([]string{"deploy", "server", "nimbly", "mygame:1234"}))
+	assert.False(t, isAllowedJobCommand([]string{"build"}))
+	assert.False(t, isAllowedJobCommand([]string{"remove", "server", "nimbly"}))
+	assert.False(t, isAllowedJobCommand(nil))
+}
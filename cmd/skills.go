@@ -59,12 +59,12 @@ func init() {
 	rootCmd.AddCommand(skillsCmd)
 }
 
-// printSkillContent writes skill content to stdout. In interactive terminals
+// printMarkdownContent writes markdown content, eg, a skill, to stdout. In interactive terminals
 // it pretty-prints the markdown via glamour for readability; everywhere else
 // (pipes, redirects, CI, --verbose) it writes the exact bytes so AI agents
 // and shell pipelines see the raw markdown. Ensures a single trailing newline
 // in the raw path; glamour produces its own trailing whitespace.
-func printSkillContent(content []byte) {
+func printMarkdownContent(content []byte) {
 	if tui.IsInteractiveMode() {
 		if rendered, ok := renderMarkdownForTerminal(content); ok {
 			fmt.Print(rendered)
@@ -89,6 +89,6 @@ func (o *skillsGetOpts) Run(cmd *cobra.Command) error {
 		content = skillspkg.RenderRootPage(skill, content)
 	}
 
-	printSkillContent(content)
+	printMarkdownContent(content)
 	return nil
 }
@@ -0,0 +1,19 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// stateCmd includes commands for managing the CLI's persisted state.
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Commands for managing the CLI's local state (credentials, caches, etc.)",
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Remove the CLI's persisted state.
+type stateCleanOpts struct {
+	flagAll    bool
+	flagYes    bool
+	flagDryRun bool
+}
+
+func init() {
+	o := stateCleanOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "clean [flags]",
+		Short: "Remove the CLI's cached data or all of its local state",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Remove the CLI's cached data. The cached data is fetched again when needed.
+
+			With --all, all of the CLI's local state is removed, including the credentials of all
+			the logged-in sessions, the onboarding progress, and the command usage statistics.
+			This requires confirmation, or --yes in non-interactive sessions.
+
+			Only the directories of the current profile are removed: set METAPLAY_HOME to clean an
+			isolated profile. See 'metaplay state show' for the locations of the directories.
+
+			Related commands:
+			- 'metaplay state show' to show where the CLI stores its local state.
+			- 'metaplay auth logout' to sign out of the current session only.
+		`),
+		Example: renderExample(`
+			# Remove the cached data.
+			metaplay state clean
+
+			# Remove all of the local state, including credentials.
+			metaplay state clean --all
+
+			# Show what would be removed without removing anything.
+			metaplay state clean --all --dry-run
+		`),
+	}
+	stateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagAll, "all", false, "Remove all of the local state, including credentials, instead of only the cached data")
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Skip the confirmation prompt")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *stateCleanOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagAll && !o.flagYes && !o.flagDryRun && !tui.IsInteractiveMode() {
+		return clierrors.NewUsageError("Confirmation required to remove all of the local state").
+			WithSuggestion("Use --yes in non-interactive mode to confirm")
+	}
+	return nil
+}
+
+func (o *stateCleanOpts) Run(cmd *cobra.Command) error {
+	dirs, err := getStateDirInfos()
+	if err != nil {
+		return err
+	}
+
+	// Only remove the existing directories, and only the cache without --all.
+	var targets []stateDirInfo
+	for _, dir := range dirs {
+		if dir.Exists && (o.flagAll || dir.Kind == common.StateDirCache) {
+			targets = append(targets, dir)
+		}
+	}
+	if len(targets) == 0 {
+		log.Info().Msg("Nothing to clean")
+		return nil
+	}
+
+	if o.flagDryRun {
+		plan := dryRunPlan{}
+		for _, dir := range targets {
+			plan.Addf("Remove the %s directory %s (%s)", dir.Kind, dir.Path, humanize.Bytes(uint64(dir.SizeBytes)))
+		}
+		plan.Print()
+		return nil
+	}
+
+	if o.flagAll && !o.flagYes {
+		log.Info().Msg("")
+		log.Info().Msg("The following directories will be removed:")
+		for _, dir := range targets {
+			log.Info().Msgf("  %-7s %s", dir.Kind+":", styles.RenderTechnical(dir.Path))
+		}
+		log.Info().Msg("")
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Remove all of the local state, including credentials?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return clierrors.New("Cleaning the local state canceled by user")
+		}
+	}
+
+	for _, dir := range targets {
+		if err := os.RemoveAll(dir.Path); err != nil {
+			return clierrors.Wrapf(err, "Failed to remove the %s directory %s", dir.Kind, dir.Path)
+		}
+		log.Info().Msgf("%s Removed the %s directory %s", styles.RenderSuccess("✓"), dir.Kind, styles.RenderTechnical(dir.Path))
+	}
+	if o.flagAll {
+		log.Info().Msgf("Sign in again with %s.", styles.RenderPrompt("metaplay auth login"))
+	}
+	return nil
+}
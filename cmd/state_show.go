@@ -0,0 +1,154 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Show the locations of the CLI's persisted state.
+type stateShowOpts struct {
+	flagFormat string
+}
+
+// stateDirInfo is a directory of persisted state along with its current usage.
+type stateDirInfo struct {
+	common.StateDir
+	Exists    bool  `json:"exists"`
+	NumFiles  int   `json:"num_files"`
+	SizeBytes int64 `json:"size_bytes"`
+}
+
+func init() {
+	o := stateShowOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "show [flags]",
+		Short: "Show where the CLI stores its local state",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Show the directories where the CLI stores its local state, along with their size:
+			- config: The credentials of the logged-in sessions and the settings ('metaplay config').
+			- state: Persistent state, eg, the progress of 'metaplay onboard'.
+			- cache: Cached data that is safe to remove, eg, the organization policies.
+
+			On Linux, the XDG base directories are used: $XDG_CONFIG_HOME, $XDG_STATE_HOME and
+			$XDG_CACHE_HOME (defaulting to ~/.config, ~/.local/state and ~/.cache). On macOS and
+			Windows, the platform's application data directories are used.
+
+			Set METAPLAY_HOME to relocate all of the state under a single directory, eg, to use
+			hermetic state in CI runners or to keep multiple isolated profiles on one machine.
+
+			Related commands:
+			- 'metaplay state clean' to remove the cached data or all of the state.
+		`),
+		Example: renderExample(`
+			# Show the state directories.
+			metaplay state show
+
+			# Show the state directories of an isolated profile.
+			METAPLAY_HOME=~/metaplay-profiles/ci metaplay state show
+
+			# Output the state directories as JSON.
+			metaplay state show --format=json
+		`),
+	}
+	stateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+}
+
+func (o *stateShowOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+	return nil
+}
+
+func (o *stateShowOpts) Run(cmd *cobra.Command) error {
+	dirs, err := getStateDirInfos()
+	if err != nil {
+		return err
+	}
+
+	if o.flagFormat == "json" {
+		dirsJSON, err := json.MarshalIndent(dirs, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal state directories as JSON")
+		}
+		log.Info().Msg(string(dirsJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("CLI State Directories"))
+	log.Info().Msg("")
+	for _, dir := range dirs {
+		usage := styles.RenderMuted("(not created)")
+		if dir.Exists {
+			usage = styles.RenderMuted(fmt.Sprintf("(%d files, %s)", dir.NumFiles, humanize.Bytes(uint64(dir.SizeBytes))))
+		}
+		log.Info().Msgf("  %-7s %s %s", dir.Kind+":", styles.RenderTechnical(dir.Path), usage)
+		log.Info().Msgf("  %-7s %s", "", styles.RenderMuted("from "+dir.Source))
+	}
+	log.Info().Msg("")
+	return nil
+}
+
+// getStateDirInfos resolves all the state directories and their current usage.
+func getStateDirInfos() ([]stateDirInfo, error) {
+	dirs := make([]stateDirInfo, 0, len(common.AllStateDirKinds))
+	for _, kind := range common.AllStateDirKinds {
+		stateDir, err := common.ResolveStateDirPath(kind)
+		if err != nil {
+			return nil, err
+		}
+		info := stateDirInfo{StateDir: stateDir}
+		info.Exists, info.NumFiles, info.SizeBytes, err = getDirectoryUsage(stateDir.Path)
+		if err != nil {
+			return nil, clierrors.Wrapf(err, "Failed to read the %s directory", kind)
+		}
+		dirs = append(dirs, info)
+	}
+	return dirs, nil
+}
+
+// getDirectoryUsage returns whether the directory exists, and the number and total size of
+// the files in it (recursively).
+func getDirectoryUsage(dirPath string) (exists bool, numFiles int, sizeBytes int64, err error) {
+	err = filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		exists = true
+		if entry.Type().IsRegular() {
+			fileInfo, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			numFiles++
+			sizeBytes += fileInfo.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) && !exists {
+		return false, 0, 0, nil
+	}
+	return exists, numFiles, sizeBytes, err
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDirectoryUsage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "org-policies"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte("12345"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "org-policies", "a.json"), []byte("123"), 0600))
+
+	exists, numFiles, sizeBytes, err := getDirectoryUsage(dir)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 2, numFiles)
+	assert.Equal(t, int64(8), sizeBytes)
+
+	exists, numFiles, sizeBytes, err = getDirectoryUsage(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Zero(t, numFiles)
+	assert.Zero(t, sizeBytes)
+}
+
+func TestGetStateDirInfosWithMetaplayHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(common.MetaplayHomeEnvVar, home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "cache"), 0700))
+
+	dirs, err := getStateDirInfos()
+	require.NoError(t, err)
+	require.Len(t, dirs, 3)
+	for _, dir := range dirs {
+		assert.Equal(t, filepath.Join(home, string(dir.Kind)), dir.Path)
+		assert.Equal(t, common.MetaplayHomeEnvVar, dir.Source)
+		assert.Equal(t, dir.Kind == common.StateDirCache, dir.Exists)
+	}
+}
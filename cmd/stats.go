@@ -0,0 +1,339 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Name of the file (in the CLI state directory) where the command usage statistics are persisted.
+const usageStatsFileName = "usage-stats.json"
+
+// Number of most recent durations kept per command for computing the median duration.
+const usageStatsMaxDurations = 100
+
+// Show the local command usage statistics.
+type statsOpts struct {
+	flagFormat  string
+	flagReset   bool
+	flagEnable  bool
+	flagDisable bool
+}
+
+// usageStats is the persisted command usage statistics of the local user. The statistics are
+// never sent anywhere.
+type usageStats struct {
+	Disabled bool                     `json:"disabled,omitempty"` // User has opted out of collecting the statistics.
+	Since    time.Time                `json:"since"`              // When the collection of the statistics started.
+	Commands map[string]*commandUsage `json:"commands"`           // Statistics by command path, eg, 'build image'.
+}
+
+// commandUsage is the usage statistics of a single command.
+type commandUsage struct {
+	Runs         int       `json:"runs"`            // Number of times the command has been run.
+	Failures     int       `json:"failures"`        // Number of runs that failed.
+	TotalSeconds float64   `json:"totalSeconds"`    // Total duration of all the runs.
+	LastRun      time.Time `json:"lastRun"`         // When the command was last run.
+	Durations    []float64 `json:"recentDurations"` // Durations of the most recent runs, in seconds.
+}
+
+// commandUsageSummary is the summarized usage of a single command, as output by 'metaplay stats'.
+type commandUsageSummary struct {
+	Command       string    `json:"command"`
+	Runs          int       `json:"runs"`
+	Failures      int       `json:"failures"`
+	MedianSeconds float64   `json:"medianSeconds"`
+	TotalSeconds  float64   `json:"totalSeconds"`
+	LastRun       time.Time `json:"lastRun"`
+}
+
+var statsOpt = statsOpts{}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [flags]",
+	Short: "Show how much time you spend in each CLI command",
+	Run:   runCommand(&statsOpt),
+	Long: renderLong(&statsOpt, `
+		Show the local usage statistics of the CLI commands: how many times each command
+		has been run, how many of the runs failed, and the median and total durations. The
+		commands are listed in the order of total time spent, which helps in seeing where
+		the time goes, eg, whether builds would benefit from caching or remote builders.
+
+		The statistics are collected only locally, in the CLI's state directory (see 'metaplay
+		state show'), and are never sent anywhere. Only the command names are recorded, not
+		the arguments.
+
+		To opt out of collecting the statistics, use --disable, or set the environment
+		variable METAPLAYCLI_USAGE_STATS=no. Use --enable to opt back in, and --reset to
+		clear the collected statistics.
+	`),
+	Example: renderExample(`
+		# Show the command usage statistics.
+		metaplay stats
+
+		# Output the statistics as JSON.
+		metaplay stats --format=json
+
+		# Clear the collected statistics.
+		metaplay stats --reset
+
+		# Stop collecting the statistics.
+		metaplay stats --disable
+	`),
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	flags := statsCmd.Flags()
+	flags.StringVar(&statsOpt.flagFormat, "format", "text", "Output format: 'text' or 'json'")
+	flags.BoolVar(&statsOpt.flagReset, "reset", false, "Clear the collected statistics")
+	flags.BoolVar(&statsOpt.flagEnable, "enable", false, "Enable collecting the statistics")
+	flags.BoolVar(&statsOpt.flagDisable, "disable", false, "Disable collecting the statistics")
+	statsCmd.MarkFlagsMutuallyExclusive("enable", "disable")
+}
+
+func (o *statsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use --format=text or --format=json")
+	}
+	return nil
+}
+
+func (o *statsOpts) Run(cmd *cobra.Command) error {
+	stats, err := loadUsageStats()
+	if err != nil {
+		return err
+	}
+
+	// Handle the modifications of the statistics.
+	if o.flagReset || o.flagEnable || o.flagDisable {
+		if o.flagReset {
+			stats.Commands = map[string]*commandUsage{}
+			stats.Since = time.Now()
+			log.Info().Msg(styles.RenderSuccess("✅ Command usage statistics cleared"))
+		}
+		if o.flagEnable {
+			stats.Disabled = false
+			log.Info().Msg(styles.RenderSuccess("✅ Command usage statistics enabled"))
+		}
+		if o.flagDisable {
+			stats.Disabled = true
+			log.Info().Msg(styles.RenderSuccess("✅ Command usage statistics disabled"))
+		}
+		return saveUsageStats(stats)
+	}
+
+	summaries := summarizeUsageStats(stats)
+	if o.flagFormat == "json" {
+		summariesJSON, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal usage statistics as JSON")
+		}
+		log.Info().Msg(string(summariesJSON))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Command Usage Statistics"))
+	log.Info().Msg("")
+	if stats.Disabled || isFalsy(os.Getenv("METAPLAYCLI_USAGE_STATS")) {
+		log.Info().Msg(styles.RenderWarning("Collecting the statistics is disabled."))
+		log.Info().Msg("")
+	}
+	if len(summaries) == 0 {
+		log.Info().Msg("No commands recorded yet.")
+		return nil
+	}
+
+	log.Info().Msgf("Recorded since %s:", styles.RenderTechnical(stats.Since.Local().Format(time.DateOnly)))
+	log.Info().Msg("")
+	commandWidth := len("Command")
+	for _, summary := range summaries {
+		commandWidth = max(commandWidth, len(summary.Command))
+	}
+	log.Info().Msg(styles.RenderMuted(fmt.Sprintf("  %-*s  %6s  %6s  %10s  %10s", commandWidth, "Command", "Runs", "Failed", "Median", "Total")))
+	for _, summary := range summaries {
+		log.Info().Msgf("  %-*s  %6d  %6d  %10s  %10s", commandWidth, summary.Command, summary.Runs, summary.Failures,
+			formatDuration(int(summary.MedianSeconds+0.5)), formatDuration(int(summary.TotalSeconds+0.5)))
+	}
+	log.Info().Msg("")
+	return nil
+}
+
+// summarizeUsageStats returns the usage summaries of the commands, ordered by the total time spent.
+func summarizeUsageStats(stats *usageStats) []commandUsageSummary {
+	summaries := []commandUsageSummary{}
+	for command, usage := range stats.Commands {
+		summaries = append(summaries, commandUsageSummary{
+			Command:       command,
+			Runs:          usage.Runs,
+			Failures:      usage.Failures,
+			MedianSeconds: medianOf(usage.Durations),
+			TotalSeconds:  usage.TotalSeconds,
+			LastRun:       usage.LastRun,
+		})
+	}
+	slices.SortFunc(summaries, func(a, b commandUsageSummary) int {
+		if a.TotalSeconds != b.TotalSeconds {
+			if a.TotalSeconds > b.TotalSeconds {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Command, b.Command)
+	})
+	return summaries
+}
+
+// medianOf returns the median of the values, or zero if there are none.
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// record adds a run of the command to the statistics.
+func (stats *usageStats) record(command string, duration time.Duration, failed bool, now time.Time) {
+	usage, ok := stats.Commands[command]
+	if !ok {
+		usage = &commandUsage{}
+		stats.Commands[command] = usage
+	}
+	usage.Runs++
+	if failed {
+		usage.Failures++
+	}
+	usage.TotalSeconds += duration.Seconds()
+	usage.LastRun = now
+	usage.Durations = append(usage.Durations, duration.Seconds())
+	if len(usage.Durations) > usageStatsMaxDurations {
+		usage.Durations = usage.Durations[len(usage.Durations)-usageStatsMaxDurations:]
+	}
+}
+
+// recordCommandUsage records a run of the command in the local usage statistics, unless the
+// user has opted out. Failing to record the statistics is not an error for the command.
+func recordCommandUsage(cmd *cobra.Command, duration time.Duration, runErr error) {
+	// Skip the root command, the stats command itself, and the commands invoked by other tools.
+	command := strings.TrimSpace(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	parentCmd := cmd.Parent()
+	isCompletion := parentCmd != nil && parentCmd.Name() == "completion"
+	if command == "" || command == "stats" || isCompletion || cmd.Name() == "kubernetes-execcredential" {
+		return
+	}
+	if isFalsy(os.Getenv("METAPLAYCLI_USAGE_STATS")) {
+		return
+	}
+
+	stats, err := loadUsageStats()
+	if err != nil {
+		log.Debug().Msgf("Failed to load usage statistics: %v", err)
+		return
+	}
+	if stats.Disabled {
+		return
+	}
+	stats.record(command, duration, runErr != nil, time.Now())
+	if err := saveUsageStats(stats); err != nil {
+		log.Debug().Msgf("Failed to save usage statistics: %v", err)
+	}
+}
+
+// resolveUsageStatsFilePath returns the path to the persisted usage statistics. The statistics
+// written by older CLI versions in the config directory are moved to the state directory.
+func resolveUsageStatsFilePath() (string, error) {
+	configDir, err := auth.ResolveConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	stateDir, err := common.ResolveStateDir(common.StateDirState)
+	if err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(stateDir, usageStatsFileName)
+	if err := common.MigrateLegacyStatePath(filepath.Join(configDir, usageStatsFileName), filePath); err != nil {
+		return "", fmt.Errorf("failed to migrate usage statistics: %w", err)
+	}
+	return filePath, nil
+}
+
+// loadUsageStats loads the persisted usage statistics. Returns empty statistics if none have
+// been recorded yet.
+func loadUsageStats() (*usageStats, error) {
+	filePath, err := resolveUsageStatsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &usageStats{}
+	statsJSON, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read usage statistics: %w", err)
+	} else if err == nil {
+		if err := json.Unmarshal(statsJSON, stats); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal usage statistics: %w", err)
+		}
+	}
+	if stats.Commands == nil {
+		stats.Commands = map[string]*commandUsage{}
+	}
+	if stats.Since.IsZero() {
+		stats.Since = time.Now()
+	}
+	return stats, nil
+}
+
+// saveUsageStats persists the usage statistics. The file is replaced atomically, so that
+// concurrently running commands can't leave it corrupted.
+func saveUsageStats(stats *usageStats) error {
+	filePath, err := resolveUsageStatsFilePath()
+	if err != nil {
+		return err
+	}
+
+	statsJSON, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage statistics: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), usageStatsFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write usage statistics: %w", err)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+	if _, err := tmpFile.Write(statsJSON); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write usage statistics: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write usage statistics: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write usage statistics: %w", err)
+	}
+	return nil
+}
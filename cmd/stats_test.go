@@ -0,0 +1,49 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedianOf(t *testing.T) {
+	assert.Equal(t, 0.0, medianOf(nil))
+	assert.Equal(t, 3.0, medianOf([]float64{5, 1, 3}))
+	assert.Equal(t, 2.5, medianOf([]float64{4, 1, 3, 2}))
+}
+
+func TestUsageStatsRecord(t *testing.T) {
+	stats := &usageStats{Commands: map[string]*commandUsage{}}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	stats.record("build image", 14*time.Minute, false, now)
+	stats.record("build image", 12*time.Minute, true, now)
+	stats.record("build image", 16*time.Minute, false, now)
+	stats.record("deploy server", 2*time.Minute, false, now)
+
+	buildUsage := stats.Commands["build image"]
+	assert.Equal(t, 3, buildUsage.Runs)
+	assert.Equal(t, 1, buildUsage.Failures)
+	assert.Equal(t, (42 * time.Minute).Seconds(), buildUsage.TotalSeconds)
+	assert.Equal(t, now, buildUsage.LastRun)
+
+	// Commands are ordered by the total time spent.
+	summaries := summarizeUsageStats(stats)
+	assert.Equal(t, []commandUsageSummary{
+		{Command: "build image", Runs: 3, Failures: 1, MedianSeconds: 14 * 60, TotalSeconds: 42 * 60, LastRun: now},
+		{Command: "deploy server", Runs: 1, MedianSeconds: 2 * 60, TotalSeconds: 2 * 60, LastRun: now},
+	}, summaries)
+
+	// Only the most recent durations are kept for the median.
+	for range usageStatsMaxDurations {
+		stats.record("deploy server", time.Minute, false, now)
+	}
+	assert.Len(t, stats.Commands["deploy server"].Durations, usageStatsMaxDurations)
+	assert.Equal(t, 60.0, medianOf(stats.Commands["deploy server"].Durations))
+	assert.Equal(t, usageStatsMaxDurations+1, stats.Commands["deploy server"].Runs)
+}
@@ -0,0 +1,19 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// statusCmd includes commands for checking the status of the Metaplay services.
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the status of the Metaplay services",
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/metaplay/cli/pkg/httputil"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Check the reachability of the upstream services used by the CLI.
+type statusServicesOpts struct {
+	flagTimeout time.Duration
+}
+
+// serviceCheck is a single upstream service endpoint to check.
+type serviceCheck struct {
+	name          string // Human-readable name of the service.
+	url           string // URL to probe.
+	requireStatus int    // Required HTTP status code (0 to accept any non-server-error response).
+}
+
+// serviceCheckResult is the result of checking a service endpoint.
+type serviceCheckResult struct {
+	check      serviceCheck
+	statusCode int
+	latency    time.Duration
+	err        error
+}
+
+// isHealthy returns true if the service responded as expected.
+func (result *serviceCheckResult) isHealthy() bool {
+	if result.err != nil {
+		return false
+	}
+	if result.check.requireStatus != 0 {
+		return result.statusCode == result.check.requireStatus
+	}
+	return result.statusCode < http.StatusInternalServerError
+}
+
+func init() {
+	o := statusServicesOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "services [flags]",
+		Short: "Check the reachability of the Metaplay portal, auth, and chart repository",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Check whether the upstream services used by the CLI are reachable and responding:
+			- The Metaplay portal (and the fallback portal URLs, if configured).
+			- The Metaplay Auth endpoints (and the project's custom auth providers, if any).
+			- The Helm chart repository (from metaplay-project.yaml, if in a project directory).
+
+			Use this to tell apart problems in your own setup (network, proxy, credentials) from
+			outages of the upstream services. The command exits with an error if any of the
+			services are unreachable.
+
+			Fallback portal URLs can be configured with the METAPLAYCLI_PORTAL_FALLBACK_BASEURLS
+			environment variable (comma-separated). When the primary portal is unreachable, the
+			first reachable fallback is used by all the commands.
+		`),
+		Example: renderExample(`
+			# Check the status of all the services.
+			metaplay status services
+
+			# Use a longer timeout for slow networks.
+			metaplay status services --timeout=15s
+		`),
+	}
+	statusCmd.AddCommand(cmd)
+
+	cmd.Flags().DurationVar(&o.flagTimeout, "timeout", 5*time.Second, "Timeout for each of the checks")
+}
+
+func (o *statusServicesOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagTimeout <= 0 {
+		return clierrors.NewUsageErrorf("Invalid --timeout value %v", o.flagTimeout).
+			WithSuggestion("Use a positive duration, e.g., '5s'")
+	}
+	return nil
+}
+
+func (o *statusServicesOpts) Run(cmd *cobra.Command) error {
+	// Try to resolve the project for the chart repository and custom auth providers.
+	project, err := tryResolveProject()
+	if err != nil {
+		log.Debug().Msgf("Failed to resolve project: %v", err)
+		project = nil
+	}
+
+	// Collect the services to check.
+	checks := []serviceCheck{}
+	for ndx, baseURL := range portalapi.GetPortalBaseURLs() {
+		name := "Portal"
+		if ndx > 0 {
+			name = fmt.Sprintf("Portal (fallback %d)", ndx)
+		}
+		checks = append(checks, serviceCheck{name: name, url: baseURL})
+	}
+
+	authProviders := []*auth.AuthProviderConfig{auth.NewMetaplayAuthProvider()}
+	helmChartRepo := defaultHelmChartRepository
+	if project != nil {
+		for _, authProvider := range project.Config.AuthProviders {
+			authProviders = append(authProviders, authProvider)
+		}
+		helmChartRepo = coalesceString(project.Config.HelmChartRepository, defaultHelmChartRepository)
+	}
+	for _, authProvider := range authProviders {
+		checks = append(checks, serviceCheck{name: fmt.Sprintf("Auth: %s", authProvider.Name), url: authProvider.TokenEndpoint})
+	}
+
+	checks = append(checks, helmChartRepositoryCheck(helmChartRepo))
+
+	// Run the checks concurrently.
+	results := make([]serviceCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for ndx, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statusCode, latency, err := httputil.ProbeURL(check.url, o.flagTimeout)
+			results[ndx] = serviceCheckResult{check: check, statusCode: statusCode, latency: latency, err: err}
+		}()
+	}
+	wg.Wait()
+
+	// Print the results.
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Metaplay Services Status"))
+	log.Info().Msg("")
+	numUnhealthy := 0
+	for _, result := range results {
+		log.Info().Msgf("%s %s %s", renderServiceCheckStatus(&result), result.check.name, styles.RenderMuted(result.check.url))
+		if !result.isHealthy() {
+			numUnhealthy++
+			if result.err != nil {
+				log.Info().Msgf("    %s", styles.RenderError(result.err.Error()))
+			}
+		}
+	}
+	log.Info().Msg("")
+
+	if common.PortalBaseURL != portalapi.ResolveBaseURL() {
+		log.Info().Msgf("Commands are using the fallback portal %s", styles.RenderTechnical(portalapi.ResolveBaseURL()))
+		log.Info().Msg("")
+	}
+
+	if numUnhealthy > 0 {
+		return clierrors.Newf("%d of %d services are unreachable or failing", numUnhealthy, len(results)).
+			WithSuggestion("If you can reach other websites, the problem is likely with the upstream service and not in your setup; retry later")
+	}
+
+	log.Info().Msg(styles.RenderSuccess("✅ All services are reachable"))
+	return nil
+}
+
+// helmChartRepositoryCheck returns the check for the Helm chart repository: the repository index
+// for http(s) repositories, or the registry API endpoint for OCI registries. The registry API
+// requires authentication, so any non-server-error response is accepted from it.
+func helmChartRepositoryCheck(helmChartRepo string) serviceCheck {
+	if helmutil.IsOCIChartRepository(helmChartRepo) {
+		registryHost, err := helmutil.GetOCIRegistryHost(helmChartRepo)
+		if err == nil {
+			return serviceCheck{name: "Helm chart repository", url: fmt.Sprintf("https://%s/v2/", registryHost)}
+		}
+	}
+	return serviceCheck{name: "Helm chart repository", url: strings.TrimSuffix(helmChartRepo, "/") + "/index.yaml", requireStatus: http.StatusOK}
+}
+
+// renderServiceCheckStatus renders the status of a check, eg, "✓ 200 (123ms)".
+func renderServiceCheckStatus(result *serviceCheckResult) string {
+	latency := result.latency.Round(time.Millisecond)
+	if result.err != nil {
+		return styles.RenderError(fmt.Sprintf("✗ unreachable (%v)", latency))
+	}
+	statusText := fmt.Sprintf("%d (%v)", result.statusCode, latency)
+	if !result.isHealthy() {
+		return styles.RenderError("✗ " + statusText)
+	}
+	return styles.RenderSuccess("✓ " + statusText)
+}
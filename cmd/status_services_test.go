@@ -0,0 +1,36 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceCheckResultIsHealthy(t *testing.T) {
+	anyStatus := serviceCheck{name: "Portal", url: "https://portal.metaplay.dev"}
+	requireOK := serviceCheck{name: "Helm chart repository", url: "https://charts.metaplay.dev/index.yaml", requireStatus: 200}
+
+	assert.True(t, (&serviceCheckResult{check: anyStatus, statusCode: 200}).isHealthy())
+	assert.True(t, (&serviceCheckResult{check: anyStatus, statusCode: 405}).isHealthy())
+	assert.False(t, (&serviceCheckResult{check: anyStatus, statusCode: 503}).isHealthy())
+	assert.False(t, (&serviceCheckResult{check: anyStatus, err: errors.New("connection refused")}).isHealthy())
+
+	assert.True(t, (&serviceCheckResult{check: requireOK, statusCode: 200}).isHealthy())
+	assert.False(t, (&serviceCheckResult{check: requireOK, statusCode: 404}).isHealthy())
+}
+
+func TestHelmChartRepositoryCheck(t *testing.T) {
+	check := helmChartRepositoryCheck("https://charts.metaplay.dev/")
+	assert.Equal(t, "https://charts.metaplay.dev/index.yaml", check.url)
+	assert.Equal(t, 200, check.requireStatus)
+
+	// OCI registries require authentication, so any non-server-error response is accepted.
+	check = helmChartRepositoryCheck("oci://registry.example.com/charts")
+	assert.Equal(t, "https://registry.example.com/v2/", check.url)
+	assert.Equal(t, 0, check.requireStatus)
+}
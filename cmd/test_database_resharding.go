@@ -12,7 +12,6 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	clierrors "github.com/metaplay/cli/internal/errors"
@@ -111,7 +110,10 @@ func (o *testDatabaseReshardingOpts) Run(cmd *cobra.Command) error {
 	}
 
 	projectID := project.Config.ProjectHumanID
-	serverImage := fmt.Sprintf("%s/server:test", strings.ToLower(projectID))
+	serverImage, err := resolveTestImageName(project, "server", "server")
+	if err != nil {
+		return err
+	}
 	integrationTestsConfig := project.Config.IntegrationTests
 
 	log.Info().Msg("")
@@ -7,13 +7,17 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/metaplay/cli/pkg/envapi"
 	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/styles"
 	"github.com/metaplay/cli/pkg/testutil"
@@ -30,32 +34,171 @@ type integrationTestCtx struct {
 	playwrightTsImage  string
 	playwrightNetImage string
 	config             *metaproj.IntegrationTestsConfig
+	testName           string                  // Name of the test when running tests in parallel, used to tell the containers and logs apart
+	logTail            *integrationTestLogTail // Last lines of the test's logs, for the report (only with --report-format)
+}
+
+// containerName returns the name of the test's container for the given component, eg,
+// 'lovely-wombats-build-test-botclient'. The test name is appended when running in parallel.
+func (testCtx integrationTestCtx) containerName(component string) string {
+	name := fmt.Sprintf("%s-test-%s", testCtx.project.Config.ProjectHumanID, component)
+	if testCtx.testName != "" {
+		name += "-" + testCtx.testName
+	}
+	return name
+}
+
+// logPrefix returns the prefix for the log lines of the given component (empty for the CLI's own
+// logs), eg, '[botclient] ', or '[bots] [botclient] ' when running in parallel.
+func (testCtx integrationTestCtx) logPrefix(component string) string {
+	prefix := ""
+	if testCtx.testName != "" {
+		prefix = fmt.Sprintf("[%s] ", testCtx.testName)
+	}
+	if component != "" {
+		prefix += fmt.Sprintf("[%s] ", component)
+	}
+	return prefix
+}
+
+// logWriter returns the writer for the container logs of the test, or nil for the default (stdout).
+// With --report-format, the logs are also captured for the excerpt in the report.
+func (testCtx integrationTestCtx) logWriter() io.Writer {
+	if testCtx.logTail == nil {
+		return nil
+	}
+	return io.MultiWriter(os.Stdout, testCtx.logTail)
+}
+
+// integrationTestTarget describes the game server that the test containers run against:
+// either a local background server container or a deployed cloud environment.
+type integrationTestTarget struct {
+	network           string // Docker network mode for the test containers (empty for default)
+	serverHost        string // Game server hostname for the bots
+	serverPort        int    // Game server port for the bots
+	enableTls         bool   // Whether the bots should use TLS to connect
+	cdnBaseURL        string // CDN base URL for the bots
+	dashboardURL      string // Base URL of the LiveOps Dashboard (and admin API)
+	environmentFamily string // Environment family passed to the botclient
+	accessToken       string // Access token for the admin API (empty for local server)
+}
+
+// newLocalIntegrationTestTarget returns the target for a background game server container.
+// The test containers share the server container's network namespace, so everything is
+// reachable via localhost.
+func newLocalIntegrationTestTarget(server *testutil.BackgroundGameServer) *integrationTestTarget {
+	return &integrationTestTarget{
+		network:           fmt.Sprintf("container:%s", server.ContainerName()),
+		serverHost:        "localhost",
+		serverPort:        9339,
+		enableTls:         false,
+		cdnBaseURL:        "http://localhost:5552/",
+		dashboardURL:      "http://localhost:5550",
+		environmentFamily: "Local",
+	}
+}
+
+// withAccessTokenEnv adds the admin API access token (if any) to the container environment
+// variables. The Playwright tests use it to authenticate against a cloud dashboard.
+func (target *integrationTestTarget) withAccessTokenEnv(env map[string]string) map[string]string {
+	if target.accessToken != "" {
+		env["METAPLAY_ACCESS_TOKEN"] = target.accessToken
+	}
+	return env
 }
 
 type integrationTest struct {
 	name        string
 	displayName string
-	run         func(ctx integrationTestCtx, server *testutil.BackgroundGameServer) error
+	run         func(ctx integrationTestCtx, target *integrationTestTarget) error
 }
 
 var integrationTests = []integrationTest{
-	{"bots", "Run botclient tests", func(testCtx integrationTestCtx, server *testutil.BackgroundGameServer) error {
-		return testCtx.opts.runBotTests(testCtx.ctx, testCtx.project, server, testCtx.serverImage, testCtx.config)
+	{"bots", "Run botclient tests", func(testCtx integrationTestCtx, target *integrationTestTarget) error {
+		return testCtx.opts.runBotTests(testCtx, target)
 	}},
-	{"dashboard", "Run dashboard Playwright tests", func(testCtx integrationTestCtx, server *testutil.BackgroundGameServer) error {
-		return testCtx.opts.runDashboardTests(testCtx.ctx, testCtx.project, server, testCtx.playwrightTsImage)
+	{"dashboard", "Run dashboard Playwright tests", func(testCtx integrationTestCtx, target *integrationTestTarget) error {
+		return testCtx.opts.runDashboardTests(testCtx, target)
 	}},
-	{"system", "Run Playwright.NET system tests", func(testCtx integrationTestCtx, server *testutil.BackgroundGameServer) error {
-		return testCtx.opts.runSystemTests(testCtx.ctx, testCtx.project, server, testCtx.playwrightNetImage)
+	{"system", "Run Playwright.NET system tests", func(testCtx integrationTestCtx, target *integrationTestTarget) error {
+		return testCtx.opts.runSystemTests(testCtx, target)
 	}},
 }
 
+// Names of the test-specific containers, which the custom tests must not use as their names.
+var integrationTestComponentNames = []string{"server", "botclient", "playwright-ts", "playwright-net"}
+
+// customIntegrationTest is a project-specific test container declared in metaplay-project.yaml.
+type customIntegrationTest struct {
+	config metaproj.CustomIntegrationTestConfig
+	image  string // Image to run: built from config.Target, or config.Image as-is
+}
+
+// resolveCustomIntegrationTests returns the custom tests declared in metaplay-project.yaml, with
+// the names of the images to build for the tests with a build target.
+func resolveCustomIntegrationTests(project *metaproj.MetaplayProject) ([]customIntegrationTest, error) {
+	if project.Config.IntegrationTests == nil {
+		return nil, nil
+	}
+
+	customTests := []customIntegrationTest{}
+	for _, config := range project.Config.IntegrationTests.Tests {
+		isBuiltin := slices.ContainsFunc(integrationTests, func(t integrationTest) bool { return t.name == config.Name })
+		if isBuiltin || slices.Contains(integrationTestComponentNames, config.Name) {
+			return nil, fmt.Errorf("integration test name '%s' in metaplay-project.yaml is reserved for the built-in tests, use another name", config.Name)
+		}
+
+		image := config.Image
+		if config.Target != "" {
+			var err error
+			image, err = resolveTestImageName(project, config.Name, config.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		customTests = append(customTests, customIntegrationTest{config: config, image: image})
+	}
+	return customTests, nil
+}
+
+// integrationTest returns the test for running the custom test container.
+func (customTest customIntegrationTest) integrationTest() integrationTest {
+	displayName := coalesceString(customTest.config.Description, fmt.Sprintf("Run custom test %s", customTest.config.Name))
+	return integrationTest{customTest.config.Name, displayName, func(testCtx integrationTestCtx, target *integrationTestTarget) error {
+		return testCtx.opts.runCustomTest(testCtx, target, customTest)
+	}}
+}
+
+// Outcomes of a single integration test.
+const (
+	integrationTestPassed   = "passed"
+	integrationTestFailed   = "failed"
+	integrationTestCanceled = "canceled" // Interrupted by a failure of another test (with --fail-fast) or the timeout
+	integrationTestSkipped  = "skipped"  // Not started due to a failure of another test (with --fail-fast) or the timeout
+)
+
+// integrationTestResult is the outcome of running a single integration test.
+type integrationTestResult struct {
+	test     integrationTest
+	status   string        // One of the integrationTest* outcomes
+	duration time.Duration // Time taken by the test, including starting the server
+	err      error         // Error of a failed test
+
+	logExcerpt string // Last lines of the logs of a failed test (only with --report-format)
+}
+
 type testIntegrationOpts struct {
 	flagSkipBuild    bool
 	flagDebugNetwork bool
 	flagOutputDir    string
 	flagTest         string
 	flagTimeout      time.Duration
+	flagEnvironment  string
+	flagParallel     int
+	flagFailFast     bool
+	flagReportFormat string
+
+	localEnv *metaproj.LocalEnvFile // Developer-specific environment variables for the containers
 }
 
 func init() {
@@ -84,7 +227,54 @@ func init() {
 			For each of the tests, the game server container is first started in the background and then
 			the test-specific container is run against the game server.
 
+			With --parallel=N, up to N tests are run at the same time, each with its own game server
+			container. The logs of the tests are interleaved, with each line prefixed by the test name.
+			By default, the run stops at the first failed test; use --fail-fast=false to run all the
+			tests regardless. A summary of the results and durations of the tests is printed at the end.
+
+			With --report-format, a machine-readable report of the results is written into the output
+			directory, so that CI systems can show the failed tests natively: 'junit' writes a JUnit XML
+			report (integration-test-report.xml) and 'json' a JSON report (integration-test-report.json).
+			The report includes the status and duration of each test, and an excerpt of the logs of the
+			failed tests.
+
+			With --environment, the tests are run against a game server deployed in a cloud environment
+			instead of a local server container. This is useful for post-deploy smoke tests in CI. You
+			must be signed in to the environment, eg, using 'metaplay auth machine-login' in CI. The bots
+			and Playwright tests connect to the environment's game server and LiveOps Dashboard using the
+			hostnames resolved from the environment's details. With --parallel, the tests share the
+			deployed game server instead of starting their own.
+
+			Developer-specific environment variables from the project's .metaplay/env.local.yaml (if
+			any) are injected into the game server and botclient containers.
+
 			Tests:`+testListLines.String()+`
+
+			Projects can declare their own test containers under 'integrationTests.tests' in
+			metaplay-project.yaml. The custom tests are run after the built-in ones, and can be
+			selected with --test like them. Each test is run from an image built from a stage
+			('target') of the SDK's Dockerfile.server or the project's own 'dockerfile', or from an
+			existing 'image', with the given 'command', 'env', and 'mounts' (host paths are relative
+			to the project directory). The Dockerfile is built in the build root directory, with the
+			same build arguments as the server image, eg, PROJECT_ROOT:
+
+			  integrationTests:
+			    tests:
+			      - name: api-smoke
+			        description: Run API smoke tests
+			        target: api-smoke-tests
+			        dockerfile: Tests/Dockerfile
+			        command: ["npm", "test"]
+			        env:
+			          API_TIMEOUT: "30s"
+			        mounts:
+			          - Tests/fixtures:/fixtures
+
+			The custom test containers get the game server's connection information in the environment
+			variables SERVER_HOST, SERVER_PORT, SERVER_ENABLE_TLS, CDN_BASE_URL, DASHBOARD_BASE_URL, and
+			METAPLAY_ENVIRONMENT_FAMILY (and METAPLAY_ACCESS_TOKEN with --environment). The results can
+			be written into the directory OUTPUT_DIRECTORY, which is stored under the output directory.
+			A test fails if its container exits with a non-zero code.
 		`),
 		Example: renderExample(`
 			# Run the full integration test pipeline
@@ -96,8 +286,17 @@ func init() {
 			# Run only the 'bots' test.
 			metaplay test integration --test=bots
 
+			# Run the tests in parallel, and don't stop at the first failure.
+			metaplay test integration --parallel=3 --fail-fast=false
+
+			# Write a JUnit XML report of the results for the CI system.
+			metaplay test integration --report-format=junit
+
 			# Run with a custom timeout (e.g., 30 minutes)
 			metaplay test integration --timeout=30m
+
+			# Run the tests against the game server deployed in the environment 'nimbly'.
+			metaplay test integration --environment=nimbly
 		`),
 	}
 
@@ -112,8 +311,12 @@ func init() {
 	for _, t := range integrationTests {
 		testNames = append(testNames, "'"+t.name+"'")
 	}
-	flags.StringVar(&o.flagTest, "test", "", "Run only the specified test ("+strings.Join(testNames, ", ")+")")
+	flags.StringVar(&o.flagTest, "test", "", "Run only the specified test ("+strings.Join(testNames, ", ")+", or a custom test from metaplay-project.yaml)")
 	flags.DurationVar(&o.flagTimeout, "timeout", 1*time.Hour, "Timeout for running tests (e.g., 30m, 1h, 2h30m). Does not apply to image builds.")
+	flags.StringVarP(&o.flagEnvironment, "environment", "e", "", "Run the tests against the game server in the given cloud environment instead of a local server container")
+	flags.IntVar(&o.flagParallel, "parallel", 1, "Number of tests to run in parallel, each with its own game server container")
+	flags.BoolVar(&o.flagFailFast, "fail-fast", true, "Stop the test run at the first failed test (use --fail-fast=false to run all the tests)")
+	flags.StringVar(&o.flagReportFormat, "report-format", "", "Write a report of the test results into the output directory (junit or json)")
 	_ = flags.MarkDeprecated("only", "use --tests instead")
 }
 
@@ -121,21 +324,17 @@ func (o *testIntegrationOpts) Prepare(cmd *cobra.Command, args []string) error {
 	if o.flagTimeout <= 0 {
 		return fmt.Errorf("--timeout must be a positive duration (e.g., 30m, 1h)")
 	}
-	if o.flagTest != "" {
-		found := false
-		for _, t := range integrationTests {
-			if t.name == o.flagTest {
-				found = true
-				break
-			}
-		}
-		if !found {
-			var names []string
-			for _, t := range integrationTests {
-				names = append(names, t.name)
-			}
-			return fmt.Errorf("unknown test '%s'. Available tests: %s", o.flagTest, strings.Join(names, ", "))
-		}
+	if o.flagEnvironment != "" && o.flagDebugNetwork {
+		return fmt.Errorf("--debug-network cannot be used with --environment")
+	}
+	if o.flagParallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+	if o.flagParallel > 1 && o.flagDebugNetwork {
+		return fmt.Errorf("--debug-network cannot be used with --parallel")
+	}
+	if o.flagReportFormat != "" && o.flagReportFormat != integrationTestReportJUnit && o.flagReportFormat != integrationTestReportJSON {
+		return fmt.Errorf("invalid --report-format '%s', must be '%s' or '%s'", o.flagReportFormat, integrationTestReportJUnit, integrationTestReportJSON)
 	}
 	return nil
 }
@@ -149,21 +348,71 @@ func (o *testIntegrationOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Derive container image names once and pass them to phases
-	projectID := strings.ToLower(project.Config.ProjectHumanID)
-	serverImage := fmt.Sprintf("%s/server:test", projectID)
-	pwTsImage := fmt.Sprintf("%s/playwright-ts:test", projectID)
-	pwNetImage := fmt.Sprintf("%s/playwright-net:test", projectID)
+	serverImage, err := resolveTestImageName(project, "server", "server")
+	if err != nil {
+		return err
+	}
+	pwTsImage, err := resolveTestImageName(project, "dashboard-tests", "playwright-ts")
+	if err != nil {
+		return err
+	}
+	pwNetImage, err := resolveTestImageName(project, "playwright-net", "playwright-net")
+	if err != nil {
+		return err
+	}
 
 	// Get integration tests config (may be nil if not specified)
 	integrationTestsConfig := project.Config.IntegrationTests
 
-	// Build the list of tests to run, filtered by --test if specified.
+	// Load the developer-specific environment variables for the containers.
+	o.localEnv, err = loadLocalEnvFile(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	// Build the list of tests to run, the built-in tests followed by the custom ones from
+	// metaplay-project.yaml, filtered by --test if specified.
+	customTests, err := resolveCustomIntegrationTests(project)
+	if err != nil {
+		return err
+	}
+	allTests := slices.Clone(integrationTests)
+	for _, customTest := range customTests {
+		allTests = append(allTests, customTest.integrationTest())
+	}
 	var tests []integrationTest
-	for _, t := range integrationTests {
+	for _, t := range allTests {
 		if o.flagTest == "" || o.flagTest == t.name {
 			tests = append(tests, t)
 		}
 	}
+	if len(tests) == 0 {
+		var names []string
+		for _, t := range allTests {
+			names = append(names, t.name)
+		}
+		return fmt.Errorf("unknown test '%s'. Available tests: %s", o.flagTest, strings.Join(names, ", "))
+	}
+
+	// Only build the images of the custom tests that are run.
+	var customTestsToBuild []customIntegrationTest
+	for _, customTest := range customTests {
+		if customTest.config.Target != "" && (o.flagTest == "" || o.flagTest == customTest.config.Name) {
+			customTestsToBuild = append(customTestsToBuild, customTest)
+		}
+	}
+
+	// Resolve the cloud environment to test against (if specified).
+	var remoteTarget *integrationTestTarget
+	var targetEnvName string
+	if o.flagEnvironment != "" {
+		envConfig, target, err := o.resolveRemoteTarget(ctx, project)
+		if err != nil {
+			return err
+		}
+		remoteTarget = target
+		targetEnvName = fmt.Sprintf("%s [%s]", envConfig.Name, envConfig.HumanID)
+	}
 
 	log.Info().Msg("")
 	log.Info().Msg(styles.RenderTitle("Run Integration Tests"))
@@ -213,6 +462,13 @@ func (o *testIntegrationOpts) Run(cmd *cobra.Command) error {
 		testsToRun = o.flagTest
 	}
 	log.Info().Msgf("Tests to run:           %s", styles.RenderTechnical(testsToRun))
+	log.Info().Msgf("Parallel tests:         %s", styles.RenderTechnical(fmt.Sprintf("%d", o.flagParallel)))
+	log.Info().Msgf("Fail fast:              %s", styles.RenderTechnical(fmt.Sprintf("%v", o.flagFailFast)))
+	if remoteTarget != nil {
+		log.Info().Msgf("Target environment:     %s", styles.RenderTechnical(targetEnvName))
+	} else {
+		log.Info().Msgf("Target environment:     %s", styles.RenderTechnical("local server container"))
+	}
 	log.Info().Msgf("Test output directory:  %s", styles.RenderTechnical(o.flagOutputDir))
 	log.Info().Msgf("Timeout:                %s", styles.RenderTechnical(o.flagTimeout.String()))
 
@@ -224,7 +480,7 @@ func (o *testIntegrationOpts) Run(cmd *cobra.Command) error {
 	// Build the container images first (not subject to --timeout but still
 	// cancelable via Ctrl+C through cmd.Context()).
 	if !o.flagSkipBuild {
-		if err := o.buildDockerImages(ctx, project, serverImage, pwTsImage, pwNetImage, integrationTestsConfig); err != nil {
+		if err := o.buildDockerImages(ctx, project, serverImage, pwTsImage, pwNetImage, customTestsToBuild, integrationTestsConfig); err != nil {
 			return fmt.Errorf("failed to build container images: %w", err)
 		}
 	} else {
@@ -248,26 +504,34 @@ func (o *testIntegrationOpts) Run(cmd *cobra.Command) error {
 		config:             integrationTestsConfig,
 	}
 
-	// Run all the active tests.
-	for _, t := range tests {
-		// Check if the timeout has been reached
-		if testRunCtx.Err() != nil {
-			return fmt.Errorf("test run timed out after %s", o.flagTimeout)
-		}
+	// Run all the active tests and summarize the results.
+	startTime := time.Now()
+	results := o.runIntegrationTests(testCtx, tests, remoteTarget)
+	printIntegrationTestSummary(results)
 
+	// Write the report for the CI system.
+	if o.flagReportFormat != "" {
+		reportPath, err := writeIntegrationTestReport(o.flagOutputDir, o.flagReportFormat, results, startTime, time.Since(startTime))
+		if err != nil {
+			return err
+		}
 		log.Info().Msg("")
-		log.Info().Msgf("%s %s: %s", styles.RenderBright("🔷"), styles.RenderTechnical(t.name), styles.RenderBright(t.displayName))
-		log.Info().Msg("")
+		log.Info().Msgf("Test report written to %s", styles.RenderTechnical(reportPath))
+	}
 
-		runFn := t.run
-		if err := o.runTestCase(testRunCtx, project, serverImage, integrationTestsConfig, t.displayName, func(server *testutil.BackgroundGameServer) error {
-			return runFn(testCtx, server)
-		}); err != nil {
-			return fmt.Errorf("test '%s' failed: %w", t.displayName, err)
+	if testRunCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("test run timed out after %s", o.flagTimeout)
+	}
+	var failed []integrationTestResult
+	for _, result := range results {
+		if result.status == integrationTestFailed {
+			failed = append(failed, result)
 		}
-
-		log.Info().Msg("")
-		log.Info().Msgf("%s Test %s successful", styles.RenderSuccess("✓"), styles.RenderTechnical(t.name))
+	}
+	if len(failed) == 1 {
+		return fmt.Errorf("test '%s' failed: %w", failed[0].test.displayName, failed[0].err)
+	} else if len(failed) > 1 {
+		return fmt.Errorf("%d integration tests failed", len(failed))
 	}
 
 	log.Info().Msg("")
@@ -275,33 +539,198 @@ func (o *testIntegrationOpts) Run(cmd *cobra.Command) error {
 	return nil
 }
 
+// runIntegrationTests runs the tests, up to --parallel of them at a time. With --fail-fast, the
+// running tests are canceled and the remaining ones skipped after the first failure. Returns the
+// results in the order of the tests.
+func (o *testIntegrationOpts) runIntegrationTests(testCtx integrationTestCtx, tests []integrationTest, remoteTarget *integrationTestTarget) []integrationTestResult {
+	results := make([]integrationTestResult, len(tests))
+	for ndx, t := range tests {
+		results[ndx] = integrationTestResult{test: t, status: integrationTestSkipped}
+	}
+
+	// Canceled on the first failure with --fail-fast (and on timeout, via the parent context).
+	runCtx, cancelRun := context.WithCancel(testCtx.ctx)
+	defer cancelRun()
+
+	parallel := o.flagParallel > 1 && len(tests) > 1
+	slots := make(chan struct{}, o.flagParallel)
+	var wg sync.WaitGroup
+	for ndx, t := range tests {
+		// Wait for a free slot, unless the run has been stopped.
+		select {
+		case slots <- struct{}{}:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			runTestCtx := testCtx
+			runTestCtx.ctx = runCtx
+			if parallel {
+				runTestCtx.testName = t.name
+			}
+			if o.flagReportFormat != "" {
+				runTestCtx.logTail = newIntegrationTestLogTail(integrationTestLogExcerptLines)
+			}
+			results[ndx] = o.runIntegrationTest(runTestCtx, t, remoteTarget)
+			if results[ndx].status == integrationTestFailed && o.flagFailFast {
+				cancelRun()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runIntegrationTest runs a single test against the remote target, or a background game server
+// container started for the test.
+func (o *testIntegrationOpts) runIntegrationTest(testCtx integrationTestCtx, t integrationTest, remoteTarget *integrationTestTarget) integrationTestResult {
+	prefix := testCtx.logPrefix("")
+	log.Info().Msg("")
+	log.Info().Msgf("%s%s %s: %s", prefix, styles.RenderBright("🔷"), styles.RenderTechnical(t.name), styles.RenderBright(t.displayName))
+	log.Info().Msg("")
+
+	startTime := time.Now()
+	var testErr error
+	if remoteTarget != nil {
+		testErr = t.run(testCtx, remoteTarget)
+	} else {
+		testErr = o.runTestCase(testCtx, func(server *testutil.BackgroundGameServer) error {
+			return t.run(testCtx, newLocalIntegrationTestTarget(server))
+		})
+	}
+	result := integrationTestResult{test: t, duration: time.Since(startTime), err: testErr}
+
+	switch {
+	case testErr == nil:
+		result.status = integrationTestPassed
+		log.Info().Msg("")
+		log.Info().Msgf("%s%s Test %s successful", prefix, styles.RenderSuccess("✓"), styles.RenderTechnical(t.name))
+	case testCtx.ctx.Err() != nil:
+		// Interrupted by another test's failure or the timeout, rather than failed on its own.
+		result.status = integrationTestCanceled
+		log.Info().Msg("")
+		log.Info().Msgf("%s%s Test %s canceled", prefix, styles.RenderWarning("-"), styles.RenderTechnical(t.name))
+	default:
+		result.status = integrationTestFailed
+		if testCtx.logTail != nil {
+			result.logExcerpt = testCtx.logTail.String()
+		}
+		log.Info().Msg("")
+		log.Info().Msgf("%s%s Test %s failed: %v", prefix, styles.RenderError("✗"), styles.RenderTechnical(t.name), testErr)
+	}
+	return result
+}
+
+// printIntegrationTestSummary prints a table of the results and durations of the tests.
+func printIntegrationTestSummary(results []integrationTestResult) {
+	nameW := len("TEST")
+	for _, result := range results {
+		nameW = max(nameW, len(result.test.name))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Test Summary"))
+	log.Info().Msg("")
+	log.Info().Msgf("  %-*s  %-8s  %s", nameW, "TEST", "RESULT", "DURATION")
+	for _, result := range results {
+		duration := "-"
+		if result.status != integrationTestSkipped {
+			duration = result.duration.Round(time.Second).String()
+		}
+
+		// Pad plain text before applying ANSI styles.
+		status := fmt.Sprintf("%-8s", result.status)
+		switch result.status {
+		case integrationTestPassed:
+			status = styles.RenderSuccess(status)
+		case integrationTestFailed:
+			status = styles.RenderError(status)
+		default:
+			status = styles.RenderMuted(status)
+		}
+		log.Info().Msgf("  %-*s  %s  %s", nameW, result.test.name, status, duration)
+	}
+}
+
+// resolveRemoteTarget resolves the cloud environment to run the tests against and returns
+// the connection information for the test containers.
+func (o *testIntegrationOpts) resolveRemoteTarget(ctx context.Context, project *metaproj.MetaplayProject) (*metaproj.ProjectEnvironmentConfig, *integrationTestTarget, error) {
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.flagEnvironment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get environment details from the StackAPI.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	envDetails, err := targetEnv.GetDetails()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Strip the trailing dot from the hostnames: older botclients include it in the SNI
+	// request, which fails with the ingress routing. The plain name works for all versions.
+	serverHostname := strings.TrimRight(envDetails.Deployment.ServerHostname, ".")
+	adminHostname := strings.TrimRight(envDetails.Deployment.AdminHostname, ".")
+
+	return envConfig, &integrationTestTarget{
+		network:           "",
+		serverHost:        serverHostname,
+		serverPort:        9339, // \todo should use envDetails.Deployment.ServerPorts but its occasionally empty
+		enableTls:         true,
+		cdnBaseURL:        fmt.Sprintf("https://%s/", envDetails.Deployment.CdnS3Fqdn),
+		dashboardURL:      fmt.Sprintf("https://%s", adminHostname),
+		environmentFamily: envConfig.GetEnvironmentFamily(),
+		accessToken:       tokenSet.AccessToken,
+	}, nil
+}
+
 // runTestCase starts a background game server, runs the provided test function, and then stops the server.
-func (o *testIntegrationOpts) runTestCase(ctx context.Context, project *metaproj.MetaplayProject, serverImage string, integrationTestsConfig *metaproj.IntegrationTestsConfig, displayName string, fn func(*testutil.BackgroundGameServer) error) error {
+func (o *testIntegrationOpts) runTestCase(testCtx integrationTestCtx, fn func(*testutil.BackgroundGameServer) error) error {
+	ctx := testCtx.ctx
+	project := testCtx.project
+	serverImage := testCtx.serverImage
+	integrationTestsConfig := testCtx.config
+	prefix := testCtx.logPrefix("")
+
 	// Build server options with any custom configuration
 	serverOpts := testutil.GameServerOptions{
 		Image:         serverImage,
-		ContainerName: fmt.Sprintf("%s-test-server", project.Config.ProjectHumanID),
+		ContainerName: testCtx.containerName("server"),
+		LogPrefix:     testCtx.logPrefix("server"),
+		LogWriter:     testCtx.logWriter(),
 	}
 	if integrationTestsConfig != nil && integrationTestsConfig.Server != nil {
 		serverOpts.ExtraArgs = integrationTestsConfig.Server.Args
-		serverOpts.ExtraEnv = integrationTestsConfig.Server.Env
+		serverOpts.ExtraEnv = maps.Clone(integrationTestsConfig.Server.Env)
+	}
+	if serverOpts.ExtraEnv == nil {
+		serverOpts.ExtraEnv = map[string]string{}
 	}
+	maps.Copy(serverOpts.ExtraEnv, o.localEnv.ServerEnv())
 
 	// Create and start the background server for this test
 	server := testutil.NewGameServer(serverOpts)
 
-	log.Info().Msg("Starting background game server...")
+	log.Info().Msgf("%sStarting background game server...", prefix)
 	if err := server.Start(ctx); err != nil {
 		return fmt.Errorf("failed to start background server: %w", err)
 	}
 	defer func() {
-		log.Info().Msg("Shutting down background server...")
+		log.Info().Msgf("%sShutting down background server...", prefix)
 		if shutdownErr := server.Shutdown(context.Background()); shutdownErr != nil {
-			log.Error().Msgf("Failed to shutdown background server: %v", shutdownErr)
+			log.Error().Msgf("%sFailed to shutdown background server: %v", prefix, shutdownErr)
 		}
 	}()
 
-	log.Info().Msgf("Background server started at %s", server.BaseURL().String())
+	log.Info().Msgf("%sBackground server started at %s", prefix, server.BaseURL().String())
 
 	// Optional: run network debug checks
 	if o.flagDebugNetwork {
@@ -319,14 +748,17 @@ func (o *testIntegrationOpts) runTestCase(ctx context.Context, project *metaproj
 }
 
 // runBotTests runs the botclient against the already-running server.
-func (o *testIntegrationOpts) runBotTests(ctx context.Context, project *metaproj.MetaplayProject, server *testutil.BackgroundGameServer, imageName string, integrationTestsConfig *metaproj.IntegrationTestsConfig) error {
+func (o *testIntegrationOpts) runBotTests(testCtx integrationTestCtx, target *integrationTestTarget) error {
+	integrationTestsConfig := testCtx.config
+
 	// Build default env and merge any extra env vars
 	botEnv := map[string]string{
-		"METAPLAY_ENVIRONMENT_FAMILY": "Local",
+		"METAPLAY_ENVIRONMENT_FAMILY": target.environmentFamily,
 	}
 	if integrationTestsConfig != nil && integrationTestsConfig.BotClient != nil {
 		maps.Copy(botEnv, integrationTestsConfig.BotClient.Env)
 	}
+	maps.Copy(botEnv, o.localEnv.BotClientEnv())
 
 	// Build default cmd and append any extra args
 	botCmd := []string{
@@ -336,30 +768,33 @@ func (o *testIntegrationOpts) runBotTests(ctx context.Context, project *metaproj
 		"--Environment:EnableKeyboardInput=false",
 		"--Environment:ExitOnLogError=true",
 		// Bot-specific configuration
-		"--Bot:ServerHost=localhost",
-		"--Bot:ServerPort=9339",
-		"--Bot:EnableTls=false",
-		"--Bot:CdnBaseUrl=http://localhost:5552/",
-		"-ExitAfter=00:00:30",               // Run for 30 seconds (.NET TimeSpan format)
-		"-MaxBots=10",                       // Spawn up to 10 bots
-		"-SpawnRate=2",                      // Spawn 2 bots per second
-		"-ExpectedSessionDuration=00:00:10", // Each bot session lasts ~10 seconds (.NET TimeSpan format)
+		fmt.Sprintf("--Bot:ServerHost=%s", target.serverHost),
+		fmt.Sprintf("--Bot:ServerPort=%d", target.serverPort),
+		fmt.Sprintf("--Bot:EnableTls=%t", target.enableTls),
+		fmt.Sprintf("--Bot:CdnBaseUrl=%s", target.cdnBaseURL),
+		"-ExitAfter=00:00:30", // Run for 30 seconds (.NET TimeSpan format)
 	}
+	botCmd = append(botCmd, botClientLoadOptions{
+		MaxBots:         10,               // Spawn up to 10 bots
+		SpawnRate:       2,                // Spawn 2 bots per second
+		SessionDuration: 10 * time.Second, // Each bot session lasts ~10 seconds
+	}.toArgs()...)
 	if integrationTestsConfig != nil && integrationTestsConfig.BotClient != nil {
 		botCmd = append(botCmd, integrationTestsConfig.BotClient.Args...)
 	}
 
 	botClientOpts := testutil.RunOnceContainerOptions{
-		Image:         imageName,
-		ContainerName: fmt.Sprintf("%s-test-botclient", project.Config.ProjectHumanID),
-		LogPrefix:     "[botclient] ",
-		Network:       fmt.Sprintf("container:%s", server.ContainerName()),
+		Image:         testCtx.serverImage,
+		ContainerName: testCtx.containerName("botclient"),
+		LogPrefix:     testCtx.logPrefix("botclient"),
+		LogWriter:     testCtx.logWriter(),
+		Network:       target.network,
 		Env:           botEnv,
 		Cmd:           botCmd,
 	}
 
 	botClient := testutil.NewRunOnceContainer(botClientOpts)
-	exitCode, err := botClient.Run(ctx)
+	exitCode, err := botClient.Run(testCtx.ctx)
 	if err != nil {
 		return fmt.Errorf("botclient failed to run: %w", err)
 	}
@@ -372,7 +807,7 @@ func (o *testIntegrationOpts) runBotTests(ctx context.Context, project *metaproj
 }
 
 // runDashboardTests runs the Playwright TypeScript tests against the dashboard.
-func (o *testIntegrationOpts) runDashboardTests(ctx context.Context, project *metaproj.MetaplayProject, server *testutil.BackgroundGameServer, imageName string) error {
+func (o *testIntegrationOpts) runDashboardTests(testCtx integrationTestCtx, target *integrationTestTarget) error {
 	// Create output directory for dashboard test results.
 	resultsDir := filepath.ToSlash(filepath.Join(o.flagOutputDir, "dashboard"))
 	if err := os.MkdirAll(resultsDir, 0755); err != nil {
@@ -388,15 +823,16 @@ func (o *testIntegrationOpts) runDashboardTests(ctx context.Context, project *me
 	absResultsDir = filepath.ToSlash(absResultsDir)
 
 	playwrightOpts := testutil.RunOnceContainerOptions{
-		Image:         imageName,
-		ContainerName: fmt.Sprintf("%s-test-playwright-ts", project.Config.ProjectHumanID),
-		LogPrefix:     "[playwright-ts] ",
-		Network:       fmt.Sprintf("container:%s", server.ContainerName()),
-		Env: map[string]string{
-			"DASHBOARD_BASE_URL": "http://localhost:5550",
+		Image:         testCtx.playwrightTsImage,
+		ContainerName: testCtx.containerName("playwright-ts"),
+		LogPrefix:     testCtx.logPrefix("playwright-ts"),
+		LogWriter:     testCtx.logWriter(),
+		Network:       target.network,
+		Env: target.withAccessTokenEnv(map[string]string{
+			"DASHBOARD_BASE_URL": target.dashboardURL,
 			"CI":                 "true",
 			"OUTPUT_DIRECTORY":   "/PlaywrightOutput",
-		},
+		}),
 		Mounts: []string{
 			fmt.Sprintf("%s:/PlaywrightOutput", absResultsDir),
 		},
@@ -404,7 +840,7 @@ func (o *testIntegrationOpts) runDashboardTests(ctx context.Context, project *me
 
 	// Run the Playwright tests container.
 	playwright := testutil.NewRunOnceContainer(playwrightOpts)
-	exitCode, err := playwright.Run(ctx)
+	exitCode, err := playwright.Run(testCtx.ctx)
 	if err != nil {
 		return fmt.Errorf("playwright tests failed to run: %w", err)
 	}
@@ -417,7 +853,7 @@ func (o *testIntegrationOpts) runDashboardTests(ctx context.Context, project *me
 }
 
 // runSystemTests runs the Playwright .NET tests for system testing.
-func (o *testIntegrationOpts) runSystemTests(ctx context.Context, project *metaproj.MetaplayProject, server *testutil.BackgroundGameServer, imageName string) error {
+func (o *testIntegrationOpts) runSystemTests(testCtx integrationTestCtx, target *integrationTestTarget) error {
 	// Create output directory for system test results.
 	resultsDir := filepath.ToSlash(filepath.Join(o.flagOutputDir, "system"))
 	if err := os.MkdirAll(resultsDir, 0755); err != nil {
@@ -433,14 +869,15 @@ func (o *testIntegrationOpts) runSystemTests(ctx context.Context, project *metap
 	absResultsDir = filepath.ToSlash(absResultsDir)
 
 	playwrightOpts := testutil.RunOnceContainerOptions{
-		Image:         imageName,
-		ContainerName: fmt.Sprintf("%s-test-playwright-net", project.Config.ProjectHumanID),
-		LogPrefix:     "[playwright-net] ",
-		Network:       fmt.Sprintf("container:%s", server.ContainerName()),
-		Env: map[string]string{
-			"DASHBOARD_BASE_URL": "http://localhost:5550",
+		Image:         testCtx.playwrightNetImage,
+		ContainerName: testCtx.containerName("playwright-net"),
+		LogPrefix:     testCtx.logPrefix("playwright-net"),
+		LogWriter:     testCtx.logWriter(),
+		Network:       target.network,
+		Env: target.withAccessTokenEnv(map[string]string{
+			"DASHBOARD_BASE_URL": target.dashboardURL,
 			"OUTPUT_DIRECTORY":   "/PlaywrightOutput",
-		},
+		}),
 		Mounts: []string{
 			fmt.Sprintf("%s:/PlaywrightOutput", absResultsDir),
 		},
@@ -448,7 +885,7 @@ func (o *testIntegrationOpts) runSystemTests(ctx context.Context, project *metap
 
 	// Run the Playwright .NET tests container.
 	playwright := testutil.NewRunOnceContainer(playwrightOpts)
-	exitCode, err := playwright.Run(ctx)
+	exitCode, err := playwright.Run(testCtx.ctx)
 	if err != nil {
 		return fmt.Errorf("playwright system tests failed to run: %w", err)
 	}
@@ -460,6 +897,69 @@ func (o *testIntegrationOpts) runSystemTests(ctx context.Context, project *metap
 	return nil
 }
 
+// runCustomTest runs the container of a custom test from metaplay-project.yaml against the target.
+func (o *testIntegrationOpts) runCustomTest(testCtx integrationTestCtx, target *integrationTestTarget, customTest customIntegrationTest) error {
+	name := customTest.config.Name
+
+	// Create output directory for the test results.
+	resultsDir := filepath.Join(o.flagOutputDir, name)
+	if err := os.MkdirAll(resultsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", resultsDir, err)
+	}
+	absResultsDir, err := filepath.Abs(resultsDir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %w", resultsDir, err)
+	}
+
+	// Mount the output directory and the configured host paths (relative to the project).
+	mounts := []string{fmt.Sprintf("%s:/TestOutput", filepath.ToSlash(absResultsDir))}
+	for _, mount := range customTest.config.Mounts {
+		hostPath, containerPath, _ := metaproj.SplitIntegrationTestMount(mount)
+		if !filepath.IsAbs(hostPath) {
+			hostPath = filepath.Join(testCtx.project.RelativeDir, hostPath)
+		}
+		absHostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path for %s: %w", hostPath, err)
+		}
+		mounts = append(mounts, fmt.Sprintf("%s:%s", filepath.ToSlash(absHostPath), containerPath))
+	}
+
+	// Pass the connection information of the game server, with the configured env on top.
+	env := target.withAccessTokenEnv(map[string]string{
+		"SERVER_HOST":                 target.serverHost,
+		"SERVER_PORT":                 fmt.Sprintf("%d", target.serverPort),
+		"SERVER_ENABLE_TLS":           fmt.Sprintf("%t", target.enableTls),
+		"CDN_BASE_URL":                target.cdnBaseURL,
+		"DASHBOARD_BASE_URL":          target.dashboardURL,
+		"METAPLAY_ENVIRONMENT_FAMILY": target.environmentFamily,
+		"CI":                          "true",
+		"OUTPUT_DIRECTORY":            "/TestOutput",
+	})
+	maps.Copy(env, customTest.config.Env)
+
+	customOpts := testutil.RunOnceContainerOptions{
+		Image:         customTest.image,
+		ContainerName: testCtx.containerName(name),
+		LogPrefix:     testCtx.logPrefix(name),
+		LogWriter:     testCtx.logWriter(),
+		Network:       target.network,
+		Env:           env,
+		Cmd:           customTest.config.Command,
+		Mounts:        mounts,
+	}
+
+	// Run the test container.
+	exitCode, err := testutil.NewRunOnceContainer(customOpts).Run(testCtx.ctx)
+	if err != nil {
+		return fmt.Errorf("%s test failed to run: %w", name, err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("%s test failed with exit code: %d", name, exitCode)
+	}
+	return nil
+}
+
 // debugNetworkConnectivity runs network tests to help diagnose connectivity issues to the game server container.
 // These tests are run in containers to simulate the same networking as the other test containers will use.
 func (o *testIntegrationOpts) debugNetworkConnectivity(ctx context.Context, project *metaproj.MetaplayProject, server *testutil.BackgroundGameServer, serverImage string) error {
@@ -540,7 +1040,7 @@ func (o *testIntegrationOpts) debugNetworkConnectivity(ctx context.Context, proj
 // the server image, and additional testing images for Playwright.
 // Note: Docker builds are not subject to the --timeout flag as cancelling them mid-build
 // is complex and unreliable. Builds are typically fast when cached.
-func (o *testIntegrationOpts) buildDockerImages(ctx context.Context, project *metaproj.MetaplayProject, serverImage, pwTsImage, pwNetImage string, integrationTestsConfig *metaproj.IntegrationTestsConfig) error {
+func (o *testIntegrationOpts) buildDockerImages(ctx context.Context, project *metaproj.MetaplayProject, serverImage, pwTsImage, pwNetImage string, customTests []customIntegrationTest, integrationTestsConfig *metaproj.IntegrationTestsConfig) error {
 	// Determine build engine
 	// \todo allow specifying this with a flag?
 	buildEngine := "buildkit"
@@ -578,7 +1078,7 @@ func (o *testIntegrationOpts) buildDockerImages(ctx context.Context, project *me
 	log.Info().Msg(styles.RenderBright("🔷 Build Playwright (TypeScript) test image"))
 	pwTsParams := commonParams
 	pwTsParams.imageName = pwTsImage
-	pwTsParams.target = "playwright-ts-tests"
+	pwTsParams.target = dockerStagePlaywrightTsTests
 	if err := buildDockerImage(ctx, pwTsParams); err != nil {
 		return fmt.Errorf("failed to build playwright-ts image: %w", err)
 	}
@@ -588,14 +1088,41 @@ func (o *testIntegrationOpts) buildDockerImages(ctx context.Context, project *me
 	log.Info().Msg(styles.RenderBright("🔷 Build Playwright.NET test image"))
 	pwNetParams := commonParams
 	pwNetParams.imageName = pwNetImage
-	pwNetParams.target = "playwright-net-tests"
+	pwNetParams.target = dockerStagePlaywrightNetTests
 	if err := buildDockerImage(ctx, pwNetParams); err != nil {
 		return fmt.Errorf("failed to build playwright-net image: %w", err)
 	}
 
+	// Build the images of the custom tests from their targets in the project's Dockerfile.
+	for _, customTest := range customTests {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderBright(fmt.Sprintf("🔷 Build %s test image", customTest.config.Name)))
+		customParams := commonParams
+		customParams.imageName = customTest.image
+		customParams.target = customTest.config.Target
+		if customTest.config.Dockerfile != "" {
+			customParams.dockerFile = filepath.Join(project.RelativeDir, customTest.config.Dockerfile)
+		}
+		if err := buildDockerImage(ctx, customParams); err != nil {
+			return fmt.Errorf("failed to build %s test image: %w", customTest.config.Name, err)
+		}
+	}
+
 	return nil
 }
 
+// resolveTestImageName resolves the name of a locally built image used in the tests. The
+// name comes from the 'imageNaming' template in metaplay-project.yaml with the build target
+// as the component, or is '<projectID>/<defaultComponent>' if no template is specified. The
+// tag is always 'test'.
+func resolveTestImageName(project *metaproj.MetaplayProject, target, defaultComponent string) (string, error) {
+	if project.Config.ImageNaming == "" {
+		return fmt.Sprintf("%s/%s:test", strings.ToLower(project.Config.ProjectHumanID), defaultComponent), nil
+	}
+
+	return resolveProjectImageName(project, target, "test")
+}
+
 // dockerSupportsBuildx returns true if docker buildx is available.
 func dockerSupportsBuildx(ctx context.Context) bool {
 	if ctx.Err() != nil {
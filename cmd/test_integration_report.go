@@ -0,0 +1,221 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported formats for --report-format.
+const (
+	integrationTestReportJUnit = "junit"
+	integrationTestReportJSON  = "json"
+)
+
+// Number of the last log lines of a failed test to include in the report.
+const integrationTestLogExcerptLines = 100
+
+// integrationTestLogTail keeps the last lines of the logs written to it, for including an excerpt
+// of the logs of failed tests in the report. Safe for concurrent use, as the game server and the
+// test container logs are written from different goroutines.
+type integrationTestLogTail struct {
+	mu       sync.Mutex
+	maxLines int
+	lines    []string
+	partial  string // Last line, not yet terminated by a newline
+}
+
+func newIntegrationTestLogTail(maxLines int) *integrationTestLogTail {
+	return &integrationTestLogTail{maxLines: maxLines}
+}
+
+// Write implements io.Writer.
+func (t *integrationTestLogTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := strings.Split(t.partial+string(p), "\n")
+	t.partial = lines[len(lines)-1]
+	t.lines = append(t.lines, lines[:len(lines)-1]...)
+	if len(t.lines) > t.maxLines {
+		t.lines = t.lines[len(t.lines)-t.maxLines:]
+	}
+	return len(p), nil
+}
+
+// String returns the last lines written, including any unterminated last line.
+func (t *integrationTestLogTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := t.lines
+	if t.partial != "" {
+		lines = append(lines[:len(lines):len(lines)], t.partial)
+	}
+	if len(lines) > t.maxLines {
+		lines = lines[len(lines)-t.maxLines:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON report.
+
+type integrationTestJSONReport struct {
+	Status          string                    `json:"status"` // "passed" or "failed"
+	DurationSeconds float64                   `json:"durationSeconds"`
+	Tests           []integrationTestJSONCase `json:"tests"`
+}
+
+type integrationTestJSONCase struct {
+	Name            string  `json:"name"`
+	DisplayName     string  `json:"displayName"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+	LogExcerpt      string  `json:"logExcerpt,omitempty"`
+}
+
+// JUnit XML report, in the format understood by most CI systems.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// integrationTestReportFileName returns the name of the report file in the output directory.
+func integrationTestReportFileName(format string) string {
+	if format == integrationTestReportJUnit {
+		return "integration-test-report.xml"
+	}
+	return "integration-test-report.json"
+}
+
+// renderIntegrationTestReport renders the test results in the given report format.
+func renderIntegrationTestReport(format string, results []integrationTestResult, startTime time.Time, totalDuration time.Duration) ([]byte, error) {
+	switch format {
+	case integrationTestReportJSON:
+		report := integrationTestJSONReport{
+			Status:          integrationTestPassed,
+			DurationSeconds: totalDuration.Seconds(),
+			Tests:           []integrationTestJSONCase{},
+		}
+		for _, result := range results {
+			testCase := integrationTestJSONCase{
+				Name:            result.test.name,
+				DisplayName:     result.test.displayName,
+				Status:          result.status,
+				DurationSeconds: result.duration.Seconds(),
+				LogExcerpt:      result.logExcerpt,
+			}
+			if result.err != nil {
+				testCase.Error = result.err.Error()
+			}
+			if result.status != integrationTestPassed {
+				report.Status = integrationTestFailed
+			}
+			report.Tests = append(report.Tests, testCase)
+		}
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+
+	case integrationTestReportJUnit:
+		suite := junitTestSuite{
+			Name:      "metaplay-integration-tests",
+			Time:      fmt.Sprintf("%.3f", totalDuration.Seconds()),
+			Timestamp: startTime.UTC().Format("2006-01-02T15:04:05"),
+			Cases:     []junitTestCase{},
+		}
+		for _, result := range results {
+			testCase := junitTestCase{
+				Name:      result.test.name,
+				ClassName: "integration",
+				Time:      fmt.Sprintf("%.3f", result.duration.Seconds()),
+			}
+			switch result.status {
+			case integrationTestFailed:
+				testCase.Failure = &junitFailure{Message: fmt.Sprintf("%s failed: %v", result.test.displayName, result.err), Text: result.logExcerpt}
+				suite.Failures++
+			case integrationTestCanceled, integrationTestSkipped:
+				testCase.Skipped = &junitSkipped{Message: fmt.Sprintf("Test %s", result.status)}
+				suite.Skipped++
+			}
+			suite.Tests++
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		suites := junitTestSuites{
+			Name:     suite.Name,
+			Tests:    suite.Tests,
+			Failures: suite.Failures,
+			Skipped:  suite.Skipped,
+			Time:     suite.Time,
+			Suites:   []junitTestSuite{suite},
+		}
+		content, err := xml.MarshalIndent(suites, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), append(content, '\n')...), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported report format '%s'", format)
+	}
+}
+
+// writeIntegrationTestReport writes the report of the test results into the output directory.
+// Returns the path to the written report.
+func writeIntegrationTestReport(outputDir, format string, results []integrationTestResult, startTime time.Time, totalDuration time.Duration) (string, error) {
+	content, err := renderIntegrationTestReport(format, results, startTime, totalDuration)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the test report: %w", err)
+	}
+
+	reportPath := filepath.Join(outputDir, integrationTestReportFileName(format))
+	if err := os.WriteFile(reportPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write the test report %s: %w", reportPath, err)
+	}
+	return reportPath, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIntegrationTestResults() []integrationTestResult {
+	return []integrationTestResult{
+		{test: integrationTests[0], status: integrationTestPassed, duration: 90 * time.Second},
+		{test: integrationTests[1], status: integrationTestFailed, duration: 30 * time.Second, err: errors.New("playwright-ts exited with code 1"), logExcerpt: "[playwright-ts] 1 failed <test>"},
+		{test: integrationTests[2], status: integrationTestSkipped},
+	}
+}
+
+func TestIntegrationTestLogTail(t *testing.T) {
+	tail := newIntegrationTestLogTail(3)
+	assert.Equal(t, "", tail.String())
+
+	_, _ = tail.Write([]byte("line 1\nline 2\nli"))
+	assert.Equal(t, "line 1\nline 2\nli", tail.String())
+
+	// Only the last lines are kept.
+	_, _ = tail.Write([]byte("ne 3\n"))
+	for i := 4; i <= 10; i++ {
+		_, _ = fmt.Fprintf(tail, "line %d\n", i)
+	}
+	assert.Equal(t, "line 8\nline 9\nline 10", tail.String())
+
+	_, _ = tail.Write([]byte("partial"))
+	assert.Equal(t, "line 9\nline 10\npartial", tail.String())
+}
+
+func TestRenderIntegrationTestReportJSON(t *testing.T) {
+	content, err := renderIntegrationTestReport(integrationTestReportJSON, testIntegrationTestResults(), time.Now(), 2*time.Minute)
+	require.NoError(t, err)
+
+	var report integrationTestJSONReport
+	require.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, integrationTestFailed, report.Status)
+	assert.Equal(t, 120.0, report.DurationSeconds)
+	require.Len(t, report.Tests, 3)
+	assert.Equal(t, integrationTestJSONCase{Name: "bots", DisplayName: "Run botclient tests", Status: integrationTestPassed, DurationSeconds: 90}, report.Tests[0])
+	assert.Equal(t, "playwright-ts exited with code 1", report.Tests[1].Error)
+	assert.Equal(t, "[playwright-ts] 1 failed <test>", report.Tests[1].LogExcerpt)
+	assert.Equal(t, integrationTestSkipped, report.Tests[2].Status)
+}
+
+func TestRenderIntegrationTestReportJUnit(t *testing.T) {
+	startTime := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	content, err := renderIntegrationTestReport(integrationTestReportJUnit, testIntegrationTestResults(), startTime, 2*time.Minute)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `<?xml version="1.0" encoding="UTF-8"?>`)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(content, &report))
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, "120.000", report.Time)
+	require.Len(t, report.Suites, 1)
+
+	suite := report.Suites[0]
+	assert.Equal(t, "2026-10-16T12:00:00", suite.Timestamp)
+	require.Len(t, suite.Cases, 3)
+	assert.Equal(t, "bots", suite.Cases[0].Name)
+	assert.Equal(t, "90.000", suite.Cases[0].Time)
+	assert.Nil(t, suite.Cases[0].Failure)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, "Run dashboard Playwright tests failed: playwright-ts exited with code 1", suite.Cases[1].Failure.Message)
+	assert.Equal(t, "[playwright-ts] 1 failed <test>", suite.Cases[1].Failure.Text)
+	require.NotNil(t, suite.Cases[2].Skipped)
+}
+
+func TestWriteIntegrationTestReport(t *testing.T) {
+	outputDir := t.TempDir()
+	reportPath, err := writeIntegrationTestReport(outputDir, integrationTestReportJUnit, testIntegrationTestResults(), time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(outputDir, "integration-test-report.xml"), reportPath)
+	_, err = os.Stat(reportPath)
+	assert.NoError(t, err)
+
+	_, err = writeIntegrationTestReport(outputDir, "html", testIntegrationTestResults(), time.Now(), time.Minute)
+	assert.Error(t, err)
+}
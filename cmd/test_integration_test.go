@@ -0,0 +1,132 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIntegrationTests returns fake tests that succeed, except for the ones named in failing.
+// The failing tests fail immediately and the others wait until done, or their context is canceled.
+func testIntegrationTests(names []string, failing map[string]bool, done <-chan struct{}, started *atomic.Int32) []integrationTest {
+	tests := []integrationTest{}
+	for _, name := range names {
+		tests = append(tests, integrationTest{name, "Test " + name, func(testCtx integrationTestCtx, target *integrationTestTarget) error {
+			started.Add(1)
+			if failing[name] {
+				return errors.New("test failure")
+			}
+			select {
+			case <-done:
+				return nil
+			case <-testCtx.ctx.Done():
+				return testCtx.ctx.Err()
+			}
+		}})
+	}
+	return tests
+}
+
+func runTestIntegrationTests(opts *testIntegrationOpts, tests []integrationTest) []integrationTestResult {
+	testCtx := integrationTestCtx{ctx: context.Background(), opts: opts}
+	return opts.runIntegrationTests(testCtx, tests, &integrationTestTarget{})
+}
+
+func resultStatuses(results []integrationTestResult) []string {
+	statuses := []string{}
+	for _, result := range results {
+		statuses = append(statuses, result.status)
+	}
+	return statuses
+}
+
+func TestIntegrationTestContainerNamesAndLogPrefixes(t *testing.T) {
+	testCtx := integrationTestCtx{project: &metaproj.MetaplayProject{Config: metaproj.ProjectConfig{ProjectHumanID: "lovely-wombats-build"}}}
+	assert.Equal(t, "lovely-wombats-build-test-server", testCtx.containerName("server"))
+	assert.Equal(t, "[server] ", testCtx.logPrefix("server"))
+	assert.Equal(t, "", testCtx.logPrefix(""))
+
+	testCtx.testName = "bots"
+	assert.Equal(t, "lovely-wombats-build-test-server-bots", testCtx.containerName("server"))
+	assert.Equal(t, "[bots] [server] ", testCtx.logPrefix("server"))
+	assert.Equal(t, "[bots] ", testCtx.logPrefix(""))
+}
+
+func TestRunIntegrationTestsSequential(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+	var started atomic.Int32
+
+	// With --fail-fast, the tests after the failed one are skipped.
+	opts := &testIntegrationOpts{flagParallel: 1, flagFailFast: true}
+	results := runTestIntegrationTests(opts, testIntegrationTests([]string{"a", "b", "c"}, map[string]bool{"b": true}, done, &started))
+	assert.Equal(t, []string{integrationTestPassed, integrationTestFailed, integrationTestSkipped}, resultStatuses(results))
+	assert.EqualValues(t, 2, started.Load())
+	assert.EqualError(t, results[1].err, "test failure")
+
+	// Without --fail-fast, all the tests are run.
+	started.Store(0)
+	opts.flagFailFast = false
+	results = runTestIntegrationTests(opts, testIntegrationTests([]string{"a", "b", "c"}, map[string]bool{"b": true}, done, &started))
+	assert.Equal(t, []string{integrationTestPassed, integrationTestFailed, integrationTestPassed}, resultStatuses(results))
+	assert.EqualValues(t, 3, started.Load())
+}
+
+func TestRunIntegrationTestsParallel(t *testing.T) {
+	var started atomic.Int32
+
+	// With --fail-fast, the failure cancels the other running test and skips the rest.
+	done := make(chan struct{}) // Never closed: the passing tests only finish when canceled
+	opts := &testIntegrationOpts{flagParallel: 2, flagFailFast: true}
+	results := runTestIntegrationTests(opts, testIntegrationTests([]string{"a", "b", "c"}, map[string]bool{"b": true}, done, &started))
+	assert.Equal(t, []string{integrationTestCanceled, integrationTestFailed, integrationTestSkipped}, resultStatuses(results))
+	assert.EqualValues(t, 2, started.Load())
+
+	// Without --fail-fast, all the tests run to completion.
+	started.Store(0)
+	done = make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(done) })
+	opts.flagFailFast = false
+	results = runTestIntegrationTests(opts, testIntegrationTests([]string{"a", "b", "c"}, map[string]bool{"b": true}, done, &started))
+	assert.Equal(t, []string{integrationTestPassed, integrationTestFailed, integrationTestPassed}, resultStatuses(results))
+	assert.EqualValues(t, 3, started.Load())
+}
+
+func TestResolveCustomIntegrationTests(t *testing.T) {
+	project := &metaproj.MetaplayProject{Config: metaproj.ProjectConfig{ProjectHumanID: "lovely-wombats-build"}}
+	customTests, err := resolveCustomIntegrationTests(project)
+	require.NoError(t, err)
+	assert.Empty(t, customTests)
+
+	project.Config.IntegrationTests = &metaproj.IntegrationTestsConfig{Tests: []metaproj.CustomIntegrationTestConfig{
+		{Name: "api-smoke", Description: "Run API smoke tests", Target: "api-smoke-tests"},
+		{Name: "k6", Image: "grafana/k6:latest"},
+	}}
+	customTests, err = resolveCustomIntegrationTests(project)
+	require.NoError(t, err)
+	require.Len(t, customTests, 2)
+	assert.Equal(t, "lovely-wombats-build/api-smoke:test", customTests[0].image)
+	assert.Equal(t, "grafana/k6:latest", customTests[1].image)
+
+	test := customTests[0].integrationTest()
+	assert.Equal(t, "api-smoke", test.name)
+	assert.Equal(t, "Run API smoke tests", test.displayName)
+	assert.Equal(t, "Run custom test k6", customTests[1].integrationTest().displayName)
+
+	// The names of the built-in tests and their containers are reserved.
+	for _, name := range []string{"bots", "server"} {
+		project.Config.IntegrationTests.Tests = []metaproj.CustomIntegrationTestConfig{{Name: name, Target: "tests"}}
+		_, err = resolveCustomIntegrationTests(project)
+		assert.Error(t, err, name)
+	}
+}
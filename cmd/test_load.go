@@ -0,0 +1,518 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Run a load test against a cloud environment with bots deployed into the environment.
+type testLoadOpts struct {
+	UsePositionalArgs
+
+	argEnvironment     string
+	argImageNameTag    string
+	flagMaxBots        int
+	flagBotsPerPod     int
+	flagRampDuration   time.Duration
+	flagHoldDuration   time.Duration
+	flagSampleInterval time.Duration
+	flagMaxCPU         float64
+	flagMaxMemory      string
+	flagMinCCU         int
+	flagCCUQuery       string
+	flagReportPath     string
+	flagKeepBots       bool
+
+	maxMemoryBytes int64 // Parsed --max-memory (0 if not specified)
+}
+
+// Phases of the load test.
+const (
+	loadTestPhaseRamp = "ramp"
+	loadTestPhaseHold = "hold"
+)
+
+// Default PromQL query for the number of concurrent users (CCU) of the game server.
+const defaultLoadTestCCUQuery = `sum(game_connections_current{namespace="{namespace}"})`
+
+// Queries for the peak resource usage of the game server pods during the load test, with
+// '{namespace}' for the environment's namespace.
+const (
+	loadTestCPUQuery    = `max(sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="{namespace}", container="shard-server"}[1m])))`
+	loadTestMemoryQuery = `max(sum by (pod) (container_memory_working_set_bytes{namespace="{namespace}", container="shard-server"}))`
+)
+
+// loadTestSample is the game server metrics at a point of the load test. The metrics that
+// could not be queried are nil.
+type loadTestSample struct {
+	ElapsedSeconds float64  `json:"elapsedSeconds"`
+	Phase          string   `json:"phase"`
+	TargetBots     int      `json:"targetBots"` // Number of bots expected to be running at this point
+	CCU            *float64 `json:"ccu,omitempty"`
+	CPU            *float64 `json:"cpu,omitempty"`         // CPU usage (cores) of the busiest game server pod
+	MemoryBytes    *float64 `json:"memoryBytes,omitempty"` // Memory usage of the largest game server pod
+}
+
+// loadTestCheck is the result of checking one of the pass/fail thresholds.
+type loadTestCheck struct {
+	Name   string `json:"name"`
+	Limit  string `json:"limit"`
+	Value  string `json:"value"`
+	Passed bool   `json:"passed"`
+}
+
+// loadTestReport is the JSON report of the load test written with --report.
+type loadTestReport struct {
+	Environment         string           `json:"environment"`
+	MaxBots             int              `json:"maxBots"`
+	BotsPerPod          int              `json:"botsPerPod"`
+	RampDurationSeconds float64          `json:"rampDurationSeconds"`
+	HoldDurationSeconds float64          `json:"holdDurationSeconds"`
+	Passed              bool             `json:"passed"`
+	Interrupted         bool             `json:"interrupted"`
+	PeakCCU             *float64         `json:"peakCCU,omitempty"`
+	PeakCPU             *float64         `json:"peakCPU,omitempty"`
+	PeakMemoryBytes     *float64         `json:"peakMemoryBytes,omitempty"`
+	Checks              []loadTestCheck  `json:"checks"`
+	Samples             []loadTestSample `json:"samples"`
+}
+
+func init() {
+	o := testLoadOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgument(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'lovely-wombats-build-nimbly'.")
+	args.AddStringArgument(&o.argImageNameTag, "[IMAGE:]TAG", "Docker image name and tag for the bots, eg, 'mygame:364cff09' or '364cff09'.")
+
+	cmd := &cobra.Command{
+		Use:   "load ENVIRONMENT [IMAGE:]TAG [flags]",
+		Short: "[preview] Run a load test with bots against a cloud environment",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			PREVIEW: This command is currently in preview and may change in the future. If you encounter
+			problems or have feedback, please file an issue at https://github.com/metaplay/cli/issues/new.
+
+			Run a load test against the game server deployed in the target environment.
+
+			The test runs as follows:
+
+			1. Deploy the BotClient into the environment, like 'metaplay deploy botclient', with
+			   --max-bots bots in pods of --bots-per-pod bots each.
+			2. Ramp up the bots linearly to --max-bots over --ramp-duration, and keep them running
+			   for --hold-duration.
+			3. Every --sample-interval, collect the CPU and memory usage of the busiest game server pod
+			   and the number of concurrent users (CCU) from the environment's Prometheus.
+			4. Remove the bots from the environment (unless --keep-bots).
+			5. Print a summary of the peak metrics and check them against the thresholds.
+
+			The thresholds are given with --max-cpu, --max-memory, and --min-ccu. The CCU is checked
+			against the peak during the hold phase. The command fails if any of the thresholds are
+			exceeded, so it can be used in CI pipelines. Use --report to also write the results and
+			all the samples into a JSON file.
+
+			The CCU is queried with --ccu-query, where '{namespace}' is replaced with the environment's
+			Kubernetes namespace. Override it if your game server reports the CCU in another metric.
+
+			The Helm values files of the bots (see 'metaplay deploy botclient') are used as-is, except
+			for the number of bots, the spawn rate, and the session duration, which are set by the
+			load test.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay build image' to build the docker image.
+			- 'metaplay deploy botclient ...' to deploy the bots without the load test.
+			- 'metaplay env metrics ENVIRONMENT' to show the metrics of the environment.
+		`),
+		Example: renderExample(`
+			# Ramp up to 1000 bots over 5 minutes in environment tough-falcons, and keep them running for 10 minutes.
+			metaplay test load tough-falcons 364cff09 --max-bots=1000 --ramp-duration=5m --hold-duration=10m
+
+			# Fail the test if a game server pod uses more than 2 CPU cores or 4GiB of memory, or the CCU stays below 950.
+			metaplay test load tough-falcons 364cff09 --max-bots=1000 --max-cpu=2 --max-memory=4Gi --min-ccu=950
+
+			# Write the results into a JSON report.
+			metaplay test load tough-falcons 364cff09 --report=load-test-report.json
+		`),
+	}
+	testCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.IntVar(&o.flagMaxBots, "max-bots", 100, "Number of bots to ramp up to")
+	flags.IntVar(&o.flagBotsPerPod, "bots-per-pod", 50, "Number of bots to run in each bot client pod")
+	flags.DurationVar(&o.flagRampDuration, "ramp-duration", 5*time.Minute, "Duration to ramp up the bots to --max-bots, eg, 5m")
+	flags.DurationVar(&o.flagHoldDuration, "hold-duration", 10*time.Minute, "Duration to keep --max-bots running after the ramp-up, eg, 10m")
+	flags.DurationVar(&o.flagSampleInterval, "sample-interval", 30*time.Second, "Interval between collecting the game server metrics")
+	flags.Float64Var(&o.flagMaxCPU, "max-cpu", 0, "Fail if the CPU usage of a game server pod exceeds this many cores (default: no limit)")
+	flags.StringVar(&o.flagMaxMemory, "max-memory", "", "Fail if the memory usage of a game server pod exceeds this, eg, '4Gi' (default: no limit)")
+	flags.IntVar(&o.flagMinCCU, "min-ccu", 0, "Fail if the peak CCU during the hold phase is below this (default: no limit)")
+	flags.StringVar(&o.flagCCUQuery, "ccu-query", defaultLoadTestCCUQuery, "PromQL query for the CCU of the game server, with '{namespace}' for the environment's namespace")
+	flags.StringVar(&o.flagReportPath, "report", "", "Write the results of the load test into this JSON file")
+	flags.BoolVar(&o.flagKeepBots, "keep-bots", false, "Keep the bots running in the environment after the test")
+}
+
+func (o *testLoadOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagMaxBots <= 0 {
+		return clierrors.NewUsageError("--max-bots must be a positive integer")
+	}
+	if o.flagBotsPerPod <= 0 {
+		return clierrors.NewUsageError("--bots-per-pod must be a positive integer")
+	}
+	if o.flagRampDuration < 0 {
+		return clierrors.NewUsageError("--ramp-duration must not be negative")
+	}
+	if o.flagHoldDuration <= 0 {
+		return clierrors.NewUsageError("--hold-duration must be a positive duration (e.g., 5m, 10m)")
+	}
+	if o.flagSampleInterval < time.Second {
+		return clierrors.NewUsageError("--sample-interval must be at least 1s")
+	}
+	if o.flagMaxCPU < 0 {
+		return clierrors.NewUsageError("--max-cpu must not be negative")
+	}
+	if o.flagMinCCU < 0 {
+		return clierrors.NewUsageError("--min-ccu must not be negative")
+	}
+	if o.flagMaxMemory != "" {
+		quantity, err := resource.ParseQuantity(o.flagMaxMemory)
+		if err != nil {
+			return clierrors.NewUsageErrorf("Invalid --max-memory '%s'", o.flagMaxMemory).
+				WithSuggestion("Specify the memory as a Kubernetes quantity, eg, '4Gi' or '4096Mi'")
+		}
+		o.maxMemoryBytes = quantity.Value()
+	}
+	if strings.TrimSpace(o.flagCCUQuery) == "" {
+		return clierrors.NewUsageError("--ccu-query must not be empty")
+	}
+	return nil
+}
+
+func (o *testLoadOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Resolve project and environment.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+	envConfig, tokenSet, err := resolveEnvironment(ctx, project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	// Resolve the Prometheus endpoint before deploying anything.
+	targetEnv := envapi.NewTargetEnvironment(tokenSet, envConfig.StackDomain, envConfig.HumanID)
+	promClient, err := targetEnv.NewPrometheusClient()
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to resolve the Prometheus endpoint of the environment").
+			WithSuggestion("The load test needs the environment's Prometheus for collecting the game server metrics")
+	}
+	namespace := envConfig.GetKubernetesNamespace()
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Run Load Test"))
+	log.Info().Msg("")
+	log.Info().Msgf("Target environment: %s", styles.RenderTechnical(fmt.Sprintf("%s [%s]", envConfig.Name, envConfig.HumanID)))
+	log.Info().Msgf("Max bots:           %s", styles.RenderTechnical(fmt.Sprintf("%d (%d pods)", o.flagMaxBots, loadTestBotPods(o.flagMaxBots, o.flagBotsPerPod))))
+	log.Info().Msgf("Ramp duration:      %s", styles.RenderTechnical(o.flagRampDuration.String()))
+	log.Info().Msgf("Hold duration:      %s", styles.RenderTechnical(o.flagHoldDuration.String()))
+	log.Info().Msgf("Sample interval:    %s", styles.RenderTechnical(o.flagSampleInterval.String()))
+	log.Info().Msgf("Thresholds:         %s", styles.RenderTechnical(o.describeThresholds()))
+
+	// Deploy the bots, like 'metaplay deploy botclient', with the load test's ramp profile.
+	deployOpts := &deployBotClientOpts{
+		argEnvironment:  envConfig.HumanID,
+		argImageNameTag: o.argImageNameTag,
+		extraArgs:       loadTestHelmSetArgs(o.flagMaxBots, o.flagBotsPerPod, o.flagRampDuration, o.flagHoldDuration),
+	}
+	if err := deployOpts.Run(cmd); err != nil {
+		return err
+	}
+
+	// Remove the bots when done, also when the test is interrupted.
+	if !o.flagKeepBots {
+		defer func() {
+			log.Info().Msg("")
+			removeOpts := &removeBotClientOpts{argEnvironment: envConfig.HumanID}
+			cleanupCmd := &cobra.Command{}
+			cleanupCmd.SetContext(context.WithoutCancel(ctx))
+			if err := removeOpts.Run(cleanupCmd); err != nil {
+				log.Warn().Msgf("Failed to remove the bots: %v", err)
+				log.Warn().Msgf("Remove them manually with %s", styles.RenderPrompt(fmt.Sprintf("metaplay remove botclient %s", envConfig.HumanID)))
+			}
+		}()
+	}
+
+	// Collect the game server metrics until the end of the hold phase.
+	samples, interrupted := o.collectLoadTestSamples(ctx, promClient, namespace)
+
+	// Summarize and check the thresholds.
+	report := o.buildLoadTestReport(envConfig.HumanID, samples, interrupted)
+	printLoadTestSummary(report)
+
+	if o.flagReportPath != "" {
+		content, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to marshal the load test report as JSON")
+		}
+		if err := os.WriteFile(o.flagReportPath, append(content, '\n'), 0644); err != nil {
+			return clierrors.Wrapf(err, "Failed to write the load test report to %s", o.flagReportPath)
+		}
+		log.Info().Msgf("Load test report written to %s", styles.RenderTechnical(o.flagReportPath))
+		log.Info().Msg("")
+	}
+
+	if interrupted {
+		return clierrors.New("Load test was interrupted before the end of the hold phase")
+	}
+	if !report.Passed {
+		numFailed := 0
+		for _, check := range report.Checks {
+			if !check.Passed {
+				numFailed++
+			}
+		}
+		return clierrors.Newf("Load test failed %d of %d threshold checks", numFailed, len(report.Checks))
+	}
+
+	log.Info().Msg(styles.RenderSuccess("✅ Load test passed"))
+	return nil
+}
+
+// describeThresholds returns the pass/fail thresholds of the test as text.
+func (o *testLoadOpts) describeThresholds() string {
+	thresholds := []string{}
+	if o.flagMaxCPU > 0 {
+		thresholds = append(thresholds, fmt.Sprintf("CPU <= %g cores", o.flagMaxCPU))
+	}
+	if o.maxMemoryBytes > 0 {
+		thresholds = append(thresholds, fmt.Sprintf("memory <= %s", humanize.IBytes(uint64(o.maxMemoryBytes))))
+	}
+	if o.flagMinCCU > 0 {
+		thresholds = append(thresholds, fmt.Sprintf("CCU >= %d", o.flagMinCCU))
+	}
+	if len(thresholds) == 0 {
+		return "none"
+	}
+	return strings.Join(thresholds, ", ")
+}
+
+// collectLoadTestSamples collects the game server metrics every --sample-interval until the end
+// of the hold phase. Returns the samples, and whether the test was interrupted.
+func (o *testLoadOpts) collectLoadTestSamples(ctx context.Context, promClient *envapi.PrometheusClient, namespace string) ([]loadTestSample, bool) {
+	totalDuration := o.flagRampDuration + o.flagHoldDuration
+	ticker := time.NewTicker(o.flagSampleInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("")
+	log.Info().Msgf("Running bots for %s...", totalDuration)
+	log.Info().Msg("")
+	log.Info().Msgf("  %-8s  %-5s  %6s  %8s  %6s  %s", "ELAPSED", "PHASE", "BOTS", "CCU", "CPU", "MEMORY")
+
+	samples := []loadTestSample{}
+	startTime := time.Now()
+	for {
+		elapsed := time.Since(startTime)
+		sample := loadTestSample{
+			ElapsedSeconds: math.Round(elapsed.Seconds()),
+			Phase:          loadTestPhase(elapsed, o.flagRampDuration),
+			TargetBots:     loadTestTargetBots(elapsed, o.flagRampDuration, o.flagMaxBots),
+			CCU:            queryLoadTestMetric(ctx, promClient, o.flagCCUQuery, namespace),
+			CPU:            queryLoadTestMetric(ctx, promClient, loadTestCPUQuery, namespace),
+			MemoryBytes:    queryLoadTestMetric(ctx, promClient, loadTestMemoryQuery, namespace),
+		}
+		samples = append(samples, sample)
+		log.Info().Msgf("  %-8s  %-5s  %6d  %8s  %6s  %s",
+			elapsed.Round(time.Second),
+			sample.Phase,
+			sample.TargetBots,
+			formatLoadTestMetric(sample.CCU, func(v float64) string { return fmt.Sprintf("%.0f", v) }),
+			formatLoadTestMetric(sample.CPU, func(v float64) string { return fmt.Sprintf("%.2f", v) }),
+			formatLoadTestMetric(sample.MemoryBytes, func(v float64) string { return humanize.IBytes(uint64(v)) }))
+
+		if elapsed >= totalDuration {
+			return samples, false
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Warn().Msg("Load test interrupted")
+			return samples, true
+		case <-ticker.C:
+		}
+	}
+}
+
+// queryLoadTestMetric runs the query (with '{namespace}' replaced) and returns the value of the
+// first result, or nil if the query failed or returned no results.
+func queryLoadTestMetric(ctx context.Context, promClient *envapi.PrometheusClient, query string, namespace string) *float64 {
+	results, err := promClient.Query(ctx, strings.ReplaceAll(query, "{namespace}", namespace))
+	if err != nil {
+		log.Debug().Msgf("Prometheus query '%s' failed: %v", query, err)
+		return nil
+	}
+	if len(results) == 0 || math.IsNaN(results[0].Value) || math.IsInf(results[0].Value, 0) {
+		return nil
+	}
+	value := results[0].Value
+	return &value
+}
+
+// formatLoadTestMetric formats a metric value, or '-' if there's no value.
+func formatLoadTestMetric(value *float64, format func(float64) string) string {
+	if value == nil {
+		return "-"
+	}
+	return format(*value)
+}
+
+// loadTestBotPods returns the number of bot client pods needed for the bots.
+func loadTestBotPods(maxBots, botsPerPod int) int {
+	return (maxBots + botsPerPod - 1) / botsPerPod
+}
+
+// loadTestPhase returns the phase of the load test at the elapsed time.
+func loadTestPhase(elapsed, rampDuration time.Duration) string {
+	if elapsed < rampDuration {
+		return loadTestPhaseRamp
+	}
+	return loadTestPhaseHold
+}
+
+// loadTestTargetBots returns the number of bots expected to be running at the elapsed time, with
+// the bots ramped up linearly to maxBots over the ramp duration.
+func loadTestTargetBots(elapsed, rampDuration time.Duration, maxBots int) int {
+	if elapsed >= rampDuration {
+		return maxBots
+	}
+	return int(float64(maxBots) * elapsed.Seconds() / rampDuration.Seconds())
+}
+
+// loadTestHelmSetArgs returns the Helm '--set' arguments for the bot client chart to run the
+// bots with the load test's ramp profile. All the pods spawn their bots in parallel, so the
+// spawn rate of each pod determines the duration of the ramp-up. The bot sessions last for the
+// whole test, so that the bots stay connected during the hold phase.
+func loadTestHelmSetArgs(maxBots, botsPerPod int, rampDuration, holdDuration time.Duration) []string {
+	botsPerPod = min(botsPerPod, maxBots)
+	spawnRate := float64(botsPerPod)
+	if rampDuration >= time.Second {
+		spawnRate = float64(botsPerPod) / rampDuration.Seconds()
+	}
+	return []string{
+		"--set", fmt.Sprintf("botclients.maxBotId=%d", maxBots),
+		"--set", fmt.Sprintf("botclients.botsPerPod=%d", botsPerPod),
+		"--set", "botclients.botSpawnRate=" + strconv.FormatFloat(spawnRate, 'f', -1, 64),
+		"--set-string", "botclients.botSessionDuration=" + formatDotnetTimeSpan(rampDuration+holdDuration),
+	}
+}
+
+// buildLoadTestReport summarizes the samples and checks them against the thresholds.
+func (o *testLoadOpts) buildLoadTestReport(environment string, samples []loadTestSample, interrupted bool) loadTestReport {
+	report := loadTestReport{
+		Environment:         environment,
+		MaxBots:             o.flagMaxBots,
+		BotsPerPod:          o.flagBotsPerPod,
+		RampDurationSeconds: o.flagRampDuration.Seconds(),
+		HoldDurationSeconds: o.flagHoldDuration.Seconds(),
+		Interrupted:         interrupted,
+		Checks:              []loadTestCheck{},
+		Samples:             samples,
+	}
+
+	// Peak CCU during the hold phase, and peak resource usage during the whole test.
+	for _, sample := range samples {
+		if sample.Phase == loadTestPhaseHold {
+			report.PeakCCU = maxLoadTestMetric(report.PeakCCU, sample.CCU)
+		}
+		report.PeakCPU = maxLoadTestMetric(report.PeakCPU, sample.CPU)
+		report.PeakMemoryBytes = maxLoadTestMetric(report.PeakMemoryBytes, sample.MemoryBytes)
+	}
+
+	// Check the thresholds. A missing metric fails the check, as it can't be verified.
+	if o.flagMaxCPU > 0 {
+		report.Checks = append(report.Checks, loadTestCheck{
+			Name:   "Peak CPU usage (cores)",
+			Limit:  fmt.Sprintf("<= %g", o.flagMaxCPU),
+			Value:  formatLoadTestMetric(report.PeakCPU, func(v float64) string { return fmt.Sprintf("%.2f", v) }),
+			Passed: report.PeakCPU != nil && *report.PeakCPU <= o.flagMaxCPU,
+		})
+	}
+	if o.maxMemoryBytes > 0 {
+		report.Checks = append(report.Checks, loadTestCheck{
+			Name:   "Peak memory usage",
+			Limit:  "<= " + humanize.IBytes(uint64(o.maxMemoryBytes)),
+			Value:  formatLoadTestMetric(report.PeakMemoryBytes, func(v float64) string { return humanize.IBytes(uint64(v)) }),
+			Passed: report.PeakMemoryBytes != nil && *report.PeakMemoryBytes <= float64(o.maxMemoryBytes),
+		})
+	}
+	if o.flagMinCCU > 0 {
+		report.Checks = append(report.Checks, loadTestCheck{
+			Name:   "Peak CCU (hold phase)",
+			Limit:  fmt.Sprintf(">= %d", o.flagMinCCU),
+			Value:  formatLoadTestMetric(report.PeakCCU, func(v float64) string { return fmt.Sprintf("%.0f", v) }),
+			Passed: report.PeakCCU != nil && *report.PeakCCU >= float64(o.flagMinCCU),
+		})
+	}
+
+	report.Passed = !interrupted
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+	return report
+}
+
+// maxLoadTestMetric returns the larger of the values, ignoring missing values.
+func maxLoadTestMetric(a, b *float64) *float64 {
+	if a == nil {
+		return b
+	}
+	if b == nil || *a >= *b {
+		return a
+	}
+	return b
+}
+
+// printLoadTestSummary prints the peak metrics and the results of the threshold checks.
+func printLoadTestSummary(report loadTestReport) {
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Load Test Summary"))
+	log.Info().Msg("")
+	log.Info().Msgf("  %-24s %s", "Peak CCU (hold phase):", styles.RenderTechnical(formatLoadTestMetric(report.PeakCCU, func(v float64) string { return fmt.Sprintf("%.0f", v) })))
+	log.Info().Msgf("  %-24s %s", "Peak CPU usage (cores):", styles.RenderTechnical(formatLoadTestMetric(report.PeakCPU, func(v float64) string { return fmt.Sprintf("%.2f", v) })))
+	log.Info().Msgf("  %-24s %s", "Peak memory usage:", styles.RenderTechnical(formatLoadTestMetric(report.PeakMemoryBytes, func(v float64) string { return humanize.IBytes(uint64(v)) })))
+	log.Info().Msg("")
+
+	if len(report.Checks) == 0 {
+		log.Info().Msg(styles.RenderMuted("No thresholds specified; use --max-cpu, --max-memory, or --min-ccu to check the results."))
+		log.Info().Msg("")
+		return
+	}
+
+	log.Info().Msgf("  %-24s %-12s %-12s %s", "CHECK", "LIMIT", "VALUE", "RESULT")
+	for _, check := range report.Checks {
+		result := styles.RenderSuccess("pass")
+		if !check.Passed {
+			result = styles.RenderError("FAIL")
+		}
+		log.Info().Msgf("  %-24s %-12s %-12s %s", check.Name, check.Limit, check.Value, result)
+	}
+	log.Info().Msg("")
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/helmutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func TestLoadTestTargetBots(t *testing.T) {
+	ramp := 5 * time.Minute
+	assert.Equal(t, 0, loadTestTargetBots(0, ramp, 1000))
+	assert.Equal(t, 500, loadTestTargetBots(150*time.Second, ramp, 1000))
+	assert.Equal(t, 1000, loadTestTargetBots(ramp, ramp, 1000))
+	assert.Equal(t, 1000, loadTestTargetBots(time.Hour, ramp, 1000))
+	assert.Equal(t, 1000, loadTestTargetBots(0, 0, 1000))
+
+	assert.Equal(t, loadTestPhaseRamp, loadTestPhase(time.Minute, ramp))
+	assert.Equal(t, loadTestPhaseHold, loadTestPhase(ramp, ramp))
+
+	assert.Equal(t, 20, loadTestBotPods(1000, 50))
+	assert.Equal(t, 21, loadTestBotPods(1001, 50))
+	assert.Equal(t, 1, loadTestBotPods(10, 50))
+}
+
+func TestLoadTestHelmSetArgs(t *testing.T) {
+	values, err := helmutil.ParseHelmExtraArgs(loadTestHelmSetArgs(1000, 50, 5*time.Minute, 10*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"botclients": map[string]any{
+			"maxBotId":           int64(1000),
+			"botsPerPod":         int64(50),
+			"botSpawnRate":       "0.16666666666666666", // Helm parses only integers from --set, but renders the value as-is
+			"botSessionDuration": "00:15:00",
+		},
+	}, values)
+
+	// Without a ramp-up, the bots are spawned at once. The pods are never larger than the number of bots.
+	values, err = helmutil.ParseHelmExtraArgs(loadTestHelmSetArgs(10, 50, 0, time.Minute))
+	require.NoError(t, err)
+	botclients := values["botclients"].(map[string]any)
+	assert.Equal(t, int64(10), botclients["botsPerPod"])
+	assert.Equal(t, int64(10), botclients["botSpawnRate"])
+}
+
+func TestBuildLoadTestReport(t *testing.T) {
+	o := &testLoadOpts{flagMaxBots: 100, flagBotsPerPod: 50, flagRampDuration: time.Minute, flagHoldDuration: time.Minute, flagMaxCPU: 2, maxMemoryBytes: 4 << 30, flagMinCCU: 95}
+	samples := []loadTestSample{
+		{Phase: loadTestPhaseRamp, CCU: floatPtr(120), CPU: floatPtr(1.5), MemoryBytes: floatPtr(1 << 30)},
+		{Phase: loadTestPhaseHold, CCU: floatPtr(98), CPU: floatPtr(1.8)},
+		{Phase: loadTestPhaseHold, CCU: floatPtr(96), MemoryBytes: floatPtr(2 << 30)},
+	}
+
+	report := o.buildLoadTestReport("tough-falcons", samples, false)
+	assert.True(t, report.Passed)
+	assert.Equal(t, 98.0, *report.PeakCCU) // Only the hold phase counts for the CCU
+	assert.Equal(t, 1.8, *report.PeakCPU)
+	assert.Equal(t, float64(2<<30), *report.PeakMemoryBytes)
+	require.Len(t, report.Checks, 3)
+	assert.Equal(t, loadTestCheck{Name: "Peak CPU usage (cores)", Limit: "<= 2", Value: "1.80", Passed: true}, report.Checks[0])
+	assert.Equal(t, loadTestCheck{Name: "Peak memory usage", Limit: "<= 4.0 GiB", Value: "2.0 GiB", Passed: true}, report.Checks[1])
+	assert.Equal(t, loadTestCheck{Name: "Peak CCU (hold phase)", Limit: ">= 95", Value: "98", Passed: true}, report.Checks[2])
+
+	// Exceeded thresholds fail the test.
+	o.flagMaxCPU = 1.6
+	report = o.buildLoadTestReport("tough-falcons", samples, false)
+	assert.False(t, report.Passed)
+	assert.False(t, report.Checks[0].Passed)
+
+	// Missing metrics fail the checks, as they can't be verified.
+	report = o.buildLoadTestReport("tough-falcons", []loadTestSample{{Phase: loadTestPhaseHold}}, false)
+	assert.False(t, report.Passed)
+	assert.Nil(t, report.PeakCCU)
+	assert.Equal(t, "-", report.Checks[2].Value)
+
+	// Without thresholds, the test passes unless interrupted.
+	o = &testLoadOpts{flagMaxBots: 100, flagBotsPerPod: 50}
+	report = o.buildLoadTestReport("tough-falcons", samples, false)
+	assert.True(t, report.Passed)
+	assert.Empty(t, report.Checks)
+	report = o.buildLoadTestReport("tough-falcons", samples, true)
+	assert.False(t, report.Passed)
+	assert.True(t, report.Interrupted)
+}
+
+func TestTestLoadPrepare(t *testing.T) {
+	o := &testLoadOpts{flagMaxBots: 100, flagBotsPerPod: 50, flagHoldDuration: time.Minute, flagSampleInterval: 30 * time.Second, flagCCUQuery: defaultLoadTestCCUQuery, flagMaxMemory: "4Gi"}
+	require.NoError(t, o.Prepare(nil, nil))
+	assert.Equal(t, int64(4<<30), o.maxMemoryBytes)
+
+	o.flagMaxMemory = "lots"
+	assert.Error(t, o.Prepare(nil, nil))
+
+	o.flagMaxMemory = ""
+	o.flagMaxBots = 0
+	assert.Error(t, o.Prepare(nil, nil))
+}
@@ -0,0 +1,514 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/metaplay/cli/pkg/testutil"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// \todo Add support for customizing the unit test projects to run (via metaplay-project.yaml?)
+
+// Statuses of the unit test projects.
+const (
+	unitTestPassed = "passed"
+	unitTestFailed = "failed"
+)
+
+// Mount points of the build root and the results directory in the --in-docker container.
+const (
+	unitTestContainerBuildRoot  = "/build"
+	unitTestContainerResultsDir = "/results"
+)
+
+type testUnitOpts struct {
+	flagFilter     string
+	flagInDocker   bool
+	flagIncludeSdk bool
+	flagResultsDir string
+	flagFormat     string
+}
+
+// unitTestProject is a .NET unit test project to run.
+type unitTestProject struct {
+	name string // Name of the project, eg, 'SharedCode.Tests'
+	dir  string // Path to the project directory
+}
+
+// unitTestProjectResult is the result of running the tests in a single project, as parsed
+// from the TRX results file written by 'dotnet test'.
+type unitTestProjectResult struct {
+	Project         string            `json:"project"`
+	Status          string            `json:"status"`
+	DurationSeconds float64           `json:"durationSeconds"`
+	Total           int               `json:"total"`
+	Passed          int               `json:"passed"`
+	Failed          int               `json:"failed"`
+	Skipped         int               `json:"skipped"`
+	FailedTests     []unitTestFailure `json:"failedTests,omitempty"`
+	ResultsFile     string            `json:"resultsFile,omitempty"`
+	Error           string            `json:"error,omitempty"`
+}
+
+type unitTestFailure struct {
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+}
+
+// unitTestReport is the output of --format=json.
+type unitTestReport struct {
+	Status          string                  `json:"status"`
+	DurationSeconds float64                 `json:"durationSeconds"`
+	Projects        []unitTestProjectResult `json:"projects"`
+}
+
+// Subset of the TRX (Visual Studio test results) format written by 'dotnet test --logger trx'.
+type trxTestRun struct {
+	XMLName       xml.Name `xml:"TestRun"`
+	ResultSummary struct {
+		Counters struct {
+			Total       int `xml:"total,attr"`
+			Passed      int `xml:"passed,attr"`
+			Failed      int `xml:"failed,attr"`
+			NotExecuted int `xml:"notExecuted,attr"`
+		} `xml:"Counters"`
+	} `xml:"ResultSummary"`
+	Results []struct {
+		TestName string `xml:"testName,attr"`
+		Outcome  string `xml:"outcome,attr"`
+		Message  string `xml:"Output>ErrorInfo>Message"`
+	} `xml:"Results>UnitTestResult"`
+}
+
+func init() {
+	o := testUnitOpts{}
+
+	cmd := &cobra.Command{
+		Use:     "unit [flags]",
+		Aliases: []string{"dotnet-unit"},
+		Short:   "Run the .NET unit tests of the backend and shared code",
+		Run:     runCommand(&o),
+		Long: renderLong(&o, `
+			Run the .NET unit tests of the project's backend and shared code with 'dotnet test'.
+
+			The following unit test projects are run (if present):
+			- Backend/SharedCode.Tests
+			- Backend/Server.Tests
+
+			With --include-sdk, the Metaplay SDK unit test projects are also run:
+			- MetaplaySDK/Backend/Cloud.Tests
+			- MetaplaySDK/Backend/Cloud.Serialization.Compilation.Tests
+			- MetaplaySDK/Backend/Server.Tests
+
+			By default, the tests are run with the locally installed .NET SDK, which must meet the
+			minimum version required by the Metaplay SDK. With --in-docker, the tests are instead run
+			in the official .NET SDK container image matching the project's 'dotnetRuntimeVersion',
+			which is the same .NET SDK used to build the server image. Use this to get the same
+			results as in CI, or when the right .NET SDK is not installed locally. The build root
+			directory is mounted into the container.
+
+			Use --filter to only run some of the tests. The filter expression is passed to
+			'dotnet test --filter' as-is, see https://learn.microsoft.com/en-us/dotnet/core/testing/selective-unit-tests
+			for the syntax.
+
+			The results of each test project are written into the results directory as TRX files,
+			which most CI systems can display. A summary of the results is printed at the end. Use
+			--format=json to print the summary in JSON format instead, in which case all the other
+			output is written to stderr.
+
+			All the test projects are run even if some of them fail. The command fails if any of
+			the tests failed.
+
+			Related commands:
+			- 'metaplay test integration' runs the integration tests against the server image.
+			- 'metaplay build server' builds the game server locally.
+		`),
+		Example: renderExample(`
+			# Run the project's unit tests.
+			metaplay test unit
+
+			# Also run the Metaplay SDK unit tests.
+			metaplay test unit --include-sdk
+
+			# Run only the tests in the given class.
+			metaplay test unit --filter FullyQualifiedName~PlayerModelTests
+
+			# Run the tests in a .NET SDK container, like in CI.
+			metaplay test unit --in-docker
+
+			# Print the results in JSON format.
+			metaplay test unit --format=json
+		`),
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagFilter, "filter", "", "Only run the tests matching the 'dotnet test --filter' expression")
+	flags.BoolVar(&o.flagInDocker, "in-docker", false, "Run the tests in a .NET SDK container instead of using the local .NET SDK")
+	flags.BoolVar(&o.flagIncludeSdk, "include-sdk", false, "Also run the Metaplay SDK unit tests")
+	flags.StringVar(&o.flagResultsDir, "results-dir", "unit-test-results", "Directory to write the TRX test results into")
+	flags.StringVar(&o.flagFormat, "format", "text", "Output format of the results summary: 'text' or 'json'")
+
+	testCmd.AddCommand(cmd)
+}
+
+func (o *testUnitOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagFormat != "text" && o.flagFormat != "json" {
+		return clierrors.NewUsageErrorf("Invalid format %q", o.flagFormat).
+			WithSuggestion("Use 'text' or 'json'")
+	}
+
+	if o.flagResultsDir == "" {
+		return clierrors.NewUsageError("Results directory must not be empty").
+			WithSuggestion("Specify the directory with --results-dir")
+	}
+
+	return nil
+}
+
+func (o *testUnitOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Resolve project
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	// With JSON output, keep stdout reserved for the report: write the progress logs and the
+	// output of 'dotnet test' to stderr instead.
+	var output io.Writer = os.Stdout
+	if o.flagFormat == "json" {
+		output = os.Stderr
+		stdoutLogger := log.Logger
+		log.Logger = stderrLogger
+		defer func() { log.Logger = stdoutLogger }()
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Run .NET Unit Tests"))
+	log.Info().Msg("")
+
+	// Check for Docker, or the .NET SDK installation and required version (based on SDK version)
+	if o.flagInDocker {
+		if err := checkDockerAvailable(ctx); err != nil {
+			return err
+		}
+		log.Info().Msgf("Using .NET SDK image: %s", styles.RenderTechnical(unitTestDockerImage(project)))
+	} else {
+		if err := checkDotnetSdkVersion(ctx, project.VersionMetadata.MinDotnetSdkVersion); err != nil {
+			return err
+		}
+	}
+
+	testProjects := resolveUnitTestProjects(project, o.flagIncludeSdk)
+	if len(testProjects) == 0 {
+		return clierrors.Newf("No unit test projects found in %s", project.GetBackendDir()).
+			WithSuggestion("Create a SharedCode.Tests or Server.Tests project in the backend directory, or use --include-sdk to run the Metaplay SDK tests")
+	}
+
+	resultsDir, err := filepath.Abs(o.flagResultsDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the results directory: %w", err)
+	}
+	if err := os.MkdirAll(resultsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create the results directory %s: %w", resultsDir, err)
+	}
+
+	// Run the test projects one at a time for clearer output. Keep going on failures to get
+	// the results of all the projects.
+	startTime := time.Now()
+	results := []unitTestProjectResult{}
+	for _, testProject := range testProjects {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderBright(fmt.Sprintf("🔷 Run tests in %s", testProject.name)))
+
+		projectStartTime := time.Now()
+		resultsFileName := testProject.name + ".trx"
+		if err := os.Remove(filepath.Join(resultsDir, resultsFileName)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old test results: %w", err)
+		}
+		runErr := o.runDotnetTest(ctx, project, testProject, resultsDir, resultsFileName, output)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		result := parseUnitTestResults(testProject.name, filepath.Join(resultsDir, resultsFileName), runErr)
+		result.DurationSeconds = time.Since(projectStartTime).Seconds()
+		results = append(results, result)
+	}
+
+	report := unitTestReport{
+		Status:          unitTestPassed,
+		DurationSeconds: time.Since(startTime).Seconds(),
+		Projects:        results,
+	}
+	numFailed := 0
+	for _, result := range results {
+		if result.Status != unitTestPassed {
+			report.Status = unitTestFailed
+			numFailed++
+		}
+	}
+
+	// Output in desired format.
+	if o.flagFormat == "json" {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal the results to JSON: %w", err)
+		}
+		fmt.Println(string(reportJSON))
+	} else {
+		printUnitTestSummary(results)
+		log.Info().Msg("")
+		log.Info().Msgf("Test results written to %s", styles.RenderTechnical(resultsDir))
+	}
+
+	if numFailed > 0 {
+		return clierrors.Newf("Unit tests failed in %d of %d test projects", numFailed, len(results))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess("✅ All .NET unit tests passed"))
+	return nil
+}
+
+// resolveUnitTestProjects returns the unit test projects to run. The project's own test projects
+// are optional and only included if they exist.
+func resolveUnitTestProjects(project *metaproj.MetaplayProject, includeSdk bool) []unitTestProject {
+	testProjects := []unitTestProject{}
+
+	if includeSdk {
+		sdkBackendDir := filepath.Join(project.GetSdkRootDir(), "Backend")
+		for _, name := range []string{"Cloud.Tests", "Cloud.Serialization.Compilation.Tests", "Server.Tests"} {
+			testProjects = append(testProjects, unitTestProject{
+				name: "MetaplaySDK." + name,
+				dir:  filepath.Join(sdkBackendDir, name),
+			})
+		}
+	}
+
+	backendDir := project.GetBackendDir()
+	for _, name := range []string{"SharedCode.Tests", "Server.Tests"} {
+		projectDir := filepath.Join(backendDir, name)
+		if st, err := os.Stat(projectDir); err == nil && st.IsDir() {
+			testProjects = append(testProjects, unitTestProject{name: name, dir: projectDir})
+		}
+	}
+
+	return testProjects
+}
+
+// dotnetTestArgs returns the arguments for 'dotnet test' to write the results of the project
+// into a TRX file in resultsDir.
+func dotnetTestArgs(filter, resultsDir, resultsFileName string) []string {
+	args := []string{
+		"test",
+		"--logger", "trx;LogFileName=" + resultsFileName,
+		"--results-directory", resultsDir,
+	}
+	if filter != "" {
+		args = append(args, "--filter", filter)
+	}
+	return args
+}
+
+// unitTestDockerImage returns the .NET SDK image matching the project's .NET runtime version,
+// ie, the same SDK version used when building the server image.
+func unitTestDockerImage(project *metaproj.MetaplayProject) string {
+	segments := project.Config.DotnetRuntimeVersion.Segments()
+	return fmt.Sprintf("mcr.microsoft.com/dotnet/sdk:%d.%d", segments[0], segments[1])
+}
+
+// runDotnetTest runs 'dotnet test' for the test project, either locally or in a .NET SDK container.
+func (o *testUnitOpts) runDotnetTest(ctx context.Context, project *metaproj.MetaplayProject, testProject unitTestProject, resultsDir, resultsFileName string, output io.Writer) error {
+	if !o.flagInDocker {
+		args := dotnetTestArgs(o.flagFilter, resultsDir, resultsFileName)
+		cmd := exec.CommandContext(ctx, "dotnet", args...)
+		cmd.Dir = testProject.dir
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		log.Info().Msg(styles.RenderMuted(fmt.Sprintf("%s$ dotnet %s", testProject.dir, strings.Join(args, " "))))
+		cleanup, err := startCmd(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to start dotnet: %w", err)
+		}
+		defer cleanup()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("dotnet exited with error: %w", err)
+		}
+		return nil
+	}
+
+	// Mount the build root (which contains both the SDK and the project) into the container,
+	// and run the tests in the test project's directory within it.
+	buildRootDir, err := filepath.Abs(project.GetBuildRootDir())
+	if err != nil {
+		return fmt.Errorf("failed to resolve the build root directory: %w", err)
+	}
+	containerWorkDir, err := unitTestContainerPath(buildRootDir, testProject.dir)
+	if err != nil {
+		return err
+	}
+
+	image := unitTestDockerImage(project)
+	args := dotnetTestArgs(o.flagFilter, unitTestContainerResultsDir, resultsFileName)
+	log.Info().Msg(styles.RenderMuted(fmt.Sprintf("[%s] %s$ dotnet %s", image, containerWorkDir, strings.Join(args, " "))))
+
+	container := testutil.RunOnceContainerOptions{
+		Image:      image,
+		Cmd:        append([]string{"dotnet"}, args...),
+		WorkingDir: containerWorkDir,
+		Mounts: []string{
+			buildRootDir + ":" + unitTestContainerBuildRoot,
+			resultsDir + ":" + unitTestContainerResultsDir,
+		},
+		Env: map[string]string{
+			"HOME":                        "/tmp",
+			"DOTNET_CLI_HOME":             "/tmp",
+			"DOTNET_CLI_TELEMETRY_OPTOUT": "1",
+			"DOTNET_NOLOGO":               "1",
+			"CI":                          "true",
+		},
+		LogPrefix:  "[dotnet] ",
+		LogWriter:  output,
+		AutoRemove: true,
+	}
+
+	// Run as the current user so the build outputs and results in the mounted directories
+	// are not owned by root. Not needed with Docker Desktop on Windows.
+	if runtime.GOOS != "windows" {
+		container.User = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	}
+
+	exitCode, err := testutil.RunContainerToCompletion(ctx, container)
+	if err != nil {
+		return fmt.Errorf("failed to run dotnet test container: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("dotnet exited with code %d", exitCode)
+	}
+	return nil
+}
+
+// unitTestContainerPath returns the path of hostPath within the --in-docker container, where
+// the build root is mounted. The path must be within the build root.
+func unitTestContainerPath(buildRootDir, hostPath string) (string, error) {
+	absPath, err := filepath.Abs(hostPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %w", hostPath, err)
+	}
+	relPath, err := filepath.Rel(buildRootDir, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", clierrors.Newf("Test project %s is not within the build root directory %s", hostPath, buildRootDir).
+			WithSuggestion("Run the tests without --in-docker, or set 'buildRootDir' in metaplay-project.yaml to include the directory")
+	}
+	return unitTestContainerBuildRoot + "/" + filepath.ToSlash(relPath), nil
+}
+
+// parseUnitTestResults resolves the result of a test project from its TRX results file and the
+// error from 'dotnet test'. The results file is missing if the tests didn't run, eg, on build errors.
+func parseUnitTestResults(projectName, resultsPath string, runErr error) unitTestProjectResult {
+	result := unitTestProjectResult{Project: projectName, Status: unitTestPassed}
+
+	content, err := os.ReadFile(resultsPath)
+	if err != nil {
+		result.Status = unitTestFailed
+		if runErr != nil {
+			result.Error = runErr.Error()
+		} else {
+			result.Error = fmt.Sprintf("failed to read test results: %v", err)
+		}
+		return result
+	}
+
+	var testRun trxTestRun
+	if err := xml.Unmarshal(content, &testRun); err != nil {
+		result.Status = unitTestFailed
+		result.Error = fmt.Sprintf("failed to parse test results %s: %v", resultsPath, err)
+		return result
+	}
+
+	counters := testRun.ResultSummary.Counters
+	result.ResultsFile = resultsPath
+	result.Total = counters.Total
+	result.Passed = counters.Passed
+	result.Failed = counters.Failed
+	result.Skipped = counters.NotExecuted
+	for _, testResult := range testRun.Results {
+		if testResult.Outcome == "Failed" {
+			result.FailedTests = append(result.FailedTests, unitTestFailure{
+				Name:    testResult.TestName,
+				Message: strings.TrimSpace(testResult.Message),
+			})
+		}
+	}
+
+	if runErr != nil || result.Failed > 0 {
+		result.Status = unitTestFailed
+		if runErr != nil && result.Failed == 0 {
+			result.Error = runErr.Error()
+		}
+	}
+	return result
+}
+
+// printUnitTestSummary prints the results of the test projects as a table, followed by the
+// failed tests.
+func printUnitTestSummary(results []unitTestProjectResult) {
+	nameW := len("PROJECT")
+	for _, result := range results {
+		nameW = max(nameW, len(result.Project))
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Test Summary"))
+	log.Info().Msg("")
+	log.Info().Msgf("  %-*s  %-8s  %6s  %6s  %7s  %6s  %s", nameW, "PROJECT", "RESULT", "PASSED", "FAILED", "SKIPPED", "TOTAL", "DURATION")
+	for _, result := range results {
+		// Pad plain text before applying ANSI styles.
+		status := fmt.Sprintf("%-8s", result.Status)
+		if result.Status == unitTestPassed {
+			status = styles.RenderSuccess(status)
+		} else {
+			status = styles.RenderError(status)
+		}
+		duration := (time.Duration(result.DurationSeconds * float64(time.Second))).Round(time.Second).String()
+		log.Info().Msgf("  %-*s  %s  %6d  %6d  %7d  %6d  %s", nameW, result.Project, status, result.Passed, result.Failed, result.Skipped, result.Total, duration)
+	}
+
+	for _, result := range results {
+		if result.Error != "" {
+			log.Info().Msg("")
+			log.Info().Msgf("%s %s: %s", styles.RenderError("❌"), result.Project, result.Error)
+		}
+		if len(result.FailedTests) > 0 {
+			log.Info().Msg("")
+			log.Info().Msgf("%s Failed tests in %s:", styles.RenderError("❌"), result.Project)
+			for _, failure := range result.FailedTests {
+				log.Info().Msgf("  - %s", failure.Name)
+				if failure.Message != "" {
+					log.Info().Msg(styles.RenderMuted("    " + strings.ReplaceAll(failure.Message, "\n", "\n    ")))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTrxResults = `<?xml version="1.0" encoding="utf-8"?>
+<TestRun id="1c5f0e4e" name="runner 2026-10-16 12:00:00" xmlns="http://microsoft.com/schemas/VisualStudio/TeamTest/2010">
+  <Results>
+    <UnitTestResult testId="1" testName="PlayerModelTests.TestLevelUp" outcome="Passed" duration="00:00:00.0120000" />
+    <UnitTestResult testId="2" testName="PlayerModelTests.TestInventory" outcome="Failed" duration="00:00:00.0030000">
+      <Output>
+        <ErrorInfo>
+          <Message>
+  Expected: 3
+  But was:  2
+          </Message>
+          <StackTrace>at PlayerModelTests.TestInventory()</StackTrace>
+        </ErrorInfo>
+      </Output>
+    </UnitTestResult>
+    <UnitTestResult testId="3" testName="PlayerModelTests.TestSlow" outcome="NotExecuted" />
+  </Results>
+  <ResultSummary outcome="Failed">
+    <Counters total="3" executed="2" passed="1" failed="1" error="0" timeout="0" aborted="0" inconclusive="0" notExecuted="1" />
+  </ResultSummary>
+</TestRun>`
+
+func TestParseUnitTestResults(t *testing.T) {
+	resultsPath := filepath.Join(t.TempDir(), "SharedCode.Tests.trx")
+	require.NoError(t, os.WriteFile(resultsPath, []byte(testTrxResults), 0644))
+
+	result := parseUnitTestResults("SharedCode.Tests", resultsPath, errors.New("dotnet exited with error: exit status 1"))
+	assert.Equal(t, unitTestFailed, result.Status)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, resultsPath, result.ResultsFile)
+	assert.Empty(t, result.Error) // The failed tests explain the error
+	assert.Equal(t, []unitTestFailure{{Name: "PlayerModelTests.TestInventory", Message: "Expected: 3\n  But was:  2"}}, result.FailedTests)
+
+	// Missing results file, eg, on build errors.
+	result = parseUnitTestResults("Server.Tests", filepath.Join(t.TempDir(), "missing.trx"), errors.New("dotnet exited with error: exit status 1"))
+	assert.Equal(t, unitTestFailed, result.Status)
+	assert.Equal(t, "dotnet exited with error: exit status 1", result.Error)
+	assert.Empty(t, result.ResultsFile)
+
+	// Invalid results file.
+	require.NoError(t, os.WriteFile(resultsPath, []byte("not xml"), 0644))
+	result = parseUnitTestResults("SharedCode.Tests", resultsPath, nil)
+	assert.Equal(t, unitTestFailed, result.Status)
+	assert.Contains(t, result.Error, "failed to parse test results")
+}
+
+func TestParseUnitTestResultsPassed(t *testing.T) {
+	resultsPath := filepath.Join(t.TempDir(), "SharedCode.Tests.trx")
+	content := `<TestRun><ResultSummary outcome="Completed"><Counters total="2" passed="2" failed="0" notExecuted="0" /></ResultSummary></TestRun>`
+	require.NoError(t, os.WriteFile(resultsPath, []byte(content), 0644))
+
+	result := parseUnitTestResults("SharedCode.Tests", resultsPath, nil)
+	assert.Equal(t, unitTestProjectResult{Project: "SharedCode.Tests", Status: unitTestPassed, Total: 2, Passed: 2, ResultsFile: resultsPath}, result)
+
+	// A failing 'dotnet test' fails the project even if the results look good, eg, on test host crashes.
+	result = parseUnitTestResults("SharedCode.Tests", resultsPath, errors.New("dotnet exited with error: exit status 1"))
+	assert.Equal(t, unitTestFailed, result.Status)
+	assert.Equal(t, "dotnet exited with error: exit status 1", result.Error)
+}
+
+func TestDotnetTestArgs(t *testing.T) {
+	assert.Equal(t, []string{"test", "--logger", "trx;LogFileName=Server.Tests.trx", "--results-directory", "/results"},
+		dotnetTestArgs("", "/results", "Server.Tests.trx"))
+	assert.Equal(t, []string{"test", "--logger", "trx;LogFileName=Server.Tests.trx", "--results-directory", "/results", "--filter", "FullyQualifiedName~PlayerModelTests"},
+		dotnetTestArgs("FullyQualifiedName~PlayerModelTests", "/results", "Server.Tests.trx"))
+}
+
+func TestUnitTestContainerPath(t *testing.T) {
+	buildRootDir := t.TempDir()
+
+	containerPath, err := unitTestContainerPath(buildRootDir, filepath.Join(buildRootDir, "MetaplaySDK", "Backend", "Cloud.Tests"))
+	require.NoError(t, err)
+	assert.Equal(t, "/build/MetaplaySDK/Backend/Cloud.Tests", containerPath)
+
+	_, err = unitTestContainerPath(buildRootDir, filepath.Join(filepath.Dir(buildRootDir), "Backend", "Server.Tests"))
+	assert.Error(t, err)
+}
+
+func TestTestUnitPrepare(t *testing.T) {
+	o := &testUnitOpts{flagFormat: "text", flagResultsDir: "unit-test-results"}
+	require.NoError(t, o.Prepare(nil, nil))
+
+	o.flagFormat = "xml"
+	assert.Error(t, o.Prepare(nil, nil))
+
+	o.flagFormat = "json"
+	o.flagResultsDir = ""
+	assert.Error(t, o.Prepare(nil, nil))
+}
@@ -0,0 +1,555 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Name of the SDK modifications patch file written by 'metaplay update sdk' into the project directory.
+const sdkModificationsPatchFileName = "metaplay-sdk-modifications.patch"
+
+// Re-apply the SDK modifications patch after updating the SDK.
+type updateApplyPatchOpts struct {
+	UsePositionalArgs
+
+	argPatchFile string
+	flagDryRun   bool
+}
+
+// patchHunkResult is the result of applying a single hunk of a patch.
+type patchHunkResult int
+
+const (
+	patchHunkApplied        patchHunkResult = iota // The hunk was applied
+	patchHunkAlreadyApplied                        // The file already contains the changed lines
+	patchHunkFailed                                // The hunk could not be applied
+)
+
+// patchFileDiff is the diff of a single file in a multi-file unified diff.
+type patchFileDiff struct {
+	OldPath        string       // Path of the file before the change (without the 'a/' prefix), empty for new files
+	NewPath        string       // Path of the file after the change (without the 'b/' prefix), empty for deleted files
+	Header         []string     // The 'diff', 'new file mode', '---', '+++', etc. lines before the hunks
+	Hunks          []rejectHunk // Hunks of the diff
+	NoNewlineAtEnd bool         // The file doesn't end in a newline after the change
+}
+
+// patchFileResult is the result of applying the diff of a single file.
+type patchFileResult struct {
+	Diff        *patchFileDiff
+	TargetPath  string            // Path to the patched file on disk
+	HunkResults []patchHunkResult // Result of each hunk of the diff
+	Content     *string           // Content of the patched file, nil if the file is to be deleted
+	Changed     bool              // Whether the file needs to be written or deleted
+}
+
+var patchHunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+func init() {
+	o := updateApplyPatchOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argPatchFile, "PATCH", "Path to the patch file. Defaults to 'metaplay-sdk-modifications.patch' in the project directory.")
+
+	cmd := &cobra.Command{
+		Use:   "apply-patch [PATCH] [flags]",
+		Short: "Re-apply the SDK modifications patch after updating the SDK",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Re-apply your SDK modifications, saved in metaplay-sdk-modifications.patch by
+			'metaplay update sdk', to the updated SDK. The patch is applied with a built-in
+			patch applier, so neither 'patch' nor 'git' are needed.
+
+			Each hunk of the patch is first tried at its original location, adjusted for the
+			preceding hunks. If the SDK file has changed around it, the hunk is applied where its
+			original lines are found exactly once in the file, eg, because the code moved or only
+			the whitespace differs. The following hunks are then expected at the same offset. When
+			only the whitespace differs, the unchanged lines around the changes keep the whitespace
+			of the SDK file. Hunks whose changes are already in the file are skipped.
+
+			The hunks that cannot be applied are written to .rej files next to the files they were
+			rejected from. Use 'metaplay update resolve-rejects' to go through them.
+
+			With --dry-run, only the results of applying the patch are shown and no files are
+			modified.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay update sdk' to update the SDK and extract your SDK modifications as a patch.
+			- 'metaplay update resolve-rejects' to resolve the hunks that could not be applied.
+		`),
+		Example: renderExample(`
+			# Re-apply the SDK modifications after 'metaplay update sdk'.
+			metaplay update apply-patch
+
+			# Preview what would be applied.
+			metaplay update apply-patch --dry-run
+
+			# Apply a specific patch file.
+			metaplay update apply-patch my-sdk-changes.patch
+		`),
+	}
+	updateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *updateApplyPatchOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *updateApplyPatchOpts) Run(cmd *cobra.Command) error {
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+	projectConfig, err := metaproj.LoadProjectConfigFile(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	sdkRootDir := filepath.Join(projectDir, projectConfig.SdkRootDir)
+
+	patchPath := o.argPatchFile
+	if patchPath == "" {
+		patchPath = filepath.Join(projectDir, sdkModificationsPatchFileName)
+	}
+	patchContent, err := os.ReadFile(patchPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return clierrors.Newf("Patch file %s not found", patchPath).
+			WithSuggestion("The patch file is written by 'metaplay update sdk' when SDK modifications are detected")
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", patchPath, err)
+	}
+
+	diffs, err := parsePatchFile(string(patchContent))
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to parse patch file %s", patchPath)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Apply SDK Modifications Patch"))
+	log.Info().Msg("")
+	log.Info().Msgf("Patch file: %s", styles.RenderTechnical(patchPath))
+	log.Info().Msgf("SDK directory: %s", styles.RenderTechnical(sdkRootDir))
+	if o.flagDryRun {
+		log.Info().Msg(styles.RenderMuted("Dry-run mode: no files will be modified"))
+	}
+	log.Info().Msg("")
+
+	numApplied := 0
+	numAlreadyApplied := 0
+	numFailed := 0
+	for _, diff := range diffs {
+		targetPath, err := resolvePatchTargetPath(projectDir, sdkRootDir, diff.targetPath())
+		if err != nil {
+			return err
+		}
+
+		result, err := applyPatchFileDiff(diff, targetPath)
+		if err != nil {
+			return err
+		}
+
+		// Report the results of the file.
+		applied, alreadyApplied, failed := result.countHunks()
+		numApplied += applied
+		numAlreadyApplied += alreadyApplied
+		numFailed += failed
+		relPath, err := filepath.Rel(projectDir, targetPath)
+		if err != nil {
+			relPath = targetPath
+		}
+		var status string
+		switch {
+		case failed > 0:
+			status = styles.RenderError(fmt.Sprintf("%d of %d hunk(s) failed", failed, len(result.HunkResults)))
+		case applied == 0:
+			status = styles.RenderMuted("already applied")
+		case result.Content == nil:
+			status = styles.RenderSuccess("deleted")
+		case diff.OldPath == "":
+			status = styles.RenderSuccess("created")
+		default:
+			status = styles.RenderSuccess(fmt.Sprintf("%d hunk(s) applied", applied))
+		}
+		log.Info().Msgf("  %s: %s", filepath.ToSlash(relPath), status)
+
+		if o.flagDryRun {
+			continue
+		}
+
+		// Write the patched file and the rejected hunks.
+		if err := result.write(); err != nil {
+			return err
+		}
+		if failed > 0 {
+			rejectPath := targetPath + ".rej"
+			if err := os.WriteFile(rejectPath, []byte(result.renderRejects()), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", rejectPath, err)
+			}
+		}
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("Hunks applied: %d, already applied: %d, failed: %d", numApplied, numAlreadyApplied, numFailed)
+	log.Info().Msg("")
+	if numFailed > 0 {
+		if o.flagDryRun {
+			log.Info().Msg(styles.RenderWarning(fmt.Sprintf("%d hunk(s) could not be applied", numFailed)))
+			log.Info().Msg("Run without --dry-run to apply the patch and write the failed hunks to .rej files.")
+		} else {
+			log.Info().Msg(styles.RenderWarning(fmt.Sprintf("%d hunk(s) could not be applied and were saved to .rej files", numFailed)))
+			log.Info().Msgf("Use %s to go through them.", styles.RenderPrompt("metaplay update resolve-rejects"))
+		}
+	} else if o.flagDryRun {
+		log.Info().Msg(styles.RenderSuccess("✅ The patch can be applied cleanly"))
+	} else {
+		log.Info().Msg(styles.RenderSuccess("✅ SDK modifications patch applied"))
+		log.Info().Msgf("Once you have checked the changes, you can delete %s.", styles.RenderTechnical(patchPath))
+	}
+	return nil
+}
+
+// targetPath returns the path of the file the diff applies to.
+func (diff *patchFileDiff) targetPath() string {
+	if diff.NewPath != "" {
+		return diff.NewPath
+	}
+	return diff.OldPath
+}
+
+// parsePatchPath parses the path of a '---' or '+++' line, stripping the 'a/' or 'b/' prefix
+// and any trailing timestamp. Returns an empty path for /dev/null.
+func parsePatchPath(value string) string {
+	if tab := strings.IndexByte(value, '\t'); tab >= 0 {
+		value = value[:tab]
+	}
+	if value == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(value, "a/") || strings.HasPrefix(value, "b/") {
+		return value[2:]
+	}
+	return value
+}
+
+// parsePatchFile parses a multi-file unified diff, as written by 'metaplay update sdk' or
+// 'git diff'.
+func parsePatchFile(content string) ([]*patchFileDiff, error) {
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+
+	var diffs []*patchFileDiff
+	var diff *patchFileDiff
+	for ndx := 0; ndx < len(lines); ndx++ {
+		line := lines[ndx]
+
+		// A 'diff' line, or a '---' line after the hunks of the previous file, starts a new file.
+		if strings.HasPrefix(line, "diff ") || (strings.HasPrefix(line, "--- ") && (diff == nil || len(diff.Hunks) > 0)) {
+			diff = &patchFileDiff{}
+			diffs = append(diffs, diff)
+		}
+
+		match := patchHunkHeaderRegex.FindStringSubmatch(line)
+		if match == nil {
+			if diff == nil {
+				continue // Ignore any text before the first file
+			}
+			if len(diff.Hunks) > 0 {
+				return nil, fmt.Errorf("unexpected line after hunk %s: %q", diff.Hunks[len(diff.Hunks)-1].Header, line)
+			}
+			if value, ok := strings.CutPrefix(line, "--- "); ok {
+				diff.OldPath = parsePatchPath(value)
+			} else if value, ok := strings.CutPrefix(line, "+++ "); ok {
+				diff.NewPath = parsePatchPath(value)
+			}
+			diff.Header = append(diff.Header, line)
+			continue
+		}
+
+		if diff == nil || (diff.OldPath == "" && diff.NewPath == "") {
+			return nil, fmt.Errorf("hunk %s without file headers", line)
+		}
+
+		// Read the hunk lines, using the line counts of the header to know where the hunk ends.
+		oldStart, _ := strconv.Atoi(match[1])
+		oldCount, newCount := 1, 1
+		if match[2] != "" {
+			oldCount, _ = strconv.Atoi(match[2])
+		}
+		if match[4] != "" {
+			newCount, _ = strconv.Atoi(match[4])
+		}
+		hunk := rejectHunk{OldStart: oldStart, Header: line}
+		// A '\ No newline at end of file' marker applies to the preceding line. Only the
+		// new side matters, as the new content is what gets written.
+		markNoNewline := func(hunkLines []string) {
+			if len(hunkLines) > 0 && hunkLines[len(hunkLines)-1][0] != '-' {
+				diff.NoNewlineAtEnd = true
+			}
+		}
+		for oldCount > 0 || newCount > 0 {
+			ndx++
+			if ndx >= len(lines) {
+				return nil, fmt.Errorf("hunk %s of %s ends prematurely", hunk.Header, diff.targetPath())
+			}
+			hunkLine := lines[ndx]
+			if hunkLine == "" {
+				// Some tools strip the trailing space of empty context lines.
+				hunkLine = " "
+			}
+			switch hunkLine[0] {
+			case ' ':
+				oldCount--
+				newCount--
+			case '-':
+				oldCount--
+			case '+':
+				newCount--
+			case '\\':
+				markNoNewline(hunk.Lines)
+				continue
+			default:
+				return nil, fmt.Errorf("unexpected line in hunk %s of %s: %q", hunk.Header, diff.targetPath(), hunkLine)
+			}
+			hunk.Lines = append(hunk.Lines, hunkLine)
+		}
+
+		if ndx+1 < len(lines) && strings.HasPrefix(lines[ndx+1], "\\") {
+			ndx++
+			markNoNewline(hunk.Lines)
+		}
+		diff.Hunks = append(diff.Hunks, hunk)
+	}
+
+	if len(diffs) == 0 {
+		return nil, fmt.Errorf("no file diffs found")
+	}
+	return diffs, nil
+}
+
+// resolvePatchTargetPath resolves the path of a file in the patch to the file on disk. The paths of
+// the SDK files are relative to the parent of the SDK directory ('MetaplaySDK/...'); other paths are
+// relative to the project directory.
+func resolvePatchTargetPath(projectDir, sdkRootDir, pathInPatch string) (string, error) {
+	cleanPath := filepath.Clean(filepath.FromSlash(pathInPatch))
+	if filepath.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+		return "", clierrors.Newf("Invalid path '%s' in patch file", pathInPatch).
+			WithSuggestion("Paths in the patch must be relative and stay within the project")
+	}
+	if relPath, ok := strings.CutPrefix(filepath.ToSlash(cleanPath), "MetaplaySDK/"); ok {
+		return filepath.Join(sdkRootDir, filepath.FromSlash(relPath)), nil
+	}
+	return filepath.Join(projectDir, cleanPath), nil
+}
+
+// applyPatchFileDiff applies the hunks of the diff to the file at targetPath. The file is not
+// modified; the resulting content is returned in the result.
+func applyPatchFileDiff(diff *patchFileDiff, targetPath string) (*patchFileResult, error) {
+	result := &patchFileResult{Diff: diff, TargetPath: targetPath}
+
+	existingContent, err := os.ReadFile(targetPath)
+	fileExists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", targetPath, err)
+	}
+
+	// New files are created as is, unless a file with different content already exists.
+	if diff.OldPath == "" {
+		var newLines []string
+		for _, hunk := range diff.Hunks {
+			newLines = append(newLines, hunk.newLines()...)
+		}
+		newContent := joinPatchedLines(newLines, "\n", !diff.NoNewlineAtEnd)
+		hunkResult := patchHunkApplied
+		if fileExists {
+			existingLines, _, _ := splitFileLines(string(existingContent))
+			if slicesEqualIgnoringWhitespace(existingLines, newLines) {
+				hunkResult = patchHunkAlreadyApplied
+			} else {
+				hunkResult = patchHunkFailed
+			}
+		}
+		for range diff.Hunks {
+			result.HunkResults = append(result.HunkResults, hunkResult)
+		}
+		result.Content = &newContent
+		result.Changed = hunkResult == patchHunkApplied
+		return result, nil
+	}
+
+	// Deleted files are only deleted if they haven't changed.
+	if diff.NewPath == "" {
+		var oldLines []string
+		for _, hunk := range diff.Hunks {
+			oldLines = append(oldLines, hunk.oldLines()...)
+		}
+		hunkResult := patchHunkAlreadyApplied
+		if fileExists {
+			existingLines, _, _ := splitFileLines(string(existingContent))
+			if slicesEqualIgnoringWhitespace(existingLines, oldLines) {
+				hunkResult = patchHunkApplied
+			} else {
+				hunkResult = patchHunkFailed
+			}
+		}
+		for range diff.Hunks {
+			result.HunkResults = append(result.HunkResults, hunkResult)
+		}
+		result.Changed = hunkResult == patchHunkApplied
+		return result, nil
+	}
+
+	// Modified files must exist.
+	if !fileExists {
+		for range diff.Hunks {
+			result.HunkResults = append(result.HunkResults, patchHunkFailed)
+		}
+		return result, nil
+	}
+
+	lines, lineEnding, hasFinalNewline := splitFileLines(string(existingContent))
+	lineDelta := 0 // Offset of the hunks from their original location, from the hunks applied so far
+	for _, hunk := range diff.Hunks {
+		hunkResult, start := locatePatchHunk(lines, hunk, lineDelta)
+		result.HunkResults = append(result.HunkResults, hunkResult)
+		if hunkResult != patchHunkApplied {
+			continue
+		}
+
+		oldLines := hunk.oldLines()
+		newLines := hunk.newLines()
+		if start+len(oldLines) == len(lines) && len(hunk.Lines) > 0 {
+			// The hunk reaches the end of the file, so it determines the final newline.
+			hasFinalNewline = !diff.NoNewlineAtEnd
+		}
+		lines = replaceHunkLines(lines, start, hunk)
+
+		// Like patch(1), expect the following hunks at the same offset as this one was found at.
+		lineDelta = start - patchHunkOrigin(hunk) + len(newLines) - len(oldLines)
+		result.Changed = true
+	}
+
+	content := joinPatchedLines(lines, lineEnding, hasFinalNewline)
+	result.Content = &content
+	return result, nil
+}
+
+// locatePatchHunk finds where the hunk applies in the file lines. The hunk's original location,
+// adjusted by the line count changes of the preceding hunks, is preferred. Otherwise, the hunk is
+// matched like a rejected hunk: it is already applied, or its original lines are found exactly once.
+func locatePatchHunk(lines []string, hunk rejectHunk, lineDelta int) (patchHunkResult, int) {
+	oldLines := hunk.oldLines()
+	expected := patchHunkOrigin(hunk) + lineDelta
+	if expected >= 0 && expected+len(oldLines) <= len(lines) {
+		matches := true
+		for ndx, line := range oldLines {
+			if lines[expected+ndx] != line {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return patchHunkApplied, expected
+		}
+	}
+
+	switch status, start := classifyRejectHunk(lines, hunk); status {
+	case rejectHunkApplicable:
+		return patchHunkApplied, start
+	case rejectHunkAlreadyApplied:
+		return patchHunkAlreadyApplied, -1
+	default:
+		return patchHunkFailed, -1
+	}
+}
+
+// patchHunkOrigin returns the index of the first original line of the hunk in the original file.
+func patchHunkOrigin(hunk rejectHunk) int {
+	if len(hunk.oldLines()) == 0 {
+		// Pure additions are inserted after the line OldStart.
+		return hunk.OldStart
+	}
+	return hunk.OldStart - 1
+}
+
+// joinPatchedLines joins the lines back into file content.
+func joinPatchedLines(lines []string, lineEnding string, hasFinalNewline bool) string {
+	content := strings.Join(lines, lineEnding)
+	if hasFinalNewline && len(lines) > 0 {
+		content += lineEnding
+	}
+	return content
+}
+
+// slicesEqualIgnoringWhitespace returns whether the lines are equal, ignoring trailing whitespace
+// and indentation differences.
+func slicesEqualIgnoringWhitespace(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || len(findLineSequence(a, b, true)) == 1
+}
+
+// countHunks returns the number of applied, already applied, and failed hunks.
+func (result *patchFileResult) countHunks() (applied, alreadyApplied, failed int) {
+	for _, hunkResult := range result.HunkResults {
+		switch hunkResult {
+		case patchHunkApplied:
+			applied++
+		case patchHunkAlreadyApplied:
+			alreadyApplied++
+		case patchHunkFailed:
+			failed++
+		}
+	}
+	return applied, alreadyApplied, failed
+}
+
+// write writes the patched file, or deletes it, if the patch changed it.
+func (result *patchFileResult) write() error {
+	if !result.Changed {
+		return nil
+	}
+	if result.Content == nil {
+		if err := os.Remove(result.TargetPath); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", result.TargetPath, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(result.TargetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", result.TargetPath, err)
+	}
+	if err := os.WriteFile(result.TargetPath, []byte(*result.Content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", result.TargetPath, err)
+	}
+	return nil
+}
+
+// renderRejects returns the .rej file content with the file headers and the failed hunks, in the
+// format read by 'metaplay update resolve-rejects'.
+func (result *patchFileResult) renderRejects() string {
+	rej := &rejectFile{Preamble: result.Diff.Header}
+	for ndx, hunk := range result.Diff.Hunks {
+		if result.HunkResults[ndx] == patchHunkFailed {
+			rej.Hunks = append(rej.Hunks, hunk)
+		}
+	}
+	return rej.Render()
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPatchOldFoo = "class Foo\n{\n    void A()\n    {\n        Bar(1);\n    }\n\n    void B()\n    {\n    }\n}\n"
+const testPatchNewFoo = "class Foo\n{\n    void A()\n    {\n        Bar(2);\n    }\n\n    void B()\n    {\n    }\n}\n"
+
+func TestParsePatchFile(t *testing.T) {
+	patch := generateUnifiedDiff("MetaplaySDK/Foo.cs", []byte(testPatchOldFoo), []byte(testPatchNewFoo), false, false) +
+		generateUnifiedDiff("MetaplaySDK/New.cs", nil, []byte("// New\n-- not a header\nclass New {}"), true, false) +
+		generateUnifiedDiff("MetaplaySDK/Old.cs", []byte("class Old {}\n"), nil, false, true)
+
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+
+	assert.Equal(t, "MetaplaySDK/Foo.cs", diffs[0].OldPath)
+	assert.Equal(t, "MetaplaySDK/Foo.cs", diffs[0].NewPath)
+	require.Len(t, diffs[0].Hunks, 1)
+	assert.Equal(t, 2, diffs[0].Hunks[0].OldStart)
+	assert.False(t, diffs[0].NoNewlineAtEnd)
+
+	// New files, with a line that looks like a file header, and no final newline.
+	assert.Empty(t, diffs[1].OldPath)
+	assert.Equal(t, "MetaplaySDK/New.cs", diffs[1].targetPath())
+	assert.Equal(t, []string{"// New", "-- not a header", "class New {}"}, diffs[1].Hunks[0].newLines())
+	assert.True(t, diffs[1].NoNewlineAtEnd)
+
+	// Deleted files.
+	assert.Empty(t, diffs[2].NewPath)
+	assert.Equal(t, "MetaplaySDK/Old.cs", diffs[2].targetPath())
+
+	_, err = parsePatchFile("")
+	assert.Error(t, err)
+	_, err = parsePatchFile("--- a/Foo.cs\n+++ b/Foo.cs\n@@ -1,3 +1,3 @@\n a\n-b\n")
+	assert.Error(t, err)
+}
+
+func TestResolvePatchTargetPath(t *testing.T) {
+	projectDir := "project"
+	sdkRootDir := filepath.Join("sdk", "MetaplaySDK")
+
+	targetPath, err := resolvePatchTargetPath(projectDir, sdkRootDir, "MetaplaySDK/Backend/Foo.cs")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(sdkRootDir, "Backend", "Foo.cs"), targetPath)
+
+	targetPath, err = resolvePatchTargetPath(projectDir, sdkRootDir, "Backend/Server/Bar.cs")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(projectDir, "Backend", "Server", "Bar.cs"), targetPath)
+
+	_, err = resolvePatchTargetPath(projectDir, sdkRootDir, "../outside.cs")
+	assert.Error(t, err)
+}
+
+func TestApplyPatchFileDiff(t *testing.T) {
+	dir := t.TempDir()
+	patch := generateUnifiedDiff("Foo.cs", []byte(testPatchOldFoo), []byte(testPatchNewFoo), false, false)
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+	diff := diffs[0]
+	targetPath := filepath.Join(dir, "Foo.cs")
+
+	// Unchanged file, with Windows line endings.
+	require.NoError(t, os.WriteFile(targetPath, []byte(strings.ReplaceAll(testPatchOldFoo, "\n", "\r\n")), 0644))
+	result, err := applyPatchFileDiff(diff, targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied}, result.HunkResults)
+	require.NoError(t, result.write())
+	content, err := os.ReadFile(targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(testPatchNewFoo, "\n", "\r\n"), string(content))
+
+	// Applying again finds the changes already in the file.
+	result, err = applyPatchFileDiff(diff, targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkAlreadyApplied}, result.HunkResults)
+	assert.False(t, result.Changed)
+
+	// The new SDK has added lines before the hunk.
+	require.NoError(t, os.WriteFile(targetPath, []byte("using System;\n\n"+testPatchOldFoo), 0644))
+	result, err = applyPatchFileDiff(diff, targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied}, result.HunkResults)
+	assert.Equal(t, "using System;\n\n"+testPatchNewFoo, *result.Content)
+
+	// The new SDK has changed the same lines.
+	conflicting := "class Foo\n{\n    void A()\n    {\n        Baz(1);\n    }\n}\n"
+	require.NoError(t, os.WriteFile(targetPath, []byte(conflicting), 0644))
+	result, err = applyPatchFileDiff(diff, targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkFailed}, result.HunkResults)
+	assert.False(t, result.Changed)
+
+	// The rejected hunks can be read by 'update resolve-rejects'.
+	rej, err := parseRejectFile(result.renderRejects())
+	require.NoError(t, err)
+	assert.Equal(t, diff.Header, rej.Preamble)
+	assert.Equal(t, diff.Hunks, rej.Hunks)
+
+	// Modified files that no longer exist.
+	result, err = applyPatchFileDiff(diff, filepath.Join(dir, "Missing.cs"))
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkFailed}, result.HunkResults)
+}
+
+func TestApplyPatchFileDiffNewAndDeleted(t *testing.T) {
+	dir := t.TempDir()
+	patch := generateUnifiedDiff("New.cs", nil, []byte("class New\n{\n}"), true, false) +
+		generateUnifiedDiff("Old.cs", []byte("class Old\n{\n}\n"), nil, false, true)
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+	newPath := filepath.Join(dir, "Sub", "New.cs")
+	oldPath := filepath.Join(dir, "Old.cs")
+
+	// New files are created, including the directory.
+	result, err := applyPatchFileDiff(diffs[0], newPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied}, result.HunkResults)
+	require.NoError(t, result.write())
+	content, err := os.ReadFile(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, "class New\n{\n}", string(content))
+
+	result, err = applyPatchFileDiff(diffs[0], newPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkAlreadyApplied}, result.HunkResults)
+
+	require.NoError(t, os.WriteFile(newPath, []byte("class Other {}\n"), 0644))
+	result, err = applyPatchFileDiff(diffs[0], newPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkFailed}, result.HunkResults)
+
+	// Deleted files are only deleted if they haven't changed.
+	require.NoError(t, os.WriteFile(oldPath, []byte("class Old\n{\n    int X;\n}\n"), 0644))
+	result, err = applyPatchFileDiff(diffs[1], oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkFailed}, result.HunkResults)
+
+	require.NoError(t, os.WriteFile(oldPath, []byte("class Old\n{\n}\n"), 0644))
+	result, err = applyPatchFileDiff(diffs[1], oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied}, result.HunkResults)
+	require.NoError(t, result.write())
+	assert.NoFileExists(t, oldPath)
+
+	result, err = applyPatchFileDiff(diffs[1], oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkAlreadyApplied}, result.HunkResults)
+}
+
+func TestApplyPatchFileDiffFinalNewline(t *testing.T) {
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "Foo.cs")
+	patch := generateUnifiedDiff("Foo.cs", []byte("a\nb\nc\n"), []byte("a\nb\nd"), false, false)
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+	assert.True(t, diffs[0].NoNewlineAtEnd)
+
+	require.NoError(t, os.WriteFile(targetPath, []byte("a\nb\nc\n"), 0644))
+	result, err := applyPatchFileDiff(diffs[0], targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nd", *result.Content)
+}
+
+func TestApplyPatchFileDiffOffset(t *testing.T) {
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "Foo.cs")
+	original := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\no\np\nq\nr\ns\nt\n"
+	patch := generateUnifiedDiff("Foo.cs", []byte(original), []byte(strings.NewReplacer("c\n", "C\n", "q\n", "Q\n").Replace(original)), false, false)
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+	require.Len(t, diffs[0].Hunks, 2)
+
+	// The first hunk is found after the added lines, and the second hunk is expected at the same
+	// offset, even though its original lines are also found elsewhere.
+	require.NoError(t, os.WriteFile(targetPath, []byte("x\ny\nz\n"+original+"n\no\np\nq\nr\ns\nt\n"), 0644))
+	result, err := applyPatchFileDiff(diffs[0], targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied, patchHunkApplied}, result.HunkResults)
+	assert.Equal(t, "x\ny\nz\na\nb\nC\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\no\np\nQ\nr\ns\nt\nn\no\np\nq\nr\ns\nt\n", *result.Content)
+}
+
+func TestApplyPatchFileDiffKeepsWhitespace(t *testing.T) {
+	dir := t.TempDir()
+	targetPath := filepath.Join(dir, "Foo.cs")
+	patch := generateUnifiedDiff("Foo.cs", []byte("class A\n{\n    int x;\n}\n"), []byte("class A\n{\n    int y;\n}\n"), false, false)
+	diffs, err := parsePatchFile(patch)
+	require.NoError(t, err)
+
+	// The unchanged lines keep the whitespace of the file, the added lines use the patch's.
+	require.NoError(t, os.WriteFile(targetPath, []byte("class A \n  {\n\tint x;\n  }\n"), 0644))
+	result, err := applyPatchFileDiff(diffs[0], targetPath)
+	require.NoError(t, err)
+	assert.Equal(t, []patchHunkResult{patchHunkApplied}, result.HunkResults)
+	assert.Equal(t, "class A \n  {\n    int y;\n  }\n", *result.Content)
+}
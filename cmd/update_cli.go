@@ -5,6 +5,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/pathutil"
 	"github.com/metaplay/cli/internal/version"
@@ -17,7 +19,15 @@ import (
 // around by fetching a release themselves.
 const manualDownloadSuggestion = "Check your network connection, or download a release manually from https://github.com/metaplay/cli/releases"
 
+// Update channels of the CLI, see README.md.
+const (
+	cliChannelStable     = "stable"
+	cliChannelPrerelease = "prerelease"
+)
+
+// Update the Metaplay CLI binary to the latest version of the release channel.
 type updateCliOpts struct {
+	flagChannel    string
 	flagPrerelease bool
 }
 
@@ -25,24 +35,70 @@ func init() {
 	o := updateCliOpts{}
 
 	var cmd = &cobra.Command{
-		Use:   "cli",
+		Use:   "cli [flags]",
 		Short: "Update the Metaplay CLI to the latest version",
 		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Update the Metaplay CLI binary to the latest version of the release channel:
+			- 'stable': the official releases (eg, '1.2.3').
+			- 'prerelease': the development builds (eg, '1.2.4-dev.1'), which are not intended
+			  for general use.
+
+			By default, the channel of the running CLI is used. Switching from a prerelease build to
+			the stable channel installs the latest stable release, even if it is older.
+
+			The release archive for the current platform is downloaded from GitHub and verified
+			against the SHA-256 checksums published with the release before the running executable
+			is replaced. The executable is replaced atomically, so a failed update leaves the
+			current version in place.
+
+			Related commands:
+			- 'metaplay version' to show the current version.
+		`),
+		Example: renderExample(`
+			# Update to the latest version on the current channel.
+			metaplay update cli
+
+			# Switch to the prerelease channel.
+			metaplay update cli --channel=prerelease
+
+			# Switch back to the latest stable release.
+			metaplay update cli --channel=stable
+		`),
 	}
 
-	cmd.Flags().BoolVar(&o.flagPrerelease, "prerelease", false, "Update to the latest prerelease version")
+	flags := cmd.Flags()
+	flags.StringVar(&o.flagChannel, "channel", "", "Release channel to update from: 'stable' or 'prerelease' (default to the channel of the running CLI)")
+	flags.BoolVar(&o.flagPrerelease, "prerelease", false, "Update to the latest prerelease version")
+	_ = flags.MarkDeprecated("prerelease", "use --channel=prerelease instead")
 
 	updateCmd.AddCommand(cmd)
 }
 
 func (o *updateCliOpts) Prepare(cmd *cobra.Command, args []string) error {
+	switch o.flagChannel {
+	case "":
+		// Default to the channel of the running CLI.
+		o.flagChannel = cliChannelStable
+		if o.flagPrerelease || version.IsPrerelease() || version.IsDevBuild() {
+			o.flagChannel = cliChannelPrerelease
+		}
+	case cliChannelStable, cliChannelPrerelease:
+		if o.flagPrerelease {
+			return clierrors.NewUsageError("--prerelease cannot be used with --channel").
+				WithSuggestion("Use --channel=prerelease instead")
+		}
+	default:
+		return clierrors.NewUsageErrorf("Invalid --channel %q", o.flagChannel).
+			WithSuggestion("Use 'stable' or 'prerelease'")
+	}
 	return nil
 }
 
 func (o *updateCliOpts) Run(cmd *cobra.Command) error {
 	ctx := cmd.Context()
 
-	prerelease := o.flagPrerelease || version.IsPrerelease() || version.IsDevBuild()
+	prerelease := o.flagChannel == cliChannelPrerelease
 	if prerelease {
 		log.Info().Msgf("Checking for the latest Metaplay CLI prerelease version...")
 	} else {
@@ -56,12 +112,13 @@ func (o *updateCliOpts) Run(cmd *cobra.Command) error {
 			WithSuggestion(manualDownloadSuggestion)
 	}
 
-	// A local "dev" build has no parseable version, so IsNewer can't compare it; always
-	// proceed in that case so `update cli` can move a locally built binary onto a release.
-	if !version.IsDevBuild() && !version.IsNewer(latest, version.AppVersion) {
+	if !shouldUpdateCli(latest, prerelease) {
 		log.Info().Msgf("Already on the latest Metaplay CLI version (%s)", version.AppVersion)
 		return nil
 	}
+	if !prerelease && version.IsPrerelease() && !version.IsNewer(latest, version.AppVersion) {
+		log.Info().Msgf("Switching from prerelease %s to the older stable version", styles.RenderTechnical(version.AppVersion))
+	}
 
 	log.Info().Msgf("Downloading Metaplay CLI version %s...", styles.RenderTechnical(latest))
 
@@ -79,7 +136,22 @@ func (o *updateCliOpts) Run(cmd *cobra.Command) error {
 	}
 
 	log.Info().Msg("")
-	log.Info().Msgf(styles.RenderSuccess("✅ Successfully updated to version %s!"), latest)
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Successfully updated to version %s!", latest)))
 
 	return nil
 }
+
+// shouldUpdateCli reports whether the running CLI should be replaced with the latest version of
+// the channel. A local "dev" build has no parseable version, so IsNewer can't compare it; always
+// proceed in that case so `update cli` can move a locally built binary onto a release. When
+// switching a prerelease build to the stable channel, the latest stable version is installed
+// even if it is older than the running prerelease.
+func shouldUpdateCli(latest string, prerelease bool) bool {
+	if version.IsDevBuild() {
+		return true
+	}
+	if !prerelease && version.IsPrerelease() {
+		return latest != version.AppVersion
+	}
+	return version.IsNewer(latest, version.AppVersion)
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/internal/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldUpdateCli(t *testing.T) {
+	originalVersion := version.AppVersion
+	t.Cleanup(func() { version.AppVersion = originalVersion })
+
+	testCases := []struct {
+		current    string
+		latest     string
+		prerelease bool
+		want       bool
+	}{
+		// Local dev builds are always updated.
+		{"dev", "1.2.3", false, true},
+		// Stable channel only updates to newer versions.
+		{"1.2.3", "1.2.4", false, true},
+		{"1.2.3", "1.2.3", false, false},
+		// Prerelease channel.
+		{"1.2.4-dev.1", "1.2.4-dev.2", true, true},
+		{"1.2.4-dev.2", "1.2.4-dev.2", true, false},
+		{"1.2.3", "1.2.4-dev.1", true, true},
+		// Switching a prerelease build to the stable channel allows downgrading.
+		{"1.2.4-dev.1", "1.2.3", false, true},
+		{"1.2.3-dev.1", "1.2.3", false, true},
+	}
+
+	for _, tc := range testCases {
+		version.AppVersion = tc.current
+		assert.Equal(t, tc.want, shouldUpdateCli(tc.latest, tc.prerelease), "current=%s latest=%s prerelease=%v", tc.current, tc.latest, tc.prerelease)
+	}
+}
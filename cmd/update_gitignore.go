@@ -0,0 +1,169 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Name of the managed blocks in the project's .gitignore and .gitattributes.
+const gitManagedBlockName = "Metaplay CLI managed block"
+
+// Files generated by the CLI that should not be committed, relative to the project root.
+var gitManagedIgnorePatterns = []string{
+	"# Maintained by 'metaplay update gitignore', changes inside this block are overwritten.",
+	"/" + metaproj.LocalEnvFilePath,     // Local environment variables, may contain secrets
+	"/" + serveAPIDiscoveryFilePath,     // Address and token of 'metaplay serve-api'
+	"/metaplay-sdk-modifications.patch", // SDK modifications saved by 'metaplay update sdk'
+	"/metaplay-project.yaml.bak",        // Backup from 'metaplay update project-config'
+	"*.rej",                             // Rejected hunks from applying the SDK modifications patch
+	"*.orig",                            // Backups from applying the SDK modifications patch
+	"/integration-test-output/",         // Output of 'metaplay test integration'
+	"/diagnostics-*.tar.gz",             // Bundles from 'metaplay debug collect-diagnostics'
+	"/profile-*.nettrace",               // CPU profiles from 'metaplay debug collect-cpu-profile'
+	"/profile-*.speedscope.json",        // CPU profiles in Speedscope format
+	"/profile-*.chromium.json",          // CPU profiles in Chromium format
+	"/dump-*.gcdump",                    // Heap dumps from 'metaplay debug collect-heap-dump'
+	"/core_*",                           // Full memory dumps from 'metaplay debug collect-heap-dump'
+}
+
+// Binary artifacts that git should not diff or merge as text, relative to the project root.
+var gitManagedAttributes = []string{
+	"# Maintained by 'metaplay update gitignore', changes inside this block are overwritten.",
+	"*.mpa binary",      // Game config and localization archives
+	"*.nettrace binary", // CPU profiles
+	"*.gcdump binary",   // Heap dumps
+}
+
+// Update the CLI-managed blocks in the project's .gitignore and .gitattributes.
+type updateGitignoreOpts struct {
+	UsePositionalArgs
+
+	flagAutoConfirm bool
+	flagPlanJSON    bool
+	flagPlanOnly    bool
+}
+
+func init() {
+	o := updateGitignoreOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "gitignore [flags]",
+		Short: "Update the CLI-managed blocks in the project's .gitignore and .gitattributes",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Update the blocks of the project's .gitignore and .gitattributes files that the
+			Metaplay CLI manages, so that the files the CLI generates don't get committed by
+			accident.
+
+			The .gitignore block covers the files generated by the CLI commands, eg, the local
+			environment file (.metaplay/env.local.yaml), the SDK modifications patch of
+			'metaplay update sdk', the output of 'metaplay test integration', and the diagnostics
+			bundles, CPU profiles, and heap dumps of the 'metaplay debug' commands. The
+			.gitattributes block marks the large binary artifacts, like the game config
+			archives, as binary.
+
+			The blocks are delimited by '# BEGIN Metaplay CLI managed block' and
+			'# END Metaplay CLI managed block' lines. The rest of the files are left intact, and
+			the files are created if they don't exist. 'metaplay init project' adds the blocks
+			as well.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay init project' to integrate the Metaplay SDK into a project.
+		`),
+		Example: renderExample(`
+			# Update the managed blocks in .gitignore and .gitattributes.
+			metaplay update gitignore
+
+			# Show the changes without writing them.
+			metaplay update gitignore --plan-only
+		`),
+	}
+	updateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Automatically confirm file writes")
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "dry-run", false, "Alias for --plan-only")
+}
+
+func (o *updateGitignoreOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *updateGitignoreOpts) Run(cmd *cobra.Command) error {
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+
+	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
+	if err := addGitManagedBlocksToPlan(plan, projectDir); err != nil {
+		return err
+	}
+	if err := plan.Scan(); err != nil {
+		return err
+	}
+
+	// With --plan-json or --plan-only, show the plan and stop.
+	if o.flagPlanJSON || o.flagPlanOnly {
+		return showPlanOnly(plan, o.flagPlanJSON, false)
+	}
+
+	if plan.FilesToWrite() == 0 {
+		log.Info().Msg("")
+		log.Info().Msg("The managed blocks in .gitignore and .gitattributes are already up to date.")
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Update Git Files"))
+	log.Info().Msg("")
+	log.Info().Msg("Files to be modified:")
+	plan.Preview(false)
+
+	if err := plan.WaitForWritable(cmd.Context(), false); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	if !o.flagAutoConfirm {
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Aborted.")
+			return nil
+		}
+	}
+
+	if err := plan.Execute(); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderSuccess("✅ Updated the managed blocks in .gitignore and .gitattributes"))
+	return nil
+}
+
+// addGitManagedBlocksToPlan adds the CLI-managed blocks of .gitignore and .gitattributes in the
+// project directory to the plan.
+func addGitManagedBlocksToPlan(plan *filesetwriter.Plan, projectDir string) error {
+	if err := plan.AddManagedBlock(filepath.Join(projectDir, ".gitignore"), gitManagedBlockName, gitManagedIgnorePatterns, "update Metaplay CLI managed block"); err != nil {
+		return err
+	}
+	return plan.AddManagedBlock(filepath.Join(projectDir, ".gitattributes"), gitManagedBlockName, gitManagedAttributes, "update Metaplay CLI managed block")
+}
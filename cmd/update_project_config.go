@@ -0,0 +1,176 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/filesetwriter"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Suffix of the backup of metaplay-project.yaml written before migrating it.
+const projectConfigBackupSuffix = ".bak"
+
+// Upgrade the metaplay-project.yaml to the current layout.
+type updateProjectConfigOpts struct {
+	UsePositionalArgs
+
+	flagAutoConfirm bool
+	flagPlanJSON    bool
+	flagPlanOnly    bool
+}
+
+func init() {
+	o := updateProjectConfigOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "project-config [flags]",
+		Short: "Upgrade the metaplay-project.yaml written for an older layout",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Upgrade a metaplay-project.yaml written by older versions of the Metaplay CLI to the
+			current layout. The following migrations are applied, in order, when needed:
+			1. Rename the environments' 'botsValuesFile' to 'botclientValuesFile'.
+			2. Add the 'unityProjectDir', if it is missing and a Unity project is found in the
+			   project directory or its immediate subdirectories.
+
+			The changes are listed and the file changes previewed before writing. The original file
+			is saved as metaplay-project.yaml.bak. The comments and formatting of the unchanged parts
+			of the file are kept, and running the command again is a no-op. If the migrated file
+			still fails to load, the command fails, so that the remaining problems can be fixed
+			manually.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay update project-environments' to update the environments from the portal.
+			- 'metaplay update sdk' to update the Metaplay SDK in the project.
+		`),
+		Example: renderExample(`
+			# Upgrade metaplay-project.yaml to the current layout.
+			metaplay update project-config
+
+			# Show the changes without writing them.
+			metaplay update project-config --plan-only
+
+			# Upgrade without confirmation, eg, in CI.
+			metaplay update project-config --yes
+		`),
+	}
+	updateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagAutoConfirm, "yes", "y", false, "Automatically confirm file writes")
+	flags.BoolVar(&o.flagPlanJSON, "plan-json", false, "Output the planned file changes (with diffs) as JSON without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "plan-only", false, "Show the planned file changes without writing any files")
+	flags.BoolVar(&o.flagPlanOnly, "dry-run", false, "Alias for --plan-only")
+}
+
+func (o *updateProjectConfigOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *updateProjectConfigOpts) Run(cmd *cobra.Command) error {
+	// Only locate the project: loading it would fail for configs that need migrating.
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+
+	configFilePath := filepath.Join(projectDir, metaproj.ConfigFileName)
+	content, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to read %s", configFilePath)
+	}
+
+	migrated, results, err := metaproj.MigrateProjectConfig(projectDir, content)
+	if err != nil {
+		return clierrors.Wrapf(err, "Failed to migrate %s", configFilePath).
+			WithSuggestion("Fix the reported problem in the file and run the command again")
+	}
+
+	// Preview the migrated file and the backup of the original.
+	plan := filesetwriter.NewPlan(tui.IsInteractiveMode())
+	if len(results) > 0 {
+		plan.Add(configFilePath+projectConfigBackupSuffix, content, 0644)
+		plan.AddUpdate(configFilePath, migrated, 0644, "migrate to the current layout")
+	}
+	if err := plan.Scan(); err != nil {
+		return err
+	}
+
+	if o.flagPlanJSON {
+		return showPlanOnly(plan, true, false)
+	}
+
+	if len(results) == 0 {
+		log.Info().Msg("")
+		log.Info().Msgf("%s %s is already up to date", styles.RenderSuccess("✓"), styles.RenderTechnical(metaproj.ConfigFileName))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Update Project Config"))
+	log.Info().Msg("")
+	log.Info().Msg("Migrations to apply:")
+	for _, result := range results {
+		log.Info().Msgf("  %s %s", styles.RenderMuted(fmt.Sprintf("%d.", result.Version)), result.Description)
+		for _, change := range result.Changes {
+			log.Info().Msgf("     %s %s", styles.RenderWarning("~"), change)
+		}
+	}
+
+	if o.flagPlanOnly {
+		return showPlanOnly(plan, false, false)
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg("Files to be modified:")
+	plan.Preview(false)
+
+	if err := plan.WaitForWritable(cmd.Context(), false); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	if !o.flagAutoConfirm {
+		if !tui.IsInteractiveMode() {
+			return clierrors.Newf("Confirmation required to update %s", metaproj.ConfigFileName).
+				WithSuggestion("Use --yes to apply the changes in non-interactive mode")
+		}
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg("Aborted.")
+			return nil
+		}
+	}
+
+	if err := plan.Execute(); err != nil {
+		return err
+	}
+
+	// Check that the migrated config loads, so that remaining problems are reported right away.
+	log.Info().Msg("")
+	if _, err := metaproj.LoadProjectConfigFile(projectDir); err != nil {
+		return clierrors.Wrapf(err, "Migrated %s, but it is still invalid", metaproj.ConfigFileName).
+			WithDetails(fmt.Sprintf("The original file is saved as %s.", filepath.Join(projectDir, metaproj.ConfigFileName+projectConfigBackupSuffix))).
+			WithSuggestion(fmt.Sprintf("Fix the remaining problems in %s manually", metaproj.ConfigFileName))
+	}
+
+	log.Info().Msg(styles.RenderSuccess(fmt.Sprintf("✅ Migrated %s to the current layout", metaproj.ConfigFileName)))
+	log.Info().Msgf("The original file is saved as %s.", styles.RenderTechnical(metaproj.ConfigFileName+projectConfigBackupSuffix))
+	return nil
+}
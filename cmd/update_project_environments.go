@@ -8,11 +8,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
+	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/internal/tui"
 	"github.com/metaplay/cli/pkg/metaproj"
 	"github.com/metaplay/cli/pkg/portalapi"
@@ -24,6 +26,7 @@ import (
 // Command for updating the 'environments' section in the 'metaplay-project.yaml'. The environments
 // infos are fetched from the portal using the projectID (human ID) specified in the YAML file.
 type updateProjectEnvironmentsOpts struct {
+	flagYes bool
 }
 
 func init() {
@@ -37,16 +40,30 @@ func init() {
 		Long: renderLong(&o, `
 			Update the environments in the metaplay-project.yaml from the Metaplay Portal.
 
+			The changes are shown as a per-field diff of the added and changed environments, and
+			must be confirmed before metaplay-project.yaml is rewritten. Environments that no longer
+			exist in the portal (or that you don't have access to) are listed, but not removed. The
+			fields not managed by the portal, eg, 'serverValuesFile' and 'aliases', are kept, as
+			are the comments and formatting of the unchanged parts of the file.
+
+			In non-interactive mode, --yes is required to apply the changes.
+
 			Related commands:
 			- 'metaplay deploy server' ...
 		`),
 		Example: renderExample(`
 			# Update the project environments from the portal.
 			metaplay update project-environments
+
+			# Update the project environments without confirmation, eg, in CI.
+			metaplay update project-environments --yes
 		`),
 	}
 
 	updateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagYes, "yes", false, "Apply the changes without confirmation")
 }
 
 func (o *updateProjectEnvironmentsOpts) Prepare(cmd *cobra.Command, args []string) error {
@@ -94,8 +111,43 @@ func (o *updateProjectEnvironmentsOpts) Run(cmd *cobra.Command) error {
 	}
 	log.Debug().Msgf("Found following environments for project: %+v", projectEnvironments)
 
+	// Show the changes.
+	changes := diffProjectEnvironments(project.Config.Environments, projectEnvironments)
+	if len(projectEnvironments) == 0 {
+		log.Info().Msgf("%s No environments found for this project in the portal.", styles.RenderMuted("i"))
+	}
+	for _, change := range changes {
+		for _, line := range renderEnvironmentChange(change) {
+			log.Info().Msg(line)
+		}
+	}
+
+	// Nothing to write if only environments missing from the portal were found.
+	if !slices.ContainsFunc(changes, func(change environmentChange) bool { return change.Kind != environmentMissing }) {
+		log.Info().Msg("")
+		log.Info().Msgf("%s Environments in %s are already up to date", styles.RenderSuccess("✓"), styles.RenderTechnical("metaplay-project.yaml"))
+		return nil
+	}
+
+	// Ask for confirmation before modifying the file.
+	log.Info().Msg("")
+	if !o.flagYes {
+		if !tui.IsInteractiveMode() {
+			return clierrors.New("Confirmation required to update metaplay-project.yaml").
+				WithSuggestion("Use --yes to apply the changes in non-interactive mode")
+		}
+		confirmed, err := tui.DoConfirmQuestion(cmd.Context(), "Update metaplay-project.yaml?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			log.Info().Msg(styles.RenderMuted("Update canceled."))
+			return nil
+		}
+	}
+
 	// Update the environments in metaplay-project.yaml.
-	err = o.updateProjectConfigEnvironments(project, projectEnvironments)
+	err = o.updateProjectConfigEnvironments(project, projectEnvironments, changes)
 	if err != nil {
 		return err
 	}
@@ -105,10 +157,107 @@ func (o *updateProjectEnvironmentsOpts) Run(cmd *cobra.Command) error {
 	return nil
 }
 
+// Kind of change to an environment in metaplay-project.yaml.
+type environmentChangeKind int
+
+const (
+	environmentAdded   environmentChangeKind = iota // Environment exists only in the portal
+	environmentUpdated                              // Environment's portal-managed fields have changed
+	environmentMissing                              // Environment exists only in metaplay-project.yaml
+)
+
+// environmentFieldChange is a change to a single field of an environment.
+type environmentFieldChange struct {
+	Field    string // Name of the field in metaplay-project.yaml, eg, 'stackDomain'
+	OldValue string // Value in metaplay-project.yaml (empty for added environments)
+	NewValue string // Value in the portal
+}
+
+// environmentChange describes how an environment differs between metaplay-project.yaml and
+// the portal.
+type environmentChange struct {
+	HumanID string                   // Human ID of the environment
+	Kind    environmentChangeKind    // Kind of change
+	Fields  []environmentFieldChange // Changed fields (all the portal-managed fields for added environments)
+}
+
+// diffProjectEnvironments compares the environments in metaplay-project.yaml against the ones
+// in the portal. Only the fields managed by the portal are compared. Returns the added and
+// updated environments in the portal order, followed by the environments that are missing
+// from the portal. Unchanged environments are not included.
+func diffProjectEnvironments(existingEnvs []metaproj.ProjectEnvironmentConfig, portalEnvs []portalapi.EnvironmentInfo) []environmentChange {
+	changes := []environmentChange{}
+	for _, portalEnv := range portalEnvs {
+		newFields := [][2]string{
+			{"name", portalEnv.Name},
+			{"hostingType", string(portalEnv.HostingType)},
+			{"type", string(portalEnv.Type)},
+			{"stackDomain", portalEnv.StackDomain},
+		}
+
+		ndx := slices.IndexFunc(existingEnvs, func(env metaproj.ProjectEnvironmentConfig) bool { return env.HumanID == portalEnv.HumanID })
+		if ndx == -1 {
+			change := environmentChange{HumanID: portalEnv.HumanID, Kind: environmentAdded}
+			for _, field := range newFields {
+				change.Fields = append(change.Fields, environmentFieldChange{Field: field[0], NewValue: field[1]})
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		existingEnv := existingEnvs[ndx]
+		oldValues := map[string]string{
+			"name":        existingEnv.Name,
+			"hostingType": string(existingEnv.HostingType),
+			"type":        string(existingEnv.Type),
+			"stackDomain": existingEnv.StackDomain,
+		}
+		change := environmentChange{HumanID: portalEnv.HumanID, Kind: environmentUpdated}
+		for _, field := range newFields {
+			if oldValues[field[0]] != field[1] {
+				change.Fields = append(change.Fields, environmentFieldChange{Field: field[0], OldValue: oldValues[field[0]], NewValue: field[1]})
+			}
+		}
+		if len(change.Fields) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, existingEnv := range existingEnvs {
+		if !slices.ContainsFunc(portalEnvs, func(env portalapi.EnvironmentInfo) bool { return env.HumanID == existingEnv.HumanID }) {
+			changes = append(changes, environmentChange{HumanID: existingEnv.HumanID, Kind: environmentMissing})
+		}
+	}
+
+	return changes
+}
+
+// renderEnvironmentChange renders the change as colored diff lines.
+func renderEnvironmentChange(change environmentChange) []string {
+	switch change.Kind {
+	case environmentAdded:
+		lines := []string{fmt.Sprintf("%s Add environment %s", styles.RenderSuccess("+"), styles.RenderTechnical(change.HumanID))}
+		for _, field := range change.Fields {
+			lines = append(lines, fmt.Sprintf("    %s %-12s %s", styles.RenderSuccess("+"), field.Field+":", styles.RenderSuccess(field.NewValue)))
+		}
+		return lines
+	case environmentUpdated:
+		lines := []string{fmt.Sprintf("%s Update environment %s", styles.RenderWarning("~"), styles.RenderTechnical(change.HumanID))}
+		for _, field := range change.Fields {
+			lines = append(lines, fmt.Sprintf("    %s %-12s %s %s %s", styles.RenderWarning("~"), field.Field+":", styles.RenderError(field.OldValue), styles.RenderMuted("→"), styles.RenderSuccess(field.NewValue)))
+		}
+		return lines
+	default:
+		return []string{fmt.Sprintf("%s Environment %s does not exist in portal; remove manually from metaplay-project.yaml if not needed", styles.RenderError("-"), styles.RenderTechnical(change.HumanID))}
+	}
+}
+
 // Update the metaplay-project.yaml to be up-to-date with newEnvironments.
 // Use goccy/go-yaml for minimally editing the file, i.e., to retain ordering, comments,
 // and whitespace in the untouched parts of the file.
-func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project *metaproj.MetaplayProject, newPortalEnvironments []portalapi.EnvironmentInfo) error {
+// Only the added and updated environments in changes are written, so the unchanged entries
+// keep their comments and formatting.
+func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project *metaproj.MetaplayProject, newPortalEnvironments []portalapi.EnvironmentInfo, changes []environmentChange) error {
 	// Load the existing YAML file
 	projectConfigFilePath := filepath.Join(project.RelativeDir, metaproj.ConfigFileName)
 	configFileBytes, err := os.ReadFile(projectConfigFilePath)
@@ -139,9 +288,6 @@ func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project
 	if _, isNull := envsNode.(*ast.NullNode); isNull {
 		if len(newPortalEnvironments) == 0 {
 			// No environments to add, keep the null node as-is to avoid outputting "environments: []"
-			log.Info().Msgf("%s No environments found for this project in the portal.", styles.RenderMuted("i"))
-			log.Info().Msg("")
-			log.Info().Msgf("%s Updated environments in %s", styles.RenderSuccess("✓"), styles.RenderTechnical("metaplay-project.yaml"))
 			return nil
 		}
 
@@ -183,13 +329,12 @@ func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project
 		}
 	}
 
-	// Print a note if no environments exist in the portal.
-	if len(newPortalEnvironments) == 0 {
-		log.Info().Msgf("%s No environments found for this project in the portal.", styles.RenderMuted("i"))
-	}
-
-	// Handle all environments from the portal.
+	// Handle the added and updated environments from the portal.
 	for _, portalEnv := range newPortalEnvironments {
+		if !slices.ContainsFunc(changes, func(change environmentChange) bool { return change.HumanID == portalEnv.HumanID }) {
+			continue
+		}
+
 		// Find the index of the environment with matching humanId
 		foundIndex := -1
 		for i, envNode := range envsSeqNode.Values {
@@ -208,26 +353,24 @@ func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project
 			}
 		}
 
-		// Initialize new project environment config (with fresh information from portal).
-		newEnvConfig := metaproj.ProjectEnvironmentConfig{
-			Name:        portalEnv.Name,
-			HostingType: portalEnv.HostingType,
-			HumanID:     portalEnv.HumanID,
-			StackDomain: portalEnv.StackDomain,
-			Type:        portalEnv.Type,
-		}
-
-		// If updating an existing environment, copy the fields from the original entry
-		// that are not owned/known by the portal.
+		// If updating an existing environment, start from the original entry to keep the
+		// fields that are not owned/known by the portal.
+		newEnvConfig := metaproj.ProjectEnvironmentConfig{}
 		if foundIndex != -1 {
 			oldConfig, err := project.Config.GetEnvironmentByHumanID(portalEnv.HumanID)
 			if err != nil {
 				return err
 			}
-			newEnvConfig.ServerValuesFile = oldConfig.ServerValuesFile
-			newEnvConfig.BotClientValuesFile = oldConfig.BotClientValuesFile
+			newEnvConfig = *oldConfig
 		}
 
+		// Update the fields owned by the portal.
+		newEnvConfig.Name = portalEnv.Name
+		newEnvConfig.HostingType = portalEnv.HostingType
+		newEnvConfig.HumanID = portalEnv.HumanID
+		newEnvConfig.StackDomain = portalEnv.StackDomain
+		newEnvConfig.Type = portalEnv.Type
+
 		// Convert environment info to YAML.
 		envYAML, err := yaml.Marshal(newEnvConfig)
 		if err != nil {
@@ -242,28 +385,12 @@ func (o *updateProjectEnvironmentsOpts) updateProjectConfigEnvironments(project
 
 		// Update an existing node or append a new node to the end.
 		if foundIndex == -1 {
-			log.Info().Msgf("%s Add new environment %s", styles.RenderSuccess("+"), styles.RenderTechnical(portalEnv.HumanID))
 			envsSeqNode.Values = append(envsSeqNode.Values, envAST.Docs[0].Body)
 		} else {
-			log.Info().Msgf("%s Update existing environment %s", styles.RenderSuccess("*"), styles.RenderTechnical(portalEnv.HumanID))
 			envsSeqNode.Values[foundIndex] = envAST.Docs[0].Body
 		}
 	}
 
-	// Find any deleted environments. Only show a message if there are any.
-	for _, envConfig := range project.Config.Environments {
-		found := false
-		for _, newEnv := range newPortalEnvironments {
-			if newEnv.HumanID == envConfig.HumanID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			log.Info().Msgf("%s Environment %s does not exist in portal; remove manually from metaplay-project.yaml if not needed", styles.RenderError("-"), styles.RenderTechnical(envConfig.HumanID))
-		}
-	}
-
 	// Write the updated YAML back to the file
 	if err := os.WriteFile(projectConfigFilePath, []byte(root.String()), 0644); err != nil {
 		return fmt.Errorf("failed to write updated config: %v", err)
@@ -5,6 +5,9 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -301,3 +304,98 @@ environments:
 		})
 	}
 }
+
+func TestDiffProjectEnvironments(t *testing.T) {
+	existing := []metaproj.ProjectEnvironmentConfig{
+		{Name: "Development", HumanID: "tough-falcons", Type: portalapi.EnvironmentTypeDevelopment, StackDomain: "dev.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Staging", HumanID: "calm-otters", Type: portalapi.EnvironmentTypeStaging, StackDomain: "old.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Removed", HumanID: "gone-geckos", Type: portalapi.EnvironmentTypeDevelopment, StackDomain: "dev.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+	}
+	portalEnvs := []portalapi.EnvironmentInfo{
+		{Name: "Development", HumanID: "tough-falcons", Type: portalapi.EnvironmentTypeDevelopment, StackDomain: "dev.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Staging", HumanID: "calm-otters", Type: portalapi.EnvironmentTypeStaging, StackDomain: "new.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Production", HumanID: "happy-pandas", Type: portalapi.EnvironmentTypeProduction, StackDomain: "prod.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+	}
+
+	expected := []environmentChange{
+		{
+			HumanID: "calm-otters",
+			Kind:    environmentUpdated,
+			Fields:  []environmentFieldChange{{Field: "stackDomain", OldValue: "old.metaplay.io", NewValue: "new.metaplay.io"}},
+		},
+		{
+			HumanID: "happy-pandas",
+			Kind:    environmentAdded,
+			Fields: []environmentFieldChange{
+				{Field: "name", NewValue: "Production"},
+				{Field: "hostingType", NewValue: "metaplay-hosted"},
+				{Field: "type", NewValue: "production"},
+				{Field: "stackDomain", NewValue: "prod.metaplay.io"},
+			},
+		},
+		{HumanID: "gone-geckos", Kind: environmentMissing},
+	}
+
+	changes := diffProjectEnvironments(existing, portalEnvs)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Changes mismatch.\nExpected: %+v\nGot:      %+v", expected, changes)
+	}
+
+	// No changes when the environments are identical.
+	if changes := diffProjectEnvironments(existing[:1], portalEnvs[:1]); len(changes) != 0 {
+		t.Errorf("Expected no changes, got: %+v", changes)
+	}
+}
+
+func TestUpdateProjectConfigEnvironmentsOnlyChanged(t *testing.T) {
+	input := `projectID: test-project
+environments:
+  # Keep this comment.
+  - name: Development
+    hostingType: metaplay-hosted
+    humanId: tough-falcons
+    type: development
+    stackDomain: dev.metaplay.io # Inline comment.
+  - name: Staging
+    hostingType: metaplay-hosted
+    humanId: calm-otters
+    type: staging
+    stackDomain: old.metaplay.io
+    aliases: [stage]
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, metaproj.ConfigFileName), []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := []metaproj.ProjectEnvironmentConfig{
+		{Name: "Development", HumanID: "tough-falcons", Type: portalapi.EnvironmentTypeDevelopment, StackDomain: "dev.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Staging", HumanID: "calm-otters", Type: portalapi.EnvironmentTypeStaging, StackDomain: "old.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted, Aliases: []string{"stage"}},
+	}
+	portalEnvs := []portalapi.EnvironmentInfo{
+		{Name: "Development", HumanID: "tough-falcons", Type: portalapi.EnvironmentTypeDevelopment, StackDomain: "dev.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+		{Name: "Staging", HumanID: "calm-otters", Type: portalapi.EnvironmentTypeStaging, StackDomain: "new.metaplay.io", HostingType: portalapi.HostingTypeMetaplayHosted},
+	}
+	project := &metaproj.MetaplayProject{RelativeDir: dir, Config: metaproj.ProjectConfig{Environments: existing}}
+
+	o := updateProjectEnvironmentsOpts{}
+	if err := o.updateProjectConfigEnvironments(project, portalEnvs, diffProjectEnvironments(existing, portalEnvs)); err != nil {
+		t.Fatalf("updateProjectConfigEnvironments failed: %v", err)
+	}
+
+	result, err := os.ReadFile(filepath.Join(dir, metaproj.ConfigFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(result)
+
+	// The unchanged environment keeps its comments.
+	for _, want := range []string{"# Keep this comment.", "# Inline comment.", "stackDomain: new.metaplay.io", "- stage"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "old.metaplay.io") {
+		t.Errorf("Expected stackDomain to be updated, got:\n%s", output)
+	}
+}
@@ -0,0 +1,499 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Resolve the rejected hunks (.rej files) left by re-applying the SDK modifications patch.
+type updateResolveRejectsOpts struct {
+	UsePositionalArgs
+
+	flagYes    bool
+	flagDryRun bool
+}
+
+// rejectHunkStatus is the result of matching a rejected hunk against the current file.
+type rejectHunkStatus int
+
+const (
+	rejectHunkConflict       rejectHunkStatus = iota // Needs manual resolution
+	rejectHunkApplicable                             // The original lines were found, the hunk can be applied
+	rejectHunkAlreadyApplied                         // The file already contains the changed lines
+)
+
+// rejectHunk is a single hunk of a .rej file.
+type rejectHunk struct {
+	OldStart int      // Line number of the hunk in the old SDK file (1-based)
+	Header   string   // The '@@ -a,b +c,d @@' line
+	Lines    []string // Hunk lines with their ' ', '-', or '+' prefix
+}
+
+// rejectFile is a parsed .rej file.
+type rejectFile struct {
+	Path       string       // Path to the .rej file
+	TargetPath string       // Path to the file that the hunks were rejected from
+	Preamble   []string     // Lines before the first hunk, eg, the 'diff' or '---'/'+++' headers
+	Hunks      []rejectHunk // Rejected hunks
+}
+
+var rejectHunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+func init() {
+	o := updateResolveRejectsOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "resolve-rejects [flags]",
+		Short: "Resolve the rejected hunks left by re-applying SDK modifications",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Guide through resolving the rejected hunks (.rej files) left in the SDK directory by
+			re-applying the SDK modifications patch with 'metaplay update apply-patch', 'git apply
+			--reject', or 'patch -p1' after 'metaplay update sdk'.
+
+			Each rejected hunk is matched against the file in the new SDK:
+			- Hunks whose changes are already in the file are resolved as is.
+			- Hunks whose original lines are found exactly once in the file, eg, because the code
+			  moved or only the whitespace differs, can be applied automatically.
+			- The rest need to be resolved manually. The hunk, showing your change to the old
+			  SDK, is shown along with the surrounding lines of the new SDK file.
+
+			The obvious cases are applied after confirmation, or without it with --yes. Resolved
+			hunks are removed from the .rej files, and .rej files without remaining hunks are
+			deleted. Resolve the remaining hunks by editing the files and delete the .rej files
+			when done.
+
+			With --dry-run, only the rejected hunks and how they would be resolved are shown.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay update sdk' to update the SDK and extract your SDK modifications as a patch.
+			- 'metaplay update apply-patch' to re-apply the SDK modifications patch.
+		`),
+		Example: renderExample(`
+			# Go through the rejected hunks in the SDK directory.
+			metaplay update resolve-rejects
+
+			# Apply all the obvious cases without asking.
+			metaplay update resolve-rejects --yes
+
+			# Only show the rejected hunks.
+			metaplay update resolve-rejects --dry-run
+		`),
+	}
+	updateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.flagYes, "yes", "y", false, "Apply the obvious cases without asking")
+	addDryRunFlag(flags, &o.flagDryRun)
+}
+
+func (o *updateResolveRejectsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *updateResolveRejectsOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+	projectConfig, err := metaproj.LoadProjectConfigFile(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+	sdkRootDir := filepath.Join(projectDir, projectConfig.SdkRootDir)
+
+	rejectPaths, err := findRejectFiles(sdkRootDir)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Resolve Rejected SDK Patch Hunks"))
+	log.Info().Msg("")
+	if len(rejectPaths) == 0 {
+		log.Info().Msgf("No .rej files found in %s", styles.RenderTechnical(sdkRootDir))
+		return nil
+	}
+	log.Info().Msgf("Found %s in %s", styles.RenderTechnical(fmt.Sprintf("%d .rej file(s)", len(rejectPaths))), styles.RenderTechnical(sdkRootDir))
+
+	numApplied := 0
+	numAlreadyApplied := 0
+	numRemaining := 0
+	for _, rejectPath := range rejectPaths {
+		rej, err := loadRejectFile(rejectPath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(projectDir, rej.TargetPath)
+		if err != nil {
+			relPath = rej.TargetPath
+		}
+
+		log.Info().Msg("")
+		log.Info().Msgf("%s %s", styles.RenderAttention("●"), styles.RenderTechnical(filepath.ToSlash(relPath)))
+
+		content, err := os.ReadFile(rej.TargetPath)
+		if err != nil {
+			log.Info().Msgf("  %s", styles.RenderWarning(fmt.Sprintf("Cannot read the file: %v", err)))
+			numRemaining += len(rej.Hunks)
+			continue
+		}
+		lines, lineEnding, hasFinalNewline := splitFileLines(string(content))
+
+		// Classify the hunks and show the ones that need manual resolution.
+		var applicable []rejectHunk
+		var remaining []rejectHunk
+		for _, hunk := range rej.Hunks {
+			status, _ := classifyRejectHunk(lines, hunk)
+			switch status {
+			case rejectHunkAlreadyApplied:
+				log.Info().Msgf("  %s %s", styles.RenderSuccess("✓"), styles.RenderMuted(hunk.Header+" already applied"))
+				numAlreadyApplied++
+			case rejectHunkApplicable:
+				log.Info().Msgf("  %s %s", styles.RenderAttention("+"), hunk.Header+" can be applied automatically")
+				applicable = append(applicable, hunk)
+			default:
+				log.Info().Msgf("  %s %s", styles.RenderError("✗"), hunk.Header+" needs manual resolution")
+				printRejectHunkContext(lines, hunk)
+				remaining = append(remaining, hunk)
+			}
+		}
+
+		// Apply the obvious cases, if confirmed.
+		apply := len(applicable) > 0 && !o.flagDryRun
+		if apply && !o.flagYes {
+			if !tui.CanAskQuestions() {
+				return clierrors.NewUsageError("Confirmation required to apply the rejected hunks").
+					WithSuggestion("Use --yes to apply the obvious cases, or --dry-run to only show them")
+			}
+			apply, err = tui.DoConfirmQuestion(ctx, fmt.Sprintf("Apply %d hunk(s) to %s?", len(applicable), filepath.Base(rej.TargetPath)))
+			if err != nil {
+				return err
+			}
+		}
+		if apply {
+			for _, hunk := range applicable {
+				var ok bool
+				lines, ok = applyRejectHunk(lines, hunk)
+				if ok {
+					numApplied++
+				} else {
+					remaining = append(remaining, hunk)
+				}
+			}
+			newContent := strings.Join(lines, lineEnding)
+			if hasFinalNewline {
+				newContent += lineEnding
+			}
+			if err := os.WriteFile(rej.TargetPath, []byte(newContent), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", rej.TargetPath, err)
+			}
+		} else {
+			remaining = append(remaining, applicable...)
+		}
+		numRemaining += len(remaining)
+
+		// Update or remove the .rej file.
+		if o.flagDryRun || len(remaining) == len(rej.Hunks) {
+			continue
+		}
+		if len(remaining) == 0 {
+			if err := os.Remove(rej.Path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", rej.Path, err)
+			}
+		} else {
+			rej.Hunks = remaining
+			if err := os.WriteFile(rej.Path, []byte(rej.Render()), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", rej.Path, err)
+			}
+		}
+	}
+
+	log.Info().Msg("")
+	if o.flagDryRun {
+		log.Info().Msg(styles.RenderMuted("Dry-run mode: no files were modified"))
+		return nil
+	}
+	log.Info().Msgf("Applied %d hunk(s), %d were already applied, %d remaining", numApplied, numAlreadyApplied, numRemaining)
+	if numRemaining > 0 {
+		log.Info().Msg("")
+		log.Info().Msg("Resolve the remaining hunks by editing the files, and delete the .rej files when done.")
+	} else {
+		log.Info().Msg(styles.RenderSuccess("✅ All rejected hunks resolved"))
+	}
+	return nil
+}
+
+// findRejectFiles returns the .rej files in the directory tree.
+func findRejectFiles(rootDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" || entry.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(entry.Name(), ".rej") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s for .rej files: %w", rootDir, err)
+	}
+	return paths, nil
+}
+
+// loadRejectFile reads and parses a .rej file.
+func loadRejectFile(path string) (*rejectFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	rej, err := parseRejectFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	rej.Path = path
+	rej.TargetPath = strings.TrimSuffix(path, ".rej")
+	return rej, nil
+}
+
+// parseRejectFile parses the hunks of a .rej file, as written by 'git apply --reject' or 'patch'.
+func parseRejectFile(content string) (*rejectFile, error) {
+	rej := &rejectFile{}
+	var hunk *rejectHunk
+	for _, line := range strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n") {
+		if match := rejectHunkHeaderRegex.FindStringSubmatch(line); match != nil {
+			oldStart, _ := strconv.Atoi(match[1])
+			rej.Hunks = append(rej.Hunks, rejectHunk{OldStart: oldStart, Header: line})
+			hunk = &rej.Hunks[len(rej.Hunks)-1]
+			continue
+		}
+		if hunk == nil {
+			rej.Preamble = append(rej.Preamble, line)
+			continue
+		}
+		if line == "" {
+			// Some tools strip the trailing space of empty context lines.
+			hunk.Lines = append(hunk.Lines, " ")
+		} else if strings.HasPrefix(line, "\\") {
+			// '\ No newline at end of file' markers don't matter when matching lines.
+			continue
+		} else if strings.ContainsAny(line[:1], " -+") {
+			hunk.Lines = append(hunk.Lines, line)
+		} else {
+			return nil, fmt.Errorf("unexpected line in hunk %s: %q", hunk.Header, line)
+		}
+	}
+	if len(rej.Hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found")
+	}
+	return rej, nil
+}
+
+// Render returns the .rej file content with the remaining hunks.
+func (rej *rejectFile) Render() string {
+	var sb strings.Builder
+	for _, line := range rej.Preamble {
+		sb.WriteString(line + "\n")
+	}
+	for _, hunk := range rej.Hunks {
+		sb.WriteString(hunk.Header + "\n")
+		for _, line := range hunk.Lines {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// oldLines returns the lines of the hunk before the change, ie, the context and removed lines.
+func (hunk *rejectHunk) oldLines() []string {
+	return hunk.sideLines('-')
+}
+
+// newLines returns the lines of the hunk after the change, ie, the context and added lines.
+func (hunk *rejectHunk) newLines() []string {
+	return hunk.sideLines('+')
+}
+
+func (hunk *rejectHunk) sideLines(changePrefix byte) []string {
+	lines := []string{}
+	for _, line := range hunk.Lines {
+		if line[0] == ' ' || line[0] == changePrefix {
+			lines = append(lines, line[1:])
+		}
+	}
+	return lines
+}
+
+// splitFileLines splits the file content into lines, returning the line ending used and whether
+// the content ends in a newline, so that the content can be joined back as it was.
+func splitFileLines(content string) (lines []string, lineEnding string, hasFinalNewline bool) {
+	lineEnding = "\n"
+	if strings.Contains(content, "\r\n") {
+		lineEnding = "\r\n"
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	hasFinalNewline = strings.HasSuffix(content, "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return []string{}, lineEnding, hasFinalNewline
+	}
+	return strings.Split(content, "\n"), lineEnding, hasFinalNewline
+}
+
+// findLineSequence returns the indexes where the sequence of lines occurs in the file lines.
+// With ignoreWhitespace, trailing whitespace and indentation differences are ignored.
+func findLineSequence(lines []string, sequence []string, ignoreWhitespace bool) []int {
+	if len(sequence) == 0 || len(sequence) > len(lines) {
+		return nil
+	}
+	normalize := func(line string) string {
+		if ignoreWhitespace {
+			return strings.TrimSpace(line)
+		}
+		return line
+	}
+	var matches []int
+	for start := 0; start+len(sequence) <= len(lines); start++ {
+		found := true
+		for ndx, line := range sequence {
+			if normalize(lines[start+ndx]) != normalize(line) {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, start)
+		}
+	}
+	return matches
+}
+
+// classifyRejectHunk matches the hunk against the file lines. For applicable hunks, also returns
+// the index where the original lines start.
+func classifyRejectHunk(lines []string, hunk rejectHunk) (rejectHunkStatus, int) {
+	oldLines := hunk.oldLines()
+	newLines := hunk.newLines()
+
+	// Changes already in the file. Checked first, as the changed lines of a pure addition
+	// contain the original lines.
+	if len(findLineSequence(lines, newLines, true)) > 0 {
+		return rejectHunkAlreadyApplied, -1
+	}
+
+	// Find the original lines, exactly or ignoring whitespace.
+	oldMatches := findLineSequence(lines, oldLines, false)
+	if len(oldMatches) == 0 {
+		oldMatches = findLineSequence(lines, oldLines, true)
+	}
+
+	// Only apply when the location is unambiguous.
+	if len(oldMatches) == 1 {
+		return rejectHunkApplicable, oldMatches[0]
+	}
+	return rejectHunkConflict, -1
+}
+
+// applyRejectHunk replaces the original lines of an applicable hunk with the changed lines.
+// Returns false if the hunk is not applicable to the lines.
+func applyRejectHunk(lines []string, hunk rejectHunk) ([]string, bool) {
+	status, start := classifyRejectHunk(lines, hunk)
+	if status != rejectHunkApplicable {
+		return lines, false
+	}
+	return replaceHunkLines(lines, start, hunk), true
+}
+
+// replaceHunkLines replaces the original lines of the hunk, starting at index start in the file
+// lines, with the changed lines. The context lines are kept as they are in the file, so that
+// hunks matched ignoring whitespace don't change the whitespace of the surrounding code.
+func replaceHunkLines(lines []string, start int, hunk rejectHunk) []string {
+	numOldLines := len(hunk.oldLines())
+	result := make([]string, 0, len(lines)-numOldLines+len(hunk.newLines()))
+	result = append(result, lines[:start]...)
+	fileNdx := start
+	for _, line := range hunk.Lines {
+		switch line[0] {
+		case ' ':
+			result = append(result, lines[fileNdx])
+			fileNdx++
+		case '-':
+			fileNdx++
+		case '+':
+			result = append(result, line[1:])
+		}
+	}
+	result = append(result, lines[start+numOldLines:]...)
+	return result
+}
+
+// guessRejectHunkLocation returns the index in the file lines where the hunk most likely belongs:
+// the location of the first line of the hunk that occurs exactly once in the file, or the
+// hunk's original line number.
+func guessRejectHunkLocation(lines []string, hunk rejectHunk) int {
+	for offset, line := range hunk.oldLines() {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if matches := findLineSequence(lines, []string{line}, true); len(matches) == 1 {
+			return max(matches[0]-offset, 0)
+		}
+	}
+	return max(min(hunk.OldStart-1, len(lines)-1), 0)
+}
+
+// printRejectHunkContext prints the rejected hunk, showing the change made to the old SDK, and
+// the lines around its likely location in the new SDK file.
+func printRejectHunkContext(lines []string, hunk rejectHunk) {
+	log.Info().Msg("")
+	log.Info().Msg("    Your change to the old SDK:")
+	for _, line := range hunk.Lines {
+		switch line[0] {
+		case '-':
+			log.Info().Msgf("      %s", styles.RenderError(line))
+		case '+':
+			log.Info().Msgf("      %s", styles.RenderSuccess(line))
+		default:
+			log.Info().Msgf("      %s", styles.RenderMuted(line))
+		}
+	}
+
+	if len(lines) > 0 {
+		const contextLines = 3
+		location := guessRejectHunkLocation(lines, hunk)
+		start := max(location-contextLines, 0)
+		end := min(location+len(hunk.oldLines())+contextLines, len(lines))
+		log.Info().Msg("")
+		log.Info().Msgf("    New SDK, lines %d-%d:", start+1, end)
+		for ndx := start; ndx < end; ndx++ {
+			log.Info().Msgf("      %s %s", styles.RenderMuted(fmt.Sprintf("%5d", ndx+1)), lines[ndx])
+		}
+	}
+	log.Info().Msg("")
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRejectContent = `diff a/MetaplaySDK/Foo.cs b/MetaplaySDK/Foo.cs	(rejected hunks)
+@@ -10,4 +10,4 @@ class Foo
+ void A()
+ {
+-    Bar(1);
++    Bar(2);
+ }
+@@ -20,3 +20,4 @@ class Foo
+ void B()
+ {
++    Log();
+ }
+`
+
+func TestParseRejectFile(t *testing.T) {
+	rej, err := parseRejectFile(testRejectContent)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"diff a/MetaplaySDK/Foo.cs b/MetaplaySDK/Foo.cs\t(rejected hunks)"}, rej.Preamble)
+	require.Len(t, rej.Hunks, 2)
+	assert.Equal(t, 10, rej.Hunks[0].OldStart)
+	assert.Equal(t, []string{"void A()", "{", "    Bar(1);", "}"}, rej.Hunks[0].oldLines())
+	assert.Equal(t, []string{"void A()", "{", "    Bar(2);", "}"}, rej.Hunks[0].newLines())
+	assert.Equal(t, 20, rej.Hunks[1].OldStart)
+
+	// Rendering round-trips the content.
+	assert.Equal(t, testRejectContent, rej.Render())
+
+	_, err = parseRejectFile("--- a/Foo.cs\n+++ b/Foo.cs\n")
+	assert.Error(t, err)
+}
+
+func TestClassifyRejectHunk(t *testing.T) {
+	rej, err := parseRejectFile(testRejectContent)
+	require.NoError(t, err)
+	hunkA := rej.Hunks[0]
+	hunkB := rej.Hunks[1]
+
+	// Code moved in the new SDK, with different indentation.
+	lines := []string{"// New header", "  void A()", "  {", "      Bar(1);", "  }", "void B()", "{", "    Log();", "}"}
+	status, start := classifyRejectHunk(lines, hunkA)
+	assert.Equal(t, rejectHunkApplicable, status)
+	assert.Equal(t, 1, start)
+
+	// Pure addition that is already in the file.
+	status, _ = classifyRejectHunk(lines, hunkB)
+	assert.Equal(t, rejectHunkAlreadyApplied, status)
+
+	// The original lines are ambiguous.
+	ambiguous := []string{"void A()", "{", "    Bar(1);", "}", "void A()", "{", "    Bar(1);", "}"}
+	status, _ = classifyRejectHunk(ambiguous, hunkA)
+	assert.Equal(t, rejectHunkConflict, status)
+
+	// The code changed in the new SDK.
+	changed := []string{"void A()", "{", "    Baz(1);", "}"}
+	status, _ = classifyRejectHunk(changed, hunkA)
+	assert.Equal(t, rejectHunkConflict, status)
+	assert.Equal(t, 0, guessRejectHunkLocation(changed, hunkA))
+}
+
+func TestApplyRejectHunk(t *testing.T) {
+	rej, err := parseRejectFile(testRejectContent)
+	require.NoError(t, err)
+
+	lines, _, _ := splitFileLines("// Header\r\nvoid A()\r\n{\r\n    Bar(1);\r\n}\r\n")
+	result, ok := applyRejectHunk(lines, rej.Hunks[0])
+	require.True(t, ok)
+	assert.Equal(t, []string{"// Header", "void A()", "{", "    Bar(2);", "}"}, result)
+
+	// Applying again is not possible.
+	_, ok = applyRejectHunk(result, rej.Hunks[0])
+	assert.False(t, ok)
+}
+
+func TestSplitFileLines(t *testing.T) {
+	lines, lineEnding, hasFinalNewline := splitFileLines("a\r\nb\r\n")
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.Equal(t, "\r\n", lineEnding)
+	assert.True(t, hasFinalNewline)
+
+	lines, lineEnding, hasFinalNewline = splitFileLines("a\nb")
+	assert.Equal(t, []string{"a", "b"}, lines)
+	assert.Equal(t, "\n", lineEnding)
+	assert.False(t, hasFinalNewline)
+}
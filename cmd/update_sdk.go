@@ -28,6 +28,8 @@ type updateSdkOpts struct {
 	flagAutoAgreeContracts bool   // Automatically agree to contracts
 	flagYes                bool   // Skip confirmation prompts
 	flagSkipPatch          bool   // Skip patch file generation
+	flagDryRun             bool   // Only show what would be done
+	flagGitRef             string // Git tag or commit to check out when the SDK is a git checkout
 }
 
 func init() {
@@ -47,14 +49,28 @@ func init() {
 
 			Experimental: If local modifications to SDK files are detected, a patch file
 			(metaplay-sdk-modifications.patch) will be extracted before updating. You can
-			re-apply the changes with 'patch -p1' or 'git apply --reject'. Some hunks may
-			fail if there are conflicts with the new SDK version and will require manual
-			resolution. This feature is experimental - please ensure you use version control
+			re-apply the changes with 'metaplay update apply-patch'. Some hunks may fail if
+			there are conflicts with the new SDK version and will require manual resolution;
+			'metaplay update resolve-rejects' helps with going through the rejected hunks. This feature is experimental - please ensure you use version control
 			(e.g., git) to have a backup of your SDK modifications.
 
 			You may also use your own preferred way to preserve the changes. If so, use
 			--skip-patch to disable patch file generation.
 
+			If the SDK directory is a git repository of its own, eg, a git submodule of the
+			project repository, the SDK is updated by fetching the tags and checking out the
+			release tag of the target version (eg, 'release-35.2') instead of replacing the files
+			from the SDK zip. Use --git-ref to check out a specific tag or commit instead. Your
+			SDK modifications are tracked by git, so no patch file is generated. The SDK repository
+			must not have uncommitted changes, or local commits that are not in the checked-out ref
+			(rebase them onto the release, and use --git-ref=HEAD). After the checkout, the version in
+			version.yaml must match the target version. For submodules, remember to commit the
+			updated submodule in the project repository.
+
+			With --dry-run, the target version is resolved and the modifications are detected as
+			usual, but the patch file is not written and the SDK is not replaced. No confirmation
+			is required.
+
 			You must be logged in to the Metaplay portal (use 'metaplay auth login').
 		`),
 		Example: renderExample(`
@@ -72,6 +88,15 @@ func init() {
 
 			# Skip patch file generation (when handling SDK modifications yourself)
 			metaplay update sdk --skip-patch
+
+			# Show what updating to the latest 35.x would do without changing anything
+			metaplay update sdk --to-version=35 --dry-run
+
+			# Update an SDK git submodule to a specific tag of the SDK repository
+			metaplay update sdk --to-version=35.2 --git-ref=release-35.2-hotfix
+
+			# Replay the answers recorded earlier with --record-answers
+			metaplay update sdk --answers answers.yaml
 		`),
 	}
 
@@ -80,13 +105,15 @@ func init() {
 	flags.BoolVar(&o.flagAutoAgreeContracts, "auto-agree", false, "Automatically agree to privacy policy and terms & conditions")
 	flags.BoolVar(&o.flagYes, "yes", false, "Skip confirmation prompts")
 	flags.BoolVar(&o.flagSkipPatch, "skip-patch", false, "Skip patch file generation for SDK modifications")
+	flags.StringVar(&o.flagGitRef, "git-ref", "", "Git tag or commit to check out when the SDK is a git submodule (default: the target version's release tag)")
+	addDryRunFlag(flags, &o.flagDryRun)
 
 	updateCmd.AddCommand(cmd)
 }
 
 func (o *updateSdkOpts) Prepare(cmd *cobra.Command, args []string) error {
 	// Validate non-interactive mode requirements
-	if !tui.IsInteractiveMode() && o.flagToVersion == "" {
+	if !tui.CanAskQuestions() && o.flagToVersion == "" {
 		return fmt.Errorf("in non-interactive mode, --to-version is required")
 	}
 	return nil
@@ -125,6 +152,16 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	}
 	currentVersion := versionMetadata.SdkVersion
 
+	// Detect an SDK in its own git repository (eg, a submodule), updated with git instead of the zip.
+	sdkCheckout, err := detectSdkGitCheckout(ctx, sdkRootDirAbs)
+	if err != nil {
+		return err
+	}
+	if o.flagGitRef != "" && sdkCheckout == nil {
+		return clierrors.NewUsageError("--git-ref can only be used when the SDK is a git submodule or repository").
+			WithSuggestion(fmt.Sprintf("The SDK at %s is not the root of a git repository", sdkRootDirAbs))
+	}
+
 	// Display header
 	log.Info().Msg("")
 	log.Info().Msg(styles.RenderTitle("Update Metaplay SDK"))
@@ -143,7 +180,7 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	var patchContent string
 	var modificationCheckDone bool
 
-	if !o.flagSkipPatch {
+	if !o.flagSkipPatch && sdkCheckout == nil {
 		currentVersionInfo, err := portalClient.FindSdkVersionByVersionOrName(currentVersion.String())
 		if err != nil {
 			return clierrors.Wrap(err, "Failed to look up current SDK version in portal").
@@ -176,6 +213,13 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg("")
 	log.Info().Msgf("Current SDK version:  %s", styles.RenderTechnical(currentVersion.String()))
 	log.Info().Msgf("SDK location:         %s", styles.RenderTechnical(sdkRootDirAbs))
+	if sdkCheckout != nil {
+		if sdkCheckout.IsSubmodule {
+			log.Info().Msgf("SDK layout:           %s", styles.RenderTechnical("git submodule"))
+		} else {
+			log.Info().Msgf("SDK layout:           %s", styles.RenderTechnical("git repository"))
+		}
+	}
 	if modificationCheckDone {
 		if len(modifications) > 0 {
 			log.Info().Msgf("Modifications to SDK: %s", styles.RenderWarning(fmt.Sprintf("%d file(s)", len(modifications))))
@@ -188,7 +232,7 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	// Show modified files and warn user before proceeding
 	var patchPath string
 	if len(modifications) > 0 {
-		patchPath = filepath.Join(projectDir, "metaplay-sdk-modifications.patch")
+		patchPath = filepath.Join(projectDir, sdkModificationsPatchFileName)
 		log.Info().Msg(styles.RenderTitle("SDK Modifications Detected"))
 		log.Info().Msg("")
 		log.Info().Msg(styles.RenderWarning("EXPERIMENTAL: Automatic change preservation is an experimental feature."))
@@ -207,21 +251,22 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 			log.Info().Msg("")
 		}
 
-		log.Info().Msgf("After the update, you can re-apply the changes from the SDK directory with either:")
-		log.Info().Msgf("  %s", styles.RenderPrompt("patch -p1 < "+patchPath))
-		log.Info().Msg("or")
-		log.Info().Msgf("  %s", styles.RenderPrompt("git apply --reject "+patchPath))
+		log.Info().Msgf("After the update, you can re-apply the changes with:")
+		log.Info().Msgf("  %s", styles.RenderPrompt("metaplay update apply-patch"))
 		log.Info().Msg("")
-		log.Info().Msg("Tip: Use 'patch -p1 --dry-run < <patch>' first to preview what will be applied.")
+		log.Info().Msg("Tip: Use 'metaplay update apply-patch --dry-run' first to preview what will be applied.")
 		log.Info().Msg("")
 		log.Info().Msg("Note: Some hunks may fail if there are conflicting changes in the new SDK.")
 		log.Info().Msg("      Failed hunks are saved to .rej files for manual resolution.")
+		log.Info().Msg("      Use 'metaplay update resolve-rejects' to go through them.")
 		log.Info().Msg("")
 		log.Info().Msg(styles.RenderWarning("If you encounter any issues with handling the patch, please report them at https://github.com/metaplay/cli/issues"))
 		log.Info().Msg("")
 
-		// Ask for confirmation
-		if tui.IsInteractiveMode() {
+		// Ask for confirmation (not needed in dry-run mode as nothing is modified)
+		if o.flagDryRun {
+			log.Info().Msg(styles.RenderMuted("Dry-run mode: skipping confirmation"))
+		} else if tui.CanAskQuestions() {
 			confirmed, err := tui.DoConfirmQuestion(ctx, "Continue with update?")
 			if err != nil {
 				return err
@@ -238,7 +283,7 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 		}
 
 		// Save patch file
-		if patchContent != "" {
+		if patchContent != "" && !o.flagDryRun {
 			if err := os.WriteFile(patchPath, []byte(patchContent), 0644); err != nil {
 				log.Warn().Msgf("Could not save patch file: %v", err)
 			}
@@ -281,8 +326,8 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	}
 
 	// Confirm update (when no modifications were detected)
-	if len(modifications) == 0 && !o.flagYes {
-		if !tui.IsInteractiveMode() {
+	if len(modifications) == 0 && !o.flagYes && !o.flagDryRun {
+		if !tui.CanAskQuestions() {
 			return fmt.Errorf("confirmation required; use --yes to skip")
 		}
 
@@ -297,32 +342,68 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 		}
 	}
 
-	// Ensure contracts accepted
-	if err := ensureSdkDownloadContractsAccepted(ctx, portalClient, o.flagAutoAgreeContracts); err != nil {
-		return err
-	}
-
 	// Validate the target SDK version is supported
 	if _, err := parseAndValidateSdkVersion(targetVersion.Version); err != nil {
 		return err
 	}
 
+	// If dry-run mode, show the update steps and stop here.
+	if o.flagDryRun {
+		dryRun := dryRunPlan{}
+		if sdkCheckout != nil {
+			gitRef := o.flagGitRef
+			if gitRef == "" {
+				gitRef = "the first existing tag of " + strings.Join(sdkReleaseTagCandidates(targetVersion), ", ")
+			}
+			dryRun.Addf("Fetch tags in the SDK repository at %s", styles.RenderTechnical(sdkRootDirAbs))
+			dryRun.Addf("Check out %s", styles.RenderTechnical(gitRef))
+			dryRun.Addf("Check that version.yaml is for SDK %s", styles.RenderTechnical(targetVersion.Version))
+			dryRun.Print()
+			return nil
+		}
+		if len(modifications) > 0 && patchContent != "" {
+			dryRun.Addf("Write SDK modifications patch to %s", styles.RenderTechnical(patchPath))
+		}
+		dryRun.Addf("Remove existing SDK %s at %s", styles.RenderTechnical(currentVersion.String()), styles.RenderTechnical(sdkRootDirAbs))
+		dryRun.Addf("Download and extract SDK %s into %s", styles.RenderTechnical(targetVersion.Version), styles.RenderTechnical(filepath.Dir(sdkRootDirAbs)))
+		dryRun.Print()
+		return nil
+	}
+
 	// Apply the update
-	log.Info().Msg("")
-	log.Info().Msg(styles.RenderTitle(fmt.Sprintf("Updating SDK to %s", styles.RenderTechnical(targetVersion.Version))))
-	log.Info().Msg("")
+	if sdkCheckout != nil {
+		// Check out the target version in the SDK repository.
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle(fmt.Sprintf("Updating SDK to %s", styles.RenderTechnical(targetVersion.Version))))
+		log.Info().Msg("")
 
-	// Remove existing SDK directory (with retries for transient file locks)
-	log.Info().Msgf("  Removing existing SDK at %s...", styles.RenderTechnical(sdkRootDirAbs))
-	if err := removeDirectoryWithRetry(sdkRootDirAbs, 3, 2*time.Second); err != nil {
-		return fmt.Errorf("failed to remove existing SDK directory: %w\n\nThis can happen if files are in use by another process (e.g., Unity, IDE, dashboard dev server). Close any applications using SDK files and try again", err)
-	}
+		gitRef, err := updateSdkGitCheckout(ctx, sdkCheckout, targetVersion, o.flagGitRef)
+		if err != nil {
+			return err
+		}
+		log.Info().Msgf("  Checked out %s", styles.RenderTechnical(gitRef))
+	} else {
+		// Ensure contracts accepted
+		if err := ensureSdkDownloadContractsAccepted(ctx, portalClient, o.flagAutoAgreeContracts); err != nil {
+			return err
+		}
 
-	// Download and extract new SDK
-	log.Info().Msgf("  Downloading and extracting SDK %s...", styles.RenderTechnical(targetVersion.Version))
-	parentDir := filepath.Dir(sdkRootDirAbs)
-	if _, err := downloadAndExtractSdk(tokenSet, parentDir, targetVersion); err != nil {
-		return fmt.Errorf("failed to download and extract SDK: %w", err)
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle(fmt.Sprintf("Updating SDK to %s", styles.RenderTechnical(targetVersion.Version))))
+		log.Info().Msg("")
+
+		// Remove existing SDK directory (with retries for transient file locks)
+		log.Info().Msgf("  Removing existing SDK at %s...", styles.RenderTechnical(sdkRootDirAbs))
+		if err := removeDirectoryWithRetry(sdkRootDirAbs, 3, 2*time.Second); err != nil {
+			return fmt.Errorf("failed to remove existing SDK directory: %w\n\nThis can happen if files are in use by another process (e.g., Unity, IDE, dashboard dev server). Close any applications using SDK files and try again", err)
+		}
+
+		// Download and extract new SDK
+		log.Info().Msgf("  Downloading and extracting SDK %s...", styles.RenderTechnical(targetVersion.Version))
+		parentDir := filepath.Dir(sdkRootDirAbs)
+		if _, err := downloadAndExtractSdk(tokenSet, parentDir, targetVersion); err != nil {
+			return fmt.Errorf("failed to download and extract SDK: %w", err)
+		}
 	}
 
 	// Success message and release notes
@@ -342,6 +423,17 @@ func (o *updateSdkOpts) Run(cmd *cobra.Command) error {
 	log.Info().Msg("")
 	log.Info().Msgf("📖 Release notes: %s", releaseNotesURL)
 
+	// The updated submodule must be committed in the project repository.
+	if sdkCheckout != nil && sdkCheckout.IsSubmodule {
+		submodulePath, err := filepath.Rel(sdkCheckout.SuperprojectDir, sdkCheckout.Dir)
+		if err != nil {
+			submodulePath = sdkCheckout.Dir
+		}
+		log.Info().Msg("")
+		log.Info().Msgf("Commit the updated SDK submodule in the project repository with:")
+		log.Info().Msgf("  %s", styles.RenderPrompt(fmt.Sprintf("git add %s && git commit -m \"Update Metaplay SDK to %s\"", filepath.ToSlash(submodulePath), targetVersion.Version)))
+	}
+
 	if isMajorUpdate {
 		log.Info().Msg("")
 		log.Info().Msg(styles.RenderPrompt("This is a major release update. Follow the migration guide in the release notes!"))
@@ -0,0 +1,221 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/rs/zerolog/log"
+)
+
+// sdkGitCheckout describes an SDK directory that is the root of its own git repository, eg,
+// a git submodule of the project repository. Such SDKs are updated by checking out the
+// release tag instead of replacing the files from the SDK zip.
+type sdkGitCheckout struct {
+	Dir             string // Absolute path to the SDK directory (the root of the SDK repository)
+	IsSubmodule     bool   // Is the SDK repository a git submodule of another repository?
+	SuperprojectDir string // Root of the repository containing the submodule, if a submodule
+}
+
+// detectSdkGitCheckout checks whether the SDK directory is the root of its own git repository.
+// Returns nil if it is not, eg, when the SDK files are committed directly into the project
+// repository, or git is not available.
+func detectSdkGitCheckout(ctx context.Context, sdkRootDirAbs string) (*sdkGitCheckout, error) {
+	topLevel, err := runGitOutput(ctx, sdkRootDirAbs, "rev-parse", "--show-toplevel")
+	if err != nil {
+		log.Debug().Msgf("SDK directory %s is not in a git repository: %v", sdkRootDirAbs, err)
+		return nil, nil
+	}
+
+	// The SDK must be the root of the repository, not just a directory in the project repository.
+	sameDir, err := isSameDirectory(topLevel, sdkRootDirAbs)
+	if err != nil {
+		return nil, err
+	}
+	if !sameDir {
+		return nil, nil
+	}
+
+	// Submodules know the working tree of their superproject.
+	superprojectDir, err := runGitOutput(ctx, sdkRootDirAbs, "rev-parse", "--show-superproject-working-tree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the superproject of the SDK repository: %w", err)
+	}
+
+	return &sdkGitCheckout{
+		Dir:             sdkRootDirAbs,
+		IsSubmodule:     superprojectDir != "",
+		SuperprojectDir: superprojectDir,
+	}, nil
+}
+
+// sdkReleaseTagCandidates returns the tag names that the SDK release may be tagged with in the
+// SDK repository, in the order of preference, eg, 'release-35.2' or 'v35.2'.
+func sdkReleaseTagCandidates(targetVersion *portalapi.SdkVersionInfo) []string {
+	candidates := []string{
+		"release-" + targetVersion.Version,
+		"Release-" + targetVersion.Version,
+		"v" + targetVersion.Version,
+		targetVersion.Version,
+	}
+	// The release name, eg, 'Release 35.2' -> 'Release-35.2'.
+	if targetVersion.Name != "" {
+		nameTag := strings.Join(strings.Fields(targetVersion.Name), "-")
+		if !slices.Contains(candidates, nameTag) {
+			candidates = append(candidates, nameTag)
+		}
+	}
+	return candidates
+}
+
+// findSdkReleaseTag returns the first of the candidate tags that exists in the list of tags.
+func findSdkReleaseTag(tags []string, candidates []string) string {
+	for _, candidate := range candidates {
+		if slices.Contains(tags, candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// updateSdkGitCheckout updates the SDK repository to the target version by checking out gitRef,
+// or the target version's release tag if gitRef is empty. The version.yaml of the checked-out
+// SDK must match the target version, otherwise the previous commit is restored.
+func updateSdkGitCheckout(ctx context.Context, checkout *sdkGitCheckout, targetVersion *portalapi.SdkVersionInfo, gitRef string) (string, error) {
+	// Refuse to check out over uncommitted changes to the SDK files.
+	isDirty, err := isGitWorkingTreeDirty(ctx, checkout.Dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to check the SDK repository for uncommitted changes: %w", err)
+	}
+	if isDirty {
+		return "", clierrors.Newf("The SDK repository at %s has uncommitted changes", checkout.Dir).
+			WithSuggestion("Commit or stash the changes in the SDK repository before updating")
+	}
+
+	// Remember the current commit so that it can be restored if the update fails.
+	previousCommit, err := runGitOutput(ctx, checkout.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the current SDK commit: %w", err)
+	}
+
+	log.Info().Msgf("  Fetching tags in %s...", checkout.Dir)
+	if _, err := runGitOutput(ctx, checkout.Dir, "fetch", "--tags", "--force"); err != nil {
+		return "", clierrors.Wrap(err, "Failed to fetch tags from the SDK repository remote").
+			WithSuggestion("Check that the SDK repository's remote is reachable with 'git fetch'")
+	}
+
+	// Resolve the release tag, unless a ref was given explicitly.
+	if gitRef == "" {
+		tagsOutput, err := runGitOutput(ctx, checkout.Dir, "tag", "--list")
+		if err != nil {
+			return "", fmt.Errorf("failed to list tags in the SDK repository: %w", err)
+		}
+		candidates := sdkReleaseTagCandidates(targetVersion)
+		gitRef = findSdkReleaseTag(strings.Fields(tagsOutput), candidates)
+		if gitRef == "" {
+			return "", clierrors.Newf("No release tag found for SDK %s in the SDK repository", targetVersion.Version).
+				WithDetails(fmt.Sprintf("Looked for tags: %s", strings.Join(candidates, ", "))).
+				WithSuggestion("Specify the tag or commit to check out with --git-ref")
+		}
+	}
+
+	// Refuse to leave behind local commits that are not in the target ref, as the updated SDK
+	// would silently lose the local modifications.
+	localCommits, err := listGitCommitsNotInRef(ctx, checkout.Dir, gitRef)
+	if err != nil {
+		return "", clierrors.Wrapf(err, "Failed to resolve '%s' in the SDK repository", gitRef)
+	}
+	if len(localCommits) > 0 {
+		return "", clierrors.Newf("The SDK repository at %s has %d local commit(s) that are not in '%s'", checkout.Dir, len(localCommits), gitRef).
+			WithDetails(fmt.Sprintf("Local commits: %s", strings.Join(localCommits, ", "))).
+			WithSuggestion(fmt.Sprintf("Rebase the local commits onto the new version with 'git rebase %s' in the SDK repository, and run the update again with --git-ref=HEAD", gitRef))
+	}
+
+	log.Info().Msgf("  Checking out %s...", gitRef)
+	if _, err := runGitOutput(ctx, checkout.Dir, "checkout", "--detach", gitRef); err != nil {
+		return "", clierrors.Wrapf(err, "Failed to check out '%s' in the SDK repository", gitRef)
+	}
+
+	// The checked-out SDK must be the target version.
+	if err := checkSdkVersionMatches(checkout.Dir, targetVersion.Version); err != nil {
+		log.Info().Msgf("  Restoring the previous commit %s...", previousCommit)
+		if _, restoreErr := runGitOutput(ctx, checkout.Dir, "checkout", "--detach", previousCommit); restoreErr != nil {
+			log.Warn().Msgf("Failed to restore the previous SDK commit %s: %v", previousCommit, restoreErr)
+		}
+		return "", err
+	}
+
+	return gitRef, nil
+}
+
+// listGitCommitsNotInRef returns the abbreviated hashes of the local commits in HEAD that are
+// not reachable from the ref, ie, the commits that checking out the ref would leave behind. The
+// commits on the remote branches or tags (eg, a newer SDK release when downgrading) are not local.
+func listGitCommitsNotInRef(ctx context.Context, repoDir, ref string) ([]string, error) {
+	output, err := runGitOutput(ctx, repoDir, "rev-list", "--abbrev-commit", "HEAD", "--not", ref, "--remotes", "--tags")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+// checkSdkVersionMatches checks that the SDK's version.yaml is for the expected version.
+func checkSdkVersionMatches(sdkRootDir string, expectedVersion string) error {
+	versionMetadata, err := metaproj.LoadSdkVersionMetadata(sdkRootDir)
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to read the SDK version.yaml after the update")
+	}
+	expected, err := version.NewVersion(expectedVersion)
+	if err != nil {
+		return fmt.Errorf("invalid SDK version '%s': %w", expectedVersion, err)
+	}
+	if !versionMetadata.SdkVersion.Equal(expected) {
+		return clierrors.Newf("The checked-out SDK is version %s, expected %s", versionMetadata.SdkVersion, expected).
+			WithSuggestion("Specify the tag or commit of the target version with --git-ref")
+	}
+	return nil
+}
+
+// isSameDirectory returns true if the two paths refer to the same directory, resolving symlinks.
+func isSameDirectory(a, b string) (bool, error) {
+	aResolved, err := filepath.EvalSymlinks(a)
+	if err != nil {
+		return false, err
+	}
+	bResolved, err := filepath.EvalSymlinks(b)
+	if err != nil {
+		return false, err
+	}
+	return filepath.Clean(aResolved) == filepath.Clean(bResolved), nil
+}
+
+// runGitOutput runs git with the arguments in the directory and returns its trimmed output.
+func runGitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		trimmed := strings.TrimSpace(stderr.String())
+		if trimmed != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], truncateForLog(trimmed, 500))
+		}
+		return "", fmt.Errorf("git %s failed: %v", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
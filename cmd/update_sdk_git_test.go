@@ -0,0 +1,118 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSdkReleaseTagCandidates(t *testing.T) {
+	candidates := sdkReleaseTagCandidates(&portalapi.SdkVersionInfo{Version: "35.2", Name: "Release 35.2"})
+	assert.Equal(t, []string{"release-35.2", "Release-35.2", "v35.2", "35.2"}, candidates)
+
+	candidates = sdkReleaseTagCandidates(&portalapi.SdkVersionInfo{Version: "35.2", Name: "R35.2 Hotfix"})
+	assert.Equal(t, []string{"release-35.2", "Release-35.2", "v35.2", "35.2", "R35.2-Hotfix"}, candidates)
+}
+
+func TestFindSdkReleaseTag(t *testing.T) {
+	candidates := []string{"release-35.2", "v35.2", "35.2"}
+	assert.Equal(t, "v35.2", findSdkReleaseTag([]string{"v35.1", "v35.2", "35.2"}, candidates))
+	assert.Equal(t, "", findSdkReleaseTag([]string{"v35.1"}, candidates))
+}
+
+func TestDetectSdkGitCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "protocol.file.allow=always"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	// SDK files committed directly into the project repository.
+	projectDir := t.TempDir()
+	git(projectDir, "init", "--quiet")
+	sdkDir := filepath.Join(projectDir, "MetaplaySDK")
+	require.NoError(t, os.MkdirAll(sdkDir, 0755))
+	checkout, err := detectSdkGitCheckout(ctx, sdkDir)
+	require.NoError(t, err)
+	assert.Nil(t, checkout)
+
+	// Not in a git repository at all.
+	checkout, err = detectSdkGitCheckout(ctx, t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, checkout)
+
+	// SDK as a git submodule.
+	sdkRepoDir := t.TempDir()
+	git(sdkRepoDir, "init", "--quiet")
+	require.NoError(t, os.WriteFile(filepath.Join(sdkRepoDir, "README.md"), []byte("SDK\n"), 0644))
+	git(sdkRepoDir, "add", "README.md")
+	git(sdkRepoDir, "commit", "--quiet", "-m", "Initial")
+	git(projectDir, "submodule", "--quiet", "add", sdkRepoDir, "Submodule")
+	checkout, err = detectSdkGitCheckout(ctx, filepath.Join(projectDir, "Submodule"))
+	require.NoError(t, err)
+	require.NotNil(t, checkout)
+	assert.True(t, checkout.IsSubmodule)
+	sameDir, err := isSameDirectory(projectDir, checkout.SuperprojectDir)
+	require.NoError(t, err)
+	assert.True(t, sameDir)
+
+	// SDK as a standalone repository.
+	checkout, err = detectSdkGitCheckout(ctx, sdkRepoDir)
+	require.NoError(t, err)
+	require.NotNil(t, checkout)
+	assert.False(t, checkout.IsSubmodule)
+}
+
+func TestListGitCommitsNotInRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	repoDir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repoDir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "Release 35.1")
+	git("tag", "v35.1")
+	git("commit", "--quiet", "--allow-empty", "-m", "Release 35.2")
+	git("tag", "v35.2")
+
+	// The release commits are not local, even when downgrading.
+	commits, err := listGitCommitsNotInRef(ctx, repoDir, "v35.2")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+	commits, err = listGitCommitsNotInRef(ctx, repoDir, "v35.1")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+
+	// Local modifications on top of the release.
+	git("checkout", "--quiet", "--detach", "v35.1")
+	git("commit", "--quiet", "--allow-empty", "-m", "Local fix")
+	commits, err = listGitCommitsNotInRef(ctx, repoDir, "v35.2")
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	// Unknown refs are reported.
+	_, err = listGitCommitsNotInRef(ctx, repoDir, "v99.0")
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Pin the project, environment and auth profile for the commands run in the current shell.
+type useOpts struct {
+	UsePositionalArgs
+
+	argEnvironment string
+	flagClear      bool
+}
+
+func init() {
+	o := useOpts{}
+
+	args := o.Arguments()
+	args.AddStringArgumentOpt(&o.argEnvironment, "ENVIRONMENT", "Target environment name or id, eg, 'tough-falcons'. Shows the active context if omitted.")
+
+	cmd := &cobra.Command{
+		Use:   "use [ENVIRONMENT] [flags]",
+		Short: "Set the default project and environment for the commands run in this shell",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Set the environment that the commands run in the current shell target by default, so
+			that the ENVIRONMENT argument can be omitted, eg, 'metaplay debug logs' instead of
+			'metaplay debug logs nimbly'. Useful for focused debugging sessions on one environment.
+
+			The context pins the project directory, the environment and the credential profile
+			used for it. The pinned project is used when running the commands outside of any
+			project directory. Inside another project's directory, the context is ignored. The
+			pinned profile is used unless another one is given with --profile or METAPLAY_PROFILE.
+
+			The context is stored in the CLI's state directory, separately for each shell (based on
+			the shell's process ID). As process IDs get reused, the context of a shell expires after
+			24 hours. Set METAPLAYCLI_CONTEXT to name the context explicitly, eg, to share one
+			context between the panes of a terminal multiplexer or in scripts. Named contexts don't
+			expire.
+
+			An environment given on the command line always takes precedence over the context. Use
+			'metaplay use --clear' to reset the context.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay auth login' to log in to the environment's auth provider.
+			- 'metaplay update project-environments' to update the environments in metaplay-project.yaml.
+		`),
+		Example: renderExample(`
+			# Target the environment 'nimbly' by default in this shell.
+			metaplay use nimbly
+
+			# Now the ENVIRONMENT argument can be omitted.
+			metaplay debug logs
+			metaplay deploy server 364cff09
+
+			# Show the active context.
+			metaplay use
+
+			# Reset the context.
+			metaplay use --clear
+		`),
+		Annotations: map[string]string{annotationIgnoreCliContext: "true"},
+	}
+
+	cmd.GroupID = "other"
+	rootCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.BoolVar(&o.flagClear, "clear", false, "Reset the context of this shell")
+}
+
+func (o *useOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagClear && o.argEnvironment != "" {
+		return clierrors.NewUsageError("ENVIRONMENT cannot be given with --clear")
+	}
+	return nil
+}
+
+func (o *useOpts) Run(cmd *cobra.Command) error {
+	if o.flagClear {
+		cleared, err := clearCliContext()
+		if err != nil {
+			return err
+		}
+		if cleared {
+			log.Info().Msgf("%s Context cleared", styles.RenderSuccess("✓"))
+		} else {
+			log.Info().Msg("No active context to clear")
+		}
+		return nil
+	}
+
+	if o.argEnvironment == "" {
+		return o.showContext()
+	}
+
+	contextName, err := getCliContextName()
+	if err != nil {
+		return clierrors.WrapUsageError(err, "Invalid context name").
+			WithSuggestion(fmt.Sprintf("Unset %s or use a name with only letters, digits, '_', '.' and '-'", cliContextEnvVar))
+	}
+
+	// Resolve the project (if any) and check that the environment exists and that we're logged in.
+	project, err := tryResolveProject()
+	if err != nil {
+		return err
+	}
+	envConfig, _, err := resolveEnvironment(cmd.Context(), project, o.argEnvironment)
+	if err != nil {
+		return err
+	}
+
+	cliCtx := newCliContext(contextName, time.Now())
+	cliCtx.Environment = envConfig.HumanID
+	if project != nil {
+		projectDir, err := filepath.Abs(project.RelativeDir)
+		if err != nil {
+			return clierrors.Wrap(err, "Failed to resolve the project directory")
+		}
+		cliCtx.ProjectDir = projectDir
+	}
+
+	if err := saveCliContext(cliCtx); err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msgf("%s Commands in this shell now target %s by default", styles.RenderSuccess("✓"), styles.RenderTechnical(envConfig.HumanID))
+	log.Info().Msg("")
+	printCliContext(cliCtx)
+	log.Info().Msg("")
+	log.Info().Msgf("Run %s to reset.", styles.RenderPrompt("metaplay use --clear"))
+	return nil
+}
+
+// showContext prints the active context of the current shell.
+func (o *useOpts) showContext() error {
+	cliCtx, err := loadCliContext()
+	if err != nil {
+		return err
+	}
+	if cliCtx == nil {
+		log.Info().Msg("No active context in this shell.")
+		log.Info().Msgf("Run %s to set the default environment.", styles.RenderPrompt("metaplay use ENVIRONMENT"))
+		return nil
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Active Context"))
+	log.Info().Msg("")
+	printCliContext(cliCtx)
+	log.Info().Msg("")
+	return nil
+}
+
+// printCliContext prints the pinned values of the context.
+func printCliContext(cliCtx *cliContext) {
+	projectDir := cliCtx.ProjectDir
+	if projectDir == "" {
+		projectDir = styles.RenderMuted("(none)")
+	} else {
+		projectDir = styles.RenderTechnical(projectDir)
+	}
+	log.Info().Msgf("Context:      %s", styles.RenderTechnical(cliCtx.Name))
+	log.Info().Msgf("Project:      %s", projectDir)
+	log.Info().Msgf("Environment:  %s", styles.RenderTechnical(cliCtx.Environment))
+	log.Info().Msgf("Auth profile: %s", styles.RenderTechnical(cliCtx.AuthProfile))
+	log.Info().Msgf("Set at:       %s", styles.RenderMuted(cliCtx.CreatedAt.Local().Format(time.DateTime)))
+	if !cliCtx.ExpiresAt.IsZero() {
+		log.Info().Msgf("Expires at:   %s", styles.RenderMuted(cliCtx.ExpiresAt.Local().Format(time.DateTime)))
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/spf13/cobra"
+)
+
+// Per-user settings of the CLI, loaded before running a command. Empty if there is no settings
+// file. Managed with 'metaplay config'.
+var userConfig = &common.UserConfig{}
+
+// Environment variables to set for the child processes when telemetry is opted out of.
+var telemetryOptOutEnvVars = map[string]string{
+	"DOTNET_CLI_TELEMETRY_OPTOUT": "1", // .NET SDK, eg, 'dotnet build'
+	"NUXT_TELEMETRY_DISABLED":     "1", // Nuxt, eg, building the dashboard
+}
+
+// loadUserConfig loads the per-user settings. On failure, the settings are left empty, so that
+// a broken settings file doesn't prevent using the CLI (including 'metaplay config' to fix it).
+func loadUserConfig() error {
+	config, err := common.LoadUserConfig()
+	if err != nil {
+		userConfig = &common.UserConfig{}
+		return err
+	}
+	userConfig = config
+	return nil
+}
+
+// resolveColorMode returns the color mode to use: METAPLAYCLI_COLOR, or else --color if given,
+// or else the user's setting, or else the default of the flag.
+func resolveColorMode(cmd *cobra.Command) string {
+	colorMode := flagColorMode
+	if flag := cmd.Flags().Lookup("color"); (flag == nil || !flag.Changed) && userConfig.Color != "" {
+		colorMode = userConfig.Color
+	}
+	return coalesceString(os.Getenv("METAPLAYCLI_COLOR"), colorMode)
+}
+
+// applyUserConfig applies the per-user settings that are not resolved elsewhere. Explicitly
+// given flags and environment variables take precedence over the settings.
+func applyUserConfig(cmd *cobra.Command) {
+	// Portal base URL: METAPLAYCLI_PORTAL_BASEURL takes precedence.
+	if userConfig.PortalBaseURL != "" && os.Getenv("METAPLAYCLI_PORTAL_BASEURL") == "" {
+		common.PortalBaseURL = userConfig.PortalBaseURL
+	}
+
+	// Default output format: only applies to the commands whose --format defaults to 'text',
+	// as the others use different formats, eg, 'metaplay database query'.
+	if userConfig.OutputFormat != "" {
+		if flag := cmd.Flags().Lookup("format"); flag != nil && !flag.Changed && flag.DefValue == "text" {
+			_ = flag.Value.Set(userConfig.OutputFormat)
+		}
+	}
+
+	// Telemetry opt-out: inherited by all the child processes. Values set by the user are kept.
+	if userConfig.TelemetryOptOut {
+		for name, value := range telemetryOptOutEnvVars {
+			if _, exists := os.LookupEnv(name); !exists {
+				_ = os.Setenv(name, value)
+			}
+		}
+	}
+}
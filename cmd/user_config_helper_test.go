@@ -0,0 +1,65 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveColorMode(t *testing.T) {
+	defer func() { userConfig = &common.UserConfig{} }()
+	newCmd := func(args ...string) *cobra.Command {
+		flagColorMode = "auto"
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVar(&flagColorMode, "color", "auto", "")
+		assert.NoError(t, cmd.Flags().Parse(args))
+		return cmd
+	}
+
+	t.Setenv("METAPLAYCLI_COLOR", "")
+	userConfig = &common.UserConfig{}
+	assert.Equal(t, "auto", resolveColorMode(newCmd()))
+
+	// Setting is used when no flag is given.
+	userConfig = &common.UserConfig{Color: "no"}
+	assert.Equal(t, "no", resolveColorMode(newCmd()))
+
+	// Flag and environment variable take precedence.
+	assert.Equal(t, "yes", resolveColorMode(newCmd("--color=yes")))
+	t.Setenv("METAPLAYCLI_COLOR", "yes")
+	assert.Equal(t, "yes", resolveColorMode(newCmd()))
+}
+
+func TestApplyUserConfigOutputFormat(t *testing.T) {
+	defer func() { userConfig = &common.UserConfig{} }()
+	userConfig = &common.UserConfig{OutputFormat: "json"}
+
+	newCmd := func(defaultFormat string, args ...string) (*cobra.Command, *string) {
+		var format string
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVar(&format, "format", defaultFormat, "")
+		assert.NoError(t, cmd.Flags().Parse(args))
+		return cmd, &format
+	}
+
+	// Default of the flag is replaced.
+	cmd, format := newCmd("text")
+	applyUserConfig(cmd)
+	assert.Equal(t, "json", *format)
+
+	// Explicit flag takes precedence.
+	cmd, format = newCmd("text", "--format=text")
+	applyUserConfig(cmd)
+	assert.Equal(t, "text", *format)
+
+	// Commands with other formats are not affected.
+	cmd, format = newCmd("table")
+	applyUserConfig(cmd)
+	assert.Equal(t, "table", *format)
+}
@@ -0,0 +1,20 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// validateCmd is the root for all "validate" related subcommands.
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate project files without building or deploying",
+	Long:  "Commands for statically checking project files to catch mistakes before they are deployed.",
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
@@ -0,0 +1,364 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// How often the files are checked for changes in watch mode.
+const validateProjectWatchInterval = 500 * time.Millisecond
+
+// Validate the project config and the files referenced by it, optionally re-validating on changes.
+type validateProjectOpts struct {
+	UsePositionalArgs
+
+	flagWatch bool
+}
+
+// projectValidationIssue is a problem found in one of the project's files.
+type projectValidationIssue struct {
+	FilePath string // Path to the file with the issue
+	Line     int    // Line number in the file (1-based), or 0 if unknown
+	Message  string // Description of the problem
+}
+
+// projectValidationResult is the result of validating the project's files.
+type projectValidationResult struct {
+	NumFiles     int                      // Number of files validated
+	Issues       []projectValidationIssue // Issues found, in file order
+	WatchedFiles []string                 // Files that affect the validation, including missing ones
+}
+
+// fileStamp identifies a version of a file for detecting changes to it.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+// Matches the line number in yaml.v3 errors, eg, 'yaml: line 12: mapping values are not allowed'.
+var yamlErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+
+func init() {
+	o := validateProjectOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "project [flags]",
+		Short: "Validate metaplay-project.yaml and the files referenced by it",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Validate the project config (metaplay-project.yaml) and the files referenced by it:
+			- The project config is parsed and validated as when running any other command.
+			- The Helm values files of the environments must exist and be valid YAML.
+			- The game server runtime options files (Config/Options.*.yaml) are validated against
+			  the option schema of the SDK, if the SDK includes it. Otherwise, only their YAML
+			  syntax is checked.
+
+			The issues are printed with the file and line, along with the surrounding lines of
+			the file when the line is known.
+
+			With --watch, the files are validated again whenever any of them changes, so you can
+			edit the configs with instant feedback. Stop watching with Ctrl+C.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay validate runtime-options ...' to validate only the runtime options files.
+		`),
+		Example: renderExample(`
+			# Validate the project config and the files referenced by it.
+			metaplay validate project
+
+			# Validate again on every save while editing the files.
+			metaplay validate project --watch
+		`),
+	}
+	validateCmd.AddCommand(cmd)
+
+	cmd.Flags().BoolVarP(&o.flagWatch, "watch", "w", false, "Validate the files again whenever they change")
+}
+
+func (o *validateProjectOpts) Prepare(cmd *cobra.Command, args []string) error {
+	return nil
+}
+
+func (o *validateProjectOpts) Run(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+
+	// Find the project directory. The config itself is loaded during validation.
+	projectDir, err := findProjectDirectory()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Validate Project"))
+	log.Info().Msg("")
+
+	for {
+		result := validateProjectFiles(projectDir)
+		printProjectValidationResult(result)
+
+		if !o.flagWatch {
+			if len(result.Issues) > 0 {
+				return clierrors.Newf("Found %d issue(s) in the project files", len(result.Issues)).
+					WithSuggestion("Fix the issues listed above")
+			}
+			return nil
+		}
+
+		log.Info().Msg(styles.RenderMuted(fmt.Sprintf("Watching %d file(s) for changes, press Ctrl+C to stop...", len(result.WatchedFiles))))
+		changedFile, err := waitForFileChange(ctx, result.WatchedFiles, validateProjectWatchInterval)
+		if err != nil {
+			// Interrupted by the user.
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+
+		log.Info().Msg("")
+		log.Info().Msgf("%s %s changed, validating again", styles.RenderMuted(time.Now().Format("15:04:05")), styles.RenderTechnical(filepath.ToSlash(changedFile)))
+		log.Info().Msg("")
+	}
+}
+
+// validateProjectFiles validates the project config and the files it references. The validation
+// continues past the issues where possible, so that all of them can be fixed at once.
+func validateProjectFiles(projectDir string) projectValidationResult {
+	result := projectValidationResult{}
+	addIssue := func(filePath string, line int, message string) {
+		result.Issues = append(result.Issues, projectValidationIssue{FilePath: filePath, Line: line, Message: message})
+	}
+
+	// Parse the project config.
+	configPath := filepath.Join(projectDir, metaproj.ConfigFileName)
+	result.WatchedFiles = append(result.WatchedFiles, configPath)
+	result.NumFiles++
+	configContent, err := os.ReadFile(configPath)
+	if err != nil {
+		addIssue(configPath, 0, fmt.Sprintf("Failed to read the project config: %v", err))
+		return result
+	}
+	var config metaproj.ProjectConfig
+	if err := yaml.Unmarshal(configContent, &config); err != nil {
+		addIssue(configPath, parseYAMLErrorLine(err), err.Error())
+		return result
+	}
+
+	// Validate the project config like when loading the project, pointing to the offending
+	// field when it can be located.
+	if err := metaproj.ApplyProjectConfigDefaults(&config); err != nil {
+		addIssue(configPath, 0, err.Error())
+	} else if err := metaproj.ValidateProjectConfig(projectDir, &config); err != nil {
+		message := err.Error()
+		addIssue(configPath, findYAMLKeyLine(configContent, message), message)
+	}
+	project := &metaproj.MetaplayProject{Config: config, RelativeDir: projectDir}
+
+	// Check the Helm values files of the environments.
+	valuesFiles := []string{}
+	for ndx := range config.Environments {
+		envConfig := &config.Environments[ndx]
+		for _, valuesFile := range append(project.GetServerValuesFiles(envConfig), project.GetBotClientValuesFiles(envConfig)...) {
+			if !slices.Contains(valuesFiles, valuesFile) {
+				valuesFiles = append(valuesFiles, valuesFile)
+			}
+		}
+	}
+	for _, valuesFile := range valuesFiles {
+		result.WatchedFiles = append(result.WatchedFiles, valuesFile)
+		result.NumFiles++
+		content, err := os.ReadFile(valuesFile)
+		if err != nil {
+			addIssue(valuesFile, 0, fmt.Sprintf("Failed to read the Helm values file: %v", err))
+			continue
+		}
+		var values map[string]any
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			addIssue(valuesFile, parseYAMLErrorLine(err), err.Error())
+		}
+	}
+
+	// Check the runtime options files, against the schema if the SDK includes it. The directory
+	// is watched too, so that added and removed options files are noticed.
+	result.WatchedFiles = append(result.WatchedFiles, filepath.Join(project.GetServerDir(), "Config"))
+	optionsFiles, err := resolveRuntimeOptionsFiles(project, nil)
+	if err != nil {
+		log.Debug().Msgf("No runtime options files to validate: %v", err)
+		optionsFiles = nil
+	}
+	schemaPath := filepath.Join(project.GetSdkRootDir(), metaproj.RuntimeOptionsSchemaSdkPath)
+	var schema *metaproj.RuntimeOptionsSchema
+	if schemaContent, err := os.ReadFile(schemaPath); err == nil {
+		result.WatchedFiles = append(result.WatchedFiles, schemaPath)
+		schema, err = metaproj.ParseRuntimeOptionsSchema(schemaContent)
+		if err != nil {
+			addIssue(schemaPath, 0, fmt.Sprintf("Invalid runtime options schema: %v", err))
+		}
+	}
+	for _, optionsFile := range optionsFiles {
+		result.WatchedFiles = append(result.WatchedFiles, optionsFile)
+		result.NumFiles++
+		content, err := os.ReadFile(optionsFile)
+		if err != nil {
+			addIssue(optionsFile, 0, fmt.Sprintf("Failed to read the runtime options file: %v", err))
+			continue
+		}
+
+		if schema == nil {
+			var options map[string]any
+			if err := yaml.Unmarshal(content, &options); err != nil {
+				addIssue(optionsFile, parseYAMLErrorLine(err), err.Error())
+			}
+			continue
+		}
+
+		issues, err := metaproj.ValidateRuntimeOptions(schema, content)
+		if err != nil {
+			addIssue(optionsFile, parseYAMLErrorLine(err), err.Error())
+			continue
+		}
+		for _, issue := range issues {
+			message := fmt.Sprintf("%s: %s", issue.Path, issue.Message)
+			if issue.Suggestion != "" {
+				message += fmt.Sprintf(" (%s)", issue.Suggestion)
+			}
+			addIssue(optionsFile, issue.Line, message)
+		}
+	}
+
+	return result
+}
+
+// printProjectValidationResult prints the issues with the lines of the files around them.
+func printProjectValidationResult(result projectValidationResult) {
+	fileContents := map[string][]string{}
+	for _, issue := range result.Issues {
+		displayPath := filepath.ToSlash(issue.FilePath)
+		if issue.Line > 0 {
+			displayPath = fmt.Sprintf("%s:%d", displayPath, issue.Line)
+		}
+		log.Info().Msgf("%s %s %s", styles.RenderError("✗"), styles.RenderMuted(displayPath+":"), issue.Message)
+
+		if issue.Line > 0 {
+			lines, ok := fileContents[issue.FilePath]
+			if !ok {
+				content, _ := os.ReadFile(issue.FilePath)
+				lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+				fileContents[issue.FilePath] = lines
+			}
+			for _, line := range renderFileLineContext(lines, issue.Line, 1) {
+				log.Info().Msg(line)
+			}
+		}
+	}
+
+	if len(result.Issues) == 0 {
+		log.Info().Msgf("✅ All %d project file(s) are valid", result.NumFiles)
+	} else {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderError(fmt.Sprintf("Found %d issue(s) in %d project file(s)", len(result.Issues), result.NumFiles)))
+	}
+	log.Info().Msg("")
+}
+
+// renderFileLineContext renders the given line of the file with numContext lines around it,
+// marking the line itself, eg, '  > 12 | key: value'.
+func renderFileLineContext(lines []string, lineNumber int, numContext int) []string {
+	if lineNumber < 1 || lineNumber > len(lines) {
+		return nil
+	}
+
+	firstLine := max(1, lineNumber-numContext)
+	lastLine := min(len(lines), lineNumber+numContext)
+	width := len(strconv.Itoa(lastLine))
+	result := []string{}
+	for ndx := firstLine; ndx <= lastLine; ndx++ {
+		text := strings.TrimRight(lines[ndx-1], "\r")
+		if ndx == lineNumber {
+			result = append(result, fmt.Sprintf("  > %*d | %s", width, ndx, text))
+		} else {
+			result = append(result, styles.RenderMuted(fmt.Sprintf("    %*d | %s", width, ndx, text)))
+		}
+	}
+	return result
+}
+
+// parseYAMLErrorLine returns the line number in a YAML parse error, or 0 if there is none.
+func parseYAMLErrorLine(err error) int {
+	match := yamlErrorLineRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+// findYAMLKeyLine returns the line of the first key in the YAML content that is mentioned in the
+// message in quotes, eg, 'serverValuesFile' in a validation error. Returns 0 if none is found.
+func findYAMLKeyLine(content []byte, message string) int {
+	quotedRegex := regexp.MustCompile(`'([A-Za-z][A-Za-z0-9]*)'`)
+	lines := strings.Split(string(content), "\n")
+	for _, match := range quotedRegex.FindAllStringSubmatch(message, -1) {
+		keyRegex := regexp.MustCompile(`^\s*(?:-\s+)?` + regexp.QuoteMeta(match[1]) + `\s*:`)
+		for ndx, line := range lines {
+			if keyRegex.MatchString(line) {
+				return ndx + 1
+			}
+		}
+	}
+	return 0
+}
+
+// waitForFileChange polls the files until any of them is created, modified, or removed, and
+// returns the path of the changed file. Returns the context's error when it is canceled.
+func waitForFileChange(ctx context.Context, filePaths []string, interval time.Duration) (string, error) {
+	initial := map[string]fileStamp{}
+	for _, filePath := range filePaths {
+		initial[filePath] = getFileStamp(filePath)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+			for _, filePath := range filePaths {
+				if getFileStamp(filePath) != initial[filePath] {
+					return filePath, nil
+				}
+			}
+		}
+	}
+}
+
+// getFileStamp returns the current stamp of the file.
+func getFileStamp(filePath string) fileStamp {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseYAMLErrorLine(t *testing.T) {
+	assert.Equal(t, 12, parseYAMLErrorLine(errors.New("yaml: line 12: mapping values are not allowed in this context")))
+	assert.Equal(t, 3, parseYAMLErrorLine(errors.New("yaml: unmarshal errors:\n  line 3: cannot unmarshal !!str `abc` into int")))
+	assert.Equal(t, 0, parseYAMLErrorLine(errors.New("yaml: did not find expected key")))
+}
+
+func TestFindYAMLKeyLine(t *testing.T) {
+	content := []byte("projectID: test\nenvironments:\n  - humanId: dev\n    serverValuesFile: missing.yaml\n")
+	assert.Equal(t, 4, findYAMLKeyLine(content, "Helm values file 'serverValuesFile' not found"))
+	assert.Equal(t, 3, findYAMLKeyLine(content, "Invalid 'humanId' in environment"))
+	assert.Equal(t, 0, findYAMLKeyLine(content, "Something is wrong"))
+	assert.Equal(t, 0, findYAMLKeyLine(content, "Unknown 'otherKey'"))
+}
+
+func TestRenderFileLineContext(t *testing.T) {
+	lines := []string{"a: 1", "b: 2", "c: 3"}
+
+	rendered := renderFileLineContext(lines, 1, 1)
+	require.Len(t, rendered, 2)
+	assert.Equal(t, "  > 1 | a: 1", rendered[0])
+	assert.Contains(t, rendered[1], "2 | b: 2")
+
+	rendered = renderFileLineContext(lines, 2, 1)
+	require.Len(t, rendered, 3)
+	assert.Equal(t, "  > 2 | b: 2", rendered[1])
+
+	assert.Nil(t, renderFileLineContext(lines, 0, 1))
+	assert.Nil(t, renderFileLineContext(lines, 4, 1))
+}
+
+func TestValidateProjectFiles_InvalidYAML(t *testing.T) {
+	projectDir := t.TempDir()
+	configPath := filepath.Join(projectDir, metaproj.ConfigFileName)
+	require.NoError(t, os.WriteFile(configPath, []byte("projectID: test\nbuildDir: [\n"), 0644))
+
+	result := validateProjectFiles(projectDir)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, configPath, result.Issues[0].FilePath)
+	assert.Equal(t, 2, result.Issues[0].Line)
+	assert.Equal(t, []string{configPath}, result.WatchedFiles)
+}
+
+func TestValidateProjectFiles_MissingConfig(t *testing.T) {
+	result := validateProjectFiles(t.TempDir())
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, 0, result.Issues[0].Line)
+	assert.Contains(t, result.Issues[0].Message, "Failed to read the project config")
+}
+
+func TestWaitForFileChange(t *testing.T) {
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, "existing.yaml")
+	missingPath := filepath.Join(dir, "missing.yaml")
+	require.NoError(t, os.WriteFile(existingPath, []byte("a: 1\n"), 0644))
+
+	// Creating a missing file is a change.
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		time.Sleep(30 * time.Millisecond)
+		_ = os.WriteFile(missingPath, []byte("b: 2\n"), 0644)
+	}()
+	changed, err := waitForFileChange(context.Background(), []string{existingPath, missingPath}, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, missingPath, changed)
+
+	// Don't let the completion of the write above show up as a change below.
+	<-written
+
+	// Canceling the context stops waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = waitForFileChange(ctx, []string{existingPath, missingPath}, 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// Validate the game server runtime options files against the SDK's option schema.
+type validateRuntimeOptionsOpts struct {
+	UsePositionalArgs
+
+	flagFiles  []string
+	flagSchema string
+	flagImage  string
+}
+
+func init() {
+	o := validateRuntimeOptionsOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "runtime-options [flags]",
+		Short: "Validate the game server runtime options files",
+		Run:   runCommand(&o),
+		Long: renderLong(&o, `
+			Validate the game server runtime options files against the option schema of the
+			Metaplay SDK.
+
+			Catches mistyped option names and values of the wrong type before they are deployed.
+			The game server only logs unknown options as warnings on startup, so the mistakes
+			are otherwise easy to miss.
+
+			By default, all the Config/Options.*.yaml files in the game server project are
+			validated, including Options.base.yaml and the per-environment options files. Use
+			--file to validate specific files instead.
+
+			The option schema is read from the SDK (MetaplaySDK/Backend/Server/RuntimeOptionsSchema.json)
+			by default. Use --image to read the schema from a locally built game server image
+			instead, or --schema to use a specific schema file.
+
+			{Arguments}
+
+			Related commands:
+			- 'metaplay dev server' runs the game server locally with the runtime options files.
+			- 'metaplay deploy server ...' deploys the game server with the runtime options files.
+		`),
+		Example: renderExample(`
+			# Validate all the runtime options files of the project.
+			metaplay validate runtime-options
+
+			# Validate only the production options file.
+			metaplay validate runtime-options --file Backend/Server/Config/Options.production.yaml
+
+			# Validate against the schema in a locally built server image.
+			metaplay validate runtime-options --image mygame:12345678
+		`),
+	}
+	validateCmd.AddCommand(cmd)
+
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&o.flagFiles, "file", "f", nil, "Runtime options file to validate, can be repeated (default: Config/Options.*.yaml in the game server project)")
+	flags.StringVar(&o.flagSchema, "schema", "", "Path to the runtime options schema file (default: from the SDK)")
+	flags.StringVar(&o.flagImage, "image", "", "Local game server docker image to read the runtime options schema from")
+}
+
+func (o *validateRuntimeOptionsOpts) Prepare(cmd *cobra.Command, args []string) error {
+	if o.flagSchema != "" && o.flagImage != "" {
+		return clierrors.NewUsageError("Only one of --schema and --image can be specified")
+	}
+	return nil
+}
+
+func (o *validateRuntimeOptionsOpts) Run(cmd *cobra.Command) error {
+	// Load project config.
+	project, err := resolveProject()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("")
+	log.Info().Msg(styles.RenderTitle("Validate Runtime Options"))
+	log.Info().Msg("")
+
+	// Load the option schema.
+	schema, schemaSource, err := o.loadSchema(cmd, project)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Schema: %s", styles.RenderTechnical(schemaSource))
+	log.Info().Msg("")
+
+	// Resolve files to validate.
+	files, err := resolveRuntimeOptionsFiles(project, o.flagFiles)
+	if err != nil {
+		return err
+	}
+
+	// Validate each file.
+	numIssues := 0
+	for _, filePath := range files {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to read runtime options file %s", filePath)
+		}
+
+		issues, err := metaproj.ValidateRuntimeOptions(schema, content)
+		if err != nil {
+			return clierrors.Wrapf(err, "Failed to parse runtime options file %s", filePath).
+				WithSuggestion("Check that the file is valid YAML")
+		}
+
+		displayPath := filepath.ToSlash(filePath)
+		if len(issues) == 0 {
+			log.Info().Msgf("%s %s", styles.RenderSuccess("✓"), displayPath)
+			continue
+		}
+
+		log.Info().Msgf("%s %s", styles.RenderError("✗"), displayPath)
+		for _, issue := range issues {
+			log.Info().Msg(formatRuntimeOptionsIssue(displayPath, issue))
+		}
+		numIssues += len(issues)
+	}
+
+	log.Info().Msg("")
+	if numIssues > 0 {
+		return clierrors.Newf("Found %d issue(s) in the runtime options files", numIssues).
+			WithSuggestion("Fix the option names and values listed above")
+	}
+
+	log.Info().Msgf("✅ All %d runtime options file(s) are valid", len(files))
+	return nil
+}
+
+// loadSchema loads the runtime options schema from the file given with --schema, the docker
+// image given with --image, or the SDK. Returns the schema and a description of its source.
+func (o *validateRuntimeOptionsOpts) loadSchema(cmd *cobra.Command, project *metaproj.MetaplayProject) (*metaproj.RuntimeOptionsSchema, string, error) {
+	var content []byte
+	var source string
+	var err error
+	if o.flagImage != "" {
+		source = fmt.Sprintf("%s:%s", o.flagImage, metaproj.RuntimeOptionsSchemaImagePath)
+		content, err = envapi.ReadLocalDockerImageFile(cmd.Context(), o.flagImage, metaproj.RuntimeOptionsSchemaImagePath)
+		if err != nil {
+			return nil, "", clierrors.Wrapf(err, "Failed to read the runtime options schema from image %s", o.flagImage).
+				WithSuggestion("Build the image with 'metaplay build image', or check that its SDK version includes the runtime options schema")
+		}
+	} else {
+		source = o.flagSchema
+		if source == "" {
+			source = filepath.Join(project.GetSdkRootDir(), metaproj.RuntimeOptionsSchemaSdkPath)
+		}
+		content, err = os.ReadFile(source)
+		if err != nil {
+			return nil, "", clierrors.Wrapf(err, "Failed to read the runtime options schema %s", source).
+				WithSuggestion("Check that your SDK version includes the runtime options schema, or read it from a server image with --image")
+		}
+	}
+
+	schema, err := metaproj.ParseRuntimeOptionsSchema(content)
+	if err != nil {
+		return nil, "", clierrors.Wrap(err, "Invalid runtime options schema")
+	}
+	return schema, filepath.ToSlash(source), nil
+}
+
+// resolveRuntimeOptionsFiles returns the runtime options files to validate: the explicitly
+// specified files, or all the Config/Options.*.yaml files in the game server project.
+func resolveRuntimeOptionsFiles(project *metaproj.MetaplayProject, flagFiles []string) ([]string, error) {
+	if len(flagFiles) > 0 {
+		return flagFiles, nil
+	}
+
+	configDir := filepath.Join(project.GetServerDir(), "Config")
+	files, err := filepath.Glob(filepath.Join(configDir, "Options.*.yaml"))
+	if err != nil {
+		return nil, clierrors.Wrapf(err, "Failed to list runtime options files in %s", configDir)
+	}
+	if len(files) == 0 {
+		return nil, clierrors.Newf("No runtime options files found in %s", filepath.ToSlash(configDir)).
+			WithSuggestion("Specify the files to validate with --file")
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// formatRuntimeOptionsIssue formats the issue as an indented 'file:line: path: message' line.
+func formatRuntimeOptionsIssue(filePath string, issue metaproj.RuntimeOptionsIssue) string {
+	line := fmt.Sprintf("  %s %s: %s", styles.RenderMuted(fmt.Sprintf("%s:%d:", filePath, issue.Line)), styles.RenderTechnical(issue.Path), issue.Message)
+	if issue.Suggestion != "" {
+		line += styles.RenderMuted(fmt.Sprintf(" (%s)", issue.Suggestion))
+	}
+	return line
+}
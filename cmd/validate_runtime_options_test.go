@@ -0,0 +1,41 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/metaproj"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRuntimeOptionsFiles(t *testing.T) {
+	projectDir := t.TempDir()
+	project := &metaproj.MetaplayProject{RelativeDir: projectDir}
+	project.Config.BackendDir = "Backend"
+
+	// No files found.
+	_, err := resolveRuntimeOptionsFiles(project, nil)
+	assert.Error(t, err)
+
+	configDir := filepath.Join(projectDir, "Backend", "Server", "Config")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	for _, name := range []string{"Options.production.yaml", "Options.base.yaml", "Other.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, name), nil, 0644))
+	}
+
+	// All Options.*.yaml files are used by default.
+	files, err := resolveRuntimeOptionsFiles(project, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(configDir, "Options.base.yaml"), filepath.Join(configDir, "Options.production.yaml")}, files)
+
+	// Explicitly specified files override the defaults.
+	files, err = resolveRuntimeOptionsFiles(project, []string{"custom.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"custom.yaml"}, files)
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/goccy/go-yaml"
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// recordedAnswer is an answer to a single interactive question. Exactly one of the value fields
+// is set, depending on the kind of the question.
+type recordedAnswer struct {
+	Question string   `yaml:"question"`
+	Confirm  *bool    `yaml:"confirm,omitempty"` // Answer to a yes/no question
+	Choice   string   `yaml:"choice,omitempty"`  // Name of the item chosen from a list
+	Choices  []string `yaml:"choices,omitempty"` // Names of the items chosen from a multi-select list
+
+	isUsed bool // Has the answer been replayed already?
+}
+
+// answersFile is the file that the answers are recorded into and replayed from.
+type answersFile struct {
+	Answers []*recordedAnswer `yaml:"answers"`
+}
+
+var (
+	answersRecordPath string       // Path to record the answers into, if recording
+	answersRecorded   answersFile  // Answers recorded so far
+	answersReplayPath string       // Path of the replayed answers file, if replaying
+	answersReplay     *answersFile // Answers to replay, if replaying
+)
+
+// StartRecordingAnswers records the answers to all the interactive questions into the file,
+// which can later be replayed with ReplayAnswers(). The file is rewritten after each answer,
+// so the answers are kept even if the command fails later on.
+func StartRecordingAnswers(path string) error {
+	answersRecordPath = path
+	answersRecorded = answersFile{Answers: []*recordedAnswer{}}
+	return writeRecordedAnswers()
+}
+
+// ReplayAnswers loads the answers recorded with StartRecordingAnswers() and uses them to answer
+// the interactive questions instead of asking the user. The questions are matched by their
+// text, in the recorded order. The answers are also used in non-interactive mode.
+func ReplayAnswers(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	var answers answersFile
+	if err := yaml.Unmarshal(content, &answers); err != nil {
+		return fmt.Errorf("failed to parse answers file %s: %w", path, err)
+	}
+	for ndx, answer := range answers.Answers {
+		if answer == nil || answer.Question == "" {
+			return fmt.Errorf("answer #%d in %s has no question", ndx+1, path)
+		}
+	}
+
+	answersReplayPath = path
+	answersReplay = &answers
+	return nil
+}
+
+// IsReplayingAnswers returns true if the interactive questions are answered from a file.
+func IsReplayingAnswers() bool {
+	return answersReplay != nil
+}
+
+// CanAskQuestions returns true if interactive questions can be answered, either by the user in
+// interactive mode or from a replayed answers file.
+func CanAskQuestions() bool {
+	return isInteractiveMode || IsReplayingAnswers()
+}
+
+// findReplayedAnswer returns the first unused replayed answer to the question. If there is no
+// answer in interactive mode, nil is returned and the user is asked instead. In non-interactive
+// mode, a missing answer is an error.
+func findReplayedAnswer(question string) (*recordedAnswer, error) {
+	if answersReplay == nil {
+		return nil, nil
+	}
+
+	question = normalizeAnswerText(question)
+	for _, answer := range answersReplay.Answers {
+		if !answer.isUsed && answer.Question == question {
+			answer.isUsed = true
+			return answer, nil
+		}
+	}
+
+	if isInteractiveMode {
+		log.Debug().Msgf("No answer to '%s' in %s, asking the user", question, answersReplayPath)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("no answer to '%s' in answers file %s; record the answers again with --record-answers", question, answersReplayPath)
+}
+
+// logReplayedAnswer shows the replayed answer to the user in place of the dialog.
+func logReplayedAnswer(question string, answer string) {
+	log.Info().Msgf("%s %s %s", normalizeAnswerText(question), styles.RenderTechnical(answer), styles.RenderMuted("(from "+answersReplayPath+")"))
+}
+
+// recordAnswer records the answer, if recording. Failing to write the file is only logged, so
+// that the command itself is not interrupted.
+func recordAnswer(answer recordedAnswer) {
+	if answersRecordPath == "" {
+		return
+	}
+
+	answer.Question = normalizeAnswerText(answer.Question)
+	answersRecorded.Answers = append(answersRecorded.Answers, &answer)
+	if err := writeRecordedAnswers(); err != nil {
+		log.Warn().Msgf("Failed to write answers file %s: %v", answersRecordPath, err)
+	}
+}
+
+// writeRecordedAnswers writes the answers recorded so far into the answers file.
+func writeRecordedAnswers() error {
+	content, err := yaml.Marshal(answersRecorded)
+	if err != nil {
+		return fmt.Errorf("failed to serialize answers: %w", err)
+	}
+	header := "# Answers to the interactive questions of the Metaplay CLI.\n# Replay them with: metaplay <command> --answers " + answersRecordPath + "\n"
+	return os.WriteFile(answersRecordPath, append([]byte(header), content...), 0644)
+}
+
+// normalizeAnswerText removes the styling and surrounding whitespace from a question or an item
+// name, so that they can be compared across runs.
+func normalizeAnswerText(text string) string {
+	return strings.TrimSpace(ansi.Strip(text))
+}
+
+// replayConfirm returns the replayed answer to a yes/no question, if any.
+func replayConfirm(question string) (answer bool, found bool, err error) {
+	replayed, err := findReplayedAnswer(question)
+	if err != nil || replayed == nil {
+		return false, false, err
+	}
+	if replayed.Confirm == nil {
+		return false, false, fmt.Errorf("answer to '%s' in %s is not a yes/no answer", replayed.Question, answersReplayPath)
+	}
+
+	answerStr := "no"
+	if *replayed.Confirm {
+		answerStr = "yes"
+	}
+	logReplayedAnswer(question, answerStr)
+	return *replayed.Confirm, true, nil
+}
+
+// recordConfirm records the answer to a yes/no question.
+func recordConfirm(question string, answer bool) {
+	recordAnswer(recordedAnswer{Question: question, Confirm: &answer})
+}
+
+// replayChoice returns the index of the replayed choice among the item names, or -1 if there
+// is no replayed answer.
+func replayChoice(question string, names []string) (int, error) {
+	replayed, err := findReplayedAnswer(question)
+	if err != nil || replayed == nil {
+		return -1, err
+	}
+
+	for ndx, name := range names {
+		if normalizeAnswerText(name) == replayed.Choice {
+			logReplayedAnswer(question, replayed.Choice)
+			return ndx, nil
+		}
+	}
+	return -1, fmt.Errorf("answer '%s' to '%s' in %s is not one of the available choices", replayed.Choice, replayed.Question, answersReplayPath)
+}
+
+// recordChoice records the name of the item chosen from a list.
+func recordChoice(question string, name string) {
+	recordAnswer(recordedAnswer{Question: question, Choice: normalizeAnswerText(name)})
+}
+
+// replayChoices returns the indexes of the replayed choices among the item names, or nil if
+// there is no replayed answer.
+func replayChoices(question string, names []string) ([]int, error) {
+	replayed, err := findReplayedAnswer(question)
+	if err != nil || replayed == nil {
+		return nil, err
+	}
+
+	indexes := []int{}
+	for _, choice := range replayed.Choices {
+		found := false
+		for ndx, name := range names {
+			if normalizeAnswerText(name) == choice {
+				indexes = append(indexes, ndx)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("answer '%s' to '%s' in %s is not one of the available choices", choice, replayed.Question, answersReplayPath)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("answer to '%s' in %s has no choices", replayed.Question, answersReplayPath)
+	}
+
+	logReplayedAnswer(question, strings.Join(replayed.Choices, ", "))
+	return indexes, nil
+}
+
+// recordChoices records the names of the items chosen from a multi-select list.
+func recordChoices(question string, names []string) {
+	normalized := make([]string, len(names))
+	for ndx, name := range names {
+		normalized[ndx] = normalizeAnswerText(name)
+	}
+	recordAnswer(recordedAnswer{Question: question, Choices: normalized})
+}
+
+// chooseWithAnswers returns the replayed choice to the question, if any. Otherwise, the user
+// chooses with chooseFunc, and the choice is recorded.
+func chooseWithAnswers(question string, names []string, chooseFunc func() (int, error)) (int, error) {
+	if chosen, err := replayChoice(question, names); err != nil || chosen >= 0 {
+		return chosen, err
+	}
+
+	chosen, err := chooseFunc()
+	if err != nil {
+		return -1, err
+	}
+	recordChoice(question, names[chosen])
+	return chosen, nil
+}
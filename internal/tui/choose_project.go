@@ -16,7 +16,7 @@ import (
 // access to) and then displays an interactive list for the user to choose the project from.
 func ChooseOrgAndProject(orgsAndProjects []portalapi.OrganizationWithProjects) (*portalapi.ProjectInfo, error) {
 	// Must be in interactive mode.
-	if !isInteractiveMode {
+	if !CanAskQuestions() {
 		return nil, fmt.Errorf("interactive mode required for project selection")
 	}
 
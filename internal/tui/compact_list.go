@@ -30,7 +30,7 @@ func (item compactListItem) Title() string {
 	return fmt.Sprintf("%s %s", item.name, styles.RenderMuted(item.description))
 }
 
-func (item compactListItem) FilterValue() string { return item.description }
+func (item compactListItem) FilterValue() string { return item.name + " " + item.description }
 
 // compactListDelegate implements a compact list delegate for the project selection.
 type compactListDelegate struct{}
@@ -61,6 +61,7 @@ type compactListModel struct {
 	title    string
 	subtitle string
 	model    list.Model
+	filter   *listFilter
 	selected *compactListItem
 	quitting bool
 	err      error
@@ -68,8 +69,9 @@ type compactListModel struct {
 
 func newCompactListModel(title string, model list.Model) compactListModel {
 	return compactListModel{
-		title: title,
-		model: model,
+		title:  title,
+		model:  model,
+		filter: newListFilter(model.Items()),
 	}
 }
 
@@ -84,15 +86,26 @@ func (m compactListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case tea.KeyPressMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "esc":
+			// Clear the filter first, cancel if there is no filter.
+			if !m.filter.clear(&m.model) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
 		case "enter":
 			if item, ok := m.model.SelectedItem().(compactListItem); ok {
 				m.selected = &item
 				m.quitting = true
 				return m, tea.Quit
 			}
+			return m, nil
+		}
+		if m.filter.handleKey(msg, &m.model) {
+			return m, nil
 		}
 	}
 
@@ -109,6 +122,9 @@ func (m compactListModel) View() tea.View {
 			content += "  " + styles.RenderMuted(m.subtitle) + "\n"
 		}
 		content += styles.ListStyle.Render(m.model.View())
+		content = strings.TrimRight(content, " \t\n") + "\n\n"
+		content += m.filter.renderStatus(m.model) + "\n"
+		content += m.filter.renderHelp(m.model, "enter to select")
 	}
 
 	return tea.NewView(content)
@@ -148,6 +164,7 @@ type multiSelectModel struct {
 	title    string
 	footer   string
 	model    list.Model
+	filter   *listFilter
 	checked  map[int]bool
 	done     bool
 	quitting bool
@@ -159,6 +176,7 @@ func newMultiSelectModel(title string, footer string, model list.Model, checked
 		title:   title,
 		footer:  footer,
 		model:   model,
+		filter:  newListFilter(model.Items()),
 		checked: checked,
 	}
 }
@@ -177,6 +195,13 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
+		case "esc":
+			// Clear the filter first, cancel if there is no filter.
+			if !m.filter.clear(&m.model) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
 		case "space":
 			// Toggle selection of the current item. (bubbletea v2 reports
 			// space as "space" rather than " ".)
@@ -193,6 +218,9 @@ func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.quitting = true
 			return m, tea.Quit
 		}
+		if m.filter.handleKey(msg, &m.model) {
+			return m, nil
+		}
 	}
 
 	var cmd tea.Cmd
@@ -205,14 +233,15 @@ func (m multiSelectModel) View() tea.View {
 
 	if !m.quitting {
 		content += styles.ListStyle.Render(m.model.View())
+		// The list output trails with whitespace and no final newline,
+		// so trim and control spacing explicitly: one blank line above
+		// the footer, footer line itself, then the filter status and help lines.
+		content = strings.TrimRight(content, " \t\n") + "\n\n"
 		if m.footer != "" {
-			// The list output trails with whitespace and no final newline,
-			// so trim and control spacing explicitly: one blank line above
-			// the footer, footer line itself, then the help line.
-			content = strings.TrimRight(content, " \t\n") + "\n\n"
 			content += styles.RenderMuted("  "+m.footer) + "\n"
 		}
-		content += styles.RenderMuted("  space to toggle, enter to confirm")
+		content += m.filter.renderStatus(m.model) + "\n"
+		content += m.filter.renderHelp(m.model, "space to toggle, enter to confirm")
 	}
 
 	return tea.NewView(content)
@@ -224,11 +253,7 @@ func chooseFromList(title string, items []list.Item) (int, error) {
 
 func chooseFromListWithSubtitle(title string, subtitle string, items []list.Item) (int, error) {
 	// Initialize list with custom delegate
-	list := list.New(items, compactListDelegate{}, 0, min(2+len(items), 20))
-	list.SetShowTitle(false)
-	list.SetFilteringEnabled(false)
-	list.SetShowStatusBar(false)
-	list.SetShowHelp(false)
+	list := newListDialogModel(items, compactListDelegate{}, 1)
 
 	// Create and run model
 	model := newCompactListModel(title, list)
@@ -251,9 +276,21 @@ func chooseFromListWithSubtitle(title string, subtitle string, items []list.Item
 	return selectedItem.index, nil
 }
 
+// compactListItemNames returns the names of the list items, used to identify the items in the
+// answers file.
+func compactListItemNames(listItems []list.Item) []string {
+	names := make([]string, len(listItems))
+	for ndx, listItem := range listItems {
+		names[ndx] = listItem.(compactListItem).name
+	}
+	return names
+}
+
 // Show a dialog to user to select an item from the provided list.
 // The toItemFunc() is used to convert the items into a (name, description)
 // tuple for display. The selected item in the list is returned (or error).
+// Typing filters the list, so all printable keys (including 'q') go to the
+// filter: Esc clears the filter or cancels the dialog, Ctrl+C always cancels.
 func ChooseFromListDialog[TItem any](title string, items []TItem, toItemFunc func(item *TItem) (string, string)) (*TItem, error) {
 	// \todo Bit of a hack to render title first
 	if len(items) == 0 {
@@ -275,8 +312,10 @@ func ChooseFromListDialog[TItem any](title string, items []TItem, toItemFunc fun
 		}
 	}
 
-	// Let the user choose list items.
-	chosen, err := chooseFromList(title, listItems)
+	// Let the user choose list items (or replay the recorded choice).
+	chosen, err := chooseWithAnswers(title, compactListItemNames(listItems), func() (int, error) {
+		return chooseFromList(title, listItems)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -305,8 +344,72 @@ func ChooseFromListDialogWithHeader[TItem any](title string, header string, item
 		}
 	}
 
-	// Let the user choose list items.
-	chosen, err := chooseFromListWithSubtitle(title, header, listItems)
+	// Let the user choose list items (or replay the recorded choice).
+	chosen, err := chooseWithAnswers(title, compactListItemNames(listItems), func() (int, error) {
+		return chooseFromListWithSubtitle(title, header, listItems)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &items[chosen], nil
+}
+
+// ChooseFromTableDialog is like ChooseFromListDialog but renders the items in aligned columns
+// under a header row. The toRowFunc() returns the cells of an item, one for each column. The
+// filtering matches against all the columns.
+func ChooseFromTableDialog[TItem any](title string, columns []string, items []TItem, toRowFunc func(item *TItem) []string) (*TItem, error) {
+	if len(items) == 0 {
+		log.Info().Msg("")
+		log.Info().Msg(styles.RenderTitle(title))
+		log.Info().Msg("")
+		return nil, fmt.Errorf("ChooseFromTableDialog(): an empty list was provided")
+	}
+
+	// Resolve the cells and the column widths.
+	rows := make([][]string, len(items))
+	widths := make([]int, len(columns))
+	for col, name := range columns {
+		widths[col] = lipgloss.Width(name)
+	}
+	for ndx := range items {
+		rows[ndx] = toRowFunc(&items[ndx])
+		for col, cell := range rows[ndx] {
+			if col < len(widths) {
+				widths[col] = max(widths[col], lipgloss.Width(cell))
+			}
+		}
+	}
+
+	// The first column is the item name, the rest are rendered (muted) as the description.
+	// Note: the header's first gap is 1 space to match the space between name and description.
+	formatRow := func(cells []string) (string, string) {
+		padded := make([]string, len(columns))
+		for col := range columns {
+			cell := ""
+			if col < len(cells) {
+				cell = cells[col]
+			}
+			padded[col] = cell + strings.Repeat(" ", widths[col]-lipgloss.Width(cell))
+		}
+		return padded[0], strings.Join(padded[1:], "  ")
+	}
+	headerName, headerDescription := formatRow(columns)
+
+	listItems := make([]list.Item, len(items))
+	for ndx := range items {
+		name, description := formatRow(rows[ndx])
+		listItems[ndx] = compactListItem{
+			index:       ndx,
+			name:        name,
+			description: description,
+		}
+	}
+
+	// Let the user choose list items (or replay the recorded choice).
+	chosen, err := chooseWithAnswers(title, compactListItemNames(listItems), func() (int, error) {
+		return chooseFromListWithSubtitle(title, headerName+" "+headerDescription, listItems)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +455,18 @@ func ChooseMultipleFromListDialogWithDefaults[TItem any](
 		}
 	}
 
+	// Replay the recorded choices, if any.
+	names := compactListItemNames(listItems)
+	if chosen, err := replayChoices(title, names); err != nil {
+		return nil, err
+	} else if chosen != nil {
+		selected := make([]TItem, len(chosen))
+		for ndx, itemNdx := range chosen {
+			selected[ndx] = items[itemNdx]
+		}
+		return selected, nil
+	}
+
 	// Initialise the checked map per the predicate.
 	checked := make(map[int]bool, len(items))
 	for ndx := range items {
@@ -360,11 +475,7 @@ func ChooseMultipleFromListDialogWithDefaults[TItem any](
 		}
 	}
 	delegate := &multiSelectDelegate{checked: checked}
-	l := list.New(listItems, delegate, 0, min(2+len(listItems), 20))
-	l.SetShowTitle(false)
-	l.SetFilteringEnabled(false)
-	l.SetShowStatusBar(false)
-	l.SetShowHelp(false)
+	l := newListDialogModel(listItems, delegate, 1)
 
 	// Create and run model.
 	model := newMultiSelectModel(title, footer, l, checked)
@@ -384,15 +495,18 @@ func ChooseMultipleFromListDialogWithDefaults[TItem any](
 
 	// Collect selected items in order.
 	var selected []TItem
+	var selectedNames []string
 	for ndx := range items {
 		if finalM.checked[ndx] {
 			selected = append(selected, items[ndx])
+			selectedNames = append(selectedNames, names[ndx])
 		}
 	}
 
 	if len(selected) == 0 {
 		return nil, fmt.Errorf("no items selected")
 	}
+	recordChoices(title, selectedNames)
 
 	return selected, nil
 }
@@ -506,20 +620,31 @@ func ChooseFromListDialogMultiline[TItem any](
 	l.SetShowStatusBar(false)
 	l.SetShowHelp(false)
 
-	model := newCompactListMultilineModel(title, l)
-	program := tea.NewProgram(model)
-	finalModel, err := program.Run()
+	// Let the user choose the item (or replay the recorded choice).
+	names := make([]string, len(prep))
+	for ndx, p := range prep {
+		names[ndx] = p.name
+	}
+	chosen, err := chooseWithAnswers(title, names, func() (int, error) {
+		model := newCompactListMultilineModel(title, l)
+		program := tea.NewProgram(model)
+		finalModel, err := program.Run()
+		if err != nil {
+			return -1, fmt.Errorf("failed to run selection dialog: %w", err)
+		}
+		finalM := finalModel.(compactListMultilineModel)
+		if finalM.err != nil {
+			return -1, finalM.err
+		}
+		if finalM.selectedIndex < 0 {
+			return -1, fmt.Errorf("selection canceled")
+		}
+		return finalM.selectedIndex, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to run selection dialog: %w", err)
-	}
-	finalM := finalModel.(compactListMultilineModel)
-	if finalM.err != nil {
-		return nil, finalM.err
-	}
-	if finalM.selectedIndex < 0 {
-		return nil, fmt.Errorf("selection canceled")
+		return nil, err
 	}
-	return &items[finalM.selectedIndex], nil
+	return &items[chosen], nil
 }
 
 // compactListMultilineModel drives ChooseFromListDialogMultiline. Mirrors
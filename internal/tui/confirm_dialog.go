@@ -69,14 +69,26 @@ func (m confirmDialog) View() tea.View {
 }
 
 // Show the user a confirm dialog and wait for a yes/no answer.
+// The answer is replayed from or recorded into the answers file, if any.
 func DoConfirmDialog(ctx context.Context, title string, body string, question string) (bool, error) {
+	// Identify the question by the title too, as the same question can be asked in many dialogs.
+	answerKey := question
+	if title != "" {
+		answerKey = title + ": " + question
+	}
+	if answer, found, err := replayConfirm(answerKey); err != nil || found {
+		return answer, err
+	}
+
 	p := tea.NewProgram(newConfirmDialog(ctx, title, body, question))
 	m, err := p.Run()
 	if err != nil {
 		return false, fmt.Errorf("failed to run confirmation dialog: %v", err)
 	}
 
-	return m.(confirmDialog).choice, nil
+	choice := m.(confirmDialog).choice
+	recordConfirm(answerKey, choice)
+	return choice, nil
 }
 
 // Show the user a one-line confirm question and wait for a yes/no answer.
@@ -0,0 +1,150 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"github.com/metaplay/cli/pkg/styles"
+)
+
+// Maximum height of the list dialogs (in rows). Longer lists are paged.
+const maxListDialogHeight = 20
+
+// newListDialogKeyMap returns the key bindings for navigating the list dialogs. Only non-printable
+// keys are bound so that all the printable characters can be used for the type-to-filter. This
+// also means that 'q' no longer quits the list dialogs: Esc and Ctrl+C are the exits, which are
+// handled by the dialog models.
+func newListDialogKeyMap() list.KeyMap {
+	disabled := key.NewBinding(key.WithDisabled())
+	return list.KeyMap{
+		CursorUp:             key.NewBinding(key.WithKeys("up", "ctrl+p")),
+		CursorDown:           key.NewBinding(key.WithKeys("down", "ctrl+n")),
+		PrevPage:             key.NewBinding(key.WithKeys("pgup", "left")),
+		NextPage:             key.NewBinding(key.WithKeys("pgdown", "right")),
+		GoToStart:            key.NewBinding(key.WithKeys("home")),
+		GoToEnd:              key.NewBinding(key.WithKeys("end")),
+		Filter:               disabled,
+		ClearFilter:          disabled,
+		CancelWhileFiltering: disabled,
+		AcceptWhileFiltering: disabled,
+		ShowFullHelp:         disabled,
+		CloseFullHelp:        disabled,
+		Quit:                 disabled,
+		ForceQuit:            disabled,
+	}
+}
+
+// newListDialogModel creates a list model for the list dialogs with the given delegate. The
+// list's own filtering is disabled in favor of listFilter, which filters as the user types.
+func newListDialogModel(items []list.Item, delegate list.ItemDelegate, rowsPerItem int) list.Model {
+	l := list.New(items, delegate, 0, min(2+rowsPerItem*len(items), maxListDialogHeight))
+	l.KeyMap = newListDialogKeyMap()
+	l.SetShowTitle(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.SetShowPagination(false)
+	return l
+}
+
+// listFilter implements incremental fuzzy filtering of a list: the items are filtered as the
+// user types, with the best matches first.
+type listFilter struct {
+	allItems []list.Item // All the items in the list, in the original order
+	query    string      // Current filter query (empty for no filtering)
+}
+
+func newListFilter(items []list.Item) *listFilter {
+	return &listFilter{allItems: items}
+}
+
+// handleKey updates the filter query based on the key press. Returns true if the key was
+// consumed by the filter.
+func (f *listFilter) handleKey(msg tea.KeyPressMsg, model *list.Model) bool {
+	switch {
+	case msg.String() == "backspace":
+		if f.query == "" {
+			return false
+		}
+		runes := []rune(f.query)
+		f.query = string(runes[:len(runes)-1])
+	case msg.String() == "ctrl+u":
+		f.query = ""
+	case msg.Text != "" && msg.Mod&(tea.ModCtrl|tea.ModAlt) == 0:
+		f.query += msg.Text
+	default:
+		return false
+	}
+
+	f.apply(model)
+	return true
+}
+
+// clear resets the filter query. Returns false if there was no active filter.
+func (f *listFilter) clear(model *list.Model) bool {
+	if f.query == "" {
+		return false
+	}
+	f.query = ""
+	f.apply(model)
+	return true
+}
+
+// apply updates the visible items of the list model based on the current query.
+func (f *listFilter) apply(model *list.Model) {
+	if f.query == "" {
+		model.SetItems(f.allItems)
+		model.ResetSelected()
+		return
+	}
+
+	targets := make([]string, len(f.allItems))
+	for ndx, item := range f.allItems {
+		targets[ndx] = item.FilterValue()
+	}
+	ranks := list.DefaultFilter(f.query, targets)
+	matches := make([]list.Item, len(ranks))
+	for ndx, rank := range ranks {
+		matches[ndx] = f.allItems[rank.Index]
+	}
+	model.SetItems(matches)
+	model.ResetSelected()
+}
+
+// renderStatus renders the filter query, the number of matches, and the current page.
+func (f *listFilter) renderStatus(model list.Model) string {
+	var parts []string
+	if f.query != "" {
+		parts = append(parts, fmt.Sprintf("filter: %s", styles.RenderTechnical(f.query)))
+		parts = append(parts, styles.RenderMuted(fmt.Sprintf("%d of %d match", len(model.Items()), len(f.allItems))))
+	} else {
+		parts = append(parts, styles.RenderMuted("type to filter"))
+	}
+	if model.Paginator.TotalPages > 1 {
+		parts = append(parts, styles.RenderMuted(fmt.Sprintf("page %d/%d", model.Paginator.Page+1, model.Paginator.TotalPages)))
+	}
+	return "  " + strings.Join(parts, styles.RenderMuted(" · "))
+}
+
+// renderHelp renders the keyboard shortcuts of the list dialogs. The selectHelp describes the
+// dialog-specific selection keys, eg, 'enter to select'.
+func (f *listFilter) renderHelp(model list.Model, selectHelp string) string {
+	shortcuts := []string{"↑/↓ to move"}
+	if model.Paginator.TotalPages > 1 {
+		shortcuts = append(shortcuts, "pgup/pgdn to page", "home/end to jump")
+	}
+	shortcuts = append(shortcuts, selectHelp)
+	if f.query != "" {
+		shortcuts = append(shortcuts, "esc to clear filter")
+	} else {
+		shortcuts = append(shortcuts, "esc to cancel")
+	}
+	return styles.RenderMuted("  " + strings.Join(shortcuts, ", "))
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+)
+
+func newTestListItems(names ...string) []list.Item {
+	items := make([]list.Item, len(names))
+	for ndx, name := range names {
+		items[ndx] = compactListItem{index: ndx, name: name, description: "env-" + name}
+	}
+	return items
+}
+
+func typeText(text string) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: []rune(text)[0], Text: text}
+}
+
+func visibleNames(model list.Model) []string {
+	return compactListItemNames(model.Items())
+}
+
+func TestListFilterTyping(t *testing.T) {
+	items := newTestListItems("production", "staging", "develop", "qa-test")
+	model := newListDialogModel(items, compactListDelegate{}, 1)
+	filter := newListFilter(items)
+
+	// Typing filters the items incrementally.
+	for _, ch := range []string{"s", "t", "a"} {
+		if !filter.handleKey(typeText(ch), &model) {
+			t.Fatalf("key %q was not consumed by the filter", ch)
+		}
+	}
+	if filter.query != "sta" {
+		t.Errorf("query = %q, want %q", filter.query, "sta")
+	}
+	// The matching is fuzzy, with the best match first.
+	if got := visibleNames(model); !slices.Equal(got, []string{"staging", "qa-test"}) {
+		t.Errorf("visible items = %v, want [staging qa-test]", got)
+	}
+
+	// Backspace removes the last character.
+	if !filter.handleKey(tea.KeyPressMsg{Code: tea.KeyBackspace}, &model) {
+		t.Fatalf("backspace was not consumed by the filter")
+	}
+	if filter.query != "st" {
+		t.Errorf("query = %q, want %q", filter.query, "st")
+	}
+
+	// Ctrl+U clears the whole query and shows all items in the original order.
+	if !filter.handleKey(tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl}, &model) {
+		t.Fatalf("ctrl+u was not consumed by the filter")
+	}
+	if filter.query != "" {
+		t.Errorf("query = %q, want empty", filter.query)
+	}
+	if got := visibleNames(model); !slices.Equal(got, []string{"production", "staging", "develop", "qa-test"}) {
+		t.Errorf("visible items = %v, want all items", got)
+	}
+
+	// Backspace with an empty query is not consumed.
+	if filter.handleKey(tea.KeyPressMsg{Code: tea.KeyBackspace}, &model) {
+		t.Errorf("backspace with an empty query was consumed by the filter")
+	}
+
+	// Control and navigation keys are left for the list.
+	if filter.handleKey(tea.KeyPressMsg{Code: 'n', Mod: tea.ModCtrl}, &model) {
+		t.Errorf("ctrl+n was consumed by the filter")
+	}
+	if filter.handleKey(tea.KeyPressMsg{Code: tea.KeyDown}, &model) {
+		t.Errorf("down was consumed by the filter")
+	}
+}
+
+func TestListFilterMatchesDescription(t *testing.T) {
+	items := newTestListItems("alpha", "beta")
+	model := newListDialogModel(items, compactListDelegate{}, 1)
+	filter := newListFilter(items)
+
+	// The filter value includes the description (the other columns of table dialogs).
+	for _, ch := range []string{"e", "n", "v", "-", "b"} {
+		filter.handleKey(typeText(ch), &model)
+	}
+	if got := visibleNames(model); !slices.Equal(got, []string{"beta"}) {
+		t.Errorf("visible items = %v, want [beta]", got)
+	}
+
+	// No matches leaves the list empty.
+	filter.handleKey(typeText("x"), &model)
+	if got := visibleNames(model); len(got) != 0 {
+		t.Errorf("visible items = %v, want none", got)
+	}
+}
+
+func TestListFilterClear(t *testing.T) {
+	items := newTestListItems("alpha", "beta")
+	model := newListDialogModel(items, compactListDelegate{}, 1)
+	filter := newListFilter(items)
+
+	if filter.clear(&model) {
+		t.Errorf("clear() with no filter returned true")
+	}
+
+	filter.handleKey(typeText("b"), &model)
+	if !filter.clear(&model) {
+		t.Errorf("clear() with a filter returned false")
+	}
+	if got := visibleNames(model); !slices.Equal(got, []string{"alpha", "beta"}) {
+		t.Errorf("visible items = %v, want all items", got)
+	}
+}
+
+func TestCompactListKeys(t *testing.T) {
+	items := newTestListItems("production", "qa-test")
+	model := newCompactListModel("Choose", newListDialogModel(items, compactListDelegate{}, 1))
+
+	// 'q' is used for filtering and doesn't quit the dialog.
+	updated, _ := model.Update(typeText("q"))
+	model = updated.(compactListModel)
+	if model.quitting {
+		t.Fatalf("'q' quit the dialog")
+	}
+	if got := visibleNames(model.model); !slices.Equal(got, []string{"qa-test"}) {
+		t.Errorf("visible items = %v, want [qa-test]", got)
+	}
+
+	// The first Esc clears the filter, the second one cancels the dialog.
+	updated, _ = model.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	model = updated.(compactListModel)
+	if model.quitting || model.filter.query != "" {
+		t.Fatalf("esc with a filter: quitting = %v, query = %q, want the filter cleared", model.quitting, model.filter.query)
+	}
+	updated, _ = model.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	model = updated.(compactListModel)
+	if !model.quitting || model.selected != nil {
+		t.Errorf("esc without a filter did not cancel the dialog")
+	}
+
+	// Ctrl+C cancels the dialog even with a filter.
+	model = newCompactListModel("Choose", newListDialogModel(items, compactListDelegate{}, 1))
+	updated, _ = model.Update(typeText("p"))
+	updated, _ = updated.(compactListModel).Update(tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+	model = updated.(compactListModel)
+	if !model.quitting || model.selected != nil {
+		t.Errorf("ctrl+c did not cancel the dialog")
+	}
+}
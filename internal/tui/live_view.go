@@ -0,0 +1,92 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/metaplay/cli/pkg/styles"
+)
+
+// LiveView shows content that is re-rendered whenever the underlying state changes, until
+// the user quits with 'q' or Ctrl+C. The view is shown in the alternate screen buffer so the
+// terminal contents are restored on exit.
+type LiveView struct {
+	render  func(width int) string      // Renders the current content for the given terminal width
+	program atomic.Pointer[tea.Program] // Running program (nil until Run() is called)
+}
+
+// refreshMsg is sent to re-render the live view
+type refreshMsg struct{}
+
+// liveViewModel is the Bubble Tea model of a LiveView
+type liveViewModel struct {
+	view   *LiveView
+	width  int
+	height int
+}
+
+// NewLiveView creates a new live view with the given render function. The render function
+// must be safe to call concurrently with the code that updates the rendered state.
+func NewLiveView(render func(width int) string) *LiveView {
+	return &LiveView{render: render}
+}
+
+// Refresh re-renders the view. Safe to call from any goroutine, also before Run().
+func (v *LiveView) Refresh() {
+	if program := v.program.Load(); program != nil {
+		program.Send(refreshMsg{})
+	}
+}
+
+// Run shows the live view until the user quits it.
+func (v *LiveView) Run() error {
+	program := tea.NewProgram(liveViewModel{view: v})
+	v.program.Store(program)
+	if _, err := program.Run(); err != nil {
+		return fmt.Errorf("failed to run live view: %w", err)
+	}
+	return nil
+}
+
+// Init implements tea.Model
+func (m liveViewModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m liveViewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case refreshMsg:
+		// Nothing to update, the content is re-rendered from the latest state.
+	}
+	return m, nil
+}
+
+// View implements tea.Model
+func (m liveViewModel) View() tea.View {
+	lines := strings.Split(m.view.render(m.width), "\n")
+
+	// Truncate to the terminal height, leaving room for the help line.
+	if m.height > 2 && len(lines) > m.height-2 {
+		lines = lines[:m.height-2]
+	}
+	lines = append(lines, "", styles.RenderMuted("Press q to quit"))
+
+	view := tea.NewView(strings.Join(lines, "\n"))
+	view.AltScreen = true
+	return view
+}
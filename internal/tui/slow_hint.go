@@ -0,0 +1,44 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"time"
+
+	"github.com/metaplay/cli/pkg/styles"
+	"github.com/rs/zerolog/log"
+)
+
+// SlowHint is an actionable hint shown to the user when an operation takes longer than
+// expected, so that long-running operations don't look like the CLI is hanging.
+type SlowHint struct {
+	Threshold time.Duration // Show the hint after the operation has run this long
+	Message   string        // Hint to show, eg, what commonly causes the slowness and how to fix it
+}
+
+// IsExceeded returns true if the elapsed time exceeds the hint's threshold.
+func (hint *SlowHint) IsExceeded(elapsed time.Duration) bool {
+	return hint != nil && elapsed >= hint.Threshold
+}
+
+// render returns the hint as a styled line.
+func (hint *SlowHint) render() string {
+	return styles.RenderWarning("Taking longer than expected: ") + hint.Message
+}
+
+// WarnIfSlow prints the hint as a warning if the returned stop function is not called within
+// the hint's threshold. A nil hint is allowed and never prints anything. Usage:
+//
+//	stop := tui.WarnIfSlow(&tui.SlowHint{Threshold: time.Minute, Message: "..."})
+//	defer stop()
+func WarnIfSlow(hint *SlowHint) (stop func()) {
+	if hint == nil {
+		return func() {}
+	}
+	timer := time.AfterFunc(hint.Threshold, func() {
+		log.Warn().Msg(hint.render())
+	})
+	return func() { timer.Stop() }
+}
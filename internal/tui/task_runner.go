@@ -6,6 +6,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,22 @@ const (
 	StatusFailed
 )
 
+// String returns the lower-case name of the status, eg, 'completed'.
+func (s TaskStatus) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusRunning:
+		return "running"
+	case StatusCompleted:
+		return "completed"
+	case StatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
 // Spinner frames for the running state
 var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
@@ -131,17 +148,21 @@ func (to *TaskOutput) getLines() []string {
 type Task struct {
 	title     string        // Title for the task
 	runFunc   TaskRunFunc   // Run function for the task
+	dependsOn []*Task       // Tasks that must complete before this task is started
 	status    TaskStatus    // Status of the task
 	error     error         // Error that was returned by the task execution function
 	startTime time.Time     // Time when the task was started
 	elapsed   time.Duration // Amount of time elapsed while running the task
+	slowHint  *SlowHint     // Hint to show if the task runs for too long (optional)
 	mu        sync.Mutex    // Protects status, error, startTime, and elapsed
 	output    TaskOutput    // Output from the task
 }
 
-// TaskRunner manages and executes a sequence of tasks with visual progress
+// TaskRunner manages and executes a set of tasks with visual progress. By default, the tasks
+// are run sequentially in the order they were added. Tasks added with AddTaskAfter() only wait
+// for their explicit dependencies, so independent tasks can run in parallel.
 type TaskRunner struct {
-	tasks      []*Task       // Tasks that the operation consists of, run sequentially
+	tasks      []*Task       // Tasks that the operation consists of, in the order they were added
 	quitting   bool          // Is the operation quitting?
 	done       chan struct{} // Signals when all tasks are complete
 	frameIndex int           // Current frame index for spinner animation
@@ -164,17 +185,75 @@ func NewTaskRunner() *TaskRunner {
 	}
 }
 
-// AddTask adds a new task to the runner
-func (m *TaskRunner) AddTask(title string, runFunc TaskRunFunc) {
+// AddTask adds a new task to the runner. The task is started after all the previously added
+// tasks have completed.
+func (m *TaskRunner) AddTask(title string, runFunc TaskRunFunc) *Task {
+	return m.AddTaskAfter(title, slices.Clone(m.tasks), runFunc)
+}
+
+// AddTaskWithSlowHint adds a new task to the runner, with a hint that is shown if the task
+// runs for longer than the hint's threshold.
+func (m *TaskRunner) AddTaskWithSlowHint(title string, slowHint SlowHint, runFunc TaskRunFunc) *Task {
+	task := m.AddTask(title, runFunc)
+	task.slowHint = &slowHint
+	return task
+}
+
+// AddTaskAfter adds a new task to the runner that is started as soon as all the given
+// dependencies have completed, possibly in parallel with other tasks. With no dependencies,
+// the task is started immediately when the runner is run. The dependencies must have been
+// added to the same runner earlier.
+func (m *TaskRunner) AddTaskAfter(title string, dependencies []*Task, runFunc TaskRunFunc) *Task {
+	for _, dep := range dependencies {
+		if !slices.Contains(m.tasks, dep) {
+			log.Panic().Msgf("Task '%s' depends on a task that is not in the runner", title)
+		}
+	}
+
 	// Initialize task
 	task := &Task{
-		title:   title,
-		runFunc: runFunc,
-		status:  StatusPending,
+		title:     title,
+		runFunc:   runFunc,
+		dependsOn: dependencies,
+		status:    StatusPending,
 	}
 
 	// Add to runner
 	m.tasks = append(m.tasks, task)
+	return task
+}
+
+// AddTaskAfterWithSlowHint is like AddTaskAfter(), with a hint that is shown if the task runs
+// for longer than the hint's threshold.
+func (m *TaskRunner) AddTaskAfterWithSlowHint(title string, dependencies []*Task, slowHint SlowHint, runFunc TaskRunFunc) *Task {
+	task := m.AddTaskAfter(title, dependencies, runFunc)
+	task.slowHint = &slowHint
+	return task
+}
+
+// TaskResult is the outcome of a single task, as returned by TaskRunner.Results().
+type TaskResult struct {
+	Title   string        // Title of the task
+	Status  TaskStatus    // Final status of the task (pending if the task was not run)
+	Error   error         // Error returned by the task, if it failed
+	Elapsed time.Duration // Time spent running the task
+}
+
+// Results returns the outcomes of all the tasks in the order they were added. Intended to be
+// called after Run() has returned.
+func (m *TaskRunner) Results() []TaskResult {
+	results := make([]TaskResult, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		task.mu.Lock()
+		results = append(results, TaskResult{
+			Title:   task.title,
+			Status:  task.status,
+			Error:   task.error,
+			Elapsed: task.elapsed,
+		})
+		task.mu.Unlock()
+	}
+	return results
 }
 
 // taskStatusStyle returns the appropriate style for a task based on its status
@@ -207,7 +286,7 @@ func (m *TaskRunner) getStatusSymbol(status TaskStatus) string {
 	}
 }
 
-// Run starts executing tasks sequentially and displays the progress
+// Run executes the tasks (in parallel where their dependencies allow) and displays the progress
 func (m *TaskRunner) Run() error {
 	if isInteractiveMode {
 		return m.runInteractive()
@@ -221,7 +300,14 @@ func (m *TaskRunner) runInteractive() error {
 	m.program = tea.NewProgram(m)
 
 	// Start task execution in background
-	go m.executeTasks()
+	go func() {
+		firstError := m.executeTasks(nil, nil)
+
+		// Signal completion and quit the program
+		close(m.done)
+		m.program.Send(doneMsg{err: firstError})
+		log.Debug().Msg("All tasks completed")
+	}()
 
 	// Run the TUI
 	if _, err := m.program.Run(); err != nil {
@@ -236,32 +322,19 @@ func (m *TaskRunner) runInteractive() error {
 
 // runNonInteractive runs tasks with basic logging for non-interactive shells
 func (m *TaskRunner) runNonInteractive() error {
-	for _, task := range m.tasks {
+	onStart := func(task *Task) {
 		log.Info().Msgf("%s...", task.title)
-
-		task.mu.Lock()
-		task.status = StatusRunning
-		task.startTime = time.Now()
-		task.mu.Unlock()
-
-		if err := task.runFunc(&task.output); err != nil {
-			task.mu.Lock()
-			task.elapsed = time.Since(task.startTime)
-			task.status = StatusFailed
-			task.error = err
-			task.mu.Unlock()
-
-			// log.Error().Msgf(styleError.Render("ERROR: %v"), err)
-			return err
+	}
+	onCompleted := func(task *Task, numRunning int) {
+		// Include the title when other tasks are running, to tell the parallel tasks apart.
+		if numRunning > 0 {
+			log.Info().Msgf(" %s %s %s", styles.RenderSuccess("✓"), task.title, humanizeElapsed(task.elapsed))
+		} else {
+			log.Info().Msgf(" %s %s %s", styles.RenderSuccess("✓"), "Done", humanizeElapsed(task.elapsed))
 		}
-
-		task.mu.Lock()
-		task.status = StatusCompleted
-		elapsed := time.Since(task.startTime)
-		task.elapsed = elapsed
-		task.mu.Unlock()
-
-		log.Info().Msgf(" %s %s %s", styles.RenderSuccess("✓"), "Done", humanizeElapsed(elapsed))
+	}
+	if err := m.executeTasks(onStart, onCompleted); err != nil {
+		return err
 	}
 
 	log.Info().Msg("")
@@ -288,44 +361,108 @@ func (m *TaskRunner) checkErrors() error {
 	return nil
 }
 
-// executeTasks runs all tasks sequentially in interactive mode
-func (m *TaskRunner) executeTasks() {
-	var firstError error
-	for _, task := range m.tasks {
-		// Update task status to running and start timing
-		task.mu.Lock()
-		task.status = StatusRunning
-		task.startTime = time.Now()
-		task.mu.Unlock()
+// executeTasks runs the tasks, starting each task as soon as all of its dependencies have
+// completed. After a task fails, no more tasks are started, and the error is returned once the
+// tasks that are still running have finished, so that none are left running in the background
+// when the caller continues (eg, to clean up the resources used by the tasks). The optional
+// onStart and onCompleted callbacks are invoked on this goroutine when a task starts, and when
+// it completes successfully (with the number of tasks still running).
+func (m *TaskRunner) executeTasks(onStart func(task *Task), onCompleted func(task *Task, numRunning int)) error {
+	finished := make(chan *Task, len(m.tasks))
+	numRunning := 0
+
+	for {
+		// Start all the pending tasks whose dependencies have completed.
+		for _, task := range m.tasks {
+			if task.getStatus() != StatusPending || !task.areDependenciesCompleted() {
+				continue
+			}
 
-		// Execute the task
-		log.Debug().Msgf("Task start: %s", task.title)
-		if err := task.runFunc(&task.output); err != nil {
+			// Update task status to running and start timing
 			task.mu.Lock()
-			task.elapsed = time.Since(task.startTime)
-			task.status = StatusFailed
-			task.error = err
+			task.status = StatusRunning
+			task.startTime = time.Now()
 			task.mu.Unlock()
-			if firstError == nil {
-				firstError = err
+
+			if onStart != nil {
+				onStart(task)
 			}
-			break
-		} else {
-			task.mu.Lock()
-			task.status = StatusCompleted
-			elapsed := time.Since(task.startTime)
-			task.elapsed = elapsed
-			task.mu.Unlock()
-			log.Debug().Msgf("Task completed: %s %s", task.title, humanizeElapsed(elapsed))
+			numRunning++
+			go func() {
+				task.execute()
+				finished <- task
+			}()
+		}
+
+		// All done: the tasks cannot be left pending as the dependencies always refer to
+		// earlier tasks.
+		if numRunning == 0 {
+			return nil
+		}
+
+		// Wait for the next task to finish.
+		task := <-finished
+		numRunning--
+		task.mu.Lock()
+		status := task.status
+		err := task.error
+		task.mu.Unlock()
+		if status == StatusFailed {
+			// The tasks cannot be canceled, so wait for the ones still running.
+			if numRunning > 0 {
+				log.Debug().Msgf("Waiting for %d running task(s) to finish after failure", numRunning)
+			}
+			for ; numRunning > 0; numRunning-- {
+				<-finished
+			}
+			return err
+		}
+		if onCompleted != nil {
+			onCompleted(task, numRunning)
 		}
 	}
+}
 
-	// Signal completion and quit the program if in interactive mode
-	close(m.done)
-	if m.program != nil {
-		m.program.Send(doneMsg{err: firstError})
+// execute runs the task's function and updates the task's status based on the result.
+func (t *Task) execute() {
+	log.Debug().Msgf("Task start: %s", t.title)
+
+	// Show the slow hint as a log message in non-interactive mode (no-op in interactive mode).
+	stopSlowHint := func() {}
+	if !isInteractiveMode {
+		stopSlowHint = WarnIfSlow(t.slowHint)
+	}
+	err := t.runFunc(&t.output)
+	stopSlowHint()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.elapsed = time.Since(t.startTime)
+	if err != nil {
+		t.status = StatusFailed
+		t.error = err
+		log.Debug().Msgf("Task failed: %s %s", t.title, humanizeElapsed(t.elapsed))
+	} else {
+		t.status = StatusCompleted
+		log.Debug().Msgf("Task completed: %s %s", t.title, humanizeElapsed(t.elapsed))
 	}
-	log.Debug().Msg("All tasks completed")
+}
+
+// getStatus returns the current status of the task.
+func (t *Task) getStatus() TaskStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// areDependenciesCompleted returns true if all the task's dependencies have completed.
+func (t *Task) areDependenciesCompleted() bool {
+	for _, dep := range t.dependsOn {
+		if dep.getStatus() != StatusCompleted {
+			return false
+		}
+	}
+	return true
 }
 
 // Init implements tea.Model
@@ -349,7 +486,8 @@ func (m TaskRunner) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 	case tickMsg:
-		// Only advance the frame if enough time has passed and there's a running task
+		// Only advance the frame if enough time has passed and there's a running task.
+		// Update the elapsed time of all the running tasks, as several can run in parallel.
 		if time.Since(m.lastTick) >= time.Millisecond*80 {
 			hasRunningTask := false
 			for _, task := range m.tasks {
@@ -359,9 +497,6 @@ func (m TaskRunner) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					task.elapsed = time.Since(task.startTime)
 				}
 				task.mu.Unlock()
-				if hasRunningTask {
-					break
-				}
 			}
 			if hasRunningTask {
 				m.frameIndex = (m.frameIndex + 1) % len(spinnerFrames)
@@ -392,6 +527,7 @@ func (m TaskRunner) View() tea.View {
 		err := task.error
 		title := task.title
 		elapsed := task.elapsed
+		slowHint := task.slowHint
 		outputLines := task.output.getLines()
 		task.mu.Unlock()
 
@@ -408,6 +544,11 @@ func (m TaskRunner) View() tea.View {
 		}
 		lines = append(lines, taskLine)
 
+		// Show the hint if the task is taking longer than expected.
+		if status == StatusRunning && slowHint.IsExceeded(elapsed) {
+			lines = append(lines, fmt.Sprintf("    %s", slowHint.render()))
+		}
+
 		// Add output lines if there are any, indented by 4 spaces
 		for _, outputLine := range outputLines {
 			lines = append(lines, fmt.Sprintf("    %s", styles.RenderMuted(outputLine)))
@@ -0,0 +1,134 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package tui
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForChannel waits for the channel to be closed, or fails the test after a timeout.
+func waitForChannel(t *testing.T, ch <-chan struct{}, what string) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(5 * time.Second):
+		t.Errorf("timed out waiting for %s", what)
+		return false
+	}
+}
+
+func TestTaskRunnerDependencyOrder(t *testing.T) {
+	var mu sync.Mutex
+	order := []string{}
+	record := func(title string) {
+		mu.Lock()
+		order = append(order, title)
+		mu.Unlock()
+	}
+
+	// The first two tasks have no dependencies, so they must run in parallel: each waits
+	// for the other one to start.
+	runner := NewTaskRunner()
+	startedA := make(chan struct{})
+	startedB := make(chan struct{})
+	taskA := runner.AddTaskAfter("A", nil, func(output *TaskOutput) error {
+		close(startedA)
+		waitForChannel(t, startedB, "task B to start")
+		record("A")
+		return nil
+	})
+	taskB := runner.AddTaskAfter("B", nil, func(output *TaskOutput) error {
+		close(startedB)
+		waitForChannel(t, startedA, "task A to start")
+		record("B")
+		return nil
+	})
+	runner.AddTaskAfter("C", []*Task{taskA, taskB}, func(output *TaskOutput) error {
+		record("C")
+		return nil
+	})
+	runner.AddTask("D", func(output *TaskOutput) error {
+		record("D")
+		return nil
+	})
+
+	if err := runner.executeTasks(nil, nil); err != nil {
+		t.Fatalf("executeTasks() failed: %v", err)
+	}
+
+	// C waits for both A and B (which finish in any order), and D (added with AddTask) waits
+	// for all the earlier tasks.
+	if len(order) != 4 || !slices.Contains(order[:2], "A") || !slices.Contains(order[:2], "B") || !slices.Equal(order[2:], []string{"C", "D"}) {
+		t.Errorf("tasks were run in order %v, want A and B (in any order), then C and D", order)
+	}
+	for _, result := range runner.Results() {
+		if result.Status != StatusCompleted || result.Error != nil {
+			t.Errorf("task %s: status = %s, error = %v, want completed", result.Title, result.Status, result.Error)
+		}
+	}
+}
+
+func TestTaskRunnerFailureWhileOthersRunning(t *testing.T) {
+	failure := errors.New("task failed")
+	releaseSlow := make(chan struct{})
+	failed := make(chan struct{})
+
+	runner := NewTaskRunner()
+	slowTask := runner.AddTaskAfter("Slow", nil, func(output *TaskOutput) error {
+		waitForChannel(t, releaseSlow, "the slow task to be released")
+		return nil
+	})
+	failingTask := runner.AddTaskAfter("Failing", nil, func(output *TaskOutput) error {
+		defer close(failed)
+		return failure
+	})
+	runner.AddTaskAfter("AfterSlow", []*Task{slowTask}, func(output *TaskOutput) error {
+		t.Error("task depending on the slow task was started after a failure")
+		return nil
+	})
+	runner.AddTaskAfter("AfterFailing", []*Task{failingTask}, func(output *TaskOutput) error {
+		t.Error("task depending on the failed task was started")
+		return nil
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- runner.executeTasks(nil, nil)
+	}()
+
+	// The runner must wait for the slow task to finish before returning.
+	waitForChannel(t, failed, "the failing task to run")
+	select {
+	case err := <-result:
+		t.Fatalf("executeTasks() returned while a task was still running: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(releaseSlow)
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, failure) {
+			t.Errorf("executeTasks() = %v, want %v", err, failure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for executeTasks() to return")
+	}
+
+	wantStatuses := map[string]TaskStatus{
+		"Slow":         StatusCompleted,
+		"Failing":      StatusFailed,
+		"AfterSlow":    StatusPending,
+		"AfterFailing": StatusPending,
+	}
+	for _, result := range runner.Results() {
+		if result.Status != wantStatuses[result.Title] {
+			t.Errorf("task %s: status = %s, want %s", result.Title, result.Status, wantStatuses[result.Title])
+		}
+	}
+}
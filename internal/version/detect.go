@@ -193,10 +193,15 @@ func IsNewer(candidate, current string) bool {
 	return c.GreaterThan(cur)
 }
 
-// assetURL builds the CDN download URL for the release archive of the given version,
-// matching the goreleaser archive name templates in .goreleaser.yaml / .goreleaser-dev.yaml.
-// Keep this in sync with those templates and with install.sh / install.ps1.
+// assetURL builds the CDN download URL for the release archive of the given version.
 func assetURL(tag string) string {
+	return fmt.Sprintf("%s/%s/%s", downloadBaseURL, tag, assetName())
+}
+
+// assetName returns the name of the release archive for the current platform, matching the
+// goreleaser archive name templates in .goreleaser.yaml / .goreleaser-dev.yaml.
+// Keep this in sync with those templates and with install.sh / install.ps1.
+func assetName() string {
 	osTitle := map[string]string{
 		"linux":   "Linux",
 		"windows": "Windows",
@@ -213,6 +218,5 @@ func assetURL(tag string) string {
 		ext = "zip"
 	}
 
-	name := fmt.Sprintf("MetaplayCLI_%s_%s.%s", osTitle, arch, ext)
-	return fmt.Sprintf("%s/%s/%s", downloadBaseURL, tag, name)
+	return fmt.Sprintf("MetaplayCLI_%s_%s.%s", osTitle, arch, ext)
 }
@@ -5,18 +5,31 @@
 package version
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"runtime"
+	"strings"
 
 	"github.com/creativeprojects/go-selfupdate"
 	"github.com/creativeprojects/go-selfupdate/update"
 )
 
+// checksumsURL builds the CDN download URL for the SHA-256 checksums file of the given version,
+// matching the goreleaser checksum name templates in .goreleaser.yaml / .goreleaser-dev.yaml.
+func checksumsURL(tag string) string {
+	return fmt.Sprintf("%s/%s/MetaplayCLI_%s_checksums.txt", downloadBaseURL, tag, tag)
+}
+
 // DownloadAndApply downloads the release archive for the given version from the GitHub
-// CDN (not the throttled api.github.com), extracts the 'metaplay' binary, and atomically
-// replaces the executable at exePath.
+// CDN (not the throttled api.github.com), verifies it against the release's SHA-256
+// checksums file, extracts the 'metaplay' binary, and atomically replaces the executable
+// at exePath.
 //
 // It reuses go-selfupdate's standalone helpers for the archive handling and the safe,
 // cross-platform binary swap, so we don't have to reimplement either.
@@ -25,25 +38,30 @@ import (
 // connection should not fail a legitimate update). Cancellation is governed by ctx, so the
 // caller can bound or interrupt it (e.g. Ctrl+C via the command context).
 func DownloadAndApply(ctx context.Context, tag, exePath string) error {
-	url := assetURL(tag)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	// Resolve the expected checksum first: without it, the archive cannot be trusted.
+	checksums, err := download(ctx, checksumsURL(tag))
 	if err != nil {
 		return err
 	}
+	expectedChecksum, err := parseChecksum(checksums, assetName())
+	if err != nil {
+		return fmt.Errorf("failed to resolve the checksum of %s: %w", assetName(), err)
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	url := assetURL(tag)
+	archive, err := download(ctx, url)
 	if err != nil {
-		return fmt.Errorf("failed to download %s: %w", url, err)
+		return err
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download %s: unexpected status %d", url, resp.StatusCode)
+	// Refuse to install an archive that was corrupted or tampered with.
+	actualChecksum := sha256.Sum256(archive)
+	if hex.EncodeToString(actualChecksum[:]) != expectedChecksum {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %x", url, expectedChecksum, actualChecksum)
 	}
 
 	// Extract the 'metaplay' binary from the archive (format detected from the URL suffix).
-	binary, err := selfupdate.DecompressCommand(resp.Body, url, "metaplay", runtime.GOOS, runtime.GOARCH)
+	binary, err := selfupdate.DecompressCommand(bytes.NewReader(archive), url, "metaplay", runtime.GOOS, runtime.GOARCH)
 	if err != nil {
 		return fmt.Errorf("failed to extract the binary from %s: %w", url, err)
 	}
@@ -54,3 +72,52 @@ func DownloadAndApply(ctx context.Context, tag, exePath string) error {
 	}
 	return nil
 }
+
+// download fetches the contents of the given URL.
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return body, nil
+}
+
+// parseChecksum returns the hex-encoded SHA-256 checksum of the named file from the contents of
+// a checksums file in the 'sha256sum' format, eg, "<checksum>  MetaplayCLI_Linux_x86_64.tar.gz".
+func parseChecksum(checksums []byte, fileName string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// A leading '*' marks files checksummed in binary mode.
+		if strings.TrimPrefix(fields[1], "*") != fileName {
+			continue
+		}
+		checksum := strings.ToLower(fields[0])
+		if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+			return "", fmt.Errorf("invalid SHA-256 checksum '%s' for %s", fields[0], fileName)
+		}
+		return checksum, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum for %s in the checksums file", fileName)
+}
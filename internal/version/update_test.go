@@ -0,0 +1,46 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package version
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChecksumsURL(t *testing.T) {
+	want := "https://github.com/metaplay/cli/releases/download/1.11.1-dev.12/MetaplayCLI_1.11.1-dev.12_checksums.txt"
+	if got := checksumsURL("1.11.1-dev.12"); got != want {
+		t.Errorf("checksumsURL() = %s, want %s", got, want)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	linuxChecksum := strings.Repeat("ab", 32)
+	windowsChecksum := strings.Repeat("CD", 32)
+	checksums := []byte(linuxChecksum + "  MetaplayCLI_Linux_x86_64.tar.gz\n" +
+		windowsChecksum + " *MetaplayCLI_Windows_x86_64.zip\n" +
+		"not-a-checksum  MetaplayCLI_Darwin_arm64.tar.gz\n")
+
+	tests := []struct {
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{"MetaplayCLI_Linux_x86_64.tar.gz", linuxChecksum, false},
+		// Binary mode marker and uppercase hex are accepted.
+		{"MetaplayCLI_Windows_x86_64.zip", strings.ToLower(windowsChecksum), false},
+		{"MetaplayCLI_Darwin_arm64.tar.gz", "", true},
+		{"MetaplayCLI_Linux_arm64.tar.gz", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseChecksum(checksums, tt.fileName)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChecksum(%q) error = %v, wantErr %v", tt.fileName, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseChecksum(%q) = %q, want %q", tt.fileName, got, tt.want)
+		}
+	}
+}
@@ -18,8 +18,11 @@ type AuthProviderConfig struct {
 	Audience         string `yaml:"audience"`         // Eg, "managed-gameservers"
 }
 
+// GetSessionID returns the ID of the provider's session in the active profile. The sessions of
+// the default profile use the provider name as-is, eg, 'Metaplay Auth', and the sessions of
+// named profiles are suffixed with the profile, eg, 'Metaplay Auth@work'.
 func (provider *AuthProviderConfig) GetSessionID() string {
-	return provider.Name
+	return profileSessionID(provider.Name, ResolveActiveProfile())
 }
 
 // Create a default AuthProvider that uses Metaplay Auth.
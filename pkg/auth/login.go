@@ -211,14 +211,19 @@ func MachineLogin(authProvider *AuthProviderConfig, clientID, clientSecret strin
 		return fmt.Errorf("failed to parse token JSON: %w", err)
 	}
 
+	return completeMachineLogin(authProvider, &tokenSet)
+}
+
+// completeMachineLogin persists the machine user's tokens and reports the logged in user.
+func completeMachineLogin(authProvider *AuthProviderConfig, tokenSet *TokenSet) error {
 	// Save tokens securely
-	err = SaveSessionState(authProvider.GetSessionID(), UserTypeMachine, &tokenSet)
+	err := SaveSessionState(authProvider.GetSessionID(), UserTypeMachine, tokenSet)
 	if err != nil {
 		return fmt.Errorf("failed to save tokens: %w", err)
 	}
 
 	// Fetch the user info.
-	userinfo, err := FetchUserInfo(authProvider, &tokenSet)
+	userinfo, err := FetchUserInfo(authProvider, tokenSet)
 	if err != nil {
 		return err
 	}
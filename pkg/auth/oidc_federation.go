@@ -0,0 +1,158 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/pkg/httputil"
+	"github.com/rs/zerolog/log"
+)
+
+// Environment variable for passing an OIDC ID token from any CI provider, eg, with the GitLab
+// 'id_tokens' keyword. Takes precedence over the token requested from GitHub Actions.
+const OIDCTokenEnvVar = "METAPLAY_OIDC_TOKEN"
+
+// OAuth2 token exchange (RFC 8693) grant and token types.
+const (
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	idTokenType            = "urn:ietf:params:oauth:token-type:id_token"
+)
+
+// Timeout for requesting the ID token from the CI provider.
+const oidcTokenRequestTimeout = 30 * time.Second
+
+// OIDCFederationConfig describes the CI provider's ID tokens that can be exchanged for the
+// credentials of a machine user ($.oidcFederation in metaplay-project.yaml).
+type OIDCFederationConfig struct {
+	Issuer   string `yaml:"issuer"`             // Trusted issuer of the ID tokens, eg, 'https://token.actions.githubusercontent.com'
+	Audience string `yaml:"audience"`           // Audience that the ID tokens must be issued for, eg, 'metaplay'
+	ClientID string `yaml:"clientId,omitempty"` // Client ID of the machine user to log in as (optional if the issuer maps to a single machine user)
+}
+
+// ResolveCIIDToken returns an OIDC ID token issued by the CI provider for the given audience,
+// along with a human-readable description of where the token came from. The token is taken
+// from METAPLAY_OIDC_TOKEN, or else requested from GitHub Actions.
+func ResolveCIIDToken(ctx context.Context, audience string) (string, string, error) {
+	if token := strings.TrimSpace(os.Getenv(OIDCTokenEnvVar)); token != "" {
+		return token, OIDCTokenEnvVar, nil
+	}
+
+	// GitHub Actions exposes these when the workflow has the 'id-token: write' permission.
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL != "" && requestToken != "" {
+		token, err := requestGitHubActionsIDToken(ctx, requestURL, requestToken, audience)
+		if err != nil {
+			return "", "", err
+		}
+		return token, "GitHub Actions", nil
+	}
+
+	return "", "", clierrors.New("No OIDC ID token available from the CI provider").
+		WithSuggestion(fmt.Sprintf("On GitHub Actions, grant the workflow the 'id-token: write' permission. On other CI providers, pass the ID token in %s, eg, using the 'id_tokens' keyword on GitLab.", OIDCTokenEnvVar))
+}
+
+// requestGitHubActionsIDToken requests an ID token for the audience from the GitHub Actions
+// OIDC provider.
+func requestGitHubActionsIDToken(ctx context.Context, requestURL, requestToken, audience string) (string, error) {
+	tokenURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := tokenURL.Query()
+	query.Set("audience", audience)
+	tokenURL.RawQuery = query.Encode()
+
+	log.Debug().Msgf("Request OIDC ID token from GitHub Actions for audience '%s'", audience)
+	var response struct {
+		Value string `json:"value"`
+	}
+	resp, err := httputil.NewRetryClient().
+		SetTimeout(oidcTokenRequestTimeout).
+		R().
+		SetContext(ctx).
+		SetHeader("Authorization", "Bearer "+requestToken).
+		SetResult(&response).
+		Get(tokenURL.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to request an ID token from GitHub Actions: %w", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return "", fmt.Errorf("failed to request an ID token from GitHub Actions: status %d: %s", resp.StatusCode(), resp.String())
+	}
+	if response.Value == "" {
+		return "", fmt.Errorf("GitHub Actions returned an empty ID token")
+	}
+	return response.Value, nil
+}
+
+// ValidateIDTokenClaims checks that the ID token was issued by the trusted issuer for the
+// expected audience and has not expired. The signature is not verified: that is done by the
+// auth provider in the token exchange. This only gives better errors for misconfigurations.
+func ValidateIDTokenClaims(idToken string, federation *OIDCFederationConfig) error {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, &claims); err != nil {
+		return fmt.Errorf("failed to parse the ID token: %w", err)
+	}
+
+	if strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(federation.Issuer, "/") {
+		return fmt.Errorf("ID token issuer '%s' does not match the trusted issuer '%s'", claims.Issuer, federation.Issuer)
+	}
+	if !slices.Contains(claims.Audience, federation.Audience) {
+		return fmt.Errorf("ID token audience %v does not include '%s'", []string(claims.Audience), federation.Audience)
+	}
+	if claims.ExpiresAt != nil && time.Now().After(claims.ExpiresAt.Time) {
+		return fmt.Errorf("ID token expired at %s", claims.ExpiresAt.Time.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// MachineLoginWithIDToken exchanges an OIDC ID token issued by a CI provider for the
+// credentials of a machine user (RFC 8693 token exchange), so that no long-lived secrets are
+// needed in the CI jobs.
+func MachineLoginWithIDToken(authProvider *AuthProviderConfig, federation *OIDCFederationConfig, idToken string) error {
+	if err := ValidateIDTokenClaims(idToken, federation); err != nil {
+		return clierrors.Wrap(err, "Invalid OIDC ID token").
+			WithSuggestion("Check that the issuer and audience in 'oidcFederation' in metaplay-project.yaml match the CI provider's ID token")
+	}
+
+	params := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"subject_token":      {idToken},
+		"subject_token_type": {idTokenType},
+		"scope":              {"openid email profile offline_access"},
+	}
+	if federation.ClientID != "" {
+		params.Set("client_id", federation.ClientID)
+	}
+
+	body, statusCode, err := httputil.PostFormWithRetry(authProvider.TokenEndpoint, params.Encode())
+	if err != nil {
+		return clierrors.Wrap(err, "Failed to exchange the ID token with Metaplay").
+			WithSuggestion("Check your network connection and try again")
+	}
+	if statusCode != http.StatusOK {
+		return clierrors.Newf("Token exchange failed with status %d", statusCode).
+			WithDetails(string(body)).
+			WithSuggestion("Check that the machine user trusts the CI provider's issuer and the identity of this job")
+	}
+
+	var tokenSet TokenSet
+	if err := json.Unmarshal(body, &tokenSet); err != nil {
+		return fmt.Errorf("failed to parse token JSON: %w", err)
+	}
+	return completeMachineLogin(authProvider, &tokenSet)
+}
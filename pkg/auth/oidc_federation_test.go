@@ -0,0 +1,89 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// makeTestIDToken returns an (unsigned) ID token with the given claims.
+func makeTestIDToken(t *testing.T, claims jwt.RegisteredClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestValidateIDTokenClaims(t *testing.T) {
+	federation := &OIDCFederationConfig{Issuer: "https://token.actions.githubusercontent.com", Audience: "metaplay"}
+	future := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	past := jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		isValid bool
+	}{
+		{"valid", jwt.RegisteredClaims{Issuer: federation.Issuer, Audience: jwt.ClaimStrings{"metaplay"}, ExpiresAt: future}, true},
+		{"trailing slash in issuer", jwt.RegisteredClaims{Issuer: federation.Issuer + "/", Audience: jwt.ClaimStrings{"other", "metaplay"}, ExpiresAt: future}, true},
+		{"wrong issuer", jwt.RegisteredClaims{Issuer: "https://gitlab.com", Audience: jwt.ClaimStrings{"metaplay"}, ExpiresAt: future}, false},
+		{"wrong audience", jwt.RegisteredClaims{Issuer: federation.Issuer, Audience: jwt.ClaimStrings{"sts.amazonaws.com"}, ExpiresAt: future}, false},
+		{"expired", jwt.RegisteredClaims{Issuer: federation.Issuer, Audience: jwt.ClaimStrings{"metaplay"}, ExpiresAt: past}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIDTokenClaims(makeTestIDToken(t, tt.claims), federation)
+			if (err == nil) != tt.isValid {
+				t.Errorf("ValidateIDTokenClaims() error = %v, want valid = %v", err, tt.isValid)
+			}
+		})
+	}
+
+	if err := ValidateIDTokenClaims("not-a-jwt", federation); err == nil {
+		t.Errorf("expected an error for a malformed token")
+	}
+}
+
+func TestResolveCIIDToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer request-token" || r.URL.Query().Get("audience") != "metaplay" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"value":"github-id-token"}`))
+	}))
+	defer server.Close()
+
+	// No token available.
+	t.Setenv(OIDCTokenEnvVar, "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+	if _, _, err := ResolveCIIDToken(context.Background(), "metaplay"); err == nil {
+		t.Errorf("expected an error when no ID token is available")
+	}
+
+	// GitHub Actions.
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", server.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+	token, source, err := ResolveCIIDToken(context.Background(), "metaplay")
+	if err != nil || token != "github-id-token" || source != "GitHub Actions" {
+		t.Errorf("got (%q, %q, %v), want the token from GitHub Actions", token, source, err)
+	}
+
+	// Explicit token takes precedence.
+	t.Setenv(OIDCTokenEnvVar, "explicit-id-token\n")
+	token, source, err = ResolveCIIDToken(context.Background(), "metaplay")
+	if err != nil || token != "explicit-id-token" || source != OIDCTokenEnvVar {
+		t.Errorf("got (%q, %q, %v), want the token from %s", token, source, err, OIDCTokenEnvVar)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Name of the profile used when no other profile is selected. Its sessions are the same as
+// before profiles were introduced, so existing logins keep working.
+const DefaultProfile = "default"
+
+// Environment variable for selecting the profile, eg, METAPLAY_PROFILE=work. Takes precedence
+// over the profile selected with 'metaplay auth switch'.
+const ProfileEnvVar = "METAPLAY_PROFILE"
+
+// Separator between the provider name and the profile in the session IDs of named profiles.
+const profileSeparator = "@"
+
+// Valid profile names. The separator is not allowed, so that session IDs are unambiguous.
+var profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Profile set explicitly for this invocation, eg, with 'metaplay auth login --profile=work'.
+var profileOverride string
+
+// ProfileInfo describes a profile and the sessions stored in it.
+type ProfileInfo struct {
+	Name      string   `json:"name"`      // Name of the profile, eg, 'work'.
+	IsActive  bool     `json:"isActive"`  // Is this the active profile?
+	Providers []string `json:"providers"` // Names of the auth providers with a session in the profile.
+}
+
+// ValidateProfileName checks that the profile name can be used.
+func ValidateProfileName(profile string) error {
+	if !profileNameRegex.MatchString(profile) {
+		return fmt.Errorf("invalid profile name '%s': only letters, digits, '_', '.' and '-' are allowed", profile)
+	}
+	return nil
+}
+
+// SetProfileOverride selects the profile to use for the rest of this invocation, taking
+// precedence over METAPLAY_PROFILE and the persisted active profile.
+func SetProfileOverride(profile string) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	profileOverride = profile
+	return nil
+}
+
+// ResolveActiveProfile returns the profile to use: the override of this invocation, or else
+// METAPLAY_PROFILE, or else the profile selected with 'metaplay auth switch', or else the
+// default profile.
+func ResolveActiveProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if profile := os.Getenv(ProfileEnvVar); profile != "" {
+		if err := ValidateProfileName(profile); err != nil {
+			log.Warn().Msgf("Ignoring %s: %v", ProfileEnvVar, err)
+		} else {
+			return profile
+		}
+	}
+
+	persistedConfig, err := loadPersistedConfig()
+	if err != nil {
+		log.Debug().Msgf("Failed to load the active profile, using the default: %v", err)
+		return DefaultProfile
+	}
+	if persistedConfig.ActiveProfile != "" {
+		return persistedConfig.ActiveProfile
+	}
+	return DefaultProfile
+}
+
+// SwitchProfile persists the given profile as the active one for the subsequent invocations.
+func SwitchProfile(profile string) error {
+	if err := ValidateProfileName(profile); err != nil {
+		return err
+	}
+	return updatePersistedConfig(func(config *PersistedConfig) error {
+		if profile == DefaultProfile {
+			config.ActiveProfile = ""
+		} else {
+			config.ActiveProfile = profile
+		}
+		return nil
+	})
+}
+
+// ListProfiles returns the profiles with stored sessions, along with the active profile and the
+// default profile even if they have no sessions, sorted by name.
+func ListProfiles() ([]ProfileInfo, error) {
+	persistedConfig, err := loadPersistedConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	activeProfile := ResolveActiveProfile()
+	profiles := map[string]*ProfileInfo{}
+	getProfile := func(name string) *ProfileInfo {
+		if profile, found := profiles[name]; found {
+			return profile
+		}
+		profile := &ProfileInfo{Name: name, IsActive: name == activeProfile, Providers: []string{}}
+		profiles[name] = profile
+		return profile
+	}
+	getProfile(DefaultProfile)
+	getProfile(activeProfile)
+	for sessionID := range persistedConfig.Sessions {
+		providerName, profile := parseProfileSessionID(sessionID)
+		info := getProfile(profile)
+		info.Providers = append(info.Providers, providerName)
+	}
+
+	result := make([]ProfileInfo, 0, len(profiles))
+	for _, profile := range profiles {
+		sort.Strings(profile.Providers)
+		result = append(result, *profile)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// profileSessionID returns the session ID of the provider in the given profile.
+func profileSessionID(providerName, profile string) string {
+	if profile == "" || profile == DefaultProfile {
+		return providerName
+	}
+	return providerName + profileSeparator + profile
+}
+
+// parseProfileSessionID splits a session ID into the provider name and the profile.
+func parseProfileSessionID(sessionID string) (providerName string, profile string) {
+	ndx := strings.LastIndex(sessionID, profileSeparator)
+	if ndx == -1 || !profileNameRegex.MatchString(sessionID[ndx+1:]) {
+		return sessionID, DefaultProfile
+	}
+	return sessionID[:ndx], sessionID[ndx+1:]
+}
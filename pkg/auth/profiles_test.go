@@ -0,0 +1,106 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/metaplay/cli/pkg/common"
+)
+
+func TestProfileSessionID(t *testing.T) {
+	tests := []struct {
+		providerName string
+		profile      string
+		want         string
+	}{
+		{"Metaplay Auth", DefaultProfile, "Metaplay Auth"},
+		{"Metaplay Auth", "", "Metaplay Auth"},
+		{"Metaplay Auth", "work", "Metaplay Auth@work"},
+	}
+	for _, tt := range tests {
+		got := profileSessionID(tt.providerName, tt.profile)
+		if got != tt.want {
+			t.Errorf("profileSessionID(%q, %q) = %q, want %q", tt.providerName, tt.profile, got, tt.want)
+		}
+		providerName, profile := parseProfileSessionID(got)
+		wantProfile := tt.profile
+		if wantProfile == "" {
+			wantProfile = DefaultProfile
+		}
+		if providerName != tt.providerName || profile != wantProfile {
+			t.Errorf("parseProfileSessionID(%q) = (%q, %q), want (%q, %q)", got, providerName, profile, tt.providerName, wantProfile)
+		}
+	}
+}
+
+func TestResolveActiveProfile(t *testing.T) {
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+	defer func() { profileOverride = "" }()
+
+	if got := ResolveActiveProfile(); got != DefaultProfile {
+		t.Errorf("expected the default profile, got %q", got)
+	}
+
+	// Persisted profile.
+	if err := SwitchProfile("work"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolveActiveProfile(); got != "work" {
+		t.Errorf("expected the persisted profile, got %q", got)
+	}
+
+	// Environment variable takes precedence over the persisted profile.
+	t.Setenv(ProfileEnvVar, "clientA")
+	if got := ResolveActiveProfile(); got != "clientA" {
+		t.Errorf("expected the profile from %s, got %q", ProfileEnvVar, got)
+	}
+
+	// Override takes precedence over all.
+	if err := SetProfileOverride("clientB"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolveActiveProfile(); got != "clientB" {
+		t.Errorf("expected the override profile, got %q", got)
+	}
+
+	if err := SetProfileOverride("bad@name"); err == nil {
+		t.Errorf("expected an error for an invalid profile name")
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	t.Setenv(ProfileEnvVar, "")
+
+	err := updatePersistedConfig(func(config *PersistedConfig) error {
+		config.Sessions["Metaplay Auth"] = PersistedSessionState{UserType: UserTypeHuman}
+		config.Sessions["Metaplay Auth@work"] = PersistedSessionState{UserType: UserTypeHuman}
+		config.Sessions["custom@work"] = PersistedSessionState{UserType: UserTypeHuman}
+		config.ActiveProfile = "clientA"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 3 {
+		t.Fatalf("expected 3 profiles, got %+v", profiles)
+	}
+	if profiles[0].Name != "clientA" || !profiles[0].IsActive || len(profiles[0].Providers) != 0 {
+		t.Errorf("unexpected active profile: %+v", profiles[0])
+	}
+	if profiles[1].Name != DefaultProfile || profiles[1].IsActive || len(profiles[1].Providers) != 1 {
+		t.Errorf("unexpected default profile: %+v", profiles[1])
+	}
+	if profiles[2].Name != "work" || len(profiles[2].Providers) != 2 || profiles[2].Providers[0] != "Metaplay Auth" {
+		t.Errorf("unexpected work profile: %+v", profiles[2])
+	}
+}
@@ -17,8 +17,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// Get the expires-at of the access token of the tokenSet.
-func getAccessTokenExpiresAt(tokenSet *TokenSet) (time.Time, error) {
+// GetAccessTokenExpiresAt returns the expiry time of the access token of the tokenSet.
+func GetAccessTokenExpiresAt(tokenSet *TokenSet) (time.Time, error) {
 	// Parse the token without validation
 	token, _, err := jwt.NewParser().ParseUnverified(tokenSet.AccessToken, jwt.MapClaims{})
 	if err != nil {
@@ -57,7 +57,7 @@ func LoadAndRefreshTokenSet(authProvider *AuthProviderConfig) (*TokenSet, error)
 
 	// Resolve when access token expires.
 	tokenSet := sessionState.TokenSet
-	expiresAt, err := getAccessTokenExpiresAt(tokenSet)
+	expiresAt, err := GetAccessTokenExpiresAt(tokenSet)
 	if err != nil {
 		return nil, clierrors.Wrap(err, "Failed to parse access token expiration").
 			WithSuggestion("Run 'metaplay auth login' to re-authenticate")
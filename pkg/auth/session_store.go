@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/metaplay/cli/pkg/common"
 	"github.com/rs/zerolog/log"
 	"github.com/zalando/go-keyring"
 )
@@ -31,6 +32,9 @@ const (
 	keyringKey     = "encryption-key"
 )
 
+// Prefix of the keyring keys of the sessions' refresh tokens, followed by the session ID.
+const keyringRefreshTokenKeyPrefix = "refresh-token:"
+
 // linuxFallbackKey is a hard-coded encryption key used on Linux systems where
 // the system keyring (Secret Service) is not available. This includes headless
 // servers, containers, and minimal installs without GNOME Keyring or KWallet.
@@ -55,14 +59,16 @@ type SessionState struct {
 
 // Persisted session state (with encrypted tokenSet).
 type PersistedSessionState struct {
-	UserType       UserType `json:"userType"`              // Type of the user (human or machine)
-	TokenSetLegacy string   `json:"tokenSet,omitempty"`    // Legacy CFB-encrypted tokenSet (deprecated)
-	TokenSetGCM    string   `json:"tokenSetGcm,omitempty"` // GCM-encrypted tokenSet
+	UserType              UserType `json:"userType"`                        // Type of the user (human or machine)
+	TokenSetLegacy        string   `json:"tokenSet,omitempty"`              // Legacy CFB-encrypted tokenSet (deprecated)
+	TokenSetGCM           string   `json:"tokenSetGcm,omitempty"`           // GCM-encrypted tokenSet
+	RefreshTokenInKeyring bool     `json:"refreshTokenInKeyring,omitempty"` // Is the refresh token stored in the OS keyring instead of tokenSetGcm?
 }
 
 // Represents the config.json persisted on disk.
 type PersistedConfig struct {
-	Sessions map[string]PersistedSessionState `json:"sessions"` // Persisted sessions, use sessionID as key.
+	Sessions      map[string]PersistedSessionState `json:"sessions"`                // Persisted sessions, use sessionID as key.
+	ActiveProfile string                           `json:"activeProfile,omitempty"` // Profile selected with 'metaplay auth switch', empty for the default.
 }
 
 func newPersistedConfig() *PersistedConfig {
@@ -229,36 +235,23 @@ func decryptLegacyCFB(data []byte, key []byte) ([]byte, error) {
 }
 
 // resolvePersistedConfigFilePath resolves the path to the persisted configuration.
-// It follows platform-specific best practices for Linux, macOS, and Windows.
 func resolvePersistedConfigFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	baseDir, err := ResolveConfigDirectory()
 	if err != nil {
-		return "", fmt.Errorf("failed to get user's home directory: %w", err)
-	}
-
-	// Use the appropriate directory for storing application data
-	var baseDir string
-	switch runtime.GOOS {
-	case "windows":
-		// Windows: Use AppData\Local for application-specific data
-		baseDir = filepath.Join(homeDir, "AppData", "Local", "Metaplay")
-	case "darwin":
-		// macOS: Use ~/Library/Application Support for application data
-		baseDir = filepath.Join(homeDir, "Library", "Application Support", "Metaplay")
-	default:
-		// Linux and other Unix-like systems: Use ~/.config/metaplay for user-specific configuration data
-		baseDir = filepath.Join(homeDir, ".config", "metaplay")
-	}
-
-	// Ensure the directory exists
-	if err := os.MkdirAll(baseDir, 0700); err != nil {
-		return "", fmt.Errorf("failed to create directory for file path: %w", err)
+		return "", err
 	}
 
 	// Return the resolved file path
 	return filepath.Join(baseDir, "config.json"), nil
 }
 
+// ResolveConfigDirectory resolves (and creates, if needed) the directory for the CLI's
+// user-specific configuration and credentials. See common.ResolveStateDirPath() for how the
+// directory is resolved on each platform, and how to relocate it with METAPLAY_HOME.
+func ResolveConfigDirectory() (string, error) {
+	return common.ResolveStateDir(common.StateDirConfig)
+}
+
 // Load the persisted config file on disk. Returns an empty default state if the
 // file does not exist.
 func loadPersistedConfig() (*PersistedConfig, error) {
@@ -329,10 +322,22 @@ func updatePersistedConfig(updateFunc func(*PersistedConfig) error) error {
 	return savePersistedConfig(configState)
 }
 
-// SaveSessionState saves the current session state (with GCM-encrypted tokenSet).
+// SaveSessionState saves the current session state (with GCM-encrypted tokenSet). The long-lived
+// refresh token is stored in the OS keyring (macOS Keychain, Windows Credential Manager or
+// Secret Service on Linux) when available, and otherwise in the encrypted tokenSet.
 func SaveSessionState(sessionID string, userType UserType, tokenSet *TokenSet) error {
+	// Move the refresh token to the keyring, if possible.
+	persistedTokenSet := *tokenSet
+	refreshTokenInKeyring := storeRefreshTokenInKeyring(sessionID, tokenSet.RefreshToken)
+	if refreshTokenInKeyring {
+		persistedTokenSet.RefreshToken = ""
+	} else {
+		// Remove any stale refresh token from an earlier session.
+		deleteRefreshTokenFromKeyring(sessionID)
+	}
+
 	// Serialize the tokenSet to JSON
-	tokenSetJSON, err := json.Marshal(tokenSet)
+	tokenSetJSON, err := json.Marshal(&persistedTokenSet)
 	if err != nil {
 		return fmt.Errorf("failed to serialize TokenSet: %w", err)
 	}
@@ -351,8 +356,9 @@ func SaveSessionState(sessionID string, userType UserType, tokenSet *TokenSet) e
 
 	// Construct session state (only using GCM field, legacy field is omitted).
 	sessionState := PersistedSessionState{
-		UserType:    userType,
-		TokenSetGCM: base64.StdEncoding.EncodeToString(encryptedTokenSet),
+		UserType:              userType,
+		TokenSetGCM:           base64.StdEncoding.EncodeToString(encryptedTokenSet),
+		RefreshTokenInKeyring: refreshTokenInKeyring,
 	}
 
 	// Update session state in persisted config.
@@ -445,7 +451,18 @@ func LoadSessionState(sessionID string) (*SessionState, error) {
 		return nil, fmt.Errorf("failed to deserialize TokenSet: %w", err)
 	}
 
-	// Migrate legacy session to GCM encryption
+	// Resolve the refresh token from the keyring, or migrate it there from the file.
+	if sessionState.RefreshTokenInKeyring {
+		refreshToken, err := keyring.Get(keyringService, keyringRefreshTokenKeyPrefix+sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the refresh token from the OS keyring (please log in again): %w", err)
+		}
+		tokenSet.RefreshToken = refreshToken
+	} else if tokenSet.RefreshToken != "" && storeRefreshTokenInKeyring(sessionID, tokenSet.RefreshToken) {
+		needsMigration = true
+	}
+
+	// Migrate legacy session to GCM encryption, and the refresh token to the keyring
 	if needsMigration {
 		// Re-save with GCM encryption. Ignore errors as we can retry next time.
 		_ = SaveSessionState(sessionID, sessionState.UserType, &tokenSet)
@@ -459,6 +476,8 @@ func LoadSessionState(sessionID string) (*SessionState, error) {
 
 // DeleteSessionState removes the current session state (i.e., signs out the user).
 func DeleteSessionState(sessionID string) error {
+	deleteRefreshTokenFromKeyring(sessionID)
+
 	// Remove the session from the persisted config.
 	return updatePersistedConfig(func(config *PersistedConfig) error {
 		delete(config.Sessions, sessionID)
@@ -466,6 +485,28 @@ func DeleteSessionState(sessionID string) error {
 	})
 }
 
+// storeRefreshTokenInKeyring stores the session's refresh token in the OS keyring. Returns false
+// if there is no refresh token or the keyring is not available, eg, on headless Linux machines
+// or when the token is too large for Windows Credential Manager.
+func storeRefreshTokenInKeyring(sessionID, refreshToken string) bool {
+	if refreshToken == "" {
+		return false
+	}
+	if err := keyring.Set(keyringService, keyringRefreshTokenKeyPrefix+sessionID, refreshToken); err != nil {
+		log.Debug().Msgf("Failed to store the refresh token in the OS keyring, storing it in the credentials file: %v", err)
+		return false
+	}
+	return true
+}
+
+// deleteRefreshTokenFromKeyring removes the session's refresh token from the OS keyring, if any.
+func deleteRefreshTokenFromKeyring(sessionID string) {
+	err := keyring.Delete(keyringService, keyringRefreshTokenKeyPrefix+sessionID)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		log.Debug().Msgf("Failed to remove the refresh token from the OS keyring: %v", err)
+	}
+}
+
 // RevokeRefreshToken revokes a refresh token at the authorization server per RFC 7009.
 // This is best-effort: errors are logged at Warn level but not returned, since local
 // cleanup should proceed regardless of server-side revocation success.
@@ -526,3 +567,72 @@ func RevokeAndDeleteSession(authProvider *AuthProviderConfig, sessionID string)
 	// Always delete local session state
 	return DeleteSessionState(sessionID)
 }
+
+// Where a credential is stored.
+type CredentialLocation string
+
+const (
+	CredentialLocationKeyring     CredentialLocation = "keyring"      // In the OS keyring
+	CredentialLocationFile        CredentialLocation = "file"         // In the encrypted credentials file
+	CredentialLocationFallbackKey CredentialLocation = "fallback-key" // Built-in key, protected by the filesystem permissions only (Linux)
+	CredentialLocationMissing     CredentialLocation = "missing"      // Not found
+)
+
+// SessionStorageInfo describes where the credentials of a session are stored.
+type SessionStorageInfo struct {
+	SessionID       string             `json:"sessionId"`       // ID of the session, eg, 'Metaplay Auth'
+	UserType        UserType           `json:"userType"`        // Type of the user (human or machine)
+	KeyringName     string             `json:"keyringName"`     // Name of the OS keyring, eg, 'macOS Keychain'
+	CredentialsFile string             `json:"credentialsFile"` // Path to the file with the encrypted tokens
+	RefreshToken    CredentialLocation `json:"refreshToken"`    // Where the refresh token is stored
+	EncryptionKey   CredentialLocation `json:"encryptionKey"`   // Where the key for encrypting the credentials file is stored
+}
+
+// GetKeyringName returns the name of the OS keyring used on this platform.
+func GetKeyringName() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "macOS Keychain"
+	case "windows":
+		return "Windows Credential Manager"
+	default:
+		return "Secret Service"
+	}
+}
+
+// DescribeSessionStorage returns where the credentials of the session are stored, or nil if
+// there is no such session. The credentials themselves are not read.
+func DescribeSessionStorage(sessionID string) (*SessionStorageInfo, error) {
+	filePath, err := resolvePersistedConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	persistedConfig, err := loadPersistedConfig()
+	if err != nil {
+		return nil, err
+	}
+	sessionState, found := persistedConfig.Sessions[sessionID]
+	if !found {
+		return nil, nil
+	}
+
+	info := &SessionStorageInfo{
+		SessionID:       sessionID,
+		UserType:        sessionState.UserType,
+		KeyringName:     GetKeyringName(),
+		CredentialsFile: filePath,
+		RefreshToken:    CredentialLocationFile,
+		EncryptionKey:   CredentialLocationKeyring,
+	}
+	if sessionState.RefreshTokenInKeyring {
+		info.RefreshToken = CredentialLocationKeyring
+	}
+	if _, err := keyring.Get(keyringService, keyringKey); err != nil {
+		if runtime.GOOS == "linux" {
+			info.EncryptionKey = CredentialLocationFallbackKey
+		} else {
+			info.EncryptionKey = CredentialLocationMissing
+		}
+	}
+	return info, nil
+}
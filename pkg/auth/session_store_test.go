@@ -0,0 +1,99 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package auth
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSessionStateRefreshTokenInKeyring(t *testing.T) {
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	keyring.MockInit()
+
+	tokenSet := &TokenSet{IDToken: "id-token", AccessToken: "access-token", RefreshToken: "refresh-token-secret"}
+	if err := SaveSessionState("Metaplay Auth", UserTypeMachine, tokenSet); err != nil {
+		t.Fatal(err)
+	}
+
+	// Refresh token is in the keyring, not in the credentials file.
+	stored, err := keyring.Get(keyringService, keyringRefreshTokenKeyPrefix+"Metaplay Auth")
+	if err != nil || stored != "refresh-token-secret" {
+		t.Errorf("expected the refresh token in the keyring, got %q (%v)", stored, err)
+	}
+	info, err := DescribeSessionStorage("Metaplay Auth")
+	if err != nil || info == nil {
+		t.Fatalf("DescribeSessionStorage() = %v, %v", info, err)
+	}
+	if info.RefreshToken != CredentialLocationKeyring || info.EncryptionKey != CredentialLocationKeyring {
+		t.Errorf("unexpected storage: %+v", info)
+	}
+
+	// Loading restores the full token set.
+	sessionState, err := LoadSessionState("Metaplay Auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *sessionState.TokenSet != *tokenSet || sessionState.UserType != UserTypeMachine {
+		t.Errorf("got %+v, want %+v", *sessionState.TokenSet, *tokenSet)
+	}
+
+	// Deleting removes the refresh token from the keyring.
+	if err := DeleteSessionState("Metaplay Auth"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.Get(keyringService, keyringRefreshTokenKeyPrefix+"Metaplay Auth"); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("expected the refresh token to be removed from the keyring, got %v", err)
+	}
+}
+
+func TestSessionStateWithoutKeyring(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the credentials file can only be used without a keyring on Linux")
+	}
+	t.Setenv(common.MetaplayHomeEnvVar, t.TempDir())
+	keyring.MockInitWithError(errors.New("no secret service"))
+
+	tokenSet := &TokenSet{AccessToken: "access-token", RefreshToken: "refresh-token-secret"}
+	if err := SaveSessionState("Metaplay Auth", UserTypeHuman, tokenSet); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := DescribeSessionStorage("Metaplay Auth")
+	if err != nil || info == nil {
+		t.Fatalf("DescribeSessionStorage() = %v, %v", info, err)
+	}
+	if info.RefreshToken != CredentialLocationFile || info.EncryptionKey != CredentialLocationFallbackKey {
+		t.Errorf("unexpected storage: %+v", info)
+	}
+
+	// The file is still encrypted.
+	content, err := os.ReadFile(info.CredentialsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "refresh-token-secret") {
+		t.Errorf("refresh token stored in plaintext")
+	}
+
+	sessionState, err := LoadSessionState("Metaplay Auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *sessionState.TokenSet != *tokenSet {
+		t.Errorf("got %+v, want %+v", *sessionState.TokenSet, *tokenSet)
+	}
+
+	// Unknown sessions have no storage.
+	if info, err := DescribeSessionStorage("unknown"); err != nil || info != nil {
+		t.Errorf("DescribeSessionStorage() of an unknown session = %v, %v", info, err)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cistatus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Base URL of the Bitbucket Cloud REST API.
+const bitbucketAPIBaseURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketReporter reports the deployment as a build status on the deployed commit. The
+// Bitbucket API does not allow creating deployments from outside its own deployment steps.
+type bitbucketReporter struct {
+	httpClient  *http.Client
+	apiBaseURL  string
+	info        DeploymentInfo
+	repoName    string // Full name of the repository, eg, 'myteam/mygame'.
+	commitSHA   string
+	pipelineURL string // Link to the pipeline run (can be empty).
+	accessToken string // Repository, project, or workspace access token (if used).
+	username    string // Username for app password authentication (if used).
+	appPassword string // App password (if used).
+}
+
+// Bitbucket commit build status request body.
+type bitbucketBuildStatus struct {
+	Key         string `json:"key"`
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// newBitbucketReporter creates a reporter when running in Bitbucket Pipelines with API
+// credentials available. Returns nil otherwise.
+func newBitbucketReporter(info DeploymentInfo, getenv func(string) string, httpClient *http.Client) *bitbucketReporter {
+	repoName := getenv("BITBUCKET_REPO_FULL_NAME")
+	commitSHA := getenv("BITBUCKET_COMMIT")
+	if getenv("BITBUCKET_BUILD_NUMBER") == "" || repoName == "" || commitSHA == "" {
+		return nil
+	}
+
+	accessToken := getenv("BITBUCKET_ACCESS_TOKEN")
+	username := getenv("BITBUCKET_USERNAME")
+	appPassword := getenv("BITBUCKET_APP_PASSWORD")
+	if accessToken == "" && (username == "" || appPassword == "") {
+		return nil
+	}
+
+	pipelineURL := ""
+	if getenv("BITBUCKET_GIT_HTTP_ORIGIN") != "" {
+		pipelineURL = fmt.Sprintf("%s/pipelines/results/%s", getenv("BITBUCKET_GIT_HTTP_ORIGIN"), getenv("BITBUCKET_BUILD_NUMBER"))
+	}
+
+	return &bitbucketReporter{
+		httpClient:  httpClient,
+		apiBaseURL:  bitbucketAPIBaseURL,
+		info:        info,
+		repoName:    repoName,
+		commitSHA:   commitSHA,
+		pipelineURL: pipelineURL,
+		accessToken: accessToken,
+		username:    username,
+		appPassword: appPassword,
+	}
+}
+
+func (r *bitbucketReporter) Provider() string {
+	return "Bitbucket"
+}
+
+func (r *bitbucketReporter) Start(ctx context.Context) error {
+	return r.postBuildStatus(ctx, "INPROGRESS")
+}
+
+func (r *bitbucketReporter) Finish(ctx context.Context, success bool) error {
+	state := "FAILED"
+	if success {
+		state = "SUCCESSFUL"
+	}
+	return r.postBuildStatus(ctx, state)
+}
+
+func (r *bitbucketReporter) postBuildStatus(ctx context.Context, state string) error {
+	// Link to the environment, falling back to the pipeline run (the URL is required).
+	url := r.info.EnvironmentURL
+	if url == "" {
+		url = r.pipelineURL
+	}
+
+	body, err := json.Marshal(bitbucketBuildStatus{
+		Key:         "metaplay-deploy-" + r.info.Environment,
+		State:       state,
+		Name:        fmt.Sprintf("Deploy to %s", r.info.Environment),
+		URL:         url,
+		Description: r.info.Description,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/repositories/%s/commit/%s/statuses/build", r.apiBaseURL, r.repoName, r.commitSHA)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.accessToken)
+	} else {
+		req.SetBasicAuth(r.username, r.appPassword)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update Bitbucket build status to '%s': %w", state, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to update Bitbucket build status to '%s': HTTP %d: %s", state, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
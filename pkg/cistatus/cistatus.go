@@ -0,0 +1,54 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+// Package cistatus reports the status of deployments back to the CI provider running the CLI,
+// eg, as GitHub Deployments or Bitbucket commit build statuses.
+package cistatus
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout for the individual requests to the CI provider APIs.
+const requestTimeout = 15 * time.Second
+
+// DeploymentInfo describes the deployment whose status is reported.
+type DeploymentInfo struct {
+	Environment    string // Human ID of the target environment, eg, 'lovely-wombats-build-nimbly'.
+	EnvironmentURL string // URL of the deployed environment, eg, the LiveOps Dashboard.
+	IsProduction   bool   // Is the target a production environment?
+	Description    string // Short description of the deployment, eg, 'Deploy mygame:364cff09'.
+}
+
+// DeploymentStatusReporter reports the status of a single deployment to a CI provider.
+type DeploymentStatusReporter interface {
+	// Provider returns the human-readable name of the CI provider, eg, 'GitHub'.
+	Provider() string
+	// Start marks the deployment as in progress.
+	Start(ctx context.Context) error
+	// Finish marks the deployment as succeeded or failed.
+	Finish(ctx context.Context, success bool) error
+}
+
+// DetectDeploymentStatusReporter returns a reporter for the CI provider the CLI is running in,
+// based on the environment variables returned by getenv. Returns nil if not running in a
+// supported CI provider or if the credentials for its API are not available.
+//
+// Supported providers:
+//   - GitHub Actions: requires GITHUB_TOKEN with the 'deployments: write' permission.
+//   - Bitbucket Pipelines: requires BITBUCKET_ACCESS_TOKEN (or BITBUCKET_USERNAME and
+//     BITBUCKET_APP_PASSWORD) with the 'repository:write' scope.
+func DetectDeploymentStatusReporter(info DeploymentInfo, getenv func(string) string) DeploymentStatusReporter {
+	httpClient := &http.Client{Timeout: requestTimeout}
+
+	if reporter := newGitHubReporter(info, getenv, httpClient); reporter != nil {
+		return reporter
+	}
+	if reporter := newBitbucketReporter(info, getenv, httpClient); reporter != nil {
+		return reporter
+	}
+	return nil
+}
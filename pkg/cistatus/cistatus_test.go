@@ -0,0 +1,243 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cistatus
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testDeploymentInfo = DeploymentInfo{
+	Environment:    "lovely-wombats-build-nimbly",
+	EnvironmentURL: "https://lovely-wombats-build-nimbly-admin.p1.metaplay.io",
+	IsProduction:   false,
+	Description:    "Deploy 364cff09",
+}
+
+func makeGetenv(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestDetectDeploymentStatusReporter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "no ci",
+			env:      map[string]string{},
+			expected: "",
+		},
+		{
+			name: "github actions",
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_TOKEN":      "ghs_token",
+				"GITHUB_REPOSITORY": "metaplay/mygame",
+				"GITHUB_SHA":        "364cff09",
+			},
+			expected: "GitHub",
+		},
+		{
+			name: "github actions without token",
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REPOSITORY": "metaplay/mygame",
+				"GITHUB_SHA":        "364cff09",
+			},
+			expected: "",
+		},
+		{
+			name: "github actions with invalid repository",
+			env: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_TOKEN":      "ghs_token",
+				"GITHUB_REPOSITORY": "mygame",
+				"GITHUB_SHA":        "364cff09",
+			},
+			expected: "",
+		},
+		{
+			name: "bitbucket with access token",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER":   "12",
+				"BITBUCKET_REPO_FULL_NAME": "myteam/mygame",
+				"BITBUCKET_COMMIT":         "364cff09",
+				"BITBUCKET_ACCESS_TOKEN":   "token",
+			},
+			expected: "Bitbucket",
+		},
+		{
+			name: "bitbucket with app password",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER":   "12",
+				"BITBUCKET_REPO_FULL_NAME": "myteam/mygame",
+				"BITBUCKET_COMMIT":         "364cff09",
+				"BITBUCKET_USERNAME":       "user",
+				"BITBUCKET_APP_PASSWORD":   "password",
+			},
+			expected: "Bitbucket",
+		},
+		{
+			name: "bitbucket without credentials",
+			env: map[string]string{
+				"BITBUCKET_BUILD_NUMBER":   "12",
+				"BITBUCKET_REPO_FULL_NAME": "myteam/mygame",
+				"BITBUCKET_COMMIT":         "364cff09",
+				"BITBUCKET_USERNAME":       "user",
+			},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := DetectDeploymentStatusReporter(testDeploymentInfo, makeGetenv(tc.env))
+			provider := ""
+			if reporter != nil {
+				provider = reporter.Provider()
+			}
+			if provider != tc.expected {
+				t.Errorf("Expected provider '%s', got '%s'", tc.expected, provider)
+			}
+		})
+	}
+}
+
+func TestBitbucketReporter(t *testing.T) {
+	var states []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repositories/myteam/mygame/commit/364cff09/statuses/build" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected Authorization header '%s'", r.Header.Get("Authorization"))
+		}
+
+		var status bitbucketBuildStatus
+		if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if status.Key != "metaplay-deploy-lovely-wombats-build-nimbly" {
+			t.Errorf("Unexpected key '%s'", status.Key)
+		}
+		if status.URL != testDeploymentInfo.EnvironmentURL {
+			t.Errorf("Unexpected url '%s'", status.URL)
+		}
+		states = append(states, status.State)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	reporter := newBitbucketReporter(testDeploymentInfo, makeGetenv(map[string]string{
+		"BITBUCKET_BUILD_NUMBER":   "12",
+		"BITBUCKET_REPO_FULL_NAME": "myteam/mygame",
+		"BITBUCKET_COMMIT":         "364cff09",
+		"BITBUCKET_ACCESS_TOKEN":   "token",
+	}), server.Client())
+	reporter.apiBaseURL = server.URL
+
+	if err := reporter.Start(t.Context()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := reporter.Finish(t.Context(), false); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	if strings.Join(states, ",") != "INPROGRESS,FAILED" {
+		t.Errorf("Unexpected states: %v", states)
+	}
+}
+
+func TestBitbucketReporterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "insufficient scope")
+	}))
+	defer server.Close()
+
+	reporter := newBitbucketReporter(testDeploymentInfo, makeGetenv(map[string]string{
+		"BITBUCKET_BUILD_NUMBER":   "12",
+		"BITBUCKET_REPO_FULL_NAME": "myteam/mygame",
+		"BITBUCKET_COMMIT":         "364cff09",
+		"BITBUCKET_USERNAME":       "user",
+		"BITBUCKET_APP_PASSWORD":   "password",
+	}), server.Client())
+	reporter.apiBaseURL = server.URL
+
+	err := reporter.Start(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "insufficient scope") {
+		t.Errorf("Expected error with response body, got: %v", err)
+	}
+}
+
+func TestGitHubReporter(t *testing.T) {
+	var states []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghs_token" {
+			t.Errorf("Unexpected Authorization header '%s'", r.Header.Get("Authorization"))
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/metaplay/mygame/deployments":
+			if body["ref"] != "364cff09" || body["environment"] != testDeploymentInfo.Environment {
+				t.Errorf("Unexpected deployment request: %v", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"id": 42}`)
+		case "/api/v3/repos/metaplay/mygame/deployments/42/statuses":
+			if body["environment_url"] != testDeploymentInfo.EnvironmentURL {
+				t.Errorf("Unexpected environment_url: %v", body["environment_url"])
+			}
+			if body["log_url"] != "https://github.example.com/metaplay/mygame/actions/runs/7" {
+				t.Errorf("Unexpected log_url: %v", body["log_url"])
+			}
+			states = append(states, body["state"].(string))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(w, `{"id": 1}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reporter := newGitHubReporter(testDeploymentInfo, makeGetenv(map[string]string{
+		"GITHUB_ACTIONS":    "true",
+		"GITHUB_TOKEN":      "ghs_token",
+		"GITHUB_REPOSITORY": "metaplay/mygame",
+		"GITHUB_SHA":        "364cff09",
+		"GITHUB_API_URL":    server.URL,
+		"GITHUB_SERVER_URL": "https://github.example.com",
+		"GITHUB_RUN_ID":     "7",
+	}), server.Client())
+	if reporter == nil {
+		t.Fatal("Expected GitHub reporter to be created")
+	}
+
+	if err := reporter.Start(t.Context()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := reporter.Finish(t.Context(), true); err != nil {
+		t.Fatalf("Finish failed: %v", err)
+	}
+
+	if strings.Join(states, ",") != "in_progress,success" {
+		t.Errorf("Unexpected states: %v", states)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package cistatus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v86/github"
+)
+
+// gitHubReporter reports the deployment as a GitHub Deployment with deployment statuses.
+type gitHubReporter struct {
+	client       *github.Client
+	info         DeploymentInfo
+	owner        string
+	repo         string
+	commitSHA    string
+	logURL       string // Link to the workflow run (can be empty).
+	deploymentID int64  // ID of the created GitHub Deployment (0 if not created).
+}
+
+// newGitHubReporter creates a reporter when running in GitHub Actions with a GITHUB_TOKEN.
+// Returns nil otherwise.
+func newGitHubReporter(info DeploymentInfo, getenv func(string) string, httpClient *http.Client) *gitHubReporter {
+	token := getenv("GITHUB_TOKEN")
+	repository := getenv("GITHUB_REPOSITORY")
+	commitSHA := getenv("GITHUB_SHA")
+	if getenv("GITHUB_ACTIONS") != "true" || token == "" || commitSHA == "" {
+		return nil
+	}
+
+	owner, repo, found := strings.Cut(repository, "/")
+	if !found || owner == "" || repo == "" {
+		return nil
+	}
+
+	client := github.NewClient(httpClient).WithAuthToken(token)
+
+	// Use the API of the GitHub Enterprise Server, if running on one.
+	if apiURL := getenv("GITHUB_API_URL"); apiURL != "" && apiURL != "https://api.github.com" {
+		enterpriseClient, err := client.WithEnterpriseURLs(apiURL, apiURL)
+		if err != nil {
+			return nil
+		}
+		client = enterpriseClient
+	}
+
+	logURL := ""
+	if serverURL, runID := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_RUN_ID"); serverURL != "" && runID != "" {
+		logURL = fmt.Sprintf("%s/%s/actions/runs/%s", serverURL, repository, runID)
+	}
+
+	return &gitHubReporter{
+		client:    client,
+		info:      info,
+		owner:     owner,
+		repo:      repo,
+		commitSHA: commitSHA,
+		logURL:    logURL,
+	}
+}
+
+func (r *gitHubReporter) Provider() string {
+	return "GitHub"
+}
+
+func (r *gitHubReporter) Start(ctx context.Context) error {
+	// Create the deployment. Required contexts are cleared as the commit statuses of the
+	// workflow itself are still pending while it's running.
+	deployment, _, err := r.client.Repositories.CreateDeployment(ctx, r.owner, r.repo, &github.DeploymentRequest{
+		Ref:                   github.Ptr(r.commitSHA),
+		Environment:           github.Ptr(r.info.Environment),
+		Description:           github.Ptr(r.info.Description),
+		AutoMerge:             github.Ptr(false),
+		RequiredContexts:      &[]string{},
+		ProductionEnvironment: github.Ptr(r.info.IsProduction),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub deployment: %w", err)
+	}
+	r.deploymentID = deployment.GetID()
+
+	return r.createStatus(ctx, "in_progress")
+}
+
+func (r *gitHubReporter) Finish(ctx context.Context, success bool) error {
+	// Nothing to update if the deployment was not created.
+	if r.deploymentID == 0 {
+		return nil
+	}
+
+	state := "failure"
+	if success {
+		state = "success"
+	}
+	return r.createStatus(ctx, state)
+}
+
+func (r *gitHubReporter) createStatus(ctx context.Context, state string) error {
+	request := &github.DeploymentStatusRequest{
+		State:       github.Ptr(state),
+		Description: github.Ptr(r.info.Description),
+	}
+	if r.info.EnvironmentURL != "" {
+		request.EnvironmentURL = github.Ptr(r.info.EnvironmentURL)
+	}
+	if r.logURL != "" {
+		request.LogURL = github.Ptr(r.logURL)
+	}
+
+	_, _, err := r.client.Repositories.CreateDeploymentStatus(ctx, r.owner, r.repo, r.deploymentID, request)
+	if err != nil {
+		return fmt.Errorf("failed to update GitHub deployment status to '%s': %w", state, err)
+	}
+	return nil
+}
@@ -4,13 +4,19 @@
 
 package common
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 const DefaultPortalBaseURL = "https://portal.metaplay.dev"
 
 // Base URL of the Metaplay portal.
 var PortalBaseURL = DefaultPortalBaseURL
 
+// Fallback base URLs of the Metaplay portal, tried in order when the primary one is unreachable.
+var PortalFallbackBaseURLs []string
+
 func init() {
 	// Allow overriding portalBaseURL with an environment variable (for testing purposes)
 	// To test against local portal: set METAPLAYCLI_PORTAL_BASEURL=http://localhost:3000
@@ -18,4 +24,13 @@ func init() {
 	if override != "" {
 		PortalBaseURL = override
 	}
+
+	// Fallback portal base URLs as a comma-separated list, eg,
+	// METAPLAYCLI_PORTAL_FALLBACK_BASEURLS=https://portal-eu.example.com,https://portal-us.example.com
+	for _, fallback := range strings.Split(os.Getenv("METAPLAYCLI_PORTAL_FALLBACK_BASEURLS"), ",") {
+		fallback = strings.TrimSpace(fallback)
+		if fallback != "" {
+			PortalFallbackBaseURLs = append(PortalFallbackBaseURLs, fallback)
+		}
+	}
 }
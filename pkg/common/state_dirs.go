@@ -0,0 +1,179 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Environment variable for relocating all of the CLI's persisted state under a single
+// directory, eg, for hermetic CI runners or for multiple isolated profiles on one machine.
+const MetaplayHomeEnvVar = "METAPLAY_HOME"
+
+// StateDirKind is a kind of persisted state of the CLI. Each kind is stored in its own directory.
+type StateDirKind string
+
+const (
+	StateDirConfig StateDirKind = "config" // Configuration and credentials
+	StateDirState  StateDirKind = "state"  // Persistent state, eg, the onboarding progress
+	StateDirCache  StateDirKind = "cache"  // Cached data that can be safely removed
+)
+
+// AllStateDirKinds lists all the kinds of persisted state of the CLI.
+var AllStateDirKinds = []StateDirKind{StateDirConfig, StateDirState, StateDirCache}
+
+// StateDir is a resolved directory for a kind of persisted state.
+type StateDir struct {
+	Kind   StateDirKind `json:"kind"`
+	Path   string       `json:"path"`
+	Source string       `json:"source"` // What the path was resolved from, eg, 'METAPLAY_HOME' or 'default'
+}
+
+// XDG base directory environment variables and their defaults relative to the home directory.
+var xdgStateDirVars = map[StateDirKind]struct {
+	envVar     string
+	defaultDir string
+}{
+	StateDirConfig: {"XDG_CONFIG_HOME", ".config"},
+	StateDirState:  {"XDG_STATE_HOME", filepath.Join(".local", "state")},
+	StateDirCache:  {"XDG_CACHE_HOME", ".cache"},
+}
+
+// ResolveStateDirPath resolves the directory for the given kind of persisted state, without
+// creating it. The directory is resolved in the following order:
+// - $METAPLAY_HOME/<kind>, if METAPLAY_HOME is set.
+// - On Linux and other Unix-like systems, the XDG base directories, eg, $XDG_CACHE_HOME/metaplay
+// for the cache, defaulting to ~/.config/metaplay, ~/.local/state/metaplay and ~/.cache/metaplay.
+// - On macOS and Windows, the platform-specific application data directories.
+//
+// Older CLI versions always used ~/.config/metaplay on Linux. When XDG_CONFIG_HOME points
+// elsewhere, the configuration and credentials in the old directory are moved to the new one.
+func ResolveStateDirPath(kind StateDirKind) (StateDir, error) {
+	// Only look up the home directory when it is needed, as it can be missing in CI runners.
+	if os.Getenv(MetaplayHomeEnvVar) != "" {
+		return resolveStateDirPath(kind, runtime.GOOS, os.Getenv, ""), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return StateDir{}, fmt.Errorf("failed to get user's home directory: %w", err)
+	}
+	stateDir := resolveStateDirPath(kind, runtime.GOOS, os.Getenv, homeDir)
+
+	xdg := xdgStateDirVars[StateDirConfig]
+	if kind == StateDirConfig && stateDir.Source == xdg.envVar {
+		legacyDir := filepath.Join(homeDir, xdg.defaultDir, "metaplay")
+		if err := migrateLegacyStateDir(legacyDir, stateDir.Path); err != nil {
+			return StateDir{}, fmt.Errorf("failed to migrate %s directory: %w", kind, err)
+		}
+	}
+	return stateDir, nil
+}
+
+// ResolveStateDir resolves (and creates, if needed) the directory for the given kind of
+// persisted state. See ResolveStateDirPath() for how the directory is resolved.
+func ResolveStateDir(kind StateDirKind) (string, error) {
+	stateDir, err := ResolveStateDirPath(kind)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(stateDir.Path, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s directory %s: %w", kind, stateDir.Path, err)
+	}
+	return stateDir.Path, nil
+}
+
+// resolveStateDirPath resolves the directory for the kind of state on the given OS, with
+// the environment variables from getenv.
+func resolveStateDirPath(kind StateDirKind, goos string, getenv func(string) string, homeDir string) StateDir {
+	// METAPLAY_HOME overrides everything.
+	if metaplayHome := getenv(MetaplayHomeEnvVar); metaplayHome != "" {
+		return StateDir{Kind: kind, Path: filepath.Join(metaplayHome, string(kind)), Source: MetaplayHomeEnvVar}
+	}
+
+	switch goos {
+	case "windows":
+		// Windows: Use AppData\Local for application-specific data
+		baseDir := filepath.Join(homeDir, "AppData", "Local", "Metaplay")
+		switch kind {
+		case StateDirState:
+			return StateDir{Kind: kind, Path: filepath.Join(baseDir, "State"), Source: "default"}
+		case StateDirCache:
+			return StateDir{Kind: kind, Path: filepath.Join(baseDir, "Cache"), Source: "default"}
+		default:
+			return StateDir{Kind: kind, Path: baseDir, Source: "default"}
+		}
+	case "darwin":
+		// macOS: Use ~/Library/Application Support for application data and ~/Library/Caches for caches
+		baseDir := filepath.Join(homeDir, "Library", "Application Support", "Metaplay")
+		switch kind {
+		case StateDirState:
+			return StateDir{Kind: kind, Path: filepath.Join(baseDir, "State"), Source: "default"}
+		case StateDirCache:
+			return StateDir{Kind: kind, Path: filepath.Join(homeDir, "Library", "Caches", "Metaplay"), Source: "default"}
+		default:
+			return StateDir{Kind: kind, Path: baseDir, Source: "default"}
+		}
+	default:
+		// Linux and other Unix-like systems: Use the XDG base directories
+		xdg := xdgStateDirVars[kind]
+		if xdgDir := getenv(xdg.envVar); filepath.IsAbs(xdgDir) {
+			return StateDir{Kind: kind, Path: filepath.Join(xdgDir, "metaplay"), Source: xdg.envVar}
+		}
+		return StateDir{Kind: kind, Path: filepath.Join(homeDir, xdg.defaultDir, "metaplay"), Source: "default"}
+	}
+}
+
+// migrateLegacyStateDir moves the files and directories in a state directory written by an
+// older CLI version to the current directory, keeping any that already exist there. The legacy
+// directory is removed if it was fully migrated.
+func migrateLegacyStateDir(legacyDir string, dir string) error {
+	if legacyDir == dir {
+		return nil
+	}
+	entries, err := os.ReadDir(legacyDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := MigrateLegacyStatePath(filepath.Join(legacyDir, entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	// Only succeeds if everything was moved.
+	_ = os.Remove(legacyDir)
+	return nil
+}
+
+// MigrateLegacyStatePath moves a file or directory written by an older CLI version to its
+// current location, unless something already exists in the current location.
+func MigrateLegacyStatePath(legacyPath string, path string) error {
+	if legacyPath == path {
+		return nil
+	}
+	if _, err := os.Stat(legacyPath); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(legacyPath, path); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", legacyPath, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveStateDirPath(t *testing.T) {
+	homeDir := filepath.Join("/", "home", "user")
+	testCases := []struct {
+		name       string
+		kind       StateDirKind
+		goos       string
+		env        map[string]string
+		wantPath   string
+		wantSource string
+	}{
+		{"linux config default", StateDirConfig, "linux", nil, filepath.Join(homeDir, ".config", "metaplay"), "default"},
+		{"linux state default", StateDirState, "linux", nil, filepath.Join(homeDir, ".local", "state", "metaplay"), "default"},
+		{"linux cache default", StateDirCache, "linux", nil, filepath.Join(homeDir, ".cache", "metaplay"), "default"},
+		{"linux xdg cache", StateDirCache, "linux", map[string]string{"XDG_CACHE_HOME": "/var/cache/ci"}, filepath.Join("/var/cache/ci", "metaplay"), "XDG_CACHE_HOME"},
+		{"linux relative xdg ignored", StateDirConfig, "linux", map[string]string{"XDG_CONFIG_HOME": "relative"}, filepath.Join(homeDir, ".config", "metaplay"), "default"},
+		{"darwin config", StateDirConfig, "darwin", nil, filepath.Join(homeDir, "Library", "Application Support", "Metaplay"), "default"},
+		{"darwin cache", StateDirCache, "darwin", nil, filepath.Join(homeDir, "Library", "Caches", "Metaplay"), "default"},
+		{"darwin ignores xdg", StateDirConfig, "darwin", map[string]string{"XDG_CONFIG_HOME": "/xdg"}, filepath.Join(homeDir, "Library", "Application Support", "Metaplay"), "default"},
+		{"windows state", StateDirState, "windows", nil, filepath.Join(homeDir, "AppData", "Local", "Metaplay", "State"), "default"},
+		{"metaplay home", StateDirState, "linux", map[string]string{MetaplayHomeEnvVar: "/profiles/ci", "XDG_STATE_HOME": "/xdg"}, filepath.Join("/profiles/ci", "state"), MetaplayHomeEnvVar},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			got := resolveStateDirPath(tc.kind, tc.goos, getenv, homeDir)
+			if got.Path != tc.wantPath || got.Source != tc.wantSource {
+				t.Errorf("got %s (from %s), want %s (from %s)", got.Path, got.Source, tc.wantPath, tc.wantSource)
+			}
+			if got.Kind != tc.kind {
+				t.Errorf("got kind %s, want %s", got.Kind, tc.kind)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyStatePath(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "config", "onboarding.json")
+	path := filepath.Join(dir, "state", "onboarding.json")
+
+	// Nothing to migrate.
+	if err := MigrateLegacyStatePath(legacyPath, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The legacy file is moved.
+	if err := os.MkdirAll(filepath.Dir(legacyPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacyPath, []byte("legacy"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateLegacyStatePath(legacyPath, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "legacy" {
+		t.Errorf("expected migrated file, got %q (%v)", content, err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("expected legacy file to be removed, got %v", err)
+	}
+
+	// An existing file is not overwritten.
+	if err := os.WriteFile(legacyPath, []byte("older"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateLegacyStatePath(legacyPath, path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "legacy" {
+		t.Errorf("expected existing file to be kept, got %q", content)
+	}
+}
+
+func TestMigrateLegacyStateDir(t *testing.T) {
+	dir := t.TempDir()
+	legacyDir := filepath.Join(dir, "home", ".config", "metaplay")
+	newDir := filepath.Join(dir, "xdg", "metaplay")
+
+	// Nothing to migrate.
+	if err := migrateLegacyStateDir(legacyDir, newDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The credentials and the user config are moved, an existing file is kept.
+	if err := os.MkdirAll(legacyDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(newDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"config.json": "credentials", UserConfigFileName: "legacy"} {
+		if err := os.WriteFile(filepath.Join(legacyDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(newDir, UserConfigFileName), []byte("current"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateLegacyStateDir(legacyDir, newDir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(newDir, "config.json")); err != nil || string(content) != "credentials" {
+		t.Errorf("expected migrated credentials, got %q (%v)", content, err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(newDir, UserConfigFileName)); string(content) != "current" {
+		t.Errorf("expected existing user config to be kept, got %q", content)
+	}
+
+	// The legacy directory is kept while it has files that weren't moved.
+	if _, err := os.Stat(filepath.Join(legacyDir, UserConfigFileName)); err != nil {
+		t.Errorf("expected unmigrated legacy file to be kept, got %v", err)
+	}
+}
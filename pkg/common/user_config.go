@@ -0,0 +1,212 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Name of the per-user settings file in the config directory, eg, ~/.config/metaplay/config.yaml.
+const UserConfigFileName = "config.yaml"
+
+// UserConfig holds the per-user settings of the CLI, so that the same global flags don't need to
+// be repeated on every invocation. Flags and environment variables take precedence over these.
+type UserConfig struct {
+	OutputFormat    string `yaml:"outputFormat,omitempty"`    // Default for the --format flags, eg, 'json'
+	Color           string `yaml:"color,omitempty"`           // Default for --color: 'yes', 'no' or 'auto'
+	ProjectPath     string `yaml:"projectPath,omitempty"`     // Project directory used outside of any project directory
+	TelemetryOptOut bool   `yaml:"telemetryOptOut,omitempty"` // Opt out of the telemetry of the tools run by the CLI, eg, the .NET SDK
+	PortalBaseURL   string `yaml:"portalBaseURL,omitempty"`   // Base URL of the Metaplay portal
+}
+
+// UserConfigSetting describes a setting in the UserConfig for 'metaplay config'.
+type UserConfigSetting struct {
+	Key         string // Key of the setting in the config file, eg, 'outputFormat'
+	Description string // Human-readable description of the setting
+
+	get      func(config *UserConfig) string
+	set      func(config *UserConfig, value string)
+	validate func(value string) error
+}
+
+// Get returns the value of the setting in the config, or an empty string if not set.
+func (setting *UserConfigSetting) Get(config *UserConfig) string {
+	return setting.get(config)
+}
+
+// Set validates and sets the value of the setting in the config. An empty value resets the
+// setting to its default.
+func (setting *UserConfigSetting) Set(config *UserConfig, value string) error {
+	if value != "" {
+		if err := setting.validate(value); err != nil {
+			return err
+		}
+	}
+	setting.set(config, value)
+	return nil
+}
+
+// UserConfigSettings lists all the settings of the UserConfig.
+var UserConfigSettings = []UserConfigSetting{
+	{
+		Key:         "outputFormat",
+		Description: "Default output format of the commands with a --format flag: 'text' or 'json'",
+		get:         func(config *UserConfig) string { return config.OutputFormat },
+		set:         func(config *UserConfig, value string) { config.OutputFormat = value },
+		validate:    validateOneOf("text", "json"),
+	},
+	{
+		Key:         "color",
+		Description: "Whether to color the output: 'yes', 'no' or 'auto'",
+		get:         func(config *UserConfig) string { return config.Color },
+		set:         func(config *UserConfig, value string) { config.Color = value },
+		validate:    validateOneOf("yes", "no", "auto"),
+	},
+	{
+		Key:         "projectPath",
+		Description: "Project directory to use when not running in any project directory",
+		get:         func(config *UserConfig) string { return config.ProjectPath },
+		set:         func(config *UserConfig, value string) { config.ProjectPath = value },
+		validate: func(value string) error {
+			if !filepath.IsAbs(value) {
+				return errors.New("must be an absolute path")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "telemetryOptOut",
+		Description: "Opt out of the telemetry of the tools run by the CLI, eg, the .NET SDK: 'true' or 'false'",
+		get: func(config *UserConfig) string {
+			if !config.TelemetryOptOut {
+				return ""
+			}
+			return "true"
+		},
+		set: func(config *UserConfig, value string) {
+			optOut, _ := strconv.ParseBool(value)
+			config.TelemetryOptOut = optOut
+		},
+		validate: func(value string) error {
+			_, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.New("must be 'true' or 'false'")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "portalBaseURL",
+		Description: "Base URL of the Metaplay portal, eg, 'https://portal.metaplay.dev'",
+		get:         func(config *UserConfig) string { return config.PortalBaseURL },
+		set:         func(config *UserConfig, value string) { config.PortalBaseURL = strings.TrimSuffix(value, "/") },
+		validate: func(value string) error {
+			parsed, err := url.Parse(value)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return errors.New("must be an http(s) URL, eg, 'https://portal.metaplay.dev'")
+			}
+			return nil
+		},
+	},
+}
+
+// FindUserConfigSetting returns the setting with the given key.
+func FindUserConfigSetting(key string) (*UserConfigSetting, error) {
+	for ndx := range UserConfigSettings {
+		if UserConfigSettings[ndx].Key == key {
+			return &UserConfigSettings[ndx], nil
+		}
+	}
+	keys := make([]string, len(UserConfigSettings))
+	for ndx, setting := range UserConfigSettings {
+		keys[ndx] = setting.Key
+	}
+	return nil, fmt.Errorf("unknown setting '%s', valid settings are: %s", key, strings.Join(keys, ", "))
+}
+
+// validateOneOf returns a validator that accepts only the given values.
+func validateOneOf(allowed ...string) func(value string) error {
+	return func(value string) error {
+		for _, candidate := range allowed {
+			if value == candidate {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of: %s", strings.Join(allowed, ", "))
+	}
+}
+
+// ResolveUserConfigFilePath returns the path to the per-user settings file, without creating it.
+func ResolveUserConfigFilePath() (string, error) {
+	configDir, err := ResolveStateDirPath(StateDirConfig)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir.Path, UserConfigFileName), nil
+}
+
+// LoadUserConfig loads the per-user settings. Returns empty settings if the file does not exist.
+func LoadUserConfig() (*UserConfig, error) {
+	filePath, err := ResolveUserConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &UserConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	// Reject unknown keys, so that typos don't go unnoticed.
+	var config UserConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	// Validate the values, as the file may have been edited by hand.
+	for _, setting := range UserConfigSettings {
+		if value := setting.Get(&config); value != "" {
+			if err := setting.validate(value); err != nil {
+				return nil, fmt.Errorf("invalid value for '%s' in %s: %w", setting.Key, filePath, err)
+			}
+		}
+	}
+	return &config, nil
+}
+
+// SaveUserConfig persists the per-user settings.
+func SaveUserConfig(config *UserConfig) error {
+	filePath, err := ResolveUserConfigFilePath()
+	if err != nil {
+		return err
+	}
+	if _, err := ResolveStateDir(StateDirConfig); err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the settings: %w", err)
+	}
+	if err := os.WriteFile(filePath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
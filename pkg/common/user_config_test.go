@@ -0,0 +1,112 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserConfigRoundTrip(t *testing.T) {
+	t.Setenv(MetaplayHomeEnvVar, t.TempDir())
+
+	// Missing file results in empty settings.
+	config, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() with no file failed: %v", err)
+	}
+	if *config != (UserConfig{}) {
+		t.Errorf("expected empty settings, got %+v", *config)
+	}
+
+	config.OutputFormat = "json"
+	config.TelemetryOptOut = true
+	config.PortalBaseURL = "http://localhost:3000"
+	if err := SaveUserConfig(config); err != nil {
+		t.Fatalf("SaveUserConfig() failed: %v", err)
+	}
+
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() failed: %v", err)
+	}
+	if *loaded != *config {
+		t.Errorf("got %+v, want %+v", *loaded, *config)
+	}
+}
+
+func TestLoadUserConfigInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"valid", "outputFormat: json\ncolor: no\n", false},
+		{"unknown key", "outputFormt: json\n", true},
+		{"invalid value", "color: sometimes\n", true},
+		{"relative project path", "projectPath: projects/MyGame\n", true},
+		{"malformed", "outputFormat: [json\n", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(MetaplayHomeEnvVar, t.TempDir())
+			filePath, err := ResolveUserConfigFilePath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filePath, []byte(tc.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = LoadUserConfig()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("LoadUserConfig() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserConfigSettingSet(t *testing.T) {
+	testCases := []struct {
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"outputFormat", "json", "json", false},
+		{"outputFormat", "yaml", "", true},
+		{"color", "auto", "auto", false},
+		{"telemetryOptOut", "true", "true", false},
+		{"telemetryOptOut", "false", "", false},
+		{"telemetryOptOut", "maybe", "", true},
+		{"portalBaseURL", "https://portal.example.com/", "https://portal.example.com", false},
+		{"portalBaseURL", "portal.example.com", "", true},
+	}
+
+	for _, tc := range testCases {
+		setting, err := FindUserConfigSetting(tc.key)
+		if err != nil {
+			t.Fatalf("FindUserConfigSetting(%q) failed: %v", tc.key, err)
+		}
+		config := UserConfig{}
+		err = setting.Set(&config, tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("Set(%q, %q) error = %v, wantErr %v", tc.key, tc.value, err, tc.wantErr)
+		}
+		if got := setting.Get(&config); got != tc.want {
+			t.Errorf("Set(%q, %q) resulted in %q, want %q", tc.key, tc.value, got, tc.want)
+		}
+	}
+
+	if _, err := FindUserConfigSetting("unknown"); err == nil {
+		t.Errorf("FindUserConfigSetting() of an unknown key should fail")
+	}
+}
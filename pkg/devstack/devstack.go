@@ -0,0 +1,426 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+// Package devstack orchestrates a local full-stack development environment: a game server
+// container and a MariaDB database container connected with a private Docker network.
+package devstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	"github.com/metaplay/cli/pkg/envapi"
+	"github.com/rs/zerolog/log"
+)
+
+// Default database image. Must be MariaDB-compatible: the health check uses the image's
+// healthcheck.sh script.
+const DefaultDatabaseImage = "mariadb:11.4"
+
+// Label used to find the resources belonging to a project's stack. The value is the project ID.
+const stackLabel = "io.metaplay.dev-stack"
+
+// Database credentials. The database is only reachable within the stack's network.
+const (
+	databaseName     = "metaplay"
+	databaseUser     = "metaplay"
+	databasePassword = "metaplay"
+)
+
+// Container ports published on the host (on 127.0.0.1).
+var serverPublishedPorts = []string{
+	"5550/tcp", // LiveOps Dashboard & admin API
+	"8585/tcp", // Health probe proxy
+	"8888/tcp", // SystemHttpServer
+	"9090/tcp", // Metrics
+	"9339/tcp", // Game client connections
+}
+
+// Options for the local stack.
+type Options struct {
+	ProjectID     string            // Project human ID, used to name the stack's resources
+	ServerImage   string            // Game server image, eg, 'mygame:364cff09'
+	DatabaseImage string            // Database image (default: DefaultDatabaseImage)
+	ServerEnv     map[string]string // Extra environment variables for the game server
+	ServerArgs    []string          // Extra arguments for the game server
+}
+
+// Stack is a project's local full-stack development environment.
+type Stack struct {
+	opts   Options
+	docker *client.Client
+}
+
+// New creates a handle to the project's local stack. Does not start anything.
+func New(opts Options) (*Stack, error) {
+	if opts.ProjectID == "" {
+		return nil, errors.New("project ID must be specified")
+	}
+	if opts.DatabaseImage == "" {
+		opts.DatabaseImage = DefaultDatabaseImage
+	}
+
+	docker, err := envapi.NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	return &Stack{opts: opts, docker: docker}, nil
+}
+
+// Close releases the Docker client. Does not stop the stack.
+func (s *Stack) Close() {
+	_ = s.docker.Close()
+}
+
+// NetworkName returns the name of the stack's Docker network.
+func (s *Stack) NetworkName() string { return s.opts.ProjectID + "-dev" }
+
+// DatabaseContainerName returns the name of the stack's database container.
+func (s *Stack) DatabaseContainerName() string { return s.opts.ProjectID + "-dev-database" }
+
+// ServerContainerName returns the name of the stack's game server container.
+func (s *Stack) ServerContainerName() string { return s.opts.ProjectID + "-dev-server" }
+
+// DatabaseVolumeName returns the name of the volume holding the database files.
+func (s *Stack) DatabaseVolumeName() string { return s.opts.ProjectID + "-dev-database" }
+
+// labels returns the labels for all the stack's resources.
+func (s *Stack) labels() map[string]string {
+	return map[string]string{stackLabel: s.opts.ProjectID}
+}
+
+// Prepare removes any containers left over from a previous run, creates the network and the
+// database volume (if missing), and pulls the database image (if missing).
+func (s *Stack) Prepare(ctx context.Context) error {
+	if _, err := s.removeContainers(ctx); err != nil {
+		return err
+	}
+
+	// Create the network, unless it already exists.
+	networks, err := s.docker.NetworkList(ctx, network.ListOptions{Filters: filters.NewArgs(filters.Arg("name", s.NetworkName()))})
+	if err != nil {
+		return fmt.Errorf("failed to list docker networks: %w", err)
+	}
+	if !slices.ContainsFunc(networks, func(n network.Summary) bool { return n.Name == s.NetworkName() }) {
+		if _, err := s.docker.NetworkCreate(ctx, s.NetworkName(), network.CreateOptions{Labels: s.labels()}); err != nil {
+			return fmt.Errorf("failed to create docker network %s: %w", s.NetworkName(), err)
+		}
+	}
+
+	// Create the database volume (no-op if it already exists).
+	if _, err := s.docker.VolumeCreate(ctx, volume.CreateOptions{Name: s.DatabaseVolumeName(), Labels: s.labels()}); err != nil {
+		return fmt.Errorf("failed to create docker volume %s: %w", s.DatabaseVolumeName(), err)
+	}
+
+	// Pull the database image, unless it exists locally.
+	if _, err := s.docker.ImageInspect(ctx, s.opts.DatabaseImage); err != nil {
+		log.Debug().Msgf("Pulling database image %s", s.opts.DatabaseImage)
+		reader, err := s.docker.ImagePull(ctx, s.opts.DatabaseImage, image.PullOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to pull database image %s: %w", s.opts.DatabaseImage, err)
+		}
+		defer reader.Close()
+		if _, err := io.Copy(io.Discard, reader); err != nil {
+			return fmt.Errorf("failed to pull database image %s: %w", s.opts.DatabaseImage, err)
+		}
+	}
+
+	return nil
+}
+
+// StartDatabase starts the database container and waits for it to become healthy.
+func (s *Stack) StartDatabase(ctx context.Context) error {
+	config := &container.Config{
+		Image:  s.opts.DatabaseImage,
+		Labels: s.labels(),
+		Env: []string{
+			"MARIADB_DATABASE=" + databaseName,
+			"MARIADB_USER=" + databaseUser,
+			"MARIADB_PASSWORD=" + databasePassword,
+			"MARIADB_RANDOM_ROOT_PASSWORD=1",
+		},
+		Healthcheck: &container.HealthConfig{
+			Test:        []string{"CMD", "healthcheck.sh", "--connect", "--innodb_initialized"},
+			Interval:    2 * time.Second,
+			Timeout:     5 * time.Second,
+			StartPeriod: 5 * time.Second,
+			Retries:     30,
+		},
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode: container.NetworkMode(s.NetworkName()),
+		Mounts: []mount.Mount{
+			{Type: mount.TypeVolume, Source: s.DatabaseVolumeName(), Target: "/var/lib/mysql"},
+		},
+	}
+	if err := s.startContainer(ctx, s.DatabaseContainerName(), config, hostConfig); err != nil {
+		return err
+	}
+
+	return s.waitUntil(ctx, s.DatabaseContainerName(), 2*time.Minute, func(state *container.State) (bool, error) {
+		if state.Health != nil && state.Health.Status == container.Unhealthy {
+			return false, errors.New("database container is unhealthy")
+		}
+		return state.Health != nil && state.Health.Status == container.Healthy, nil
+	})
+}
+
+// StartServer starts the game server container and waits for it to become ready.
+func (s *Stack) StartServer(ctx context.Context) error {
+	exposedPorts := nat.PortSet{}
+	portBindings := nat.PortMap{}
+	for _, port := range serverPublishedPorts {
+		exposedPorts[nat.Port(port)] = struct{}{}
+		portBindings[nat.Port(port)] = []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: strings.TrimSuffix(port, "/tcp")}}
+	}
+
+	config := &container.Config{
+		Image:        s.opts.ServerImage,
+		Labels:       s.labels(),
+		Env:          formatServerEnv(s.opts.ServerEnv),
+		Cmd:          getServerArgs(s.DatabaseContainerName(), s.opts.ServerArgs),
+		ExposedPorts: exposedPorts,
+	}
+	hostConfig := &container.HostConfig{
+		NetworkMode:  container.NetworkMode(s.NetworkName()),
+		PortBindings: portBindings,
+	}
+	if err := s.startContainer(ctx, s.ServerContainerName(), config, hostConfig); err != nil {
+		return err
+	}
+
+	// Poll the SystemHttpServer's readiness endpoint via the published port.
+	httpClient := &http.Client{Timeout: 2 * time.Second}
+	return s.waitUntil(ctx, s.ServerContainerName(), 3*time.Minute, func(state *container.State) (bool, error) {
+		resp, err := httpClient.Get("http://127.0.0.1:8888/isReady")
+		if err != nil {
+			return false, nil
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK, nil
+	})
+}
+
+// StreamLogs streams the combined logs of the stack's containers into the writer, each line
+// prefixed with the container's role, until the context is canceled or the containers exit.
+func (s *Stack) StreamLogs(ctx context.Context, writer io.Writer) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for ndx, target := range []struct{ name, prefix string }{
+		{s.DatabaseContainerName(), "[database] "},
+		{s.ServerContainerName(), "[server] "},
+	} {
+		wg.Go(func() {
+			reader, err := s.docker.ContainerLogs(ctx, target.name, container.LogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+			if err != nil {
+				errs[ndx] = fmt.Errorf("failed to read logs of %s: %w", target.name, err)
+				return
+			}
+			defer reader.Close()
+
+			prefixed := &linePrefixWriter{prefix: target.prefix, writer: writer, mu: &mu}
+			if _, err := stdcopy.StdCopy(prefixed, prefixed, reader); err != nil && ctx.Err() == nil {
+				errs[ndx] = fmt.Errorf("failed to read logs of %s: %w", target.name, err)
+			}
+			prefixed.Flush()
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Down stops and removes the stack's containers and network. The database volume is removed
+// only if removeVolumes is true. Returns the names of the removed containers.
+func (s *Stack) Down(ctx context.Context, removeVolumes bool) ([]string, error) {
+	removed, err := s.removeContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networks, err := s.docker.NetworkList(ctx, network.ListOptions{Filters: s.labelFilter()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker networks: %w", err)
+	}
+	for _, n := range networks {
+		if err := s.docker.NetworkRemove(ctx, n.ID); err != nil {
+			return nil, fmt.Errorf("failed to remove docker network %s: %w", n.Name, err)
+		}
+	}
+
+	if removeVolumes {
+		if err := s.docker.VolumeRemove(ctx, s.DatabaseVolumeName(), true); err != nil && !client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("failed to remove docker volume %s: %w", s.DatabaseVolumeName(), err)
+		}
+	}
+
+	return removed, nil
+}
+
+// labelFilter returns a filter matching the stack's resources.
+func (s *Stack) labelFilter() filters.Args {
+	return filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", stackLabel, s.opts.ProjectID)))
+}
+
+// removeContainers stops and removes all the stack's containers. Returns the names of the
+// removed containers.
+func (s *Stack) removeContainers(ctx context.Context) ([]string, error) {
+	containers, err := s.docker.ContainerList(ctx, container.ListOptions{All: true, Filters: s.labelFilter()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker containers: %w", err)
+	}
+
+	var removed []string
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+
+		// Stop gracefully first so the game server can shut down cleanly.
+		stopTimeout := 30
+		if err := s.docker.ContainerStop(ctx, c.ID, container.StopOptions{Timeout: &stopTimeout}); err != nil {
+			log.Debug().Msgf("Failed to stop container %s: %v", name, err)
+		}
+		if err := s.docker.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err != nil && !client.IsErrNotFound(err) {
+			return nil, fmt.Errorf("failed to remove container %s: %w", name, err)
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
+
+// startContainer creates and starts a container.
+func (s *Stack) startContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig) error {
+	log.Debug().Msgf("Create container: name=%s image=%s", name, config.Image)
+	created, err := s.docker.ContainerCreate(ctx, config, hostConfig, nil, nil, name)
+	if err != nil {
+		return fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+	if err := s.docker.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("failed to start container %s: %w", name, err)
+	}
+	return nil
+}
+
+// waitUntil polls the condition until it returns true, the container exits, or the timeout
+// is reached.
+func (s *Stack) waitUntil(ctx context.Context, containerName string, timeout time.Duration, condition func(state *container.State) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		inspect, err := s.docker.ContainerInspect(ctx, containerName)
+		if err != nil {
+			return fmt.Errorf("failed to inspect container %s: %w", containerName, err)
+		}
+		if !inspect.State.Running {
+			return fmt.Errorf("container %s exited with code %d", containerName, inspect.State.ExitCode)
+		}
+		if done, err := condition(inspect.State); err != nil {
+			return err
+		} else if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for container %s to become ready", containerName)
+		case <-ticker.C:
+		}
+	}
+}
+
+// getServerArgs returns the command line for the game server container, connecting it to the
+// stack's database.
+func getServerArgs(databaseHost string, extraArgs []string) []string {
+	args := []string{
+		"gameserver",                  // Inform entrypoint to start gameserver
+		"-AdminApiListenHost=0.0.0.0", // Listen to all traffic
+		"--Environment:EnableKeyboardInput=false",
+		"--Environment:EnableSystemHttpServer=true",
+		"--Environment:SystemHttpListenHost=0.0.0.0",
+		"--AdminApi:WebRootPath=wwwroot",
+		"--Database:Backend=MySql",
+		"--Database:NumActiveShards=1",
+		"--Database:Shards:0:DatabaseName=" + databaseName,
+		"--Database:Shards:0:ReadWriteHost=" + databaseHost,
+		"--Database:Shards:0:ReadOnlyHost=" + databaseHost,
+		"--Database:Shards:0:UserId=" + databaseUser,
+		"--Database:Shards:0:Password=" + databasePassword,
+	}
+	return append(args, extraArgs...)
+}
+
+// formatServerEnv returns the game server container's environment variables, with the extra
+// variables overriding the defaults.
+func formatServerEnv(extraEnv map[string]string) []string {
+	env := map[string]string{
+		"ASPNETCORE_ENVIRONMENT":      "Development",
+		"METAPLAY_ENVIRONMENT_FAMILY": "Local",
+	}
+	maps.Copy(env, extraEnv)
+
+	result := make([]string, 0, len(env))
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		result = append(result, fmt.Sprintf("%s=%s", name, env[name]))
+	}
+	return result
+}
+
+// linePrefixWriter writes complete lines to the underlying writer, each prefixed with the given
+// prefix. The mutex is shared between the writers of all the containers so that the lines
+// don't interleave.
+type linePrefixWriter struct {
+	prefix  string
+	writer  io.Writer
+	mu      *sync.Mutex
+	pending []byte
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		ndx := slices.Index(w.pending, '\n')
+		if ndx < 0 {
+			break
+		}
+		w.writeLine(w.pending[:ndx+1])
+		w.pending = w.pending[ndx+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any incomplete last line.
+func (w *linePrefixWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.writeLine(append(w.pending, '\n'))
+		w.pending = nil
+	}
+}
+
+func (w *linePrefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = w.writer.Write(append([]byte(w.prefix), line...))
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package devstack
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestGetServerArgs(t *testing.T) {
+	args := getServerArgs("mygame-dev-database", []string{"--Player:Foo=bar"})
+	if args[0] != "gameserver" {
+		t.Errorf("expected 'gameserver' as first argument, got %q", args[0])
+	}
+	for _, want := range []string{"--Database:Backend=MySql", "--Database:Shards:0:ReadWriteHost=mygame-dev-database", "--Database:Shards:0:ReadOnlyHost=mygame-dev-database"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("missing argument %q in %v", want, args)
+		}
+	}
+	if args[len(args)-1] != "--Player:Foo=bar" {
+		t.Errorf("extra arguments must be last, got %v", args)
+	}
+}
+
+func TestFormatServerEnv(t *testing.T) {
+	env := formatServerEnv(map[string]string{"METAPLAY_ENVIRONMENT_FAMILY": "Custom", "MY_VAR": "1"})
+	want := []string{"ASPNETCORE_ENVIRONMENT=Development", "METAPLAY_ENVIRONMENT_FAMILY=Custom", "MY_VAR=1"}
+	if !slices.Equal(env, want) {
+		t.Errorf("formatServerEnv() = %v, want %v", env, want)
+	}
+}
+
+func TestLinePrefixWriter(t *testing.T) {
+	var output bytes.Buffer
+	writer := &linePrefixWriter{prefix: "[server] ", writer: &output, mu: &sync.Mutex{}}
+	_, _ = writer.Write([]byte("first line\nsecond "))
+	_, _ = writer.Write([]byte("line\nincomplete"))
+	if output.String() != "[server] first line\n[server] second line\n" {
+		t.Errorf("unexpected output before flush: %q", output.String())
+	}
+	writer.Flush()
+	if output.String() != "[server] first line\n[server] second line\n[server] incomplete\n" {
+		t.Errorf("unexpected output after flush: %q", output.String())
+	}
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CanaryRollout rolls out a new game server version to a subset of the pods first. It uses the
+// StatefulSet rolling update partitions: only the pods with an ordinal greater than or equal to
+// the partition are updated to the new revision, the rest keep running the previous version.
+type CanaryRollout struct {
+	kubeCli            *KubeClient
+	shardSets          []appsv1.StatefulSet // Shard sets at the time of planning the rollout.
+	replicas           []int32              // Number of pods in each shard set.
+	partitions         []int32              // Canary partition for each shard set.
+	originalPartitions []int32              // Partition of each shard set before the rollout, restored when resuming.
+	startTime          time.Time            // When the canary pods were started.
+}
+
+// NewCanaryRollout plans a canary rollout of roughly canaryPercent percent of the game server pods.
+// The game server must already be deployed and its shard sets must use the RollingUpdate strategy.
+func (targetEnv *TargetEnvironment) NewCanaryRollout(ctx context.Context, canaryPercent int) (*CanaryRollout, error) {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	shardSets, err := fetchGameServerShardSets(ctx, kubeCli, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(shardSets) == 0 {
+		return nil, clierrors.New("Canary deployment requires an existing game server deployment").
+			WithSuggestion("Deploy the game server without --strategy=canary first")
+	}
+
+	replicas := make([]int32, len(shardSets))
+	originalPartitions := make([]int32, len(shardSets))
+	totalPods := int32(0)
+	for ndx, shardSet := range shardSets {
+		if shardSet.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+			return nil, clierrors.Newf("Shard set '%s' uses update strategy '%s', canary deployment requires '%s'", shardSet.Name, shardSet.Spec.UpdateStrategy.Type, appsv1.RollingUpdateStatefulSetStrategyType).
+				WithSuggestion("Deploy without --strategy=canary")
+		}
+		if shardSet.Spec.Replicas != nil {
+			replicas[ndx] = *shardSet.Spec.Replicas
+		}
+		if rollingUpdate := shardSet.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+			originalPartitions[ndx] = *rollingUpdate.Partition
+		}
+		totalPods += replicas[ndx]
+	}
+	if totalPods < 2 {
+		return nil, clierrors.Newf("Canary deployment requires at least 2 game server pods, found %d", totalPods).
+			WithSuggestion("Deploy without --strategy=canary")
+	}
+
+	return &CanaryRollout{
+		kubeCli:            kubeCli,
+		shardSets:          shardSets,
+		replicas:           replicas,
+		partitions:         planCanaryPartitions(replicas, canaryPercent),
+		originalPartitions: originalPartitions,
+	}, nil
+}
+
+// planCanaryPartitions computes the rolling update partition for each shard set so that
+// canaryPercent percent (rounded up) of all the pods get updated. At least one pod is always
+// updated and at least one pod is kept on the old version. The canary pods are spread evenly
+// across the shard sets.
+func planCanaryPartitions(replicas []int32, canaryPercent int) []int32 {
+	totalPods := 0
+	for _, numReplicas := range replicas {
+		totalPods += int(numReplicas)
+	}
+	numCanaryPods := (totalPods*canaryPercent + 99) / 100
+	numCanaryPods = max(1, min(numCanaryPods, totalPods-1))
+
+	// Assign canary pods to shard sets in round-robin order.
+	numCanaryPerSet := make([]int32, len(replicas))
+	for numAssigned := 0; numAssigned < numCanaryPods; {
+		for ndx := range replicas {
+			if numAssigned < numCanaryPods && numCanaryPerSet[ndx] < replicas[ndx] {
+				numCanaryPerSet[ndx]++
+				numAssigned++
+			}
+		}
+	}
+
+	partitions := make([]int32, len(replicas))
+	for ndx := range replicas {
+		partitions[ndx] = replicas[ndx] - numCanaryPerSet[ndx]
+	}
+	return partitions
+}
+
+// NumCanaryPods returns the number of pods that get updated during the canary phase.
+func (c *CanaryRollout) NumCanaryPods() int {
+	numCanaryPods := 0
+	for ndx := range c.shardSets {
+		numCanaryPods += int(c.replicas[ndx] - c.partitions[ndx])
+	}
+	return numCanaryPods
+}
+
+// setPartition sets the rolling update partition of a shard set.
+func (c *CanaryRollout) setPartition(ctx context.Context, shardSetName string, partition int32) error {
+	patch := fmt.Sprintf(`{"spec":{"updateStrategy":{"type":"RollingUpdate","rollingUpdate":{"partition":%d}}}}`, partition)
+	_, err := c.kubeCli.Clientset.AppsV1().StatefulSets(c.kubeCli.Namespace).Patch(ctx, shardSetName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to set rolling update partition of shard set %s: %w", shardSetName, err)
+	}
+	return nil
+}
+
+// Pause prevents any of the game server pods from being updated by setting the partitions
+// past the last pod. Must be called before deploying the new version.
+func (c *CanaryRollout) Pause(ctx context.Context, output *tui.TaskOutput) error {
+	for ndx, shardSet := range c.shardSets {
+		output.AppendLinef("Pausing rollout of shard set %s", shardSet.Name)
+		if err := c.setPartition(ctx, shardSet.Name, c.replicas[ndx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start lets the canary pods be updated to the new version.
+func (c *CanaryRollout) Start(ctx context.Context, output *tui.TaskOutput) error {
+	for ndx, shardSet := range c.shardSets {
+		numCanaryPods := c.replicas[ndx] - c.partitions[ndx]
+		output.AppendLinef("Updating %d pod(s) of shard set %s", numCanaryPods, shardSet.Name)
+		if err := c.setPartition(ctx, shardSet.Name, c.partitions[ndx]); err != nil {
+			return err
+		}
+	}
+	c.startTime = time.Now()
+	return nil
+}
+
+// Resume restores the rolling update partitions the shard sets had before the rollout, which
+// normally lets all the game server pods be updated to the latest version. Used both for
+// promoting the canary and for restoring the normal rollout behavior after a rollback.
+func (c *CanaryRollout) Resume(ctx context.Context, output *tui.TaskOutput) error {
+	for ndx, shardSet := range c.shardSets {
+		output.AppendLinef("Resuming rollout of shard set %s", shardSet.Name)
+		if err := c.setPartition(ctx, shardSet.Name, c.originalPartitions[ndx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveCanaryPodStatuses resolves the status of each canary pod. Pods that are not yet
+// running the updated revision are reported as pending.
+func (c *CanaryRollout) resolveCanaryPodStatuses(ctx context.Context) ([]ShardPodStatus, []corev1.Pod, error) {
+	var statuses []ShardPodStatus
+	var canaryPods []corev1.Pod
+	for ndx, plannedShardSet := range c.shardSets {
+		// Fetch the up-to-date shard set to know the latest revision.
+		shardSet, err := c.kubeCli.Clientset.AppsV1().StatefulSets(c.kubeCli.Namespace).Get(ctx, plannedShardSet.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch shard set %s: %w", plannedShardSet.Name, err)
+		}
+
+		for ordinal := c.partitions[ndx]; ordinal < c.replicas[ndx]; ordinal++ {
+			podName := fmt.Sprintf("%s-%d", shardSet.Name, ordinal)
+			pod, err := c.kubeCli.Clientset.CoreV1().Pods(c.kubeCli.Namespace).Get(ctx, podName, metav1.GetOptions{})
+			if err != nil {
+				statuses = append(statuses, ShardPodStatus{Name: podName, Phase: PhasePending, Message: "Pod not found"})
+				continue
+			}
+			if pod.Labels["controller-revision-hash"] != shardSet.Status.UpdateRevision || pod.DeletionTimestamp != nil {
+				statuses = append(statuses, ShardPodStatus{Name: podName, Phase: PhasePending, Message: "Pod is being updated"})
+				continue
+			}
+
+			status := resolvePodStatus(*pod)
+			statuses = append(statuses, ShardPodStatus{Name: podName, Phase: status.Phase, Message: status.Message})
+			canaryPods = append(canaryPods, *pod)
+		}
+	}
+	return statuses, canaryPods, nil
+}
+
+// WaitForCanaryPodsReady waits until all the canary pods are running the new version and are ready.
+func (c *CanaryRollout) WaitForCanaryPodsReady(ctx context.Context, output *tui.TaskOutput, timeout time.Duration) error {
+	startTime := time.Now()
+	for time.Since(startTime) < timeout {
+		statuses, _, err := c.resolveCanaryPodStatuses(ctx)
+		if err != nil {
+			return err
+		}
+
+		allReady := true
+		statusLines := []string{fmt.Sprintf("Canary pods (%d):", len(statuses))}
+		for _, status := range statuses {
+			statusLines = append(statusLines, fmt.Sprintf("  %s: %s [%s]", status.Name, status.Phase, status.Message))
+			if status.Phase == PhaseFailed {
+				output.SetHeaderLines(statusLines)
+				return fmt.Errorf("canary pod %s failed: %s", status.Name, status.Message)
+			}
+			if status.Phase != PhaseReady {
+				allReady = false
+			}
+		}
+		output.SetHeaderLines(statusLines)
+
+		if allReady {
+			return nil
+		}
+
+		// Wait a bit to check again (slower updates in non-interactive mode to avoid spamming the log).
+		if err := sleepWithContext(ctx, pollInterval(500*time.Millisecond, 5*time.Second)); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("timeout waiting for canary pods to be ready")
+}
+
+// Response of the admin API 'api/serverErrors' endpoint. Only the fields used here are included.
+type serverErrorsResponse struct {
+	Errors []serverErrorEntry `json:"errors"`
+}
+
+// serverErrorEntry is a single error logged by the game server.
+type serverErrorEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Message   string    `json:"message"`
+}
+
+// countServerErrorsSince returns the number of server errors reported by the admin API since
+// the given time and the latest such error message.
+func countServerErrorsSince(response serverErrorsResponse, since time.Time) (int, string) {
+	numErrors := 0
+	latestMessage := ""
+	var latestTimestamp time.Time
+	for _, serverError := range response.Errors {
+		if serverError.Timestamp.Before(since) {
+			continue
+		}
+		numErrors++
+		if serverError.Timestamp.After(latestTimestamp) {
+			latestTimestamp = serverError.Timestamp
+			latestMessage = fmt.Sprintf("%s: %s", serverError.Source, serverError.Message)
+		}
+	}
+	return numErrors, latestMessage
+}
+
+// MonitorCanary observes the canary pods for the given duration. Fails if any canary pod fails
+// or restarts, or if the game server reports more than maxErrors errors via the admin API. The
+// error check is skipped if adminClient is nil. Failing to fetch the errors fails the canary, so
+// that a canary is never promoted without the check having been made.
+func (c *CanaryRollout) MonitorCanary(ctx context.Context, output *tui.TaskOutput, adminClient *metahttp.Client, duration time.Duration, maxErrors int) error {
+	// Record the initial restart counts of the canary pods.
+	_, canaryPods, err := c.resolveCanaryPodStatuses(ctx)
+	if err != nil {
+		return err
+	}
+	initialRestarts := map[string]int32{}
+	for _, pod := range canaryPods {
+		if containerStatus := findShardServerContainer(pod); containerStatus != nil {
+			initialRestarts[pod.Name] = containerStatus.RestartCount
+		}
+	}
+
+	monitorStartTime := time.Now()
+	for time.Since(monitorStartTime) < duration {
+		statuses, canaryPods, err := c.resolveCanaryPodStatuses(ctx)
+		if err != nil {
+			return err
+		}
+
+		remaining := (duration - time.Since(monitorStartTime)).Round(time.Second)
+		statusLines := []string{fmt.Sprintf("Monitoring canary pods (%v remaining):", remaining)}
+		for _, status := range statuses {
+			statusLines = append(statusLines, fmt.Sprintf("  %s: %s [%s]", status.Name, status.Phase, status.Message))
+			if status.Phase == PhaseFailed {
+				output.SetHeaderLines(statusLines)
+				return fmt.Errorf("canary pod %s failed: %s", status.Name, status.Message)
+			}
+		}
+
+		// Check that the canary pods have not restarted.
+		for _, pod := range canaryPods {
+			containerStatus := findShardServerContainer(pod)
+			if containerStatus != nil && containerStatus.RestartCount > initialRestarts[pod.Name] {
+				output.SetHeaderLines(statusLines)
+				return fmt.Errorf("canary pod %s restarted %d time(s)", pod.Name, containerStatus.RestartCount-initialRestarts[pod.Name])
+			}
+		}
+
+		// Check the server error rate from the admin API.
+		if adminClient != nil {
+			response, err := metahttp.Get[serverErrorsResponse](adminClient, "/api/serverErrors")
+			if err != nil {
+				output.SetHeaderLines(statusLines)
+				log.Debug().Msgf("Failed to fetch server errors from admin API: %v", err)
+				return clierrors.Wrap(err, "Failed to fetch the server errors from the admin API for the canary error check").
+					WithSuggestion("The game server may not support the error check, use --canary-skip-error-check to only monitor the canary pods")
+			}
+			numErrors, latestMessage := countServerErrorsSince(response, c.startTime)
+			statusLines = append(statusLines, fmt.Sprintf("Server errors since canary start: %d (max %d)", numErrors, maxErrors))
+			if numErrors > maxErrors {
+				output.SetHeaderLines(statusLines)
+				return fmt.Errorf("canary exceeded the maximum number of server errors (%d > %d), latest: %s", numErrors, maxErrors, strings.TrimSpace(latestMessage))
+			}
+		} else {
+			statusLines = append(statusLines, "Server error check skipped (--canary-skip-error-check)")
+		}
+
+		output.SetHeaderLines(statusLines)
+
+		// Wait a bit to check again (slower updates in non-interactive mode to avoid spamming the log).
+		if err := sleepWithContext(ctx, pollInterval(5*time.Second, 15*time.Second)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pollInterval returns the interval for polling the canary pods: slower in non-interactive
+// mode to avoid spamming the log.
+func pollInterval(interactive, nonInteractive time.Duration) time.Duration {
+	if tui.IsInteractiveMode() {
+		return interactive
+	}
+	return nonInteractive
+}
+
+// sleepWithContext waits for the given duration, or until the context is canceled.
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanCanaryPartitions(t *testing.T) {
+	testCases := []struct {
+		name          string
+		replicas      []int32
+		canaryPercent int
+		expected      []int32
+	}{
+		{"SingleShardSet", []int32{10}, 10, []int32{9}},
+		{"RoundsUp", []int32{10}, 15, []int32{8}},
+		{"AtLeastOnePod", []int32{4}, 1, []int32{3}},
+		{"KeepsOneOldPod", []int32{4}, 99, []int32{1}},
+		{"SpreadAcrossShardSets", []int32{4, 4}, 50, []int32{2, 2}},
+		{"SingletonShardSets", []int32{1, 1, 1}, 10, []int32{0, 1, 1}},
+		{"SkipsFullShardSets", []int32{1, 5}, 50, []int32{0, 3}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, planCanaryPartitions(tc.replicas, tc.canaryPercent))
+		})
+	}
+}
+
+func TestCountServerErrorsSince(t *testing.T) {
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	response := serverErrorsResponse{
+		Errors: []serverErrorEntry{
+			{Timestamp: since.Add(-time.Minute), Source: "GlobalStateManager", Message: "before canary"},
+			{Timestamp: since.Add(2 * time.Minute), Source: "PlayerActor", Message: "latest error"},
+			{Timestamp: since.Add(time.Minute), Source: "PlayerActor", Message: "first error"},
+		},
+	}
+
+	numErrors, latestMessage := countServerErrorsSince(response, since)
+	assert.Equal(t, 2, numErrors)
+	assert.Equal(t, "PlayerActor: latest error", latestMessage)
+
+	numErrors, latestMessage = countServerErrorsSince(serverErrorsResponse{}, since)
+	assert.Equal(t, 0, numErrors)
+	assert.Empty(t, latestMessage)
+}
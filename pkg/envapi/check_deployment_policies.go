@@ -0,0 +1,279 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Game server container ports that must be reachable for the deployment to work.
+const (
+	gameServerClientPort = 9339 // Client-facing game traffic.
+	gameServerAdminPort  = 5550 // LiveOps Dashboard & admin API.
+)
+
+// deploymentPolicyWarning is a potential misconfiguration of the NetworkPolicies or
+// PodDisruptionBudgets that affect the game server pods.
+type deploymentPolicyWarning struct {
+	Message    string // What is wrong.
+	Suggestion string // How to fix it.
+}
+
+// checkNetworkPolicies checks that the NetworkPolicies selecting the game server pods allow
+// ingress traffic to the client and admin ports. Only pods that expose the port are checked.
+func checkNetworkPolicies(policies []networkingv1.NetworkPolicy, pods []corev1.Pod) []deploymentPolicyWarning {
+	var warnings []deploymentPolicyWarning
+	for _, check := range []struct {
+		port int32
+		name string
+	}{
+		{gameServerClientPort, "client"},
+		{gameServerAdminPort, "admin"},
+	} {
+		var blockedPods []string
+		var blockingPolicies []string
+		for _, pod := range pods {
+			container := findContainerExposingPort(pod, check.port)
+			if container == nil {
+				continue
+			}
+			allowed, isolatingPolicies := isIngressAllowed(policies, pod, container, check.port)
+			if !allowed {
+				blockedPods = append(blockedPods, pod.Name)
+				for _, policyName := range isolatingPolicies {
+					if !slices.Contains(blockingPolicies, policyName) {
+						blockingPolicies = append(blockingPolicies, policyName)
+					}
+				}
+			}
+		}
+
+		if len(blockedPods) > 0 {
+			warnings = append(warnings, deploymentPolicyWarning{
+				Message: fmt.Sprintf("NetworkPolicies %s do not allow %s traffic to port %d of pods %s",
+					strings.Join(blockingPolicies, ", "), check.name, check.port, strings.Join(blockedPods, ", ")),
+				Suggestion: fmt.Sprintf("Add an ingress rule allowing TCP port %d to one of the policies, or remove the policies if they are not needed", check.port),
+			})
+		}
+	}
+	return warnings
+}
+
+// findContainerExposingPort returns the container of the pod that declares the given container
+// port, or nil if there is none.
+func findContainerExposingPort(pod corev1.Pod, port int32) *corev1.Container {
+	for ndx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[ndx]
+		for _, containerPort := range container.Ports {
+			if containerPort.ContainerPort == port {
+				return container
+			}
+		}
+	}
+	return nil
+}
+
+// isIngressAllowed checks whether the NetworkPolicies allow ingress traffic into the given
+// container port of the pod. Returns the names of the policies that isolate the pod if the
+// traffic is not allowed. The sources of the traffic ('from') are not evaluated as the
+// load balancer addresses are not known, so any rule that covers the port counts as allowing.
+func isIngressAllowed(policies []networkingv1.NetworkPolicy, pod corev1.Pod, container *corev1.Container, port int32) (bool, []string) {
+	isIsolated := false
+	var isolatingPolicies []string
+	for _, policy := range policies {
+		if !policyAppliesToIngress(policy) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			log.Debug().Msgf("Invalid pod selector in NetworkPolicy %s: %v", policy.Name, err)
+			continue
+		}
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		// Pod is selected by an ingress policy, so only traffic allowed by some rule gets through.
+		isIsolated = true
+		isolatingPolicies = append(isolatingPolicies, policy.Name)
+		for _, rule := range policy.Spec.Ingress {
+			if ingressRuleCoversPort(rule, container, port) {
+				return true, nil
+			}
+		}
+	}
+	return !isIsolated, isolatingPolicies
+}
+
+// policyAppliesToIngress checks whether the policy restricts ingress traffic. Policies without
+// explicit policyTypes always apply to ingress.
+func policyAppliesToIngress(policy networkingv1.NetworkPolicy) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true
+	}
+	return slices.Contains(policy.Spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+}
+
+// ingressRuleCoversPort checks whether the ingress rule allows TCP traffic to the container port.
+// Ports can be referred to by number or by the container port name.
+func ingressRuleCoversPort(rule networkingv1.NetworkPolicyIngressRule, container *corev1.Container, port int32) bool {
+	if len(rule.Ports) == 0 {
+		return true
+	}
+	for _, policyPort := range rule.Ports {
+		if policyPort.Protocol != nil && *policyPort.Protocol != corev1.ProtocolTCP {
+			continue
+		}
+		if policyPort.Port == nil {
+			return true
+		}
+		if policyPort.Port.Type == intstr.Int {
+			endPort := policyPort.Port.IntVal
+			if policyPort.EndPort != nil {
+				endPort = *policyPort.EndPort
+			}
+			if port >= policyPort.Port.IntVal && port <= endPort {
+				return true
+			}
+		} else {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == policyPort.Port.StrVal && containerPort.ContainerPort == port {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// checkPodDisruptionBudgets checks that the PodDisruptionBudgets selecting the game server shard
+// sets allow at least one pod to be evicted. Otherwise, node drains (eg, during cluster upgrades)
+// hang until someone manually intervenes. This mostly happens with singleton shard sets.
+func checkPodDisruptionBudgets(pdbs []policyv1.PodDisruptionBudget, shardSets []appsv1.StatefulSet) []deploymentPolicyWarning {
+	var warnings []deploymentPolicyWarning
+	for _, pdb := range pdbs {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			log.Debug().Msgf("Invalid selector in PodDisruptionBudget %s: %v", pdb.Name, err)
+			continue
+		}
+
+		// Count the pods of the shard sets selected by the budget.
+		numPods := 0
+		var shardSetNames []string
+		for _, shardSet := range shardSets {
+			if !selector.Matches(labels.Set(shardSet.Spec.Template.Labels)) {
+				continue
+			}
+			numReplicas := 1
+			if shardSet.Spec.Replicas != nil {
+				numReplicas = int(*shardSet.Spec.Replicas)
+			}
+			numPods += numReplicas
+			shardSetNames = append(shardSetNames, shardSet.Name)
+		}
+		if numPods == 0 {
+			continue
+		}
+
+		numAllowedDisruptions, err := resolveAllowedDisruptions(pdb, numPods)
+		if err != nil {
+			log.Debug().Msgf("Unable to resolve allowed disruptions of PodDisruptionBudget %s: %v", pdb.Name, err)
+			continue
+		}
+
+		if numAllowedDisruptions <= 0 {
+			warnings = append(warnings, deploymentPolicyWarning{
+				Message: fmt.Sprintf("PodDisruptionBudget %s does not allow evicting any pods of shard sets %s (%d pods total), which blocks node maintenance",
+					pdb.Name, strings.Join(shardSetNames, ", "), numPods),
+				Suggestion: "Use 'maxUnavailable: 1' for the budget, or remove it if the shard sets are singletons",
+			})
+		}
+	}
+	return warnings
+}
+
+// resolveAllowedDisruptions computes how many of the numPods pods the budget allows to be evicted
+// when all of them are healthy.
+func resolveAllowedDisruptions(pdb policyv1.PodDisruptionBudget, numPods int) (int, error) {
+	if pdb.Spec.MaxUnavailable != nil {
+		return intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MaxUnavailable, numPods, true)
+	}
+	if pdb.Spec.MinAvailable != nil {
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(pdb.Spec.MinAvailable, numPods, true)
+		if err != nil {
+			return 0, err
+		}
+		return numPods - minAvailable, nil
+	}
+	// Budget without either field does not restrict evictions.
+	return numPods, nil
+}
+
+// checkDeploymentPolicies checks the NetworkPolicies and PodDisruptionBudgets affecting the game
+// server for common misconfigurations. The problems are only reported as warnings in the output
+// as they do not necessarily prevent the game server from working.
+func (targetEnv *TargetEnvironment) checkDeploymentPolicies(ctx context.Context, output *tui.TaskOutput) error {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	pods, err := FetchGameServerPods(ctx, kubeCli)
+	if err != nil {
+		return err
+	}
+
+	shardSets, err := fetchGameServerShardSets(ctx, kubeCli, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var warnings []deploymentPolicyWarning
+
+	// The policy resources may not be accessible with all credentials, so failures to list them are not fatal.
+	networkPolicies, err := kubeCli.Clientset.NetworkingV1().NetworkPolicies(kubeCli.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		output.AppendLinef("Unable to list NetworkPolicies, skipping check: %v", err)
+	} else {
+		output.AppendLinef("Found %d NetworkPolicies", len(networkPolicies.Items))
+		warnings = append(warnings, checkNetworkPolicies(networkPolicies.Items, pods)...)
+	}
+
+	pdbs, err := kubeCli.Clientset.PolicyV1().PodDisruptionBudgets(kubeCli.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		output.AppendLinef("Unable to list PodDisruptionBudgets, skipping check: %v", err)
+	} else {
+		output.AppendLinef("Found %d PodDisruptionBudgets", len(pdbs.Items))
+		warnings = append(warnings, checkPodDisruptionBudgets(pdbs.Items, shardSets)...)
+	}
+
+	if len(warnings) > 0 {
+		footerLines := []string{}
+		for _, warning := range warnings {
+			footerLines = append(footerLines, fmt.Sprintf("WARNING: %s", warning.Message))
+			footerLines = append(footerLines, fmt.Sprintf("  Suggestion: %s", warning.Suggestion))
+		}
+		output.SetFooterLines(footerLines)
+	}
+
+	return nil
+}
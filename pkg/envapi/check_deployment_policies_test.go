@@ -0,0 +1,151 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var testServerLabels = map[string]string{"app": "metaplay-server"}
+
+func newTestServerPod(name string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: testServerLabels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "shard-server",
+					Ports: []corev1.ContainerPort{
+						{Name: "game", ContainerPort: gameServerClientPort},
+						{Name: "admin", ContainerPort: gameServerAdminPort},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestIngressPolicy(name string, rules ...networkingv1.NetworkPolicyIngressRule) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: testServerLabels},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}
+}
+
+func newTestPortRule(port intstr.IntOrString) networkingv1.NetworkPolicyIngressRule {
+	return networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+	}
+}
+
+func TestCheckNetworkPolicies_NoPolicies(t *testing.T) {
+	warnings := checkNetworkPolicies(nil, []corev1.Pod{newTestServerPod("all-0")})
+	assert.Empty(t, warnings)
+}
+
+func TestCheckNetworkPolicies_DenyAll(t *testing.T) {
+	policies := []networkingv1.NetworkPolicy{newTestIngressPolicy("deny-all")}
+	warnings := checkNetworkPolicies(policies, []corev1.Pod{newTestServerPod("all-0")})
+	require.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0].Message, "deny-all")
+	assert.Contains(t, warnings[0].Message, "port 9339")
+	assert.Contains(t, warnings[1].Message, "port 5550")
+}
+
+func TestCheckNetworkPolicies_AllowedByNumberAndName(t *testing.T) {
+	policies := []networkingv1.NetworkPolicy{
+		newTestIngressPolicy("allow-client", newTestPortRule(intstr.FromInt32(gameServerClientPort))),
+		newTestIngressPolicy("allow-admin", newTestPortRule(intstr.FromString("admin"))),
+	}
+	warnings := checkNetworkPolicies(policies, []corev1.Pod{newTestServerPod("all-0")})
+	assert.Empty(t, warnings)
+}
+
+func TestCheckNetworkPolicies_OnlyClientAllowed(t *testing.T) {
+	policies := []networkingv1.NetworkPolicy{
+		newTestIngressPolicy("allow-client", newTestPortRule(intstr.FromInt32(gameServerClientPort))),
+	}
+	warnings := checkNetworkPolicies(policies, []corev1.Pod{newTestServerPod("all-0")})
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Message, "admin traffic")
+}
+
+func TestCheckNetworkPolicies_EgressOnlyPolicyIgnored(t *testing.T) {
+	policy := newTestIngressPolicy("egress-only")
+	policy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeEgress}
+	warnings := checkNetworkPolicies([]networkingv1.NetworkPolicy{policy}, []corev1.Pod{newTestServerPod("all-0")})
+	assert.Empty(t, warnings)
+}
+
+func newTestShardSet(name string, replicas int32) appsv1.StatefulSet {
+	return appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: testServerLabels},
+			},
+		},
+	}
+}
+
+func newTestPDB(name string, minAvailable, maxUnavailable *intstr.IntOrString) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: testServerLabels},
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+		},
+	}
+}
+
+func TestCheckPodDisruptionBudgets_SingletonMinAvailable(t *testing.T) {
+	minAvailable := intstr.FromInt32(1)
+	pdbs := []policyv1.PodDisruptionBudget{newTestPDB("server-pdb", &minAvailable, nil)}
+	warnings := checkPodDisruptionBudgets(pdbs, []appsv1.StatefulSet{newTestShardSet("all", 1)})
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].Message, "server-pdb")
+	assert.Contains(t, warnings[0].Message, "all")
+}
+
+func TestCheckPodDisruptionBudgets_ZeroMaxUnavailable(t *testing.T) {
+	maxUnavailable := intstr.FromString("0%")
+	pdbs := []policyv1.PodDisruptionBudget{newTestPDB("server-pdb", nil, &maxUnavailable)}
+	warnings := checkPodDisruptionBudgets(pdbs, []appsv1.StatefulSet{newTestShardSet("all", 3)})
+	require.Len(t, warnings, 1)
+}
+
+func TestCheckPodDisruptionBudgets_AllowsEviction(t *testing.T) {
+	minAvailable := intstr.FromInt32(1)
+	maxUnavailable := intstr.FromInt32(1)
+	pdbs := []policyv1.PodDisruptionBudget{
+		newTestPDB("min-available", &minAvailable, nil),
+		newTestPDB("max-unavailable", nil, &maxUnavailable),
+	}
+	warnings := checkPodDisruptionBudgets(pdbs, []appsv1.StatefulSet{newTestShardSet("all", 2)})
+	assert.Empty(t, warnings)
+}
+
+func TestCheckPodDisruptionBudgets_NonMatchingSelector(t *testing.T) {
+	minAvailable := intstr.FromInt32(1)
+	pdb := newTestPDB("other-pdb", &minAvailable, nil)
+	pdb.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}
+	warnings := checkPodDisruptionBudgets([]policyv1.PodDisruptionBudget{pdb}, []appsv1.StatefulSet{newTestShardSet("all", 1)})
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// EnvironmentCheckStatus is the outcome of a single environment health check.
+type EnvironmentCheckStatus string
+
+const (
+	CheckPassed  EnvironmentCheckStatus = "pass"
+	CheckFailed  EnvironmentCheckStatus = "fail"
+	CheckSkipped EnvironmentCheckStatus = "skip" // Not run because a check it depends on failed
+)
+
+// EnvironmentCheckResult is the result of a single environment health check.
+type EnvironmentCheckResult struct {
+	Name       string                 `json:"name"`
+	Status     EnvironmentCheckStatus `json:"status"`
+	Message    string                 `json:"message"`
+	DurationMs int64                  `json:"duration_ms"`
+}
+
+// Timeout of each individual environment health check.
+const environmentCheckTimeout = 15 * time.Second
+
+// CheckEnvironmentHealth runs the same checks as WaitForServerToBeReady() once, without waiting
+// for anything to become ready: the game server pods are healthy, the domain names resolve, the
+// game server accepts client connections, and the LiveOps Dashboard responds to HTTP requests.
+func (targetEnv *TargetEnvironment) CheckEnvironmentHealth(ctx context.Context) ([]EnvironmentCheckResult, error) {
+	envDetails, err := targetEnv.GetDetails()
+	if err != nil {
+		return nil, err
+	}
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []EnvironmentCheckResult{}
+	runCheck := func(name string, dependsOn *EnvironmentCheckResult, check func(ctx context.Context) (string, error)) *EnvironmentCheckResult {
+		if dependsOn != nil && dependsOn.Status != CheckPassed {
+			results = append(results, EnvironmentCheckResult{
+				Name:    name,
+				Status:  CheckSkipped,
+				Message: fmt.Sprintf("Skipped because '%s' did not pass", dependsOn.Name),
+			})
+			return &results[len(results)-1]
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, environmentCheckTimeout)
+		defer cancel()
+		startTime := time.Now()
+		message, err := check(checkCtx)
+		result := EnvironmentCheckResult{
+			Name:       name,
+			Status:     CheckPassed,
+			Message:    message,
+			DurationMs: time.Since(startTime).Milliseconds(),
+		}
+		if err != nil {
+			result.Status = CheckFailed
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+		return &results[len(results)-1]
+	}
+
+	// Game server pods.
+	runCheck("Game server pods are healthy", nil, func(ctx context.Context) (string, error) {
+		shardSets, err := FetchGameServerShardSetStatuses(ctx, kubeCli)
+		if err != nil {
+			return "", err
+		}
+		return evaluateShardSetHealth(shardSets)
+	})
+
+	// Client-facing networking. Copy the result before running more checks, as appending to the
+	// results may move them.
+	serverHostname := envDetails.Deployment.ServerHostname
+	serverPort := 9339 // \todo should use envDetails.Deployment.ServerPorts but its occasionally empty
+	serverDomain := *runCheck("Game server domain name resolves", nil, func(ctx context.Context) (string, error) {
+		return checkDomainResolves(ctx, serverHostname)
+	})
+	runCheck("Game server serves clients", &serverDomain, func(ctx context.Context) (string, error) {
+		if err := attemptTLSConnection(serverHostname, serverPort); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Connected to %s:%d", serverHostname, serverPort), nil
+	})
+
+	// Admin interface.
+	adminHostname := envDetails.Deployment.AdminHostname
+	adminDomain := *runCheck("LiveOps Dashboard domain name resolves", nil, func(ctx context.Context) (string, error) {
+		return checkDomainResolves(ctx, adminHostname)
+	})
+	runCheck("LiveOps Dashboard serves traffic", &adminDomain, func(ctx context.Context) (string, error) {
+		url := "https://" + adminHostname
+		status, err := attemptHTTPRequest(ctx, newHTTPCheckClient(), url)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s responded with %s", url, status), nil
+	})
+
+	return results, nil
+}
+
+// evaluateShardSetHealth checks that there is at least one shard set and that all the expected
+// pods are ready.
+func evaluateShardSetHealth(shardSets []ShardSetStatus) (string, error) {
+	if len(shardSets) == 0 {
+		return "", fmt.Errorf("no game server shard sets found")
+	}
+
+	numPods := 0
+	numReady := 0
+	var firstNotReady *ShardPodStatus
+	for _, shardSet := range shardSets {
+		for ndx, pod := range shardSet.Pods {
+			numPods++
+			if pod.Phase == PhaseReady {
+				numReady++
+			} else if firstNotReady == nil {
+				firstNotReady = &shardSet.Pods[ndx]
+			}
+		}
+	}
+
+	if numPods == 0 {
+		return "", fmt.Errorf("no game server pods expected, the shard sets are scaled to zero")
+	}
+	if firstNotReady != nil {
+		return "", fmt.Errorf("%d of %d pods ready, %s is %s: %s", numReady, numPods, firstNotReady.Name, firstNotReady.Phase, firstNotReady.Message)
+	}
+	return fmt.Sprintf("%d of %d pods ready", numReady, numPods), nil
+}
+
+// checkDomainResolves checks that the hostname resolves to an address.
+func checkDomainResolves(ctx context.Context, hostname string) (string, error) {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %v", hostname, err)
+	}
+	return fmt.Sprintf("%s resolves to %s", hostname, addrs[0]), nil
+}
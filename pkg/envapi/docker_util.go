@@ -5,9 +5,11 @@
 package envapi
 
 import (
+	"archive/tar"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -16,17 +18,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/rs/zerolog/log"
 )
 
+// Label that marks an image built from a git working tree with uncommitted changes.
+const DirtyImageLabel = "io.metaplay.dirty"
+
 // Metadata about a Metaplay docker image.
 type MetaplayImageInfo struct {
 	ImageID      string    // Docker image ID
@@ -37,7 +44,9 @@ type MetaplayImageInfo struct {
 	SdkVersion   string    // Metaplay SDK version (label io.metaplay.sdk_version).
 	CommitID     string    // Commit ID, e.g., git hash (label io.metaplay.commit_id).
 	BuildNumber  string    // Build number (label io.metaplay.build_number).
+	IsDirty      bool      // Was the image built from a git working tree with uncommitted changes (label io.metaplay.dirty)?
 	CreatedTime  time.Time // Image creation timestamp.
+	Size         int64     // Image size in bytes (zero if unknown).
 	OS           string    // OS the image is built for (e.g., "linux") - can be added if needed elsewhere
 	Architecture string    // Architecture the image is built for (e.g., "amd64") - can be added if needed elsewhere
 }
@@ -64,6 +73,9 @@ func newMetaplayImageInfo(imageID, repoTag, tag string, labels map[string]string
 		return nil, fmt.Errorf("missing required label 'io.metaplay.build_number' in image %s (tag %s)", imageID, repoTag)
 	}
 
+	// The dirty label is optional as it's only added by newer CLI versions.
+	isDirty := labels[DirtyImageLabel] == "true"
+
 	// Create and return the MetaplayImageInfo
 	return &MetaplayImageInfo{
 		ImageID:      imageID,
@@ -74,6 +86,7 @@ func newMetaplayImageInfo(imageID, repoTag, tag string, labels map[string]string
 		SdkVersion:   sdkVersion,
 		CommitID:     commitID,
 		BuildNumber:  buildNumber,
+		IsDirty:      isDirty,
 		CreatedTime:  createdTime,
 		OS:           os,
 		Architecture: architecture,
@@ -166,6 +179,37 @@ func ReadLocalDockerImageMetadata(imageRefString string) (*MetaplayImageInfo, er
 	return newMetaplayImageInfoFromInspect(imageInspect.ID, imageRefString, parsedRef, imageInspect)
 }
 
+// ReadLocalDockerImageFile reads a single file from a local Docker image. The file is copied
+// out of a temporary container that is never started.
+func ReadLocalDockerImageFile(ctx context.Context, imageRef string, filePath string) ([]byte, error) {
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = dockerClient.Close() }()
+
+	created, err := dockerClient.ContainerCreate(ctx, &container.Config{Image: imageRef}, nil, nil, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container from local docker image '%s': %w", imageRef, err)
+	}
+	defer func() {
+		_ = dockerClient.ContainerRemove(context.Background(), created.ID, container.RemoveOptions{Force: true})
+	}()
+
+	reader, _, err := dockerClient.CopyFromContainer(ctx, created.ID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from docker image '%s': %w", filePath, imageRef, err)
+	}
+	defer reader.Close()
+
+	// The file is returned as a tar archive with a single entry.
+	tarReader := tar.NewReader(reader)
+	if _, err := tarReader.Next(); err != nil {
+		return nil, fmt.Errorf("failed to read %s from docker image '%s': %w", filePath, imageRef, err)
+	}
+	return io.ReadAll(tarReader)
+}
+
 // RemoteDockerImageDigests holds the two content digests that can identify a remote image. Which
 // one matches a local image's reported ID depends on the local Docker daemon's image store:
 // the legacy store reports the config digest as the image ID, while the containerd store reports
@@ -242,8 +286,35 @@ func isRemoteImageNotFound(err error) bool {
 // FetchRemoteDockerImageMetadata retrieves the labels of an image in a remote Docker registry.
 func FetchRemoteDockerImageMetadata(creds *DockerCredentials, imageRef string) (*MetaplayImageInfo, error) {
 	log.Debug().Msgf("Fetch image metadata for a remote container image: %s", imageRef)
+	img, ref, err := fetchRemoteDockerImage(creds, imageRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the image configuration blob
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image config file '%s': %w", imageRef, err)
+	}
+
+	// Use the helper function to convert config file data to MetaplayImageInfo
+	// ImageID can be obtained from the image's digest for uniqueness.
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image digest for %s: %w", imageRef, err)
+	}
+	imageID := digest.String()
+	// The 'tag' for newMetaplayImageInfo is the specific identifier part of the reference (tag or digest).
+	tag := ref.Identifier()
+
+	return newMetaplayImageInfo(imageID, imageRef, tag, cfg.Config.Labels, cfg.Created.Time, cfg.OS, cfg.Architecture)
+}
+
+// fetchRemoteDockerImage resolves a remote container image. The image layers are fetched lazily
+// when accessed.
+func fetchRemoteDockerImage(creds *DockerCredentials, imageRef string) (v1.Image, name.Reference, error) {
 	if imageRef == "" {
-		return nil, fmt.Errorf("empty image reference")
+		return nil, nil, fmt.Errorf("empty image reference")
 	}
 
 	// Create a registry authenticator using the provided credentials
@@ -255,36 +326,21 @@ func FetchRemoteDockerImageMetadata(creds *DockerCredentials, imageRef string) (
 	// Parse the image reference (name + tag or digest)
 	ref, err := name.ParseReference(imageRef, name.WithDefaultRegistry(creds.RegistryURL))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse remote docker image reference '%s': %w", imageRef, err)
+		return nil, nil, fmt.Errorf("failed to parse remote docker image reference '%s': %w", imageRef, err)
 	}
 
 	// Retrieve the image manifest and associated metadata
 	desc, err := remote.Get(ref, remote.WithAuth(authenticator))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get remote docker image descriptor '%s': %w", imageRef, err)
+		return nil, nil, fmt.Errorf("failed to get remote docker image descriptor '%s': %w", imageRef, err)
 	}
 
-	// Fetch the image configuration blob
+	// Resolve the image from the descriptor
 	img, err := desc.Image()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get remote docker image from descriptor '%s': %w", imageRef, err)
-	}
-	cfg, err := img.ConfigFile()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get remote docker image config file '%s': %w", imageRef, err)
-	}
-
-	// Use the helper function to convert config file data to MetaplayImageInfo
-	// ImageID can be obtained from the image's digest for uniqueness.
-	digest, err := img.Digest()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get image digest for %s: %w", imageRef, err)
+		return nil, nil, fmt.Errorf("failed to get remote docker image from descriptor '%s': %w", imageRef, err)
 	}
-	imageID := digest.String()
-	// The 'tag' for newMetaplayImageInfo is the specific identifier part of the reference (tag or digest).
-	tag := ref.Identifier()
-
-	return newMetaplayImageInfo(imageID, imageRef, tag, cfg.Config.Labels, cfg.Created.Time, cfg.OS, cfg.Architecture)
+	return img, ref, nil
 }
 
 // newMetaplayImageInfoFromInspect creates a MetaplayImageInfo from an image inspect response.
@@ -309,7 +365,7 @@ func newMetaplayImageInfoFromInspect(imageID string, repoTag string, imageRef na
 	}
 
 	// Convert ImageInspect data to MetaplayImageInfo
-	return newMetaplayImageInfo(
+	imageInfo, err := newMetaplayImageInfo(
 		imageID,
 		repoTag,
 		imageRef.Identifier(), // from name.ParseReference(repoTag)
@@ -318,6 +374,23 @@ func newMetaplayImageInfoFromInspect(imageID string, repoTag string, imageRef na
 		imageInspect.Os,
 		imageInspect.Architecture,
 	)
+	if err != nil {
+		return nil, err
+	}
+	imageInfo.Size = imageInspect.Size
+	return imageInfo, nil
+}
+
+// isRegistryQualifiedImageName returns true if the image name starts with a registry host,
+// eg, 'docker.io/library/ubuntu:latest' or 'localhost:5000/myimage:tag'. Following docker's
+// rules, the first path component is a registry host if it contains a '.' or a ':', or is
+// 'localhost'.
+func isRegistryQualifiedImageName(imageName string) bool {
+	firstComponent, _, hasPath := strings.Cut(imageName, "/")
+	if !hasPath {
+		return false
+	}
+	return strings.ContainsAny(firstComponent, ".:") || firstComponent == "localhost"
 }
 
 // ReadLocalDockerImagesByProjectID retrieves metadata for all local Docker images
@@ -356,9 +429,9 @@ func ReadLocalDockerImagesByProjectID(projectID string) ([]MetaplayImageInfo, er
 				continue
 			}
 
-			// Skip tags that look like fully qualified names (e.g., 'docker.io/library/ubuntu:latest' or 'customregistry/myimage:tag').
-			// We only want to handle simple/local tags (e.g., 'myimage:latest').
-			if strings.Contains(repoTag, "/") {
+			// Skip tags that refer to a registry (e.g., 'docker.io/library/ubuntu:latest' or 'localhost:5000/myimage:tag').
+			// We only want to handle local tags (e.g., 'myimage:latest' or 'mygame/server:latest' when using 'imageNaming').
+			if isRegistryQualifiedImageName(repoTag) {
 				continue
 			}
 
@@ -58,3 +58,23 @@ func TestIsRemoteImageNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestIsRegistryQualifiedImageName(t *testing.T) {
+	testCases := []struct {
+		imageName string
+		want      bool
+	}{
+		{"mygame:364cff09", false},
+		{"mygame/server:364cff09", false},
+		{"docker.io/library/ubuntu:latest", true},
+		{"localhost:5000/mygame:364cff09", true},
+		{"localhost/mygame:364cff09", true},
+		{"123456789.dkr.ecr.eu-west-1.amazonaws.com/mygame:364cff09", true},
+	}
+
+	for _, tc := range testCases {
+		if got := isRegistryQualifiedImageName(tc.imageName); got != tc.want {
+			t.Errorf("isRegistryQualifiedImageName(%q) = %v, want %v", tc.imageName, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/rs/zerolog/log"
+)
+
+// Annotations that buildx uses to attach attestation manifests to the images in an image index.
+const (
+	attestationReferenceTypeAnnotation   = "vnd.docker.reference.type"
+	attestationReferenceDigestAnnotation = "vnd.docker.reference.digest"
+	attestationManifestReferenceType     = "attestation-manifest"
+	inTotoPredicateTypeAnnotation        = "in-toto.io/predicate-type"
+)
+
+// In-toto predicate types of the supported attestations.
+const (
+	predicateTypeSPDX         = "https://spdx.dev/Document"
+	predicateTypeCycloneDX    = "https://cyclonedx.org/bom"
+	predicateTypeProvenance02 = "https://slsa.dev/provenance/v0.2"
+	predicateTypeProvenance1  = "https://slsa.dev/provenance/v1"
+)
+
+// ImageAttestations are the supply-chain attestations attached to an image by buildx (with
+// 'metaplay build image --sbom --provenance'). Nil fields mean the attestation is missing.
+type ImageAttestations struct {
+	SBOM       *ImageSBOM       // Software bill of materials, if any
+	Provenance *ImageProvenance // SLSA provenance, if any
+}
+
+// ImageSBOM summarizes the SBOM attestation of an image.
+type ImageSBOM struct {
+	Format      string // Format and version of the SBOM, eg, 'SPDX-2.3' or 'CycloneDX-1.5'
+	NumPackages int    // Number of packages (or components) listed in the SBOM
+}
+
+// ImageProvenance summarizes the SLSA provenance attestation of an image.
+type ImageProvenance struct {
+	SlsaVersion string // Version of the SLSA provenance format, eg, 'v0.2' or 'v1'
+	BuilderID   string // Identifier of the builder, if recorded
+	BuildType   string // Type of the build, eg, 'https://mobyproject.org/buildkit@v1'
+}
+
+// inTotoStatement is an in-toto attestation statement, as stored in the attestation layers.
+type inTotoStatement struct {
+	Type          string `json:"_type"`
+	PredicateType string `json:"predicateType"`
+	Subject       []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	Predicate json.RawMessage `json:"predicate"`
+}
+
+// FetchRemoteDockerImageAttestations fetches and verifies the attestations of an image in a remote
+// Docker registry. Each attestation must refer to one of the image's platform manifests, and its
+// in-toto subject must match that manifest's digest. Images without attestations (including
+// single-arch images, which cannot carry them) return empty attestations.
+func FetchRemoteDockerImageAttestations(creds *DockerCredentials, imageRef string) (*ImageAttestations, error) {
+	log.Debug().Msgf("Fetch attestations of remote container image: %s", imageRef)
+	if imageRef == "" {
+		return nil, fmt.Errorf("empty image reference")
+	}
+
+	// Create a registry authenticator using the provided credentials.
+	authenticator := authn.FromConfig(authn.AuthConfig{
+		Username: creds.Username,
+		Password: creds.Password,
+	})
+
+	// Parse the image reference (name + tag or digest).
+	ref, err := name.ParseReference(imageRef, name.WithDefaultRegistry(creds.RegistryURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote docker image reference '%s': %w", imageRef, err)
+	}
+
+	desc, err := remote.Get(ref, remote.WithAuth(authenticator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image descriptor '%s': %w", imageRef, err)
+	}
+
+	// Attestations are stored in the image index, so single-arch images have none.
+	attestations := &ImageAttestations{}
+	if !desc.MediaType.IsIndex() {
+		return attestations, nil
+	}
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image index '%s': %w", imageRef, err)
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image index manifest '%s': %w", imageRef, err)
+	}
+
+	// Collect the digests of the platform manifests, which the attestations refer to.
+	imageDigests := map[string]bool{}
+	for _, manifest := range indexManifest.Manifests {
+		if manifest.Annotations[attestationReferenceTypeAnnotation] != attestationManifestReferenceType {
+			imageDigests[manifest.Digest.String()] = true
+		}
+	}
+
+	for _, manifest := range indexManifest.Manifests {
+		if manifest.Annotations[attestationReferenceTypeAnnotation] != attestationManifestReferenceType {
+			continue
+		}
+
+		subjectDigest := manifest.Annotations[attestationReferenceDigestAnnotation]
+		if !imageDigests[subjectDigest] {
+			return nil, fmt.Errorf("attestation manifest %s refers to unknown image manifest '%s'", manifest.Digest, subjectDigest)
+		}
+
+		attestationImage, err := index.Image(manifest.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attestation manifest %s: %w", manifest.Digest, err)
+		}
+		if err := readAttestationManifest(attestationImage, subjectDigest, attestations); err != nil {
+			return nil, fmt.Errorf("invalid attestation manifest %s: %w", manifest.Digest, err)
+		}
+	}
+
+	return attestations, nil
+}
+
+// readAttestationManifest reads the in-toto statements of an attestation manifest into the
+// attestations. The layer contents are verified against their digests when read.
+func readAttestationManifest(attestationImage v1.Image, subjectDigest string, attestations *ImageAttestations) error {
+	manifest, err := attestationImage.Manifest()
+	if err != nil {
+		return err
+	}
+
+	for _, layerDesc := range manifest.Layers {
+		predicateType := layerDesc.Annotations[inTotoPredicateTypeAnnotation]
+		if predicateType == "" {
+			continue
+		}
+
+		layer, err := attestationImage.LayerByDigest(layerDesc.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to get attestation layer %s: %w", layerDesc.Digest, err)
+		}
+		reader, err := layer.Uncompressed()
+		if err != nil {
+			return fmt.Errorf("failed to read attestation layer %s: %w", layerDesc.Digest, err)
+		}
+		content, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read attestation layer %s: %w", layerDesc.Digest, err)
+		}
+
+		if err := parseAttestationStatement(content, subjectDigest, attestations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAttestationStatement parses an in-toto statement, checks that its subject is the image
+// with the given manifest digest, and stores the summary of a supported predicate into the
+// attestations. Unsupported predicate types are ignored.
+func parseAttestationStatement(content []byte, subjectDigest string, attestations *ImageAttestations) error {
+	var statement inTotoStatement
+	if err := json.Unmarshal(content, &statement); err != nil {
+		return fmt.Errorf("failed to parse in-toto statement: %w", err)
+	}
+
+	// The statement must be about the image that the attestation manifest refers to.
+	algorithm, hex, _ := strings.Cut(subjectDigest, ":")
+	subjectFound := false
+	for _, subject := range statement.Subject {
+		if subject.Digest[algorithm] == hex {
+			subjectFound = true
+			break
+		}
+	}
+	if !subjectFound {
+		return fmt.Errorf("%s attestation subject does not match image %s", statement.PredicateType, subjectDigest)
+	}
+
+	switch statement.PredicateType {
+	case predicateTypeSPDX:
+		var predicate struct {
+			SpdxVersion string            `json:"spdxVersion"`
+			Packages    []json.RawMessage `json:"packages"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return fmt.Errorf("failed to parse SPDX document: %w", err)
+		}
+		attestations.SBOM = &ImageSBOM{Format: predicate.SpdxVersion, NumPackages: len(predicate.Packages)}
+
+	case predicateTypeCycloneDX:
+		var predicate struct {
+			SpecVersion string            `json:"specVersion"`
+			Components  []json.RawMessage `json:"components"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return fmt.Errorf("failed to parse CycloneDX document: %w", err)
+		}
+		attestations.SBOM = &ImageSBOM{Format: "CycloneDX-" + predicate.SpecVersion, NumPackages: len(predicate.Components)}
+
+	case predicateTypeProvenance02:
+		var predicate struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			BuildType string `json:"buildType"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return fmt.Errorf("failed to parse SLSA provenance: %w", err)
+		}
+		attestations.Provenance = &ImageProvenance{SlsaVersion: "v0.2", BuilderID: predicate.Builder.ID, BuildType: predicate.BuildType}
+
+	case predicateTypeProvenance1:
+		var predicate struct {
+			BuildDefinition struct {
+				BuildType string `json:"buildType"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+		}
+		if err := json.Unmarshal(statement.Predicate, &predicate); err != nil {
+			return fmt.Errorf("failed to parse SLSA provenance: %w", err)
+		}
+		attestations.Provenance = &ImageProvenance{SlsaVersion: "v1", BuilderID: predicate.RunDetails.Builder.ID, BuildType: predicate.BuildDefinition.BuildType}
+
+	default:
+		log.Debug().Msgf("Ignoring attestation with unsupported predicate type '%s'", statement.PredicateType)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+)
+
+const testSubjectDigest = "sha256:1a2b3c4d"
+
+func TestParseAttestationStatementSPDX(t *testing.T) {
+	content := `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://spdx.dev/Document",
+		"subject": [{"name": "pkg:docker/mygame@364cff09", "digest": {"sha256": "1a2b3c4d"}}],
+		"predicate": {"spdxVersion": "SPDX-2.3", "packages": [{"name": "dotnet"}, {"name": "openssl"}]}
+	}`
+
+	attestations := &ImageAttestations{}
+	if err := parseAttestationStatement([]byte(content), testSubjectDigest, attestations); err != nil {
+		t.Fatalf("parseAttestationStatement() failed: %v", err)
+	}
+	if attestations.SBOM == nil || attestations.SBOM.Format != "SPDX-2.3" || attestations.SBOM.NumPackages != 2 {
+		t.Errorf("unexpected SBOM: %+v", attestations.SBOM)
+	}
+	if attestations.Provenance != nil {
+		t.Errorf("unexpected provenance: %+v", attestations.Provenance)
+	}
+}
+
+func TestParseAttestationStatementProvenance(t *testing.T) {
+	content := `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://slsa.dev/provenance/v0.2",
+		"subject": [{"name": "pkg:docker/mygame@364cff09", "digest": {"sha256": "1a2b3c4d"}}],
+		"predicate": {"builder": {"id": "https://github.com/metaplay/game/actions/runs/1"}, "buildType": "https://mobyproject.org/buildkit@v1"}
+	}`
+
+	attestations := &ImageAttestations{}
+	if err := parseAttestationStatement([]byte(content), testSubjectDigest, attestations); err != nil {
+		t.Fatalf("parseAttestationStatement() failed: %v", err)
+	}
+	want := ImageProvenance{SlsaVersion: "v0.2", BuilderID: "https://github.com/metaplay/game/actions/runs/1", BuildType: "https://mobyproject.org/buildkit@v1"}
+	if attestations.Provenance == nil || *attestations.Provenance != want {
+		t.Errorf("provenance = %+v, want %+v", attestations.Provenance, want)
+	}
+}
+
+func TestParseAttestationStatementSubjectMismatch(t *testing.T) {
+	content := `{
+		"_type": "https://in-toto.io/Statement/v0.1",
+		"predicateType": "https://spdx.dev/Document",
+		"subject": [{"name": "pkg:docker/mygame@364cff09", "digest": {"sha256": "ffffffff"}}],
+		"predicate": {"spdxVersion": "SPDX-2.3", "packages": []}
+	}`
+
+	attestations := &ImageAttestations{}
+	if err := parseAttestationStatement([]byte(content), testSubjectDigest, attestations); err == nil {
+		t.Error("expected an error for mismatching subject")
+	}
+	if attestations.SBOM != nil {
+		t.Errorf("unexpected SBOM: %+v", attestations.SBOM)
+	}
+}
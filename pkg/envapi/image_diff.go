@@ -0,0 +1,248 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/rs/zerolog/log"
+)
+
+// Kinds of file changes between two images.
+const (
+	ImageFileAdded    = "added"
+	ImageFileRemoved  = "removed"
+	ImageFileModified = "modified"
+)
+
+// ImageLayerInfo describes a single layer of a container image.
+type ImageLayerInfo struct {
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`                // Compressed size of the layer in bytes.
+	CreatedBy string `json:"createdBy,omitempty"` // Dockerfile instruction that created the layer (if known).
+}
+
+// ImageFileChange describes a file that differs between two images.
+type ImageFileChange struct {
+	Path      string `json:"path"`
+	Change    string `json:"change"` // One of ImageFileAdded, ImageFileRemoved, or ImageFileModified.
+	SizeA     int64  `json:"sizeA"`
+	SizeB     int64  `json:"sizeB"`
+	SizeDelta int64  `json:"sizeDelta"`
+}
+
+// ImageDiff is the difference between two container images: image A is the baseline and
+// image B the one being compared against it.
+type ImageDiff struct {
+	ImageA          string            `json:"imageA"`
+	ImageB          string            `json:"imageB"`
+	SizeA           int64             `json:"sizeA"` // Total compressed size of image A layers.
+	SizeB           int64             `json:"sizeB"` // Total compressed size of image B layers.
+	SizeDelta       int64             `json:"sizeDelta"`
+	NumSharedLayers int               `json:"numSharedLayers"`
+	AddedLayers     []ImageLayerInfo  `json:"addedLayers"`
+	RemovedLayers   []ImageLayerInfo  `json:"removedLayers"`
+	FileChanges     []ImageFileChange `json:"fileChanges,omitempty"` // Only populated when comparing files, sorted by largest size change first.
+}
+
+// imageFileEntry is the size and content hash of a regular file in an image filesystem.
+type imageFileEntry struct {
+	Size   int64
+	Digest [sha256.Size]byte
+}
+
+// FetchRemoteDockerImageDiff compares two remote container images. Layers are compared by digest.
+// If compareFiles is true, the flattened filesystems of both images are downloaded and compared
+// file-by-file, which can take a while for large images.
+func FetchRemoteDockerImageDiff(creds *DockerCredentials, imageRefA, imageRefB string, compareFiles bool) (*ImageDiff, error) {
+	log.Debug().Msgf("Compute image diff between %s and %s", imageRefA, imageRefB)
+
+	imgA, _, err := fetchRemoteDockerImage(creds, imageRefA)
+	if err != nil {
+		return nil, err
+	}
+	imgB, _, err := fetchRemoteDockerImage(creds, imageRefB)
+	if err != nil {
+		return nil, err
+	}
+
+	layersA, err := readImageLayers(imgA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layers of image '%s': %w", imageRefA, err)
+	}
+	layersB, err := readImageLayers(imgB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layers of image '%s': %w", imageRefB, err)
+	}
+
+	diff := diffImageLayers(layersA, layersB)
+	diff.ImageA = imageRefA
+	diff.ImageB = imageRefB
+
+	if compareFiles {
+		filesA, err := readImageFiles(imgA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read filesystem of image '%s': %w", imageRefA, err)
+		}
+		filesB, err := readImageFiles(imgB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read filesystem of image '%s': %w", imageRefB, err)
+		}
+		diff.FileChanges = diffImageFiles(filesA, filesB)
+	}
+
+	return diff, nil
+}
+
+// readImageLayers returns the layers of the image, annotated with the Dockerfile instructions
+// from the image config history.
+func readImageLayers(img v1.Image) ([]ImageLayerInfo, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	// History entries for empty layers (eg, ENV or LABEL) have no corresponding layer.
+	var createdBy []string
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range cfg.History {
+		if !entry.EmptyLayer {
+			createdBy = append(createdBy, entry.CreatedBy)
+		}
+	}
+
+	result := make([]ImageLayerInfo, 0, len(layers))
+	for ndx, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, err
+		}
+		size, err := layer.Size()
+		if err != nil {
+			return nil, err
+		}
+		info := ImageLayerInfo{
+			Digest: digest.String(),
+			Size:   size,
+		}
+		if len(createdBy) == len(layers) {
+			info.CreatedBy = createdBy[ndx]
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// readImageFiles reads the flattened filesystem of the image and returns the regular files in it
+// keyed by their absolute path.
+func readImageFiles(img v1.Image) (map[string]imageFileEntry, error) {
+	reader := mutate.Extract(img)
+	defer reader.Close()
+
+	files := map[string]imageFileEntry{}
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, tarReader); err != nil {
+			return nil, err
+		}
+		entry := imageFileEntry{Size: header.Size}
+		copy(entry.Digest[:], hasher.Sum(nil))
+		files[path.Join("/", strings.TrimPrefix(header.Name, "./"))] = entry
+	}
+	return files, nil
+}
+
+// diffImageLayers compares the layers of two images by their digests. Does not fill in the image names.
+func diffImageLayers(layersA, layersB []ImageLayerInfo) *ImageDiff {
+	diff := &ImageDiff{
+		AddedLayers:   []ImageLayerInfo{},
+		RemovedLayers: []ImageLayerInfo{},
+	}
+
+	isInA := map[string]bool{}
+	for _, layer := range layersA {
+		diff.SizeA += layer.Size
+		isInA[layer.Digest] = true
+	}
+	isInB := map[string]bool{}
+	for _, layer := range layersB {
+		diff.SizeB += layer.Size
+		isInB[layer.Digest] = true
+		if isInA[layer.Digest] {
+			diff.NumSharedLayers++
+		} else {
+			diff.AddedLayers = append(diff.AddedLayers, layer)
+		}
+	}
+	for _, layer := range layersA {
+		if !isInB[layer.Digest] {
+			diff.RemovedLayers = append(diff.RemovedLayers, layer)
+		}
+	}
+	diff.SizeDelta = diff.SizeB - diff.SizeA
+
+	return diff
+}
+
+// diffImageFiles compares the files of two image filesystems. Returns the changed files sorted
+// by the largest absolute size change first, and by path for equal changes.
+func diffImageFiles(filesA, filesB map[string]imageFileEntry) []ImageFileChange {
+	changes := []ImageFileChange{}
+	for filePath, fileB := range filesB {
+		fileA, found := filesA[filePath]
+		if !found {
+			changes = append(changes, ImageFileChange{Path: filePath, Change: ImageFileAdded, SizeB: fileB.Size})
+		} else if fileA != fileB {
+			changes = append(changes, ImageFileChange{Path: filePath, Change: ImageFileModified, SizeA: fileA.Size, SizeB: fileB.Size})
+		}
+	}
+	for filePath, fileA := range filesA {
+		if _, found := filesB[filePath]; !found {
+			changes = append(changes, ImageFileChange{Path: filePath, Change: ImageFileRemoved, SizeA: fileA.Size})
+		}
+	}
+
+	for ndx := range changes {
+		changes[ndx].SizeDelta = changes[ndx].SizeB - changes[ndx].SizeA
+	}
+
+	slices.SortFunc(changes, func(a, b ImageFileChange) int {
+		absA := max(a.SizeDelta, -a.SizeDelta)
+		absB := max(b.SizeDelta, -b.SizeDelta)
+		if absA != absB {
+			if absA > absB {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return changes
+}
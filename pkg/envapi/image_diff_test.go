@@ -0,0 +1,64 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffImageLayers(t *testing.T) {
+	layersA := []ImageLayerInfo{
+		{Digest: "sha256:base", Size: 100},
+		{Digest: "sha256:deps-old", Size: 50},
+		{Digest: "sha256:app-old", Size: 10},
+	}
+	layersB := []ImageLayerInfo{
+		{Digest: "sha256:base", Size: 100},
+		{Digest: "sha256:deps-new", Size: 450},
+		{Digest: "sha256:app-new", Size: 12},
+	}
+
+	diff := diffImageLayers(layersA, layersB)
+	assert.Equal(t, int64(160), diff.SizeA)
+	assert.Equal(t, int64(562), diff.SizeB)
+	assert.Equal(t, int64(402), diff.SizeDelta)
+	assert.Equal(t, 1, diff.NumSharedLayers)
+	assert.Equal(t, []ImageLayerInfo{layersB[1], layersB[2]}, diff.AddedLayers)
+	assert.Equal(t, []ImageLayerInfo{layersA[1], layersA[2]}, diff.RemovedLayers)
+}
+
+func TestDiffImageLayers_Identical(t *testing.T) {
+	layers := []ImageLayerInfo{{Digest: "sha256:base", Size: 100}}
+	diff := diffImageLayers(layers, layers)
+	assert.Equal(t, int64(0), diff.SizeDelta)
+	assert.Equal(t, 1, diff.NumSharedLayers)
+	assert.Empty(t, diff.AddedLayers)
+	assert.Empty(t, diff.RemovedLayers)
+}
+
+func TestDiffImageFiles(t *testing.T) {
+	filesA := map[string]imageFileEntry{
+		"/app/Server.dll":   {Size: 1000, Digest: [32]byte{1}},
+		"/app/Shared.dll":   {Size: 500, Digest: [32]byte{2}},
+		"/app/Obsolete.dll": {Size: 300, Digest: [32]byte{3}},
+		"/app/config.yaml":  {Size: 10, Digest: [32]byte{4}},
+	}
+	filesB := map[string]imageFileEntry{
+		"/app/Server.dll":  {Size: 1200, Digest: [32]byte{5}},
+		"/app/Shared.dll":  {Size: 500, Digest: [32]byte{2}},
+		"/app/Huge.bin":    {Size: 4000, Digest: [32]byte{6}},
+		"/app/config.yaml": {Size: 10, Digest: [32]byte{7}},
+	}
+
+	changes := diffImageFiles(filesA, filesB)
+	require.Len(t, changes, 4)
+	assert.Equal(t, ImageFileChange{Path: "/app/Huge.bin", Change: ImageFileAdded, SizeB: 4000, SizeDelta: 4000}, changes[0])
+	assert.Equal(t, ImageFileChange{Path: "/app/Obsolete.dll", Change: ImageFileRemoved, SizeA: 300, SizeDelta: -300}, changes[1])
+	assert.Equal(t, ImageFileChange{Path: "/app/Server.dll", Change: ImageFileModified, SizeA: 1000, SizeB: 1200, SizeDelta: 200}, changes[2])
+	assert.Equal(t, ImageFileChange{Path: "/app/config.yaml", Change: ImageFileModified, SizeA: 10, SizeB: 10, SizeDelta: 0}, changes[3])
+}
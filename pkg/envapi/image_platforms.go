@@ -0,0 +1,284 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// ReadLocalDockerImagePlatforms returns the platforms (eg, 'linux/amd64') that a local Docker
+// image is available for. With the containerd image store, a multi-arch image built with
+// 'docker buildx build --platform=...' lists all of its platforms. With the legacy image
+// store, only the image's own platform is returned.
+func ReadLocalDockerImagePlatforms(ctx context.Context, imageRef string) ([]string, error) {
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = dockerClient.Close() }()
+
+	imageInspect, err := dockerClient.ImageInspect(ctx, imageRef, client.ImageInspectWithManifests(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect local docker image '%s': %w", imageRef, err)
+	}
+
+	// Manifests are only populated by the containerd image store.
+	platforms := []string{}
+	for _, manifest := range imageInspect.Manifests {
+		if manifest.Kind != image.ManifestKindImage || manifest.ImageData == nil {
+			continue
+		}
+		platforms = append(platforms, formatImagePlatform(manifest.ImageData.Platform.OS, manifest.ImageData.Platform.Architecture))
+	}
+	if len(platforms) == 0 {
+		platforms = append(platforms, formatImagePlatform(imageInspect.Os, imageInspect.Architecture))
+	}
+	return normalizeImagePlatforms(platforms), nil
+}
+
+// FetchRemoteDockerImagePlatforms returns the platforms (eg, 'linux/amd64') that an image in a
+// remote Docker registry is available for. For a multi-arch image, the platforms are read from
+// its manifest list (image index). For a single-arch image, the platform is read from the image
+// config.
+func FetchRemoteDockerImagePlatforms(creds *DockerCredentials, imageRef string) ([]string, error) {
+	log.Debug().Msgf("Fetch platforms of remote container image: %s", imageRef)
+	if imageRef == "" {
+		return nil, fmt.Errorf("empty image reference")
+	}
+
+	// Create a registry authenticator using the provided credentials.
+	authenticator := authn.FromConfig(authn.AuthConfig{
+		Username: creds.Username,
+		Password: creds.Password,
+	})
+
+	// Parse the image reference (name + tag or digest).
+	ref, err := name.ParseReference(imageRef, name.WithDefaultRegistry(creds.RegistryURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse remote docker image reference '%s': %w", imageRef, err)
+	}
+
+	desc, err := remote.Get(ref, remote.WithAuth(authenticator))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image descriptor '%s': %w", imageRef, err)
+	}
+
+	// Multi-arch image: list the platforms in the index. Attestation manifests use the
+	// 'unknown/unknown' platform and are skipped.
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote docker image index '%s': %w", imageRef, err)
+		}
+		indexManifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote docker image index manifest '%s': %w", imageRef, err)
+		}
+		platforms := []string{}
+		for _, manifest := range indexManifest.Manifests {
+			if manifest.Platform == nil {
+				continue
+			}
+			platforms = append(platforms, formatImagePlatform(manifest.Platform.OS, manifest.Platform.Architecture))
+		}
+		return normalizeImagePlatforms(platforms), nil
+	}
+
+	// Single-arch image: read the platform from the image config.
+	img, err := desc.Image()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image from descriptor '%s': %w", imageRef, err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote docker image config file '%s': %w", imageRef, err)
+	}
+	return normalizeImagePlatforms([]string{formatImagePlatform(cfg.OS, cfg.Architecture)}), nil
+}
+
+// GetGameServerNodeArchitectures returns the distinct CPU architectures (eg, 'amd64') of the
+// Kubernetes nodes that the environment's game server runs on. The nodes of the running game
+// server pods are used if there are any. Otherwise, the nodes are selected with the game server
+// StatefulSets' nodeSelector and required node affinity. If neither is available, eg, on the
+// first deployment, all the nodes in the cluster are used.
+func GetGameServerNodeArchitectures(ctx context.Context, kubeCli *KubeClient) ([]string, error) {
+	nodes, err := kubeCli.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Kubernetes nodes: %w", err)
+	}
+
+	pods, err := kubeCli.Clientset.CoreV1().Pods(kubeCli.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metaplayGameServerPodLabelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game server pods: %w", err)
+	}
+
+	statefulSets, err := kubeCli.Clientset.AppsV1().StatefulSets(kubeCli.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list game server StatefulSets: %w", err)
+	}
+	podSpecs := []corev1.PodSpec{}
+	for _, statefulSet := range statefulSets.Items {
+		if statefulSet.Spec.Template.Labels["app"] == "metaplay-server" {
+			podSpecs = append(podSpecs, statefulSet.Spec.Template.Spec)
+		}
+	}
+
+	gameServerNodes, err := selectGameServerNodes(nodes.Items, pods.Items, podSpecs)
+	if err != nil {
+		return nil, err
+	}
+	return getNodeArchitectures(gameServerNodes), nil
+}
+
+// selectGameServerNodes returns the nodes that the game server pods run on, or if there are
+// no scheduled pods, the nodes that the pod specs can be scheduled on. Returns all the nodes if
+// there are no pods or pod specs.
+func selectGameServerNodes(nodes []corev1.Node, pods []corev1.Pod, podSpecs []corev1.PodSpec) ([]corev1.Node, error) {
+	// Use the nodes of the scheduled pods.
+	podNodeNames := []string{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			podNodeNames = append(podNodeNames, pod.Spec.NodeName)
+		}
+	}
+	if len(podNodeNames) > 0 {
+		return slices.DeleteFunc(slices.Clone(nodes), func(node corev1.Node) bool {
+			return !slices.Contains(podNodeNames, node.Name)
+		}), nil
+	}
+
+	// Use the nodes matching the scheduling constraints of any of the pod specs.
+	if len(podSpecs) == 0 {
+		return nodes, nil
+	}
+	result := []corev1.Node{}
+	for _, node := range nodes {
+		for _, podSpec := range podSpecs {
+			matches, err := isNodeSchedulableForPodSpec(node, podSpec)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				result = append(result, node)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// isNodeSchedulableForPodSpec returns true if the node's labels match the pod spec's
+// nodeSelector and required node affinity. Taints and field selectors are not considered.
+func isNodeSchedulableForPodSpec(node corev1.Node, podSpec corev1.PodSpec) (bool, error) {
+	nodeLabels := labels.Set(node.Labels)
+	if !labels.SelectorFromSet(podSpec.NodeSelector).Matches(nodeLabels) {
+		return false, nil
+	}
+
+	affinity := podSpec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true, nil
+	}
+
+	// The node must match any of the terms, and a term matches if all of its expressions match.
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for _, term := range terms {
+		selector, err := nodeSelectorRequirementsAsSelector(term.MatchExpressions)
+		if err != nil {
+			return false, fmt.Errorf("invalid game server node affinity: %w", err)
+		}
+		if selector.Matches(nodeLabels) {
+			return true, nil
+		}
+	}
+	return len(terms) == 0, nil
+}
+
+// nodeSelectorRequirementsAsSelector converts the node selector requirements to a label selector.
+func nodeSelectorRequirementsAsSelector(requirements []corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	operators := map[corev1.NodeSelectorOperator]selection.Operator{
+		corev1.NodeSelectorOpIn:           selection.In,
+		corev1.NodeSelectorOpNotIn:        selection.NotIn,
+		corev1.NodeSelectorOpExists:       selection.Exists,
+		corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		corev1.NodeSelectorOpGt:           selection.GreaterThan,
+		corev1.NodeSelectorOpLt:           selection.LessThan,
+	}
+
+	selector := labels.NewSelector()
+	for _, requirement := range requirements {
+		operator, ok := operators[requirement.Operator]
+		if !ok {
+			return nil, fmt.Errorf("unsupported node selector operator '%s'", requirement.Operator)
+		}
+		labelRequirement, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*labelRequirement)
+	}
+	return selector, nil
+}
+
+// getNodeArchitectures returns the distinct CPU architectures of the nodes, sorted.
+func getNodeArchitectures(nodes []corev1.Node) []string {
+	architectures := []string{}
+	for _, node := range nodes {
+		arch := node.Status.NodeInfo.Architecture
+		if arch != "" && !slices.Contains(architectures, arch) {
+			architectures = append(architectures, arch)
+		}
+	}
+	slices.Sort(architectures)
+	return architectures
+}
+
+// FindMissingImageArchitectures returns the node architectures (eg, 'arm64') that the image
+// platforms (eg, 'linux/amd64') do not include. Returns an empty slice if the image can run on
+// all the nodes.
+func FindMissingImageArchitectures(imagePlatforms []string, nodeArchitectures []string) []string {
+	missing := []string{}
+	for _, arch := range nodeArchitectures {
+		if !slices.Contains(imagePlatforms, formatImagePlatform("linux", arch)) {
+			missing = append(missing, arch)
+		}
+	}
+	return missing
+}
+
+// formatImagePlatform formats the OS and architecture as a platform, eg, 'linux/amd64'.
+func formatImagePlatform(os, architecture string) string {
+	return fmt.Sprintf("%s/%s", os, architecture)
+}
+
+// normalizeImagePlatforms drops incomplete and unknown platforms (used by attestation
+// manifests) and duplicates, and sorts the platforms.
+func normalizeImagePlatforms(platforms []string) []string {
+	result := []string{}
+	for _, platform := range platforms {
+		if strings.HasPrefix(platform, "/") || strings.HasSuffix(platform, "/") || strings.Contains(platform, "unknown") || slices.Contains(result, platform) {
+			continue
+		}
+		result = append(result, platform)
+	}
+	slices.Sort(result)
+	return result
+}
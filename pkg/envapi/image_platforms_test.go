@@ -0,0 +1,151 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNormalizeImagePlatforms(t *testing.T) {
+	got := normalizeImagePlatforms([]string{"linux/arm64", "unknown/unknown", "linux/amd64", "linux/arm64", "/"})
+	want := []string{"linux/amd64", "linux/arm64"}
+	if !slices.Equal(got, want) {
+		t.Errorf("normalizeImagePlatforms() = %v, want %v", got, want)
+	}
+}
+
+func TestFindMissingImageArchitectures(t *testing.T) {
+	testCases := []struct {
+		name           string
+		imagePlatforms []string
+		nodeArchs      []string
+		want           []string
+	}{
+		{
+			name:           "single-arch image matches nodes",
+			imagePlatforms: []string{"linux/amd64"},
+			nodeArchs:      []string{"amd64"},
+			want:           []string{},
+		},
+		{
+			name:           "amd64 image on arm64 nodes",
+			imagePlatforms: []string{"linux/amd64"},
+			nodeArchs:      []string{"arm64"},
+			want:           []string{"arm64"},
+		},
+		{
+			name:           "multi-arch image on mixed nodes",
+			imagePlatforms: []string{"linux/amd64", "linux/arm64"},
+			nodeArchs:      []string{"amd64", "arm64"},
+			want:           []string{},
+		},
+		{
+			name:           "single-arch image on mixed nodes",
+			imagePlatforms: []string{"linux/arm64"},
+			nodeArchs:      []string{"amd64", "arm64"},
+			want:           []string{"amd64"},
+		},
+		{
+			name:           "no nodes",
+			imagePlatforms: []string{"linux/amd64"},
+			nodeArchs:      []string{},
+			want:           []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FindMissingImageArchitectures(tc.imagePlatforms, tc.nodeArchs)
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("FindMissingImageArchitectures() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func newTestNode(name, arch string, nodeLabels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+		Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{Architecture: arch}},
+	}
+}
+
+func TestSelectGameServerNodes(t *testing.T) {
+	nodes := []corev1.Node{
+		newTestNode("amd-1", "amd64", map[string]string{"pool": "system"}),
+		newTestNode("arm-1", "arm64", map[string]string{"pool": "game"}),
+		newTestNode("arm-2", "arm64", map[string]string{"pool": "game", "spot": "true"}),
+	}
+	affinitySpec := corev1.PodSpec{
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"game"}},
+						{Key: "spot", Operator: corev1.NodeSelectorOpDoesNotExist},
+					}},
+				},
+			},
+		}},
+	}
+
+	testCases := []struct {
+		name     string
+		pods     []corev1.Pod
+		podSpecs []corev1.PodSpec
+		want     []string
+	}{
+		{
+			name: "no pods or specs uses all nodes",
+			want: []string{"amd-1", "arm-1", "arm-2"},
+		},
+		{
+			name:     "scheduled pods take precedence over specs",
+			pods:     []corev1.Pod{{Spec: corev1.PodSpec{NodeName: "arm-2"}}, {Spec: corev1.PodSpec{}}},
+			podSpecs: []corev1.PodSpec{{NodeSelector: map[string]string{"pool": "system"}}},
+			want:     []string{"arm-2"},
+		},
+		{
+			name:     "node selector",
+			pods:     []corev1.Pod{{Spec: corev1.PodSpec{}}},
+			podSpecs: []corev1.PodSpec{{NodeSelector: map[string]string{"pool": "game"}}},
+			want:     []string{"arm-1", "arm-2"},
+		},
+		{
+			name:     "required node affinity",
+			podSpecs: []corev1.PodSpec{affinitySpec},
+			want:     []string{"arm-1"},
+		},
+		{
+			name:     "any of the specs",
+			podSpecs: []corev1.PodSpec{affinitySpec, {NodeSelector: map[string]string{"pool": "system"}}},
+			want:     []string{"amd-1", "arm-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected, err := selectGameServerNodes(nodes, tc.pods, tc.podSpecs)
+			if err != nil {
+				t.Fatalf("selectGameServerNodes() failed: %v", err)
+			}
+			got := []string{}
+			for _, node := range selected {
+				got = append(got, node.Name)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("selectGameServerNodes() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if archs := getNodeArchitectures(nodes); !slices.Equal(archs, []string{"amd64", "arm64"}) {
+		t.Errorf("getNodeArchitectures() = %v, want [amd64 arm64]", archs)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/rs/zerolog/log"
+)
+
+// HasRemoteDockerImageSignature reports whether an image in a remote Docker registry has a cosign
+// signature stored alongside it in the same repository. Only the presence of the signature is
+// checked, the signature itself is not verified.
+func HasRemoteDockerImageSignature(creds *DockerCredentials, imageRef string) (bool, error) {
+	log.Debug().Msgf("Check signature of remote container image: %s", imageRef)
+
+	// Resolve the manifest digest of the image, which the signature tag is derived from.
+	digests, exists, err := FetchRemoteDockerImageDigests(creds, imageRef)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, fmt.Errorf("remote docker image '%s' not found", imageRef)
+	}
+
+	// Check whether the signature exists in the image's repository.
+	ref, err := name.ParseReference(imageRef, name.WithDefaultRegistry(creds.RegistryURL))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse remote docker image reference '%s': %w", imageRef, err)
+	}
+	signatureRef := fmt.Sprintf("%s:%s", ref.Context().Name(), cosignSignatureTag(digests.ManifestDigest))
+	_, signed, err := FetchRemoteDockerImageDigests(creds, signatureRef)
+	if err != nil {
+		return false, err
+	}
+	return signed, nil
+}
+
+// cosignSignatureTag returns the tag that cosign stores the signature of an image under, eg,
+// 'sha256-1a2b3c.sig' for the manifest digest 'sha256:1a2b3c'.
+func cosignSignatureTag(manifestDigest string) string {
+	return strings.Replace(manifestDigest, ":", "-", 1) + ".sig"
+}
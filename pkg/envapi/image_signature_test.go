@@ -0,0 +1,14 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import "testing"
+
+func TestCosignSignatureTag(t *testing.T) {
+	got := cosignSignatureTag("sha256:1a2b3c4d")
+	if got != "sha256-1a2b3c4d.sig" {
+		t.Errorf("cosignSignatureTag() = %q, want %q", got, "sha256-1a2b3c4d.sig")
+	}
+}
@@ -50,10 +50,16 @@ type KubeConfigUserData struct {
 }
 
 type KubeConfigUserDataExec struct {
-	Command         string   `yaml:"command"`
-	Args            []string `yaml:"args"`
-	ApiVersion      string   `yaml:"apiVersion"`
-	InteractiveMode string   `yaml:"interactiveMode"`
+	Command         string                      `yaml:"command"`
+	Args            []string                    `yaml:"args"`
+	Env             []KubeConfigUserDataExecEnv `yaml:"env,omitempty"`
+	ApiVersion      string                      `yaml:"apiVersion"`
+	InteractiveMode string                      `yaml:"interactiveMode"`
+}
+
+type KubeConfigUserDataExecEnv struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
 }
 
 type KubeExecCredential struct {
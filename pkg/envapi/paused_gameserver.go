@@ -0,0 +1,155 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Name of the secret that holds the game server Helm release while the environment is paused.
+// Not a user secret, so it doesn't show up in 'metaplay secrets list'.
+const pausedGameServerSecretName = "metaplay-paused-gameserver"
+
+// Keys in the paused game server secret.
+const pausedGameServerReleaseKey = "release"   // Gzipped JSON of the Helm release
+const pausedGameServerPausedAtKey = "pausedAt" // Time of pausing, in RFC3339
+
+// PausedGameServer is the game server Helm release saved when pausing the environment, to be
+// re-installed when resuming it.
+type PausedGameServer struct {
+	Release  *release.Release // The release, including its chart and values
+	PausedAt time.Time        // When the environment was paused
+}
+
+// SavePausedGameServer saves the game server Helm release (with its chart and values) into a
+// secret in the environment, so that it can be re-installed after it has been uninstalled.
+func (targetEnv *TargetEnvironment) SavePausedGameServer(ctx context.Context, rel *release.Release, pausedAt time.Time) error {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	encoded, err := encodePausedRelease(rel)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pausedGameServerSecretName,
+			Labels: map[string]string{
+				managedSecretLabelName: managedSecretLabelValue,
+			},
+		},
+		Data: map[string][]byte{
+			pausedGameServerReleaseKey:  encoded,
+			pausedGameServerPausedAtKey: []byte(pausedAt.UTC().Format(time.RFC3339)),
+		},
+	}
+
+	// Replace any earlier saved release, eg, from a pause that failed to uninstall the release.
+	secrets := kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace)
+	existing, err := secrets.Get(ctx, pausedGameServerSecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+	} else if err == nil {
+		existing.Labels = secret.Labels
+		existing.Data = secret.Data
+		_, err = secrets.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the paused game server release: %w", err)
+	}
+	return nil
+}
+
+// GetPausedGameServer returns the game server Helm release saved when pausing the environment,
+// or nil if the environment is not paused.
+func (targetEnv *TargetEnvironment) GetPausedGameServer(ctx context.Context) (*PausedGameServer, error) {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace).Get(ctx, pausedGameServerSecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the paused game server release: %w", err)
+	}
+
+	rel, err := decodePausedRelease(secret.Data[pausedGameServerReleaseKey])
+	if err != nil {
+		return nil, err
+	}
+	pausedAt, _ := time.Parse(time.RFC3339, string(secret.Data[pausedGameServerPausedAtKey]))
+	return &PausedGameServer{Release: rel, PausedAt: pausedAt}, nil
+}
+
+// DeletePausedGameServer deletes the game server Helm release saved when pausing the environment.
+func (targetEnv *TargetEnvironment) DeletePausedGameServer(ctx context.Context) error {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	err = kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace).Delete(ctx, pausedGameServerSecretName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the paused game server release: %w", err)
+	}
+	return nil
+}
+
+// encodePausedRelease encodes the Helm release as gzipped JSON, like Helm stores its releases.
+func encodePausedRelease(rel *release.Release) ([]byte, error) {
+	payload, err := json.Marshal(rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Helm release %s: %w", rel.Name, err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to compress Helm release %s: %w", rel.Name, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress Helm release %s: %w", rel.Name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodePausedRelease decodes a Helm release encoded with encodePausedRelease().
+func decodePausedRelease(data []byte) (*release.Release, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the paused game server release: %w", err)
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the paused game server release: %w", err)
+	}
+
+	var rel release.Release
+	if err := json.Unmarshal(payload, &rel); err != nil {
+		return nil, fmt.Errorf("failed to decode the paused game server release: %w", err)
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return nil, fmt.Errorf("the paused game server release has no chart")
+	}
+	return &rel, nil
+}
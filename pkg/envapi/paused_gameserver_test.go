@@ -0,0 +1,63 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestPausedReleaseRoundTrip(t *testing.T) {
+	rel := &release.Release{
+		Name:      "gameserver",
+		Namespace: "nimbly",
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "metaplay-gameserver", Version: "0.8.1"},
+			Templates: []*chart.File{{Name: "templates/gameserver.yaml", Data: []byte("kind: GameServer\n")}},
+		},
+		Config: map[string]any{
+			"image":  map[string]any{"tag": "1234"},
+			"shards": []any{map[string]any{"name": "all", "singleton": true}},
+		},
+	}
+
+	encoded, err := encodePausedRelease(rel)
+	if err != nil {
+		t.Fatalf("encodePausedRelease failed: %v", err)
+	}
+	decoded, err := decodePausedRelease(encoded)
+	if err != nil {
+		t.Fatalf("decodePausedRelease failed: %v", err)
+	}
+
+	if decoded.Name != "gameserver" || decoded.Namespace != "nimbly" {
+		t.Errorf("unexpected release %s/%s", decoded.Namespace, decoded.Name)
+	}
+	if decoded.Chart.Metadata.Version != "0.8.1" {
+		t.Errorf("unexpected chart version %s", decoded.Chart.Metadata.Version)
+	}
+	if len(decoded.Chart.Templates) != 1 || string(decoded.Chart.Templates[0].Data) != "kind: GameServer\n" {
+		t.Errorf("chart templates not preserved: %+v", decoded.Chart.Templates)
+	}
+	if tag := decoded.Config["image"].(map[string]any)["tag"]; tag != "1234" {
+		t.Errorf("unexpected image tag %v", tag)
+	}
+}
+
+func TestDecodePausedReleaseInvalid(t *testing.T) {
+	if _, err := decodePausedRelease([]byte("not gzip")); err == nil {
+		t.Error("expected an error for invalid data")
+	}
+
+	encoded, err := encodePausedRelease(&release.Release{Name: "gameserver"})
+	if err != nil {
+		t.Fatalf("encodePausedRelease failed: %v", err)
+	}
+	if _, err := decodePausedRelease(encoded); err == nil {
+		t.Error("expected an error for a release without a chart")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/metaplay/cli/pkg/httputil"
+)
+
+// Suffixes of Prometheus push (remote write) endpoints. The query API is served under the same
+// base URL.
+var prometheusPushPathSuffixes = []string{"/api/v1/write", "/api/v1/push", "/api/prom/push"}
+
+// PrometheusClient runs queries against the Prometheus of an environment.
+type PrometheusClient struct {
+	BaseURL string // Base URL of the Prometheus API, without the '/api/v1/...' suffix
+	client  *resty.Client
+}
+
+// PrometheusSample is a single sample in the result of a Prometheus query.
+type PrometheusSample struct {
+	Labels    map[string]string `json:"labels"`
+	Value     float64           `json:"value"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// LabelsString formats the labels of the sample in the PromQL style, eg, '{pod="all-0"}'.
+func (sample PrometheusSample) LabelsString() string {
+	name := sample.Labels["__name__"]
+	var parts []string
+	for _, key := range slices.Sorted(maps.Keys(sample.Labels)) {
+		if key != "__name__" {
+			parts = append(parts, fmt.Sprintf("%s=%q", key, sample.Labels[key]))
+		}
+	}
+	return name + "{" + strings.Join(parts, ", ") + "}"
+}
+
+// prometheusQueryResponse is the response of the Prometheus instant query API.
+type prometheusQueryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// NewPrometheusClient creates a client for the Prometheus of the environment, using the
+// endpoint and the credentials from the environment details.
+func (target *TargetEnvironment) NewPrometheusClient() (*PrometheusClient, error) {
+	details, err := target.GetDetails()
+	if err != nil {
+		return nil, err
+	}
+	observability := details.Observability
+	if observability.PrometheusEndpoint == "" {
+		return nil, fmt.Errorf("environment %s does not have a Prometheus endpoint", target.HumanID)
+	}
+
+	client := httputil.NewRetryClient()
+	if observability.PrometheusUsername != "" {
+		client.SetBasicAuth(observability.PrometheusUsername, observability.PrometheusPassword)
+	}
+	return &PrometheusClient{
+		BaseURL: resolvePrometheusBaseURL(observability.PrometheusEndpoint),
+		client:  client,
+	}, nil
+}
+
+// resolvePrometheusBaseURL strips the push path from the endpoint, if any, to get the base URL
+// of the Prometheus API.
+func resolvePrometheusBaseURL(endpoint string) string {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	for _, suffix := range prometheusPushPathSuffixes {
+		if trimmed, found := strings.CutSuffix(endpoint, suffix); found {
+			return trimmed
+		}
+	}
+	return endpoint
+}
+
+// Query runs an instant PromQL query and returns the resulting samples, sorted by their labels.
+func (c *PrometheusClient) Query(ctx context.Context, promql string) ([]PrometheusSample, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetFormData(map[string]string{"query": promql}).
+		Post(c.BaseURL + "/api/v1/query")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus at %s: %w", c.BaseURL, err)
+	}
+	return parsePrometheusQueryResponse(resp.StatusCode(), resp.Body())
+}
+
+// parsePrometheusQueryResponse parses the response of an instant query. Vector and scalar
+// results are supported.
+func parsePrometheusQueryResponse(statusCode int, body []byte) ([]PrometheusSample, error) {
+	var response prometheusQueryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("invalid response from Prometheus (status %d): %s", statusCode, strings.TrimSpace(string(body)))
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s: %s", response.ErrorType, response.Error)
+	}
+
+	switch response.Data.ResultType {
+	case "vector":
+		var results []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+		}
+		if err := json.Unmarshal(response.Data.Result, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse Prometheus vector result: %w", err)
+		}
+		samples := make([]PrometheusSample, 0, len(results))
+		for _, result := range results {
+			sample, err := parsePrometheusValue(result.Value)
+			if err != nil {
+				return nil, err
+			}
+			sample.Labels = result.Metric
+			samples = append(samples, sample)
+		}
+		slices.SortFunc(samples, func(a, b PrometheusSample) int { return strings.Compare(a.LabelsString(), b.LabelsString()) })
+		return samples, nil
+	case "scalar":
+		var value []any
+		if err := json.Unmarshal(response.Data.Result, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse Prometheus scalar result: %w", err)
+		}
+		sample, err := parsePrometheusValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return []PrometheusSample{sample}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Prometheus result type '%s', only instant vectors and scalars are supported", response.Data.ResultType)
+	}
+}
+
+// parsePrometheusValue parses a '[<unix time>, "<value>"]' pair.
+func parsePrometheusValue(value []any) (PrometheusSample, error) {
+	if len(value) != 2 {
+		return PrometheusSample{}, fmt.Errorf("invalid Prometheus sample: %v", value)
+	}
+	timestamp, ok := value[0].(float64)
+	if !ok {
+		return PrometheusSample{}, fmt.Errorf("invalid Prometheus sample timestamp: %v", value[0])
+	}
+	valueStr, ok := value[1].(string)
+	if !ok {
+		return PrometheusSample{}, fmt.Errorf("invalid Prometheus sample value: %v", value[1])
+	}
+	parsed, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return PrometheusSample{}, fmt.Errorf("invalid Prometheus sample value '%s': %w", valueStr, err)
+	}
+	return PrometheusSample{
+		Labels:    map[string]string{},
+		Value:     parsed,
+		Timestamp: time.UnixMilli(int64(timestamp * 1000)),
+	}, nil
+}
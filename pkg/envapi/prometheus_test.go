@@ -0,0 +1,77 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/httputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePrometheusBaseURL(t *testing.T) {
+	assert.Equal(t, "https://prometheus.example.com", resolvePrometheusBaseURL("https://prometheus.example.com/"))
+	assert.Equal(t, "https://prometheus.example.com", resolvePrometheusBaseURL("https://prometheus.example.com/api/v1/write"))
+	assert.Equal(t, "https://mimir.example.com/prometheus", resolvePrometheusBaseURL("https://mimir.example.com/prometheus/api/v1/push"))
+}
+
+func TestParsePrometheusQueryResponse(t *testing.T) {
+	samples, err := parsePrometheusQueryResponse(200, []byte(`{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [
+				{"metric": {"__name__": "up", "pod": "all-1"}, "value": [1700000000.5, "0"]},
+				{"metric": {"__name__": "up", "pod": "all-0"}, "value": [1700000000.5, "1"]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, `up{pod="all-0"}`, samples[0].LabelsString())
+	assert.Equal(t, 1.0, samples[0].Value)
+	assert.Equal(t, int64(1700000000500), samples[0].Timestamp.UnixMilli())
+	assert.Equal(t, `up{pod="all-1"}`, samples[1].LabelsString())
+
+	samples, err = parsePrometheusQueryResponse(200, []byte(`{"status": "success", "data": {"resultType": "scalar", "result": [1700000000, "42.5"]}}`))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 42.5, samples[0].Value)
+	assert.Equal(t, "{}", samples[0].LabelsString())
+
+	_, err = parsePrometheusQueryResponse(400, []byte(`{"status": "error", "errorType": "bad_data", "error": "parse error"}`))
+	assert.ErrorContains(t, err, "bad_data: parse error")
+
+	_, err = parsePrometheusQueryResponse(200, []byte(`{"status": "success", "data": {"resultType": "matrix", "result": []}}`))
+	assert.ErrorContains(t, err, "unsupported Prometheus result type 'matrix'")
+
+	_, err = parsePrometheusQueryResponse(502, []byte(`Bad Gateway`))
+	assert.ErrorContains(t, err, "status 502")
+}
+
+func TestPrometheusClientQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "secret", password)
+		assert.Equal(t, "up", r.FormValue("query"))
+		_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {"pod": "all-0"}, "value": [1700000000, "1"]}]}}`))
+	}))
+	defer server.Close()
+
+	client := &PrometheusClient{
+		BaseURL: server.URL,
+		client:  httputil.NewRetryClient().SetBasicAuth("user", "secret"),
+	}
+	samples, err := client.Query(context.Background(), "up")
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "all-0", samples[0].Labels["pod"])
+}
@@ -7,9 +7,11 @@ package envapi
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -20,6 +22,19 @@ const userSecretNamePrefix = "user-"
 const userSecretLabelName = "io.metaplay.secret-type"
 const userSecretLabelValue = "user"
 
+// Label to tag on all the secrets managed by the CLI, so that they can be told apart from the
+// secrets created by other tools, eg, with kubectl or Helm.
+const managedSecretLabelName = "metaplay.io/managed"
+const managedSecretLabelValue = "true"
+
+// userSecretLabels returns the labels of a new user secret.
+func userSecretLabels() map[string]string {
+	return map[string]string{
+		userSecretLabelName:    userSecretLabelValue,
+		managedSecretLabelName: managedSecretLabelValue,
+	}
+}
+
 func (targetEnv *TargetEnvironment) CreateSecret(ctx context.Context, name string, payloadValues map[string][]byte) error {
 	// Initialize a Kubernetes kubeCli against the environment
 	kubeCli, err := targetEnv.GetPrimaryKubeClient()
@@ -36,7 +51,7 @@ func (targetEnv *TargetEnvironment) CreateSecret(ctx context.Context, name strin
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
-			Labels: map[string]string{userSecretLabelName: userSecretLabelValue},
+			Labels: userSecretLabels(),
 		},
 		Data: payloadValues,
 	}
@@ -137,8 +152,9 @@ func (targetEnv *TargetEnvironment) UpdateSecret(ctx context.Context, name strin
 		return fmt.Errorf("secret %s is not a valid user secret", name)
 	}
 
-	// Update the secret data
+	// Update the secret data. Secrets created by older CLI versions get the managed label too.
 	secret.Data = newData
+	secret.Labels[managedSecretLabelName] = managedSecretLabelValue
 
 	// Update the secret in Kubernetes
 	_, err = kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
@@ -148,3 +164,39 @@ func (targetEnv *TargetEnvironment) UpdateSecret(ctx context.Context, name strin
 
 	return nil
 }
+
+// SetSecretValues sets the given entries in a user secret, keeping its other entries. The secret
+// is created if it doesn't exist yet. Returns true if the secret was created.
+func (targetEnv *TargetEnvironment) SetSecretValues(ctx context.Context, name string, values map[string][]byte) (bool, error) {
+	// Initialize a Kubernetes kubeCli against the environment
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return false, err
+	}
+
+	// Create the secret if it doesn't exist yet.
+	secret, err := kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true, targetEnv.CreateSecret(ctx, name, values)
+	} else if err != nil {
+		return false, fmt.Errorf("failed to retrieve secret: %w", err)
+	}
+
+	// Check that the secret is a valid user secret
+	if value, ok := secret.Labels[userSecretLabelName]; !ok || value != userSecretLabelValue {
+		return false, fmt.Errorf("secret %s is not a valid user secret", name)
+	}
+
+	// Merge the new entries into the existing ones.
+	newData := make(map[string][]byte, len(secret.Data)+len(values))
+	maps.Copy(newData, secret.Data)
+	maps.Copy(newData, values)
+	secret.Data = newData
+	secret.Labels[managedSecretLabelName] = managedSecretLabelValue
+
+	_, err = kubeCli.Clientset.CoreV1().Secrets(kubeCli.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to update secret: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ShardSetStatus is a point-in-time report of the pods in a single game server shard set.
+type ShardSetStatus struct {
+	Name string           `json:"name"`
+	Pods []ShardPodStatus `json:"pods"`
+}
+
+// ShardPodStatus is the status of a single expected pod in a shard set.
+type ShardPodStatus struct {
+	Name    string             `json:"name"`
+	Phase   GameServerPodPhase `json:"phase"`
+	Message string             `json:"message"`
+}
+
+// FetchGameServerShardSetStatuses resolves the status of all game server pods in the
+// environment, grouped by shard set. Unlike WaitForServerToBeReady(), this does not wait
+// for anything and only reports the current state.
+func FetchGameServerShardSetStatuses(ctx context.Context, kubeCli *KubeClient) ([]ShardSetStatus, error) {
+	shardSets, err := fetchGameServerShardSets(ctx, kubeCli, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	podsByShard, err := fetchGameServerPodsByShardSet(ctx, kubeCli, shardSets)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveShardSetStatuses(podsByShard), nil
+}
+
+// ShardSetTopology is the size and the resource requests of a game server shard set.
+type ShardSetTopology struct {
+	Name          string `json:"name"`          // Name of the shard set (StatefulSet)
+	Replicas      int32  `json:"replicas"`      // Desired number of pods
+	ReadyReplicas int32  `json:"readyReplicas"` // Number of ready pods
+	CPURequest    string `json:"cpuRequest"`    // CPU request of the game server container, eg, '1000m'
+	MemoryRequest string `json:"memoryRequest"` // Memory request of the game server container, eg, '2Gi'
+}
+
+// FetchGameServerShardSetTopology resolves the current size and resource requests of each game
+// server shard set in the environment from their StatefulSets.
+func FetchGameServerShardSetTopology(ctx context.Context, kubeCli *KubeClient) ([]ShardSetTopology, error) {
+	shardSets, err := fetchGameServerShardSets(ctx, kubeCli, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ShardSetTopology, 0, len(shardSets))
+	for _, sts := range shardSets {
+		topology := ShardSetTopology{
+			Name:          sts.Name,
+			ReadyReplicas: sts.Status.ReadyReplicas,
+		}
+		if sts.Spec.Replicas != nil {
+			topology.Replicas = *sts.Spec.Replicas
+		}
+		if containers := sts.Spec.Template.Spec.Containers; len(containers) > 0 {
+			requests := containers[0].Resources.Requests
+			if cpu, ok := requests[corev1.ResourceCPU]; ok {
+				topology.CPURequest = cpu.String()
+			}
+			if memory, ok := requests[corev1.ResourceMemory]; ok {
+				topology.MemoryRequest = memory.String()
+			}
+		}
+		result = append(result, topology)
+	}
+	return result, nil
+}
+
+// resolveShardSetStatuses converts the pods of each shard set into a status report.
+// Expected pods that do not exist (yet) are reported as pending.
+func resolveShardSetStatuses(podsByShard []shardPodStates) []ShardSetStatus {
+	result := make([]ShardSetStatus, 0, len(podsByShard))
+	for _, shardPods := range podsByShard {
+		podStatuses := make([]ShardPodStatus, 0, len(shardPods.Pods))
+		for podNdx, pod := range shardPods.Pods {
+			podName := fmt.Sprintf("%s-%d", shardPods.ShardName, podNdx)
+			if pod == nil {
+				podStatuses = append(podStatuses, ShardPodStatus{
+					Name:    podName,
+					Phase:   PhasePending,
+					Message: "Pod not found",
+				})
+				continue
+			}
+
+			status := resolvePodStatus(*pod)
+			podStatuses = append(podStatuses, ShardPodStatus{
+				Name:    podName,
+				Phase:   status.Phase,
+				Message: status.Message,
+			})
+		}
+
+		result = append(result, ShardSetStatus{
+			Name: shardPods.ShardName,
+			Pods: podStatuses,
+		})
+	}
+	return result
+}
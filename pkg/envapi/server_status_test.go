@@ -0,0 +1,78 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestResolveShardSetStatuses(t *testing.T) {
+	readyPod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "shard-server",
+					Ready: true,
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	crashingPod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:  "shard-server",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				},
+			},
+		},
+	}
+
+	statuses := resolveShardSetStatuses([]shardPodStates{
+		{ShardName: "all", Pods: []*corev1.Pod{readyPod, crashingPod, nil}},
+		{ShardName: "logic", Pods: []*corev1.Pod{}},
+	})
+
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "all", statuses[0].Name)
+	require.Len(t, statuses[0].Pods, 3)
+	assert.Equal(t, "all-0", statuses[0].Pods[0].Name)
+	assert.Equal(t, PhaseReady, statuses[0].Pods[0].Phase)
+	assert.Equal(t, "all-1", statuses[0].Pods[1].Name)
+	assert.Equal(t, PhaseFailed, statuses[0].Pods[1].Phase)
+	assert.Equal(t, "all-2", statuses[0].Pods[2].Name)
+	assert.Equal(t, PhasePending, statuses[0].Pods[2].Phase)
+
+	assert.Equal(t, "logic", statuses[1].Name)
+	assert.Empty(t, statuses[1].Pods)
+}
+
+func TestEvaluateShardSetHealth(t *testing.T) {
+	_, err := evaluateShardSetHealth(nil)
+	assert.Error(t, err)
+
+	_, err = evaluateShardSetHealth([]ShardSetStatus{{Name: "all", Pods: []ShardPodStatus{}}})
+	assert.Error(t, err)
+
+	message, err := evaluateShardSetHealth([]ShardSetStatus{
+		{Name: "all", Pods: []ShardPodStatus{{Name: "all-0", Phase: PhaseReady}, {Name: "all-1", Phase: PhaseReady}}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2 of 2 pods ready", message)
+
+	_, err = evaluateShardSetHealth([]ShardSetStatus{
+		{Name: "all", Pods: []ShardPodStatus{{Name: "all-0", Phase: PhaseReady}}},
+		{Name: "logic", Pods: []ShardPodStatus{{Name: "logic-0", Phase: PhaseFailed, Message: "CrashLoopBackOff"}}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "1 of 2 pods ready, logic-0 is Failed: CrashLoopBackOff", err.Error())
+}
@@ -9,7 +9,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -21,6 +23,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrtypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,14 +35,33 @@ import (
 )
 
 // Wrapper object for accessing an environment within a target stack.
+//
+// Creating a TargetEnvironment is cheap and does not make any requests. The facets of the
+// environment are initialized lazily on first use, so that commands only pay for the ones
+// they need:
+//   - Details: the environment details from the StackAPI, see GetDetails().
+//   - Kubernetes: the client for the primary cluster, see GetPrimaryKubeClient().
+//   - Registry: the docker registry credentials and ECR client, see GetDockerCredentials().
+//
+// All the facets, and the game server accessor (see GetGameServer()), can be used from parallel
+// tasks: they're initialized under a mutex, so that concurrent first uses only make the requests
+// once.
 type TargetEnvironment struct {
 	TokenSet        *auth.TokenSet   // Tokens to use to access the environment.
 	StackApiBaseURL string           // Base URL of the StackAPI, eg, 'https://infra.<stack>/stackapi'
 	HumanID         string           // Environment human ID, eg, 'lovely-wombats-build-nimbly'. Same as Kubernetes namespace.
 	StackApiClient  *metahttp.Client // HTTP client to access environment StackAPI.
 
-	primaryKubeClient *KubeClient       // Lazily initialized KubeClient.
-	targetGameServer  *TargetGameServer // Lazily initialized TargetGameServer.
+	details             *DeploymentSecret  // Lazily fetched environment details.
+	detailsMu           sync.Mutex         // Protects details.
+	primaryKubeClient   *KubeClient        // Lazily initialized KubeClient.
+	primaryKubeClientMu sync.Mutex         // Protects primaryKubeClient.
+	targetGameServer    *TargetGameServer  // Lazily initialized TargetGameServer.
+	targetGameServerMu  sync.Mutex         // Protects targetGameServer.
+	ecrClient           *ecr.Client        // Lazily initialized ECR client.
+	ecrClientMu         sync.Mutex         // Protects ecrClient.
+	dockerCredentials   *DockerCredentials // Lazily fetched docker registry credentials.
+	dockerCredentialsMu sync.Mutex         // Protects dockerCredentials.
 }
 
 // Container for AWS access credentials into the target environment.
@@ -76,6 +98,9 @@ func (target *TargetEnvironment) GetKubernetesNamespace() string {
 
 // Get a Kubernetes client for the primary cluster.
 func (target *TargetEnvironment) GetPrimaryKubeClient() (*KubeClient, error) {
+	target.primaryKubeClientMu.Lock()
+	defer target.primaryKubeClientMu.Unlock()
+
 	// If already created, just return the earlier instance.
 	if target.primaryKubeClient != nil {
 		return target.primaryKubeClient, nil
@@ -214,6 +239,9 @@ func (target *TargetEnvironment) tryGetGameServerOldCR(ctx context.Context, kube
 
 // Get the accessor to the gameserver resource in this environment.
 func (target *TargetEnvironment) GetGameServer(ctx context.Context) (*TargetGameServer, error) {
+	target.targetGameServerMu.Lock()
+	defer target.targetGameServerMu.Unlock()
+
 	// If already created, return the instance.
 	if target.targetGameServer != nil {
 		return target.targetGameServer, nil
@@ -248,12 +276,26 @@ func (target *TargetEnvironment) GetGameServer(ctx context.Context) (*TargetGame
 	return nil, fmt.Errorf("neither old nor new gameserver CR found in Kubernetes")
 }
 
-// Request details about an environment from the StackAPI.
+// Request details about an environment from the StackAPI. The details are only fetched once
+// and the same instance is returned on subsequent calls.
 func (target *TargetEnvironment) GetDetails() (*DeploymentSecret, error) {
+	target.detailsMu.Lock()
+	defer target.detailsMu.Unlock()
+
+	// If already fetched, just return the earlier instance.
+	if target.details != nil {
+		return target.details, nil
+	}
+
 	path := fmt.Sprintf("/v0/deployments/%s", target.HumanID)
 	log.Debug().Msgf("Get environment details from %s%s", target.StackApiClient.BaseURL, path)
 	details, err := metahttp.Get[DeploymentSecret](target.StackApiClient, path)
-	return &details, err
+	if err != nil {
+		return nil, err
+	}
+
+	target.details = &details
+	return target.details, nil
 }
 
 // Get a short-lived kubeconfig with the access credentials embedded in the kubeconfig file.
@@ -326,6 +368,11 @@ func (target *TargetEnvironment) GetKubeConfigWithExecCredential(userID string)
 							target.HumanID,
 							target.StackApiBaseURL,
 						},
+						// Pin the credential profile, so that the plugin uses the same session
+						// regardless of the profile active when the kubeconfig is used.
+						Env: []KubeConfigUserDataExecEnv{
+							{Name: auth.ProfileEnvVar, Value: auth.ResolveActiveProfile()},
+						},
 						ApiVersion:      "client.authentication.k8s.io/v1beta1",
 						InteractiveMode: "Never",
 					},
@@ -360,8 +407,23 @@ func (target *TargetEnvironment) GetAWSCredentials() (*AWSCredentials, error) {
 	return &awsCredentials, err
 }
 
-// newECRClient creates an authenticated ECR client for the environment.
-func (target *TargetEnvironment) newECRClient(envDetails *DeploymentSecret) (*ecr.Client, error) {
+// getECRClient returns an authenticated ECR client for the environment. The client is created
+// on first use.
+func (target *TargetEnvironment) getECRClient() (*ecr.Client, error) {
+	target.ecrClientMu.Lock()
+	defer target.ecrClientMu.Unlock()
+
+	// If already created, just return the earlier instance.
+	if target.ecrClient != nil {
+		return target.ecrClient, nil
+	}
+
+	// The AWS region is resolved from the environment details.
+	envDetails, err := target.GetDetails()
+	if err != nil {
+		return nil, err
+	}
+
 	log.Debug().Msg("Get AWS credentials")
 	awsCredentials, err := target.GetAWSCredentials()
 	if err != nil {
@@ -384,12 +446,22 @@ func (target *TargetEnvironment) newECRClient(envDetails *DeploymentSecret) (*ec
 	}
 
 	log.Debug().Msg("Create ECR client")
-	return ecr.NewFromConfig(cfg), nil
+	target.ecrClient = ecr.NewFromConfig(cfg)
+	return target.ecrClient, nil
 }
 
-// Get Docker credentials for the environment's docker registry.
-func (target *TargetEnvironment) GetDockerCredentials(envDetails *DeploymentSecret) (*DockerCredentials, error) {
-	client, err := target.newECRClient(envDetails)
+// Get Docker credentials for the environment's docker registry. The credentials are only
+// fetched once and the same instance is returned on subsequent calls.
+func (target *TargetEnvironment) GetDockerCredentials() (*DockerCredentials, error) {
+	target.dockerCredentialsMu.Lock()
+	defer target.dockerCredentialsMu.Unlock()
+
+	// If already fetched, just return the earlier instance.
+	if target.dockerCredentials != nil {
+		return target.dockerCredentials, nil
+	}
+
+	client, err := target.getECRClient()
 	if err != nil {
 		return nil, err
 	}
@@ -424,11 +496,12 @@ func (target *TargetEnvironment) GetDockerCredentials(envDetails *DeploymentSecr
 
 	log.Debug().Msgf("ECR: username=%s, proxyEndpoint=%s", username, registryURL)
 
-	return &DockerCredentials{
+	target.dockerCredentials = &DockerCredentials{
 		Username:    username,
 		Password:    password,
 		RegistryURL: registryURL,
-	}, nil
+	}
+	return target.dockerCredentials, nil
 }
 
 // ECRImage represents a single image in the ECR repository.
@@ -442,19 +515,19 @@ type ECRImage struct {
 // ListECRImages lists Docker images in the environment's ECR repository.
 // When maxResults > 0, stops fetching pages once at least that many tagged images
 // have been collected (the caller should trim if an exact limit is needed). When 0, fetches all.
-func (target *TargetEnvironment) ListECRImages(envDetails *DeploymentSecret, maxResults int) ([]ECRImage, error) {
-	client, err := target.newECRClient(envDetails)
+func (target *TargetEnvironment) ListECRImages(maxResults int) ([]ECRImage, error) {
+	envDetails, err := target.GetDetails()
 	if err != nil {
 		return nil, err
 	}
 
-	// Extract repository name from full URI (strip registry prefix).
-	// EcrRepo looks like "123456789.dkr.ecr.us-west-2.amazonaws.com/myrepo"
-	repoName := envDetails.Deployment.EcrRepo
-	if idx := strings.Index(repoName, "/"); idx != -1 {
-		repoName = repoName[idx+1:]
+	client, err := target.getECRClient()
+	if err != nil {
+		return nil, err
 	}
 
+	repoName := getECRRepositoryName(envDetails.Deployment.EcrRepo)
+
 	var images []ECRImage
 	var nextToken *string
 	for {
@@ -508,3 +581,55 @@ func (target *TargetEnvironment) ListECRImages(envDetails *DeploymentSecret, max
 
 	return images, nil
 }
+
+// DeleteECRImages deletes the images with the given digests (and all their tags) from the
+// environment's ECR repository. Returns an error listing the images that could not be deleted.
+func (target *TargetEnvironment) DeleteECRImages(digests []string) error {
+	envDetails, err := target.GetDetails()
+	if err != nil {
+		return err
+	}
+
+	client, err := target.getECRClient()
+	if err != nil {
+		return err
+	}
+
+	repoName := getECRRepositoryName(envDetails.Deployment.EcrRepo)
+
+	// ECR accepts at most 100 images per request.
+	const maxImagesPerRequest = 100
+	failures := []string{}
+	for chunk := range slices.Chunk(digests, maxImagesPerRequest) {
+		imageIDs := make([]ecrtypes.ImageIdentifier, len(chunk))
+		for ndx, digest := range chunk {
+			imageIDs[ndx] = ecrtypes.ImageIdentifier{ImageDigest: aws.String(digest)}
+		}
+
+		output, err := client.BatchDeleteImage(context.TODO(), &ecr.BatchDeleteImageInput{
+			RepositoryName: &repoName,
+			ImageIds:       imageIDs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete images from ECR: %w", err)
+		}
+
+		for _, failure := range output.Failures {
+			failures = append(failures, fmt.Sprintf("%s: %s", aws.ToString(failure.ImageId.ImageDigest), aws.ToString(failure.FailureReason)))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to delete %d image(s) from ECR: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// getECRRepositoryName extracts the repository name from the full repository URI (strips the
+// registry prefix), eg, '123456789.dkr.ecr.us-west-2.amazonaws.com/myrepo' -> 'myrepo'.
+func getECRRepositoryName(ecrRepo string) string {
+	if idx := strings.Index(ecrRepo, "/"); idx != -1 {
+		return ecrRepo[idx+1:]
+	}
+	return ecrRepo
+}
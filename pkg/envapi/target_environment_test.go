@@ -0,0 +1,167 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/auth"
+	"github.com/metaplay/cli/pkg/metahttp"
+	"gopkg.in/yaml.v3"
+)
+
+func newTestTargetEnvironment(baseURL string) *TargetEnvironment {
+	tokenSet := &auth.TokenSet{AccessToken: "token"}
+	return &TargetEnvironment{
+		TokenSet:        tokenSet,
+		StackApiBaseURL: baseURL,
+		HumanID:         "lovely-wombats-build-nimbly",
+		StackApiClient:  metahttp.NewJSONClient(tokenSet, baseURL),
+	}
+}
+
+func TestNewTargetEnvironmentIsLazy(t *testing.T) {
+	target := NewTargetEnvironment(&auth.TokenSet{AccessToken: "token"}, "p1.metaplay.io", "lovely-wombats-build-nimbly")
+	if target.StackApiBaseURL != "https://infra.p1.metaplay.io/stackapi" {
+		t.Errorf("Unexpected StackAPI base URL: %s", target.StackApiBaseURL)
+	}
+	if target.details != nil || target.primaryKubeClient != nil || target.ecrClient != nil || target.dockerCredentials != nil {
+		t.Error("Expected no facets to be initialized on creation")
+	}
+}
+
+func TestGetDetailsIsCached(t *testing.T) {
+	numRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests++
+		if r.URL.Path != "/v0/deployments/lovely-wombats-build-nimbly" {
+			t.Errorf("Unexpected request path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"deployment": {"admin_hostname": "nimbly-admin.p1.metaplay.io"}}`))
+	}))
+	defer server.Close()
+
+	target := newTestTargetEnvironment(server.URL)
+	for range 3 {
+		details, err := target.GetDetails()
+		if err != nil {
+			t.Fatalf("GetDetails failed: %v", err)
+		}
+		if details.Deployment.AdminHostname != "nimbly-admin.p1.metaplay.io" {
+			t.Errorf("Unexpected admin hostname: %s", details.Deployment.AdminHostname)
+		}
+	}
+
+	if numRequests != 1 {
+		t.Errorf("Expected details to be fetched once, got %d requests", numRequests)
+	}
+}
+
+func TestGetDetailsFailureIsNotCached(t *testing.T) {
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"deployment": {}}`))
+	}))
+	defer server.Close()
+
+	target := newTestTargetEnvironment(server.URL)
+	if _, err := target.GetDetails(); err == nil {
+		t.Fatal("Expected GetDetails to fail")
+	}
+
+	fail = false
+	if _, err := target.GetDetails(); err != nil {
+		t.Errorf("Expected GetDetails to succeed after failure, got: %v", err)
+	}
+}
+
+func TestGetPrimaryKubeClientConcurrently(t *testing.T) {
+	var numRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		numRequests.Add(1)
+		_, _ = w.Write([]byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: secret
+`))
+	}))
+	defer server.Close()
+
+	// Concurrent first uses should create a single client.
+	target := newTestTargetEnvironment(server.URL)
+	clients := make([]*KubeClient, 8)
+	var wg sync.WaitGroup
+	for ndx := range clients {
+		wg.Go(func() {
+			kubeCli, err := target.GetPrimaryKubeClient()
+			if err != nil {
+				t.Errorf("GetPrimaryKubeClient failed: %v", err)
+			}
+			clients[ndx] = kubeCli
+		})
+	}
+	wg.Wait()
+
+	for _, kubeCli := range clients {
+		if kubeCli == nil || kubeCli != clients[0] {
+			t.Fatalf("Expected all calls to return the same client")
+		}
+	}
+	if numRequests.Load() != 1 {
+		t.Errorf("Expected kubeconfig to be fetched once, got %d requests", numRequests.Load())
+	}
+}
+
+func TestGetKubeConfigWithExecCredentialPinsProfile(t *testing.T) {
+	t.Setenv(auth.ProfileEnvVar, "work")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0/credentials/lovely-wombats-build-nimbly/k8s" {
+			t.Errorf("Unexpected request path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"spec": {"cluster": {"server": "https://k8s.p1.metaplay.io"}}}`))
+	}))
+	defer server.Close()
+
+	target := newTestTargetEnvironment(server.URL)
+	kubeconfigPayload, err := target.GetKubeConfigWithExecCredential("user@example.com")
+	if err != nil {
+		t.Fatalf("GetKubeConfigWithExecCredential failed: %v", err)
+	}
+
+	var kubeconfig KubeConfig
+	if err := yaml.Unmarshal([]byte(kubeconfigPayload), &kubeconfig); err != nil {
+		t.Fatalf("Failed to parse the kubeconfig: %v", err)
+	}
+	if len(kubeconfig.Users) != 1 {
+		t.Fatalf("Expected one user, got %d", len(kubeconfig.Users))
+	}
+	env := kubeconfig.Users[0].User.Exec.Env
+	if len(env) != 1 || env[0].Name != "METAPLAY_PROFILE" || env[0].Value != "work" {
+		t.Errorf("Expected the exec plugin to pin METAPLAY_PROFILE=work, got %+v", env)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	clierrors "github.com/metaplay/cli/internal/errors"
+	"github.com/metaplay/cli/internal/tui"
+	"github.com/rs/zerolog/log"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Label selector for the pods deployed by the metaplay-loadtest Helm chart.
+const botClientPodLabelSelector = "app=botclient"
+
+// FetchBotClientPods retrieves the bot client pods in the environment namespace.
+func FetchBotClientPods(ctx context.Context, kubeCli *KubeClient) ([]corev1.Pod, error) {
+	log.Debug().Msgf("Fetch bot client pods in namespace: %s", kubeCli.Namespace)
+	pods, err := kubeCli.Clientset.CoreV1().Pods(kubeCli.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: botClientPodLabelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bot client pods: %w", err)
+	}
+	return pods.Items, nil
+}
+
+// resolveBotClientPodStatus determines the bot client pod's phase and status message.
+// Bot client pods only have a single container, so the first container status is used.
+func resolveBotClientPodStatus(pod corev1.Pod) GameServerPodStatus {
+	if pod.DeletionTimestamp != nil {
+		return GameServerPodStatus{
+			Phase:   PhasePending,
+			Message: "Pod is being terminated",
+		}
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return GameServerPodStatus{
+			Phase:   PhasePending,
+			Message: fmt.Sprintf("Pod is %s", pod.Status.Phase),
+		}
+	}
+
+	containerStatus := pod.Status.ContainerStatuses[0]
+	state := containerStatus.State
+	switch {
+	case state.Running != nil:
+		if containerStatus.Ready {
+			return GameServerPodStatus{
+				Phase:   PhaseReady,
+				Message: fmt.Sprintf("Container %s is ready", containerStatus.Name),
+			}
+		}
+		return GameServerPodStatus{
+			Phase:   PhaseRunning,
+			Message: fmt.Sprintf("Container %s is running but not yet ready", containerStatus.Name),
+		}
+
+	case state.Waiting != nil:
+		if state.Waiting.Reason == "CrashLoopBackOff" || state.Waiting.Reason == "ErrImagePull" || state.Waiting.Reason == "ImagePullBackOff" {
+			return GameServerPodStatus{
+				Phase:   PhaseFailed,
+				Message: fmt.Sprintf("Container %s is in %s: %s", containerStatus.Name, state.Waiting.Reason, state.Waiting.Message),
+			}
+		}
+		return GameServerPodStatus{
+			Phase:   PhasePending,
+			Message: fmt.Sprintf("Container %s is waiting: %s", containerStatus.Name, state.Waiting.Reason),
+		}
+
+	case state.Terminated != nil:
+		return GameServerPodStatus{
+			Phase:   PhaseFailed,
+			Message: fmt.Sprintf("Container %s is terminated: %s", containerStatus.Name, state.Terminated.Reason),
+		}
+	}
+
+	return GameServerPodStatus{
+		Phase:   PhaseUnknown,
+		Message: "Container state is unknown",
+	}
+}
+
+// waitForBotClientsReady waits until all the bot client pods in the namespace are ready or a timeout occurs.
+func (targetEnv *TargetEnvironment) waitForBotClientsReady(ctx context.Context, output *tui.TaskOutput, timeout time.Duration) error {
+	kubeCli, err := targetEnv.GetPrimaryKubeClient()
+	if err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+	for time.Since(startTime) < timeout {
+		pods, err := FetchBotClientPods(ctx, kubeCli)
+		if err != nil {
+			return err
+		}
+
+		// Resolve the state of each pod.
+		allPodsReady := len(pods) > 0
+		statusLines := []string{fmt.Sprintf("Bot client pod states (%d):", len(pods))}
+		if len(pods) == 0 {
+			statusLines = append(statusLines, "  No bot client pods found")
+		}
+		for _, pod := range pods {
+			status := resolveBotClientPodStatus(pod)
+			statusLines = append(statusLines, fmt.Sprintf("  %s: %s [%s]", pod.Name, status.Phase, status.Message))
+			if status.Phase != PhaseReady {
+				allPodsReady = false
+			}
+
+			// If pod failed, bail out with the logs from the pod.
+			if status.Phase == PhaseFailed {
+				output.SetHeaderLines(statusLines)
+				containerName := ""
+				if len(pod.Spec.Containers) > 0 {
+					containerName = pod.Spec.Containers[0].Name
+				}
+				podLogs, err := fetchPodLogs(ctx, kubeCli, pod.Name, containerName)
+				if err != nil {
+					output.AppendLinef("Failed to get logs from pod %s: %v", pod.Name, err)
+				} else {
+					logLines := []string{fmt.Sprintf("Logs from pod %s:", pod.Name)}
+					for line := range strings.SplitSeq(podLogs, "\n") {
+						logLines = append(logLines, fmt.Sprintf("[%s] %s", pod.Name, line))
+					}
+					logLines = append(logLines, fmt.Sprintf("Pod %s failed: %s", pod.Name, status.Message))
+					output.SetFooterLines(logLines)
+				}
+				return clierrors.Newf("Bot client pod %s failed to start", pod.Name).
+					WithSuggestion(fmt.Sprintf("Check the pod logs above for details, or run: metaplay debug logs %s", targetEnv.HumanID))
+			}
+		}
+
+		output.SetHeaderLines(statusLines)
+
+		if allPodsReady {
+			return nil
+		}
+
+		// Wait a bit to check again (slower updates in non-interactive mode to avoid spamming the log).
+		if tui.IsInteractiveMode() {
+			time.Sleep(200 * time.Millisecond)
+		} else {
+			time.Sleep(2 * time.Second)
+		}
+	}
+	return errors.New("timeout waiting for bot client pods to be ready")
+}
+
+// WaitForBotClientsToBeReady adds the tasks to validate a bot client deployment to the task runner.
+func (targetEnv *TargetEnvironment) WaitForBotClientsToBeReady(ctx context.Context, taskRunner *tui.TaskRunner) {
+	taskRunner.AddTask("Wait for bot client pods to be ready", func(output *tui.TaskOutput) error {
+		return targetEnv.waitForBotClientsReady(ctx, output, 5*time.Minute)
+	})
+}
@@ -526,6 +526,18 @@ func fetchPodLogs(ctx context.Context, kubeCli *KubeClient, podName, containerNa
 	return builder.String(), nil
 }
 
+// Hints shown when waiting for the game server takes longer than usual.
+var (
+	podsReadySlowHint = tui.SlowHint{
+		Threshold: 3 * time.Minute,
+		Message:   "Pods can take several minutes to start when the cluster needs to add nodes or pull a large image. Check the pod statuses and events above for errors.",
+	}
+	domainResolutionSlowHint = tui.SlowHint{
+		Threshold: 5 * time.Minute,
+		Message:   "The domain names of a newly created environment can take up to 15 minutes to propagate, so this is expected on the first deploy. Flushing your local DNS cache can also help.",
+	}
+)
+
 // waitForDomainResolution waits for a domain to resolve within a 15-minute timeout.
 func waitForDomainResolution(output *tui.TaskOutput, hostname string, timeout time.Duration) error {
 	timeoutAt := time.Now().Add(timeout)
@@ -668,14 +680,7 @@ func waitForHTTPServerToRespond(ctx context.Context, output *tui.TaskOutput, url
 		fmt.Sprintf("Waiting for HTTP server %s to respond (timeout: %s)", url, timeout),
 	})
 
-	client := &http.Client{
-		Timeout: 5 * time.Second, // Per-request timeout
-		// Prevent the client from following redirects automatically.
-		// We want to check the status code of the initial response directly.
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-	}
+	client := newHTTPCheckClient()
 
 	for {
 		// Do a request.
@@ -683,36 +688,12 @@ func waitForHTTPServerToRespond(ctx context.Context, output *tui.TaskOutput, url
 		case <-ctx.Done():
 			return fmt.Errorf("timeout reached while waiting for %s to respond", url)
 		default:
-			// Create a new request with headers
-			req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-			if err != nil {
-				output.AppendLinef("Error creating request for %s: %v. Retrying...", url, err)
-				break
-			}
-
-			// Add the Sec-Fetch-Mode header so that StackAPI returns a 302 redirect (instead
-			// of 403 forbidden) to the request.
-			req.Header.Add("Sec-Fetch-Mode", "navigate")
-
-			// Execute the request
-			resp, err := client.Do(req)
+			status, err := attemptHTTPRequest(ctx, client, url)
 			if err != nil {
-				output.AppendLinef("Error connecting to %s: %v. Retrying...", url, err)
+				output.AppendLinef("%v. Retrying...", err)
 			} else {
-				switch {
-				case resp.StatusCode >= 200 && resp.StatusCode < 300:
-					// Accept 2xx (Success) status codes.
-					_ = resp.Body.Close()
-					output.AppendLinef("Successfully connected to %s. Status: %s", url, resp.Status)
-					return nil
-				case resp.StatusCode >= 300 && resp.StatusCode < 400:
-					// Accept 3xx (Redirection) status codes.
-					_ = resp.Body.Close()
-					output.AppendLinef("Successfully received login redirect from %s. Status: %s", url, resp.Status)
-					return nil
-				}
-				output.AppendLinef("Received status code %d from %s. Retrying...", resp.StatusCode, url)
-				_ = resp.Body.Close()
+				output.AppendLinef("Successfully connected to %s. Status: %s", url, status)
+				return nil
 			}
 		}
 
@@ -726,6 +707,45 @@ func waitForHTTPServerToRespond(ctx context.Context, output *tui.TaskOutput, url
 	}
 }
 
+// newHTTPCheckClient returns the HTTP client used for checking that HTTP servers respond.
+func newHTTPCheckClient() *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second, // Per-request timeout
+		// Prevent the client from following redirects automatically.
+		// We want to check the status code of the initial response directly.
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// attemptHTTPRequest makes a single request to the URL and returns the response status if it
+// was a success (2xx) or a redirect (3xx), eg, the StackAPI login redirect.
+func attemptHTTPRequest(ctx context.Context, client *http.Client, url string) (string, error) {
+	// Create a new request with headers
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request for %s: %v", url, err)
+	}
+
+	// Add the Sec-Fetch-Mode header so that StackAPI returns a 302 redirect (instead
+	// of 403 forbidden) to the request.
+	req.Header.Add("Sec-Fetch-Mode", "navigate")
+
+	// Execute the request
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error connecting to %s: %v", url, err)
+	}
+	_ = resp.Body.Close()
+
+	// Accept 2xx (Success) and 3xx (Redirection) status codes.
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return resp.Status, nil
+	}
+	return "", fmt.Errorf("received status code %d from %s", resp.StatusCode, url)
+}
+
 func (targetEnv *TargetEnvironment) WaitForServerToBeReady(ctx context.Context, taskRunner *tui.TaskRunner) error {
 	// Fetch environment details.
 	envDetails, err := targetEnv.GetDetails()
@@ -738,10 +758,18 @@ func (targetEnv *TargetEnvironment) WaitForServerToBeReady(ctx context.Context,
 	// soon as we want to display the logs from errors early.
 	// This can take a long time when larger changes are being applied (eg,
 	// enabling the new operator).
-	taskRunner.AddTask("Wait for game server pods to be ready", func(output *tui.TaskOutput) error {
+	podsReady := taskRunner.AddTaskWithSlowHint("Wait for game server pods to be ready", podsReadySlowHint, func(output *tui.TaskOutput) error {
 		return targetEnv.waitForGameServerReady(ctx, output, 10*time.Minute)
 	})
 
+	// The checks below are independent of each other, so they are run in parallel once the
+	// pods are ready.
+
+	// Check for NetworkPolicies and PodDisruptionBudgets that would block traffic or node maintenance.
+	taskRunner.AddTaskAfter("Check network policies and disruption budgets", []*tui.Task{podsReady}, func(output *tui.TaskOutput) error {
+		return targetEnv.checkDeploymentPolicies(ctx, output)
+	})
+
 	// CHECK CLIENT-FACING NETWORKING
 
 	serverPrimaryAddress := envDetails.Deployment.ServerHostname
@@ -749,24 +777,24 @@ func (targetEnv *TargetEnvironment) WaitForServerToBeReady(ctx context.Context,
 	log.Debug().Msgf("envDetails.Deployment.ServerPorts: %+v", envDetails.Deployment.ServerPorts)
 
 	// Wait for the primary domain name to resolve to an IP address.
-	taskRunner.AddTask("Wait for game server domain name to propagate", func(output *tui.TaskOutput) error {
+	serverDomain := taskRunner.AddTaskAfterWithSlowHint("Wait for game server domain name to propagate", []*tui.Task{podsReady}, domainResolutionSlowHint, func(output *tui.TaskOutput) error {
 		return waitForDomainResolution(output, serverPrimaryAddress, 15*time.Minute)
 	})
 
 	// Wait for server to respond to client traffic.
-	taskRunner.AddTask("Wait for game server to serve clients", func(output *tui.TaskOutput) error {
+	taskRunner.AddTaskAfter("Wait for game server to serve clients", []*tui.Task{serverDomain}, func(output *tui.TaskOutput) error {
 		return waitForGameServerClientEndpointToBeReady(ctx, output, serverPrimaryAddress, serverPrimaryPort, 5*time.Minute)
 	})
 
 	// CHECK ADMIN INTERFACE
 
 	// Wait for the admin domain name to resolve to an IP address.
-	taskRunner.AddTask("Wait for LiveOps Dashboard domain name to propagate", func(output *tui.TaskOutput) error {
+	adminDomain := taskRunner.AddTaskAfterWithSlowHint("Wait for LiveOps Dashboard domain name to propagate", []*tui.Task{podsReady}, domainResolutionSlowHint, func(output *tui.TaskOutput) error {
 		return waitForDomainResolution(output, envDetails.Deployment.AdminHostname, 15*time.Minute)
 	})
 
 	// Wait for admin API to successfully respond to an HTTP request.
-	taskRunner.AddTask("Wait for LiveOps Dashboard to serve traffic", func(output *tui.TaskOutput) error {
+	taskRunner.AddTaskAfter("Wait for LiveOps Dashboard to serve traffic", []*tui.Task{adminDomain}, func(output *tui.TaskOutput) error {
 		return waitForHTTPServerToRespond(ctx, output, "https://"+envDetails.Deployment.AdminHostname, 5*time.Minute)
 	})
 
@@ -0,0 +1,131 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GameServerPodInfo is a point-in-time summary of a single game server pod, as reported by
+// WatchGameServerPods().
+type GameServerPodInfo struct {
+	Name         string             // Name of the pod, eg, 'all-0'
+	Phase        GameServerPodPhase // Phase of the game server in the pod
+	Message      string             // Human-readable status message
+	RestartCount int32              // Number of restarts of the shard server container
+	ContainerID  string             // ID of the current shard server container (empty if not created yet)
+	Running      bool               // Is the shard server container running?
+	CreatedAt    time.Time          // Creation time of the pod
+}
+
+// Timeout for receiving the initial state of the watched resources.
+const watchSyncTimeout = 30 * time.Second
+
+// WatchGameServerPods watches the game server pods in the environment and calls onChange with
+// all the pods (sorted by name) whenever any of them changes. The callback is first called
+// with the initial state before this function returns. The watch runs until the context is
+// canceled.
+func WatchGameServerPods(ctx context.Context, kubeCli *KubeClient, onChange func(pods []GameServerPodInfo)) error {
+	return watchResources(ctx, kubeCli, "app=metaplay-server", func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Core().V1().Pods().Informer()
+	}, func(objects []any) {
+		pods := make([]GameServerPodInfo, 0, len(objects))
+		for _, obj := range objects {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				pods = append(pods, getGameServerPodInfo(*pod))
+			}
+		}
+		slices.SortFunc(pods, func(a, b GameServerPodInfo) int { return strings.Compare(a.Name, b.Name) })
+		onChange(pods)
+	})
+}
+
+// WatchHelmReleases watches the Helm release records in the environment and calls onChange
+// whenever any of them changes, eg, when a release is installed, upgraded or uninstalled. The
+// callback is first called before this function returns. The watch runs until the context is
+// canceled.
+func WatchHelmReleases(ctx context.Context, kubeCli *KubeClient, onChange func()) error {
+	// Helm stores each release revision in a secret labeled with 'owner=helm'.
+	return watchResources(ctx, kubeCli, "owner=helm", func(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
+		return factory.Core().V1().Secrets().Informer()
+	}, func(objects []any) {
+		onChange()
+	})
+}
+
+// watchResources runs an informer for the resources matching the label selector in the
+// environment's namespace, and calls onChange with all the matching resources whenever any
+// of them changes. Waits until the initial state has been received.
+func watchResources(ctx context.Context, kubeCli *KubeClient, labelSelector string, getInformer func(factory informers.SharedInformerFactory) cache.SharedIndexInformer, onChange func(objects []any)) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		kubeCli.Clientset,
+		0, // no periodic resync, only react to changes
+		informers.WithNamespace(kubeCli.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}))
+	informer := getInformer(factory)
+
+	// Only report changes after the initial state has been synced. The lock serializes the
+	// callbacks so that they always see the latest state in order.
+	var mu sync.Mutex
+	synced := false
+	notify := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if synced {
+			onChange(informer.GetStore().List())
+		}
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { notify() },
+		UpdateFunc: func(oldObj, newObj any) { notify() },
+		DeleteFunc: func(obj any) { notify() },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch resources with '%s': %w", labelSelector, err)
+	}
+
+	// The informer keeps retrying failed requests, so give up on the initial sync after a while.
+	factory.Start(ctx.Done())
+	syncCtx, cancel := context.WithTimeout(ctx, watchSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to list resources with '%s' in namespace %s", labelSelector, kubeCli.Namespace)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	synced = true
+	onChange(informer.GetStore().List())
+	return nil
+}
+
+// getGameServerPodInfo summarizes the state of a game server pod.
+func getGameServerPodInfo(pod corev1.Pod) GameServerPodInfo {
+	status := resolvePodStatus(pod)
+	info := GameServerPodInfo{
+		Name:      pod.Name,
+		Phase:     status.Phase,
+		Message:   status.Message,
+		CreatedAt: pod.CreationTimestamp.Time,
+	}
+	if container := findShardServerContainer(pod); container != nil {
+		info.RestartCount = container.RestartCount
+		info.ContainerID = container.ContainerID
+		info.Running = container.State.Running != nil
+	}
+	return info
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package envapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetGameServerPodInfo(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "all-0"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "sidecar", RestartCount: 7},
+				{
+					Name:         "shard-server",
+					Ready:        true,
+					RestartCount: 2,
+					ContainerID:  "containerd://abc123",
+					State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+
+	info := getGameServerPodInfo(pod)
+	assert.Equal(t, "all-0", info.Name)
+	assert.Equal(t, PhaseReady, info.Phase)
+	assert.Equal(t, int32(2), info.RestartCount)
+	assert.Equal(t, "containerd://abc123", info.ContainerID)
+	assert.True(t, info.Running)
+
+	// Pods without the shard server container yet are pending.
+	pending := getGameServerPodInfo(corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "all-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	})
+	assert.Equal(t, PhasePending, pending.Phase)
+	assert.Empty(t, pending.ContainerID)
+	assert.False(t, pending.Running)
+}
@@ -52,6 +52,26 @@ const (
 	ActionUnchanged                   // File exists with identical content, no write needed.
 )
 
+// String returns the machine-readable name of the action, eg, 'create'.
+func (a FileAction) String() string {
+	switch a {
+	case ActionCreate:
+		return "create"
+	case ActionOverwrite:
+		return "overwrite"
+	case ActionSkip:
+		return "skip"
+	case ActionRename:
+		return "rename"
+	case ActionUpdate:
+		return "update"
+	case ActionUnchanged:
+		return "unchanged"
+	default:
+		return fmt.Sprintf("FileAction(%d)", int(a))
+	}
+}
+
 // FileResult is the scan result for a single planned file.
 type FileResult struct {
 	File      PlannedFile // The original planned file.
@@ -69,6 +89,11 @@ type ZipExtraction struct {
 	count   int    // Number of files to extract (populated by Scan).
 }
 
+// FileCount returns the number of files to be extracted. Only valid after Scan.
+func (ze ZipExtraction) FileCount() int {
+	return ze.count
+}
+
 // Plan holds planned file operations and their resolved outcomes.
 type Plan struct {
 	files          []PlannedFile
@@ -134,6 +159,66 @@ func (p *Plan) AddUpdate(path string, content []byte, perm os.FileMode, message
 	return p
 }
 
+// AddManagedBlock adds or replaces a block of lines delimited by '# BEGIN <name>' and '# END <name>'
+// comment lines in a text file, such as .gitignore, keeping the rest of the file intact. If the file
+// is already in the plan, the block is updated in the planned content. Otherwise, the block is
+// applied to the existing file (or a new file) and the file is added as an update.
+func (p *Plan) AddManagedBlock(path string, name string, lines []string, message string) error {
+	for i := range p.files {
+		if p.files[i].Path == path {
+			p.files[i].Content = []byte(ReplaceManagedBlock(string(p.files[i].Content), name, lines))
+			p.scanned = false
+			return nil
+		}
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return clierrors.Wrap(err, fmt.Sprintf("Failed to read %s", path))
+	}
+	p.AddUpdate(path, []byte(ReplaceManagedBlock(string(existing), name, lines)), 0644, message)
+	return nil
+}
+
+// ReplaceManagedBlock returns the content with the block of lines between the '# BEGIN <name>' and
+// '# END <name>' comment lines replaced with the given lines. If there is no such block, it is
+// appended to the end of the content, separated by an empty line. The line endings of the content
+// are preserved.
+func ReplaceManagedBlock(content string, name string, lines []string) string {
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	beginMarker := "# BEGIN " + name
+	endMarker := "# END " + name
+	block := strings.Join(append(append([]string{beginMarker}, lines...), endMarker), newline) + newline
+
+	// Replace the existing block, if any.
+	contentLines := strings.SplitAfter(content, "\n")
+	beginNdx, endNdx := -1, -1
+	for i, line := range contentLines {
+		trimmed := strings.TrimSpace(line)
+		if beginNdx < 0 && trimmed == beginMarker {
+			beginNdx = i
+		} else if beginNdx >= 0 && trimmed == endMarker {
+			endNdx = i
+			break
+		}
+	}
+	if beginNdx >= 0 && endNdx >= 0 {
+		return strings.Join(contentLines[:beginNdx], "") + block + strings.Join(contentLines[endNdx+1:], "")
+	}
+
+	// Otherwise, append the block.
+	if content == "" {
+		return block
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += newline
+	}
+	return content + newline + block
+}
+
 // AddZipExtraction adds a zip archive to be extracted during Execute.
 // Only entries whose name starts with prefix are extracted. Entries are
 // written to destDir with their full zip path (the prefix is not stripped).
@@ -237,6 +322,15 @@ func (p *Plan) Results() []FileResult {
 	return p.results
 }
 
+// ZipExtractions returns the zip archives to be extracted, with file counts
+// populated. Panics if Scan has not been called.
+func (p *Plan) ZipExtractions() []ZipExtraction {
+	if !p.scanned {
+		panic("filesetwriter: ZipExtractions() called before Scan()")
+	}
+	return p.zipExtractions
+}
+
 // FilesToWrite returns the number of files that will actually be written
 // (excludes skipped files). Includes files from zip extractions.
 func (p *Plan) FilesToWrite() int {
@@ -1252,3 +1252,115 @@ func TestFilesToWriteIncludesZipFiles(t *testing.T) {
 		t.Fatalf("expected 3 files to write, got %d", got)
 	}
 }
+
+func TestZipExtractionsFileCount(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := createTestZip(t, dir, map[string]string{
+		"MetaplaySDK/a.txt": "a",
+		"MetaplaySDK/b.txt": "b",
+		"Other/c.txt":       "c",
+	})
+
+	p := NewPlan(false)
+	p.AddZipExtraction(zipPath, "MetaplaySDK/", dir)
+	if err := p.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	extractions := p.ZipExtractions()
+	if len(extractions) != 1 {
+		t.Fatalf("expected 1 zip extraction, got %d", len(extractions))
+	}
+	if extractions[0].FileCount() != 2 {
+		t.Fatalf("expected 2 files to extract, got %d", extractions[0].FileCount())
+	}
+}
+
+func TestFileActionString(t *testing.T) {
+	expected := map[FileAction]string{
+		ActionCreate:    "create",
+		ActionOverwrite: "overwrite",
+		ActionSkip:      "skip",
+		ActionRename:    "rename",
+		ActionUpdate:    "update",
+		ActionUnchanged: "unchanged",
+	}
+	for action, name := range expected {
+		if action.String() != name {
+			t.Fatalf("expected %q, got %q", name, action.String())
+		}
+	}
+}
+
+func TestReplaceManagedBlock(t *testing.T) {
+	lines := []string{"/out/", "*.log"}
+	block := "# BEGIN Test\n/out/\n*.log\n# END Test\n"
+
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", block},
+		{"append", "bin/\n", "bin/\n\n" + block},
+		{"append without trailing newline", "bin/", "bin/\n\n" + block},
+		{"replace", "bin/\n\n# BEGIN Test\n/old/\n# END Test\nobj/\n", "bin/\n\n" + block + "obj/\n"},
+		{"crlf", "bin/\r\n", "bin/\r\n\r\n# BEGIN Test\r\n/out/\r\n*.log\r\n# END Test\r\n"},
+		{"unterminated block is appended", "# BEGIN Test\n/old/\n", "# BEGIN Test\n/old/\n\n" + block},
+	}
+	for _, tc := range cases {
+		if got := ReplaceManagedBlock(tc.content, "Test", lines); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAddManagedBlock(t *testing.T) {
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, ".gitignore")
+	plannedPath := filepath.Join(dir, ".gitattributes")
+	if err := os.WriteFile(existingPath, []byte("bin/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPlan(false)
+	p.Add(plannedPath, []byte("* text=auto\n"), 0644)
+	if err := p.AddManagedBlock(existingPath, "Test", []string{"/out/"}, "update managed block"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddManagedBlock(plannedPath, "Test", []string{"*.mpa binary"}, "update managed block"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	results := p.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if got := string(results[0].File.Content); got != "* text=auto\n\n# BEGIN Test\n*.mpa binary\n# END Test\n" {
+		t.Fatalf("unexpected planned file content %q", got)
+	}
+	if results[1].Action != ActionUpdate {
+		t.Fatalf("expected ActionUpdate, got %s", results[1].Action)
+	}
+	if got := string(results[1].File.Content); got != "bin/\n\n# BEGIN Test\n/out/\n# END Test\n" {
+		t.Fatalf("unexpected updated file content %q", got)
+	}
+
+	// Once written, the block is unchanged.
+	if err := p.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	p = NewPlan(false)
+	if err := p.AddManagedBlock(existingPath, "Test", []string{"/out/"}, "update managed block"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Results()[0].Action; got != ActionUnchanged {
+		t.Fatalf("expected ActionUnchanged, got %s", got)
+	}
+}
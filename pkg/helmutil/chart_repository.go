@@ -7,6 +7,7 @@ package helmutil
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/metaplay/cli/pkg/httputil"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/registry"
 )
 
 func ValidateLocalHelmChart(helmChartLocalPath string) error {
@@ -142,10 +144,20 @@ func ResolveBestMatchingVersion(availableVersions []string, constraints version.
 // The returned version is the latest of the charts satisfying the rules:
 // a) has the specified chart name, b) is newer than the legacy version cut-off,
 // c) matches the version constraint.
-func ResolveBestMatchingHelmVersion(helmChartRepo, chartName string, legacyVersionCutoff *version.Version, versionConstraints version.Constraints) (string, error) {
+// The registryClient is required for OCI chart repositories, and ignored for others.
+func ResolveBestMatchingHelmVersion(registryClient *registry.Client, helmChartRepo, chartName string, legacyVersionCutoff *version.Version, versionConstraints version.Constraints) (string, error) {
 	// Fetch recent Helm chart versions (ignore all legacy version already here).
 	helmChartRepo = strings.TrimSuffix(helmChartRepo, "/")
-	availableChartVersions, err := FetchHelmChartVersions(helmChartRepo, chartName, legacyVersionCutoff)
+	var availableChartVersions []string
+	var err error
+	if IsOCIChartRepository(helmChartRepo) {
+		if registryClient == nil {
+			return "", fmt.Errorf("no registry client given for OCI chart repository %s", helmChartRepo)
+		}
+		availableChartVersions, err = FetchOCIHelmChartVersions(registryClient, helmChartRepo, chartName, legacyVersionCutoff)
+	} else {
+		availableChartVersions, err = FetchHelmChartVersions(helmChartRepo, chartName, legacyVersionCutoff)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch Helm chart versions from the repository: %v", err)
 	}
@@ -160,8 +172,64 @@ func ResolveBestMatchingHelmVersion(helmChartRepo, chartName string, legacyVersi
 	return useChartVersion, nil
 }
 
-// Construct final Helm chart path for a remote chart.
+// Construct final Helm chart path for a remote chart. For OCI chart repositories, the path
+// refers to the chart without the version, which must be given to Helm separately.
 func GetHelmChartPath(helmChartRepo, chartName, chartVersion string) string {
 	helmChartRepo = strings.TrimSuffix(helmChartRepo, "/")
+	if IsOCIChartRepository(helmChartRepo) {
+		return fmt.Sprintf("%s/%s", helmChartRepo, chartName)
+	}
 	return fmt.Sprintf("%s/%s-%s.tgz", helmChartRepo, chartName, chartVersion)
 }
+
+// Find the versions of the chart archives named '<chartName>-<version>.tgz' in a local
+// charts directory (eg, a project's copy of the charts for when the repository is unavailable).
+func FindLocalHelmChartVersions(chartsDir, chartName string) ([]string, error) {
+	entries, err := os.ReadDir(chartsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local charts directory %s: %w", chartsDir, err)
+	}
+
+	prefix := chartName + "-"
+	var chartVersions []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) || !strings.HasSuffix(entry.Name(), ".tgz") {
+			continue
+		}
+
+		versionStr := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), prefix), ".tgz")
+		if _, err := version.NewVersion(versionStr); err != nil {
+			log.Debug().Msgf("Skipping local chart archive with invalid version: %s", entry.Name())
+			continue
+		}
+		chartVersions = append(chartVersions, versionStr)
+	}
+
+	return chartVersions, nil
+}
+
+// Find the best matching Helm chart from a local charts directory. Returns the version and the
+// path to the chart archive. Like with the remote repositories, the chart versions older than
+// minChartVersion (if non-nil) are ignored.
+func ResolveBestMatchingLocalHelmChart(chartsDir, chartName string, minChartVersion *version.Version, versionConstraints version.Constraints) (string, string, error) {
+	chartVersions, err := FindLocalHelmChartVersions(chartsDir, chartName)
+	if err != nil {
+		return "", "", err
+	}
+	availableChartVersions := []string{}
+	for _, chartVersion := range chartVersions {
+		if minChartVersion != nil && version.Must(version.NewVersion(chartVersion)).LessThan(minChartVersion) {
+			log.Debug().Msgf("Skipping local chart %s v%s older than the minimum version %s", chartName, chartVersion, minChartVersion)
+			continue
+		}
+		availableChartVersions = append(availableChartVersions, chartVersion)
+	}
+	log.Debug().Msgf("Available Helm chart versions in %s: %v", chartsDir, strings.Join(availableChartVersions, ", "))
+
+	useChartVersion, err := ResolveBestMatchingVersion(availableChartVersions, versionConstraints)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to find a matching Helm chart version in %s: %v", chartsDir, err)
+	}
+
+	return useChartVersion, filepath.Join(chartsDir, fmt.Sprintf("%s-%s.tgz", chartName, useChartVersion)), nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBestMatchingLocalHelmChart(t *testing.T) {
+	chartsDir := t.TempDir()
+	for _, name := range []string{
+		"metaplay-gameserver-0.7.4.tgz",
+		"metaplay-gameserver-0.8.1.tgz",
+		"metaplay-gameserver-0.8.3.tgz",
+		"metaplay-gameserver-invalid.tgz",
+		"metaplay-loadtest-0.5.0.tgz",
+		"README.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(chartsDir, name), []byte{}, 0644))
+	}
+
+	versions, err := FindLocalHelmChartVersions(chartsDir, "metaplay-gameserver")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.7.4", "0.8.1", "0.8.3"}, versions)
+
+	constraints, err := version.NewConstraint("< 0.8.2")
+	require.NoError(t, err)
+	chartVersion, chartPath, err := ResolveBestMatchingLocalHelmChart(chartsDir, "metaplay-gameserver", nil, constraints)
+	require.NoError(t, err)
+	assert.Equal(t, "0.8.1", chartVersion)
+	assert.Equal(t, filepath.Join(chartsDir, "metaplay-gameserver-0.8.1.tgz"), chartPath)
+
+	chartVersion, _, err = ResolveBestMatchingLocalHelmChart(chartsDir, "metaplay-gameserver", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0.8.3", chartVersion)
+
+	_, _, err = ResolveBestMatchingLocalHelmChart(chartsDir, "metaplay-botclient", nil, nil)
+	assert.Error(t, err)
+
+	// Versions older than the minimum are ignored.
+	constraints, err = version.NewConstraint("< 0.8.0")
+	require.NoError(t, err)
+	_, _, err = ResolveBestMatchingLocalHelmChart(chartsDir, "metaplay-gameserver", version.Must(version.NewVersion("0.8.0")), constraints)
+	assert.Error(t, err)
+	chartVersion, _, err = ResolveBestMatchingLocalHelmChart(chartsDir, "metaplay-gameserver", version.Must(version.NewVersion("0.7.0")), constraints)
+	require.NoError(t, err)
+	assert.Equal(t, "0.7.4", chartVersion)
+
+	_, err = FindLocalHelmChartVersions(filepath.Join(chartsDir, "missing"), "metaplay-gameserver")
+	assert.Error(t, err)
+}
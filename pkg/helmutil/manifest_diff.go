@@ -0,0 +1,115 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// Kinds of changes to Kubernetes resources between two sets of manifests.
+const (
+	ManifestResourceAdded    = "added"
+	ManifestResourceRemoved  = "removed"
+	ManifestResourceModified = "modified"
+)
+
+// ManifestChange describes a Kubernetes resource that differs between two sets of manifests.
+// The contents are normalized YAML with the values of Secrets redacted.
+type ManifestChange struct {
+	Resource   string // Resource identifier, eg, 'StatefulSet/all'.
+	Change     string // One of ManifestResourceAdded, ManifestResourceRemoved, or ManifestResourceModified.
+	OldContent string // Empty for added resources.
+	NewContent string // Empty for removed resources.
+}
+
+// DiffManifests compares two multi-document manifests (eg, of two Helm release revisions)
+// resource-by-resource. Returns the changed resources sorted by their identifier.
+func DiffManifests(oldManifest, newManifest string) ([]ManifestChange, error) {
+	oldResources, err := parseManifestResources(oldManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old manifests: %w", err)
+	}
+	newResources, err := parseManifestResources(newManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new manifests: %w", err)
+	}
+
+	changes := []ManifestChange{}
+	for resource, newContent := range newResources {
+		oldContent, found := oldResources[resource]
+		if !found {
+			changes = append(changes, ManifestChange{Resource: resource, Change: ManifestResourceAdded, NewContent: newContent})
+		} else if oldContent != newContent {
+			changes = append(changes, ManifestChange{Resource: resource, Change: ManifestResourceModified, OldContent: oldContent, NewContent: newContent})
+		}
+	}
+	for resource, oldContent := range oldResources {
+		if _, found := newResources[resource]; !found {
+			changes = append(changes, ManifestChange{Resource: resource, Change: ManifestResourceRemoved, OldContent: oldContent})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b ManifestChange) int {
+		return strings.Compare(a.Resource, b.Resource)
+	})
+	return changes, nil
+}
+
+// parseManifestResources splits a multi-document manifest into resources keyed by their
+// identifier. The resources are re-marshaled into normalized YAML so that formatting
+// differences (eg, from post-renderers) do not show up as changes.
+func parseManifestResources(manifest string) (map[string]string, error) {
+	resources := map[string]string{}
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		kind, _ := obj["kind"].(string)
+		metadata, _ := obj["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		namespace, _ := metadata["namespace"].(string)
+		resource := fmt.Sprintf("%s/%s", kind, name)
+		if namespace != "" {
+			resource = fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+		}
+
+		if kind == "Secret" {
+			redactSecretValues(obj)
+		}
+
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		resources[resource] = string(content)
+	}
+	return resources, nil
+}
+
+// redactSecretValues replaces the values in the Secret with a short hash of the value, so that
+// changes are still visible without revealing the values.
+func redactSecretValues(secret map[string]any) {
+	for _, field := range []string{"data", "stringData"} {
+		values, ok := secret[field].(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range values {
+			hash := sha256.Sum256(fmt.Appendf(nil, "%v", value))
+			values[key] = fmt.Sprintf("<redacted sha256:%x>", hash[:4])
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOldManifest = `---
+# Source: metaplay-gameserver/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: gameserver-config
+data:
+  logLevel: info
+---
+# Source: metaplay-gameserver/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: gameserver-old
+---
+# Source: metaplay-gameserver/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: gameserver-secret
+data:
+  password: c2VjcmV0
+`
+
+const testNewManifest = `---
+# Source: metaplay-gameserver/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: gameserver-config
+data:
+  logLevel: debug
+---
+# Source: metaplay-gameserver/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name:   gameserver-secret
+data:
+  password: c2VjcmV0
+---
+# Source: metaplay-gameserver/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: gameserver-new
+  namespace: game
+`
+
+func TestDiffManifests(t *testing.T) {
+	changes, err := DiffManifests(testOldManifest, testNewManifest)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, "ConfigMap/gameserver-config", changes[0].Resource)
+	assert.Equal(t, ManifestResourceModified, changes[0].Change)
+	assert.Contains(t, changes[0].OldContent, "logLevel: info")
+	assert.Contains(t, changes[0].NewContent, "logLevel: debug")
+
+	assert.Equal(t, "Service/game/gameserver-new", changes[1].Resource)
+	assert.Equal(t, ManifestResourceAdded, changes[1].Change)
+	assert.Empty(t, changes[1].OldContent)
+
+	assert.Equal(t, "Service/gameserver-old", changes[2].Resource)
+	assert.Equal(t, ManifestResourceRemoved, changes[2].Change)
+	assert.Empty(t, changes[2].NewContent)
+}
+
+func TestDiffManifests_RedactsSecrets(t *testing.T) {
+	changes, err := DiffManifests("", testOldManifest)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	secret := changes[1]
+	assert.Equal(t, "Secret/gameserver-secret", secret.Resource)
+	assert.NotContains(t, secret.NewContent, "c2VjcmV0")
+	assert.Contains(t, secret.NewContent, "<redacted sha256:")
+}
+
+func TestDiffManifests_Identical(t *testing.T) {
+	changes, err := DiffManifests(testOldManifest, testOldManifest)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
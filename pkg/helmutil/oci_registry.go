@@ -0,0 +1,78 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/rs/zerolog/log"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// IsOCIChartRepository returns true if the Helm chart repository is an OCI registry, eg,
+// 'oci://registry.example.com/charts'.
+func IsOCIChartRepository(helmChartRepo string) bool {
+	return registry.IsOCI(helmChartRepo)
+}
+
+// GetOCIRegistryHost returns the host (and port) of an OCI chart repository, eg,
+// 'registry.example.com' for 'oci://registry.example.com/charts'.
+func GetOCIRegistryHost(helmChartRepo string) (string, error) {
+	parsedURL, err := url.Parse(helmChartRepo)
+	if err != nil {
+		return "", fmt.Errorf("invalid OCI chart repository '%s': %w", helmChartRepo, err)
+	}
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("invalid OCI chart repository '%s': host is empty", helmChartRepo)
+	}
+	return parsedURL.Host, nil
+}
+
+// NewOCIRegistryClient creates a Helm registry client for accessing OCI chart repositories.
+// If username and password are given, they are used for authenticating to the registry.
+// Otherwise, the credentials stored with 'helm registry login' or 'docker login' are used.
+func NewOCIRegistryClient(username, password string) (*registry.Client, error) {
+	options := []registry.ClientOption{registry.ClientOptEnableCache(true)}
+	if username != "" && password != "" {
+		options = append(options, registry.ClientOptBasicAuth(username, password))
+	}
+	client, err := registry.NewClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Helm registry client: %w", err)
+	}
+	return client, nil
+}
+
+// FetchOCIHelmChartVersions fetches the versions of the chart from an OCI chart repository, with
+// the chart versions stored as the tags of '<repository>/<chartName>'. Only the versions that are
+// at least minVersion are returned.
+func FetchOCIHelmChartVersions(client *registry.Client, repository, chartName string, minVersion *version.Version) ([]string, error) {
+	ref := strings.TrimPrefix(GetHelmChartPath(repository, chartName, ""), fmt.Sprintf("%s://", registry.OCIScheme))
+	log.Debug().Msgf("Fetching Helm chart versions from OCI repository '%s'...", ref)
+
+	tags, err := client.Tags(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the chart versions in %s: %w", ref, err)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no versions found for chart '%s'", chartName)
+	}
+
+	var filteredVersions []string
+	for _, tag := range tags {
+		v, err := version.NewVersion(tag)
+		if err != nil {
+			log.Warn().Msgf("Skipping invalid Helm chart version '%s': %v", tag, err)
+			continue
+		}
+		if v.Compare(minVersion) >= 0 {
+			filteredVersions = append(filteredVersions, tag)
+		}
+	}
+	return filteredVersions, nil
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOCIChartRepository(t *testing.T) {
+	assert.True(t, IsOCIChartRepository("oci://registry.example.com/charts"))
+	assert.False(t, IsOCIChartRepository("https://charts.metaplay.dev"))
+
+	host, err := GetOCIRegistryHost("oci://registry.example.com:5000/charts/metaplay")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.example.com:5000", host)
+
+	_, err = GetOCIRegistryHost("oci:///charts")
+	assert.Error(t, err)
+}
+
+func TestGetHelmChartPath(t *testing.T) {
+	assert.Equal(t, "https://charts.metaplay.dev/metaplay-gameserver-0.8.1.tgz", GetHelmChartPath("https://charts.metaplay.dev/", "metaplay-gameserver", "0.8.1"))
+
+	// The version of OCI charts is given to Helm separately.
+	assert.Equal(t, "oci://registry.example.com/charts/metaplay-gameserver", GetHelmChartPath("oci://registry.example.com/charts/", "metaplay-gameserver", "0.8.1"))
+}
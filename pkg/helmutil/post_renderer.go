@@ -0,0 +1,111 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/postrender"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Name of the file into which the Helm-rendered manifests are written for a kustomize
+// overlay. The overlay's kustomization.yaml must list this file in its 'resources'.
+const KustomizeRenderedManifestsFile = "all.yaml"
+
+// NewPostRenderer creates a Helm post-renderer from the given path. If the path is a
+// directory, it is treated as a kustomize overlay that gets applied on top of the rendered
+// manifests (see KustomizeRenderedManifestsFile). Otherwise, the path is treated as an
+// executable that receives the rendered manifests on stdin and outputs the modified ones
+// to stdout, the same as with `helm --post-renderer`.
+func NewPostRenderer(path string) (postrender.PostRenderer, error) {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		return newKustomizePostRenderer(path)
+	}
+
+	renderer, err := postrender.NewExec(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize post-renderer %s: %w", path, err)
+	}
+	return renderer, nil
+}
+
+// kustomizePostRenderer applies a kustomize overlay directory on the Helm-rendered manifests.
+type kustomizePostRenderer struct {
+	overlayDir string
+}
+
+func newKustomizePostRenderer(overlayDir string) (*kustomizePostRenderer, error) {
+	absDir, err := filepath.Abs(overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve kustomize overlay directory %s: %w", overlayDir, err)
+	}
+
+	// Check that the directory looks like a kustomization.
+	hasKustomization := false
+	for _, fileName := range konfig.RecognizedKustomizationFileNames() {
+		if _, err := os.Stat(filepath.Join(absDir, fileName)); err == nil {
+			hasKustomization = true
+			break
+		}
+	}
+	if !hasKustomization {
+		return nil, fmt.Errorf("post-renderer directory %s does not contain a kustomization.yaml", overlayDir)
+	}
+
+	// The rendered manifests get written into the overlay directory, so it must not have such a file already.
+	if _, err := os.Stat(filepath.Join(absDir, KustomizeRenderedManifestsFile)); err == nil {
+		return nil, fmt.Errorf("post-renderer directory %s must not contain a file named '%s', it is reserved for the Helm-rendered manifests", overlayDir, KustomizeRenderedManifestsFile)
+	}
+
+	return &kustomizePostRenderer{overlayDir: absDir}, nil
+}
+
+// Run builds the kustomize overlay with the rendered manifests injected into it. The overlay
+// directory is copied into an in-memory filesystem so the files on disk are never modified.
+func (r *kustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	memFS := filesys.MakeFsInMemory()
+	err := filepath.WalkDir(r.overlayDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return memFS.MkdirAll(path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return memFS.WriteFile(path, content)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kustomize overlay directory %s: %w", r.overlayDir, err)
+	}
+
+	// Inject the rendered manifests as a resource file in the overlay.
+	if err := memFS.WriteFile(filepath.Join(r.overlayDir, KustomizeRenderedManifestsFile), renderedManifests.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write rendered manifests: %w", err)
+	}
+
+	// Build the overlay.
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(memFS, r.overlayDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply kustomize overlay %s: %w", r.overlayDir, err)
+	}
+
+	output, err := resMap.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize kustomized manifests: %w", err)
+	}
+	return bytes.NewBuffer(output), nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRenderedManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: gameserver-config
+data:
+  key: value
+`
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestNewPostRenderer_KustomizeOverlay(t *testing.T) {
+	overlayDir := t.TempDir()
+	writeTestFile(t, filepath.Join(overlayDir, "kustomization.yaml"), `resources:
+- all.yaml
+commonLabels:
+  team: platform
+`)
+
+	renderer, err := NewPostRenderer(overlayDir)
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString(testRenderedManifests))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "team: platform")
+	assert.Contains(t, output.String(), "name: gameserver-config")
+
+	// The overlay directory on disk must not be modified.
+	_, err = os.Stat(filepath.Join(overlayDir, KustomizeRenderedManifestsFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNewPostRenderer_KustomizeOverlayWithPatch(t *testing.T) {
+	overlayDir := t.TempDir()
+	writeTestFile(t, filepath.Join(overlayDir, "kustomization.yaml"), `resources:
+- all.yaml
+patches:
+- path: patch.yaml
+`)
+	writeTestFile(t, filepath.Join(overlayDir, "patch.yaml"), `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: gameserver-config
+data:
+  key: patched
+`)
+
+	renderer, err := NewPostRenderer(overlayDir)
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString(testRenderedManifests))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "key: patched")
+}
+
+func TestNewPostRenderer_DirectoryWithoutKustomization(t *testing.T) {
+	_, err := NewPostRenderer(t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not contain a kustomization.yaml")
+}
+
+func TestNewPostRenderer_ReservedFileInOverlay(t *testing.T) {
+	overlayDir := t.TempDir()
+	writeTestFile(t, filepath.Join(overlayDir, "kustomization.yaml"), "resources:\n- all.yaml\n")
+	writeTestFile(t, filepath.Join(overlayDir, KustomizeRenderedManifestsFile), testRenderedManifests)
+
+	_, err := NewPostRenderer(overlayDir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "reserved")
+}
+
+func TestNewPostRenderer_MissingExecutable(t *testing.T) {
+	_, err := NewPostRenderer(filepath.Join(t.TempDir(), "does-not-exist"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// PullHelmChart downloads the chart archive from the chart repository into destDir, like
+// 'helm pull'. The chartVersion can be an exact version or a semver range. The registryClient
+// is required for OCI chart repositories, and ignored for others. Returns the path to the
+// downloaded archive, eg, '<destDir>/metaplay-gameserver-0.8.3.tgz'.
+func PullHelmChart(registryClient *registry.Client, helmChartRepo, chartName, chartVersion, destDir string) (string, error) {
+	settings := cli.New()
+	chartDownloader := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getter.All(settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+
+	// Resolve the reference to the chart archive.
+	var chartRef string
+	if IsOCIChartRepository(helmChartRepo) {
+		if registryClient == nil {
+			return "", fmt.Errorf("no registry client given for OCI chart repository %s", helmChartRepo)
+		}
+		chartDownloader.Options = append(chartDownloader.Options, getter.WithRegistryClient(registryClient))
+		chartRef = GetHelmChartPath(helmChartRepo, chartName, chartVersion)
+	} else {
+		chartURL, err := repo.FindChartInRepoURL(strings.TrimSuffix(helmChartRepo, "/"), chartName, chartVersion, "", "", "", getter.All(settings))
+		if err != nil {
+			return "", fmt.Errorf("failed to find chart %s %s in %s: %w", chartName, chartVersion, helmChartRepo, err)
+		}
+		chartRef = chartURL
+	}
+
+	log.Debug().Msgf("Downloading Helm chart %s (version %s) into %s", chartRef, chartVersion, destDir)
+	chartPath, _, err := chartDownloader.DownloadTo(chartRef, chartVersion, destDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart %s %s: %w", chartName, chartVersion, err)
+	}
+	return chartPath, nil
+}
+
+// FindUnbundledChartDependencies returns the dependencies declared in the chart archive's
+// Chart.yaml that are not bundled into the archive (in its charts/ directory).
+func FindUnbundledChartDependencies(chartPath string) ([]*chart.Dependency, error) {
+	loadedChart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Helm chart %s: %w", chartPath, err)
+	}
+
+	bundledNames := []string{}
+	for _, bundled := range loadedChart.Dependencies() {
+		bundledNames = append(bundledNames, bundled.Name())
+	}
+
+	unbundled := []*chart.Dependency{}
+	for _, dependency := range loadedChart.Metadata.Dependencies {
+		if !slices.Contains(bundledNames, dependency.Name) {
+			unbundled = append(unbundled, dependency)
+		}
+	}
+	return unbundled, nil
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestFindUnbundledChartDependencies(t *testing.T) {
+	bundled := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "bundled", Version: "1.0.0"},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "metaplay-gameserver",
+			Version:    "0.8.3",
+			Dependencies: []*chart.Dependency{
+				{Name: "bundled", Version: "1.0.0", Repository: "https://charts.example.com"},
+				{Name: "external", Version: ">=2.0.0", Repository: "oci://registry.example.com/charts"},
+			},
+		},
+	}
+	parent.SetDependencies(bundled)
+
+	chartPath, err := chartutil.Save(parent, t.TempDir())
+	require.NoError(t, err)
+
+	dependencies, err := FindUnbundledChartDependencies(chartPath)
+	require.NoError(t, err)
+	require.Len(t, dependencies, 1)
+	assert.Equal(t, "external", dependencies[0].Name)
+	assert.Equal(t, "oci://registry.example.com/charts", dependencies[0].Repository)
+}
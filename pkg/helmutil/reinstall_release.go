@@ -0,0 +1,51 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/metaplay/cli/internal/tui"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ReinstallRelease installs a previously uninstalled Helm release again, using the chart and the
+// values stored in the release, and waits for the resources to become ready.
+func ReinstallRelease(
+	output *tui.TaskOutput,
+	actionConfig *action.Configuration,
+	previousRelease *release.Release,
+	postRenderer postrender.PostRenderer,
+	timeout time.Duration,
+) (*release.Release, error) {
+	if previousRelease.Chart == nil || previousRelease.Chart.Metadata == nil {
+		return nil, fmt.Errorf("Helm release %s has no chart information", previousRelease.Name)
+	}
+	output.SetHeaderLines([]string{fmt.Sprintf("Installing release %s (chart version %s)", previousRelease.Name, previousRelease.Chart.Metadata.Version)})
+
+	// Pipe Helm output to task output
+	actionConfig.Log = func(format string, args ...any) {
+		output.AppendLine(strings.TrimRight(fmt.Sprintf(format, args...), "\r\n"))
+	}
+
+	installCmd := action.NewInstall(actionConfig)
+	installCmd.ReleaseName = previousRelease.Name
+	installCmd.Namespace = previousRelease.Namespace
+	installCmd.Version = previousRelease.Chart.Metadata.Version
+	installCmd.Wait = true
+	installCmd.Timeout = timeout
+	installCmd.SkipSchemaValidation = true // The values were already validated when first installed
+	installCmd.PostRenderer = postRenderer
+
+	installedRelease, err := installCmd.Run(previousRelease.Chart, previousRelease.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install Helm release %s: %w", previousRelease.Name, err)
+	}
+	return installedRelease, nil
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// GetReleaseHistory returns the revisions of a Helm release, newest first. At most
+// maxRevisions revisions are returned (0 means no limit).
+func GetReleaseHistory(actionConfig *action.Configuration, releaseName string, maxRevisions int) ([]*release.Release, error) {
+	revisions, err := action.NewHistory(actionConfig).Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of Helm release %s: %w", releaseName, err)
+	}
+
+	// Order newest first (Helm returns the revisions in storage order).
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version > revisions[j].Version
+	})
+
+	if maxRevisions > 0 && len(revisions) > maxRevisions {
+		revisions = revisions[:maxRevisions]
+	}
+	return revisions, nil
+}
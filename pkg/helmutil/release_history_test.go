@@ -0,0 +1,57 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newTestActionConfig(t *testing.T, releases ...*release.Release) *action.Configuration {
+	t.Helper()
+	actionConfig := &action.Configuration{
+		Releases:   storage.Init(driver.NewMemory()),
+		KubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+		Log:        func(format string, v ...any) {},
+	}
+	for _, rel := range releases {
+		require.NoError(t, actionConfig.Releases.Create(rel))
+	}
+	return actionConfig
+}
+
+func TestGetReleaseHistory(t *testing.T) {
+	actionConfig := newTestActionConfig(t,
+		&release.Release{Name: "nimbly-gameserver", Namespace: "nimbly", Version: 1, Info: &release.Info{Status: release.StatusSuperseded}},
+		&release.Release{Name: "nimbly-gameserver", Namespace: "nimbly", Version: 3, Info: &release.Info{Status: release.StatusDeployed}},
+		&release.Release{Name: "nimbly-gameserver", Namespace: "nimbly", Version: 2, Info: &release.Info{Status: release.StatusSuperseded}},
+	)
+
+	revisions, err := GetReleaseHistory(actionConfig, "nimbly-gameserver", 0)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, 3, revisions[0].Version)
+	assert.Equal(t, 2, revisions[1].Version)
+	assert.Equal(t, 1, revisions[2].Version)
+
+	revisions, err = GetReleaseHistory(actionConfig, "nimbly-gameserver", 2)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 3, revisions[0].Version)
+}
+
+func TestGetReleaseHistory_NotFound(t *testing.T) {
+	actionConfig := newTestActionConfig(t)
+	_, err := GetReleaseHistory(actionConfig, "nimbly-gameserver", 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metaplay/cli/internal/tui"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// RenderHelmManifests renders the Helm chart with the given values into Kubernetes manifests without
+// applying anything to the cluster. This is the equivalent of `helm upgrade --install --dry-run`. The
+// post-renderer, if non-nil, is applied on the rendered manifests like during an actual deployment.
+// Chart hooks are not included in the returned manifests.
+func RenderHelmManifests(
+	output *tui.TaskOutput,
+	actionConfig *action.Configuration,
+	existingRelease *release.Release,
+	namespace, releaseName, chartURL string,
+	chartVersion string,
+	values map[string]any,
+	postRenderer postrender.PostRenderer,
+	validateValuesSchema bool,
+) (string, error) {
+	// Pipe Helm output to task output
+	actionConfig.Log = func(format string, args ...any) {
+		line := fmt.Sprintf(format, args...)
+		output.AppendLine(strings.TrimRight(line, "\r\n"))
+	}
+
+	// Use install for new releases and upgrade for existing ones, so the release
+	// revision and other built-in objects are resolved as in an actual deployment.
+	if existingRelease == nil {
+		installCmd := action.NewInstall(actionConfig)
+		installCmd.Version = chartVersion
+		installCmd.ReleaseName = releaseName
+		installCmd.Namespace = namespace
+		installCmd.DryRun = true
+		installCmd.Devel = true
+		installCmd.SkipSchemaValidation = !validateValuesSchema
+		installCmd.PostRenderer = postRenderer
+
+		loadedChart, err := loadHelmChart(output, &installCmd.ChartPathOptions, chartURL)
+		if err != nil {
+			return "", err
+		}
+
+		output.AppendLine("Rendering manifests for a new release...")
+		rendered, err := installCmd.Run(loadedChart, values)
+		if err != nil {
+			return "", fmt.Errorf("failed to render the Helm chart: %w", err)
+		}
+		return rendered.Manifest, nil
+	}
+
+	upgradeCmd := action.NewUpgrade(actionConfig)
+	upgradeCmd.Version = chartVersion
+	upgradeCmd.Namespace = namespace
+	upgradeCmd.DryRun = true
+	upgradeCmd.Devel = true
+	upgradeCmd.SkipSchemaValidation = !validateValuesSchema
+	upgradeCmd.PostRenderer = postRenderer
+
+	loadedChart, err := loadHelmChart(output, &upgradeCmd.ChartPathOptions, chartURL)
+	if err != nil {
+		return "", err
+	}
+
+	output.AppendLinef("Rendering manifests for an upgrade of release %s...", releaseName)
+	rendered, err := upgradeCmd.Run(releaseName, loadedChart, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the Helm chart: %w", err)
+	}
+	return rendered.Manifest, nil
+}
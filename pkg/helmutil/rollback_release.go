@@ -0,0 +1,30 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// RollbackRelease rolls the given Helm release back to the specified revision.
+func RollbackRelease(actionConfig *action.Configuration, releaseName string, revision int) error {
+	// Create Helm Rollback action
+	rollback := action.NewRollback(actionConfig)
+	rollback.Version = revision
+	rollback.Wait = true
+	rollback.Timeout = 5 * time.Minute
+	rollback.MaxHistory = 10 // Keep 10 releases max, same as with upgrades
+
+	// Execute the Rollback action
+	err := rollback.Run(releaseName)
+	if err != nil {
+		return fmt.Errorf("failed to roll back Helm release %s to revision %d: %w", releaseName, revision, err)
+	}
+
+	return nil
+}
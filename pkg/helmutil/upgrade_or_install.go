@@ -15,9 +15,11 @@ import (
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 )
 
@@ -32,6 +34,12 @@ import (
 // The values from requiredValues are used as-is with the highest priority. Any attempt to override
 // a value defined in requiredValues with a different value results in an error. Overriding with
 // the same value is allowed.
+//
+// If postRenderer is non-nil, it is applied on the rendered manifests before they are installed
+// (equivalent to `helm --post-renderer`).
+//
+// If wait is false, returns as soon as the resources have been applied, without waiting for them
+// to become ready (equivalent to omitting `--wait`).
 func HelmUpgradeOrInstall(
 	output *tui.TaskOutput,
 	actionConfig *action.Configuration,
@@ -42,17 +50,11 @@ func HelmUpgradeOrInstall(
 	defaultValues map[string]any,
 	cliSetValues map[string]any,
 	requiredValues map[string]any,
+	postRenderer postrender.PostRenderer,
 	timeout time.Duration,
+	wait bool,
 	validateValuesSchema bool,
 ) (*release.Release, error) {
-	// Validate that defaultValues and requiredValues have correct types
-	if err := validateHelmValuesTypes(defaultValues, "defaultValues"); err != nil {
-		return nil, fmt.Errorf("invalid defaultValues: %w", err)
-	}
-	if err := validateHelmValuesTypes(requiredValues, "requiredValues"); err != nil {
-		return nil, fmt.Errorf("invalid requiredValues: %w", err)
-	}
-
 	// Show header at top
 	headerLine := fmt.Sprintf("Deploying chart %s as release %s", chartURL, releaseName)
 	output.SetHeaderLines([]string{headerLine})
@@ -78,27 +80,61 @@ func HelmUpgradeOrInstall(
 		installCmd.Version = chartVersion
 		installCmd.ReleaseName = releaseName
 		installCmd.Namespace = namespace
-		installCmd.Wait = true
+		installCmd.Wait = wait
 		installCmd.Timeout = timeout
 		installCmd.Devel = true                                 // If version is development, accept it
 		installCmd.SkipSchemaValidation = !validateValuesSchema // Disable schema validation for legacy charts
+		installCmd.PostRenderer = postRenderer
 		chartPathOptions = &installCmd.ChartPathOptions
 	} else {
 		output.AppendLinef("Existing release found (version %s), upgrade existing release", existingRelease.Chart.Metadata.Version)
 		upgradeCmd = action.NewUpgrade(actionConfig)
 		upgradeCmd.Version = chartVersion
 		upgradeCmd.Namespace = namespace
-		upgradeCmd.Wait = true
+		upgradeCmd.Wait = wait
 		upgradeCmd.Timeout = timeout
 		upgradeCmd.MaxHistory = 10                              // Keep 10 releases max
 		upgradeCmd.Devel = true                                 // If version is development, accept it
 		upgradeCmd.Atomic = false                               // Don't rollback on failures to not hide errors
 		upgradeCmd.CleanupOnFail = true                         // Clean resources on failure
 		upgradeCmd.SkipSchemaValidation = !validateValuesSchema // Disable schema validation for legacy charts
+		upgradeCmd.PostRenderer = postRenderer
 		chartPathOptions = &upgradeCmd.ChartPathOptions
 	}
 
 	// Load (download) Helm chart
+	loadedChart, err := loadHelmChart(output, chartPathOptions, chartURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve the final values
+	finalValueMap, err := ResolveHelmValues(output, valuesFiles, defaultValues, cliSetValues, requiredValues)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run install or upgrade install
+	output.AppendLine("Starting Helm deployment...")
+	if installCmd != nil {
+		output.AppendLine("Installing new release...")
+		release, err := installCmd.Run(loadedChart, finalValueMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to install the Helm chart: %w", err)
+		}
+		return release, nil
+	} else {
+		output.AppendLine("Upgrading existing release...")
+		release, err := upgradeCmd.Run(releaseName, loadedChart, finalValueMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upgrade an existing Helm release: %w", err)
+		}
+		return release, nil
+	}
+}
+
+// loadHelmChart locates (downloads if needed) and loads the Helm chart.
+func loadHelmChart(output *tui.TaskOutput, chartPathOptions *action.ChartPathOptions, chartURL string) (*chart.Chart, error) {
 	output.AppendLine("Loading Helm chart...")
 
 	helmClient := cli.New()
@@ -114,6 +150,20 @@ func HelmUpgradeOrInstall(
 	}
 
 	output.AppendLinef("Chart loaded: %s (version %s)", loadedChart.Name(), loadedChart.Metadata.Version)
+	return loadedChart, nil
+}
+
+// ResolveHelmValues resolves the final Helm values from valuesFiles, defaultValues, cliSetValues,
+// and requiredValues. See HelmUpgradeOrInstall for the precedence rules. The output may be nil
+// to resolve the values silently.
+func ResolveHelmValues(output *tui.TaskOutput, valuesFiles []string, defaultValues, cliSetValues, requiredValues map[string]any) (map[string]any, error) {
+	// Validate that defaultValues and requiredValues have correct types
+	if err := validateHelmValuesTypes(defaultValues, "defaultValues"); err != nil {
+		return nil, fmt.Errorf("invalid defaultValues: %w", err)
+	}
+	if err := validateHelmValuesTypes(requiredValues, "requiredValues"); err != nil {
+		return nil, fmt.Errorf("invalid requiredValues: %w", err)
+	}
 
 	// Construct base values
 	baseValues := map[string]any{}
@@ -124,7 +174,9 @@ func HelmUpgradeOrInstall(
 	// Load values from files if any
 	filesValueMap := map[string]any{}
 	for _, valuesFile := range valuesFiles {
-		output.AppendLinef("Loading values from: %s", valuesFile)
+		if output != nil {
+			output.AppendLinef("Loading values from: %s", valuesFile)
+		}
 		values, err := chartutil.ReadValuesFile(valuesFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read values file: %w", err)
@@ -144,7 +196,7 @@ func HelmUpgradeOrInstall(
 
 	// Apply and verify requiredValues are honored
 	if requiredValues != nil {
-		err = checkRequiredValues(finalValueMap, requiredValues)
+		err := checkRequiredValues(finalValueMap, requiredValues)
 		if err != nil {
 			return nil, fmt.Errorf("invalid values in helm value files %v: %w", valuesFiles, err)
 		}
@@ -159,23 +211,7 @@ func HelmUpgradeOrInstall(
 		log.Debug().Msgf("Final Helm values:\n%s", finalValuesYAML)
 	}
 
-	// Run install or upgrade install
-	output.AppendLine("Starting Helm deployment...")
-	if installCmd != nil {
-		output.AppendLine("Installing new release...")
-		release, err := installCmd.Run(loadedChart, finalValueMap)
-		if err != nil {
-			return nil, fmt.Errorf("failed to install the Helm chart: %w", err)
-		}
-		return release, nil
-	} else {
-		output.AppendLine("Upgrading existing release...")
-		release, err := upgradeCmd.Run(releaseName, loadedChart, finalValueMap)
-		if err != nil {
-			return nil, fmt.Errorf("failed to upgrade an existing Helm release: %w", err)
-		}
-		return release, nil
-	}
+	return finalValueMap, nil
 }
 
 // Combine two Helm values maps into one. On conflicts, the fields in 'override' win
@@ -0,0 +1,55 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package helmutil
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/metaplay/cli/internal/tui"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/postrender"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// UpgradeReleaseValues upgrades the existing Helm release with new values, using the same chart
+// (and chart version) that the release was deployed with, and waits for the resources to become
+// ready. The values replace the release's values entirely, so they should be based on the
+// existing release's values (release.Config).
+func UpgradeReleaseValues(
+	output *tui.TaskOutput,
+	actionConfig *action.Configuration,
+	existingRelease *release.Release,
+	values map[string]any,
+	postRenderer postrender.PostRenderer,
+	timeout time.Duration,
+) (*release.Release, error) {
+	if existingRelease.Chart == nil || existingRelease.Chart.Metadata == nil {
+		return nil, fmt.Errorf("Helm release %s has no chart information", existingRelease.Name)
+	}
+	output.SetHeaderLines([]string{fmt.Sprintf("Upgrading release %s (chart version %s)", existingRelease.Name, existingRelease.Chart.Metadata.Version)})
+
+	// Pipe Helm output to task output
+	actionConfig.Log = func(format string, args ...any) {
+		output.AppendLine(strings.TrimRight(fmt.Sprintf(format, args...), "\r\n"))
+	}
+
+	upgradeCmd := action.NewUpgrade(actionConfig)
+	upgradeCmd.Version = existingRelease.Chart.Metadata.Version
+	upgradeCmd.Namespace = existingRelease.Namespace
+	upgradeCmd.Wait = true
+	upgradeCmd.Timeout = timeout
+	upgradeCmd.MaxHistory = 10      // Keep 10 releases max, same as with deploys
+	upgradeCmd.Atomic = false       // Don't rollback on failures to not hide errors
+	upgradeCmd.CleanupOnFail = true // Clean resources on failure
+	upgradeCmd.PostRenderer = postRenderer
+
+	deployedRelease, err := upgradeCmd.Run(existingRelease.Name, existingRelease.Chart, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upgrade Helm release %s: %w", existingRelease.Name, err)
+	}
+	return deployedRelease, nil
+}
@@ -76,3 +76,16 @@ func PostFormWithRetry(url string, formData string) ([]byte, int, error) {
 	}
 	return resp.Body(), resp.StatusCode(), nil
 }
+
+// ProbeURL performs a single HTTP GET to the specified URL without retries, to check whether
+// the service is reachable. Returns the HTTP status code and the round-trip latency.
+func ProbeURL(url string, timeout time.Duration) (int, time.Duration, error) {
+	client := resty.New().SetTimeout(timeout)
+	startTime := time.Now()
+	resp, err := client.R().Get(url)
+	latency := time.Since(startTime)
+	if err != nil {
+		return 0, latency, fmt.Errorf("GET request to %s failed: %w", url, err)
+	}
+	return resp.StatusCode(), latency, nil
+}
@@ -38,6 +38,16 @@ type MetaplayInfraOptions struct {
 
 // FetchDatabaseShardsFromSecret fetches database shard configuration from the 'metaplay-deployment-runtime-options' Kubernetes secret.
 func FetchDatabaseShardsFromSecret(ctx context.Context, kubeCli *envapi.KubeClient, namespace string) ([]DatabaseShardConfig, error) {
+	database, err := FetchDatabaseConfigFromSecret(ctx, kubeCli, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return database.Shards, nil
+}
+
+// FetchDatabaseConfigFromSecret fetches the full database configuration, including the number of
+// active shards, from the 'metaplay-deployment-runtime-options' Kubernetes secret.
+func FetchDatabaseConfigFromSecret(ctx context.Context, kubeCli *envapi.KubeClient, namespace string) (*MetaplayInfraDatabase, error) {
 	// Get the metaplay-deployment-runtime-options secret.
 	log.Debug().Msg("Fetching Kubernetes secret 'metaplay-deployment-runtime-options'...")
 	secret, err := kubeCli.Clientset.CoreV1().Secrets(namespace).Get(ctx, "metaplay-deployment-runtime-options", metav1.GetOptions{})
@@ -70,5 +80,5 @@ func FetchDatabaseShardsFromSecret(ctx context.Context, kubeCli *envapi.KubeClie
 	}
 
 	log.Debug().Msgf("Found %d database shard(s) in infra options.json", len(infraOptions.Database.Shards))
-	return infraOptions.Database.Shards, nil
+	return &infraOptions.Database, nil
 }
@@ -7,6 +7,7 @@ package kubeutil
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -223,6 +224,21 @@ func waitForContainerReady(ctx context.Context, kubeCli *envapi.KubeClient, podN
 
 // ExecInDebugContainer executes a command in the debug container using Kubernetes API (replaces execCommand)
 func ExecInDebugContainer(ctx context.Context, kubeCli *envapi.KubeClient, podName string, debugContainerName string, command string) (string, string, error) {
+	stdOut := new(strings.Builder)
+	stdErr := new(strings.Builder)
+
+	err := StreamFromDebugContainer(ctx, kubeCli, podName, debugContainerName, command, stdOut, stdErr)
+	if err != nil {
+		return stdOut.String(), stdErr.String(), fmt.Errorf("error streaming command: %w, stdout: %s, stderr: %s", err, stdOut.String(), stdErr.String())
+	}
+
+	return stdOut.String(), stdErr.String(), nil
+}
+
+// StreamFromDebugContainer executes a command in the debug container and streams its output
+// into the given writers as it is produced. Useful for long-running commands whose output
+// should be processed incrementally. Returns when the command exits or the context is canceled.
+func StreamFromDebugContainer(ctx context.Context, kubeCli *envapi.KubeClient, podName string, debugContainerName string, command string, stdout io.Writer, stderr io.Writer) error {
 	req := kubeCli.Clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(podName).
@@ -239,21 +255,12 @@ func ExecInDebugContainer(ctx context.Context, kubeCli *envapi.KubeClient, podNa
 
 	exec, err := remotecommand.NewSPDYExecutor(kubeCli.RestConfig, "POST", req.URL())
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create executor: %w", err)
+		return fmt.Errorf("failed to create executor: %w", err)
 	}
 
-	stdOut := new(strings.Builder)
-	stdErr := new(strings.Builder)
-
-	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
-		Stdout: stdOut,
-		Stderr: stdErr,
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
 		Tty:    false,
 	})
-
-	if err != nil {
-		return stdOut.String(), stdErr.String(), fmt.Errorf("error streaming command: %w, stdout: %s, stderr: %s", err, stdOut.String(), stdErr.String())
-	}
-
-	return stdOut.String(), stdErr.String(), nil
 }
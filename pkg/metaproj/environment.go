@@ -35,15 +35,17 @@ var environmentTypeToRuntimeOptionsFileMapping = map[portalapi.EnvironmentType]s
 // Per-environment configuration from 'metaplay-project.yaml'.
 // Note: When adding new fields, remember to update ValidateProjectConfig().
 type ProjectEnvironmentConfig struct {
-	Name                string                    `yaml:"name"`                          // Name of the environment.
-	HostingType         portalapi.HostingType     `yaml:"hostingType"`                   // Type of hosting for the environment (Metaplay-hosted vs self-hosted)
-	HumanID             string                    `yaml:"humanId"`                       // Stable human ID of the environment. Also the Kubernetes namespace.
-	Type                portalapi.EnvironmentType `yaml:"type"`                          // Type of the environment (eg, development, Staging, production).
-	StackDomain         string                    `yaml:"stackDomain"`                   // Stack base domain (eg, 'p1.metaplay.io').
-	ServerValuesFile    string                    `yaml:"serverValuesFile,omitempty"`    // Relative path (from metaplay-project.yaml) to the game server deployment Helm values file.
-	BotClientValuesFile string                    `yaml:"botclientValuesFile,omitempty"` // Relative path (from metaplay-project.yaml) to the bot client deployment Helm values file.
-	AuthProvider        string                    `yaml:"authProvider,omitempty"`        // Name of the auth provider to use for this environment. Defaults to 'metaplay'.
-	Aliases             []string                  `yaml:"aliases,omitempty"`             // Short aliases for the environment, e.g., 'dev', 'prod'.
+	Name                  string                    `yaml:"name"`                            // Name of the environment.
+	HostingType           portalapi.HostingType     `yaml:"hostingType"`                     // Type of hosting for the environment (Metaplay-hosted vs self-hosted)
+	HumanID               string                    `yaml:"humanId"`                         // Stable human ID of the environment. Also the Kubernetes namespace.
+	Type                  portalapi.EnvironmentType `yaml:"type"`                            // Type of the environment (eg, development, Staging, production).
+	StackDomain           string                    `yaml:"stackDomain"`                     // Stack base domain (eg, 'p1.metaplay.io').
+	ServerValuesFile      string                    `yaml:"serverValuesFile,omitempty"`      // Relative path (from metaplay-project.yaml) to the game server deployment Helm values file.
+	BotClientValuesFile   string                    `yaml:"botclientValuesFile,omitempty"`   // Relative path (from metaplay-project.yaml) to the bot client deployment Helm values file.
+	ServerPostRenderer    string                    `yaml:"serverPostRenderer,omitempty"`    // Relative path (from metaplay-project.yaml) to the game server Helm post-renderer executable or kustomize overlay directory.
+	BotClientPostRenderer string                    `yaml:"botclientPostRenderer,omitempty"` // Relative path (from metaplay-project.yaml) to the bot client Helm post-renderer executable or kustomize overlay directory.
+	AuthProvider          string                    `yaml:"authProvider,omitempty"`          // Name of the auth provider to use for this environment. Defaults to 'metaplay'.
+	Aliases               []string                  `yaml:"aliases,omitempty"`               // Short aliases for the environment, e.g., 'dev', 'prod'.
 }
 
 // Get the Kubernetes namespace for this environment. Same as HumanID but
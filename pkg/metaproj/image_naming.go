@@ -0,0 +1,170 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Placeholders supported in the 'imageNaming' template in metaplay-project.yaml.
+var imageNamingPlaceholders = []string{
+	"registry",    // Registry host (and optional path) to prefix the images with, eg, 'registry.example.com'; empty for plain local images
+	"project",     // Project human ID, eg, 'lovely-wombats'
+	"component",   // Image component, eg, 'server' or 'botclient'
+	"tag",         // Tag given by the user or the default tag
+	"date",        // Build date and time in UTC, eg, '20250131-133012'
+	"commit",      // Git commit ID of the build
+	"buildNumber", // Build number from CI
+}
+
+// Matches a '{placeholder}' in the image naming template.
+var imageNamingPlaceholderRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Matches the default image tag '<date>[-<commit>]' of 'metaplay build image', eg,
+// '20250131-133012-1a27c25753'.
+var defaultImageTagRegexp = regexp.MustCompile(`^(\d{8}-\d{6})(?:-(.+))?$`)
+
+// ImageNameParams are the values filled into the image naming template.
+type ImageNameParams struct {
+	Registry    string
+	Project     string
+	Component   string
+	Tag         string
+	Date        string
+	Commit      string
+	BuildNumber string
+}
+
+// ValidateImageNamingTemplate checks that the image naming template only uses known
+// placeholders and produces names with both a repository and a tag part.
+func ValidateImageNamingTemplate(template string) error {
+	if strings.Count(template, "{") != strings.Count(template, "}") {
+		return fmt.Errorf("invalid imageNaming '%s': unbalanced braces", template)
+	}
+
+	for _, match := range imageNamingPlaceholderRegexp.FindAllStringSubmatch(template, -1) {
+		if !slices.Contains(imageNamingPlaceholders, match[1]) {
+			return fmt.Errorf("invalid imageNaming '%s': unknown placeholder '{%s}', supported placeholders are: {%s}", template, match[1], strings.Join(imageNamingPlaceholders, "}, {"))
+		}
+	}
+
+	// Tag is the part after the last ':' that is not part of a registry host port, eg, in
+	// 'localhost:5000/{project}'.
+	colonNdx := strings.LastIndex(template, ":")
+	if colonNdx == -1 || strings.Contains(template[colonNdx:], "/") {
+		return fmt.Errorf("invalid imageNaming '%s': must include a tag, eg, '{project}/{component}:{tag}'", template)
+	}
+	if colonNdx == 0 || colonNdx == len(template)-1 {
+		return fmt.Errorf("invalid imageNaming '%s': both the image name and tag must be non-empty", template)
+	}
+
+	return nil
+}
+
+// ParseImageTag parses the build date and commit ID out of an image tag in the default
+// '<date>[-<commit>]' format of 'metaplay build image'. Other tags return empty values.
+func ParseImageTag(tag string) (date, commit string) {
+	match := defaultImageTagRegexp.FindStringSubmatch(tag)
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
+}
+
+// RenderImageName fills in the image naming template with the given parameters. Path
+// segments left empty (eg, '{registry}/' when no registry is given) are dropped and the
+// repository part is converted to lower-case as required by docker.
+func RenderImageName(template string, params ImageNameParams) (string, error) {
+	repository, tag, err := renderImageNameParts(template, params)
+	if err != nil {
+		return "", err
+	}
+	if repository == "" || tag == "" {
+		return "", fmt.Errorf("image naming template '%s' produced an invalid image name '%s:%s'", template, repository, tag)
+	}
+	return repository + ":" + tag, nil
+}
+
+// RenderImageNameWithTag renders the repository from the image naming template and uses the
+// given tag as-is, eg, for referring to an image built earlier. The build date and commit ID
+// are parsed from the tag, if not given in the params, so that they can be used in the
+// repository part of the template.
+func RenderImageNameWithTag(template string, params ImageNameParams, tag string) (string, error) {
+	date, commit := ParseImageTag(tag)
+	params.Tag = tag
+	params.Date = cmp.Or(params.Date, date)
+	params.Commit = cmp.Or(params.Commit, commit)
+	repository, _, err := renderImageNameParts(template, params)
+	if err != nil {
+		return "", err
+	}
+	if repository == "" || tag == "" {
+		return "", fmt.Errorf("image naming template '%s' produced an invalid image name '%s:%s'", template, repository, tag)
+	}
+	return repository + ":" + tag, nil
+}
+
+// renderImageNameParts renders the repository and tag parts of the image naming template.
+func renderImageNameParts(template string, params ImageNameParams) (string, string, error) {
+	if err := ValidateImageNamingTemplate(template); err != nil {
+		return "", "", err
+	}
+
+	values := map[string]string{
+		"registry":    params.Registry,
+		"project":     params.Project,
+		"component":   params.Component,
+		"tag":         params.Tag,
+		"date":        params.Date,
+		"commit":      params.Commit,
+		"buildNumber": params.BuildNumber,
+	}
+	rendered := imageNamingPlaceholderRegexp.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return values[placeholder[1:len(placeholder)-1]]
+	})
+
+	// Split into repository and tag.
+	colonNdx := strings.LastIndex(rendered, ":")
+	repository := rendered[:colonNdx]
+	tag := rendered[colonNdx+1:]
+
+	// Drop empty path segments from the repository.
+	segments := slices.DeleteFunc(strings.Split(repository, "/"), func(segment string) bool { return segment == "" })
+	repository = strings.ToLower(strings.Join(segments, "/"))
+
+	// Trim separators left dangling by empty values, eg, '{date}-{commit}' without a commit.
+	tag = strings.Trim(tag, "-_.")
+
+	return repository, tag, nil
+}
+
+// RenderImageName renders a docker image name using the project's 'imageNaming' template.
+// The project ID is filled in automatically. Must only be called when the template is set.
+func (project *MetaplayProject) RenderImageName(params ImageNameParams) (string, error) {
+	if project.Config.ImageNaming == "" {
+		return "", fmt.Errorf("project has no imageNaming template")
+	}
+	if params.Project == "" {
+		params.Project = project.Config.ProjectHumanID
+	}
+	return RenderImageName(project.Config.ImageNaming, params)
+}
+
+// RenderImageNameWithTag renders the name of an image with the given tag using the project's
+// 'imageNaming' template, see RenderImageNameWithTag(). The project ID is filled in automatically.
+// Must only be called when the template is set.
+func (project *MetaplayProject) RenderImageNameWithTag(params ImageNameParams, tag string) (string, error) {
+	if project.Config.ImageNaming == "" {
+		return "", fmt.Errorf("project has no imageNaming template")
+	}
+	if params.Project == "" {
+		params.Project = project.Config.ProjectHumanID
+	}
+	return RenderImageNameWithTag(project.Config.ImageNaming, params, tag)
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"testing"
+)
+
+func TestValidateImageNamingTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		isValid  bool
+	}{
+		{"{project}:{tag}", true},
+		{"{registry}/{project}/{component}:{date}-{commit}", true},
+		{"localhost:5000/{project}-{component}:{buildNumber}", true},
+		{"{project}/{component}", false},
+		{"localhost:5000/{project}", false},
+		{"{project}:", false},
+		{":{tag}", false},
+		{"{project}:{unknown}", false},
+		{"{project:{tag}", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateImageNamingTemplate(test.template)
+		if test.isValid && err != nil {
+			t.Errorf("Expected '%s' to be valid, got error: %v", test.template, err)
+		}
+		if !test.isValid && err == nil {
+			t.Errorf("Expected '%s' to be invalid, but no error returned", test.template)
+		}
+	}
+}
+
+func TestRenderImageName(t *testing.T) {
+	params := ImageNameParams{
+		Project:   "Lovely-Wombats",
+		Component: "server",
+		Tag:       "364cff09",
+		Date:      "20250131-133012",
+		Commit:    "1a27c25753",
+	}
+
+	tests := []struct {
+		template string
+		registry string
+		expected string
+	}{
+		{"{project}:{tag}", "", "lovely-wombats:364cff09"},
+		{"{registry}/{project}/{component}:{date}-{commit}", "registry.example.com", "registry.example.com/lovely-wombats/server:20250131-133012-1a27c25753"},
+		{"{registry}/{project}/{component}:{date}-{commit}", "", "lovely-wombats/server:20250131-133012-1a27c25753"},
+		{"{project}-{component}:{date}-{buildNumber}", "", "lovely-wombats-server:20250131-133012"},
+	}
+
+	for _, test := range tests {
+		p := params
+		p.Registry = test.registry
+		name, err := RenderImageName(test.template, p)
+		if err != nil {
+			t.Errorf("RenderImageName('%s') failed: %v", test.template, err)
+			continue
+		}
+		if name != test.expected {
+			t.Errorf("RenderImageName('%s') = '%s', expected '%s'", test.template, name, test.expected)
+		}
+	}
+
+	// Templates rendering to an empty tag are rejected.
+	if _, err := RenderImageName("{project}:{buildNumber}", params); err == nil {
+		t.Errorf("Expected an error for empty tag")
+	}
+}
+
+func TestParseImageTag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		date   string
+		commit string
+	}{
+		{"20250131-133012-1a27c25753", "20250131-133012", "1a27c25753"},
+		{"20250131-133012", "20250131-133012", ""},
+		{"364cff09", "", ""},
+		{"test", "", ""},
+	}
+
+	for _, test := range tests {
+		date, commit := ParseImageTag(test.tag)
+		if date != test.date || commit != test.commit {
+			t.Errorf("ParseImageTag('%s') = ('%s', '%s'), expected ('%s', '%s')", test.tag, date, commit, test.date, test.commit)
+		}
+	}
+}
+
+func TestRenderImageNameWithTag(t *testing.T) {
+	params := ImageNameParams{Project: "lovely-wombats", Component: "server"}
+
+	tests := []struct {
+		template string
+		tag      string
+		expected string
+	}{
+		// The tag part of the template is not required to render to a non-empty tag.
+		{"{registry}/{project}/{component}:{date}-{commit}", "364cff09", "lovely-wombats/server:364cff09"},
+		{"{registry}/{project}/{component}:{date}-{commit}", "test", "lovely-wombats/server:test"},
+		// The date and commit are parsed from the default tag format.
+		{"{project}/{component}-{commit}:{tag}", "20250131-133012-1a27c25753", "lovely-wombats/server-1a27c25753:20250131-133012-1a27c25753"},
+	}
+
+	for _, test := range tests {
+		name, err := RenderImageNameWithTag(test.template, params, test.tag)
+		if err != nil {
+			t.Errorf("RenderImageNameWithTag('%s', '%s') failed: %v", test.template, test.tag, err)
+			continue
+		}
+		if name != test.expected {
+			t.Errorf("RenderImageNameWithTag('%s', '%s') = '%s', expected '%s'", test.template, test.tag, name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path of the developer-specific environment variables file, relative to the project directory.
+// The file is meant to be gitignored as it can contain secrets.
+const LocalEnvFilePath = ".metaplay/env.local.yaml"
+
+// Valid environment variable names.
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LocalEnvFile contains the developer-specific environment variables injected into the locally
+// run game server and bot client processes and containers (.metaplay/env.local.yaml).
+type LocalEnvFile struct {
+	Env       map[string]string `yaml:"env,omitempty"`       // Variables for both the game server and the bot client
+	Server    map[string]string `yaml:"server,omitempty"`    // Variables only for the game server
+	BotClient map[string]string `yaml:"botClient,omitempty"` // Variables only for the bot client
+}
+
+// LoadLocalEnvFile loads the local environment variables file from the project directory.
+// Returns an empty file if it does not exist.
+func LoadLocalEnvFile(projectDir string) (*LocalEnvFile, error) {
+	filePath := filepath.Join(projectDir, LocalEnvFilePath)
+	content, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return &LocalEnvFile{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", LocalEnvFilePath, err)
+	}
+
+	var envFile LocalEnvFile
+	if err := yaml.Unmarshal(content, &envFile); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LocalEnvFilePath, err)
+	}
+
+	for _, vars := range []map[string]string{envFile.Env, envFile.Server, envFile.BotClient} {
+		for name := range vars {
+			if !envVarNamePattern.MatchString(name) {
+				return nil, fmt.Errorf("invalid environment variable name '%s' in %s", name, LocalEnvFilePath)
+			}
+		}
+	}
+
+	return &envFile, nil
+}
+
+// IsEmpty returns true if the file defines no variables.
+func (envFile *LocalEnvFile) IsEmpty() bool {
+	return len(envFile.Env) == 0 && len(envFile.Server) == 0 && len(envFile.BotClient) == 0
+}
+
+// ServerEnv returns the variables for the game server. The server-specific variables take
+// precedence over the shared ones.
+func (envFile *LocalEnvFile) ServerEnv() map[string]string {
+	env := maps.Clone(envFile.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, envFile.Server)
+	return env
+}
+
+// BotClientEnv returns the variables for the bot client. The bot-specific variables take
+// precedence over the shared ones.
+func (envFile *LocalEnvFile) BotClientEnv() map[string]string {
+	env := maps.Clone(envFile.Env)
+	if env == nil {
+		env = map[string]string{}
+	}
+	maps.Copy(env, envFile.BotClient)
+	return env
+}
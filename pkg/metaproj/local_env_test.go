@@ -0,0 +1,94 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLocalEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".metaplay"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, LocalEnvFilePath), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return projectDir
+}
+
+func TestLoadLocalEnvFileMissing(t *testing.T) {
+	envFile, err := LoadLocalEnvFile(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !envFile.IsEmpty() {
+		t.Errorf("expected empty file, got %+v", envFile)
+	}
+	if len(envFile.ServerEnv()) != 0 || len(envFile.BotClientEnv()) != 0 {
+		t.Errorf("expected no variables")
+	}
+}
+
+func TestLoadLocalEnvFileMerge(t *testing.T) {
+	projectDir := writeLocalEnvFile(t, `
+env:
+  SHARED: shared
+  OVERRIDDEN: shared
+server:
+  OVERRIDDEN: server
+  SERVER_ONLY: "1"
+botClient:
+  BOT_ONLY: bot
+`)
+	envFile, err := LoadLocalEnvFile(projectDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	serverEnv := envFile.ServerEnv()
+	want := map[string]string{"SHARED": "shared", "OVERRIDDEN": "server", "SERVER_ONLY": "1"}
+	if len(serverEnv) != len(want) {
+		t.Errorf("ServerEnv() = %v, want %v", serverEnv, want)
+	}
+	for name, value := range want {
+		if serverEnv[name] != value {
+			t.Errorf("ServerEnv()[%s] = %q, want %q", name, serverEnv[name], value)
+		}
+	}
+
+	botEnv := envFile.BotClientEnv()
+	want = map[string]string{"SHARED": "shared", "OVERRIDDEN": "shared", "BOT_ONLY": "bot"}
+	if len(botEnv) != len(want) {
+		t.Errorf("BotClientEnv() = %v, want %v", botEnv, want)
+	}
+	for name, value := range want {
+		if botEnv[name] != value {
+			t.Errorf("BotClientEnv()[%s] = %q, want %q", name, botEnv[name], value)
+		}
+	}
+
+	// Merging must not modify the shared variables.
+	if envFile.Env["OVERRIDDEN"] != "shared" {
+		t.Errorf("shared variables were modified: %v", envFile.Env)
+	}
+}
+
+func TestLoadLocalEnvFileInvalidName(t *testing.T) {
+	projectDir := writeLocalEnvFile(t, "server:\n  INVALID-NAME: value\n")
+	if _, err := LoadLocalEnvFile(projectDir); err == nil {
+		t.Error("expected error for invalid variable name")
+	}
+}
+
+func TestLoadLocalEnvFileInvalidYaml(t *testing.T) {
+	projectDir := writeLocalEnvFile(t, "env: [not, a, map]\n")
+	if _, err := LoadLocalEnvFile(projectDir); err == nil {
+		t.Error("expected error for invalid yaml")
+	}
+}
@@ -90,6 +90,15 @@ func (project *MetaplayProject) GetUnityProjectDir() string {
 	return filepath.Join(project.RelativeDir, project.Config.UnityProjectDir)
 }
 
+func (project *MetaplayProject) GetUnrealProjectDir() string {
+	return filepath.Join(project.RelativeDir, project.Config.UnrealProjectDir)
+}
+
+// Return the game engine of the project's client.
+func (project *MetaplayProject) GetClientEngine() ClientEngine {
+	return project.Config.GetClientEngine()
+}
+
 // Return the relative directory to Backend/Server.
 func (project *MetaplayProject) GetServerDir() string {
 	return filepath.Join(project.RelativeDir, project.Config.BackendDir, "Server")
@@ -107,6 +116,29 @@ func (project *MetaplayProject) GetDashboardDir() string {
 	return filepath.Join(project.RelativeDir, dashboardConfig.RootDir)
 }
 
+// Return the relative directory to the SDK's default dashboard, used when the project has no
+// custom dashboard.
+func (project *MetaplayProject) GetSdkDefaultDashboardDir() string {
+	return filepath.Join(project.GetSdkRootDir(), "Frontend", "DefaultDashboard")
+}
+
+// Return the relative directory to the .NET project that builds the game config and localization archives.
+// Defaults to Backend/Server if not explicitly configured.
+func (project *MetaplayProject) GetGameConfigBuilderDir() string {
+	if project.Config.GameConfigBuilderDir == "" {
+		return project.GetServerDir()
+	}
+	return filepath.Join(project.RelativeDir, project.Config.GameConfigBuilderDir)
+}
+
+// Return the relative directory with local copies of the Helm charts (or empty if not configured).
+func (project *MetaplayProject) GetLocalChartsDir() string {
+	if project.Config.LocalChartsDir == "" {
+		return ""
+	}
+	return filepath.Join(project.RelativeDir, project.Config.LocalChartsDir)
+}
+
 func (project *MetaplayProject) GetServerValuesFiles(envConfig *ProjectEnvironmentConfig) []string {
 	if envConfig.ServerValuesFile != "" {
 		return []string{
@@ -127,6 +159,37 @@ func (project *MetaplayProject) GetBotClientValuesFiles(envConfig *ProjectEnviro
 	}
 }
 
+// Get the path to the game server Helm post-renderer (executable or kustomize overlay directory),
+// or an empty string if none is configured for the environment.
+func (project *MetaplayProject) GetServerPostRenderer(envConfig *ProjectEnvironmentConfig) string {
+	if envConfig.ServerPostRenderer == "" {
+		return ""
+	}
+	return project.resolvePostRendererPath(envConfig.ServerPostRenderer)
+}
+
+// Get the path to the bot client Helm post-renderer (executable or kustomize overlay directory),
+// or an empty string if none is configured for the environment.
+func (project *MetaplayProject) GetBotClientPostRenderer(envConfig *ProjectEnvironmentConfig) string {
+	if envConfig.BotClientPostRenderer == "" {
+		return ""
+	}
+	return project.resolvePostRendererPath(envConfig.BotClientPostRenderer)
+}
+
+// resolvePostRendererPath returns the absolute path to a post-renderer relative to the project
+// directory. The path must not be a bare file name, or it would be searched for in PATH instead
+// of the project directory.
+func (project *MetaplayProject) resolvePostRendererPath(path string) string {
+	joined := filepath.Join(project.RelativeDir, path)
+	absPath, err := filepath.Abs(joined)
+	if err != nil {
+		// Only fails if the working directory cannot be resolved.
+		return "." + string(filepath.Separator) + joined
+	}
+	return absPath
+}
+
 // Load the Metaplay project config file (metaplay-project.yaml) from the project directory.
 func LoadProjectConfigFile(projectDir string) (*ProjectConfig, error) {
 	// Check that the provided path points to a file or directory.
@@ -194,9 +257,116 @@ func validateProjectDir(projectDir, fieldName, dirValue string) error {
 	return nil
 }
 
-// validateHelmChartRepositoryURL checks if the given input is a valid Helm chart repository URL.
+// validateClientProjectDir checks that at most one of the Unity or the Unreal Engine client
+// project directories is specified and that it points to a valid project. Server-only projects
+// have neither.
+func validateClientProjectDir(projectDir string, config *ProjectConfig) error {
+	if config.UnityProjectDir != "" && config.UnrealProjectDir != "" {
+		return fmt.Errorf("fields 'unityProjectDir' and 'unrealProjectDir' cannot both be specified")
+	}
+
+	switch config.GetClientEngine() {
+	case ClientEngineUnity:
+		return validateProjectDir(projectDir, "unityProjectDir", config.UnityProjectDir)
+	case ClientEngineNone:
+		return nil
+	}
+
+	if err := validateProjectDir(projectDir, "unrealProjectDir", config.UnrealProjectDir); err != nil {
+		return err
+	}
+	if _, err := FindUnrealProjectFile(filepath.Join(projectDir, config.UnrealProjectDir)); err != nil {
+		return fmt.Errorf("field 'unrealProjectDir' ('%s') does not point to a valid Unreal Engine project: %w", config.UnrealProjectDir, err)
+	}
+	return nil
+}
+
+// validateImageSigningConfig checks that the signatures can be verified when signed images are
+// required: either a public key or the keyless signer's identity and issuer must be specified.
+func validateImageSigningConfig(config *ProjectConfig) error {
+	signing := config.ImageSigning
+	if signing != nil && (signing.CertificateIdentityRegexp != "") != (signing.CertificateOidcIssuer != "") {
+		return fmt.Errorf("imageSigning.certificateIdentityRegexp and imageSigning.certificateOidcIssuer must be specified together")
+	}
+	if signing != nil && signing.CertificateIdentityRegexp != "" {
+		if _, err := regexp.Compile(signing.CertificateIdentityRegexp); err != nil {
+			return fmt.Errorf("invalid imageSigning.certificateIdentityRegexp: %v", err)
+		}
+	}
+
+	if config.RequireSignedImages && (signing == nil || (signing.PublicKey == "" && signing.CertificateIdentityRegexp == "")) {
+		return clierrors.New("Missing imageSigning config for verifying the signatures (requireSignedImages is enabled)").
+			WithSuggestion("Specify 'imageSigning.publicKey', or 'imageSigning.certificateIdentityRegexp' and 'imageSigning.certificateOidcIssuer' for keyless signatures")
+	}
+	return nil
+}
+
+// validateCustomIntegrationTests checks the custom integration test containers: the names must
+// be unique, each test must have either a build target or an image, and the mounts must be of
+// the form 'hostPath:containerPath'.
+func validateCustomIntegrationTests(config *ProjectConfig) error {
+	if config.IntegrationTests == nil {
+		return nil
+	}
+
+	validName := regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	names := map[string]bool{}
+	for ndx, test := range config.IntegrationTests.Tests {
+		if !validName.MatchString(test.Name) {
+			return fmt.Errorf("invalid integrationTests.tests[%d].name '%s': must contain only lowercase alphanumeric characters and dashes", ndx, test.Name)
+		}
+		if names[test.Name] {
+			return fmt.Errorf("integrationTests.tests has multiple tests named '%s'", test.Name)
+		}
+		names[test.Name] = true
+
+		if (test.Target == "") == (test.Image == "") {
+			return fmt.Errorf("integration test '%s' must specify exactly one of 'target' or 'image'", test.Name)
+		}
+		if test.Dockerfile != "" && test.Target == "" {
+			return fmt.Errorf("integration test '%s' specifies 'dockerfile' without a 'target' to build", test.Name)
+		}
+		for _, mount := range test.Mounts {
+			hostPath, containerPath, found := SplitIntegrationTestMount(mount)
+			if !found || hostPath == "" || !strings.HasPrefix(containerPath, "/") {
+				return fmt.Errorf("integration test '%s' has invalid mount '%s': must be of the form 'hostPath:/containerPath'", test.Name, mount)
+			}
+		}
+	}
+	return nil
+}
+
+// SplitIntegrationTestMount splits the 'hostPath:containerPath' mount of a custom integration test
+// at the last colon, so that Windows host paths (eg, 'C:\tests:/tests') are handled correctly.
+func SplitIntegrationTestMount(mount string) (hostPath string, containerPath string, found bool) {
+	ndx := strings.LastIndex(mount, ":")
+	if ndx < 0 {
+		return mount, "", false
+	}
+	return mount[:ndx], mount[ndx+1:], true
+}
+
+// validateOIDCFederationConfig checks that the trusted issuer is an https URL and that the
+// audience is specified, if OIDC federation is configured.
+func validateOIDCFederationConfig(config *ProjectConfig) error {
+	federation := config.OIDCFederation
+	if federation == nil {
+		return nil
+	}
+	issuerURL, err := url.Parse(federation.Issuer)
+	if err != nil || issuerURL.Scheme != "https" || issuerURL.Host == "" {
+		return fmt.Errorf("oidcFederation.issuer must be an https URL, eg, 'https://token.actions.githubusercontent.com', got '%s'", federation.Issuer)
+	}
+	if federation.Audience == "" {
+		return fmt.Errorf("oidcFederation.audience is required")
+	}
+	return nil
+}
+
+// ValidateHelmChartRepositoryURL checks if the given input is a valid Helm chart repository URL:
+// either an http(s) chart repository or an OCI registry, eg, 'oci://registry.example.com/charts'.
 // It returns nil if the URL is valid, or an error describing the issue if invalid.
-func validateHelmChartRepositoryURL(chartRepo string) error {
+func ValidateHelmChartRepositoryURL(chartRepo string) error {
 	// Empty repo is allowed (we use the default).
 	if chartRepo == "" {
 		return nil
@@ -207,9 +377,9 @@ func validateHelmChartRepositoryURL(chartRepo string) error {
 		return fmt.Errorf("invalid helmChartRepository URL: %w", err)
 	}
 
-	// Check if the scheme is either "http" or "https"
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		return fmt.Errorf("invalid helmChartRepository URL scheme: %s (must be 'http' or 'https')", parsedURL.Scheme)
+	// Check if the scheme is either "http", "https", or "oci"
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" && parsedURL.Scheme != "oci" {
+		return fmt.Errorf("invalid helmChartRepository URL scheme: %s (must be 'http', 'https', or 'oci')", parsedURL.Scheme)
 	}
 
 	// Check if the host is not empty
@@ -220,6 +390,9 @@ func validateHelmChartRepositoryURL(chartRepo string) error {
 	return nil
 }
 
+// Helm chart version value that accepts the latest available version, including pre-releases.
+const LatestPrereleaseChartVersion = "latest-prerelease"
+
 // Validate that a particular Helm chart version is a valid one (only do local
 // checks, don't validate existence in the remote repository).
 func validateHelmChartVersion(fieldName string, chartVersion string) error {
@@ -228,17 +401,57 @@ func validateHelmChartVersion(fieldName string, chartVersion string) error {
 		return fmt.Errorf("missing required field %s: specify the version of the chart you want to use", fieldName)
 	}
 
-	// Validate that values other than "latest-prerelease" parse correctly.
-	if chartVersion != "latest-prerelease" {
-		_, err := version.NewVersion(chartVersion)
-		if err != nil {
-			return fmt.Errorf("invalid version string: %w", err)
-		}
+	// Validate that the version or version range parses correctly.
+	if _, err := ParseHelmChartVersionConstraints(chartVersion); err != nil {
+		return fmt.Errorf("invalid %s '%s': %w", fieldName, chartVersion, err)
 	}
 
 	return nil
 }
 
+// ParseHelmChartVersionConstraints parses a Helm chart version specifier into version constraints.
+// The specifier can be an exact version ('0.8.1'), a range of versions ('>=0.8.0 <0.9.0' or
+// '>= 0.8.0, < 0.9.0'), or 'latest-prerelease' to accept any version (nil constraints are returned).
+func ParseHelmChartVersionConstraints(chartVersion string) (version.Constraints, error) {
+	if chartVersion == LatestPrereleaseChartVersion {
+		return nil, nil
+	}
+
+	// Split into individual constraints: both commas and whitespace act as separators,
+	// but an operator separated from its version by whitespace belongs to that version.
+	tokens := strings.FieldsFunc(chartVersion, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+	parts := []string{}
+	for ndx := 0; ndx < len(tokens); ndx++ {
+		token := tokens[ndx]
+		if strings.Trim(token, "=<>!~") == "" {
+			if ndx+1 >= len(tokens) {
+				return nil, fmt.Errorf("missing version after operator '%s'", token)
+			}
+			ndx++
+			token += tokens[ndx]
+		}
+		parts = append(parts, token)
+	}
+
+	constraints, err := version.NewConstraint(strings.Join(parts, ","))
+	if err != nil {
+		return nil, err
+	}
+	return constraints, nil
+}
+
+// IsExactHelmChartVersion returns true if the Helm chart version specifier refers to a single
+// exact version (as opposed to a range of versions or 'latest-prerelease').
+func IsExactHelmChartVersion(chartVersion string) bool {
+	_, err := version.NewVersion(chartVersion)
+	return err == nil
+}
+
 // Apply any defaults to the project config which are not required to be specified.
 func ApplyProjectConfigDefaults(config *ProjectConfig) error {
 	for ndx, envConfig := range config.Environments {
@@ -274,9 +487,23 @@ func ValidateProjectConfig(projectDir string, config *ProjectConfig) error {
 	if err := validateProjectDir(projectDir, "sharedCodeDir", config.SharedCodeDir); err != nil {
 		return err
 	}
-	if err := validateProjectDir(projectDir, "unityProjectDir", config.UnityProjectDir); err != nil {
+	if err := validateClientProjectDir(projectDir, config); err != nil {
 		return err
 	}
+	if config.GameConfigBuilderDir != "" {
+		if err := validateProjectDir(projectDir, "gameConfigBuilderDir", config.GameConfigBuilderDir); err != nil {
+			return err
+		}
+	}
+
+	// Dev server runtime options files must be relative to Backend/Server.
+	if config.DevServer != nil {
+		for _, optionsFile := range config.DevServer.OptionsFiles {
+			if optionsFile == "" || filepath.IsAbs(optionsFile) {
+				return fmt.Errorf("devServer.optionsFiles entry '%s' must be a relative path (from the Backend/Server directory)", optionsFile)
+			}
+		}
+	}
 
 	// Check project .NET version.
 	if config.DotnetRuntimeVersion == nil {
@@ -295,7 +522,7 @@ func ValidateProjectConfig(projectDir string, config *ProjectConfig) error {
 	}
 
 	// Helm charts.
-	if err := validateHelmChartRepositoryURL(config.HelmChartRepository); err != nil {
+	if err := ValidateHelmChartRepositoryURL(config.HelmChartRepository); err != nil {
 		return err
 	}
 	if err := validateHelmChartVersion("serverChartVersion", config.ServerChartVersion); err != nil {
@@ -305,6 +532,28 @@ func ValidateProjectConfig(projectDir string, config *ProjectConfig) error {
 		return err
 	}
 
+	// Image naming template (optional).
+	if config.ImageNaming != "" {
+		if err := ValidateImageNamingTemplate(config.ImageNaming); err != nil {
+			return err
+		}
+	}
+
+	// Image signing (optional).
+	if err := validateImageSigningConfig(config); err != nil {
+		return err
+	}
+
+	// OIDC federation for machine logins (optional).
+	if err := validateOIDCFederationConfig(config); err != nil {
+		return err
+	}
+
+	// Custom integration tests (optional).
+	if err := validateCustomIntegrationTests(config); err != nil {
+		return err
+	}
+
 	// Validate auth providers (if specified).
 	if config.AuthProviders == nil {
 		config.AuthProviders = make(map[string]*auth.AuthProviderConfig)
@@ -412,6 +661,16 @@ func ValidateProjectConfig(projectDir string, config *ProjectConfig) error {
 				return fmt.Errorf("environment '%s' failed to validate 'botclientValuesFile': %w", envName, err)
 			}
 		}
+		if envConfig.ServerPostRenderer != "" {
+			if _, err := os.Stat(filepath.Join(projectDir, envConfig.ServerPostRenderer)); err != nil {
+				return fmt.Errorf("environment '%s' failed to validate 'serverPostRenderer': %w", envName, err)
+			}
+		}
+		if envConfig.BotClientPostRenderer != "" {
+			if _, err := os.Stat(filepath.Join(projectDir, envConfig.BotClientPostRenderer)); err != nil {
+				return fmt.Errorf("environment '%s' failed to validate 'botclientPostRenderer': %w", envName, err)
+			}
+		}
 		// Validate the environment's auth provider if specified
 		if envConfig.AuthProvider != "" {
 			// Check that the specified provider exists in the map
@@ -717,7 +976,7 @@ buildRootDir: .
 sdkRootDir: {{.SdkRootDir}}
 backendDir: {{.BackendDir}}
 sharedCodeDir: {{.SharedCodeDir}}
-unityProjectDir: {{.UnityProjectDir}}
+{{if .UnrealProjectDir}}unrealProjectDir: {{.UnrealProjectDir}}{{else if .UnityProjectDir}}unityProjectDir: {{.UnityProjectDir}}{{else}}# Server-only project: no unityProjectDir or unrealProjectDir.{{end}}
 
 # Specify .NET runtime version to build project for, only '<major>.<minor>'.
 dotnetRuntimeVersion: "{{.DotnetRuntimeVersion}}"
@@ -745,7 +1004,8 @@ environments:
 // Returns the YAML string and the parsed ProjectConfig.
 func RenderProjectConfigYAML(
 	sdkMetadata *MetaplayVersionMetadata,
-	pathToUnityProject string,
+	clientEngine ClientEngine,
+	pathToClientProject string,
 	pathToMetaplaySdk string,
 	sharedCodePath string,
 	gameBackendPath string,
@@ -761,6 +1021,7 @@ func RenderProjectConfigYAML(
 		BackendDir            string
 		SharedCodeDir         string
 		UnityProjectDir       string
+		UnrealProjectDir      string
 		DotnetRuntimeVersion  string
 		ServerChartVersion    string
 		BotClientChartVersion string
@@ -773,7 +1034,6 @@ func RenderProjectConfigYAML(
 		SdkRootDir:            filepath.ToSlash(pathToMetaplaySdk),
 		BackendDir:            filepath.ToSlash(gameBackendPath),
 		SharedCodeDir:         filepath.ToSlash(sharedCodePath),
-		UnityProjectDir:       filepath.ToSlash(pathToUnityProject),
 		DotnetRuntimeVersion:  sdkMetadata.DefaultDotnetRuntimeVersion,
 		ServerChartVersion:    sdkMetadata.DefaultServerChartVersion.String(),
 		BotClientChartVersion: sdkMetadata.DefaultBotClientChartVersion.String(),
@@ -782,6 +1042,17 @@ func RenderProjectConfigYAML(
 		Environments:          environments,
 	}
 
+	switch clientEngine {
+	case ClientEngineUnity:
+		data.UnityProjectDir = filepath.ToSlash(pathToClientProject)
+	case ClientEngineUnreal:
+		data.UnrealProjectDir = filepath.ToSlash(pathToClientProject)
+	case ClientEngineNone:
+		// Server-only project: no client project.
+	default:
+		return "", nil, fmt.Errorf("unsupported client engine '%s'", clientEngine)
+	}
+
 	// Render the template.
 	var result strings.Builder
 	err := projectFileTemplate.Execute(&result, data)
@@ -813,6 +1084,7 @@ func GenerateProjectConfigFile(
 
 	yamlContent, projectConfig, err := RenderProjectConfigYAML(
 		sdkMetadata,
+		ClientEngineUnity,
 		pathToUnityProject,
 		pathToMetaplaySdk,
 		sharedCodePath,
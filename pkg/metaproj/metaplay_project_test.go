@@ -5,10 +5,12 @@
 package metaproj
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-version"
+	"github.com/metaplay/cli/pkg/auth"
 	"github.com/metaplay/cli/pkg/portalapi"
 	"gopkg.in/yaml.v3"
 )
@@ -262,6 +264,7 @@ environments:
 		t.Run(tc.name, func(t *testing.T) {
 			yamlContent, config, err := RenderProjectConfigYAML(
 				sdkMetadata,
+				ClientEngineUnity,
 				"Unity",
 				"MetaplaySDK",
 				"SharedCode",
@@ -630,3 +633,210 @@ integrationTests:
 		})
 	}
 }
+
+func TestParseHelmChartVersionConstraints(t *testing.T) {
+	tests := []struct {
+		specifier string
+		isValid   bool
+		matches   []string
+		rejects   []string
+	}{
+		{"latest-prerelease", true, nil, nil},
+		{"0.8.1", true, []string{"0.8.1"}, []string{"0.8.0", "0.8.2"}},
+		{">=0.8.0 <0.9.0", true, []string{"0.8.0", "0.8.5"}, []string{"0.7.9", "0.9.0"}},
+		{">= 0.8.0, < 0.9.0", true, []string{"0.8.3"}, []string{"0.9.1"}},
+		{"~> 0.8.0", true, []string{"0.8.9"}, []string{"0.9.0"}},
+		{"", false, nil, nil},
+		{">=", false, nil, nil},
+		{"not-a-version", false, nil, nil},
+		{">=0.8.0 <zzz", false, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.specifier, func(t *testing.T) {
+			constraints, err := ParseHelmChartVersionConstraints(test.specifier)
+			if test.isValid && err != nil {
+				t.Fatalf("Expected '%s' to be valid, got error: %v", test.specifier, err)
+			}
+			if !test.isValid {
+				if err == nil {
+					t.Errorf("Expected '%s' to be invalid, but no error returned", test.specifier)
+				}
+				return
+			}
+			for _, v := range test.matches {
+				if !constraints.Check(version.Must(version.NewVersion(v))) {
+					t.Errorf("Expected '%s' to accept version %s", test.specifier, v)
+				}
+			}
+			for _, v := range test.rejects {
+				if constraints.Check(version.Must(version.NewVersion(v))) {
+					t.Errorf("Expected '%s' to reject version %s", test.specifier, v)
+				}
+			}
+		})
+	}
+}
+
+func TestIsExactHelmChartVersion(t *testing.T) {
+	if !IsExactHelmChartVersion("0.8.1") {
+		t.Errorf("Expected '0.8.1' to be an exact version")
+	}
+	if IsExactHelmChartVersion(">=0.8.0 <0.9.0") {
+		t.Errorf("Expected '>=0.8.0 <0.9.0' to not be an exact version")
+	}
+	if IsExactHelmChartVersion("latest-prerelease") {
+		t.Errorf("Expected 'latest-prerelease' to not be an exact version")
+	}
+}
+
+func TestValidateImageSigningConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ProjectConfig
+		isValid bool
+	}{
+		{"no signing", ProjectConfig{}, true},
+		{"signing key only", ProjectConfig{ImageSigning: &ImageSigningConfig{Key: "cosign.key"}}, true},
+		{"required without config", ProjectConfig{RequireSignedImages: true}, false},
+		{"required with signing key only", ProjectConfig{RequireSignedImages: true, ImageSigning: &ImageSigningConfig{Key: "cosign.key"}}, false},
+		{"required with public key", ProjectConfig{RequireSignedImages: true, ImageSigning: &ImageSigningConfig{PublicKey: "cosign.pub"}}, true},
+		{"required with keyless identity", ProjectConfig{RequireSignedImages: true, ImageSigning: &ImageSigningConfig{
+			CertificateIdentityRegexp: "^https://github.com/myorg/",
+			CertificateOidcIssuer:     "https://token.actions.githubusercontent.com",
+		}}, true},
+		{"identity without issuer", ProjectConfig{ImageSigning: &ImageSigningConfig{CertificateIdentityRegexp: "^https://github.com/myorg/"}}, false},
+		{"invalid identity regexp", ProjectConfig{ImageSigning: &ImageSigningConfig{
+			CertificateIdentityRegexp: "(",
+			CertificateOidcIssuer:     "https://token.actions.githubusercontent.com",
+		}}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateImageSigningConfig(&test.config)
+			if test.isValid && err != nil {
+				t.Errorf("Expected config to be valid, got error: %v", err)
+			}
+			if !test.isValid && err == nil {
+				t.Errorf("Expected config to be invalid, but no error returned")
+			}
+		})
+	}
+}
+
+func TestValidateCustomIntegrationTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		tests   []CustomIntegrationTestConfig
+		isValid bool
+	}{
+		{"no tests", nil, true},
+		{"build target", []CustomIntegrationTestConfig{{Name: "api-smoke", Target: "api-tests", Command: []string{"npm", "test"}}}, true},
+		{"image with mounts", []CustomIntegrationTestConfig{{Name: "k6", Image: "grafana/k6:latest", Mounts: []string{"Tests/k6:/scripts"}}}, true},
+		{"invalid name", []CustomIntegrationTestConfig{{Name: "Api_Smoke", Target: "api-tests"}}, false},
+		{"missing name", []CustomIntegrationTestConfig{{Target: "api-tests"}}, false},
+		{"duplicate name", []CustomIntegrationTestConfig{{Name: "smoke", Target: "a"}, {Name: "smoke", Target: "b"}}, false},
+		{"project dockerfile", []CustomIntegrationTestConfig{{Name: "smoke", Target: "smoke-tests", Dockerfile: "Tests/Dockerfile"}}, true},
+		{"dockerfile with image", []CustomIntegrationTestConfig{{Name: "smoke", Image: "b", Dockerfile: "Tests/Dockerfile"}}, false},
+		{"both target and image", []CustomIntegrationTestConfig{{Name: "smoke", Target: "a", Image: "b"}}, false},
+		{"neither target nor image", []CustomIntegrationTestConfig{{Name: "smoke"}}, false},
+		{"relative container path", []CustomIntegrationTestConfig{{Name: "smoke", Target: "a", Mounts: []string{"Tests:scripts"}}}, false},
+		{"missing container path", []CustomIntegrationTestConfig{{Name: "smoke", Target: "a", Mounts: []string{"Tests"}}}, false},
+		{"windows host path", []CustomIntegrationTestConfig{{Name: "smoke", Target: "a", Mounts: []string{`C:\Tests:/scripts`}}}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCustomIntegrationTests(&ProjectConfig{IntegrationTests: &IntegrationTestsConfig{Tests: test.tests}})
+			if test.isValid && err != nil {
+				t.Errorf("Expected config to be valid, got error: %v", err)
+			}
+			if !test.isValid && err == nil {
+				t.Errorf("Expected config to be invalid, but no error returned")
+			}
+		})
+	}
+}
+
+func TestValidateOIDCFederationConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		federation *auth.OIDCFederationConfig
+		isValid    bool
+	}{
+		{"not configured", nil, true},
+		{"github actions", &auth.OIDCFederationConfig{Issuer: "https://token.actions.githubusercontent.com", Audience: "metaplay"}, true},
+		{"gitlab with client id", &auth.OIDCFederationConfig{Issuer: "https://gitlab.com", Audience: "metaplay", ClientID: "abc123"}, true},
+		{"http issuer", &auth.OIDCFederationConfig{Issuer: "http://gitlab.example.com", Audience: "metaplay"}, false},
+		{"missing issuer", &auth.OIDCFederationConfig{Audience: "metaplay"}, false},
+		{"missing audience", &auth.OIDCFederationConfig{Issuer: "https://gitlab.com"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateOIDCFederationConfig(&ProjectConfig{OIDCFederation: test.federation})
+			if test.isValid && err != nil {
+				t.Errorf("Expected config to be valid, got error: %v", err)
+			}
+			if !test.isValid && err == nil {
+				t.Errorf("Expected config to be invalid, but no error returned")
+			}
+		})
+	}
+}
+
+func TestValidateHelmChartRepositoryURL(t *testing.T) {
+	tests := []struct {
+		chartRepo string
+		isValid   bool
+	}{
+		{"", true},
+		{"https://charts.metaplay.dev", true},
+		{"http://charts.example.com/metaplay/", true},
+		{"oci://registry.example.com/charts", true},
+		{"oci://123456789.dkr.ecr.eu-west-1.amazonaws.com/metaplay", true},
+		{"ftp://charts.example.com", false},
+		{"charts.example.com", false},
+		{"oci:///charts", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.chartRepo, func(t *testing.T) {
+			err := ValidateHelmChartRepositoryURL(test.chartRepo)
+			if test.isValid && err != nil {
+				t.Errorf("Expected '%s' to be valid, got error: %v", test.chartRepo, err)
+			}
+			if !test.isValid && err == nil {
+				t.Errorf("Expected '%s' to be invalid, but no error returned", test.chartRepo)
+			}
+		})
+	}
+}
+
+func TestGetPostRendererPaths(t *testing.T) {
+	envConfig := &ProjectEnvironmentConfig{ServerPostRenderer: "render.sh", BotClientPostRenderer: "overlays/botclient"}
+
+	// Paths are absolute even when the project is in the working directory, so that a bare
+	// file name is not searched for in PATH.
+	project := &MetaplayProject{RelativeDir: "."}
+	expected, _ := filepath.Abs("render.sh")
+	if got := project.GetServerPostRenderer(envConfig); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	expected, _ = filepath.Abs(filepath.Join("overlays", "botclient"))
+	if got := project.GetBotClientPostRenderer(envConfig); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	project = &MetaplayProject{RelativeDir: filepath.Join("..", "game")}
+	expected, _ = filepath.Abs(filepath.Join("..", "game", "render.sh"))
+	if got := project.GetServerPostRenderer(envConfig); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	// Not configured.
+	if got := project.GetServerPostRenderer(&ProjectEnvironmentConfig{}); got != "" {
+		t.Errorf("expected no post-renderer, got %q", got)
+	}
+}
@@ -28,6 +28,7 @@ type IntegrationTestsConfig struct {
 	Docker    *IntegrationTestDockerConfig    `yaml:"docker,omitempty"`
 	Server    *IntegrationTestContainerConfig `yaml:"server,omitempty"`
 	BotClient *IntegrationTestContainerConfig `yaml:"botClient,omitempty"`
+	Tests     []CustomIntegrationTestConfig   `yaml:"tests,omitempty"`
 }
 
 // IntegrationTestDockerConfig configures docker build options for integration tests.
@@ -41,27 +42,89 @@ type IntegrationTestContainerConfig struct {
 	Env  map[string]string `yaml:"env,omitempty"`
 }
 
+// CustomIntegrationTestConfig declares a project-specific integration test container that is run
+// against the game server alongside the built-in tests ($.integrationTests.tests[] in metaplay-project.yaml).
+type CustomIntegrationTestConfig struct {
+	Name        string            `yaml:"name"`                  // Name of the test, eg, 'api-smoke'
+	Description string            `yaml:"description,omitempty"` // Human-readable description of the test
+	Target      string            `yaml:"target,omitempty"`      // Build stage in the Dockerfile to build the test image from
+	Dockerfile  string            `yaml:"dockerfile,omitempty"`  // Dockerfile with the 'target' stage, relative to the project (defaults to the SDK's Dockerfile.server)
+	Image       string            `yaml:"image,omitempty"`       // Existing image to run instead of building one from 'target'
+	Command     []string          `yaml:"command,omitempty"`     // Command to run in the container (defaults to the image's)
+	Env         map[string]string `yaml:"env,omitempty"`         // Extra environment variables for the container
+	Mounts      []string          `yaml:"mounts,omitempty"`      // Bind mounts as 'hostPath:containerPath', with the host path relative to the project
+}
+
+// DevServerConfig configures running the game server locally ($.devServer in metaplay-project.yaml).
+type DevServerConfig struct {
+	OptionsFiles []string `yaml:"optionsFiles,omitempty"` // Runtime options files to use, relative to Backend/Server (defaults to Config/Options.base.yaml and Config/Options.dev.yaml)
+}
+
+// ImageSigningConfig configures signing the server images with cosign and verifying the signatures
+// ($.imageSigning in metaplay-project.yaml). Keys can be file paths or KMS URIs, eg, 'awskms:///alias/my-key'.
+type ImageSigningConfig struct {
+	Key                       string `yaml:"key,omitempty"`                       // Private key to sign the images with (keyless signing if empty)
+	PublicKey                 string `yaml:"publicKey,omitempty"`                 // Public key to verify the signatures with
+	CertificateIdentityRegexp string `yaml:"certificateIdentityRegexp,omitempty"` // Identity of the keyless signer to accept, eg, '^https://github.com/myorg/'
+	CertificateOidcIssuer     string `yaml:"certificateOidcIssuer,omitempty"`     // OIDC issuer of the keyless signer, eg, 'https://token.actions.githubusercontent.com'
+}
+
+// ClientEngine is the game engine used for the project's client.
+type ClientEngine string
+
+const (
+	ClientEngineUnity  ClientEngine = "unity"
+	ClientEngineUnreal ClientEngine = "unreal"
+	ClientEngineNone   ClientEngine = "none" // Server-only project, eg, for headless services or custom clients
+)
+
 // Metaplay project config file, named `metaplay-project.yaml`.
 // Note: When adding new fields, remember to update ValidateProjectConfig().
 type ProjectConfig struct {
-	ProjectHumanID  string `yaml:"projectID"`       // The project's human ID (as in the portal)
-	BuildRootDir    string `yaml:"buildRootDir"`    // Relative path to the docker build root directory
-	SdkRootDir      string `yaml:"sdkRootDir"`      // Relative path to the MetaplaySDK directory
-	BackendDir      string `yaml:"backendDir"`      // Relative path to the project-specific backend directory
-	SharedCodeDir   string `yaml:"sharedCodeDir"`   // Relative path to the shared code directory
-	UnityProjectDir string `yaml:"unityProjectDir"` // Relative path to the Unity (client) project
+	ProjectHumanID   string `yaml:"projectID"`                  // The project's human ID (as in the portal)
+	BuildRootDir     string `yaml:"buildRootDir"`               // Relative path to the docker build root directory
+	SdkRootDir       string `yaml:"sdkRootDir"`                 // Relative path to the MetaplaySDK directory
+	BackendDir       string `yaml:"backendDir"`                 // Relative path to the project-specific backend directory
+	SharedCodeDir    string `yaml:"sharedCodeDir"`              // Relative path to the shared code directory
+	UnityProjectDir  string `yaml:"unityProjectDir,omitempty"`  // Relative path to the Unity (client) project (neither this nor unrealProjectDir for server-only projects)
+	UnrealProjectDir string `yaml:"unrealProjectDir,omitempty"` // Relative path to the Unreal Engine (client) project, ie, the directory with the .uproject file (instead of unityProjectDir)
+
+	GameConfigBuilderDir string `yaml:"gameConfigBuilderDir,omitempty"` // Relative path to the .NET project that builds the game config and localization archives (defaults to the Backend/Server project)
 
 	DotnetRuntimeVersion *version.Version `yaml:"dotnetRuntimeVersion"` // .NET runtime version that the project is using (major.minor); depends on the SDK version, eg, '10.0' (older SDKs use '8.0' or '9.0')
 
-	HelmChartRepository   string `yaml:"helmChartRepository"`   // Helm chart repository to use (defaults to 'https://charts.metaplay.dev')
-	ServerChartVersion    string `yaml:"serverChartVersion"`    // Version of the game server Helm chart to use (or 'latest-prerelease' for absolute latest)
-	BotClientChartVersion string `yaml:"botClientChartVersion"` // Version of the bot client Helm chart to use (or 'latest-prerelease' for absolute latest)
+	HelmChartRepository   string `yaml:"helmChartRepository"`      // Helm chart repository or OCI registry to use, eg, 'oci://registry.example.com/charts' (defaults to 'https://charts.metaplay.dev')
+	ServerChartVersion    string `yaml:"serverChartVersion"`       // Version or version range (eg, '>=0.8.0 <0.9.0') of the game server Helm chart to use (or 'latest-prerelease' for absolute latest)
+	BotClientChartVersion string `yaml:"botClientChartVersion"`    // Version or version range (eg, '>=0.4.0 <0.5.0') of the bot client Helm chart to use (or 'latest-prerelease' for absolute latest)
+	LocalChartsDir        string `yaml:"localChartsDir,omitempty"` // Relative path to a directory with vendored copies of the Helm charts ('<chart>-<version>.tgz'), preferred over the chart repository
+
+	ImageNaming string `yaml:"imageNaming,omitempty"` // Template for naming the built docker images, eg, '{registry}/{project}/{component}:{date}-{commit}'
+
+	RequireSignedImages bool                `yaml:"requireSignedImages,omitempty"` // Refuse deploying server images without a verified cosign signature
+	ImageSigning        *ImageSigningConfig `yaml:"imageSigning,omitempty"`        // Keys and identities for signing and verifying the images
 
 	AuthProviders map[string]*auth.AuthProviderConfig `yaml:"authProviders,omitempty"`
 
+	OIDCFederation *auth.OIDCFederationConfig `yaml:"oidcFederation,omitempty"` // CI provider's ID tokens accepted for 'metaplay auth machine-login --oidc'
+
 	Features ProjectFeaturesConfig `yaml:"features"`
 
 	IntegrationTests *IntegrationTestsConfig `yaml:"integrationTests,omitempty"`
 
+	DevServer *DevServerConfig `yaml:"devServer,omitempty"`
+
 	Environments []ProjectEnvironmentConfig `yaml:"environments"`
 }
+
+// Return the game engine of the client, based on which client project directory is specified.
+// Projects without either directory are server-only.
+func (config *ProjectConfig) GetClientEngine() ClientEngine {
+	switch {
+	case config.UnrealProjectDir != "":
+		return ClientEngineUnreal
+	case config.UnityProjectDir != "":
+		return ClientEngineUnity
+	default:
+		return ClientEngineNone
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// ProjectConfigMigration upgrades metaplay-project.yaml files written for an older layout of the
+// project config. The file does not record which layout it uses, so each migration detects from
+// the contents whether it applies, and applying it again is a no-op.
+type ProjectConfigMigration struct {
+	Version     int    // Running number of the migration; the migrations are applied in this order
+	Description string // Human-readable description of the migration, eg, "Rename 'botsValuesFile' to 'botclientValuesFile'"
+
+	// Migrate the config in-place and return the descriptions of the changes made, if any.
+	// The root is the top-level mapping of the metaplay-project.yaml.
+	migrate func(projectDir string, root *ast.MappingNode) ([]string, error)
+}
+
+// ProjectConfigMigrationResult is a migration that changed the config, and the changes it made.
+type ProjectConfigMigrationResult struct {
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	Changes     []string `json:"changes"`
+}
+
+// Migrations of the metaplay-project.yaml layout, in the order they are applied. New migrations
+// must be added to the end with the next version number.
+var projectConfigMigrations = []ProjectConfigMigration{
+	{
+		Version:     1,
+		Description: "Rename the environments' 'botsValuesFile' to 'botclientValuesFile'",
+		migrate: func(projectDir string, root *ast.MappingNode) ([]string, error) {
+			return renameEnvironmentFields(root, []string{"botsValuesFile", "botClientValuesFile"}, "botclientValuesFile")
+		},
+	},
+	{
+		Version:     2,
+		Description: "Add the 'unityProjectDir' of the Unity client project",
+		migrate:     migrateAddUnityProjectDir,
+	},
+}
+
+// MigrateProjectConfig applies the migrations to the contents of a metaplay-project.yaml and
+// returns the migrated contents along with the migrations that changed something. The comments
+// and formatting of the unchanged parts of the file are kept. If no migrations apply, the
+// original content is returned as is.
+func MigrateProjectConfig(projectDir string, content []byte) ([]byte, []ProjectConfigMigrationResult, error) {
+	file, err := parser.ParseBytes(content, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", ConfigFileName, err)
+	}
+	if len(file.Docs) != 1 {
+		return nil, nil, fmt.Errorf("%s must contain exactly one YAML document", ConfigFileName)
+	}
+	root, ok := file.Docs[0].Body.(*ast.MappingNode)
+	if !ok {
+		return nil, nil, fmt.Errorf("the top level of %s must be a mapping", ConfigFileName)
+	}
+
+	results := []ProjectConfigMigrationResult{}
+	for _, migration := range projectConfigMigrations {
+		changes, err := migration.migrate(projectDir, root)
+		if err != nil {
+			return nil, nil, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		if len(changes) > 0 {
+			results = append(results, ProjectConfigMigrationResult{
+				Version:     migration.Version,
+				Description: migration.Description,
+				Changes:     changes,
+			})
+		}
+	}
+
+	if len(results) == 0 {
+		return content, results, nil
+	}
+	return []byte(file.String()), results, nil
+}
+
+// renameEnvironmentFields renames the legacy fields of each environment to the new name. It is an
+// error if an environment has both a legacy and the new field, as it is unclear which to keep.
+func renameEnvironmentFields(root *ast.MappingNode, legacyNames []string, newName string) ([]string, error) {
+	envsNode := findMappingValue(root, "environments")
+	if envsNode == nil {
+		return nil, nil
+	}
+	envsSeq, ok := envsNode.Value.(*ast.SequenceNode)
+	if !ok {
+		// Empty or invalid environments, nothing to rename.
+		return nil, nil
+	}
+
+	var changes []string
+	for ndx, envNode := range envsSeq.Values {
+		envMapping, ok := envNode.(*ast.MappingNode)
+		if !ok {
+			continue
+		}
+		for _, legacyName := range legacyNames {
+			legacyField := findMappingValue(envMapping, legacyName)
+			if legacyField == nil {
+				continue
+			}
+			if findMappingValue(envMapping, newName) != nil {
+				return nil, fmt.Errorf("environments[%d] has both '%s' and '%s', remove one of them", ndx, legacyName, newName)
+			}
+			renameMappingKey(legacyField, newName)
+			changes = append(changes, fmt.Sprintf("environments[%d]: renamed '%s' to '%s'", ndx, legacyName, newName))
+		}
+	}
+	return changes, nil
+}
+
+// migrateAddUnityProjectDir adds the 'unityProjectDir' to configs from before the client project
+// directory was specified in metaplay-project.yaml. Configs that already specify a client project
+// are left as is, as are server-only projects without a Unity project in the project directory.
+func migrateAddUnityProjectDir(projectDir string, root *ast.MappingNode) ([]string, error) {
+	if findMappingValue(root, "unityProjectDir") != nil || findMappingValue(root, "unrealProjectDir") != nil {
+		return nil, nil
+	}
+
+	unityProjectDirs, err := findUnityProjectDirs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	switch len(unityProjectDirs) {
+	case 0:
+		return nil, nil
+	case 1:
+		// Add the field after the other project directories, like in newly created configs.
+		unityProjectDir := unityProjectDirs[0]
+		if err := insertMappingValue(root, "sharedCodeDir", "unityProjectDir", unityProjectDir); err != nil {
+			return nil, err
+		}
+		return []string{fmt.Sprintf("added 'unityProjectDir: %s'", unityProjectDir)}, nil
+	default:
+		return nil, fmt.Errorf("found multiple Unity projects (%s), add 'unityProjectDir' to %s manually", strings.Join(unityProjectDirs, ", "), ConfigFileName)
+	}
+}
+
+// findUnityProjectDirs returns the Unity projects in the project directory or its immediate
+// subdirectories, as slash-separated paths relative to the project directory.
+func findUnityProjectDirs(projectDir string) ([]string, error) {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory %s: %w", projectDir, err)
+	}
+
+	candidates := []string{"."}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			candidates = append(candidates, entry.Name())
+		}
+	}
+
+	var unityProjectDirs []string
+	for _, candidate := range candidates {
+		if isUnityProjectDir(filepath.Join(projectDir, candidate)) {
+			unityProjectDirs = append(unityProjectDirs, filepath.ToSlash(candidate))
+		}
+	}
+	return unityProjectDirs, nil
+}
+
+// isUnityProjectDir checks whether the directory contains a Unity project.
+func isUnityProjectDir(dirPath string) bool {
+	for _, requiredPath := range []string{"Assets", "ProjectSettings", "Packages/manifest.json"} {
+		if _, err := os.Stat(filepath.Join(dirPath, requiredPath)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// findMappingValue returns the key-value pair of the mapping with the given key, or nil if the
+// key does not exist.
+func findMappingValue(mapping *ast.MappingNode, key string) *ast.MappingValueNode {
+	for _, value := range mapping.Values {
+		if value.Key.GetToken().Value == key {
+			return value
+		}
+	}
+	return nil
+}
+
+// renameMappingKey renames the key of the key-value pair, keeping its value and comments.
+func renameMappingKey(value *ast.MappingValueNode, newKey string) {
+	value.Key.GetToken().Value = newKey
+	if stringNode, ok := value.Key.(*ast.StringNode); ok {
+		stringNode.Value = newKey
+	}
+}
+
+// insertMappingValue inserts a new key-value pair into the top-level mapping after the given key,
+// or at the end if the key does not exist.
+func insertMappingValue(mapping *ast.MappingNode, afterKey, key, value string) error {
+	// Parse the new pair from YAML, so that the value gets quoted if needed.
+	parsed, err := parser.ParseBytes(fmt.Appendf(nil, "%s: %q\n", key, value), parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to create field '%s': %w", key, err)
+	}
+	newMapping, ok := parsed.Docs[0].Body.(*ast.MappingNode)
+	if !ok || len(newMapping.Values) != 1 {
+		return fmt.Errorf("failed to create field '%s'", key)
+	}
+
+	ndx := slices.IndexFunc(mapping.Values, func(v *ast.MappingValueNode) bool { return v.Key.GetToken().Value == afterKey })
+	if ndx == -1 {
+		ndx = len(mapping.Values) - 1
+	}
+	mapping.Values = slices.Insert(mapping.Values, ndx+1, newMapping.Values[0])
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const legacyProjectConfig = `# Configure project.
+projectID: lovely-wombats
+buildRootDir: .
+sdkRootDir: MetaplaySDK
+backendDir: Backend
+sharedCodeDir: Unity/Assets/SharedCode
+
+# Project environments.
+environments:
+  - name: Develop
+    humanId: lovely-wombats-develop
+    botsValuesFile: Backend/Deployments/develop-bots.yaml # Custom bot settings
+  - name: Production
+    humanId: lovely-wombats-prod
+`
+
+// createTestUnityProject creates the files of a minimal Unity project in the directory.
+func createTestUnityProject(t *testing.T, dir string) {
+	t.Helper()
+	for _, subDir := range []string{"Assets", "ProjectSettings", "Packages"} {
+		if err := os.MkdirAll(filepath.Join(dir, subDir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Packages", "manifest.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateProjectConfig(t *testing.T) {
+	projectDir := t.TempDir()
+	createTestUnityProject(t, filepath.Join(projectDir, "Unity"))
+
+	migrated, results, err := MigrateProjectConfig(projectDir, []byte(legacyProjectConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Version != 1 || results[1].Version != 2 {
+		t.Fatalf("expected migrations 1 and 2 to apply, got %+v", results)
+	}
+
+	output := string(migrated)
+	for _, want := range []string{
+		"# Configure project.",
+		"sharedCodeDir: Unity/Assets/SharedCode\nunityProjectDir: \"Unity\"\n",
+		"botclientValuesFile: Backend/Deployments/develop-bots.yaml # Custom bot settings",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected migrated config to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "botsValuesFile") {
+		t.Errorf("expected 'botsValuesFile' to be renamed, got:\n%s", output)
+	}
+
+	// Migrating again is a no-op.
+	again, results, err := MigrateProjectConfig(projectDir, migrated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 || string(again) != output {
+		t.Errorf("expected no further migrations, got %+v", results)
+	}
+}
+
+func TestMigrateProjectConfigServerOnly(t *testing.T) {
+	// Without a Unity project, the config is left as is.
+	content := strings.ReplaceAll(legacyProjectConfig, "botsValuesFile", "botclientValuesFile")
+	migrated, results, err := MigrateProjectConfig(t.TempDir(), []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 || string(migrated) != content {
+		t.Errorf("expected no migrations, got %+v", results)
+	}
+}
+
+func TestMigrateProjectConfigErrors(t *testing.T) {
+	// Both the legacy and the new field.
+	projectDir := t.TempDir()
+	content := strings.ReplaceAll(legacyProjectConfig, "    humanId: lovely-wombats-develop\n", "    humanId: lovely-wombats-develop\n    botclientValuesFile: bots.yaml\n")
+	if _, _, err := MigrateProjectConfig(projectDir, []byte(content)); err == nil || !strings.Contains(err.Error(), "has both") {
+		t.Errorf("expected error for both legacy and new field, got %v", err)
+	}
+
+	// Multiple Unity projects.
+	createTestUnityProject(t, filepath.Join(projectDir, "GameA"))
+	createTestUnityProject(t, filepath.Join(projectDir, "GameB"))
+	if _, _, err := MigrateProjectConfig(projectDir, []byte(legacyProjectConfig)); err == nil || !strings.Contains(err.Error(), "multiple Unity projects") {
+		t.Errorf("expected error for multiple Unity projects, got %v", err)
+	}
+
+	// Not a mapping.
+	if _, _, err := MigrateProjectConfig(projectDir, []byte("- foo\n")); err == nil {
+		t.Errorf("expected error for non-mapping config")
+	}
+}
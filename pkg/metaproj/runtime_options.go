@@ -0,0 +1,208 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path of the runtime options schema, relative to the SDK root directory.
+const RuntimeOptionsSchemaSdkPath = "Backend/Server/RuntimeOptionsSchema.json"
+
+// Path of the runtime options schema within the game server docker image.
+const RuntimeOptionsSchemaImagePath = "/gameserver/RuntimeOptionsSchema.json"
+
+// RuntimeOptionsSchema describes the valid keys and value types of the game server runtime
+// options. It is a subset of JSON Schema: the root is an object whose properties are the
+// runtime option sections (eg, 'Database').
+type RuntimeOptionsSchema struct {
+	Type                 string                           `json:"type,omitempty"`                 // One of: object, array, string, integer, number, boolean; empty accepts any value
+	Properties           map[string]*RuntimeOptionsSchema `json:"properties,omitempty"`           // Members of an object
+	AdditionalProperties *RuntimeOptionsSchema            `json:"additionalProperties,omitempty"` // Values of a dictionary-like object with arbitrary keys
+	Items                *RuntimeOptionsSchema            `json:"items,omitempty"`                // Elements of an array
+	Enum                 []string                         `json:"enum,omitempty"`                 // Allowed values of a string (case-insensitive)
+}
+
+// RuntimeOptionsIssue is a problem found in a runtime options file.
+type RuntimeOptionsIssue struct {
+	Line       int    // Line number in the file (1-based)
+	Path       string // Path to the option, eg, 'Database:Backend'
+	Message    string // Description of the problem
+	Suggestion string // Suggested fix, eg, the likely intended option name (may be empty)
+}
+
+// ParseRuntimeOptionsSchema parses the JSON runtime options schema.
+func ParseRuntimeOptionsSchema(content []byte) (*RuntimeOptionsSchema, error) {
+	var schema RuntimeOptionsSchema
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse runtime options schema: %w", err)
+	}
+	if schema.Type != "object" {
+		return nil, fmt.Errorf("invalid runtime options schema: root must be of type 'object', got '%s'", schema.Type)
+	}
+	return &schema, nil
+}
+
+// ValidateRuntimeOptions validates the content of a runtime options file (eg, Options.base.yaml)
+// against the schema. The option names are matched case-insensitively, like the game server does.
+func ValidateRuntimeOptions(schema *RuntimeOptionsSchema, content []byte) ([]RuntimeOptionsIssue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	// Empty file is valid.
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	var issues []RuntimeOptionsIssue
+	validateRuntimeOptionsNode(schema, doc.Content[0], "", &issues)
+	return issues, nil
+}
+
+func validateRuntimeOptionsNode(schema *RuntimeOptionsSchema, node *yaml.Node, path string, issues *[]RuntimeOptionsIssue) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// Untyped options and nulls accept anything.
+	if schema == nil || schema.Type == "" || (node.Kind == yaml.ScalarNode && node.Tag == "!!null") {
+		return
+	}
+
+	addIssue := func(message string) {
+		*issues = append(*issues, RuntimeOptionsIssue{Line: node.Line, Path: path, Message: message})
+	}
+
+	switch schema.Type {
+	case "object":
+		if node.Kind != yaml.MappingNode {
+			addIssue(fmt.Sprintf("expecting an object, got %s", describeYamlNode(node)))
+			return
+		}
+		for ndx := 0; ndx+1 < len(node.Content); ndx += 2 {
+			keyNode, valueNode := node.Content[ndx], node.Content[ndx+1]
+			childPath := keyNode.Value
+			if path != "" {
+				childPath = path + ":" + keyNode.Value
+			}
+
+			if propSchema, found := findRuntimeOptionsProperty(schema, keyNode.Value); found {
+				validateRuntimeOptionsNode(propSchema, valueNode, childPath, issues)
+			} else if schema.AdditionalProperties != nil {
+				validateRuntimeOptionsNode(schema.AdditionalProperties, valueNode, childPath, issues)
+			} else {
+				issue := RuntimeOptionsIssue{Line: keyNode.Line, Path: childPath, Message: fmt.Sprintf("unknown option '%s'", keyNode.Value)}
+				if closest := findClosestName(keyNode.Value, slices.Sorted(maps.Keys(schema.Properties))); closest != "" {
+					issue.Suggestion = fmt.Sprintf("did you mean '%s'?", closest)
+				}
+				*issues = append(*issues, issue)
+			}
+		}
+
+	case "array":
+		if node.Kind != yaml.SequenceNode {
+			addIssue(fmt.Sprintf("expecting an array, got %s", describeYamlNode(node)))
+			return
+		}
+		for ndx, itemNode := range node.Content {
+			validateRuntimeOptionsNode(schema.Items, itemNode, fmt.Sprintf("%s:%d", path, ndx), issues)
+		}
+
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			addIssue(fmt.Sprintf("expecting a string, got %s", describeYamlNode(node)))
+			return
+		}
+		if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(v string) bool { return strings.EqualFold(v, node.Value) }) {
+			addIssue(fmt.Sprintf("invalid value '%s', expecting one of: %s", node.Value, strings.Join(schema.Enum, ", ")))
+		}
+
+	case "integer":
+		if _, err := strconv.ParseInt(node.Value, 10, 64); node.Kind != yaml.ScalarNode || err != nil {
+			addIssue(fmt.Sprintf("expecting an integer, got %s", describeYamlNode(node)))
+		}
+
+	case "number":
+		if _, err := strconv.ParseFloat(node.Value, 64); node.Kind != yaml.ScalarNode || err != nil {
+			addIssue(fmt.Sprintf("expecting a number, got %s", describeYamlNode(node)))
+		}
+
+	case "boolean":
+		if node.Kind != yaml.ScalarNode || (!strings.EqualFold(node.Value, "true") && !strings.EqualFold(node.Value, "false")) {
+			addIssue(fmt.Sprintf("expecting a boolean, got %s", describeYamlNode(node)))
+		}
+	}
+}
+
+// findRuntimeOptionsProperty finds the object's property with a case-insensitive name match.
+func findRuntimeOptionsProperty(schema *RuntimeOptionsSchema, name string) (*RuntimeOptionsSchema, bool) {
+	if propSchema, found := schema.Properties[name]; found {
+		return propSchema, true
+	}
+	for propName, propSchema := range schema.Properties {
+		if strings.EqualFold(propName, name) {
+			return propSchema, true
+		}
+	}
+	return nil, false
+}
+
+// describeYamlNode returns a short description of the node for error messages.
+func describeYamlNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "an object"
+	case yaml.SequenceNode:
+		return "an array"
+	default:
+		return fmt.Sprintf("'%s'", node.Value)
+	}
+}
+
+// findClosestName returns the candidate closest to the name (case-insensitively), or an empty
+// string if none of the candidates is close enough to be a likely typo.
+func findClosestName(name string, candidates []string) string {
+	best := ""
+	bestDistance := max(2, len(name)/3) + 1
+	for _, candidate := range candidates {
+		distance := levenshteinDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+	return best
+}
+
+// levenshteinDistance returns the edit distance between the two strings.
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
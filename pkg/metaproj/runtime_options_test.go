@@ -0,0 +1,108 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"testing"
+)
+
+const testRuntimeOptionsSchema = `{
+  "type": "object",
+  "properties": {
+    "Database": {
+      "type": "object",
+      "properties": {
+        "Backend": { "type": "string", "enum": ["Sqlite", "MySql"] },
+        "NumActiveShards": { "type": "integer" },
+        "Shards": { "type": "array", "items": { "type": "object", "properties": { "Host": { "type": "string" } } } }
+      }
+    },
+    "System": {
+      "type": "object",
+      "properties": {
+        "EnableDevelopmentFeatures": { "type": "boolean" },
+        "ClientPorts": { "type": "array", "items": { "type": "integer" } }
+      }
+    },
+    "Clustering": {
+      "type": "object",
+      "properties": {
+        "NodeLabels": { "type": "object", "additionalProperties": { "type": "string" } },
+        "Custom": {}
+      }
+    }
+  }
+}`
+
+func TestValidateRuntimeOptions(t *testing.T) {
+	schema, err := ParseRuntimeOptionsSchema([]byte(testRuntimeOptionsSchema))
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		content    string
+		wantPaths  []string
+		suggestion string
+	}{
+		{"empty", "", nil, ""},
+		{"valid", "Database:\n  backend: sqlite\n  NumActiveShards: '4'\nSystem:\n  EnableDevelopmentFeatures: True\n  ClientPorts: [9339, 9340]\nClustering:\n  NodeLabels: { a: b }\n  Custom: { Anything: [1, 2] }\n", nil, ""},
+		{"null value", "Database:\n  Backend:\n", nil, ""},
+		{"unknown section", "Databse:\n  Backend: Sqlite\n", []string{"Databse"}, "did you mean 'Database'?"},
+		{"unknown option", "Database:\n  NumActiveShard: 4\n", []string{"Database:NumActiveShard"}, "did you mean 'NumActiveShards'?"},
+		{"unknown option without suggestion", "System:\n  Something: 1\n", []string{"System:Something"}, ""},
+		{"invalid enum", "Database:\n  Backend: Postgres\n", []string{"Database:Backend"}, ""},
+		{"invalid integer", "Database:\n  NumActiveShards: many\n", []string{"Database:NumActiveShards"}, ""},
+		{"invalid boolean", "System:\n  EnableDevelopmentFeatures: yes\n", []string{"System:EnableDevelopmentFeatures"}, ""},
+		{"invalid array item", "System:\n  ClientPorts: [9339, abc]\n", []string{"System:ClientPorts:1"}, ""},
+		{"nested unknown", "Database:\n  Shards:\n    - Hots: localhost\n", []string{"Database:Shards:0:Hots"}, "did you mean 'Host'?"},
+		{"object expected", "Database: Sqlite\n", []string{"Database"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			issues, err := ValidateRuntimeOptions(schema, []byte(tc.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(issues) != len(tc.wantPaths) {
+				t.Fatalf("got %d issues, want %d: %+v", len(issues), len(tc.wantPaths), issues)
+			}
+			for ndx, issue := range issues {
+				if issue.Path != tc.wantPaths[ndx] {
+					t.Errorf("issue %d path = %q, want %q", ndx, issue.Path, tc.wantPaths[ndx])
+				}
+				if issue.Suggestion != tc.suggestion {
+					t.Errorf("issue %d suggestion = %q, want %q", ndx, issue.Suggestion, tc.suggestion)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRuntimeOptionsLineNumbers(t *testing.T) {
+	schema, err := ParseRuntimeOptionsSchema([]byte(testRuntimeOptionsSchema))
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	issues, err := ValidateRuntimeOptions(schema, []byte("# Comment\nDatabase:\n  Backend: Sqlite\n  Bakend: Sqlite\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Line != 4 {
+		t.Errorf("expected one issue on line 4, got %+v", issues)
+	}
+}
+
+func TestParseRuntimeOptionsSchemaInvalid(t *testing.T) {
+	if _, err := ParseRuntimeOptionsSchema([]byte("not json")); err == nil {
+		t.Error("expected error for invalid json")
+	}
+	if _, err := ParseRuntimeOptionsSchema([]byte(`{"type": "array"}`)); err == nil {
+		t.Error("expected error for non-object root")
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Name of the Metaplay client plugin for Unreal Engine.
+const UnrealPluginName = "Metaplay"
+
+// Directory within the MetaplaySDK that contains the Unreal Engine client plugin(s). The
+// directory is added to the .uproject's 'AdditionalPluginDirectories' so that the plugin is
+// used directly from the SDK, similar to how Unity references the SDK's Client package.
+const UnrealPluginsSdkDir = "ClientUnreal"
+
+// FindUnrealProjectFile returns the name of the .uproject file in the given directory. Exactly
+// one .uproject file must exist in the directory.
+func FindUnrealProjectFile(dirPath string) (string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read directory %s: %w", dirPath, err)
+	}
+
+	var uprojectFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".uproject") {
+			uprojectFiles = append(uprojectFiles, entry.Name())
+		}
+	}
+
+	switch len(uprojectFiles) {
+	case 0:
+		return "", fmt.Errorf("no .uproject file found in %s", dirPath)
+	case 1:
+		return uprojectFiles[0], nil
+	default:
+		return "", fmt.Errorf("multiple .uproject files found in %s: %s", dirPath, strings.Join(uprojectFiles, ", "))
+	}
+}
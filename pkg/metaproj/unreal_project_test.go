@@ -0,0 +1,127 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package metaproj
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metaplay/cli/pkg/portalapi"
+)
+
+func TestFindUnrealProjectFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := FindUnrealProjectFile(dir); err == nil {
+		t.Errorf("expected error for directory without .uproject")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "MyGame.uproject"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	name, err := FindUnrealProjectFile(dir)
+	if err != nil || name != "MyGame.uproject" {
+		t.Errorf("expected MyGame.uproject, got '%s' (err: %v)", name, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Other.uproject"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindUnrealProjectFile(dir); err == nil {
+		t.Errorf("expected error for directory with multiple .uproject files")
+	}
+}
+
+func TestValidateClientProjectDir(t *testing.T) {
+	projectDir := t.TempDir()
+	for _, dir := range []string{"Unity", "Unreal", "Empty"} {
+		if err := os.Mkdir(filepath.Join(projectDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "Unreal", "MyGame.uproject"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		unityDir  string
+		unrealDir string
+		wantErr   string
+	}{
+		{name: "unity", unityDir: "Unity"},
+		{name: "unreal", unrealDir: "Unreal"},
+		{name: "server-only"},
+		{name: "both", unityDir: "Unity", unrealDir: "Unreal", wantErr: "cannot both be specified"},
+		{name: "unreal without uproject", unrealDir: "Empty", wantErr: "no .uproject file found"},
+		{name: "unreal missing", unrealDir: "Missing", wantErr: "does not point to a valid directory"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &ProjectConfig{UnityProjectDir: tc.unityDir, UnrealProjectDir: tc.unrealDir}
+			err := validateClientProjectDir(projectDir, config)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing '%s', got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRenderProjectConfigYAMLServerOnly(t *testing.T) {
+	yamlContent, config, err := RenderProjectConfigYAML(
+		createTestSdkMetadata(),
+		ClientEngineNone,
+		"",
+		"MetaplaySDK",
+		"SharedCode",
+		"Backend",
+		"",
+		createTestProjectInfo("test-project"),
+		[]portalapi.EnvironmentInfo{},
+	)
+	if err != nil {
+		t.Fatalf("RenderProjectConfigYAML failed: %v", err)
+	}
+
+	if strings.Contains(yamlContent, "unityProjectDir:") || strings.Contains(yamlContent, "unrealProjectDir:") {
+		t.Errorf("expected no client project dir in the output:\n%s", yamlContent)
+	}
+	if config.GetClientEngine() != ClientEngineNone {
+		t.Errorf("expected client engine '%s', got '%s'", ClientEngineNone, config.GetClientEngine())
+	}
+}
+
+func TestRenderProjectConfigYAMLUnreal(t *testing.T) {
+	yamlContent, config, err := RenderProjectConfigYAML(
+		createTestSdkMetadata(),
+		ClientEngineUnreal,
+		"Client",
+		"MetaplaySDK",
+		"SharedCode",
+		"Backend",
+		"",
+		createTestProjectInfo("test-project"),
+		[]portalapi.EnvironmentInfo{},
+	)
+	if err != nil {
+		t.Fatalf("RenderProjectConfigYAML failed: %v", err)
+	}
+
+	if !strings.Contains(yamlContent, "\nunrealProjectDir: Client\n") || strings.Contains(yamlContent, "unityProjectDir") {
+		t.Errorf("expected only unrealProjectDir in the output:\n%s", yamlContent)
+	}
+	if config.UnrealProjectDir != "Client" || config.UnityProjectDir != "" {
+		t.Errorf("unexpected client project dirs: unity='%s', unreal='%s'", config.UnityProjectDir, config.UnrealProjectDir)
+	}
+	if config.GetClientEngine() != ClientEngineUnreal {
+		t.Errorf("expected client engine '%s', got '%s'", ClientEngineUnreal, config.GetClientEngine())
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright Metaplay. Licensed under the Apache-2.0 license.
+ */
+
+package portalapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/metaplay/cli/pkg/common"
+	"github.com/metaplay/cli/pkg/httputil"
+	"github.com/rs/zerolog/log"
+)
+
+// Timeout for checking whether a portal base URL is reachable.
+const portalProbeTimeout = 5 * time.Second
+
+var (
+	resolvedBaseURL    string    // Portal base URL resolved by ResolveBaseURL().
+	resolveBaseURLOnce sync.Once // Guard for resolving the base URL only once.
+)
+
+// GetPortalBaseURLs returns the primary portal base URL followed by the configured fallbacks.
+func GetPortalBaseURLs() []string {
+	return append([]string{common.PortalBaseURL}, common.PortalFallbackBaseURLs...)
+}
+
+// ResolveBaseURL returns the portal base URL to use. When fallback base URLs are configured
+// (METAPLAYCLI_PORTAL_FALLBACK_BASEURLS), the first reachable one of the primary and the
+// fallbacks is used. The result is resolved once per process.
+func ResolveBaseURL() string {
+	resolveBaseURLOnce.Do(func() {
+		resolvedBaseURL = selectReachableBaseURL(GetPortalBaseURLs(), probePortalBaseURL)
+		if resolvedBaseURL != common.PortalBaseURL {
+			log.Warn().Msgf("Portal at %s is unreachable, using fallback %s", common.PortalBaseURL, resolvedBaseURL)
+		}
+	})
+	return resolvedBaseURL
+}
+
+// selectReachableBaseURL returns the first reachable base URL. Without any fallbacks, the
+// primary is returned without checking it. If none are reachable, the primary is returned
+// so that the errors refer to it.
+func selectReachableBaseURL(baseURLs []string, isReachable func(baseURL string) bool) string {
+	if len(baseURLs) == 1 {
+		return baseURLs[0]
+	}
+
+	for _, baseURL := range baseURLs {
+		if isReachable(baseURL) {
+			return baseURL
+		}
+		log.Debug().Msgf("Portal base URL %s is unreachable", baseURL)
+	}
+
+	return baseURLs[0]
+}
+
+// probePortalBaseURL checks whether the portal responds at the base URL (without server errors).
+func probePortalBaseURL(baseURL string) bool {
+	statusCode, _, err := httputil.ProbeURL(baseURL, portalProbeTimeout)
+	return err == nil && statusCode < http.StatusInternalServerError
+}
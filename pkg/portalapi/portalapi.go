@@ -5,8 +5,10 @@
 package portalapi
 
 import (
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -15,7 +17,6 @@ import (
 	goversion "github.com/hashicorp/go-version"
 	clierrors "github.com/metaplay/cli/internal/errors"
 	"github.com/metaplay/cli/pkg/auth"
-	"github.com/metaplay/cli/pkg/common"
 	"github.com/metaplay/cli/pkg/metahttp"
 	"github.com/rs/zerolog/log"
 )
@@ -51,10 +52,13 @@ func CanonicalizeSdkVersions(versions []SdkVersionInfo) {
 }
 
 // NewClient creates a new Portal API client with the given auth token set.
+// The portal base URL is resolved with ResolveBaseURL(), so fallback URLs are used if the
+// primary portal is unreachable.
 func NewClient(tokenSet *auth.TokenSet) *Client {
+	baseURL := ResolveBaseURL()
 	return &Client{
-		httpClient: metahttp.NewJSONClient(tokenSet, common.PortalBaseURL),
-		baseURL:    common.PortalBaseURL,
+		httpClient: metahttp.NewJSONClient(tokenSet, baseURL),
+		baseURL:    baseURL,
 		tokenSet:   tokenSet,
 	}
 }
@@ -263,6 +267,43 @@ func (c *Client) FetchEnvironmentInfoByHumanID(humanID, stackDomain string) (*En
 	return &envInfos[0], nil
 }
 
+// FetchOrganizationPolicy fetches the CLI policy of the organization. Organizations without a
+// policy (or portals that don't support policies) return an empty policy.
+func (c *Client) FetchOrganizationPolicy(organizationUUID string) (*OrganizationPolicy, error) {
+	url := fmt.Sprintf("/api/v1/organizations/%s/cli-policy", organizationUUID)
+	log.Debug().Msgf("Fetch organization policy from %s%s", c.httpClient.BaseURL, url)
+	policy, err := metahttp.Get[OrganizationPolicy](c.httpClient, url)
+	if err != nil {
+		if httpErr, ok := errors.AsType[*metahttp.HTTPError](err); ok && httpErr.StatusCode == http.StatusNotFound {
+			return &OrganizationPolicy{}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch organization policy from portal: %w", err)
+	}
+
+	log.Debug().Msgf("Organization policy response from portal: %+v", policy)
+	return &policy, nil
+}
+
+// FetchEnvironmentRoles fetches the roles granted to the caller in each environment of the
+// project. Portals that don't support per-environment roles return nil.
+func (c *Client) FetchEnvironmentRoles(projectUUID string) ([]EnvironmentRoles, error) {
+	url := fmt.Sprintf("/api/v1/projects/%s/environment-roles", projectUUID)
+	log.Debug().Msgf("Fetch environment roles from %s%s", c.httpClient.BaseURL, url)
+	roles, err := metahttp.Get[[]EnvironmentRoles](c.httpClient, url)
+	if err != nil {
+		if httpErr, ok := errors.AsType[*metahttp.HTTPError](err); ok && httpErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch environment roles from portal: %w", err)
+	}
+
+	log.Debug().Msgf("Environment roles response from portal: %+v", roles)
+	if roles == nil {
+		roles = []EnvironmentRoles{}
+	}
+	return roles, nil
+}
+
 // GetLatestSdkVersionInfo retrieves information about the latest SDK version.
 func (c *Client) GetLatestSdkVersionInfo() (*SdkVersionInfo, error) {
 	sdkInfo, err := metahttp.Get[SdkVersionInfo](c.httpClient, "/api/v1/sdk/latest")
@@ -413,3 +454,51 @@ func (c *Client) DownloadLatestSdk(targetDir string) (string, error) {
 	// Download the SDK
 	return c.DownloadSdkByVersionID(targetDir, latestSdk.ID, nil)
 }
+
+// FetchMachineUsers fetches the machine users of the project.
+func (c *Client) FetchMachineUsers(projectUUID string) ([]MachineUser, error) {
+	url := fmt.Sprintf("/api/v1/projects/%s/machine-users", projectUUID)
+	log.Debug().Msgf("Fetch machine users from %s%s", c.httpClient.BaseURL, url)
+	machineUsers, err := metahttp.Get[[]MachineUser](c.httpClient, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch machine users from portal: %w", err)
+	}
+	return machineUsers, nil
+}
+
+// CreateMachineUser creates a machine user with the given role in the project. The returned
+// credentials include the client secret, which cannot be retrieved again later.
+func (c *Client) CreateMachineUser(projectUUID, name, role string) (*MachineUserCredentials, error) {
+	payload := map[string]any{
+		"name": name,
+		"role": role,
+	}
+
+	url := fmt.Sprintf("/api/v1/projects/%s/machine-users", projectUUID)
+	creds, err := metahttp.PostJSON[MachineUserCredentials](c.httpClient, url, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create machine user in portal: %w", err)
+	}
+	return &creds, nil
+}
+
+// RotateMachineUserSecret replaces the client secret of the machine user with a new one. The old
+// secret stops working immediately.
+func (c *Client) RotateMachineUserSecret(machineUserUUID string) (*MachineUserCredentials, error) {
+	url := fmt.Sprintf("/api/v1/machine-users/%s/rotate-secret", machineUserUUID)
+	creds, err := metahttp.PostJSON[MachineUserCredentials](c.httpClient, url, map[string]any{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate machine user secret in portal: %w", err)
+	}
+	return &creds, nil
+}
+
+// DeleteMachineUser deletes the machine user. Its credentials stop working immediately.
+func (c *Client) DeleteMachineUser(machineUserUUID string) error {
+	url := fmt.Sprintf("/api/v1/machine-users/%s", machineUserUUID)
+	_, err := metahttp.Delete[any](c.httpClient, url, nil, "")
+	if err != nil {
+		return fmt.Errorf("failed to delete machine user in portal: %w", err)
+	}
+	return nil
+}
@@ -67,3 +67,35 @@ func TestIsMajorVersionOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectReachableBaseURL(t *testing.T) {
+	reachable := map[string]bool{
+		"https://fallback-1.example.com": false,
+		"https://fallback-2.example.com": true,
+	}
+	probed := []string{}
+	isReachable := func(baseURL string) bool {
+		probed = append(probed, baseURL)
+		return reachable[baseURL]
+	}
+
+	// Without fallbacks, the primary is used without probing.
+	if got := selectReachableBaseURL([]string{"https://primary.example.com"}, isReachable); got != "https://primary.example.com" {
+		t.Errorf("Expected primary base URL, got %s", got)
+	}
+	if len(probed) != 0 {
+		t.Errorf("Expected no probes without fallbacks, got %v", probed)
+	}
+
+	// First reachable one is used.
+	baseURLs := []string{"https://primary.example.com", "https://fallback-1.example.com", "https://fallback-2.example.com"}
+	if got := selectReachableBaseURL(baseURLs, isReachable); got != "https://fallback-2.example.com" {
+		t.Errorf("Expected second fallback base URL, got %s", got)
+	}
+
+	// If none are reachable, the primary is used.
+	reachable["https://fallback-2.example.com"] = false
+	if got := selectReachableBaseURL(baseURLs, isReachable); got != "https://primary.example.com" {
+		t.Errorf("Expected primary base URL when none are reachable, got %s", got)
+	}
+}
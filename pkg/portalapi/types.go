@@ -94,6 +94,13 @@ type EnvironmentInfo struct {
 	// Slug        string          `json:"slug"`         // Slug for the environment (simplified version of name)
 }
 
+// EnvironmentRoles lists the roles granted to the caller in an environment, as returned by
+// GET /api/v1/projects/{id}/environment-roles.
+type EnvironmentRoles struct {
+	EnvironmentUID string   `json:"environment_id"` // UUID of the environment
+	Roles          []string `json:"roles"`          // Roles granted in the environment, eg, 'game-admin'
+}
+
 // SdkVersionInfo represents information about an SDK version
 type SdkVersionInfo struct {
 	ID              string  `json:"id"`
@@ -107,3 +114,48 @@ type SdkVersionInfo struct {
 	StoragePath     *string `json:"storage_path"`
 	CreatedAt       string  `json:"created_at"`
 }
+
+// OrganizationPolicy holds the guardrails that organization admins define in the portal. The CLI
+// enforces the policy locally before operations that modify an environment. The zero value is an
+// empty policy that allows everything.
+type OrganizationPolicy struct {
+	ProtectedEnvironments []string       `json:"protected_environments"` // Human IDs of environments where destructive operations are refused
+	RequireImageSigning   bool           `json:"require_image_signing"`  // Only allow deploying images with a cosign signature in the registry
+	DeployWindows         []DeployWindow `json:"deploy_windows"`         // Time windows when deploying is allowed (none means always)
+	MinimumCliVersion     string         `json:"minimum_cli_version"`    // Minimum CLI version allowed to modify environments, eg, '1.8.0'
+	BlockedChartVersions  []string       `json:"blocked_chart_versions"` // Game server Helm chart versions or ranges not allowed, eg, '0.8.1' or '< 0.7.5'
+}
+
+// DeployWindow is a recurring time window when deploying into environments is allowed.
+type DeployWindow struct {
+	EnvironmentTypes []EnvironmentType `json:"environment_types"` // Environment types the window applies to (none means all)
+	Days             []string          `json:"days"`              // Weekdays of the window, eg, 'mon' or 'fri' (none means every day)
+	Start            string            `json:"start"`             // Start time of day in 'HH:MM' format
+	End              string            `json:"end"`               // End time of day in 'HH:MM' format (exclusive)
+	Timezone         string            `json:"timezone"`          // IANA timezone of the window, eg, 'Europe/Helsinki' (defaults to UTC)
+}
+
+// MachineUser is a non-human user of a project, eg, for authenticating CI jobs with
+// 'metaplay auth machine-login'. The client secret is never returned after creation.
+type MachineUser struct {
+	UID        string  `json:"id"`           // UUID of the machine user
+	ProjectUID string  `json:"project_id"`   // UUID of the project the machine user belongs to
+	Name       string  `json:"name"`         // Name of the machine user, unique within the project
+	Role       string  `json:"role"`         // Role of the machine user in the project, eg, 'game-admin'
+	ClientID   string  `json:"client_id"`    // Client ID used for logging in
+	CreatedAt  string  `json:"created_at"`   // Creation time (ISO8601 string)
+	LastUsedAt *string `json:"last_used_at"` // Time of the last login (ISO8601 string), nil if never used
+}
+
+// MachineUserCredentials is a machine user with its client secret, as returned when creating the
+// machine user or rotating its secret. This is the only time the secret is available.
+type MachineUserCredentials struct {
+	MachineUser
+	ClientSecret string `json:"client_secret"` // Client secret used for logging in
+}
+
+// CredentialsString returns the credentials in the format expected by 'metaplay auth machine-login',
+// ie, the METAPLAY_CREDENTIALS environment variable: '<clientId>+<clientSecret>'.
+func (creds *MachineUserCredentials) CredentialsString() string {
+	return creds.ClientID + "+" + creds.ClientSecret
+}
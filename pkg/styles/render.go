@@ -5,7 +5,10 @@
 package styles
 
 import (
+	"image/color"
 	"strings"
+
+	"charm.land/lipgloss/v2"
 )
 
 func RenderBright(str string) string    { return StyleBright.Render(str) }
@@ -31,3 +34,10 @@ func RenderListTechnical(list []string) string {
 func RenderComment(text string) string {
 	return StyleComment.Render(text)
 }
+
+// RenderSourceColor renders text in a color picked by the index of its source, eg, the pod in
+// aggregated logs. Consecutive indexes get different colors, cycling through a fixed palette.
+func RenderSourceColor(sourceNdx int, text string) string {
+	palette := []color.Color{ColorBlue, ColorGreen, ColorOrange, ColorYellow, ColorCommentGreen}
+	return lipgloss.NewStyle().Foreground(palette[sourceNdx%len(palette)]).Render(text)
+}
@@ -31,6 +31,8 @@ type GameServerOptions struct {
 	Env           map[string]string
 	ExposedPorts  []string          // optional override; defaults to []string{Port}
 	ContainerName string            // optional; useful in CI logs
+	LogPrefix     string            // prefix for server logs (default: "[server] ")
+	LogWriter     io.Writer         // where to mirror the server logs (default: os.Stdout)
 	Cmd           []string          // optional command/args to run inside the container (e.g. ["gameserver", "-LogLevel=Information"])
 	ExtraArgs     []string          // additional args to append to the default Cmd
 	ExtraEnv      map[string]string // additional env vars to merge with defaults (overrides on conflict)
@@ -96,6 +98,12 @@ func NewGameServer(opts GameServerOptions) *BackgroundGameServer {
 	opts.ExposedPorts = []string{"8585/tcp", "8888/tcp", "9090/tcp", "5550/tcp", "5560/tcp"}
 	opts.PollInterval = 2 * time.Second
 	opts.HistoryLimit = 10
+	if opts.LogPrefix == "" {
+		opts.LogPrefix = "[server] "
+	}
+	if opts.LogWriter == nil {
+		opts.LogWriter = os.Stdout
+	}
 
 	// Build default env and merge any extra env vars (extra overrides on conflict)
 	defaultEnv := map[string]string{
@@ -216,7 +224,7 @@ func (s *BackgroundGameServer) Start(ctx context.Context) error {
 	if err := s.container.Start(ctx); err != nil {
 		// Best-effort: container failed to start; drain logs for post-mortem before cleanup
 		// Attach a temporary consumer to drain logs just for post-mortem
-		tmpConsumer := &containerLogConsumer{writer: os.Stdout, prefix: "[server] "}
+		tmpConsumer := &containerLogConsumer{writer: s.opts.LogWriter, prefix: s.opts.LogPrefix}
 		_ = s.drainAllLogs(context.Background(), tmpConsumer)
 		// Now clean up
 		_ = s.Shutdown(context.Background())
@@ -226,7 +234,7 @@ func (s *BackgroundGameServer) Start(ctx context.Context) error {
 	// Attach live log consumer AFTER successful start
 	// Use a long-lived context so streaming continues past Start(ctx).
 	producerCtx, producerCancel := context.WithCancel(context.Background())
-	consumer := &containerLogConsumer{writer: os.Stdout, prefix: "[server] "}
+	consumer := &containerLogConsumer{writer: s.opts.LogWriter, prefix: s.opts.LogPrefix}
 	s.container.FollowOutput(consumer)
 	if err := s.container.StartLogProducer(producerCtx); err != nil {
 		log.Debug().Msgf("Failed to start log producer: %v", err)
@@ -24,7 +24,9 @@ type RunOnceContainerOptions struct {
 	ExposedPorts    []string          // optional ports to expose (e.g. ["8080/tcp"])
 	ContainerName   string            // optional; useful in CI logs
 	LogPrefix       string            // prefix for container logs (e.g. "[build] ")
+	LogWriter       io.Writer         // where to mirror the container logs (default: os.Stdout)
 	WorkingDir      string            // optional working directory inside container
+	User            string            // optional user to run as inside container ("uid:gid")
 	Mounts          []string          // optional bind mounts in "host:container" format
 	AutoRemove      bool              // equivalent to docker run --rm (default: true)
 	Network         string            // network mode (e.g. "container:name", "bridge", "host")
@@ -45,6 +47,9 @@ func NewRunOnceContainer(opts RunOnceContainerOptions) *RunOnceContainer {
 	if opts.LogPrefix == "" {
 		opts.LogPrefix = "[container] "
 	}
+	if opts.LogWriter == nil {
+		opts.LogWriter = os.Stdout
+	}
 	if !opts.AutoRemove && opts.ContainerName == "" {
 		// Default to auto-remove if no explicit name is set
 		opts.AutoRemove = true
@@ -72,6 +77,13 @@ func (r *RunOnceContainer) Run(ctx context.Context) (int, error) {
 		AutoRemove:   r.opts.AutoRemove,
 	}
 
+	// Run as the requested user
+	if r.opts.User != "" {
+		req.ConfigModifier = func(cfg *dockercontainer.Config) {
+			cfg.User = r.opts.User
+		}
+	}
+
 	// Handle network mode
 	if r.opts.Network != "" {
 		if req.HostConfigModifier == nil {
@@ -158,7 +170,7 @@ func (r *RunOnceContainer) Run(ctx context.Context) (int, error) {
 	log.Debug().Msg("Starting run-once container...")
 	if err := r.container.Start(ctx); err != nil {
 		// Best-effort: drain logs for post-mortem before cleanup
-		tmpConsumer := &containerLogConsumer{writer: os.Stdout, prefix: r.opts.LogPrefix}
+		tmpConsumer := &containerLogConsumer{writer: r.opts.LogWriter, prefix: r.opts.LogPrefix}
 		_ = r.drainAllLogs(context.Background(), tmpConsumer)
 		// Clean up
 		_ = r.cleanup(context.Background())
@@ -166,7 +178,7 @@ func (r *RunOnceContainer) Run(ctx context.Context) (int, error) {
 	}
 
 	// Attach log consumer after successful start
-	consumer := &containerLogConsumer{writer: os.Stdout, prefix: r.opts.LogPrefix}
+	consumer := &containerLogConsumer{writer: r.opts.LogWriter, prefix: r.opts.LogPrefix}
 	r.container.FollowOutput(consumer)
 	if err := r.container.StartLogProducer(ctx); err != nil {
 		log.Debug().Msgf("Failed to start log producer: %v", err)